@@ -1,11 +1,25 @@
+DELETE FROM access_requests;
+DELETE FROM ip_whitelist_monitor_hits;
 DELETE FROM ip_whitelist_emergency_tokens;
+DELETE FROM ip_whitelist_recovery_codes;
+DELETE FROM ip_whitelist_break_glass_approvals;
+DELETE FROM ip_whitelist_break_glass_requests;
 DELETE FROM tenant_ip_whitelist;
+DELETE FROM ip_whitelist_scopes;
+DELETE FROM tenant_ip_whitelist_countries;
 DELETE FROM email_change_tokens;
 DELETE FROM invitation_tokens;
 DELETE FROM refresh_tokens;
 DELETE FROM password_reset_tokens;
 DELETE FROM sso_auth_requests;
+DELETE FROM user_group_admins;
+DELETE FROM user_group_members;
+DELETE FROM user_groups;
 DELETE FROM user_roles;
+DELETE FROM tenant_memberships;
+DELETE FROM role_conflict_set_roles;
+DELETE FROM role_conflict_sets;
+DELETE FROM role_inclusions;
 DELETE FROM role_permissions;
 -- permission data is hardcoded and global for all tenants, no need to delete
 DELETE FROM audit_logs;
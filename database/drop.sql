@@ -1,13 +1,27 @@
 DROP TABLE IF EXISTS audit_logs;
+DROP TABLE IF EXISTS access_requests;
+DROP TABLE IF EXISTS ip_whitelist_monitor_hits;
 DROP TABLE IF EXISTS ip_whitelist_emergency_tokens;
+DROP TABLE IF EXISTS ip_whitelist_recovery_codes;
+DROP TABLE IF EXISTS ip_whitelist_break_glass_approvals;
+DROP TABLE IF EXISTS ip_whitelist_break_glass_requests;
 DROP TABLE IF EXISTS tenant_ip_whitelist;
+DROP TABLE IF EXISTS ip_whitelist_scopes;
+DROP TABLE IF EXISTS tenant_ip_whitelist_countries;
 DROP TABLE IF EXISTS goose_db_version;
 DROP TABLE IF EXISTS email_change_tokens;
 DROP TABLE IF EXISTS invitation_tokens;
 DROP TABLE IF EXISTS refresh_tokens;
 DROP TABLE IF EXISTS password_reset_tokens;
 DROP TABLE IF EXISTS sso_auth_requests;
+DROP TABLE IF EXISTS user_group_admins;
+DROP TABLE IF EXISTS user_group_members;
+DROP TABLE IF EXISTS user_groups;
 DROP TABLE IF EXISTS user_roles;
+DROP TABLE IF EXISTS tenant_memberships;
+DROP TABLE IF EXISTS role_conflict_set_roles;
+DROP TABLE IF EXISTS role_conflict_sets;
+DROP TABLE IF EXISTS role_inclusions;
 DROP TABLE IF EXISTS role_permissions;
 DROP TABLE IF EXISTS permissions;
 DROP TABLE IF EXISTS roles;
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Requests the IP whitelist would have blocked while it runs in monitor mode,
+-- counted per user, source IP and hour so that busy tenants write one row per
+-- pair per hour rather than one per request. See docs/features/ip-whitelisting.md.
+CREATE TABLE ip_whitelist_monitor_hits (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    ip_address INET NOT NULL,
+    hour TIMESTAMP WITH TIME ZONE NOT NULL,
+    hits INTEGER NOT NULL DEFAULT 1,
+    last_seen_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (tenant_id, hour, user_id, ip_address)
+);
+CREATE INDEX idx_ip_whitelist_monitor_hits_hour ON ip_whitelist_monitor_hits(hour);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS ip_whitelist_monitor_hits;
+-- +goose StatementEnd
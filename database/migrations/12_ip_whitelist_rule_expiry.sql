@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- expires_at NULL means the rule is permanent. Expired rules stop matching
+-- immediately and are deleted by lugia's maintenance runner, which audits each
+-- removal. expiry_warned_at records that the tenant's IP whitelist editors
+-- were emailed about the upcoming expiry, so each rule is warned about once.
+ALTER TABLE tenant_ip_whitelist ADD COLUMN expires_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE tenant_ip_whitelist ADD COLUMN expiry_warned_at TIMESTAMP WITH TIME ZONE;
+CREATE INDEX idx_tenant_ip_whitelist_expires_at ON tenant_ip_whitelist(expires_at) WHERE expires_at IS NOT NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_tenant_ip_whitelist_expires_at;
+ALTER TABLE tenant_ip_whitelist DROP COLUMN expiry_warned_at;
+ALTER TABLE tenant_ip_whitelist DROP COLUMN expires_at;
+
+-- +goose StatementEnd
@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- lugia caches a compiled matcher of each tenant's whitelist in every
+-- instance. Notifying from a trigger rather than from the handlers means every
+-- writer invalidates it: API handlers, imports, the maintenance runner's
+-- expiry sweep and manual fixes alike. The payload is the tenant ID, and
+-- Postgres folds identical payloads within one transaction into a single
+-- notification, so a bulk import sends one. expiry_warned_at is bookkeeping
+-- and doesn't change what matches, so updates to it alone don't notify.
+CREATE OR REPLACE FUNCTION notify_ip_whitelist_changed()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        PERFORM pg_notify('ip_whitelist_changed', OLD.tenant_id::text);
+    ELSE
+        PERFORM pg_notify('ip_whitelist_changed', NEW.tenant_id::text);
+    END IF;
+    RETURN NULL;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER tenant_ip_whitelist_changed
+    AFTER INSERT OR DELETE OR UPDATE OF tenant_id, ip_address, expires_at ON tenant_ip_whitelist
+    FOR EACH ROW
+    EXECUTE FUNCTION notify_ip_whitelist_changed();
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TRIGGER IF EXISTS tenant_ip_whitelist_changed ON tenant_ip_whitelist;
+DROP FUNCTION IF EXISTS notify_ip_whitelist_changed();
+
+-- +goose StatementEnd
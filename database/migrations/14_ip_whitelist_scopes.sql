@@ -0,0 +1,56 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- A scope narrows the IP whitelist for one role or one user. An exempt scope
+-- lets its members in from anywhere; any other scope admits its members only
+-- from addresses matching both the tenant's rules and the scope's own rules.
+-- A user's own scope takes precedence over the scopes of their roles. lugia
+-- evaluates them in the IP whitelist middleware; see
+-- docs/features/ip-whitelisting.md.
+CREATE TABLE ip_whitelist_scopes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    role_id UUID REFERENCES roles(id) ON DELETE CASCADE,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    exempt BOOLEAN NOT NULL DEFAULT false,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((role_id IS NULL) <> (user_id IS NULL)),
+    UNIQUE (tenant_id, role_id),
+    UNIQUE (tenant_id, user_id)
+);
+
+-- scope_id NULL is a tenant rule, which applies to everyone as before.
+ALTER TABLE tenant_ip_whitelist ADD COLUMN scope_id UUID REFERENCES ip_whitelist_scopes(id) ON DELETE CASCADE;
+CREATE INDEX idx_tenant_ip_whitelist_scope_id ON tenant_ip_whitelist(scope_id) WHERE scope_id IS NOT NULL;
+
+-- Moving a rule between scopes changes what matches, and scopes are part of
+-- the cached whitelist, so both notify like any other rule change.
+DROP TRIGGER IF EXISTS tenant_ip_whitelist_changed ON tenant_ip_whitelist;
+CREATE TRIGGER tenant_ip_whitelist_changed
+    AFTER INSERT OR DELETE OR UPDATE OF tenant_id, ip_address, expires_at, scope_id ON tenant_ip_whitelist
+    FOR EACH ROW
+    EXECUTE FUNCTION notify_ip_whitelist_changed();
+
+CREATE TRIGGER ip_whitelist_scopes_changed
+    AFTER INSERT OR DELETE OR UPDATE ON ip_whitelist_scopes
+    FOR EACH ROW
+    EXECUTE FUNCTION notify_ip_whitelist_changed();
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TRIGGER IF EXISTS ip_whitelist_scopes_changed ON ip_whitelist_scopes;
+DROP TRIGGER IF EXISTS tenant_ip_whitelist_changed ON tenant_ip_whitelist;
+CREATE TRIGGER tenant_ip_whitelist_changed
+    AFTER INSERT OR DELETE OR UPDATE OF tenant_id, ip_address, expires_at ON tenant_ip_whitelist
+    FOR EACH ROW
+    EXECUTE FUNCTION notify_ip_whitelist_changed();
+
+DROP INDEX IF EXISTS idx_tenant_ip_whitelist_scope_id;
+ALTER TABLE tenant_ip_whitelist DROP COLUMN scope_id;
+DROP TABLE IF EXISTS ip_whitelist_scopes;
+
+-- +goose StatementEnd
@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Country rules extend the IP whitelist by geolocation. An allow rule admits
+-- addresses located in the country on top of the CIDR rules; a deny rule
+-- blocks addresses located in the country even when a CIDR rule matches.
+-- Countries are ISO 3166-1 alpha-2 codes, resolved by lugia from a local
+-- MaxMind-format database; see docs/features/ip-whitelisting.md.
+CREATE TABLE tenant_ip_whitelist_countries (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    country_code TEXT NOT NULL CHECK (country_code ~ '^[A-Z]{2}$'),
+    action TEXT NOT NULL CHECK (action IN ('allow', 'deny')),
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (tenant_id, country_code)
+);
+
+-- Country rules are part of the cached whitelist.
+CREATE TRIGGER tenant_ip_whitelist_countries_changed
+    AFTER INSERT OR DELETE OR UPDATE ON tenant_ip_whitelist_countries
+    FOR EACH ROW
+    EXECUTE FUNCTION notify_ip_whitelist_changed();
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TRIGGER IF EXISTS tenant_ip_whitelist_countries_changed ON tenant_ip_whitelist_countries;
+DROP TABLE IF EXISTS tenant_ip_whitelist_countries;
+
+-- +goose StatementEnd
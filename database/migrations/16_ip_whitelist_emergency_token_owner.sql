@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Login, SSO and token refresh enforce the IP whitelist, so an admin locked
+-- out by it could no longer sign in to use their emergency link. Recording
+-- whose link a token is and when it expires lets sign-in admit that user while
+-- the link is live. Tokens issued before this have no owner and admit no one.
+ALTER TABLE ip_whitelist_emergency_tokens ADD COLUMN tenant_id UUID REFERENCES tenants(id) ON DELETE CASCADE;
+ALTER TABLE ip_whitelist_emergency_tokens ADD COLUMN user_id UUID REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE ip_whitelist_emergency_tokens ADD COLUMN expires_at TIMESTAMP WITH TIME ZONE;
+CREATE INDEX idx_ip_whitelist_emergency_tokens_tenant_user ON ip_whitelist_emergency_tokens(tenant_id, user_id) WHERE used_at IS NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_ip_whitelist_emergency_tokens_tenant_user;
+ALTER TABLE ip_whitelist_emergency_tokens DROP COLUMN expires_at;
+ALTER TABLE ip_whitelist_emergency_tokens DROP COLUMN user_id;
+ALTER TABLE ip_whitelist_emergency_tokens DROP COLUMN tenant_id;
+
+-- +goose StatementEnd
@@ -0,0 +1,50 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- The emailed emergency link only reaches the admin who force-activated the
+-- whitelist, so a tenant whose only such admin has left cannot recover.
+-- Recovery codes are printed in advance and redeemable by any admin holding
+-- ip_whitelist emergency; only their SHA-256 is stored.
+CREATE TABLE ip_whitelist_recovery_codes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    code_hash TEXT NOT NULL UNIQUE,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    used_at TIMESTAMP WITH TIME ZONE,
+    used_by UUID REFERENCES users(id) ON DELETE SET NULL
+);
+CREATE INDEX idx_ip_whitelist_recovery_codes_tenant_id ON ip_whitelist_recovery_codes(tenant_id);
+
+-- A break-glass request deactivates the whitelist once two different
+-- emergency holders approve it. Each holder gets their own approval link, so
+-- approvals record who approved rather than who happened to have the link.
+CREATE TABLE ip_whitelist_break_glass_requests (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    requested_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_ip_whitelist_break_glass_requests_tenant_id ON ip_whitelist_break_glass_requests(tenant_id) WHERE completed_at IS NULL;
+
+CREATE TABLE ip_whitelist_break_glass_approvals (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    request_id UUID NOT NULL REFERENCES ip_whitelist_break_glass_requests(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash TEXT NOT NULL UNIQUE,
+    approved_at TIMESTAMP WITH TIME ZONE,
+    UNIQUE (request_id, user_id)
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS ip_whitelist_break_glass_approvals;
+DROP TABLE IF EXISTS ip_whitelist_break_glass_requests;
+DROP TABLE IF EXISTS ip_whitelist_recovery_codes;
+
+-- +goose StatementEnd
@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- The user list filters on tenant_memberships, not on the home tenant in
+-- users.tenant_id, so indexes led by users.tenant_id can't serve its sort.
+-- Each ListUserIDsBy* query orders by (sort value, id) and continues after a
+-- (sort value, id) row comparison, which these indexes answer in either
+-- direction. last_login_at is indexed as the query sorts it, with users who
+-- never logged in as -infinity.
+DROP INDEX IF EXISTS idx_users_tenant_created_at_id;
+DROP INDEX IF EXISTS idx_users_tenant_last_login_at_id;
+
+CREATE INDEX idx_users_created_at_id ON users(created_at, id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_users_last_login_at_id ON users(COALESCE(last_login_at, '-infinity'::timestamptz), id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_users_name_id ON users(name, id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_users_email_id ON users(email, id) WHERE deleted_at IS NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_users_email_id;
+DROP INDEX IF EXISTS idx_users_name_id;
+DROP INDEX IF EXISTS idx_users_last_login_at_id;
+DROP INDEX IF EXISTS idx_users_created_at_id;
+
+CREATE INDEX idx_users_tenant_created_at_id ON users(tenant_id, created_at DESC, id DESC) WHERE deleted_at IS NULL;
+CREATE INDEX idx_users_tenant_last_login_at_id ON users(tenant_id, last_login_at DESC, id DESC) WHERE deleted_at IS NULL;
+
+-- +goose StatementEnd
@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- English labels alongside the Japanese description. The lugia registry
+-- keeps both in sync at startup; existing rows are filled here so the column
+-- is complete before the first sync.
+ALTER TABLE permissions ADD COLUMN description_en VARCHAR(255) NOT NULL DEFAULT '';
+
+UPDATE permissions SET description_en = labels.description_en
+FROM (VALUES
+('tenant', 'view', 'View tenant information'),
+('tenant', 'edit', 'Edit tenant information'),
+('users', 'view', 'View the user list'),
+('users', 'edit', 'Edit users'),
+('users', 'invite', 'Invite users'),
+('users', 'delete', 'Delete users'),
+('users', 'assign_roles', 'Assign roles to users'),
+('roles', 'view', 'View the role list'),
+('roles', 'edit', 'Edit roles'),
+('ip_whitelist', 'view', 'View IP restriction settings'),
+('ip_whitelist', 'edit', 'Edit IP restriction settings'),
+('ip_whitelist', 'emergency', 'Lift IP restrictions in an emergency'),
+('audit_log', 'view', 'View audit logs')
+) AS labels(resource, action, description_en)
+WHERE permissions.resource = labels.resource AND permissions.action = labels.action;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE permissions DROP COLUMN IF EXISTS description_en;
+
+-- +goose StatementEnd
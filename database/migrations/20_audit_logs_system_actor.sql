@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Maintenance jobs record changes nobody made at that moment, such as an
+-- expired IP whitelist rule being removed. Those entries have no actor.
+ALTER TABLE audit_logs ALTER COLUMN actor_id DROP NOT NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DELETE FROM audit_logs WHERE actor_id IS NULL;
+ALTER TABLE audit_logs ALTER COLUMN actor_id SET NOT NULL;
+
+-- +goose StatementEnd
@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- The background purge hard-deletes anonymized users once their retention has
+-- passed. Audit entries and IP whitelist rules outlive their author, so these
+-- references are cleared instead of blocking the delete; the user was already
+-- anonymized, so the entry loses nothing identifying.
+ALTER TABLE audit_logs DROP CONSTRAINT audit_logs_actor_id_fkey;
+ALTER TABLE audit_logs ADD CONSTRAINT audit_logs_actor_id_fkey
+    FOREIGN KEY (actor_id) REFERENCES users(id) ON DELETE SET NULL;
+
+ALTER TABLE tenant_ip_whitelist ALTER COLUMN created_by DROP NOT NULL;
+ALTER TABLE tenant_ip_whitelist DROP CONSTRAINT tenant_ip_whitelist_created_by_fkey;
+ALTER TABLE tenant_ip_whitelist ADD CONSTRAINT tenant_ip_whitelist_created_by_fkey
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+-- created_by stays nullable: a rule whose author was purged can't be given one
+-- back, and deleting it could lock its tenant out.
+ALTER TABLE tenant_ip_whitelist DROP CONSTRAINT tenant_ip_whitelist_created_by_fkey;
+ALTER TABLE tenant_ip_whitelist ADD CONSTRAINT tenant_ip_whitelist_created_by_fkey
+    FOREIGN KEY (created_by) REFERENCES users(id);
+
+ALTER TABLE audit_logs DROP CONSTRAINT audit_logs_actor_id_fkey;
+ALTER TABLE audit_logs ADD CONSTRAINT audit_logs_actor_id_fkey
+    FOREIGN KEY (actor_id) REFERENCES users(id);
+
+-- +goose StatementEnd
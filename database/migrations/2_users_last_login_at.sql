@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE users ADD COLUMN last_login_at TIMESTAMP WITH TIME ZONE;
+
+-- Support the filtered, keyset-paginated user list
+CREATE INDEX idx_users_tenant_created_at_id ON users(tenant_id, created_at DESC, id DESC) WHERE deleted_at IS NULL;
+CREATE INDEX idx_users_tenant_last_login_at_id ON users(tenant_id, last_login_at DESC, id DESC) WHERE deleted_at IS NULL;
+CREATE INDEX idx_users_tenant_status ON users(tenant_id, status) WHERE deleted_at IS NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_users_tenant_status;
+DROP INDEX IF EXISTS idx_users_tenant_last_login_at_id;
+DROP INDEX IF EXISTS idx_users_tenant_created_at_id;
+
+ALTER TABLE users DROP COLUMN IF EXISTS last_login_at;
+
+-- +goose StatementEnd
@@ -0,0 +1,52 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- users is the identity (email stays globally unique); tenant_memberships is
+-- which tenants that identity can act in. users.tenant_id remains the home
+-- tenant: the one the identity was created in and logs into by default.
+CREATE TABLE tenant_memberships (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (tenant_id, user_id)
+);
+CREATE INDEX idx_tenant_memberships_user_id ON tenant_memberships(user_id);
+
+INSERT INTO tenant_memberships (tenant_id, user_id, created_at)
+SELECT tenant_id, id, COALESCE(created_at, CURRENT_TIMESTAMP)
+FROM users
+WHERE deleted_at IS NULL;
+
+-- Every user is a member of its home tenant, however the row was inserted.
+CREATE OR REPLACE FUNCTION add_home_tenant_membership()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO tenant_memberships (tenant_id, user_id)
+    VALUES (NEW.tenant_id, NEW.id)
+    ON CONFLICT DO NOTHING;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER add_users_home_tenant_membership
+    AFTER INSERT ON users
+    FOR EACH ROW
+    EXECUTE FUNCTION add_home_tenant_membership();
+
+-- The tenant a session is acting in. NULL for sessions issued before
+-- memberships existed, which act in the user's home tenant.
+ALTER TABLE refresh_tokens ADD COLUMN tenant_id UUID REFERENCES tenants(id) ON DELETE CASCADE;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS tenant_id;
+
+DROP TRIGGER IF EXISTS add_users_home_tenant_membership ON users;
+DROP FUNCTION IF EXISTS add_home_tenant_membership();
+
+DROP TABLE IF EXISTS tenant_memberships;
+
+-- +goose StatementEnd
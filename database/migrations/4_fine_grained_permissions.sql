@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+
+INSERT INTO permissions (id, resource, action, description) VALUES
+('0c5e6f1a-2b3c-4d5e-8f90-a1b2c3d4e5f6', 'users', 'invite', 'ユーザーの招待'),
+('1d6f7a2b-3c4d-4e5f-9a01-b2c3d4e5f6a7', 'users', 'delete', 'ユーザーの削除'),
+('2e7a8b3c-4d5e-4f60-8b12-c3d4e5f6a7b8', 'users', 'assign_roles', 'ユーザーへのロール割り当て'),
+('3f8b9c4d-5e6f-4a71-9c23-d4e5f6a7b8c9', 'ip_whitelist', 'emergency', 'IP制限の緊急解除');
+
+-- Existing roles keep what "edit" used to allow
+INSERT INTO role_permissions (role_id, permission_id, tenant_id)
+SELECT role_permissions.role_id, fine.id, role_permissions.tenant_id
+FROM role_permissions
+JOIN permissions edit ON edit.id = role_permissions.permission_id AND edit.action = 'edit'
+JOIN permissions fine ON fine.resource = edit.resource
+    AND fine.action IN ('invite', 'delete', 'assign_roles', 'emergency')
+ON CONFLICT DO NOTHING;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DELETE FROM permissions WHERE (resource, action) IN (
+    ('users', 'invite'),
+    ('users', 'delete'),
+    ('users', 'assign_roles'),
+    ('ip_whitelist', 'emergency')
+);
+
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Permissions are declared in lugia's lib/authz registry and reconciled at
+-- startup. Rows the registry no longer declares are flagged rather than
+-- deleted, so existing role grants survive a rollback of the code.
+ALTER TABLE permissions ADD COLUMN removed_at TIMESTAMP WITH TIME ZONE;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE permissions DROP COLUMN removed_at;
+
+-- +goose StatementEnd
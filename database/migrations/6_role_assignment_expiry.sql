@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- expires_at NULL means the assignment is permanent. Expired rows stop
+-- counting for permission checks immediately and are deleted by lugia's
+-- maintenance runner, which audits each removal.
+ALTER TABLE user_roles ADD COLUMN expires_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE user_roles ADD COLUMN granted_by UUID REFERENCES users(id) ON DELETE SET NULL;
+CREATE INDEX idx_user_roles_expires_at ON user_roles(expires_at) WHERE expires_at IS NOT NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_user_roles_expires_at;
+ALTER TABLE user_roles DROP COLUMN granted_by;
+ALTER TABLE user_roles DROP COLUMN expires_at;
+
+-- +goose StatementEnd
@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Just-in-time role requests. A request starts pending and moves exactly once
+-- to approved, denied or expired. Approval grants the role as a time-bound
+-- user_roles row (expires_at = decided_at + duration_hours); pending requests
+-- nobody decided on are expired by lugia's maintenance runner at expires_at.
+CREATE TABLE access_requests (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    requester_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role_id UUID NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    justification TEXT NOT NULL,
+    duration_hours INTEGER NOT NULL CHECK (duration_hours > 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'denied', 'expired')),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    decided_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    decided_at TIMESTAMP WITH TIME ZONE,
+    decision_note TEXT,
+    granted_until TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_access_requests_tenant_id_created_at ON access_requests(tenant_id, created_at DESC);
+CREATE INDEX idx_access_requests_expires_at ON access_requests(expires_at) WHERE status = 'pending';
+-- One open request per user and role.
+CREATE UNIQUE INDEX uq_access_requests_pending ON access_requests(tenant_id, requester_id, role_id) WHERE status = 'pending';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS access_requests;
+
+-- +goose StatementEnd
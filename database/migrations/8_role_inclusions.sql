@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Composite roles: role_id carries every permission of included_role_id on
+-- top of its own, transitively. lugia rejects edits that would create a cycle;
+-- the permission queries expand inclusions with a recursive UNION, so a cycle
+-- that slipped in would still terminate.
+CREATE TABLE role_inclusions (
+    role_id UUID NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    included_role_id UUID NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (role_id, included_role_id),
+    CHECK (role_id <> included_role_id)
+);
+CREATE INDEX idx_role_inclusions_included_role_id ON role_inclusions(included_role_id);
+CREATE INDEX idx_role_inclusions_tenant_id ON role_inclusions(tenant_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS role_inclusions;
+
+-- +goose StatementEnd
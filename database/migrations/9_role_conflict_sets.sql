@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Separation of duties: no user may hold two or more roles of the same set,
+-- counting roles reached through role_inclusions. lugia enforces this when
+-- roles are assigned; assignments that predate a set are reported, not removed.
+CREATE TABLE role_conflict_sets (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (tenant_id, name)
+);
+
+CREATE TABLE role_conflict_set_roles (
+    conflict_set_id UUID NOT NULL REFERENCES role_conflict_sets(id) ON DELETE CASCADE,
+    role_id UUID NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (conflict_set_id, role_id)
+);
+CREATE INDEX idx_role_conflict_set_roles_tenant_id ON role_conflict_set_roles(tenant_id);
+CREATE INDEX idx_role_conflict_set_roles_role_id ON role_conflict_set_roles(role_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS role_conflict_set_roles;
+DROP TABLE IF EXISTS role_conflict_sets;
+
+-- +goose StatementEnd
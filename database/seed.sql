@@ -102,6 +102,15 @@ INSERT INTO role_permissions (role_id, permission_id, tenant_id) VALUES
 ('88888888-8888-9999-aaaa-cccccccccccc', 'cccf277b-5fd5-4f1d-b763-ebf69973e5b7', '55555555-5555-5555-5555-555555555555'), -- roles edit
 ('88888888-8888-9999-aaaa-cccccccccccc', 'a9b8c7d6-e5f4-a3b2-c1d0-e9f8a7b6c5d4', '55555555-5555-5555-5555-555555555555'); -- ip_whitelist edit
 
+-- Fine-grained actions for every role holding the matching edit permission
+-- (users invite/delete/assign_roles, ip_whitelist emergency)
+INSERT INTO role_permissions (role_id, permission_id, tenant_id)
+SELECT role_permissions.role_id, fine.id, role_permissions.tenant_id
+FROM role_permissions
+JOIN permissions edit ON edit.id = role_permissions.permission_id AND edit.action = 'edit'
+JOIN permissions fine ON fine.resource = edit.resource
+    AND fine.action IN ('invite', 'delete', 'assign_roles', 'emergency');
+
 -- Insert Users
 INSERT INTO users (id, tenant_id, email, password_hash, name, status, is_internal_admin, is_internal_user, external_sso_id) VALUES
 -- Enterprise Users (101 users)
@@ -16,6 +16,7 @@ import (
 	"dislyze/jirachi/errlib"
 	"dislyze/jirachi/jwt"
 	"dislyze/jirachi/logger"
+	"giratina/lib/config"
 	"giratina/lib/middleware"
 	"giratina/queries"
 )
@@ -39,8 +40,8 @@ func (h *AuthHandler) Login(ctx context.Context, input *LoginInput) (*struct{},
 	r := middleware.GetHTTPRequest(ctx)
 	w := middleware.GetResponseWriter(ctx)
 
-	if !h.rateLimiter.Allow(r.RemoteAddr, r) {
-		return nil, errlib.NewErrorWithDetail(fmt.Errorf("rate limit exceeded for login from %s", r.RemoteAddr), http.StatusTooManyRequests, "試行回数が上限を超えました。お手数ですが、しばらく時間をおいてから再度お試しください。")
+	if !h.rateLimiter.Allow(config.ClientIP(r), r) {
+		return nil, errlib.NewErrorWithDetail(fmt.Errorf("rate limit exceeded for login from %s", config.ClientIP(r)), http.StatusTooManyRequests, "試行回数が上限を超えました。お手数ですが、しばらく時間をおいてから再度お試しください。")
 	}
 
 	tokenPair, userID, err := h.login(ctx, &input.Body, r)
@@ -49,7 +50,7 @@ func (h *AuthHandler) Login(ctx context.Context, input *LoginInput) (*struct{},
 			EventType: "login",
 			Service:   "giratina",
 			UserID:    userID,
-			IPAddress: r.RemoteAddr,
+			IPAddress: config.ClientIP(r),
 			UserAgent: r.UserAgent(),
 			Timestamp: time.Now(),
 			Success:   false,
@@ -82,7 +83,7 @@ func (h *AuthHandler) Login(ctx context.Context, input *LoginInput) (*struct{},
 		EventType: "login",
 		Service:   "giratina",
 		UserID:    userID,
-		IPAddress: r.RemoteAddr,
+		IPAddress: config.ClientIP(r),
 		UserAgent: r.UserAgent(),
 		Timestamp: time.Now(),
 		Success:   true,
@@ -156,7 +157,7 @@ func (h *AuthHandler) login(ctx context.Context, req *LoginRequestBody, r *http.
 		UserID:     user.ID,
 		Jti:        tokenPair.JTI,
 		DeviceInfo: pgtype.Text{String: r.UserAgent(), Valid: true},
-		IpAddress:  pgtype.Text{String: r.RemoteAddr, Valid: true},
+		IpAddress:  pgtype.Text{String: config.ClientIP(r), Valid: true},
 		ExpiresAt:  pgtype.Timestamptz{Time: time.Now().Add(7 * 24 * time.Hour), Valid: true},
 	})
 	if err != nil {
@@ -9,6 +9,7 @@ import (
 
 	"dislyze/jirachi/jwt"
 	"dislyze/jirachi/logger"
+	"giratina/lib/config"
 	"giratina/lib/middleware"
 )
 
@@ -62,7 +63,7 @@ func (h *AuthHandler) revokeRefreshToken(ctx context.Context, r *http.Request) {
 		// Token is invalid or expired, log but don't fail logout
 		logger.LogAuthEvent(logger.AuthEvent{
 			EventType: "logout_token_validation_failed",
-			IPAddress: r.RemoteAddr,
+			IPAddress: config.ClientIP(r),
 			UserAgent: r.UserAgent(),
 			Success:   false,
 			Error:     err.Error(),
@@ -76,7 +77,7 @@ func (h *AuthHandler) revokeRefreshToken(ctx context.Context, r *http.Request) {
 		logger.LogAuthEvent(logger.AuthEvent{
 			EventType: "logout_token_revocation_failed",
 			UserID:    claims.UserID.String(),
-			IPAddress: r.RemoteAddr,
+			IPAddress: config.ClientIP(r),
 			UserAgent: r.UserAgent(),
 			Success:   false,
 			Error:     err.Error(),
@@ -88,7 +89,7 @@ func (h *AuthHandler) revokeRefreshToken(ctx context.Context, r *http.Request) {
 	logger.LogAuthEvent(logger.AuthEvent{
 		EventType: "logout_token_revoked",
 		UserID:    claims.UserID.String(),
-		IPAddress: r.RemoteAddr,
+		IPAddress: config.ClientIP(r),
 		UserAgent: r.UserAgent(),
 		Success:   true,
 	})
@@ -13,6 +13,7 @@ import (
 	"dislyze/jirachi/errlib"
 	"dislyze/jirachi/jwt"
 	"dislyze/jirachi/logger"
+	"giratina/lib/config"
 	"giratina/lib/middleware"
 	"giratina/queries"
 
@@ -78,7 +79,7 @@ func (h *TenantsHandler) LogInToTenant(ctx context.Context, input *LogInToTenant
 			EventType: "tenant_login",
 			Service:   "giratina",
 			UserID:    userID,
-			IPAddress: r.RemoteAddr,
+			IPAddress: config.ClientIP(r),
 			UserAgent: r.UserAgent(),
 			Timestamp: time.Now(),
 			Success:   false,
@@ -115,7 +116,7 @@ func (h *TenantsHandler) LogInToTenant(ctx context.Context, input *LogInToTenant
 		EventType: "tenant_login",
 		Service:   "giratina",
 		UserID:    userID,
-		IPAddress: r.RemoteAddr,
+		IPAddress: config.ClientIP(r),
 		UserAgent: r.UserAgent(),
 		Timestamp: time.Now(),
 		Success:   true,
@@ -169,7 +170,7 @@ func (h *TenantsHandler) logInToTenant(ctx context.Context, tenantID pgtype.UUID
 		UserID:     user.ID,
 		Jti:        tokenPair.JTI,
 		DeviceInfo: pgtype.Text{String: r.UserAgent(), Valid: true},
-		IpAddress:  pgtype.Text{String: r.RemoteAddr, Valid: true},
+		IpAddress:  pgtype.Text{String: config.ClientIP(r), Valid: true},
 		ExpiresAt:  pgtype.Timestamptz{Time: time.Now().Add(7 * 24 * time.Hour), Valid: true},
 	})
 	if err != nil {
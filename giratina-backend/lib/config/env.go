@@ -2,6 +2,8 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"net/http"
 	"os"
 
 	"github.com/joho/godotenv"
@@ -24,6 +26,21 @@ func (c *GiratinaAuthConfig) IsCookieSecure() bool {
 	return c.env.IsCookieSecure()
 }
 
+func (c *GiratinaAuthConfig) ClientIP(r *http.Request) string {
+	return ClientIP(r)
+}
+
+// ClientIP returns the peer address without its port, so that rate limits
+// count one client rather than one connection. Giratina does not read
+// forwarding headers.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
 type Env struct {
 	AppEnv                string
 	Port                  string
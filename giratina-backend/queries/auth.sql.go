@@ -18,7 +18,7 @@ INSERT INTO refresh_tokens (
     device_info,
     ip_address,
     expires_at
-) VALUES ($1, $2, $3, $4, $5) RETURNING id, user_id, jti, device_info, ip_address, expires_at, created_at, used_at, revoked_at
+) VALUES ($1, $2, $3, $4, $5) RETURNING id, user_id, jti, device_info, ip_address, expires_at, created_at, used_at, revoked_at, tenant_id
 `
 
 type CreateRefreshTokenParams struct {
@@ -48,12 +48,13 @@ func (q *Queries) CreateRefreshToken(ctx context.Context, arg *CreateRefreshToke
 		&i.CreatedAt,
 		&i.UsedAt,
 		&i.RevokedAt,
+		&i.TenantID,
 	)
 	return &i, err
 }
 
 const GetInternalUserByTenantID = `-- name: GetInternalUserByTenantID :one
-SELECT id, tenant_id, email, password_hash, name, is_internal_admin, is_internal_user, created_at, updated_at, deleted_at, status, external_sso_id FROM users
+SELECT id, tenant_id, email, password_hash, name, is_internal_admin, is_internal_user, created_at, updated_at, deleted_at, status, external_sso_id, last_login_at FROM users
 WHERE tenant_id = $1 AND is_internal_user = true AND deleted_at IS NULL
 `
 
@@ -73,12 +74,13 @@ func (q *Queries) GetInternalUserByTenantID(ctx context.Context, tenantID pgtype
 		&i.DeletedAt,
 		&i.Status,
 		&i.ExternalSsoID,
+		&i.LastLoginAt,
 	)
 	return &i, err
 }
 
 const GetRefreshTokenByUserID = `-- name: GetRefreshTokenByUserID :one
-SELECT id, user_id, jti, device_info, ip_address, expires_at, created_at, used_at, revoked_at FROM refresh_tokens 
+SELECT id, user_id, jti, device_info, ip_address, expires_at, created_at, used_at, revoked_at, tenant_id FROM refresh_tokens 
 WHERE user_id = $1 
 AND revoked_at IS NULL 
 AND expires_at > CURRENT_TIMESTAMP
@@ -97,12 +99,13 @@ func (q *Queries) GetRefreshTokenByUserID(ctx context.Context, userID pgtype.UUI
 		&i.CreatedAt,
 		&i.UsedAt,
 		&i.RevokedAt,
+		&i.TenantID,
 	)
 	return &i, err
 }
 
 const GetUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, tenant_id, email, password_hash, name, is_internal_admin, is_internal_user, created_at, updated_at, deleted_at, status, external_sso_id FROM users
+SELECT id, tenant_id, email, password_hash, name, is_internal_admin, is_internal_user, created_at, updated_at, deleted_at, status, external_sso_id, last_login_at FROM users
 WHERE email = $1 AND deleted_at IS NULL
 `
 
@@ -122,12 +125,13 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (*User, erro
 		&i.DeletedAt,
 		&i.Status,
 		&i.ExternalSsoID,
+		&i.LastLoginAt,
 	)
 	return &i, err
 }
 
 const GetUserByID = `-- name: GetUserByID :one
-SELECT id, tenant_id, email, password_hash, name, is_internal_admin, is_internal_user, created_at, updated_at, deleted_at, status, external_sso_id FROM users
+SELECT id, tenant_id, email, password_hash, name, is_internal_admin, is_internal_user, created_at, updated_at, deleted_at, status, external_sso_id, last_login_at FROM users
 WHERE id = $1 AND deleted_at IS NULL
 `
 
@@ -147,6 +151,7 @@ func (q *Queries) GetUserByID(ctx context.Context, id pgtype.UUID) (*User, error
 		&i.DeletedAt,
 		&i.Status,
 		&i.ExternalSsoID,
+		&i.LastLoginAt,
 	)
 	return &i, err
 }
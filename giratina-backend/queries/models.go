@@ -10,6 +10,22 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type AccessRequest struct {
+	ID            pgtype.UUID        `json:"id"`
+	TenantID      pgtype.UUID        `json:"tenant_id"`
+	RequesterID   pgtype.UUID        `json:"requester_id"`
+	RoleID        pgtype.UUID        `json:"role_id"`
+	Justification string             `json:"justification"`
+	DurationHours int32              `json:"duration_hours"`
+	Status        string             `json:"status"`
+	ExpiresAt     pgtype.Timestamptz `json:"expires_at"`
+	DecidedBy     pgtype.UUID        `json:"decided_by"`
+	DecidedAt     pgtype.Timestamptz `json:"decided_at"`
+	DecisionNote  pgtype.Text        `json:"decision_note"`
+	GrantedUntil  pgtype.Timestamptz `json:"granted_until"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+}
+
 type AuditLog struct {
 	ID           pgtype.UUID        `json:"id"`
 	TenantID     pgtype.UUID        `json:"tenant_id"`
@@ -61,12 +77,14 @@ type PasswordResetToken struct {
 }
 
 type Permission struct {
-	ID          pgtype.UUID        `json:"id"`
-	Resource    string             `json:"resource"`
-	Action      string             `json:"action"`
-	Description string             `json:"description"`
-	CreatedAt   pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+	ID            pgtype.UUID        `json:"id"`
+	Resource      string             `json:"resource"`
+	Action        string             `json:"action"`
+	Description   string             `json:"description"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt     pgtype.Timestamptz `json:"updated_at"`
+	RemovedAt     pgtype.Timestamptz `json:"removed_at"`
+	DescriptionEn string             `json:"description_en"`
 }
 
 type RefreshToken struct {
@@ -79,6 +97,7 @@ type RefreshToken struct {
 	CreatedAt  pgtype.Timestamptz `json:"created_at"`
 	UsedAt     pgtype.Timestamptz `json:"used_at"`
 	RevokedAt  pgtype.Timestamptz `json:"revoked_at"`
+	TenantID   pgtype.UUID        `json:"tenant_id"`
 }
 
 type Role struct {
@@ -91,6 +110,27 @@ type Role struct {
 	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
 }
 
+type RoleConflictSet struct {
+	ID        pgtype.UUID        `json:"id"`
+	TenantID  pgtype.UUID        `json:"tenant_id"`
+	Name      string             `json:"name"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type RoleConflictSetRole struct {
+	ConflictSetID pgtype.UUID        `json:"conflict_set_id"`
+	RoleID        pgtype.UUID        `json:"role_id"`
+	TenantID      pgtype.UUID        `json:"tenant_id"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+}
+
+type RoleInclusion struct {
+	RoleID         pgtype.UUID        `json:"role_id"`
+	IncludedRoleID pgtype.UUID        `json:"included_role_id"`
+	TenantID       pgtype.UUID        `json:"tenant_id"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+}
+
 type RolePermission struct {
 	RoleID       pgtype.UUID        `json:"role_id"`
 	PermissionID pgtype.UUID        `json:"permission_id"`
@@ -115,6 +155,12 @@ type Tenant struct {
 	UpdatedAt          pgtype.Timestamptz `json:"updated_at"`
 }
 
+type TenantMembership struct {
+	TenantID  pgtype.UUID        `json:"tenant_id"`
+	UserID    pgtype.UUID        `json:"user_id"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
 type TenantIpWhitelist struct {
 	ID        pgtype.UUID        `json:"id"`
 	TenantID  pgtype.UUID        `json:"tenant_id"`
@@ -137,6 +183,7 @@ type User struct {
 	DeletedAt       pgtype.Timestamptz `json:"deleted_at"`
 	Status          string             `json:"status"`
 	ExternalSsoID   pgtype.Text        `json:"external_sso_id"`
+	LastLoginAt     pgtype.Timestamptz `json:"last_login_at"`
 }
 
 type UserRole struct {
@@ -144,4 +191,6 @@ type UserRole struct {
 	RoleID    pgtype.UUID        `json:"role_id"`
 	TenantID  pgtype.UUID        `json:"tenant_id"`
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
+	GrantedBy pgtype.UUID        `json:"granted_by"`
 }
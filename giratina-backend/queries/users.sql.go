@@ -12,12 +12,13 @@ import (
 )
 
 const GetUsersByTenantID = `-- name: GetUsersByTenantID :many
-SELECT id, name, email, status
+SELECT users.id, users.name, users.email, users.status
 FROM users
-WHERE tenant_id = $1
+JOIN tenant_memberships ON tenant_memberships.user_id = users.id
+WHERE tenant_memberships.tenant_id = $1
 AND users.is_internal_user = false
 AND users.deleted_at IS NULL
-ORDER BY created_at DESC
+ORDER BY users.created_at DESC
 `
 
 type GetUsersByTenantIDRow struct {
@@ -236,6 +236,7 @@ export interface components {
             active: boolean;
             allow_internal_admin_bypass: boolean;
             enabled: boolean;
+            monitor: boolean;
         };
         LoginRequestBody: {
             /**
@@ -243,6 +243,17 @@ export function createServices(inputs: ServicesInputs): ServicesOutputs {
                   name: "FRONTEND_URL",
                   value: lugiaFrontendUrl,
                 },
+                // Ingress only admits the load balancer, whose front-end
+                // addresses aren't published, so every peer is trusted to
+                // append the client to X-Forwarded-For.
+                {
+                  name: "TRUSTED_PROXIES",
+                  value: "0.0.0.0/0,::/0",
+                },
+                {
+                  name: "TRUSTED_PROXY_HOPS",
+                  value: "1",
+                },
 
                 {
                   name: "INITIAL_PW",
@@ -6,12 +6,15 @@ package auditlog
 type ResourceType string
 
 const (
-	ResourceAuth        ResourceType = "auth"
-	ResourceAccess      ResourceType = "access"
-	ResourceUser        ResourceType = "user"
-	ResourceRole        ResourceType = "role"
-	ResourceIPWhitelist ResourceType = "ip_whitelist"
-	ResourceTenant      ResourceType = "tenant"
+	ResourceAuth            ResourceType = "auth"
+	ResourceAccess          ResourceType = "access"
+	ResourceUser            ResourceType = "user"
+	ResourceRole            ResourceType = "role"
+	ResourceRoleConflictSet ResourceType = "role_conflict_set"
+	ResourceUserGroup       ResourceType = "user_group"
+	ResourceIPWhitelist     ResourceType = "ip_whitelist"
+	ResourceTenant          ResourceType = "tenant"
+	ResourceAccessRequest   ResourceType = "access_request"
 )
 
 // Action identifies the specific operation within a resource type.
@@ -25,6 +28,7 @@ const (
 	ActionPasswordChanged        Action = "password_changed"
 	ActionPasswordResetRequested Action = "password_reset_requested"
 	ActionPasswordResetCompleted Action = "password_reset_completed"
+	ActionTenantSwitched         Action = "tenant_switched"
 )
 
 // Access actions (always outcome: failure)
@@ -43,6 +47,7 @@ const (
 	ActionRolesUpdated Action = "roles_updated"
 	ActionInviteResent Action = "invite_resent"
 	ActionListViewed   Action = "list_viewed"
+	ActionDataExported Action = "data_exported"
 )
 
 // Role management actions
@@ -60,6 +65,18 @@ const (
 	ActionIPRemoved             Action = "ip_removed"
 	ActionIPUpdated             Action = "ip_updated"
 	ActionEmergencyDeactivated  Action = "emergency_deactivated"
+	ActionMonitorStarted        Action = "monitor_started"
+	ActionIPImported            Action = "ip_imported"
+	ActionIPScopeCreated        Action = "ip_scope_created"
+	ActionIPScopeUpdated        Action = "ip_scope_updated"
+	ActionIPScopeDeleted        Action = "ip_scope_deleted"
+	ActionIPCountryAdded        Action = "ip_country_added"
+	ActionIPCountryRemoved      Action = "ip_country_removed"
+	ActionRecoveryCodesIssued   Action = "ip_recovery_codes_issued"
+	ActionRecoveryCodeUsed      Action = "ip_recovery_code_used"
+	ActionBreakGlassRequested   Action = "ip_break_glass_requested"
+	ActionBreakGlassApproved    Action = "ip_break_glass_approved"
+	ActionBreakGlassDeactivated Action = "ip_break_glass_deactivated"
 )
 
 // Tenant management actions
@@ -68,6 +85,14 @@ const (
 	ActionEnterpriseFeatureToggled Action = "enterprise_feature_toggled"
 )
 
+// Access request actions
+const (
+	ActionRequested Action = "requested"
+	ActionApproved  Action = "approved"
+	ActionDenied    Action = "denied"
+	ActionExpired   Action = "expired"
+)
+
 // Outcome represents the result of an audited action.
 type Outcome string
 
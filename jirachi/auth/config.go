@@ -1,6 +1,11 @@
 package auth
 
+import "net/http"
+
 type AuthConfig interface {
 	GetAuthJWTSecret() string
 	IsCookieSecure() bool
+	// ClientIP returns the address refresh attempts are rate limited by and
+	// recorded against.
+	ClientIP(r *http.Request) string
 }
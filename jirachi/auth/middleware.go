@@ -25,8 +25,13 @@ type AuthMiddleware struct {
 	db          *queries.Queries
 	rateLimiter *ratelimit.RateLimiter
 	pool        *pgxpool.Pool
+	tenantCheck TenantAccessCheck
 }
 
+// TenantAccessCheck decides whether a request may be issued a session in a
+// tenant. A non-nil error refuses the token refresh.
+type TenantAccessCheck func(r *http.Request, tenantID, userID pgtype.UUID) error
+
 func NewAuthMiddleware(config AuthConfig, pool *pgxpool.Pool, rateLimiter *ratelimit.RateLimiter) *AuthMiddleware {
 	return &AuthMiddleware{
 		config:      config,
@@ -36,6 +41,12 @@ func NewAuthMiddleware(config AuthConfig, pool *pgxpool.Pool, rateLimiter *ratel
 	}
 }
 
+// SetTenantAccessCheck installs a check run on every token refresh once the
+// tenant is known, before new tokens are issued.
+func (m *AuthMiddleware) SetTenantAccessCheck(check TenantAccessCheck) {
+	m.tenantCheck = check
+}
+
 func (m *AuthMiddleware) Authenticate(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		var finalClaims *jwt.Claims
@@ -100,7 +111,7 @@ func (m *AuthMiddleware) handleRefreshToken(w http.ResponseWriter, r *http.Reque
 		return nil, errors.New("no refresh token")
 	}
 
-	if !m.rateLimiter.Allow(r.RemoteAddr, r) {
+	if !m.rateLimiter.Allow(m.config.ClientIP(r), r) {
 		return nil, errors.New("too many refresh attempts")
 	}
 
@@ -159,7 +170,25 @@ func (m *AuthMiddleware) handleRefreshToken(w http.ResponseWriter, r *http.Reque
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
-	tenant, err := qtx.GetTenantByID(r.Context(), user.TenantID)
+	// A refresh token without a tenant predates tenant switching and belongs
+	// to the user's home tenant.
+	tenantID := user.TenantID
+	if storedRefreshToken.TenantID.Valid {
+		tenantID = storedRefreshToken.TenantID
+	}
+
+	isMember, err := qtx.IsTenantMember(r.Context(), &queries.IsTenantMemberParams{
+		TenantID: tenantID,
+		UserID:   user.ID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to check tenant membership: %w", err)
+	}
+	if !isMember {
+		return nil, errors.New("user is no longer a member of the refresh token's tenant")
+	}
+
+	tenant, err := qtx.GetTenantByID(r.Context(), tenantID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, errors.New("tenant not found for user")
@@ -167,6 +196,12 @@ func (m *AuthMiddleware) handleRefreshToken(w http.ResponseWriter, r *http.Reque
 		return nil, fmt.Errorf("failed to get tenant: %w", err)
 	}
 
+	if m.tenantCheck != nil {
+		if err := m.tenantCheck(r, tenant.ID, user.ID); err != nil {
+			return nil, fmt.Errorf("tenant access check failed: %w", err)
+		}
+	}
+
 	newAccessTokenString, newExpiresIn, newAccessTokenClaims, err := jwt.GenerateAccessToken(user.ID, tenant.ID, []byte(m.config.GetAuthJWTSecret()))
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate new access token: %w", err)
@@ -181,8 +216,9 @@ func (m *AuthMiddleware) handleRefreshToken(w http.ResponseWriter, r *http.Reque
 		UserID:     user.ID,
 		Jti:        newJTI,
 		DeviceInfo: pgtype.Text{String: r.UserAgent(), Valid: true},
-		IpAddress:  pgtype.Text{String: r.RemoteAddr, Valid: true},
+		IpAddress:  pgtype.Text{String: m.config.ClientIP(r), Valid: true},
 		ExpiresAt:  pgtype.Timestamptz{Time: time.Now().Add(7 * 24 * time.Hour), Valid: true},
+		TenantID:   tenant.ID,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create new refresh token in db: %w", err)
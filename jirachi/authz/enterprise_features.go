@@ -12,8 +12,9 @@ type RBAC struct {
 }
 
 type IPWhitelist struct {
-	Enabled                  bool `json:"enabled"`                  // Internal: Feature available to tenant
-	Active                   bool `json:"active"`                   // User-controlled: Whether whitelist actively enforces
+	Enabled                  bool `json:"enabled"` // Internal: Feature available to tenant
+	Active                   bool `json:"active"`  // User-controlled: Whether whitelist actively enforces
+	Monitor                  bool `json:"monitor"` // User-controlled: Record would-be blocks without enforcing; ignored while Active
 	AllowInternalAdminBypass bool `json:"allow_internal_admin_bypass"`
 }
 
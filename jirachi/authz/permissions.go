@@ -0,0 +1,22 @@
+package authz
+
+// PermissionSet is every resource/action pair a user holds in a tenant, keyed
+// by resource. It is loaded once per request and shared between requests
+// through the permission cache, so it must not be modified after loading.
+type PermissionSet map[string]map[string]bool
+
+func (s PermissionSet) Add(resource, action string) {
+	if s[resource] == nil {
+		s[resource] = map[string]bool{}
+	}
+	s[resource][action] = true
+}
+
+// Allows reports whether the set satisfies resource/action. Any action on a
+// resource satisfies a "view" check; every other action must be held itself.
+func (s PermissionSet) Allows(resource, action string) bool {
+	if action == "view" {
+		return len(s[resource]) > 0
+	}
+	return s[resource][action]
+}
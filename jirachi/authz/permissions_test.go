@@ -0,0 +1,32 @@
+package authz
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPermissionSetAllows(t *testing.T) {
+	set := PermissionSet{}
+	set.Add("users", "invite")
+	set.Add("roles", "view")
+
+	t.Run("held actions are allowed", func(t *testing.T) {
+		assert.True(t, set.Allows("users", "invite"))
+		assert.True(t, set.Allows("roles", "view"))
+	})
+
+	t.Run("any action implies view", func(t *testing.T) {
+		assert.True(t, set.Allows("users", "view"))
+	})
+
+	t.Run("view implies nothing else", func(t *testing.T) {
+		assert.False(t, set.Allows("roles", "edit"))
+		assert.False(t, set.Allows("users", "edit"))
+	})
+
+	t.Run("missing resources are denied", func(t *testing.T) {
+		assert.False(t, set.Allows("tenant", "view"))
+		assert.False(t, PermissionSet(nil).Allows("tenant", "view"))
+	})
+}
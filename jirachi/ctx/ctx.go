@@ -15,6 +15,7 @@ const (
 	UserIDKey             contextKey = "user_id"
 	EnterpriseFeaturesKey contextKey = "enterprise_features"
 	IsInternalUserKey     contextKey = "is_internal_user"
+	PermissionsKey        contextKey = "permissions"
 )
 
 func GetTenantID(ctx context.Context) pgtype.UUID {
@@ -66,3 +67,12 @@ func GetIsInternalUser(ctx context.Context) bool {
 	isInternalUser := ctx.Value(IsInternalUserKey).(bool)
 	return isInternalUser
 }
+
+func WithPermissions(ctx context.Context, permissions authz.PermissionSet) context.Context {
+	return context.WithValue(ctx, PermissionsKey, permissions)
+}
+
+func GetPermissions(ctx context.Context) authz.PermissionSet {
+	permissions := ctx.Value(PermissionsKey).(authz.PermissionSet)
+	return permissions
+}
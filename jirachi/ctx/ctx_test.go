@@ -178,3 +178,24 @@ func TestIsInternalUser(t *testing.T) {
 		})
 	})
 }
+
+func TestPermissions(t *testing.T) {
+	t.Run("round-trip", func(t *testing.T) {
+		permissions := authz.PermissionSet{}
+		permissions.Add("users", "view")
+		ctx := WithPermissions(context.Background(), permissions)
+
+		got := GetPermissions(ctx)
+
+		assert.True(t, got.Allows("users", "view"))
+		assert.False(t, got.Allows("roles", "view"))
+	})
+
+	t.Run("panics when not set", func(t *testing.T) {
+		ctx := context.Background()
+
+		assert.Panics(t, func() {
+			GetPermissions(ctx)
+		})
+	})
+}
@@ -110,3 +110,34 @@ func LogRateLimitViolation(event RateLimitEvent) {
 	}
 	fmt.Println(string(jsonData))
 }
+
+type MaintenanceEvent struct {
+	Severity  string    `json:"severity"`
+	Category  string    `json:"category"`
+	EventType string    `json:"event_type"` // "purge"
+	Service   string    `json:"service"`
+	Job       string    `json:"job"`
+	Deleted   int64     `json:"deleted"`
+	Batches   int       `json:"batches"`
+	Cutoff    time.Time `json:"cutoff"`
+	Duration  string    `json:"duration"`
+	Timestamp time.Time `json:"timestamp"`
+	Success   bool      `json:"success"`
+	Error     string    `json:"error,omitempty"`
+}
+
+func LogMaintenanceEvent(event MaintenanceEvent) {
+	event.Category = "MAINTENANCE"
+	if event.Success {
+		event.Severity = "DEFAULT"
+	} else {
+		event.Severity = "ERROR"
+	}
+
+	jsonData, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("Failed to marshal maintenance event: %v", err)
+		return
+	}
+	fmt.Println(string(jsonData))
+}
@@ -17,8 +17,9 @@ INSERT INTO refresh_tokens (
     jti,
     device_info,
     ip_address,
-    expires_at
-) VALUES ($1, $2, $3, $4, $5) RETURNING id, user_id, jti, device_info, ip_address, expires_at, created_at, used_at, revoked_at
+    expires_at,
+    tenant_id
+) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, user_id, jti, device_info, ip_address, expires_at, created_at, used_at, revoked_at, tenant_id
 `
 
 type CreateRefreshTokenParams struct {
@@ -27,6 +28,7 @@ type CreateRefreshTokenParams struct {
 	DeviceInfo pgtype.Text        `json:"device_info"`
 	IpAddress  pgtype.Text        `json:"ip_address"`
 	ExpiresAt  pgtype.Timestamptz `json:"expires_at"`
+	TenantID   pgtype.UUID        `json:"tenant_id"`
 }
 
 func (q *Queries) CreateRefreshToken(ctx context.Context, arg *CreateRefreshTokenParams) (*RefreshToken, error) {
@@ -36,6 +38,7 @@ func (q *Queries) CreateRefreshToken(ctx context.Context, arg *CreateRefreshToke
 		arg.DeviceInfo,
 		arg.IpAddress,
 		arg.ExpiresAt,
+		arg.TenantID,
 	)
 	var i RefreshToken
 	err := row.Scan(
@@ -48,6 +51,7 @@ func (q *Queries) CreateRefreshToken(ctx context.Context, arg *CreateRefreshToke
 		&i.CreatedAt,
 		&i.UsedAt,
 		&i.RevokedAt,
+		&i.TenantID,
 	)
 	return &i, err
 }
@@ -84,7 +88,7 @@ INSERT INTO users (
     status
 ) VALUES (
     $1, $2, $3, $4, $5
-) RETURNING id, tenant_id, email, password_hash, name, is_internal_admin, is_internal_user, created_at, updated_at, deleted_at, status, external_sso_id
+) RETURNING id, tenant_id, email, password_hash, name, is_internal_admin, is_internal_user, created_at, updated_at, deleted_at, status, external_sso_id, last_login_at
 `
 
 type CreateUserParams struct {
@@ -117,6 +121,7 @@ func (q *Queries) CreateUser(ctx context.Context, arg *CreateUserParams) (*User,
 		&i.DeletedAt,
 		&i.Status,
 		&i.ExternalSsoID,
+		&i.LastLoginAt,
 	)
 	return &i, err
 }
@@ -135,7 +140,7 @@ func (q *Queries) ExistsUserWithEmail(ctx context.Context, email string) (bool,
 }
 
 const GetRefreshTokenByJTI = `-- name: GetRefreshTokenByJTI :one
-SELECT id, user_id, jti, device_info, ip_address, expires_at, created_at, used_at, revoked_at FROM refresh_tokens 
+SELECT id, user_id, jti, device_info, ip_address, expires_at, created_at, used_at, revoked_at, tenant_id FROM refresh_tokens 
 WHERE jti = $1 
 AND revoked_at IS NULL 
 AND expires_at > CURRENT_TIMESTAMP
@@ -154,6 +159,7 @@ func (q *Queries) GetRefreshTokenByJTI(ctx context.Context, jti pgtype.UUID) (*R
 		&i.CreatedAt,
 		&i.UsedAt,
 		&i.RevokedAt,
+		&i.TenantID,
 	)
 	return &i, err
 }
@@ -179,7 +185,7 @@ func (q *Queries) GetTenantByID(ctx context.Context, id pgtype.UUID) (*Tenant, e
 }
 
 const GetUserByID = `-- name: GetUserByID :one
-SELECT id, tenant_id, email, password_hash, name, is_internal_admin, is_internal_user, created_at, updated_at, deleted_at, status, external_sso_id FROM users
+SELECT id, tenant_id, email, password_hash, name, is_internal_admin, is_internal_user, created_at, updated_at, deleted_at, status, external_sso_id, last_login_at FROM users
 WHERE id = $1
 `
 
@@ -199,10 +205,30 @@ func (q *Queries) GetUserByID(ctx context.Context, id pgtype.UUID) (*User, error
 		&i.DeletedAt,
 		&i.Status,
 		&i.ExternalSsoID,
+		&i.LastLoginAt,
 	)
 	return &i, err
 }
 
+const IsTenantMember = `-- name: IsTenantMember :one
+SELECT EXISTS (
+    SELECT 1 FROM tenant_memberships
+    WHERE tenant_id = $1 AND user_id = $2
+)
+`
+
+type IsTenantMemberParams struct {
+	TenantID pgtype.UUID `json:"tenant_id"`
+	UserID   pgtype.UUID `json:"user_id"`
+}
+
+func (q *Queries) IsTenantMember(ctx context.Context, arg *IsTenantMemberParams) (bool, error) {
+	row := q.db.QueryRow(ctx, IsTenantMember, arg.TenantID, arg.UserID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
 const UpdateRefreshTokenUsed = `-- name: UpdateRefreshTokenUsed :exec
 UPDATE refresh_tokens 
 SET used_at = CURRENT_TIMESTAMP 
@@ -18,6 +18,7 @@ type Querier interface {
 	GetRefreshTokenByJTI(ctx context.Context, jti pgtype.UUID) (*RefreshToken, error)
 	GetTenantByID(ctx context.Context, id pgtype.UUID) (*Tenant, error)
 	GetUserByID(ctx context.Context, id pgtype.UUID) (*User, error)
+	IsTenantMember(ctx context.Context, arg *IsTenantMemberParams) (bool, error)
 	UpdateRefreshTokenUsed(ctx context.Context, jti pgtype.UUID) error
 }
 
@@ -37,8 +37,9 @@ INSERT INTO refresh_tokens (
     jti,
     device_info,
     ip_address,
-    expires_at
-) VALUES ($1, $2, $3, $4, $5) RETURNING *;
+    expires_at,
+    tenant_id
+) VALUES ($1, $2, $3, $4, $5, $6) RETURNING *;
 
 -- name: UpdateRefreshTokenUsed :exec
 UPDATE refresh_tokens 
@@ -48,3 +49,9 @@ WHERE jti = $1;
 -- name: GetUserByID :one
 SELECT * FROM users
 WHERE id = $1;
+
+-- name: IsTenantMember :one
+SELECT EXISTS (
+    SELECT 1 FROM tenant_memberships
+    WHERE tenant_id = $1 AND user_id = $2
+);
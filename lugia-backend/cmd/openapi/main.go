@@ -13,10 +13,12 @@ import (
 	"fmt"
 	"os"
 
+	"lugia/features/access_requests"
 	"lugia/features/audit_logs"
 	"lugia/features/auth"
 	"lugia/features/ip_whitelist"
 	"lugia/features/roles"
+	"lugia/features/user_groups"
 	"lugia/features/users"
 
 	"github.com/danielgtaylor/huma/v2"
@@ -77,6 +79,21 @@ func main() {
 		huma.Register(api, users.VerifyChangeEmailOp, func(_ context.Context, _ *users.VerifyChangeEmailInput) (*struct{}, error) {
 			return nil, nil
 		})
+		huma.Register(api, users.ExportMyDataOp, func(_ context.Context, _ *users.ExportMyDataInput) (*users.ExportUserDataOutput, error) {
+			return nil, nil
+		})
+		huma.Register(api, users.GetMyTenantsOp, func(_ context.Context, _ *users.GetMyTenantsInput) (*users.GetMyTenantsOutput, error) {
+			return nil, nil
+		})
+		huma.Register(api, users.SwitchTenantOp, func(_ context.Context, _ *users.SwitchTenantInput) (*struct{}, error) {
+			return nil, nil
+		})
+		huma.Register(api, access_requests.GetMyAccessRequestsOp, func(_ context.Context, _ *access_requests.GetMyAccessRequestsInput) (*access_requests.GetMyAccessRequestsOutput, error) {
+			return nil, nil
+		})
+		huma.Register(api, access_requests.CreateAccessRequestOp, func(_ context.Context, _ *access_requests.CreateAccessRequestInput) (*struct{}, error) {
+			return nil, nil
+		})
 
 		// /tenant endpoints
 		huma.Register(api, users.ChangeTenantNameOp, func(_ context.Context, _ *users.ChangeTenantNameInput) (*struct{}, error) {
@@ -102,6 +119,26 @@ func main() {
 		huma.Register(api, users.DeleteUserOp, func(_ context.Context, _ *users.DeleteUserInput) (*struct{}, error) {
 			return nil, nil
 		})
+		huma.Register(api, users.GetUserPermissionsOp, func(_ context.Context, _ *users.GetUserPermissionsInput) (*users.GetUserPermissionsOutput, error) {
+			return nil, nil
+		})
+		huma.Register(api, users.ExportUserDataOp, func(_ context.Context, _ *users.ExportUserDataInput) (*users.ExportUserDataOutput, error) {
+			return nil, nil
+		})
+
+		// /users/groups endpoints
+		huma.Register(api, user_groups.GetUserGroupsOp, func(_ context.Context, _ *user_groups.GetUserGroupsInput) (*user_groups.GetUserGroupsOutput, error) {
+			return nil, nil
+		})
+		huma.Register(api, user_groups.CreateUserGroupOp, func(_ context.Context, _ *user_groups.CreateUserGroupInput) (*struct{}, error) {
+			return nil, nil
+		})
+		huma.Register(api, user_groups.UpdateUserGroupOp, func(_ context.Context, _ *user_groups.UpdateUserGroupInput) (*struct{}, error) {
+			return nil, nil
+		})
+		huma.Register(api, user_groups.DeleteUserGroupOp, func(_ context.Context, _ *user_groups.DeleteUserGroupInput) (*struct{}, error) {
+			return nil, nil
+		})
 
 		// /roles endpoints
 		huma.Register(api, roles.GetRolesOp, func(_ context.Context, _ *roles.GetRolesInput) (*roles.GetRolesOutput, error) {
@@ -119,14 +156,58 @@ func main() {
 		huma.Register(api, roles.DeleteRoleOp, func(_ context.Context, _ *roles.DeleteRoleInput) (*struct{}, error) {
 			return nil, nil
 		})
+		huma.Register(api, roles.DuplicateRoleOp, func(_ context.Context, _ *roles.DuplicateRoleInput) (*struct{}, error) {
+			return nil, nil
+		})
+		huma.Register(api, roles.GetRoleTemplatesOp, func(_ context.Context, _ *roles.GetRoleTemplatesInput) (*roles.GetRoleTemplatesOutput, error) {
+			return nil, nil
+		})
+		huma.Register(api, roles.CreateRoleFromTemplateOp, func(_ context.Context, _ *roles.CreateRoleFromTemplateInput) (*struct{}, error) {
+			return nil, nil
+		})
+		huma.Register(api, roles.GetConflictSetsOp, func(_ context.Context, _ *roles.GetConflictSetsInput) (*roles.GetConflictSetsOutput, error) {
+			return nil, nil
+		})
+		huma.Register(api, roles.GetConflictViolationsOp, func(_ context.Context, _ *roles.GetConflictViolationsInput) (*roles.GetConflictViolationsOutput, error) {
+			return nil, nil
+		})
+		huma.Register(api, roles.CreateConflictSetOp, func(_ context.Context, _ *roles.CreateConflictSetInput) (*struct{}, error) {
+			return nil, nil
+		})
+		huma.Register(api, roles.DeleteConflictSetOp, func(_ context.Context, _ *roles.DeleteConflictSetInput) (*struct{}, error) {
+			return nil, nil
+		})
+
+		// /access-requests endpoints
+		huma.Register(api, access_requests.GetAccessRequestsOp, func(_ context.Context, _ *access_requests.GetAccessRequestsInput) (*access_requests.GetAccessRequestsOutput, error) {
+			return nil, nil
+		})
+		huma.Register(api, access_requests.ApproveAccessRequestOp, func(_ context.Context, _ *access_requests.ApproveAccessRequestInput) (*struct{}, error) {
+			return nil, nil
+		})
+		huma.Register(api, access_requests.DenyAccessRequestOp, func(_ context.Context, _ *access_requests.DenyAccessRequestInput) (*struct{}, error) {
+			return nil, nil
+		})
 
 		// /ip-whitelist endpoints
 		huma.Register(api, ip_whitelist.GetIPWhitelistOp, func(_ context.Context, _ *ip_whitelist.GetIPWhitelistInput) (*ip_whitelist.GetIPWhitelistOutput, error) {
 			return nil, nil
 		})
+		huma.Register(api, ip_whitelist.GetMonitorReportOp, func(_ context.Context, _ *ip_whitelist.GetMonitorReportInput) (*ip_whitelist.GetMonitorReportOutput, error) {
+			return nil, nil
+		})
+		huma.Register(api, ip_whitelist.GetIPWhitelistAnalysisOp, func(_ context.Context, _ *ip_whitelist.GetIPWhitelistAnalysisInput) (*ip_whitelist.GetIPWhitelistAnalysisOutput, error) {
+			return nil, nil
+		})
+		huma.Register(api, ip_whitelist.ExportIPWhitelistOp, func(_ context.Context, _ *ip_whitelist.ExportIPWhitelistInput) (*ip_whitelist.ExportIPWhitelistOutput, error) {
+			return nil, nil
+		})
 		huma.Register(api, ip_whitelist.AddIPOp, func(_ context.Context, _ *ip_whitelist.AddIPInput) (*struct{}, error) {
 			return nil, nil
 		})
+		huma.Register(api, ip_whitelist.ImportIPWhitelistOp, func(_ context.Context, _ *ip_whitelist.ImportIPWhitelistInput) (*ip_whitelist.ImportIPWhitelistOutput, error) {
+			return nil, nil
+		})
 		huma.Register(api, ip_whitelist.UpdateIPLabelOp, func(_ context.Context, _ *ip_whitelist.UpdateIPLabelInput) (*struct{}, error) {
 			return nil, nil
 		})
@@ -139,9 +220,48 @@ func main() {
 		huma.Register(api, ip_whitelist.DeactivateWhitelistOp, func(_ context.Context, _ *ip_whitelist.DeactivateWhitelistInput) (*struct{}, error) {
 			return nil, nil
 		})
+		huma.Register(api, ip_whitelist.StartMonitorOp, func(_ context.Context, _ *ip_whitelist.StartMonitorInput) (*struct{}, error) {
+			return nil, nil
+		})
+		huma.Register(api, ip_whitelist.GetIPWhitelistScopesOp, func(_ context.Context, _ *ip_whitelist.GetIPWhitelistScopesInput) (*ip_whitelist.GetIPWhitelistScopesOutput, error) {
+			return nil, nil
+		})
+		huma.Register(api, ip_whitelist.CreateIPWhitelistScopeOp, func(_ context.Context, _ *ip_whitelist.CreateIPWhitelistScopeInput) (*ip_whitelist.CreateIPWhitelistScopeOutput, error) {
+			return nil, nil
+		})
+		huma.Register(api, ip_whitelist.UpdateIPWhitelistScopeOp, func(_ context.Context, _ *ip_whitelist.UpdateIPWhitelistScopeInput) (*struct{}, error) {
+			return nil, nil
+		})
+		huma.Register(api, ip_whitelist.DeleteIPWhitelistScopeOp, func(_ context.Context, _ *ip_whitelist.DeleteIPWhitelistScopeInput) (*struct{}, error) {
+			return nil, nil
+		})
+		huma.Register(api, ip_whitelist.GetIPWhitelistCountriesOp, func(_ context.Context, _ *ip_whitelist.GetIPWhitelistCountriesInput) (*ip_whitelist.GetIPWhitelistCountriesOutput, error) {
+			return nil, nil
+		})
+		huma.Register(api, ip_whitelist.AddIPWhitelistCountryOp, func(_ context.Context, _ *ip_whitelist.AddIPWhitelistCountryInput) (*ip_whitelist.AddIPWhitelistCountryOutput, error) {
+			return nil, nil
+		})
+		huma.Register(api, ip_whitelist.DeleteIPWhitelistCountryOp, func(_ context.Context, _ *ip_whitelist.DeleteIPWhitelistCountryInput) (*struct{}, error) {
+			return nil, nil
+		})
 		huma.Register(api, ip_whitelist.EmergencyDeactivateOp, func(_ context.Context, _ *ip_whitelist.EmergencyDeactivateInput) (*struct{}, error) {
 			return nil, nil
 		})
+		huma.Register(api, ip_whitelist.GetRecoveryCodesOp, func(_ context.Context, _ *ip_whitelist.GetRecoveryCodesInput) (*ip_whitelist.GetRecoveryCodesOutput, error) {
+			return nil, nil
+		})
+		huma.Register(api, ip_whitelist.IssueRecoveryCodesOp, func(_ context.Context, _ *ip_whitelist.IssueRecoveryCodesInput) (*ip_whitelist.IssueRecoveryCodesOutput, error) {
+			return nil, nil
+		})
+		huma.Register(api, ip_whitelist.RedeemRecoveryCodeOp, func(_ context.Context, _ *ip_whitelist.RedeemRecoveryCodeInput) (*struct{}, error) {
+			return nil, nil
+		})
+		huma.Register(api, ip_whitelist.RequestBreakGlassOp, func(_ context.Context, _ *ip_whitelist.RequestBreakGlassInput) (*struct{}, error) {
+			return nil, nil
+		})
+		huma.Register(api, ip_whitelist.ApproveBreakGlassOp, func(_ context.Context, _ *ip_whitelist.ApproveBreakGlassInput) (*ip_whitelist.ApproveBreakGlassOutput, error) {
+			return nil, nil
+		})
 
 		// /audit-logs endpoints
 		huma.Register(api, audit_logs.GetAuditLogsOp, func(_ context.Context, _ *audit_logs.GetAuditLogsInput) (*audit_logs.GetAuditLogsOutput, error) {
@@ -0,0 +1,210 @@
+// Feature doc: docs/features/access-requests.md, docs/features/audit-logging.md
+package access_requests
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"dislyze/jirachi/auditlog"
+	libctx "dislyze/jirachi/ctx"
+	"dislyze/jirachi/errlib"
+	"dislyze/jirachi/sendgridlib"
+	"lugia/lib/accessrequest"
+	"lugia/lib/authz"
+	"lugia/lib/iputils"
+	"lugia/lib/middleware"
+	"lugia/queries"
+)
+
+var CreateAccessRequestOp = huma.Operation{
+	OperationID: "create-access-request",
+	Method:      http.MethodPost,
+	Path:        "/me/access-requests",
+}
+
+type CreateAccessRequestInput struct {
+	Body CreateAccessRequestRequestBody
+}
+
+type CreateAccessRequestRequestBody struct {
+	RoleID        string `json:"role_id" minLength:"1"`
+	DurationHours int32  `json:"duration_hours"`
+	Justification string `json:"justification" minLength:"1" maxLength:"1000"`
+}
+
+func (r *CreateAccessRequestRequestBody) Resolve(ctx huma.Context) []error {
+	r.Justification = strings.TrimSpace(r.Justification)
+	if r.Justification == "" {
+		return []error{fmt.Errorf("justification is required")}
+	}
+	if r.DurationHours < accessrequest.MinDurationHours || r.DurationHours > accessrequest.MaxDurationHours {
+		return []error{fmt.Errorf("duration_hours must be between %d and %d", accessrequest.MinDurationHours, accessrequest.MaxDurationHours)}
+	}
+	return nil
+}
+
+func (h *AccessRequestsHandler) CreateAccessRequest(ctx context.Context, input *CreateAccessRequestInput) (*struct{}, error) {
+	var roleID pgtype.UUID
+	if err := roleID.Scan(input.Body.RoleID); err != nil {
+		return nil, errlib.NewError(fmt.Errorf("CreateAccessRequest: invalid role ID format: %w", err), http.StatusBadRequest)
+	}
+
+	if err := h.createAccessRequest(ctx, roleID, input.Body); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (h *AccessRequestsHandler) createAccessRequest(ctx context.Context, roleID pgtype.UUID, req CreateAccessRequestRequestBody) error {
+	tenantID := libctx.GetTenantID(ctx)
+	requesterID := libctx.GetUserID(ctx)
+	rbacEnabled := authz.TenantHasFeature(ctx, authz.FeatureRBAC)
+
+	role, err := h.q.GetRoleByID(ctx, &queries.GetRoleByIDParams{
+		ID:       roleID,
+		TenantID: tenantID,
+	})
+	if err != nil {
+		if errlib.Is(err, pgx.ErrNoRows) {
+			return errlib.NewError(fmt.Errorf("CreateAccessRequest: role %s not found in tenant %s", roleID.String(), tenantID.String()), http.StatusNotFound)
+		}
+		return errlib.NewError(fmt.Errorf("CreateAccessRequest: failed to get role: %w", err), http.StatusInternalServerError)
+	}
+	if !rbacEnabled && !role.IsDefault {
+		return errlib.NewErrorWithDetail(fmt.Errorf("CreateAccessRequest: custom role %s requested while RBAC is disabled", roleID.String()), http.StatusBadRequest, "RBACが無効なため、カスタムロールは申請できません。")
+	}
+
+	assignments, err := h.q.GetUserRoleAssignments(ctx, &queries.GetUserRoleAssignmentsParams{
+		UserID:   requesterID,
+		TenantID: tenantID,
+	})
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("CreateAccessRequest: failed to get role assignments: %w", err), http.StatusInternalServerError)
+	}
+	for _, a := range assignments {
+		if a.RoleID == roleID && !a.ExpiresAt.Valid {
+			return errlib.NewErrorWithDetail(fmt.Errorf("CreateAccessRequest: user %s already holds role %s permanently", requesterID.String(), roleID.String()), http.StatusConflict, "このロールは既に付与されています。")
+		}
+	}
+
+	tx, err := h.dbConn.Begin(ctx)
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("CreateAccessRequest: failed to begin transaction: %w", err), http.StatusInternalServerError)
+	}
+	defer func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errlib.Is(rbErr, pgx.ErrTxClosed) && !errlib.Is(rbErr, sql.ErrTxDone) {
+			errlib.LogError(fmt.Errorf("CreateAccessRequest: failed to rollback transaction: %w", rbErr))
+		}
+	}()
+	qtx := h.q.WithTx(tx)
+
+	pending, err := qtx.HasPendingAccessRequest(ctx, &queries.HasPendingAccessRequestParams{
+		TenantID:    tenantID,
+		RequesterID: requesterID,
+		RoleID:      roleID,
+	})
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("CreateAccessRequest: failed to check pending requests: %w", err), http.StatusInternalServerError)
+	}
+	if pending {
+		return errlib.NewErrorWithDetail(fmt.Errorf("CreateAccessRequest: user %s already has a pending request for role %s", requesterID.String(), roleID.String()), http.StatusConflict, "このロールへの申請は既に承認待ちです。")
+	}
+
+	approvers, err := qtx.GetAccessRequestApprovers(ctx, &queries.GetAccessRequestApproversParams{
+		TenantID:    tenantID,
+		RequesterID: requesterID,
+		RbacEnabled: rbacEnabled,
+	})
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("CreateAccessRequest: failed to get approvers: %w", err), http.StatusInternalServerError)
+	}
+	if len(approvers) == 0 {
+		return errlib.NewErrorWithDetail(fmt.Errorf("CreateAccessRequest: tenant %s has no approvers other than user %s", tenantID.String(), requesterID.String()), http.StatusConflict, "申請を承認できるユーザーがいないため、申請できません。")
+	}
+
+	requester, err := qtx.GetUserByID(ctx, requesterID)
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("CreateAccessRequest: failed to get requester: %w", err), http.StatusInternalServerError)
+	}
+
+	requestID, err := qtx.CreateAccessRequest(ctx, &queries.CreateAccessRequestParams{
+		TenantID:      tenantID,
+		RequesterID:   requesterID,
+		RoleID:        roleID,
+		Justification: req.Justification,
+		DurationHours: req.DurationHours,
+		ExpiresAt:     pgtype.Timestamptz{Time: time.Now().Add(accessrequest.PendingTTL), Valid: true},
+	})
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("CreateAccessRequest: failed to create access request: %w", err), http.StatusInternalServerError)
+	}
+
+	if authz.TenantHasFeature(ctx, authz.FeatureAuditLog) {
+		r := middleware.GetHTTPRequest(ctx)
+		metadata, _ := json.Marshal(map[string]string{
+			"actor_name":     requester.Name,
+			"actor_email":    requester.Email,
+			"role_id":        roleID.String(),
+			"role_name":      role.Name,
+			"duration_hours": strconv.Itoa(int(req.DurationHours)),
+			"justification":  req.Justification,
+		})
+
+		ipAddr, _ := netip.ParseAddr(iputils.ExtractClientIP(r))
+		err = qtx.InsertAuditLog(ctx, &queries.InsertAuditLogParams{
+			TenantID:     tenantID,
+			ActorID:      requesterID,
+			ResourceType: string(auditlog.ResourceAccessRequest),
+			Action:       string(auditlog.ActionRequested),
+			Outcome:      string(auditlog.OutcomeSuccess),
+			ResourceID:   pgtype.Text{String: requestID.String(), Valid: true},
+			Metadata:     metadata,
+			IpAddress:    &ipAddr,
+			UserAgent:    pgtype.Text{String: r.UserAgent(), Valid: true},
+		})
+		if err != nil {
+			return errlib.NewError(fmt.Errorf("CreateAccessRequest: failed to insert audit log: %w", err), http.StatusInternalServerError)
+		}
+	}
+
+	to := make([]sendgridlib.SendGridEmailAddress, 0, len(approvers))
+	for _, approver := range approvers {
+		to = append(to, sendgridlib.SendGridEmailAddress{Email: approver.Email, Name: approver.Name})
+	}
+	if err := h.sendApproverNotification(to, requester.Name, role.Name, req); err != nil {
+		return errlib.NewError(fmt.Errorf("CreateAccessRequest: failed to notify approvers: %w", err), http.StatusInternalServerError)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return errlib.NewError(fmt.Errorf("CreateAccessRequest: failed to commit transaction: %w", err), http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
+func (h *AccessRequestsHandler) sendApproverNotification(to []sendgridlib.SendGridEmailAddress, requesterName, roleName string, req CreateAccessRequestRequestBody) error {
+	subject := fmt.Sprintf("%sさんから「%s」ロールのアクセス申請が届きました", requesterName, roleName)
+	reviewLink := fmt.Sprintf("%s/settings/access-requests", h.env.FrontendURL)
+
+	plainTextContent := fmt.Sprintf("%sさんが「%s」ロールを%d時間利用するための申請を行いました。\n\n申請理由：\n%s\n\n以下のリンクから承認または却下してください。\n%s\n\n申請は%d日以内に処理されない場合、自動的に期限切れになります。",
+		requesterName, roleName, req.DurationHours, req.Justification, reviewLink, int(accessrequest.PendingTTL.Hours()/24))
+	htmlContent := fmt.Sprintf(`<p>%sさんが「%s」ロールを%d時間利用するための申請を行いました。</p>
+	<p>申請理由：<br>%s</p>
+	<p><a href="%s">申請を確認する</a></p>
+	<p>申請は%d日以内に処理されない場合、自動的に期限切れになります。</p>`,
+		html.EscapeString(requesterName), html.EscapeString(roleName), req.DurationHours, html.EscapeString(req.Justification), reviewLink, int(accessrequest.PendingTTL.Hours()/24))
+
+	return h.sendMail(to, subject, plainTextContent, htmlContent)
+}
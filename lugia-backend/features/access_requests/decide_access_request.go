@@ -0,0 +1,250 @@
+// Feature doc: docs/features/access-requests.md, docs/features/separation-of-duties.md, docs/features/audit-logging.md
+package access_requests
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"dislyze/jirachi/auditlog"
+	libctx "dislyze/jirachi/ctx"
+	"dislyze/jirachi/errlib"
+	"dislyze/jirachi/sendgridlib"
+	"lugia/lib/accessrequest"
+	"lugia/lib/authz"
+	"lugia/lib/iputils"
+	"lugia/lib/middleware"
+	"lugia/queries"
+)
+
+var ApproveAccessRequestOp = huma.Operation{
+	OperationID: "approve-access-request",
+	Method:      http.MethodPost,
+	Path:        "/access-requests/{requestID}/approve",
+}
+
+var DenyAccessRequestOp = huma.Operation{
+	OperationID: "deny-access-request",
+	Method:      http.MethodPost,
+	Path:        "/access-requests/{requestID}/deny",
+}
+
+type ApproveAccessRequestInput struct {
+	RequestID string `path:"requestID"`
+}
+
+type DenyAccessRequestInput struct {
+	RequestID string `path:"requestID"`
+	Body      DenyAccessRequestRequestBody
+}
+
+type DenyAccessRequestRequestBody struct {
+	Note string `json:"note,omitempty" maxLength:"1000"`
+}
+
+func (h *AccessRequestsHandler) ApproveAccessRequest(ctx context.Context, input *ApproveAccessRequestInput) (*struct{}, error) {
+	var requestID pgtype.UUID
+	if err := requestID.Scan(input.RequestID); err != nil {
+		return nil, errlib.NewError(fmt.Errorf("ApproveAccessRequest: invalid request ID format: %w", err), http.StatusBadRequest)
+	}
+
+	if err := h.decideAccessRequest(ctx, "ApproveAccessRequest", requestID, accessrequest.StatusApproved, ""); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (h *AccessRequestsHandler) DenyAccessRequest(ctx context.Context, input *DenyAccessRequestInput) (*struct{}, error) {
+	var requestID pgtype.UUID
+	if err := requestID.Scan(input.RequestID); err != nil {
+		return nil, errlib.NewError(fmt.Errorf("DenyAccessRequest: invalid request ID format: %w", err), http.StatusBadRequest)
+	}
+
+	if err := h.decideAccessRequest(ctx, "DenyAccessRequest", requestID, accessrequest.StatusDenied, strings.TrimSpace(input.Body.Note)); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// decideAccessRequest moves a pending request to approved or denied. The row
+// is locked for the whole transaction, so two approvers acting at once can't
+// both decide it. Approval grants the role until now + duration_hours.
+func (h *AccessRequestsHandler) decideAccessRequest(ctx context.Context, op string, requestID pgtype.UUID, next accessrequest.Status, note string) error {
+	tenantID := libctx.GetTenantID(ctx)
+	deciderID := libctx.GetUserID(ctx)
+
+	tx, err := h.dbConn.Begin(ctx)
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("%s: failed to begin transaction: %w", op, err), http.StatusInternalServerError)
+	}
+	defer func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errlib.Is(rbErr, pgx.ErrTxClosed) && !errlib.Is(rbErr, sql.ErrTxDone) {
+			errlib.LogError(fmt.Errorf("%s: failed to rollback transaction: %w", op, rbErr))
+		}
+	}()
+	qtx := h.q.WithTx(tx)
+
+	req, err := qtx.GetAccessRequestForDecision(ctx, &queries.GetAccessRequestForDecisionParams{
+		ID:       requestID,
+		TenantID: tenantID,
+	})
+	if err != nil {
+		if errlib.Is(err, pgx.ErrNoRows) {
+			return errlib.NewError(fmt.Errorf("%s: access request %s not found in tenant %s", op, requestID.String(), tenantID.String()), http.StatusNotFound)
+		}
+		return errlib.NewError(fmt.Errorf("%s: failed to get access request: %w", op, err), http.StatusInternalServerError)
+	}
+
+	if req.RequesterID == deciderID {
+		return errlib.NewErrorWithDetail(fmt.Errorf("%s: user %s tried to decide their own access request %s", op, deciderID.String(), requestID.String()), http.StatusForbidden, "自分の申請は承認・却下できません。")
+	}
+
+	current := accessrequest.Status(req.Status)
+	if !current.CanTransitionTo(next) {
+		return errlib.NewErrorWithDetail(fmt.Errorf("%s: access request %s cannot move from %s to %s", op, requestID.String(), current, next), http.StatusConflict, "この申請は既に処理されています。")
+	}
+
+	now := time.Now()
+	if !req.ExpiresAt.Time.After(now) {
+		return errlib.NewErrorWithDetail(fmt.Errorf("%s: access request %s expired at %s", op, requestID.String(), req.ExpiresAt.Time.Format(time.RFC3339)), http.StatusConflict, "この申請は期限切れです。")
+	}
+
+	var grantedUntil pgtype.Timestamptz
+	if next == accessrequest.StatusApproved {
+		isMember, err := qtx.IsTenantMember(ctx, &queries.IsTenantMemberParams{
+			TenantID: tenantID,
+			UserID:   req.RequesterID,
+		})
+		if err != nil {
+			return errlib.NewError(fmt.Errorf("%s: failed to check membership of requester %s: %w", op, req.RequesterID.String(), err), http.StatusInternalServerError)
+		}
+		if !isMember {
+			return errlib.NewErrorWithDetail(fmt.Errorf("%s: requester %s is no longer a member of tenant %s", op, req.RequesterID.String(), tenantID.String()), http.StatusConflict, "申請者はこのテナントに所属していません。")
+		}
+
+		grantedUntil = pgtype.Timestamptz{Time: accessrequest.GrantedUntil(now, req.DurationHours), Valid: true}
+		err = qtx.GrantTemporaryRole(ctx, &queries.GrantTemporaryRoleParams{
+			UserID:    req.RequesterID,
+			RoleID:    req.RoleID,
+			TenantID:  tenantID,
+			ExpiresAt: grantedUntil,
+			GrantedBy: deciderID,
+		})
+		if err != nil {
+			return errlib.NewError(fmt.Errorf("%s: failed to grant role %s to user %s: %w", op, req.RoleID.String(), req.RequesterID.String(), err), http.StatusInternalServerError)
+		}
+
+		conflicts, err := authz.UserRoleConflicts(ctx, qtx, tenantID, req.RequesterID)
+		if err != nil {
+			return errlib.NewError(fmt.Errorf("%s: %w", op, err), http.StatusInternalServerError)
+		}
+		if len(conflicts) > 0 {
+			return errlib.NewErrorWithDetail(fmt.Errorf("%s: granting role %s to user %s breaks separation of duties set %s", op, req.RoleID.String(), req.RequesterID.String(), conflicts[0].SetID), http.StatusConflict, authz.ConflictDetail(conflicts[0]))
+		}
+	}
+
+	err = qtx.DecideAccessRequest(ctx, &queries.DecideAccessRequestParams{
+		Status:       string(next),
+		DecidedBy:    deciderID,
+		DecisionNote: pgtype.Text{String: note, Valid: note != ""},
+		GrantedUntil: grantedUntil,
+		ID:           requestID,
+		TenantID:     tenantID,
+	})
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("%s: failed to update access request: %w", op, err), http.StatusInternalServerError)
+	}
+
+	decider, err := qtx.GetUserByID(ctx, deciderID)
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("%s: failed to get decider: %w", op, err), http.StatusInternalServerError)
+	}
+
+	if authz.TenantHasFeature(ctx, authz.FeatureAuditLog) {
+		r := middleware.GetHTTPRequest(ctx)
+		meta := map[string]string{
+			"actor_name":        decider.Name,
+			"actor_email":       decider.Email,
+			"role_id":           req.RoleID.String(),
+			"role_name":         req.RoleName,
+			"target_user_name":  req.RequesterName,
+			"target_user_email": req.RequesterEmail,
+		}
+		action := auditlog.ActionDenied
+		if next == accessrequest.StatusApproved {
+			action = auditlog.ActionApproved
+			meta["granted_until"] = grantedUntil.Time.Format(time.RFC3339)
+		}
+		if note != "" {
+			meta["note"] = note
+		}
+		metadata, _ := json.Marshal(meta)
+
+		ipAddr, _ := netip.ParseAddr(iputils.ExtractClientIP(r))
+		err = qtx.InsertAuditLog(ctx, &queries.InsertAuditLogParams{
+			TenantID:     tenantID,
+			ActorID:      deciderID,
+			ResourceType: string(auditlog.ResourceAccessRequest),
+			Action:       string(action),
+			Outcome:      string(auditlog.OutcomeSuccess),
+			ResourceID:   pgtype.Text{String: requestID.String(), Valid: true},
+			Metadata:     metadata,
+			IpAddress:    &ipAddr,
+			UserAgent:    pgtype.Text{String: r.UserAgent(), Valid: true},
+		})
+		if err != nil {
+			return errlib.NewError(fmt.Errorf("%s: failed to insert audit log: %w", op, err), http.StatusInternalServerError)
+		}
+	}
+
+	if err := h.sendDecisionNotification(req, decider.Name, next, grantedUntil, note); err != nil {
+		return errlib.NewError(fmt.Errorf("%s: failed to notify requester: %w", op, err), http.StatusInternalServerError)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return errlib.NewError(fmt.Errorf("%s: failed to commit transaction: %w", op, err), http.StatusInternalServerError)
+	}
+	if next == accessrequest.StatusApproved {
+		authz.InvalidateTenantPermissions(tenantID)
+	}
+
+	return nil
+}
+
+func (h *AccessRequestsHandler) sendDecisionNotification(req *queries.GetAccessRequestForDecisionRow, deciderName string, next accessrequest.Status, grantedUntil pgtype.Timestamptz, note string) error {
+	to := []sendgridlib.SendGridEmailAddress{{Email: req.RequesterEmail, Name: req.RequesterName}}
+	link := fmt.Sprintf("%s/settings/access-requests", h.env.FrontendURL)
+
+	var subject, result string
+	if next == accessrequest.StatusApproved {
+		subject = fmt.Sprintf("「%s」ロールのアクセス申請が承認されました", req.RoleName)
+		result = fmt.Sprintf("%sさんが申請を承認しました。「%s」ロールは%sまで利用できます。", deciderName, req.RoleName, grantedUntil.Time.In(jst).Format("2006/01/02 15:04"))
+	} else {
+		subject = fmt.Sprintf("「%s」ロールのアクセス申請が却下されました", req.RoleName)
+		result = fmt.Sprintf("%sさんが申請を却下しました。", deciderName)
+	}
+
+	plainTextContent := fmt.Sprintf("%s様、\n\n%s\n", req.RequesterName, result)
+	htmlContent := fmt.Sprintf("<p>%s様</p>\n\t<p>%s</p>", html.EscapeString(req.RequesterName), html.EscapeString(result))
+	if note != "" {
+		plainTextContent += fmt.Sprintf("\nコメント：\n%s\n", note)
+		htmlContent += fmt.Sprintf("\n\t<p>コメント：<br>%s</p>", html.EscapeString(note))
+	}
+	plainTextContent += fmt.Sprintf("\n申請の状況は以下のリンクから確認できます。\n%s", link)
+	htmlContent += fmt.Sprintf("\n\t<p><a href=\"%s\">申請を確認する</a></p>", link)
+
+	return h.sendMail(to, subject, plainTextContent, htmlContent)
+}
+
+// jst is the display time zone for dates in notification emails.
+var jst = time.FixedZone("JST", 9*60*60)
@@ -0,0 +1,61 @@
+// Feature doc: docs/features/access-requests.md
+package access_requests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	libctx "dislyze/jirachi/ctx"
+	"dislyze/jirachi/errlib"
+	"lugia/queries"
+)
+
+var GetAccessRequestsOp = huma.Operation{
+	OperationID: "get-access-requests",
+	Method:      http.MethodGet,
+	Path:        "/access-requests",
+}
+
+type GetAccessRequestsResponse struct {
+	Requests []AccessRequestInfo `json:"requests" nullable:"false"`
+}
+
+type GetAccessRequestsInput struct {
+	Status string `query:"status" enum:"pending,approved,denied,expired"`
+}
+
+type GetAccessRequestsOutput struct {
+	Body GetAccessRequestsResponse
+}
+
+func (h *AccessRequestsHandler) GetAccessRequests(ctx context.Context, input *GetAccessRequestsInput) (*GetAccessRequestsOutput, error) {
+	response, err := h.getAccessRequests(ctx, input.Status)
+	if err != nil {
+		return nil, err
+	}
+	return &GetAccessRequestsOutput{Body: *response}, nil
+}
+
+func (h *AccessRequestsHandler) getAccessRequests(ctx context.Context, status string) (*GetAccessRequestsResponse, error) {
+	tenantID := libctx.GetTenantID(ctx)
+
+	rows, err := h.q.ListAccessRequests(ctx, &queries.ListAccessRequestsParams{
+		TenantID: tenantID,
+		Status:   status,
+	})
+	if err != nil {
+		return nil, errlib.NewError(fmt.Errorf("GetAccessRequests: failed to list access requests: %w", err), http.StatusInternalServerError)
+	}
+
+	response := &GetAccessRequestsResponse{
+		Requests: make([]AccessRequestInfo, 0, len(rows)),
+	}
+	for _, row := range rows {
+		response.Requests = append(response.Requests, toAccessRequestInfo(row))
+	}
+
+	return response, nil
+}
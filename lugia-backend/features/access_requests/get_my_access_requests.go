@@ -0,0 +1,86 @@
+// Feature doc: docs/features/access-requests.md
+package access_requests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	libctx "dislyze/jirachi/ctx"
+	"dislyze/jirachi/errlib"
+	"lugia/lib/authz"
+	"lugia/queries"
+)
+
+var GetMyAccessRequestsOp = huma.Operation{
+	OperationID: "get-my-access-requests",
+	Method:      http.MethodGet,
+	Path:        "/me/access-requests",
+}
+
+type RequestableRole struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type GetMyAccessRequestsResponse struct {
+	Requests         []AccessRequestInfo `json:"requests" nullable:"false"`
+	RequestableRoles []RequestableRole   `json:"requestable_roles" nullable:"false"`
+}
+
+type GetMyAccessRequestsInput struct{}
+
+type GetMyAccessRequestsOutput struct {
+	Body GetMyAccessRequestsResponse
+}
+
+func (h *AccessRequestsHandler) GetMyAccessRequests(ctx context.Context, input *GetMyAccessRequestsInput) (*GetMyAccessRequestsOutput, error) {
+	response, err := h.getMyAccessRequests(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &GetMyAccessRequestsOutput{Body: *response}, nil
+}
+
+func (h *AccessRequestsHandler) getMyAccessRequests(ctx context.Context) (*GetMyAccessRequestsResponse, error) {
+	tenantID := libctx.GetTenantID(ctx)
+	userID := libctx.GetUserID(ctx)
+
+	rows, err := h.q.ListAccessRequests(ctx, &queries.ListAccessRequestsParams{
+		TenantID:    tenantID,
+		RequesterID: userID,
+		Status:      "",
+	})
+	if err != nil {
+		return nil, errlib.NewError(fmt.Errorf("GetMyAccessRequests: failed to list access requests for user %s: %w", userID.String(), err), http.StatusInternalServerError)
+	}
+
+	roleRows, err := h.q.GetRequestableRoles(ctx, &queries.GetRequestableRolesParams{
+		TenantID:    tenantID,
+		RbacEnabled: authz.TenantHasFeature(ctx, authz.FeatureRBAC),
+		UserID:      userID,
+	})
+	if err != nil {
+		return nil, errlib.NewError(fmt.Errorf("GetMyAccessRequests: failed to get requestable roles: %w", err), http.StatusInternalServerError)
+	}
+
+	response := &GetMyAccessRequestsResponse{
+		Requests:         make([]AccessRequestInfo, 0, len(rows)),
+		RequestableRoles: make([]RequestableRole, 0, len(roleRows)),
+	}
+	for _, row := range rows {
+		response.Requests = append(response.Requests, toAccessRequestInfo(row))
+	}
+	for _, row := range roleRows {
+		response.RequestableRoles = append(response.RequestableRoles, RequestableRole{
+			ID:          row.ID.String(),
+			Name:        row.Name,
+			Description: row.Description.String,
+		})
+	}
+
+	return response, nil
+}
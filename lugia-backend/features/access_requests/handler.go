@@ -0,0 +1,115 @@
+// Feature doc: docs/features/access-requests.md
+package access_requests
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sendgrid/sendgrid-go"
+
+	"dislyze/jirachi/sendgridlib"
+	"lugia/lib/config"
+	"lugia/queries"
+)
+
+type AccessRequestsHandler struct {
+	dbConn *pgxpool.Pool
+	q      *queries.Queries
+	env    *config.Env
+}
+
+func NewAccessRequestsHandler(dbConn *pgxpool.Pool, q *queries.Queries, env *config.Env) *AccessRequestsHandler {
+	return &AccessRequestsHandler{
+		dbConn: dbConn,
+		q:      q,
+		env:    env,
+	}
+}
+
+type AccessRequestInfo struct {
+	ID             string  `json:"id"`
+	RequesterID    string  `json:"requester_id"`
+	RequesterName  string  `json:"requester_name"`
+	RequesterEmail string  `json:"requester_email"`
+	RoleID         string  `json:"role_id"`
+	RoleName       string  `json:"role_name"`
+	Justification  string  `json:"justification"`
+	DurationHours  int32   `json:"duration_hours"`
+	Status         string  `json:"status" enum:"pending,approved,denied,expired"`
+	ExpiresAt      string  `json:"expires_at"`
+	DecidedByName  *string `json:"decided_by_name"`
+	DecidedAt      *string `json:"decided_at"`
+	DecisionNote   *string `json:"decision_note"`
+	GrantedUntil   *string `json:"granted_until"`
+	CreatedAt      string  `json:"created_at"`
+}
+
+func toAccessRequestInfo(row *queries.ListAccessRequestsRow) AccessRequestInfo {
+	info := AccessRequestInfo{
+		ID:             row.ID.String(),
+		RequesterID:    row.RequesterID.String(),
+		RequesterName:  row.RequesterName,
+		RequesterEmail: row.RequesterEmail,
+		RoleID:         row.RoleID.String(),
+		RoleName:       row.RoleName,
+		Justification:  row.Justification,
+		DurationHours:  row.DurationHours,
+		Status:         row.Status,
+		ExpiresAt:      row.ExpiresAt.Time.Format(time.RFC3339),
+		CreatedAt:      row.CreatedAt.Time.Format(time.RFC3339),
+	}
+	if row.DecidedByName.Valid {
+		info.DecidedByName = &row.DecidedByName.String
+	}
+	if row.DecidedAt.Valid {
+		decidedAt := row.DecidedAt.Time.Format(time.RFC3339)
+		info.DecidedAt = &decidedAt
+	}
+	if row.DecisionNote.Valid {
+		info.DecisionNote = &row.DecisionNote.String
+	}
+	if row.GrantedUntil.Valid {
+		grantedUntil := row.GrantedUntil.Time.Format(time.RFC3339)
+		info.GrantedUntil = &grantedUntil
+	}
+	return info
+}
+
+// sendMail sends one message per recipient so approvers don't see each
+// other's addresses.
+func (h *AccessRequestsHandler) sendMail(to []sendgridlib.SendGridEmailAddress, subject, plainTextContent, htmlContent string) error {
+	personalizations := make([]sendgridlib.SendGridPersonalization, 0, len(to))
+	for _, recipient := range to {
+		personalizations = append(personalizations, sendgridlib.SendGridPersonalization{
+			To:      []sendgridlib.SendGridEmailAddress{recipient},
+			Subject: subject,
+		})
+	}
+
+	sgMailBody := sendgridlib.SendGridMailRequestBody{
+		Personalizations: personalizations,
+		From:             sendgridlib.SendGridEmailAddress{Email: sendgridlib.SendGridFromEmail, Name: sendgridlib.SendGridFromName},
+		Content:          []sendgridlib.SendGridContent{{Type: "text/plain", Value: plainTextContent}, {Type: "text/html", Value: htmlContent}},
+	}
+
+	bodyBytes, err := json.Marshal(sgMailBody)
+	if err != nil {
+		return fmt.Errorf("failed to marshal SendGrid request body: %w", err)
+	}
+
+	sendgridRequest := sendgrid.GetRequest(h.env.SendgridAPIKey, "/v3/mail/send", h.env.SendgridAPIUrl)
+	sendgridRequest.Method = "POST"
+	sendgridRequest.Body = bodyBytes
+	sgResponse, err := sendgrid.API(sendgridRequest)
+	if err != nil {
+		return fmt.Errorf("SendGrid API call failed: %w", err)
+	}
+
+	if sgResponse.StatusCode < 200 || sgResponse.StatusCode >= 300 {
+		return fmt.Errorf("SendGrid returned error status code %d. Body: %s", sgResponse.StatusCode, sgResponse.Body)
+	}
+
+	return nil
+}
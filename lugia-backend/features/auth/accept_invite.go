@@ -16,6 +16,7 @@ import (
 
 	"dislyze/jirachi/errlib"
 	"dislyze/jirachi/jwt"
+	"lugia/lib/iputils"
 	"lugia/lib/middleware"
 	"lugia/queries"
 )
@@ -106,7 +107,7 @@ func (h *AuthHandler) acceptInvite(ctx context.Context, req AcceptInviteRequestB
 		return nil, errlib.NewError(fmt.Errorf("AcceptInvite: GetUserByID failed: %w", err), http.StatusInternalServerError)
 	}
 
-	tenant, err := qtx.GetTenantByID(ctx, dbUser.TenantID)
+	tenant, err := qtx.GetTenantByID(ctx, invitationTokenRecord.TenantID)
 	if err != nil {
 		return nil, errlib.NewError(fmt.Errorf("AcceptInvite: failed to get tenant: %w", err), http.StatusInternalServerError)
 	}
@@ -146,8 +147,9 @@ func (h *AuthHandler) acceptInvite(ctx context.Context, req AcceptInviteRequestB
 		UserID:     dbUser.ID,
 		Jti:        tokenPair.JTI,
 		DeviceInfo: pgtype.Text{String: r.UserAgent(), Valid: true},
-		IpAddress:  pgtype.Text{String: r.RemoteAddr, Valid: true},
+		IpAddress:  pgtype.Text{String: iputils.ExtractClientIP(r), Valid: true},
 		ExpiresAt:  pgtype.Timestamptz{Time: time.Now().Add(7 * 24 * time.Hour), Valid: true},
+		TenantID:   invitationTokenRecord.TenantID,
 	})
 	if err != nil {
 		return nil, errlib.NewError(fmt.Errorf("AcceptInvite: failed to store refresh token: %w", err), http.StatusInternalServerError)
@@ -44,8 +44,8 @@ type ForgotPasswordRequestBody struct {
 func (h *AuthHandler) ForgotPassword(ctx context.Context, input *ForgotPasswordInput) (*struct{}, error) {
 	r := middleware.GetHTTPRequest(ctx)
 
-	if !h.rateLimiter.Allow(r.RemoteAddr, r) {
-		errlib.LogError(fmt.Errorf("rate limit exceeded for forgot password: %s", r.RemoteAddr))
+	if !h.rateLimiter.Allow(iputils.ExtractClientIP(r), r) {
+		errlib.LogError(fmt.Errorf("rate limit exceeded for forgot password: %s", iputils.ExtractClientIP(r)))
 		// Always return success for security (prevent email enumeration)
 		return nil, nil
 	}
@@ -44,7 +44,7 @@ func (h *AuthHandler) Login(ctx context.Context, input *LoginInput) (*struct{},
 	r := middleware.GetHTTPRequest(ctx)
 	w := middleware.GetResponseWriter(ctx)
 
-	if !h.rateLimiter.Allow(r.RemoteAddr, r) {
+	if !h.rateLimiter.Allow(iputils.ExtractClientIP(r), r) {
 		return nil, errlib.NewErrorWithDetail(fmt.Errorf("rate limit exceeded for login"), http.StatusTooManyRequests, "試行回数が上限を超えました。お手数ですが、しばらく時間をおいてから再度お試しください。")
 	}
 
@@ -113,7 +113,12 @@ func (h *AuthHandler) login(ctx context.Context, req *LoginRequestBody, r *http.
 		return nil, user.ID.String(), fmt.Errorf("アカウントが停止されています。サポートにお問い合わせください。")
 	}
 
-	tenant, err := h.queries.GetTenantByID(ctx, user.TenantID)
+	tenantID, err := h.passwordLoginTenant(ctx, user)
+	if err != nil {
+		return nil, user.ID.String(), err
+	}
+
+	tenant, err := h.queries.GetTenantByID(ctx, tenantID)
 	if err != nil {
 		return nil, user.ID.String(), fmt.Errorf("failed to get tenant: %w", err)
 	}
@@ -128,6 +133,13 @@ func (h *AuthHandler) login(ctx context.Context, req *LoginRequestBody, r *http.
 		return nil, user.ID.String(), fmt.Errorf("メールアドレスまたはパスワードが正しくありません")
 	}
 
+	if err := middleware.CheckSignInIPWhitelist(r, h.queries, tenant.ID, user.ID); err != nil {
+		if errlib.Is(err, middleware.ErrIPNotWhitelisted) {
+			return nil, user.ID.String(), fmt.Errorf("このIPアドレスからのログインは許可されていません。")
+		}
+		return nil, user.ID.String(), fmt.Errorf("failed to check IP whitelist: %w", err)
+	}
+
 	tx, err := h.dbConn.Begin(ctx)
 	if err != nil {
 		return nil, user.ID.String(), fmt.Errorf("failed to start transaction: %w", err)
@@ -161,13 +173,18 @@ func (h *AuthHandler) login(ctx context.Context, req *LoginRequestBody, r *http.
 		UserID:     user.ID,
 		Jti:        tokenPair.JTI,
 		DeviceInfo: pgtype.Text{String: r.UserAgent(), Valid: true},
-		IpAddress:  pgtype.Text{String: r.RemoteAddr, Valid: true},
+		IpAddress:  pgtype.Text{String: iputils.ExtractClientIP(r), Valid: true},
 		ExpiresAt:  pgtype.Timestamptz{Time: time.Now().Add(7 * 24 * time.Hour), Valid: true},
+		TenantID:   tenant.ID,
 	})
 	if err != nil {
 		return nil, user.ID.String(), fmt.Errorf("failed to store refresh token: %w", err)
 	}
 
+	if err := qtx.UpdateUserLastLoginAt(ctx, user.ID); err != nil {
+		return nil, user.ID.String(), fmt.Errorf("failed to update last login: %w", err)
+	}
+
 	if err := h.insertLoginAuditLogTx(ctx, r, qtx, tenant, user, auditlog.OutcomeSuccess, ""); err != nil {
 		return nil, user.ID.String(), fmt.Errorf("failed to insert audit log: %w", err)
 	}
@@ -178,6 +195,23 @@ func (h *AuthHandler) login(ctx context.Context, req *LoginRequestBody, r *http.
 	return tokenPair, user.ID.String(), nil
 }
 
+// passwordLoginTenant picks the tenant a password login starts in: the home
+// tenant, or the first other membership that accepts passwords when the home
+// tenant is SSO-only. Falls back to the home tenant when no membership accepts
+// passwords so the caller reports the SSO-only error against it.
+func (h *AuthHandler) passwordLoginTenant(ctx context.Context, user *queries.User) (pgtype.UUID, error) {
+	memberships, err := h.queries.ListTenantMembershipsForUser(ctx, user.ID)
+	if err != nil {
+		return pgtype.UUID{}, fmt.Errorf("failed to list tenant memberships: %w", err)
+	}
+	for _, m := range memberships {
+		if m.AuthMethod != "sso" {
+			return m.ID, nil
+		}
+	}
+	return user.TenantID, nil
+}
+
 // insertLoginAuditLog inserts an audit log for login events outside a transaction.
 // Used for failure paths where the tenant is already loaded.
 func (h *AuthHandler) insertLoginAuditLog(ctx context.Context, r *http.Request, tenant *queries.Tenant, user *queries.User, outcome auditlog.Outcome, reason string) {
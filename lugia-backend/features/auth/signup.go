@@ -15,6 +15,7 @@ import (
 	"dislyze/jirachi/errlib"
 	"dislyze/jirachi/jwt"
 	"lugia/lib/authz"
+	"lugia/lib/iputils"
 	"lugia/lib/middleware"
 	"lugia/queries"
 )
@@ -48,7 +49,7 @@ func (h *AuthHandler) Signup(ctx context.Context, input *SignupInput) (*struct{}
 	r := middleware.GetHTTPRequest(ctx)
 	w := middleware.GetResponseWriter(ctx)
 
-	if !h.rateLimiter.Allow(r.RemoteAddr, r) {
+	if !h.rateLimiter.Allow(iputils.ExtractClientIP(r), r) {
 		return nil, errlib.NewErrorWithDetail(fmt.Errorf("rate limit exceeded for signup"), http.StatusTooManyRequests, "試行回数が上限を超えました。お手数ですが、しばらく時間をおいてから再度お試しください。")
 	}
 
@@ -128,7 +129,7 @@ func (h *AuthHandler) signup(ctx context.Context, req *SignupRequestBody, r *htt
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
-	_, err = h.setupDefaultRoles(ctx, qtx, tenant.ID, user.ID)
+	_, err = h.setupDefaultRoles(ctx, qtx, tenant.ID, user.ID, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to setup default roles: %w", err)
 	}
@@ -142,8 +143,9 @@ func (h *AuthHandler) signup(ctx context.Context, req *SignupRequestBody, r *htt
 		UserID:     user.ID,
 		Jti:        tokenPair.JTI,
 		DeviceInfo: pgtype.Text{String: r.UserAgent(), Valid: true},
-		IpAddress:  pgtype.Text{String: r.RemoteAddr, Valid: true},
+		IpAddress:  pgtype.Text{String: iputils.ExtractClientIP(r), Valid: true},
 		ExpiresAt:  pgtype.Timestamptz{Time: time.Now().Add(7 * 24 * time.Hour), Valid: true},
+		TenantID:   tenant.ID,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to store refresh token: %w", err)
@@ -156,7 +158,10 @@ func (h *AuthHandler) signup(ctx context.Context, req *SignupRequestBody, r *htt
 	return tokenPair, nil
 }
 
-func (h *AuthHandler) setupDefaultRoles(ctx context.Context, qtx *queries.Queries, tenantID pgtype.UUID, userID pgtype.UUID) (pgtype.UUID, error) {
+// setupDefaultRoles creates the default roles, assigns 管理者 to userID and
+// creates a custom role from each of templateKeys, which must already have
+// been validated.
+func (h *AuthHandler) setupDefaultRoles(ctx context.Context, qtx *queries.Queries, tenantID pgtype.UUID, userID pgtype.UUID, templateKeys []string) (pgtype.UUID, error) {
 	permissions, err := qtx.GetAllPermissions(ctx)
 	if err != nil {
 		return pgtype.UUID{}, fmt.Errorf("failed to get permissions: %w", err)
@@ -174,7 +179,7 @@ func (h *AuthHandler) setupDefaultRoles(ctx context.Context, qtx *queries.Querie
 
 	var permissionIDs []pgtype.UUID
 	for _, permission := range permissions {
-		if permission.Action == authz.ActionEdit {
+		if permission.Action != authz.ActionView {
 			permissionIDs = append(permissionIDs, permission.ID)
 		}
 	}
@@ -217,5 +222,36 @@ func (h *AuthHandler) setupDefaultRoles(ctx context.Context, qtx *queries.Querie
 		return pgtype.UUID{}, fmt.Errorf("failed to assign admin role to user: %w", err)
 	}
 
+	for _, key := range templateKeys {
+		template, ok := authz.LookupRoleTemplate(key)
+		if !ok {
+			return pgtype.UUID{}, fmt.Errorf("unknown role template %s", key)
+		}
+
+		templatePermissionIDs, err := template.PermissionIDs(permissions)
+		if err != nil {
+			return pgtype.UUID{}, err
+		}
+
+		role, err := qtx.CreateRole(ctx, &queries.CreateRoleParams{
+			TenantID:    tenantID,
+			Name:        template.Name,
+			Description: pgtype.Text{String: template.Description, Valid: true},
+			IsDefault:   false,
+		})
+		if err != nil {
+			return pgtype.UUID{}, fmt.Errorf("failed to create role from template %s: %w", key, err)
+		}
+
+		err = qtx.CreateRolePermissionsBulk(ctx, &queries.CreateRolePermissionsBulkParams{
+			RoleID:        role.ID,
+			PermissionIds: templatePermissionIDs,
+			TenantID:      tenantID,
+		})
+		if err != nil {
+			return pgtype.UUID{}, fmt.Errorf("failed to assign permissions to role from template %s: %w", key, err)
+		}
+	}
+
 	return adminRole.ID, nil
 }
@@ -22,6 +22,8 @@ import (
 	"dislyze/jirachi/jwt"
 	"dislyze/jirachi/logger"
 	"dislyze/jirachi/responder"
+	"lugia/lib/iputils"
+	"lugia/lib/middleware"
 	"lugia/queries"
 
 	"github.com/crewjam/saml"
@@ -272,6 +274,14 @@ func (h *AuthHandler) handleSSOCallback(ctx context.Context, samlResponseBase64
 			return nil, "", fmt.Errorf("failed to assign default role: %w", err)
 		}
 
+		// The whitelist is checked before the account is committed, so a
+		// sign-in from a blocked address provisions nobody. The new user's
+		// scopes come from the default role assigned above; the ip_blocked
+		// audit entry goes with the rollback, the access log keeps the block.
+		if userErr, err := checkSSOSignInIPWhitelist(r, qtx, ssoRequest.TenantID, user.ID); err != nil {
+			return nil, userErr, err
+		}
+
 		if err := tx.Commit(ctx); err != nil {
 			return nil, "", fmt.Errorf("failed to commit transaction: %w", err)
 		}
@@ -289,8 +299,29 @@ func (h *AuthHandler) handleSSOCallback(ctx context.Context, samlResponseBase64
 	} else {
 		// User exists
 
-		if user.TenantID != ssoRequest.TenantID {
-			return nil, "", fmt.Errorf("user belongs to different tenant")
+		isMember, err := h.queries.IsTenantMember(ctx, &queries.IsTenantMemberParams{
+			TenantID: ssoRequest.TenantID,
+			UserID:   user.ID,
+		})
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to check tenant membership: %w", err)
+		}
+		if !isMember {
+			return nil, "", fmt.Errorf("user is not a member of the SSO tenant")
+		}
+
+		if user.Status == "suspended" {
+			return nil, "アカウントが停止されています。サポートにお問い合わせください。", fmt.Errorf("account suspended")
+		}
+
+		if tenant.AuthMethod == "password" {
+			return nil, "このアカウントはSSOが無効です。パスワードでログインしてください。", fmt.Errorf("user with auth_method password attempted sso login")
+		}
+
+		// Checked before the account is touched: a blocked sign-in neither
+		// activates the user nor links their IdP identity.
+		if userErr, err := checkSSOSignInIPWhitelist(r, h.queries, tenant.ID, user.ID); err != nil {
+			return nil, userErr, err
 		}
 
 		if user.Status == "pending_verification" {
@@ -303,14 +334,6 @@ func (h *AuthHandler) handleSSOCallback(ctx context.Context, samlResponseBase64
 			}
 		}
 
-		if user.Status == "suspended" {
-			return nil, "アカウントが停止されています。サポートにお問い合わせください。", fmt.Errorf("account suspended")
-		}
-
-		if tenant.AuthMethod == "password" {
-			return nil, "このアカウントはSSOが無効です。パスワードでログインしてください。", fmt.Errorf("user with auth_method password attempted sso login")
-		}
-
 		if !user.ExternalSsoID.Valid || user.ExternalSsoID.String == "" {
 			err = h.queries.UpdateUserExternalSSOID(ctx, &queries.UpdateUserExternalSSOIDParams{
 				ExternalSsoID: pgtype.Text{String: externalSSOID, Valid: true},
@@ -355,31 +378,40 @@ func (h *AuthHandler) handleSSOCallback(ctx context.Context, samlResponseBase64
 		UserID:     user.ID,
 		Jti:        tokenPair.JTI,
 		DeviceInfo: pgtype.Text{String: r.UserAgent(), Valid: true},
-		IpAddress:  pgtype.Text{String: r.RemoteAddr, Valid: true},
+		IpAddress:  pgtype.Text{String: iputils.ExtractClientIP(r), Valid: true},
 		ExpiresAt:  pgtype.Timestamptz{Time: time.Now().Add(7 * 24 * time.Hour), Valid: true},
+		TenantID:   tenant.ID,
 	})
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to create refresh token for user_id %s: %w", user.ID, err)
 	}
 
+	if err := qtx.UpdateUserLastLoginAt(ctx, user.ID); err != nil {
+		return nil, "", fmt.Errorf("failed to update last login for user_id %s: %w", user.ID, err)
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return nil, "", fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	go func() { // #nosec G118 -- intentional: cleanup runs after response is sent, request context would be cancelled
-		cleanupCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
-		if err := h.queries.DeleteExpiredSSORequests(cleanupCtx); err != nil {
-			errlib.LogError(fmt.Errorf("failed to delete expired SSO requests: %w", err))
-		}
-	}()
-
 	return &SSOCallbackResponse{
 		UserID:    user.ID.String(),
 		TokenPair: tokenPair,
 	}, "", nil
 }
 
+// checkSSOSignInIPWhitelist enforces the tenant's whitelist on an SSO
+// sign-in, returning the message shown to the user alongside the error.
+func checkSSOSignInIPWhitelist(r *http.Request, db *queries.Queries, tenantID, userID pgtype.UUID) (string, error) {
+	if err := middleware.CheckSignInIPWhitelist(r, db, tenantID, userID); err != nil {
+		if errlib.Is(err, middleware.ErrIPNotWhitelisted) {
+			return "このIPアドレスからのログインは許可されていません。", fmt.Errorf("sso login for user_id %s from IP not in whitelist", userID)
+		}
+		return "", fmt.Errorf("failed to check IP whitelist for user_id %s: %w", userID, err)
+	}
+	return "", nil
+}
+
 func extractAttribute(statements []saml.AttributeStatement, attributeName string) string {
 	for _, stmt := range statements {
 		for _, attr := range stmt.Attributes {
@@ -18,6 +18,7 @@ import (
 	"dislyze/jirachi/errlib"
 	"dislyze/jirachi/logger"
 	"dislyze/jirachi/responder"
+	"lugia/lib/iputils"
 	"lugia/queries"
 
 	"github.com/crewjam/saml"
@@ -48,7 +49,7 @@ type SSOLoginResponse struct {
 }
 
 func (h *AuthHandler) SSOLogin(w http.ResponseWriter, r *http.Request) {
-	if !h.rateLimiter.Allow(r.RemoteAddr, r) {
+	if !h.rateLimiter.Allow(iputils.ExtractClientIP(r), r) {
 		appErr := errlib.NewErrorWithDetail(fmt.Errorf("rate limit exceeded for SSO login"), http.StatusTooManyRequests, "試行回数が上限を超えました。お手数ですが、しばらく時間をおいてから再度お試しください。")
 		responder.RespondWithError(w, appErr)
 		return
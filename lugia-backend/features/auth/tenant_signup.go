@@ -19,6 +19,8 @@ import (
 	"dislyze/jirachi/errlib"
 	jirachijwt "dislyze/jirachi/jwt"
 	"dislyze/jirachi/logger"
+	"lugia/lib/authz"
+	"lugia/lib/iputils"
 	"lugia/lib/middleware"
 	"lugia/queries"
 )
@@ -39,6 +41,9 @@ type TenantSignupRequestBody struct {
 	PasswordConfirm string `json:"password_confirm"`
 	CompanyName     string `json:"company_name"`
 	UserName        string `json:"user_name"`
+	// RoleTemplates are keys of built-in role templates to create as custom
+	// roles next to the default ones.
+	RoleTemplates []string `json:"role_templates,omitempty"`
 }
 
 type SSOConfig struct {
@@ -79,7 +84,7 @@ func (r *TenantSignupRequestBody) ValidatePasswordSignup() error {
 		return fmt.Errorf("user_name is required")
 	}
 
-	return nil
+	return r.validateRoleTemplates()
 }
 
 func (r *TenantSignupRequestBody) ValidateSSOSignup() error {
@@ -94,6 +99,20 @@ func (r *TenantSignupRequestBody) ValidateSSOSignup() error {
 		return fmt.Errorf("user_name is required")
 	}
 
+	return r.validateRoleTemplates()
+}
+
+func (r *TenantSignupRequestBody) validateRoleTemplates() error {
+	seen := map[string]bool{}
+	for _, key := range r.RoleTemplates {
+		if _, ok := authz.LookupRoleTemplate(key); !ok {
+			return fmt.Errorf("unknown role template %s", key)
+		}
+		if seen[key] {
+			return fmt.Errorf("role template %s is listed twice", key)
+		}
+		seen[key] = true
+	}
 	return nil
 }
 
@@ -101,7 +120,7 @@ func (h *AuthHandler) TenantSignup(ctx context.Context, input *TenantSignupInput
 	r := middleware.GetHTTPRequest(ctx)
 	w := middleware.GetResponseWriter(ctx)
 
-	if !h.rateLimiter.Allow(r.RemoteAddr, r) {
+	if !h.rateLimiter.Allow(iputils.ExtractClientIP(r), r) {
 		return nil, errlib.NewErrorWithDetail(fmt.Errorf("rate limit exceeded for tenant signup"), http.StatusTooManyRequests, "試行回数が上限を超えました。お手数ですが、しばらく時間をおいてから再度お試しください。")
 	}
 
@@ -243,7 +262,7 @@ func (h *AuthHandler) passwordTenantSignup(ctx context.Context, req *TenantSignu
 		return nil, fmt.Errorf("failed to create internal user %w", err)
 	}
 
-	adminRoleID, err := h.setupDefaultRoles(ctx, qtx, tenant.ID, user.ID)
+	adminRoleID, err := h.setupDefaultRoles(ctx, qtx, tenant.ID, user.ID, req.RoleTemplates)
 	if err != nil {
 		return nil, fmt.Errorf("failed to setup default roles: %w", err)
 	}
@@ -266,8 +285,9 @@ func (h *AuthHandler) passwordTenantSignup(ctx context.Context, req *TenantSignu
 		UserID:     user.ID,
 		Jti:        tokenPair.JTI,
 		DeviceInfo: pgtype.Text{String: r.UserAgent(), Valid: true},
-		IpAddress:  pgtype.Text{String: r.RemoteAddr, Valid: true},
+		IpAddress:  pgtype.Text{String: iputils.ExtractClientIP(r), Valid: true},
 		ExpiresAt:  pgtype.Timestamptz{Time: time.Now().Add(7 * 24 * time.Hour), Valid: true},
+		TenantID:   tenant.ID,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to store refresh token: %w", err)
@@ -359,7 +379,7 @@ func (h *AuthHandler) ssoTenantSignup(ctx context.Context, req *TenantSignupRequ
 		return fmt.Errorf("failed to create internal user: %w", err)
 	}
 
-	adminRoleID, err := h.setupDefaultRoles(ctx, qtx, tenant.ID, user.ID)
+	adminRoleID, err := h.setupDefaultRoles(ctx, qtx, tenant.ID, user.ID, req.RoleTemplates)
 	if err != nil {
 		return fmt.Errorf("failed to setup default roles: %w", err)
 	}
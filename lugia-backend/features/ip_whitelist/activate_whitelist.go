@@ -23,6 +23,7 @@ import (
 	"dislyze/jirachi/sendgridlib"
 	"dislyze/jirachi/utils"
 	"lugia/lib/authz"
+	"lugia/lib/geoip"
 	"lugia/lib/iputils"
 	"lugia/lib/middleware"
 	"lugia/queries"
@@ -100,6 +101,7 @@ func (h *IPWhitelistHandler) activateWhitelist(ctx context.Context, req Activate
 	}
 
 	currentFeatures.IPWhitelist.Active = true
+	currentFeatures.IPWhitelist.Monitor = false
 
 	updatedFeaturesJSON, err := json.Marshal(currentFeatures)
 	if err != nil {
@@ -167,13 +169,20 @@ func (h *IPWhitelistHandler) activateWhitelist(ctx context.Context, req Activate
 	return "", nil
 }
 
+// validateActivationSafety reports whether the tenant rules would admit
+// userIP: it matches a CIDR rule or is located in an allowed country, and
+// isn't located in a denied one.
 func (h *IPWhitelistHandler) validateActivationSafety(ctx context.Context, tenantID pgtype.UUID, userIP string) (bool, error) {
 	existingCIDRs, err := h.q.GetTenantIPWhitelistCIDRs(ctx, tenantID)
 	if err != nil {
 		return false, err
 	}
+	countries, err := h.q.GetTenantIPWhitelistCountries(ctx, tenantID)
+	if err != nil {
+		return false, err
+	}
 
-	if len(existingCIDRs) == 0 {
+	if len(existingCIDRs) == 0 && len(countries) == 0 {
 		return false, nil
 	}
 
@@ -181,6 +190,24 @@ func (h *IPWhitelistHandler) validateActivationSafety(ctx context.Context, tenan
 	if err != nil {
 		return false, err
 	}
+	if len(countries) == 0 {
+		return isAllowed, nil
+	}
+
+	addr, err := netip.ParseAddr(userIP)
+	if err != nil {
+		return false, err
+	}
+	userCountry := geoip.Country(addr.Unmap())
+	for _, country := range countries {
+		if country.CountryCode != userCountry {
+			continue
+		}
+		if country.Action == middleware.CountryRuleDeny {
+			return false, nil
+		}
+		isAllowed = true
+	}
 
 	return isAllowed, nil
 }
@@ -190,7 +217,12 @@ func (h *IPWhitelistHandler) createEmergencyTokenAndSendEmail(ctx context.Contex
 	if err != nil {
 		return fmt.Errorf("failed to generate emergency token: %w", err)
 	}
-	_, err = qtx.CreateIPWhitelistEmergencyToken(ctx, jti)
+	_, err = qtx.CreateIPWhitelistEmergencyToken(ctx, &queries.CreateIPWhitelistEmergencyTokenParams{
+		Jti:       jti,
+		TenantID:  tenantID,
+		UserID:    userID,
+		ExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(emergencyTokenTTL), Valid: true},
+	})
 	if err != nil {
 		return fmt.Errorf("failed to create emergency token record: %w", err)
 	}
@@ -258,6 +290,9 @@ type EmergencyClaims struct {
 	jwt.RegisteredClaims
 }
 
+// emergencyTokenTTL is how long an emergency deactivation link stays usable.
+const emergencyTokenTTL = 30 * time.Minute
+
 func GenerateEmergencyToken(userID, tenantID pgtype.UUID, secret []byte) (string, pgtype.UUID, error) {
 	if len(secret) == 0 {
 		return "", pgtype.UUID{}, fmt.Errorf("secret cannot be empty")
@@ -276,7 +311,7 @@ func GenerateEmergencyToken(userID, tenantID pgtype.UUID, secret []byte) (string
 		JTI:      jti,
 		RegisteredClaims: jwt.RegisteredClaims{
 			IssuedAt:  jwt.NewNumericDate(now),
-			ExpiresAt: jwt.NewNumericDate(now.Add(30 * time.Minute)),
+			ExpiresAt: jwt.NewNumericDate(now.Add(emergencyTokenTTL)),
 			NotBefore: jwt.NewNumericDate(now),
 		},
 	}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/netip"
+	"time"
 
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/jackc/pgx/v5"
@@ -35,23 +36,39 @@ type AddIPInput struct {
 type AddIPToWhitelistRequest struct {
 	IPAddress string  `json:"ip_address" minLength:"1"`
 	Label     *string `json:"label" maxLength:"255"`
+	// ExpiresAt makes the rule temporary, e.g. for a contractor's network.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// ScopeID adds the rule to a role or user scope instead of the tenant.
+	ScopeID *string `json:"scope_id,omitempty"`
+	// ConfirmBroad stores a broad tenant rule (see iputils.IsBroadPrefix).
+	// Without it such a rule is refused with 409 and confirmBroadRequired.
+	ConfirmBroad bool `json:"confirm_broad,omitempty"`
 }
 
+// confirmBroadRequired is the error detail of an unconfirmed broad rule. The
+// settings page matches on it to ask before sending the rule again.
+const confirmBroadRequired = "confirm_broad required"
+
 func (r *AddIPToWhitelistRequest) Resolve(ctx huma.Context) []error {
 	if _, err := iputils.ValidateCIDR(r.IPAddress); err != nil {
 		return []error{fmt.Errorf("invalid IP address or CIDR: %w", err)}
 	}
+	if r.ExpiresAt != nil && !r.ExpiresAt.After(time.Now()) {
+		return []error{fmt.Errorf("expires_at must be in the future")}
+	}
 	return nil
 }
 
 func (h *IPWhitelistHandler) AddIPToWhitelist(ctx context.Context, input *AddIPInput) (*struct{}, error) {
-	err := h.addIPToWhitelist(ctx, input.Body)
-	if err != nil {
+	if err := h.addIPToWhitelist(ctx, input.Body); err != nil {
 		return nil, err
 	}
 	return nil, nil
 }
 
+// addIPToWhitelist stores the rule, unless it is a broad tenant rule that
+// hasn't been confirmed. A broad scope rule only narrows the tenant rules, so
+// it needs no confirmation.
 func (h *IPWhitelistHandler) addIPToWhitelist(ctx context.Context, req AddIPToWhitelistRequest) error {
 	tenantID := libctx.GetTenantID(ctx)
 	userID := libctx.GetUserID(ctx)
@@ -66,9 +83,34 @@ func (h *IPWhitelistHandler) addIPToWhitelist(ctx context.Context, req AddIPToWh
 		return errlib.NewError(err, http.StatusBadRequest)
 	}
 
+	if req.ScopeID == nil && !req.ConfirmBroad && iputils.IsBroadPrefix(prefix) {
+		return errlib.NewErrorWithDetail(fmt.Errorf("AddIPToWhitelist: broad prefix %s not confirmed", normalizedCIDR), http.StatusConflict, confirmBroadRequired)
+	}
+
+	var scopeID pgtype.UUID
+	if req.ScopeID != nil {
+		if err := scopeID.Scan(*req.ScopeID); err != nil {
+			return errlib.NewError(fmt.Errorf("AddIPToWhitelist: invalid scope ID format: %w", err), http.StatusBadRequest)
+		}
+		scope, err := h.q.GetIPWhitelistScopeByID(ctx, &queries.GetIPWhitelistScopeByIDParams{
+			ID:       scopeID,
+			TenantID: tenantID,
+		})
+		if err != nil {
+			if errlib.Is(err, pgx.ErrNoRows) {
+				return errlib.NewError(fmt.Errorf("AddIPToWhitelist: scope %s not found", scopeID.String()), http.StatusBadRequest)
+			}
+			return errlib.NewError(err, http.StatusInternalServerError)
+		}
+		if scope.Exempt {
+			return errlib.NewErrorWithDetail(fmt.Errorf("AddIPToWhitelist: scope %s is exempt", scopeID.String()), http.StatusBadRequest, "除外設定のスコープにはIPアドレスを追加できません。")
+		}
+	}
+
 	exists, err := h.q.CheckIPExists(ctx, &queries.CheckIPExistsParams{
 		TenantID:  tenantID,
 		IpAddress: prefix,
+		ScopeID:   scopeID,
 	})
 	if err != nil {
 		return errlib.NewError(err, http.StatusInternalServerError)
@@ -82,6 +124,11 @@ func (h *IPWhitelistHandler) addIPToWhitelist(ctx context.Context, req AddIPToWh
 		label = pgtype.Text{String: *req.Label, Valid: true}
 	}
 
+	var expiresAt pgtype.Timestamptz
+	if req.ExpiresAt != nil {
+		expiresAt = pgtype.Timestamptz{Time: *req.ExpiresAt, Valid: true}
+	}
+
 	tx, err := h.dbConn.Begin(ctx)
 	if err != nil {
 		return errlib.NewError(fmt.Errorf("AddIPToWhitelist: failed to begin transaction: %w", err), http.StatusInternalServerError)
@@ -98,6 +145,8 @@ func (h *IPWhitelistHandler) addIPToWhitelist(ctx context.Context, req AddIPToWh
 		IpAddress: prefix,
 		Label:     label,
 		CreatedBy: userID,
+		ExpiresAt: expiresAt,
+		ScopeID:   scopeID,
 	})
 	if err != nil {
 		return errlib.NewError(err, http.StatusInternalServerError)
@@ -109,11 +158,20 @@ func (h *IPWhitelistHandler) addIPToWhitelist(ctx context.Context, req AddIPToWh
 		if err != nil {
 			return errlib.NewError(fmt.Errorf("AddIPToWhitelist: failed to get actor for audit log: %w", err), http.StatusInternalServerError)
 		}
-		metadata, _ := json.Marshal(map[string]string{
+		metadataMap := map[string]string{
 			"actor_name":  actor.Name,
 			"actor_email": actor.Email,
 			"ip_address":  normalizedCIDR,
-		})
+		}
+		if expiresAt.Valid {
+			metadataMap["expires_at"] = expiresAt.Time.Format(time.RFC3339)
+		}
+		if scopeID.Valid {
+			metadataMap["scope_id"] = scopeID.String()
+		} else if iputils.IsBroadPrefix(prefix) {
+			metadataMap["broad"] = "true"
+		}
+		metadata, _ := json.Marshal(metadataMap)
 		ipAddr, _ := netip.ParseAddr(iputils.ExtractClientIP(r))
 		err = qtx.InsertAuditLog(ctx, &queries.InsertAuditLogParams{
 			TenantID:     tenantID,
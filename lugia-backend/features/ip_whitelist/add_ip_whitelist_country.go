@@ -0,0 +1,140 @@
+// Feature doc: docs/features/ip-whitelisting.md, docs/features/audit-logging.md
+package ip_whitelist
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"regexp"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"dislyze/jirachi/auditlog"
+	libctx "dislyze/jirachi/ctx"
+	"dislyze/jirachi/errlib"
+	"lugia/lib/authz"
+	"lugia/lib/geoip"
+	"lugia/lib/iputils"
+	"lugia/lib/middleware"
+	"lugia/queries"
+)
+
+var AddIPWhitelistCountryOp = huma.Operation{
+	OperationID: "add-ip-whitelist-country",
+	Method:      http.MethodPost,
+	Path:        "/ip-whitelist/countries/create",
+}
+
+var countryCodePattern = regexp.MustCompile(`^[A-Z]{2}$`)
+
+type AddIPWhitelistCountryInput struct {
+	Body AddIPWhitelistCountryRequest
+}
+
+type AddIPWhitelistCountryRequest struct {
+	CountryCode string `json:"country_code" doc:"ISO 3166-1 alpha-2 country code"`
+	Action      string `json:"action" enum:"allow,deny"`
+}
+
+func (r *AddIPWhitelistCountryRequest) Resolve(ctx huma.Context) []error {
+	r.CountryCode = strings.ToUpper(strings.TrimSpace(r.CountryCode))
+	if !countryCodePattern.MatchString(r.CountryCode) {
+		return []error{fmt.Errorf("country_code must be a two-letter ISO 3166-1 code")}
+	}
+	return nil
+}
+
+type AddIPWhitelistCountryResponse struct {
+	Country IPWhitelistCountry `json:"country"`
+}
+
+type AddIPWhitelistCountryOutput struct {
+	Body AddIPWhitelistCountryResponse
+}
+
+func (h *IPWhitelistHandler) AddIPWhitelistCountry(ctx context.Context, input *AddIPWhitelistCountryInput) (*AddIPWhitelistCountryOutput, error) {
+	country, err := h.addIPWhitelistCountry(ctx, input.Body)
+	if err != nil {
+		return nil, err
+	}
+	return &AddIPWhitelistCountryOutput{Body: AddIPWhitelistCountryResponse{Country: *country}}, nil
+}
+
+func (h *IPWhitelistHandler) addIPWhitelistCountry(ctx context.Context, req AddIPWhitelistCountryRequest) (*IPWhitelistCountry, error) {
+	tenantID := libctx.GetTenantID(ctx)
+	actorID := libctx.GetUserID(ctx)
+
+	if !geoip.Loaded() {
+		return nil, errlib.NewErrorWithDetail(fmt.Errorf("AddIPWhitelistCountry: no GeoIP database loaded"), http.StatusBadRequest, "国別ルールを利用するには、GeoIPデータベースの設定が必要です。")
+	}
+
+	if req.Action == middleware.CountryRuleDeny && libctx.GetIPWhitelistConfig(ctx).Active && currentCountry(ctx) == req.CountryCode {
+		return nil, errlib.NewErrorWithDetail(fmt.Errorf("AddIPWhitelistCountry: would deny the caller's own country %s", req.CountryCode), http.StatusBadRequest, "現在アクセスしている国を拒否することはできません。")
+	}
+
+	tx, err := h.dbConn.Begin(ctx)
+	if err != nil {
+		return nil, errlib.NewError(fmt.Errorf("AddIPWhitelistCountry: failed to begin transaction: %w", err), http.StatusInternalServerError)
+	}
+	defer func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errlib.Is(rbErr, pgx.ErrTxClosed) && !errlib.Is(rbErr, sql.ErrTxDone) {
+			errlib.LogError(fmt.Errorf("AddIPWhitelistCountry: failed to rollback transaction: %w", rbErr))
+		}
+	}()
+	qtx := h.q.WithTx(tx)
+
+	row, err := qtx.AddIPWhitelistCountry(ctx, &queries.AddIPWhitelistCountryParams{
+		TenantID:    tenantID,
+		CountryCode: req.CountryCode,
+		Action:      req.Action,
+		CreatedBy:   actorID,
+	})
+	if err != nil {
+		if errlib.Is(err, pgx.ErrNoRows) {
+			return nil, errlib.NewErrorWithDetail(fmt.Errorf("AddIPWhitelistCountry: rule for %s already exists", req.CountryCode), http.StatusBadRequest, "この国のルールは既に登録されています。")
+		}
+		return nil, errlib.NewError(fmt.Errorf("AddIPWhitelistCountry: failed to add country rule: %w", err), http.StatusInternalServerError)
+	}
+
+	if authz.TenantHasFeature(ctx, authz.FeatureAuditLog) {
+		r := middleware.GetHTTPRequest(ctx)
+		actor, err := qtx.GetUserByID(ctx, actorID)
+		if err != nil {
+			return nil, errlib.NewError(fmt.Errorf("AddIPWhitelistCountry: failed to get actor for audit log: %w", err), http.StatusInternalServerError)
+		}
+		metadata, _ := json.Marshal(map[string]string{
+			"actor_name":   actor.Name,
+			"actor_email":  actor.Email,
+			"country_code": row.CountryCode,
+			"action":       row.Action,
+		})
+		ipAddr, _ := netip.ParseAddr(iputils.ExtractClientIP(r))
+		err = qtx.InsertAuditLog(ctx, &queries.InsertAuditLogParams{
+			TenantID:     tenantID,
+			ActorID:      actorID,
+			ResourceType: string(auditlog.ResourceIPWhitelist),
+			Action:       string(auditlog.ActionIPCountryAdded),
+			Outcome:      string(auditlog.OutcomeSuccess),
+			ResourceID:   pgtype.Text{String: row.ID.String(), Valid: true},
+			Metadata:     metadata,
+			IpAddress:    &ipAddr,
+			UserAgent:    pgtype.Text{String: r.UserAgent(), Valid: true},
+		})
+		if err != nil {
+			return nil, errlib.NewError(fmt.Errorf("AddIPWhitelistCountry: failed to insert audit log: %w", err), http.StatusInternalServerError)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, errlib.NewError(fmt.Errorf("AddIPWhitelistCountry: failed to commit transaction: %w", err), http.StatusInternalServerError)
+	}
+
+	country := toIPWhitelistCountry(row)
+	return &country, nil
+}
@@ -0,0 +1,210 @@
+// Feature doc: docs/features/ip-whitelisting.md, docs/features/audit-logging.md
+package ip_whitelist
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"dislyze/jirachi/auditlog"
+	jirachiAuthz "dislyze/jirachi/authz"
+	"dislyze/jirachi/errlib"
+	"lugia/lib/iputils"
+	"lugia/lib/middleware"
+)
+
+var ApproveBreakGlassOp = huma.Operation{
+	OperationID: "approve-ip-whitelist-break-glass",
+	Method:      http.MethodPost,
+	Path:        "/ip-whitelist/break-glass/approve",
+}
+
+type ApproveBreakGlassInput struct {
+	Token string `query:"token"`
+}
+
+type ApproveBreakGlassResponse struct {
+	Approvals   int  `json:"approvals"`
+	Required    int  `json:"required"`
+	Deactivated bool `json:"deactivated"`
+}
+
+type ApproveBreakGlassOutput struct {
+	Body ApproveBreakGlassResponse
+}
+
+const invalidApprovalLinkDetail = "承認リンクが無効または期限切れです。"
+
+func (h *IPWhitelistHandler) ApproveBreakGlass(ctx context.Context, input *ApproveBreakGlassInput) (*ApproveBreakGlassOutput, error) {
+	r := middleware.GetHTTPRequest(ctx)
+
+	if !h.rateLimiter.Allow(iputils.ExtractClientIP(r), r) {
+		return nil, errlib.NewError(fmt.Errorf("rate limit exceeded for break-glass approval: %s", iputils.ExtractClientIP(r)), http.StatusTooManyRequests)
+	}
+
+	if input.Token == "" {
+		return nil, errlib.NewErrorWithDetail(fmt.Errorf("break-glass approval token is empty"), http.StatusBadRequest, invalidApprovalLinkDetail)
+	}
+
+	response, err := h.approveBreakGlass(ctx, input.Token, r)
+	if err != nil {
+		return nil, err
+	}
+	return &ApproveBreakGlassOutput{Body: *response}, nil
+}
+
+// approveBreakGlass records the approval behind token and, once enough
+// different emergency holders have approved, deactivates the whitelist. The
+// request row is locked so two approvals landing together can't each see the
+// other as missing.
+func (h *IPWhitelistHandler) approveBreakGlass(ctx context.Context, token string, r *http.Request) (*ApproveBreakGlassResponse, error) {
+	approval, err := h.q.GetIPWhitelistBreakGlassApprovalByTokenHash(ctx, hashBreakGlassSecret(token))
+	if err != nil {
+		if errlib.Is(err, pgx.ErrNoRows) {
+			return nil, errlib.NewErrorWithDetail(fmt.Errorf("ApproveBreakGlass: approval token not found"), http.StatusBadRequest, invalidApprovalLinkDetail)
+		}
+		return nil, errlib.NewError(fmt.Errorf("ApproveBreakGlass: failed to get approval: %w", err), http.StatusInternalServerError)
+	}
+
+	// The per-address limit can be dodged by spoofing the address; a tenant
+	// only ever has a handful of approvals to record.
+	if !h.breakGlassRateLimiter.Allow("approve:"+approval.TenantID.String(), r) {
+		return nil, errlib.NewError(fmt.Errorf("ApproveBreakGlass: rate limit exceeded for tenant %s", approval.TenantID.String()), http.StatusTooManyRequests)
+	}
+
+	if approval.CompletedAt.Valid {
+		return nil, errlib.NewErrorWithDetail(fmt.Errorf("ApproveBreakGlass: request %s is already completed", approval.RequestID.String()), http.StatusConflict, "このリクエストは既に承認され、IPアドレス制限は解除されています。")
+	}
+	if approval.ExpiresAt.Time.Before(time.Now()) {
+		return nil, errlib.NewErrorWithDetail(fmt.Errorf("ApproveBreakGlass: request %s has expired", approval.RequestID.String()), http.StatusBadRequest, invalidApprovalLinkDetail)
+	}
+	if approval.ApprovedAt.Valid {
+		return nil, errlib.NewErrorWithDetail(fmt.Errorf("ApproveBreakGlass: approval %s is already recorded", approval.ID.String()), http.StatusConflict, "既に承認済みです。もう1名の管理者の承認をお待ちください。")
+	}
+
+	approver, err := h.q.GetUserByID(ctx, approval.UserID)
+	if err != nil {
+		return nil, errlib.NewError(fmt.Errorf("ApproveBreakGlass: failed to get approver: %w", err), http.StatusInternalServerError)
+	}
+
+	tenant, err := h.q.GetTenantByID(ctx, approval.TenantID)
+	if err != nil {
+		return nil, errlib.NewError(fmt.Errorf("ApproveBreakGlass: failed to get tenant: %w", err), http.StatusInternalServerError)
+	}
+
+	var currentFeatures jirachiAuthz.EnterpriseFeatures
+	if err := json.Unmarshal(tenant.EnterpriseFeatures, &currentFeatures); err != nil {
+		return nil, errlib.NewError(fmt.Errorf("ApproveBreakGlass: failed to parse enterprise features: %w", err), http.StatusInternalServerError)
+	}
+
+	// The link was issued to a holder, but they may have lost the permission
+	// or left the tenant since.
+	allowed, err := h.holdsEmergencyPermission(ctx, tenant.ID, approver, currentFeatures.RBAC.Enabled)
+	if err != nil {
+		return nil, errlib.NewError(fmt.Errorf("ApproveBreakGlass: %w", err), http.StatusInternalServerError)
+	}
+	if !allowed {
+		return nil, errlib.NewErrorWithDetail(fmt.Errorf("ApproveBreakGlass: user %s no longer holds ip_whitelist emergency in tenant %s", approver.ID.String(), tenant.ID.String()), http.StatusForbidden, "IPアドレス制限の緊急解除の権限がありません。")
+	}
+
+	tx, err := h.dbConn.Begin(ctx)
+	if err != nil {
+		return nil, errlib.NewError(fmt.Errorf("ApproveBreakGlass: failed to begin transaction: %w", err), http.StatusInternalServerError)
+	}
+	defer func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errlib.Is(rbErr, pgx.ErrTxClosed) && !errlib.Is(rbErr, sql.ErrTxDone) {
+			errlib.LogError(fmt.Errorf("ApproveBreakGlass: failed to rollback transaction: %w", rbErr))
+		}
+	}()
+	qtx := h.q.WithTx(tx)
+
+	completedAt, err := qtx.LockIPWhitelistBreakGlassRequest(ctx, approval.RequestID)
+	if err != nil {
+		return nil, errlib.NewError(fmt.Errorf("ApproveBreakGlass: failed to lock request: %w", err), http.StatusInternalServerError)
+	}
+	if completedAt.Valid {
+		return nil, errlib.NewErrorWithDetail(fmt.Errorf("ApproveBreakGlass: request %s was completed concurrently", approval.RequestID.String()), http.StatusConflict, "このリクエストは既に承認され、IPアドレス制限は解除されています。")
+	}
+
+	approved, err := qtx.ApproveIPWhitelistBreakGlassRequest(ctx, approval.ID)
+	if err != nil {
+		return nil, errlib.NewError(fmt.Errorf("ApproveBreakGlass: failed to record approval: %w", err), http.StatusInternalServerError)
+	}
+	if approved == 0 {
+		return nil, errlib.NewErrorWithDetail(fmt.Errorf("ApproveBreakGlass: approval %s was recorded concurrently", approval.ID.String()), http.StatusConflict, "既に承認済みです。もう1名の管理者の承認をお待ちください。")
+	}
+
+	resourceID := pgtype.Text{String: approval.RequestID.String(), Valid: true}
+	if currentFeatures.AuditLog.Enabled {
+		if err := insertBreakGlassAuditLog(ctx, qtx, r, tenant.ID, approver, auditlog.ActionBreakGlassApproved, resourceID, nil); err != nil {
+			return nil, errlib.NewError(fmt.Errorf("ApproveBreakGlass: failed to insert audit log: %w", err), http.StatusInternalServerError)
+		}
+	}
+
+	approvers, err := qtx.GetIPWhitelistBreakGlassApprovers(ctx, approval.RequestID)
+	if err != nil {
+		return nil, errlib.NewError(fmt.Errorf("ApproveBreakGlass: failed to get approvers: %w", err), http.StatusInternalServerError)
+	}
+
+	response := &ApproveBreakGlassResponse{
+		Approvals: len(approvers),
+		Required:  breakGlassApprovalsRequired,
+	}
+
+	approverEmails := make([]string, 0, len(approvers))
+	for _, a := range approvers {
+		approverEmails = append(approverEmails, a.Email)
+	}
+
+	if len(approvers) >= breakGlassApprovalsRequired {
+		if err := deactivateForBreakGlass(ctx, qtx, tenant.ID, currentFeatures); err != nil {
+			return nil, errlib.NewError(fmt.Errorf("ApproveBreakGlass: %w", err), http.StatusInternalServerError)
+		}
+		if err := qtx.CompleteIPWhitelistBreakGlassRequest(ctx, approval.RequestID); err != nil {
+			return nil, errlib.NewError(fmt.Errorf("ApproveBreakGlass: failed to complete request: %w", err), http.StatusInternalServerError)
+		}
+		if currentFeatures.AuditLog.Enabled {
+			if err := insertBreakGlassAuditLog(ctx, qtx, r, tenant.ID, approver, auditlog.ActionBreakGlassDeactivated, resourceID, map[string]interface{}{
+				"approvers": approverEmails,
+			}); err != nil {
+				return nil, errlib.NewError(fmt.Errorf("ApproveBreakGlass: failed to insert audit log: %w", err), http.StatusInternalServerError)
+			}
+		}
+		response.Deactivated = true
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, errlib.NewError(fmt.Errorf("ApproveBreakGlass: failed to commit transaction: %w", err), http.StatusInternalServerError)
+	}
+
+	if response.Deactivated {
+		settingsLink := fmt.Sprintf("%s/settings/ip-whitelist", h.env.FrontendURL)
+		subject := fmt.Sprintf("【%s】管理者の承認によりIPアドレス制限が解除されました", tenant.Name)
+		plainTextContent := fmt.Sprintf("緊急解除のリクエストが%sによって承認され、IPアドレス制限が解除されました。\n\nお心当たりがない場合は、IPアドレス制限を再度有効化してください。\n%s",
+			strings.Join(approverEmails, "、"), settingsLink)
+		htmlContent := fmt.Sprintf("<p>緊急解除のリクエストが%sによって承認され、IPアドレス制限が解除されました。</p><p>お心当たりがない場合は、IPアドレス制限を再度有効化してください。</p><p><a href=\"%s\">IPアドレス制限の設定を開く</a></p>",
+			html.EscapeString(strings.Join(approverEmails, "、")), settingsLink)
+		h.notifyIPWhitelistEditors(ctx, tenant.ID, currentFeatures.RBAC.Enabled, nil, subject, plainTextContent, htmlContent)
+	} else {
+		// Each approval is a step towards deactivation, so editors hear about
+		// it while there is still time to object.
+		remaining := breakGlassApprovalsRequired - response.Approvals
+		subject := fmt.Sprintf("【%s】IPアドレス制限の緊急解除が承認されました（%d/%d）", tenant.Name, response.Approvals, breakGlassApprovalsRequired)
+		plainTextContent := fmt.Sprintf("%s（%s）がIPアドレス制限の緊急解除のリクエストを承認しました。あと%d名が承認すると、IPアドレス制限が解除されます。\n\nお心当たりがない場合は、緊急解除の権限を持つ管理者にご確認ください。",
+			approver.Name, approver.Email, remaining)
+		htmlContent := fmt.Sprintf("<p>%s（%s）がIPアドレス制限の緊急解除のリクエストを承認しました。あと%d名が承認すると、IPアドレス制限が解除されます。</p><p>お心当たりがない場合は、緊急解除の権限を持つ管理者にご確認ください。</p>",
+			html.EscapeString(approver.Name), html.EscapeString(approver.Email), remaining)
+		h.notifyIPWhitelistEditors(ctx, tenant.ID, currentFeatures.RBAC.Enabled, map[pgtype.UUID]bool{approver.ID: true}, subject, plainTextContent, htmlContent)
+	}
+
+	return response, nil
+}
@@ -0,0 +1,210 @@
+// Feature doc: docs/features/ip-whitelisting.md, docs/features/audit-logging.md
+package ip_whitelist
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/sendgrid/sendgrid-go"
+
+	"dislyze/jirachi/auditlog"
+	jirachiAuthz "dislyze/jirachi/authz"
+	"dislyze/jirachi/errlib"
+	"dislyze/jirachi/sendgridlib"
+	"lugia/lib/authz"
+	"lugia/lib/iputils"
+	"lugia/queries"
+)
+
+// Break-glass recovery deactivates an active whitelist without a session, for
+// tenants whose admins are locked out and can't use the emergency link. It is
+// either one printed recovery code or approval by two emergency holders.
+
+// recoveryCodeCount is how many codes one issue produces. Issuing again
+// replaces every earlier code, used or not.
+const recoveryCodeCount = 10
+
+// breakGlassRequestTTL is how long the approval links of a break-glass request
+// stay usable. It is longer than an emergency link's because it has to reach
+// two people.
+const breakGlassRequestTTL = 24 * time.Hour
+
+// breakGlassApprovalsRequired is how many different emergency holders have to
+// approve a request before the whitelist is deactivated.
+const breakGlassApprovalsRequired = 2
+
+// generateRecoveryCode returns 80 random bits as four groups of four base32
+// characters, which survive being printed and typed back in.
+func generateRecoveryCode() (string, error) {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to read random bytes for recovery code: %w", err)
+	}
+	raw := base32.StdEncoding.EncodeToString(b)
+	return raw[0:4] + "-" + raw[4:8] + "-" + raw[8:12] + "-" + raw[12:16], nil
+}
+
+// hashRecoveryCode ignores case, spaces and hyphens, so a code typed from a
+// printout matches however it was grouped.
+func hashRecoveryCode(code string) string {
+	normalized := strings.Map(func(r rune) rune {
+		if r == '-' || unicode.IsSpace(r) {
+			return -1
+		}
+		return unicode.ToUpper(r)
+	}, code)
+	return hashBreakGlassSecret(normalized)
+}
+
+func hashBreakGlassSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return fmt.Sprintf("%x", sum[:])
+}
+
+// holdsEmergencyPermission reports whether user is an active member of the
+// tenant holding ip_whitelist emergency there. Break-glass endpoints have no
+// session, so this stands in for RequireIPWhitelistEmergency.
+func (h *IPWhitelistHandler) holdsEmergencyPermission(ctx context.Context, tenantID pgtype.UUID, user *queries.User, rbacEnabled bool) (bool, error) {
+	if user.Status != "active" || user.DeletedAt.Valid {
+		return false, nil
+	}
+
+	isMember, err := h.q.IsTenantMember(ctx, &queries.IsTenantMemberParams{
+		TenantID: tenantID,
+		UserID:   user.ID,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to check tenant membership: %w", err)
+	}
+	if !isMember {
+		return false, nil
+	}
+
+	permissions, err := authz.LoadUserPermissions(ctx, h.q, tenantID, user.ID, rbacEnabled)
+	if err != nil {
+		return false, err
+	}
+	return permissions.Allows(authz.PermIPWhitelistEmergency.Resource.String(), authz.PermIPWhitelistEmergency.Action), nil
+}
+
+// deactivateForBreakGlass stops enforcement and monitoring, the same end state
+// as the emergency link.
+func deactivateForBreakGlass(ctx context.Context, qtx *queries.Queries, tenantID pgtype.UUID, features jirachiAuthz.EnterpriseFeatures) error {
+	features.IPWhitelist.Active = false
+	features.IPWhitelist.Monitor = false
+
+	updatedFeaturesJSON, err := json.Marshal(features)
+	if err != nil {
+		return fmt.Errorf("failed to marshal enterprise features: %w", err)
+	}
+
+	if err := qtx.UpdateTenantEnterpriseFeatures(ctx, &queries.UpdateTenantEnterpriseFeaturesParams{
+		EnterpriseFeatures: updatedFeaturesJSON,
+		ID:                 tenantID,
+	}); err != nil {
+		return fmt.Errorf("failed to update tenant enterprise features: %w", err)
+	}
+	return nil
+}
+
+// insertBreakGlassAuditLog records a break-glass step by actor. The caller
+// checks that the tenant has audit logging.
+func insertBreakGlassAuditLog(ctx context.Context, qtx *queries.Queries, r *http.Request, tenantID pgtype.UUID, actor *queries.User, action auditlog.Action, resourceID pgtype.Text, extra map[string]interface{}) error {
+	fields := map[string]interface{}{
+		"actor_name":  actor.Name,
+		"actor_email": actor.Email,
+	}
+	for key, value := range extra {
+		fields[key] = value
+	}
+	metadata, _ := json.Marshal(fields)
+
+	ipAddr, _ := netip.ParseAddr(iputils.ExtractClientIP(r))
+	return qtx.InsertAuditLog(ctx, &queries.InsertAuditLogParams{
+		TenantID:     tenantID,
+		ActorID:      actor.ID,
+		ResourceType: string(auditlog.ResourceIPWhitelist),
+		Action:       string(action),
+		Outcome:      string(auditlog.OutcomeSuccess),
+		ResourceID:   resourceID,
+		Metadata:     metadata,
+		IpAddress:    &ipAddr,
+		UserAgent:    pgtype.Text{String: r.UserAgent(), Valid: true},
+	})
+}
+
+// notifyIPWhitelistEditors emails every ip_whitelist editor of the tenant,
+// except those in skip. Failures are logged rather than returned: the
+// break-glass step has already been committed by the time anyone is told.
+func (h *IPWhitelistHandler) notifyIPWhitelistEditors(ctx context.Context, tenantID pgtype.UUID, rbacEnabled bool, skip map[pgtype.UUID]bool, subject, plainTextContent, htmlContent string) {
+	editors, err := h.q.GetIPWhitelistEditors(ctx, &queries.GetIPWhitelistEditorsParams{
+		TenantID:    tenantID,
+		RbacEnabled: rbacEnabled,
+	})
+	if err != nil {
+		errlib.LogError(fmt.Errorf("notifyIPWhitelistEditors: failed to get IP whitelist editors for tenant %s: %w", tenantID.String(), err))
+		return
+	}
+
+	to := make([]sendgridlib.SendGridEmailAddress, 0, len(editors))
+	for _, editor := range editors {
+		if skip[editor.ID] {
+			continue
+		}
+		to = append(to, sendgridlib.SendGridEmailAddress{Email: editor.Email, Name: editor.Name})
+	}
+	if len(to) == 0 {
+		return
+	}
+
+	if err := h.sendMail(to, subject, plainTextContent, htmlContent); err != nil {
+		errlib.LogError(fmt.Errorf("notifyIPWhitelistEditors: failed to notify editors of tenant %s: %w", tenantID.String(), err))
+	}
+}
+
+// sendMail sends one message per recipient so editors don't see each other's
+// addresses.
+func (h *IPWhitelistHandler) sendMail(to []sendgridlib.SendGridEmailAddress, subject, plainTextContent, htmlContent string) error {
+	personalizations := make([]sendgridlib.SendGridPersonalization, 0, len(to))
+	for _, recipient := range to {
+		personalizations = append(personalizations, sendgridlib.SendGridPersonalization{
+			To:      []sendgridlib.SendGridEmailAddress{recipient},
+			Subject: subject,
+		})
+	}
+
+	sgMailBody := sendgridlib.SendGridMailRequestBody{
+		Personalizations: personalizations,
+		From:             sendgridlib.SendGridEmailAddress{Email: sendgridlib.SendGridFromEmail, Name: sendgridlib.SendGridFromName},
+		Content:          []sendgridlib.SendGridContent{{Type: "text/plain", Value: plainTextContent}, {Type: "text/html", Value: htmlContent}},
+	}
+
+	bodyBytes, err := json.Marshal(sgMailBody)
+	if err != nil {
+		return fmt.Errorf("failed to marshal SendGrid request body: %w", err)
+	}
+
+	sendgridRequest := sendgrid.GetRequest(h.env.SendgridAPIKey, "/v3/mail/send", h.env.SendgridAPIUrl)
+	sendgridRequest.Method = "POST"
+	sendgridRequest.Body = bodyBytes
+	sgResponse, err := sendgrid.API(sendgridRequest)
+	if err != nil {
+		return fmt.Errorf("SendGrid API call failed: %w", err)
+	}
+
+	if sgResponse.StatusCode < 200 || sgResponse.StatusCode >= 300 {
+		return fmt.Errorf("SendGrid returned error status code %d. Body: %s", sgResponse.StatusCode, sgResponse.Body)
+	}
+
+	return nil
+}
@@ -0,0 +1,170 @@
+// Feature doc: docs/features/ip-whitelisting.md, docs/features/audit-logging.md
+package ip_whitelist
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/netip"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"dislyze/jirachi/auditlog"
+	libctx "dislyze/jirachi/ctx"
+	"dislyze/jirachi/errlib"
+	"lugia/lib/authz"
+	"lugia/lib/iputils"
+	"lugia/lib/middleware"
+	"lugia/queries"
+)
+
+var CreateIPWhitelistScopeOp = huma.Operation{
+	OperationID: "create-ip-whitelist-scope",
+	Method:      http.MethodPost,
+	Path:        "/ip-whitelist/scopes/create",
+}
+
+type CreateIPWhitelistScopeInput struct {
+	Body CreateIPWhitelistScopeRequest
+}
+
+type CreateIPWhitelistScopeRequest struct {
+	RoleID *string `json:"role_id,omitempty"`
+	UserID *string `json:"user_id,omitempty"`
+	Exempt bool    `json:"exempt"`
+}
+
+func (r *CreateIPWhitelistScopeRequest) Resolve(ctx huma.Context) []error {
+	if (r.RoleID == nil) == (r.UserID == nil) {
+		return []error{fmt.Errorf("exactly one of role_id and user_id is required")}
+	}
+	return nil
+}
+
+type CreateIPWhitelistScopeResponse struct {
+	Scope IPWhitelistScope `json:"scope"`
+}
+
+type CreateIPWhitelistScopeOutput struct {
+	Body CreateIPWhitelistScopeResponse
+}
+
+func (h *IPWhitelistHandler) CreateIPWhitelistScope(ctx context.Context, input *CreateIPWhitelistScopeInput) (*CreateIPWhitelistScopeOutput, error) {
+	var roleID, userID pgtype.UUID
+	if input.Body.RoleID != nil {
+		if err := roleID.Scan(*input.Body.RoleID); err != nil {
+			return nil, errlib.NewError(fmt.Errorf("invalid role ID format: %w", err), http.StatusBadRequest)
+		}
+	}
+	if input.Body.UserID != nil {
+		if err := userID.Scan(*input.Body.UserID); err != nil {
+			return nil, errlib.NewError(fmt.Errorf("invalid user ID format: %w", err), http.StatusBadRequest)
+		}
+	}
+
+	scope, err := h.createIPWhitelistScope(ctx, roleID, userID, input.Body.Exempt)
+	if err != nil {
+		return nil, err
+	}
+	return &CreateIPWhitelistScopeOutput{Body: CreateIPWhitelistScopeResponse{Scope: *scope}}, nil
+}
+
+func (h *IPWhitelistHandler) createIPWhitelistScope(ctx context.Context, roleID, userID pgtype.UUID, exempt bool) (*IPWhitelistScope, error) {
+	tenantID := libctx.GetTenantID(ctx)
+	actorID := libctx.GetUserID(ctx)
+
+	tx, err := h.dbConn.Begin(ctx)
+	if err != nil {
+		return nil, errlib.NewError(fmt.Errorf("CreateIPWhitelistScope: failed to begin transaction: %w", err), http.StatusInternalServerError)
+	}
+	defer func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errlib.Is(rbErr, pgx.ErrTxClosed) && !errlib.Is(rbErr, sql.ErrTxDone) {
+			errlib.LogError(fmt.Errorf("CreateIPWhitelistScope: failed to rollback transaction: %w", rbErr))
+		}
+	}()
+	qtx := h.q.WithTx(tx)
+
+	if roleID.Valid {
+		valid, err := qtx.ValidateRolesBelongToTenant(ctx, &queries.ValidateRolesBelongToTenantParams{
+			Column1:  []pgtype.UUID{roleID},
+			TenantID: tenantID,
+		})
+		if err != nil {
+			return nil, errlib.NewError(fmt.Errorf("CreateIPWhitelistScope: failed to validate role: %w", err), http.StatusInternalServerError)
+		}
+		if len(valid) == 0 {
+			return nil, errlib.NewError(fmt.Errorf("CreateIPWhitelistScope: role %s does not belong to tenant %s", roleID.String(), tenantID.String()), http.StatusBadRequest)
+		}
+	} else {
+		valid, err := qtx.ValidateUsersBelongToTenant(ctx, &queries.ValidateUsersBelongToTenantParams{
+			TenantID: tenantID,
+			UserIds:  []pgtype.UUID{userID},
+		})
+		if err != nil {
+			return nil, errlib.NewError(fmt.Errorf("CreateIPWhitelistScope: failed to validate user: %w", err), http.StatusInternalServerError)
+		}
+		if len(valid) == 0 {
+			return nil, errlib.NewError(fmt.Errorf("CreateIPWhitelistScope: user %s does not belong to tenant %s", userID.String(), tenantID.String()), http.StatusBadRequest)
+		}
+	}
+
+	scopeID, err := qtx.CreateIPWhitelistScope(ctx, &queries.CreateIPWhitelistScopeParams{
+		TenantID:  tenantID,
+		RoleID:    roleID,
+		UserID:    userID,
+		Exempt:    exempt,
+		CreatedBy: actorID,
+	})
+	if err != nil {
+		if errlib.Is(err, pgx.ErrNoRows) {
+			return nil, errlib.NewErrorWithDetail(fmt.Errorf("CreateIPWhitelistScope: scope already exists"), http.StatusBadRequest, "このロールまたはユーザーのスコープは既に設定されています。")
+		}
+		return nil, errlib.NewError(fmt.Errorf("CreateIPWhitelistScope: failed to create scope: %w", err), http.StatusInternalServerError)
+	}
+
+	scope, err := qtx.GetIPWhitelistScopeByID(ctx, &queries.GetIPWhitelistScopeByIDParams{
+		ID:       scopeID,
+		TenantID: tenantID,
+	})
+	if err != nil {
+		return nil, errlib.NewError(fmt.Errorf("CreateIPWhitelistScope: failed to get created scope: %w", err), http.StatusInternalServerError)
+	}
+
+	if authz.TenantHasFeature(ctx, authz.FeatureAuditLog) {
+		r := middleware.GetHTTPRequest(ctx)
+		actor, err := qtx.GetUserByID(ctx, actorID)
+		if err != nil {
+			return nil, errlib.NewError(fmt.Errorf("CreateIPWhitelistScope: failed to get actor for audit log: %w", err), http.StatusInternalServerError)
+		}
+		metadata, _ := json.Marshal(scopeAuditMetadata(scope, map[string]any{
+			"actor_name":  actor.Name,
+			"actor_email": actor.Email,
+		}))
+		ipAddr, _ := netip.ParseAddr(iputils.ExtractClientIP(r))
+		err = qtx.InsertAuditLog(ctx, &queries.InsertAuditLogParams{
+			TenantID:     tenantID,
+			ActorID:      actorID,
+			ResourceType: string(auditlog.ResourceIPWhitelist),
+			Action:       string(auditlog.ActionIPScopeCreated),
+			Outcome:      string(auditlog.OutcomeSuccess),
+			ResourceID:   pgtype.Text{String: scopeID.String(), Valid: true},
+			Metadata:     metadata,
+			IpAddress:    &ipAddr,
+			UserAgent:    pgtype.Text{String: r.UserAgent(), Valid: true},
+		})
+		if err != nil {
+			return nil, errlib.NewError(fmt.Errorf("CreateIPWhitelistScope: failed to insert audit log: %w", err), http.StatusInternalServerError)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, errlib.NewError(fmt.Errorf("CreateIPWhitelistScope: failed to commit transaction: %w", err), http.StatusInternalServerError)
+	}
+
+	result := toIPWhitelistScope(scope)
+	return &result, nil
+}
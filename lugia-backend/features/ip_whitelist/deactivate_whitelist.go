@@ -50,6 +50,7 @@ func (h *IPWhitelistHandler) deactivateWhitelist(ctx context.Context) error {
 	enterpriseFeatures := libctx.GetEnterpriseFeatures(ctx)
 
 	enterpriseFeatures.IPWhitelist.Active = false
+	enterpriseFeatures.IPWhitelist.Monitor = false
 
 	updatedFeaturesJSON, err := json.Marshal(enterpriseFeatures)
 	if err != nil {
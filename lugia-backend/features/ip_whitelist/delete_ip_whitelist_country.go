@@ -0,0 +1,122 @@
+// Feature doc: docs/features/ip-whitelisting.md, docs/features/audit-logging.md
+package ip_whitelist
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/netip"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"dislyze/jirachi/auditlog"
+	libctx "dislyze/jirachi/ctx"
+	"dislyze/jirachi/errlib"
+	"lugia/lib/authz"
+	"lugia/lib/iputils"
+	"lugia/lib/middleware"
+	"lugia/queries"
+)
+
+var DeleteIPWhitelistCountryOp = huma.Operation{
+	OperationID: "delete-ip-whitelist-country",
+	Method:      http.MethodPost,
+	Path:        "/ip-whitelist/countries/{id}/delete",
+}
+
+type DeleteIPWhitelistCountryInput struct {
+	ID string `path:"id"`
+}
+
+func (h *IPWhitelistHandler) DeleteIPWhitelistCountry(ctx context.Context, input *DeleteIPWhitelistCountryInput) (*struct{}, error) {
+	var id pgtype.UUID
+	if err := id.Scan(input.ID); err != nil {
+		return nil, errlib.NewError(fmt.Errorf("invalid IP whitelist country rule ID format: %w", err), http.StatusBadRequest)
+	}
+
+	err := h.deleteIPWhitelistCountry(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (h *IPWhitelistHandler) deleteIPWhitelistCountry(ctx context.Context, id pgtype.UUID) error {
+	tenantID := libctx.GetTenantID(ctx)
+	actorID := libctx.GetUserID(ctx)
+
+	rule, err := h.q.GetIPWhitelistCountryByID(ctx, &queries.GetIPWhitelistCountryByIDParams{
+		ID:       id,
+		TenantID: tenantID,
+	})
+	if err != nil {
+		if errlib.Is(err, pgx.ErrNoRows) {
+			return errlib.NewError(err, http.StatusNotFound)
+		}
+		return errlib.NewError(err, http.StatusInternalServerError)
+	}
+
+	// Like deleting the rule that matches the caller's IP, removing the
+	// caller's own allowed country is refused while the whitelist is active.
+	if rule.Action == middleware.CountryRuleAllow && libctx.GetIPWhitelistConfig(ctx).Active && currentCountry(ctx) == rule.CountryCode {
+		return errlib.NewErrorWithDetail(nil, http.StatusBadRequest, "現在アクセスしている国のルールは削除できません。")
+	}
+
+	tx, err := h.dbConn.Begin(ctx)
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("DeleteIPWhitelistCountry: failed to begin transaction: %w", err), http.StatusInternalServerError)
+	}
+	defer func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errlib.Is(rbErr, pgx.ErrTxClosed) && !errlib.Is(rbErr, sql.ErrTxDone) {
+			errlib.LogError(fmt.Errorf("DeleteIPWhitelistCountry: failed to rollback transaction: %w", rbErr))
+		}
+	}()
+	qtx := h.q.WithTx(tx)
+
+	err = qtx.DeleteIPWhitelistCountry(ctx, &queries.DeleteIPWhitelistCountryParams{
+		ID:       id,
+		TenantID: tenantID,
+	})
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("DeleteIPWhitelistCountry: failed to delete country rule: %w", err), http.StatusInternalServerError)
+	}
+
+	if authz.TenantHasFeature(ctx, authz.FeatureAuditLog) {
+		r := middleware.GetHTTPRequest(ctx)
+		actor, err := qtx.GetUserByID(ctx, actorID)
+		if err != nil {
+			return errlib.NewError(fmt.Errorf("DeleteIPWhitelistCountry: failed to get actor for audit log: %w", err), http.StatusInternalServerError)
+		}
+		metadata, _ := json.Marshal(map[string]string{
+			"actor_name":   actor.Name,
+			"actor_email":  actor.Email,
+			"country_code": rule.CountryCode,
+			"action":       rule.Action,
+		})
+		ipAddr, _ := netip.ParseAddr(iputils.ExtractClientIP(r))
+		err = qtx.InsertAuditLog(ctx, &queries.InsertAuditLogParams{
+			TenantID:     tenantID,
+			ActorID:      actorID,
+			ResourceType: string(auditlog.ResourceIPWhitelist),
+			Action:       string(auditlog.ActionIPCountryRemoved),
+			Outcome:      string(auditlog.OutcomeSuccess),
+			ResourceID:   pgtype.Text{String: id.String(), Valid: true},
+			Metadata:     metadata,
+			IpAddress:    &ipAddr,
+			UserAgent:    pgtype.Text{String: r.UserAgent(), Valid: true},
+		})
+		if err != nil {
+			return errlib.NewError(fmt.Errorf("DeleteIPWhitelistCountry: failed to insert audit log: %w", err), http.StatusInternalServerError)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return errlib.NewError(fmt.Errorf("DeleteIPWhitelistCountry: failed to commit transaction: %w", err), http.StatusInternalServerError)
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+// Feature doc: docs/features/ip-whitelisting.md, docs/features/audit-logging.md
+package ip_whitelist
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/netip"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"dislyze/jirachi/auditlog"
+	libctx "dislyze/jirachi/ctx"
+	"dislyze/jirachi/errlib"
+	"lugia/lib/authz"
+	"lugia/lib/iputils"
+	"lugia/lib/middleware"
+	"lugia/queries"
+)
+
+var DeleteIPWhitelistScopeOp = huma.Operation{
+	OperationID: "delete-ip-whitelist-scope",
+	Method:      http.MethodPost,
+	Path:        "/ip-whitelist/scopes/{id}/delete",
+}
+
+type DeleteIPWhitelistScopeInput struct {
+	ID string `path:"id"`
+}
+
+func (h *IPWhitelistHandler) DeleteIPWhitelistScope(ctx context.Context, input *DeleteIPWhitelistScopeInput) (*struct{}, error) {
+	var id pgtype.UUID
+	if err := id.Scan(input.ID); err != nil {
+		return nil, errlib.NewError(fmt.Errorf("invalid IP whitelist scope ID format: %w", err), http.StatusBadRequest)
+	}
+
+	err := h.deleteIPWhitelistScope(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// deleteIPWhitelistScope removes the scope together with its rules, so its
+// members fall back to the tenant rules (or to their other role scopes).
+func (h *IPWhitelistHandler) deleteIPWhitelistScope(ctx context.Context, id pgtype.UUID) error {
+	tenantID := libctx.GetTenantID(ctx)
+	actorID := libctx.GetUserID(ctx)
+
+	scope, err := h.q.GetIPWhitelistScopeByID(ctx, &queries.GetIPWhitelistScopeByIDParams{
+		ID:       id,
+		TenantID: tenantID,
+	})
+	if err != nil {
+		if errlib.Is(err, pgx.ErrNoRows) {
+			return errlib.NewError(err, http.StatusNotFound)
+		}
+		return errlib.NewError(err, http.StatusInternalServerError)
+	}
+
+	tx, err := h.dbConn.Begin(ctx)
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("DeleteIPWhitelistScope: failed to begin transaction: %w", err), http.StatusInternalServerError)
+	}
+	defer func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errlib.Is(rbErr, pgx.ErrTxClosed) && !errlib.Is(rbErr, sql.ErrTxDone) {
+			errlib.LogError(fmt.Errorf("DeleteIPWhitelistScope: failed to rollback transaction: %w", rbErr))
+		}
+	}()
+	qtx := h.q.WithTx(tx)
+
+	ruleCount, err := qtx.DeleteIPWhitelistScope(ctx, &queries.DeleteIPWhitelistScopeParams{
+		ID:       id,
+		TenantID: tenantID,
+	})
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("DeleteIPWhitelistScope: failed to delete scope: %w", err), http.StatusInternalServerError)
+	}
+
+	if authz.TenantHasFeature(ctx, authz.FeatureAuditLog) {
+		r := middleware.GetHTTPRequest(ctx)
+		actor, err := qtx.GetUserByID(ctx, actorID)
+		if err != nil {
+			return errlib.NewError(fmt.Errorf("DeleteIPWhitelistScope: failed to get actor for audit log: %w", err), http.StatusInternalServerError)
+		}
+		metadata, _ := json.Marshal(scopeAuditMetadata(scope, map[string]any{
+			"actor_name":    actor.Name,
+			"actor_email":   actor.Email,
+			"rules_removed": ruleCount,
+		}))
+		ipAddr, _ := netip.ParseAddr(iputils.ExtractClientIP(r))
+		err = qtx.InsertAuditLog(ctx, &queries.InsertAuditLogParams{
+			TenantID:     tenantID,
+			ActorID:      actorID,
+			ResourceType: string(auditlog.ResourceIPWhitelist),
+			Action:       string(auditlog.ActionIPScopeDeleted),
+			Outcome:      string(auditlog.OutcomeSuccess),
+			ResourceID:   pgtype.Text{String: id.String(), Valid: true},
+			Metadata:     metadata,
+			IpAddress:    &ipAddr,
+			UserAgent:    pgtype.Text{String: r.UserAgent(), Valid: true},
+		})
+		if err != nil {
+			return errlib.NewError(fmt.Errorf("DeleteIPWhitelistScope: failed to insert audit log: %w", err), http.StatusInternalServerError)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return errlib.NewError(fmt.Errorf("DeleteIPWhitelistScope: failed to commit transaction: %w", err), http.StatusInternalServerError)
+	}
+
+	return nil
+}
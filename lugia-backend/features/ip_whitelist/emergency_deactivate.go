@@ -91,6 +91,7 @@ func (h *IPWhitelistHandler) emergencyDeactivate(ctx context.Context, token stri
 	}
 
 	currentFeatures.IPWhitelist.Active = false
+	currentFeatures.IPWhitelist.Monitor = false
 
 	updatedFeaturesJSON, err := json.Marshal(currentFeatures)
 	if err != nil {
@@ -24,6 +24,11 @@ type IPWhitelistRule struct {
 	Label     *string   `json:"label"`
 	CreatedBy string    `json:"created_by"`
 	CreatedAt time.Time `json:"created_at"`
+	// ExpiresAt is nil for permanent rules. Expired rules are listed until
+	// the maintenance runner deletes them, but no longer match.
+	ExpiresAt *time.Time `json:"expires_at"`
+	// ScopeID is nil for tenant rules, which apply to everyone.
+	ScopeID *string `json:"scope_id"`
 }
 
 type GetIPWhitelistInput struct{}
@@ -51,12 +56,25 @@ func (h *IPWhitelistHandler) GetIPWhitelist(ctx context.Context, input *GetIPWhi
 			label = &rule.Label.String
 		}
 
+		var expiresAt *time.Time
+		if rule.ExpiresAt.Valid {
+			expiresAt = &rule.ExpiresAt.Time
+		}
+
+		var scopeID *string
+		if rule.ScopeID.Valid {
+			id := rule.ScopeID.String()
+			scopeID = &id
+		}
+
 		rules[i] = IPWhitelistRule{
 			ID:        rule.ID.String(),
 			IPAddress: rule.IpAddress.String(),
 			Label:     label,
 			CreatedBy: rule.CreatedBy.String(),
 			CreatedAt: rule.CreatedAt.Time,
+			ExpiresAt: expiresAt,
+			ScopeID:   scopeID,
 		}
 	}
 
@@ -0,0 +1,114 @@
+// Feature doc: docs/features/ip-whitelisting.md
+package ip_whitelist
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	libctx "dislyze/jirachi/ctx"
+	"dislyze/jirachi/errlib"
+	"lugia/lib/iputils"
+)
+
+var GetIPWhitelistAnalysisOp = huma.Operation{
+	OperationID: "get-ip-whitelist-analysis",
+	Method:      http.MethodGet,
+	Path:        "/ip-whitelist/analysis",
+}
+
+type GetIPWhitelistAnalysisInput struct{}
+
+// IPWhitelistFinding is one problem with one rule. RelatedRuleID and
+// RelatedIPAddress name the covering rule of a redundant or overlapping rule
+// and are nil otherwise.
+type IPWhitelistFinding struct {
+	Kind             string  `json:"kind" enum:"redundant,overlapping,broad,private"`
+	RuleID           string  `json:"rule_id"`
+	IPAddress        string  `json:"ip_address"`
+	ScopeID          *string `json:"scope_id"`
+	RelatedRuleID    *string `json:"related_rule_id"`
+	RelatedIPAddress *string `json:"related_ip_address"`
+}
+
+type GetIPWhitelistAnalysisResponse struct {
+	Findings []IPWhitelistFinding `json:"findings" nullable:"false"`
+}
+
+type GetIPWhitelistAnalysisOutput struct {
+	Body GetIPWhitelistAnalysisResponse
+}
+
+func (h *IPWhitelistHandler) GetIPWhitelistAnalysis(ctx context.Context, input *GetIPWhitelistAnalysisInput) (*GetIPWhitelistAnalysisOutput, error) {
+	response, err := h.getIPWhitelistAnalysis(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &GetIPWhitelistAnalysisOutput{Body: *response}, nil
+}
+
+// getIPWhitelistAnalysis checks the rules in force. Expired rules no longer
+// match, so they are left out until the maintenance runner deletes them.
+func (h *IPWhitelistHandler) getIPWhitelistAnalysis(ctx context.Context) (*GetIPWhitelistAnalysisResponse, error) {
+	tenantID := libctx.GetTenantID(ctx)
+
+	ipRules, err := h.q.GetTenantIPWhitelist(ctx, tenantID)
+	if err != nil {
+		return nil, errlib.NewError(fmt.Errorf("GetIPWhitelistAnalysis: failed to get whitelist: %w", err), http.StatusInternalServerError)
+	}
+
+	now := time.Now()
+	rules := make([]iputils.AnalyzedRule, 0, len(ipRules))
+	ipAddresses := make(map[string]string, len(ipRules))
+	scopeIDs := make(map[string]*string, len(ipRules))
+	for _, rule := range ipRules {
+		if rule.ExpiresAt.Valid && !rule.ExpiresAt.Time.After(now) {
+			continue
+		}
+
+		analyzed := iputils.AnalyzedRule{
+			ID:   rule.ID.String(),
+			CIDR: rule.IpAddress.String(),
+		}
+		if rule.ExpiresAt.Valid {
+			expiresAt := rule.ExpiresAt.Time
+			analyzed.ExpiresAt = &expiresAt
+		}
+		if rule.ScopeID.Valid {
+			scopeID := rule.ScopeID.String()
+			analyzed.Group = scopeID
+			scopeIDs[analyzed.ID] = &scopeID
+		}
+		rules = append(rules, analyzed)
+		ipAddresses[analyzed.ID] = analyzed.CIDR
+	}
+
+	response := &GetIPWhitelistAnalysisResponse{Findings: []IPWhitelistFinding{}}
+	for _, finding := range iputils.AnalyzeRules(rules) {
+		scopeID := scopeIDs[finding.RuleID]
+		// A scope rule only narrows the tenant rules, so a broad one lets
+		// nobody in that the tenant rules don't.
+		if finding.Kind == iputils.RuleBroad && scopeID != nil {
+			continue
+		}
+
+		f := IPWhitelistFinding{
+			Kind:      string(finding.Kind),
+			RuleID:    finding.RuleID,
+			IPAddress: ipAddresses[finding.RuleID],
+			ScopeID:   scopeID,
+		}
+		if finding.RelatedRuleID != "" {
+			relatedRuleID := finding.RelatedRuleID
+			relatedIPAddress := ipAddresses[relatedRuleID]
+			f.RelatedRuleID = &relatedRuleID
+			f.RelatedIPAddress = &relatedIPAddress
+		}
+		response.Findings = append(response.Findings, f)
+	}
+
+	return response, nil
+}
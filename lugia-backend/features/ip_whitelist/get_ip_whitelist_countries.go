@@ -0,0 +1,89 @@
+// Feature doc: docs/features/ip-whitelisting.md
+package ip_whitelist
+
+import (
+	"context"
+	"net/http"
+	"net/netip"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	libctx "dislyze/jirachi/ctx"
+	"dislyze/jirachi/errlib"
+	"lugia/lib/geoip"
+	"lugia/lib/iputils"
+	"lugia/lib/middleware"
+	"lugia/queries"
+)
+
+var GetIPWhitelistCountriesOp = huma.Operation{
+	OperationID: "get-ip-whitelist-countries",
+	Method:      http.MethodGet,
+	Path:        "/ip-whitelist/countries",
+}
+
+// IPWhitelistCountry is a country rule. Action "allow" admits addresses
+// located in the country; "deny" blocks them even if a CIDR rule matches.
+// CreatedBy is empty once the admin who added it has been purged.
+type IPWhitelistCountry struct {
+	ID          string    `json:"id"`
+	CountryCode string    `json:"country_code"`
+	Action      string    `json:"action" enum:"allow,deny"`
+	CreatedBy   string    `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type GetIPWhitelistCountriesInput struct{}
+
+type GetIPWhitelistCountriesResponse struct {
+	Countries []IPWhitelistCountry `json:"countries" nullable:"false"`
+	// DatabaseLoaded is false when no GeoIP database is configured, in which
+	// case country rules match no address and new ones can't be added.
+	DatabaseLoaded bool `json:"database_loaded"`
+	// CurrentCountry is the caller's own country, empty if unknown.
+	CurrentCountry string `json:"current_country"`
+}
+
+type GetIPWhitelistCountriesOutput struct {
+	Body GetIPWhitelistCountriesResponse
+}
+
+func (h *IPWhitelistHandler) GetIPWhitelistCountries(ctx context.Context, input *GetIPWhitelistCountriesInput) (*GetIPWhitelistCountriesOutput, error) {
+	tenantID := libctx.GetTenantID(ctx)
+
+	rows, err := h.q.GetTenantIPWhitelistCountries(ctx, tenantID)
+	if err != nil {
+		return nil, errlib.NewError(err, http.StatusInternalServerError)
+	}
+
+	countries := make([]IPWhitelistCountry, len(rows))
+	for i, row := range rows {
+		countries[i] = toIPWhitelistCountry(row)
+	}
+
+	return &GetIPWhitelistCountriesOutput{Body: GetIPWhitelistCountriesResponse{
+		Countries:      countries,
+		DatabaseLoaded: geoip.Loaded(),
+		CurrentCountry: currentCountry(ctx),
+	}}, nil
+}
+
+func toIPWhitelistCountry(row *queries.TenantIpWhitelistCountry) IPWhitelistCountry {
+	return IPWhitelistCountry{
+		ID:          row.ID.String(),
+		CountryCode: row.CountryCode,
+		Action:      row.Action,
+		CreatedBy:   row.CreatedBy.String(),
+		CreatedAt:   row.CreatedAt.Time,
+	}
+}
+
+// currentCountry resolves the country of the request's client IP.
+func currentCountry(ctx context.Context) string {
+	addr, err := netip.ParseAddr(iputils.ExtractClientIP(middleware.GetHTTPRequest(ctx)))
+	if err != nil {
+		return ""
+	}
+	return geoip.Country(addr.Unmap())
+}
@@ -0,0 +1,132 @@
+// Feature doc: docs/features/ip-whitelisting.md
+package ip_whitelist
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	libctx "dislyze/jirachi/ctx"
+	"dislyze/jirachi/errlib"
+	"lugia/lib/iputils"
+	"lugia/queries"
+)
+
+var GetMonitorReportOp = huma.Operation{
+	OperationID: "get-whitelist-monitor-report",
+	Method:      http.MethodGet,
+	Path:        "/ip-whitelist/monitor-report",
+}
+
+type GetMonitorReportInput struct {
+	Days int `query:"days" default:"7" minimum:"1" maximum:"90"`
+}
+
+// MonitorReportEntry counts the would-be blocks of one user from one source IP.
+type MonitorReportEntry struct {
+	IPAddress  string    `json:"ip_address"`
+	UserID     string    `json:"user_id"`
+	UserName   string    `json:"user_name"`
+	UserEmail  string    `json:"user_email"`
+	Hits       int64     `json:"hits"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
+
+// MonitorReportSourceIP sums the entries of one source IP.
+// AllowedByCurrentRules is evaluated against the rules as they are now, so an
+// address added to the whitelist after being recorded shows as covered.
+type MonitorReportSourceIP struct {
+	IPAddress             string    `json:"ip_address"`
+	Hits                  int64     `json:"hits"`
+	Users                 int       `json:"users"`
+	LastSeenAt            time.Time `json:"last_seen_at"`
+	AllowedByCurrentRules bool      `json:"allowed_by_current_rules"`
+}
+
+type GetMonitorReportResponse struct {
+	Since     time.Time               `json:"since"`
+	TotalHits int64                   `json:"total_hits"`
+	SourceIPs []MonitorReportSourceIP `json:"source_ips" nullable:"false"`
+	Entries   []MonitorReportEntry    `json:"entries" nullable:"false"`
+}
+
+type GetMonitorReportOutput struct {
+	Body GetMonitorReportResponse
+}
+
+func (h *IPWhitelistHandler) GetMonitorReport(ctx context.Context, input *GetMonitorReportInput) (*GetMonitorReportOutput, error) {
+	since := time.Now().Add(-time.Duration(input.Days) * 24 * time.Hour)
+
+	response, err := h.getMonitorReport(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	return &GetMonitorReportOutput{Body: *response}, nil
+}
+
+func (h *IPWhitelistHandler) getMonitorReport(ctx context.Context, since time.Time) (*GetMonitorReportResponse, error) {
+	tenantID := libctx.GetTenantID(ctx)
+
+	rows, err := h.q.GetIPWhitelistMonitorReport(ctx, &queries.GetIPWhitelistMonitorReportParams{
+		TenantID: tenantID,
+		Since:    pgtype.Timestamptz{Time: since, Valid: true},
+	})
+	if err != nil {
+		return nil, errlib.NewError(fmt.Errorf("GetMonitorReport: failed to get monitor hits: %w", err), http.StatusInternalServerError)
+	}
+
+	cidrs, err := h.q.GetTenantIPWhitelistCIDRs(ctx, tenantID)
+	if err != nil {
+		return nil, errlib.NewError(fmt.Errorf("GetMonitorReport: failed to get whitelist: %w", err), http.StatusInternalServerError)
+	}
+
+	response := &GetMonitorReportResponse{
+		Since:     since,
+		SourceIPs: []MonitorReportSourceIP{},
+		Entries:   make([]MonitorReportEntry, len(rows)),
+	}
+	bySource := make(map[string]*MonitorReportSourceIP)
+	for i, row := range rows {
+		response.Entries[i] = MonitorReportEntry{
+			IPAddress:  row.IpAddress,
+			UserID:     row.UserID.String(),
+			UserName:   row.UserName,
+			UserEmail:  row.UserEmail,
+			Hits:       row.Hits,
+			LastSeenAt: row.LastSeenAt.Time,
+		}
+		response.TotalHits += row.Hits
+
+		source, ok := bySource[row.IpAddress]
+		if !ok {
+			allowed, err := iputils.IsIPInCIDRList(row.IpAddress, cidrs)
+			if err != nil {
+				return nil, errlib.NewError(fmt.Errorf("GetMonitorReport: failed to check IP %s: %w", row.IpAddress, err), http.StatusInternalServerError)
+			}
+			source = &MonitorReportSourceIP{IPAddress: row.IpAddress, AllowedByCurrentRules: allowed}
+			bySource[row.IpAddress] = source
+		}
+		source.Hits += row.Hits
+		source.Users++
+		if row.LastSeenAt.Time.After(source.LastSeenAt) {
+			source.LastSeenAt = row.LastSeenAt.Time
+		}
+	}
+
+	for _, source := range bySource {
+		response.SourceIPs = append(response.SourceIPs, *source)
+	}
+	sort.Slice(response.SourceIPs, func(i, j int) bool {
+		if response.SourceIPs[i].Hits != response.SourceIPs[j].Hits {
+			return response.SourceIPs[i].Hits > response.SourceIPs[j].Hits
+		}
+		return response.SourceIPs[i].IPAddress < response.SourceIPs[j].IPAddress
+	})
+
+	return response, nil
+}
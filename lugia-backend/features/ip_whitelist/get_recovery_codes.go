@@ -0,0 +1,58 @@
+// Feature doc: docs/features/ip-whitelisting.md
+package ip_whitelist
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	libctx "dislyze/jirachi/ctx"
+	"dislyze/jirachi/errlib"
+)
+
+var GetRecoveryCodesOp = huma.Operation{
+	OperationID: "get-ip-whitelist-recovery-codes",
+	Method:      http.MethodGet,
+	Path:        "/ip-whitelist/recovery-codes",
+}
+
+type GetRecoveryCodesInput struct{}
+
+// GetRecoveryCodesResponse describes the tenant's recovery codes without
+// revealing them; only their hashes are stored. IssuedAt is null until codes
+// have been issued.
+type GetRecoveryCodesResponse struct {
+	Remaining int64      `json:"remaining"`
+	IssuedAt  *time.Time `json:"issued_at"`
+}
+
+type GetRecoveryCodesOutput struct {
+	Body GetRecoveryCodesResponse
+}
+
+func (h *IPWhitelistHandler) GetRecoveryCodes(ctx context.Context, input *GetRecoveryCodesInput) (*GetRecoveryCodesOutput, error) {
+	response, err := h.getRecoveryCodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &GetRecoveryCodesOutput{Body: *response}, nil
+}
+
+func (h *IPWhitelistHandler) getRecoveryCodes(ctx context.Context) (*GetRecoveryCodesResponse, error) {
+	tenantID := libctx.GetTenantID(ctx)
+
+	status, err := h.q.GetIPWhitelistRecoveryCodeStatus(ctx, tenantID)
+	if err != nil {
+		return nil, errlib.NewError(fmt.Errorf("GetRecoveryCodes: failed to get recovery code status: %w", err), http.StatusInternalServerError)
+	}
+
+	response := &GetRecoveryCodesResponse{Remaining: status.Remaining}
+	if status.IssuedAt.Valid {
+		issuedAt := status.IssuedAt.Time
+		response.IssuedAt = &issuedAt
+	}
+	return response, nil
+}
@@ -10,17 +10,21 @@ import (
 )
 
 type IPWhitelistHandler struct {
-	dbConn     *pgxpool.Pool
-	q          *queries.Queries
-	env        *config.Env
+	dbConn      *pgxpool.Pool
+	q           *queries.Queries
+	env         *config.Env
 	rateLimiter *ratelimit.RateLimiter
+	// breakGlassRateLimiter limits the unauthenticated break-glass endpoints
+	// by a key the caller can't rotate the way it can a forwarded address.
+	breakGlassRateLimiter *ratelimit.RateLimiter
 }
 
-func NewIPWhitelistHandler(dbConn *pgxpool.Pool, q *queries.Queries, env *config.Env, rateLimiter *ratelimit.RateLimiter) *IPWhitelistHandler {
+func NewIPWhitelistHandler(dbConn *pgxpool.Pool, q *queries.Queries, env *config.Env, rateLimiter, breakGlassRateLimiter *ratelimit.RateLimiter) *IPWhitelistHandler {
 	return &IPWhitelistHandler{
-		dbConn:     dbConn,
-		q:          q,
-		env:        env,
-		rateLimiter: rateLimiter,
+		dbConn:                dbConn,
+		q:                     q,
+		env:                   env,
+		rateLimiter:           rateLimiter,
+		breakGlassRateLimiter: breakGlassRateLimiter,
 	}
-}
\ No newline at end of file
+}
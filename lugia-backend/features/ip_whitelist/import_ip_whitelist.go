@@ -0,0 +1,267 @@
+// Feature doc: docs/features/ip-whitelisting.md, docs/features/audit-logging.md
+package ip_whitelist
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"time"
+	"unicode/utf8"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"dislyze/jirachi/auditlog"
+	libctx "dislyze/jirachi/ctx"
+	"dislyze/jirachi/errlib"
+	"lugia/lib/authz"
+	"lugia/lib/iputils"
+	"lugia/lib/middleware"
+	"lugia/queries"
+)
+
+// maxImportRows bounds one import so it stays a single short transaction.
+const maxImportRows = 1000
+
+var ImportIPWhitelistOp = huma.Operation{
+	OperationID: "import-ip-whitelist",
+	Method:      http.MethodPost,
+	Path:        "/ip-whitelist/import",
+}
+
+type ImportIPWhitelistInput struct {
+	Body ImportIPWhitelistRequest
+}
+
+type ImportIPWhitelistRequest struct {
+	Format  string `json:"format" enum:"csv,json"`
+	Content string `json:"content" minLength:"1" maxLength:"1048576"`
+	// AllOrNothing imports nothing unless every row can be added. Otherwise
+	// valid rows are added and the rest are reported.
+	AllOrNothing bool `json:"all_or_nothing"`
+	// ConfirmBroad imports broad rules (see iputils.IsBroadPrefix) too.
+	// Without it such rows are reported as broad and not added, as
+	// AddIPToWhitelist does.
+	ConfirmBroad bool `json:"confirm_broad,omitempty"`
+}
+
+type ImportIPWhitelistRowResult struct {
+	Row       int     `json:"row"`
+	IPAddress string  `json:"ip_address"`
+	Status    string  `json:"status" enum:"added,duplicate,invalid,broad,skipped"`
+	Error     *string `json:"error"`
+}
+
+type ImportIPWhitelistResponse struct {
+	Imported int                          `json:"imported"`
+	Rows     []ImportIPWhitelistRowResult `json:"rows" nullable:"false"`
+}
+
+type ImportIPWhitelistOutput struct {
+	Body ImportIPWhitelistResponse
+}
+
+// importedRule is a row that passed validation and is ready to insert.
+type importedRule struct {
+	result    int
+	prefix    netip.Prefix
+	label     pgtype.Text
+	expiresAt pgtype.Timestamptz
+}
+
+func (h *IPWhitelistHandler) ImportIPWhitelist(ctx context.Context, input *ImportIPWhitelistInput) (*ImportIPWhitelistOutput, error) {
+	r := middleware.GetHTTPRequest(ctx)
+
+	if !h.rateLimiter.Allow(libctx.GetUserID(ctx).String(), r) {
+		return nil, errlib.NewError(fmt.Errorf("rate limit exceeded for IP whitelist import"), http.StatusTooManyRequests)
+	}
+
+	response, err := h.importIPWhitelist(ctx, r, input.Body)
+	if err != nil {
+		return nil, err
+	}
+	return &ImportIPWhitelistOutput{Body: *response}, nil
+}
+
+func (h *IPWhitelistHandler) importIPWhitelist(ctx context.Context, r *http.Request, req ImportIPWhitelistRequest) (*ImportIPWhitelistResponse, error) {
+	tenantID := libctx.GetTenantID(ctx)
+	userID := libctx.GetUserID(ctx)
+
+	records, err := iputils.ParseRuleFile(iputils.RuleFileFormat(req.Format), req.Content)
+	if err != nil {
+		return nil, errlib.NewErrorWithDetail(fmt.Errorf("ImportIPWhitelist: %w", err), http.StatusBadRequest, "ファイルを読み込めませんでした。形式を確認してください。")
+	}
+	if len(records) == 0 {
+		return nil, errlib.NewErrorWithDetail(fmt.Errorf("ImportIPWhitelist: file has no rules"), http.StatusBadRequest, "ファイルにIPアドレスが含まれていません。")
+	}
+	if len(records) > maxImportRows {
+		return nil, errlib.NewErrorWithDetail(fmt.Errorf("ImportIPWhitelist: %d rows exceeds the limit of %d", len(records), maxImportRows), http.StatusBadRequest, fmt.Sprintf("一度にインポートできるのは%d件までです。", maxImportRows))
+	}
+
+	tx, err := h.dbConn.Begin(ctx)
+	if err != nil {
+		return nil, errlib.NewError(fmt.Errorf("ImportIPWhitelist: failed to begin transaction: %w", err), http.StatusInternalServerError)
+	}
+	defer func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errlib.Is(rbErr, pgx.ErrTxClosed) && !errlib.Is(rbErr, sql.ErrTxDone) {
+			errlib.LogError(fmt.Errorf("ImportIPWhitelist: failed to rollback transaction: %w", rbErr))
+		}
+	}()
+	qtx := h.q.WithTx(tx)
+
+	response := &ImportIPWhitelistResponse{Rows: make([]ImportIPWhitelistRowResult, len(records))}
+	var valid []importedRule
+	seen := make(map[netip.Prefix]int, len(records))
+	now := time.Now()
+	for i, record := range records {
+		response.Rows[i] = ImportIPWhitelistRowResult{Row: record.Row, IPAddress: record.IPAddress}
+
+		rule, problem := validateImportRecord(record, now)
+		if problem != "" {
+			response.Rows[i].Status = "invalid"
+			response.Rows[i].Error = &problem
+			continue
+		}
+		response.Rows[i].IPAddress = rule.prefix.String()
+
+		if firstRow, ok := seen[rule.prefix]; ok {
+			problem := fmt.Sprintf("%d行目と重複しています", firstRow)
+			response.Rows[i].Status = "duplicate"
+			response.Rows[i].Error = &problem
+			continue
+		}
+		seen[rule.prefix] = record.Row
+
+		exists, err := qtx.CheckIPExists(ctx, &queries.CheckIPExistsParams{
+			TenantID:  tenantID,
+			IpAddress: rule.prefix,
+		})
+		if err != nil {
+			return nil, errlib.NewError(fmt.Errorf("ImportIPWhitelist: failed to check IP %s: %w", rule.prefix, err), http.StatusInternalServerError)
+		}
+		if exists {
+			problem := "既に登録されています"
+			response.Rows[i].Status = "duplicate"
+			response.Rows[i].Error = &problem
+			continue
+		}
+
+		if !req.ConfirmBroad && iputils.IsBroadPrefix(rule.prefix) {
+			problem := "非常に広い範囲です。意図した範囲であれば、確認のうえ再度インポートしてください"
+			response.Rows[i].Status = "broad"
+			response.Rows[i].Error = &problem
+			continue
+		}
+
+		rule.result = i
+		valid = append(valid, rule)
+	}
+
+	if req.AllOrNothing && len(valid) < len(records) {
+		for _, rule := range valid {
+			response.Rows[rule.result].Status = "skipped"
+		}
+		return response, nil
+	}
+
+	added := make([]map[string]string, 0, len(valid))
+	for _, rule := range valid {
+		_, err := qtx.AddIPToWhitelist(ctx, &queries.AddIPToWhitelistParams{
+			TenantID:  tenantID,
+			IpAddress: rule.prefix,
+			Label:     rule.label,
+			CreatedBy: userID,
+			ExpiresAt: rule.expiresAt,
+		})
+		if err != nil {
+			return nil, errlib.NewError(fmt.Errorf("ImportIPWhitelist: failed to add IP %s: %w", rule.prefix, err), http.StatusInternalServerError)
+		}
+		response.Rows[rule.result].Status = "added"
+
+		entry := map[string]string{"ip_address": rule.prefix.String()}
+		if rule.label.Valid {
+			entry["label"] = rule.label.String
+		}
+		if rule.expiresAt.Valid {
+			entry["expires_at"] = rule.expiresAt.Time.Format(time.RFC3339)
+		}
+		if iputils.IsBroadPrefix(rule.prefix) {
+			entry["broad"] = "true"
+		}
+		added = append(added, entry)
+	}
+	response.Imported = len(added)
+
+	if len(added) > 0 && authz.TenantHasFeature(ctx, authz.FeatureAuditLog) {
+		actor, err := qtx.GetUserByID(ctx, userID)
+		if err != nil {
+			return nil, errlib.NewError(fmt.Errorf("ImportIPWhitelist: failed to get actor for audit log: %w", err), http.StatusInternalServerError)
+		}
+		metadata, _ := json.Marshal(map[string]any{
+			"actor_name":  actor.Name,
+			"actor_email": actor.Email,
+			"format":      req.Format,
+			"count":       len(added),
+			"rules":       added,
+		})
+		ipAddr, _ := netip.ParseAddr(iputils.ExtractClientIP(r))
+		err = qtx.InsertAuditLog(ctx, &queries.InsertAuditLogParams{
+			TenantID:     tenantID,
+			ActorID:      userID,
+			ResourceType: string(auditlog.ResourceIPWhitelist),
+			Action:       string(auditlog.ActionIPImported),
+			Outcome:      string(auditlog.OutcomeSuccess),
+			ResourceID:   pgtype.Text{},
+			Metadata:     metadata,
+			IpAddress:    &ipAddr,
+			UserAgent:    pgtype.Text{String: r.UserAgent(), Valid: true},
+		})
+		if err != nil {
+			return nil, errlib.NewError(fmt.Errorf("ImportIPWhitelist: failed to insert audit log: %w", err), http.StatusInternalServerError)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, errlib.NewError(fmt.Errorf("ImportIPWhitelist: failed to commit transaction: %w", err), http.StatusInternalServerError)
+	}
+
+	return response, nil
+}
+
+// validateImportRecord applies the same rules as AddIPToWhitelist to one row.
+// The returned problem is shown to the user next to the row.
+func validateImportRecord(record iputils.RuleRecord, now time.Time) (importedRule, string) {
+	normalizedCIDR, err := iputils.ValidateCIDR(record.IPAddress)
+	if err != nil {
+		return importedRule{}, "IPアドレスまたはCIDRの形式が正しくありません"
+	}
+	prefix, err := netip.ParsePrefix(normalizedCIDR)
+	if err != nil {
+		return importedRule{}, "IPアドレスまたはCIDRの形式が正しくありません"
+	}
+	rule := importedRule{prefix: prefix}
+
+	if record.Label != nil {
+		if utf8.RuneCountInString(*record.Label) > 255 {
+			return importedRule{}, "説明は255文字以内で入力してください"
+		}
+		rule.label = pgtype.Text{String: *record.Label, Valid: true}
+	}
+
+	if record.ExpiresAt != nil {
+		expiresAt, err := time.Parse(time.RFC3339, *record.ExpiresAt)
+		if err != nil {
+			return importedRule{}, "有効期限はRFC 3339形式（例: 2030-01-01T00:00:00+09:00）で指定してください"
+		}
+		if !expiresAt.After(now) {
+			return importedRule{}, "有効期限には未来の日時を指定してください"
+		}
+		rule.expiresAt = pgtype.Timestamptz{Time: expiresAt, Valid: true}
+	}
+
+	return rule, ""
+}
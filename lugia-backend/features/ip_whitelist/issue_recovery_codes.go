@@ -0,0 +1,110 @@
+// Feature doc: docs/features/ip-whitelisting.md, docs/features/audit-logging.md
+package ip_whitelist
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"dislyze/jirachi/auditlog"
+	libctx "dislyze/jirachi/ctx"
+	"dislyze/jirachi/errlib"
+	"lugia/lib/authz"
+	"lugia/lib/middleware"
+	"lugia/queries"
+)
+
+var IssueRecoveryCodesOp = huma.Operation{
+	OperationID: "issue-ip-whitelist-recovery-codes",
+	Method:      http.MethodPost,
+	Path:        "/ip-whitelist/recovery-codes",
+}
+
+type IssueRecoveryCodesInput struct{}
+
+// IssueRecoveryCodesResponse is the only time the codes are shown.
+type IssueRecoveryCodesResponse struct {
+	Codes []string `json:"codes" nullable:"false"`
+}
+
+type IssueRecoveryCodesOutput struct {
+	Body IssueRecoveryCodesResponse
+}
+
+func (h *IPWhitelistHandler) IssueRecoveryCodes(ctx context.Context, input *IssueRecoveryCodesInput) (*IssueRecoveryCodesOutput, error) {
+	r := middleware.GetHTTPRequest(ctx)
+
+	if !h.rateLimiter.Allow(libctx.GetUserID(ctx).String(), r) {
+		return nil, errlib.NewError(fmt.Errorf("rate limit exceeded for issue recovery codes"), http.StatusTooManyRequests)
+	}
+
+	codes, err := h.issueRecoveryCodes(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	return &IssueRecoveryCodesOutput{Body: IssueRecoveryCodesResponse{Codes: codes}}, nil
+}
+
+// issueRecoveryCodes replaces the tenant's recovery codes with a fresh set, so
+// a printout that may have leaked can be invalidated by issuing again.
+func (h *IPWhitelistHandler) issueRecoveryCodes(ctx context.Context, r *http.Request) ([]string, error) {
+	tenantID := libctx.GetTenantID(ctx)
+	userID := libctx.GetUserID(ctx)
+
+	codes := make([]string, 0, recoveryCodeCount)
+	for range recoveryCodeCount {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, errlib.NewError(fmt.Errorf("IssueRecoveryCodes: %w", err), http.StatusInternalServerError)
+		}
+		codes = append(codes, code)
+	}
+
+	tx, err := h.dbConn.Begin(ctx)
+	if err != nil {
+		return nil, errlib.NewError(fmt.Errorf("IssueRecoveryCodes: failed to begin transaction: %w", err), http.StatusInternalServerError)
+	}
+	defer func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errlib.Is(rbErr, pgx.ErrTxClosed) && !errlib.Is(rbErr, sql.ErrTxDone) {
+			errlib.LogError(fmt.Errorf("IssueRecoveryCodes: failed to rollback transaction: %w", rbErr))
+		}
+	}()
+	qtx := h.q.WithTx(tx)
+
+	if err := qtx.DeleteIPWhitelistRecoveryCodes(ctx, tenantID); err != nil {
+		return nil, errlib.NewError(fmt.Errorf("IssueRecoveryCodes: failed to delete previous recovery codes: %w", err), http.StatusInternalServerError)
+	}
+
+	for _, code := range codes {
+		if err := qtx.CreateIPWhitelistRecoveryCode(ctx, &queries.CreateIPWhitelistRecoveryCodeParams{
+			TenantID:  tenantID,
+			CodeHash:  hashRecoveryCode(code),
+			CreatedBy: userID,
+		}); err != nil {
+			return nil, errlib.NewError(fmt.Errorf("IssueRecoveryCodes: failed to create recovery code: %w", err), http.StatusInternalServerError)
+		}
+	}
+
+	if authz.TenantHasFeature(ctx, authz.FeatureAuditLog) {
+		actor, err := qtx.GetUserByID(ctx, userID)
+		if err != nil {
+			return nil, errlib.NewError(fmt.Errorf("IssueRecoveryCodes: failed to get actor for audit log: %w", err), http.StatusInternalServerError)
+		}
+		if err := insertBreakGlassAuditLog(ctx, qtx, r, tenantID, actor, auditlog.ActionRecoveryCodesIssued, pgtype.Text{}, map[string]interface{}{
+			"count": len(codes),
+		}); err != nil {
+			return nil, errlib.NewError(fmt.Errorf("IssueRecoveryCodes: failed to insert audit log: %w", err), http.StatusInternalServerError)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, errlib.NewError(fmt.Errorf("IssueRecoveryCodes: failed to commit transaction: %w", err), http.StatusInternalServerError)
+	}
+
+	return codes, nil
+}
@@ -0,0 +1,157 @@
+// Feature doc: docs/features/ip-whitelisting.md, docs/features/audit-logging.md
+package ip_whitelist
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"dislyze/jirachi/auditlog"
+	jirachiAuthz "dislyze/jirachi/authz"
+	"dislyze/jirachi/errlib"
+	"lugia/lib/iputils"
+	"lugia/lib/middleware"
+	"lugia/queries"
+)
+
+var RedeemRecoveryCodeOp = huma.Operation{
+	OperationID: "redeem-ip-whitelist-recovery-code",
+	Method:      http.MethodPost,
+	Path:        "/ip-whitelist/recovery-codes/redeem",
+}
+
+type RedeemRecoveryCodeRequestBody struct {
+	Email string `json:"email" minLength:"1" pattern:"@"`
+	Code  string `json:"code" minLength:"1"`
+}
+
+type RedeemRecoveryCodeInput struct {
+	Body RedeemRecoveryCodeRequestBody
+}
+
+const invalidRecoveryCodeDetail = "リカバリーコードまたはメールアドレスが正しくありません。"
+
+func (h *IPWhitelistHandler) RedeemRecoveryCode(ctx context.Context, input *RedeemRecoveryCodeInput) (*struct{}, error) {
+	r := middleware.GetHTTPRequest(ctx)
+
+	if !h.rateLimiter.Allow(iputils.ExtractClientIP(r), r) {
+		return nil, errlib.NewError(fmt.Errorf("rate limit exceeded for redeem recovery code: %s", iputils.ExtractClientIP(r)), http.StatusTooManyRequests)
+	}
+	// The address can be spoofed behind a trusting proxy, so guesses
+	// against one account are capped too.
+	if !h.breakGlassRateLimiter.Allow("redeem:"+strings.ToLower(strings.TrimSpace(input.Body.Email)), r) {
+		return nil, errlib.NewError(fmt.Errorf("rate limit exceeded for redeem recovery code: %s", input.Body.Email), http.StatusTooManyRequests)
+	}
+
+	if err := h.redeemRecoveryCode(ctx, input.Body, r); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// redeemRecoveryCode deactivates the whitelist of the tenant the code belongs
+// to. The caller has no session, so the email stands in for one: its user has
+// to hold ip_whitelist emergency in that tenant. Every way the code or the
+// user can be wrong gets the same answer, so the endpoint can't be used to
+// probe either.
+func (h *IPWhitelistHandler) redeemRecoveryCode(ctx context.Context, req RedeemRecoveryCodeRequestBody, r *http.Request) error {
+	code, err := h.q.GetUnusedIPWhitelistRecoveryCode(ctx, hashRecoveryCode(req.Code))
+	if err != nil {
+		if errlib.Is(err, pgx.ErrNoRows) {
+			return errlib.NewErrorWithDetail(fmt.Errorf("RedeemRecoveryCode: no unused recovery code matches"), http.StatusBadRequest, invalidRecoveryCodeDetail)
+		}
+		return errlib.NewError(fmt.Errorf("RedeemRecoveryCode: failed to get recovery code: %w", err), http.StatusInternalServerError)
+	}
+
+	user, err := h.q.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		if errlib.Is(err, pgx.ErrNoRows) {
+			return errlib.NewErrorWithDetail(fmt.Errorf("RedeemRecoveryCode: no user for email %s", req.Email), http.StatusBadRequest, invalidRecoveryCodeDetail)
+		}
+		return errlib.NewError(fmt.Errorf("RedeemRecoveryCode: failed to get user by email: %w", err), http.StatusInternalServerError)
+	}
+
+	tenant, err := h.q.GetTenantByID(ctx, code.TenantID)
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("RedeemRecoveryCode: failed to get tenant: %w", err), http.StatusInternalServerError)
+	}
+
+	var currentFeatures jirachiAuthz.EnterpriseFeatures
+	if err := json.Unmarshal(tenant.EnterpriseFeatures, &currentFeatures); err != nil {
+		return errlib.NewError(fmt.Errorf("RedeemRecoveryCode: failed to parse enterprise features: %w", err), http.StatusInternalServerError)
+	}
+
+	allowed, err := h.holdsEmergencyPermission(ctx, tenant.ID, user, currentFeatures.RBAC.Enabled)
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("RedeemRecoveryCode: %w", err), http.StatusInternalServerError)
+	}
+	if !allowed {
+		return errlib.NewErrorWithDetail(fmt.Errorf("RedeemRecoveryCode: user %s does not hold ip_whitelist emergency in tenant %s", user.ID.String(), tenant.ID.String()), http.StatusBadRequest, invalidRecoveryCodeDetail)
+	}
+
+	// Nothing is blocking anyone, so keep the code for when something is.
+	if !currentFeatures.IPWhitelist.Enabled || !currentFeatures.IPWhitelist.Active {
+		return errlib.NewErrorWithDetail(fmt.Errorf("RedeemRecoveryCode: IP whitelist of tenant %s is not active", tenant.ID.String()), http.StatusConflict, "IPアドレス制限は有効になっていません。")
+	}
+
+	tx, err := h.dbConn.Begin(ctx)
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("RedeemRecoveryCode: failed to begin transaction: %w", err), http.StatusInternalServerError)
+	}
+	defer func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errlib.Is(rbErr, pgx.ErrTxClosed) && !errlib.Is(rbErr, sql.ErrTxDone) {
+			errlib.LogError(fmt.Errorf("RedeemRecoveryCode: failed to rollback transaction: %w", rbErr))
+		}
+	}()
+	qtx := h.q.WithTx(tx)
+
+	used, err := qtx.UseIPWhitelistRecoveryCode(ctx, &queries.UseIPWhitelistRecoveryCodeParams{
+		UsedBy: user.ID,
+		ID:     code.ID,
+	})
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("RedeemRecoveryCode: failed to mark recovery code as used: %w", err), http.StatusInternalServerError)
+	}
+	if used == 0 {
+		return errlib.NewErrorWithDetail(fmt.Errorf("RedeemRecoveryCode: recovery code %s was used concurrently", code.ID.String()), http.StatusBadRequest, invalidRecoveryCodeDetail)
+	}
+
+	if err := deactivateForBreakGlass(ctx, qtx, tenant.ID, currentFeatures); err != nil {
+		return errlib.NewError(fmt.Errorf("RedeemRecoveryCode: %w", err), http.StatusInternalServerError)
+	}
+
+	status, err := qtx.GetIPWhitelistRecoveryCodeStatus(ctx, tenant.ID)
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("RedeemRecoveryCode: failed to count remaining recovery codes: %w", err), http.StatusInternalServerError)
+	}
+
+	if currentFeatures.AuditLog.Enabled {
+		if err := insertBreakGlassAuditLog(ctx, qtx, r, tenant.ID, user, auditlog.ActionRecoveryCodeUsed, pgtype.Text{String: code.ID.String(), Valid: true}, map[string]interface{}{
+			"remaining_codes": status.Remaining,
+		}); err != nil {
+			return errlib.NewError(fmt.Errorf("RedeemRecoveryCode: failed to insert audit log: %w", err), http.StatusInternalServerError)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return errlib.NewError(fmt.Errorf("RedeemRecoveryCode: failed to commit transaction: %w", err), http.StatusInternalServerError)
+	}
+
+	settingsLink := fmt.Sprintf("%s/settings/ip-whitelist", h.env.FrontendURL)
+	subject := fmt.Sprintf("【%s】リカバリーコードでIPアドレス制限が解除されました", tenant.Name)
+	plainTextContent := fmt.Sprintf("%s（%s）がリカバリーコードを使用してIPアドレス制限を解除しました。残りのリカバリーコードは%d件です。\n\nお心当たりがない場合は、リカバリーコードを再発行し、IPアドレス制限を再度有効化してください。\n%s",
+		user.Name, user.Email, status.Remaining, settingsLink)
+	htmlContent := fmt.Sprintf("<p>%s（%s）がリカバリーコードを使用してIPアドレス制限を解除しました。残りのリカバリーコードは%d件です。</p><p>お心当たりがない場合は、リカバリーコードを再発行し、IPアドレス制限を再度有効化してください。</p><p><a href=\"%s\">IPアドレス制限の設定を開く</a></p>",
+		html.EscapeString(user.Name), html.EscapeString(user.Email), status.Remaining, settingsLink)
+	h.notifyIPWhitelistEditors(ctx, tenant.ID, currentFeatures.RBAC.Enabled, nil, subject, plainTextContent, htmlContent)
+
+	return nil
+}
@@ -0,0 +1,211 @@
+// Feature doc: docs/features/ip-whitelisting.md, docs/features/audit-logging.md
+package ip_whitelist
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"dislyze/jirachi/auditlog"
+	jirachiAuthz "dislyze/jirachi/authz"
+	"dislyze/jirachi/errlib"
+	"dislyze/jirachi/sendgridlib"
+	"dislyze/jirachi/utils"
+	"lugia/lib/iputils"
+	"lugia/lib/middleware"
+	"lugia/queries"
+)
+
+var RequestBreakGlassOp = huma.Operation{
+	OperationID: "request-ip-whitelist-break-glass",
+	Method:      http.MethodPost,
+	Path:        "/ip-whitelist/break-glass",
+}
+
+type RequestBreakGlassRequestBody struct {
+	Email string `json:"email" minLength:"1" pattern:"@"`
+}
+
+type RequestBreakGlassInput struct {
+	Body RequestBreakGlassRequestBody
+}
+
+func (h *IPWhitelistHandler) RequestBreakGlass(ctx context.Context, input *RequestBreakGlassInput) (*struct{}, error) {
+	r := middleware.GetHTTPRequest(ctx)
+
+	if !h.rateLimiter.Allow(iputils.ExtractClientIP(r), r) {
+		errlib.LogError(fmt.Errorf("rate limit exceeded for break-glass request: %s", iputils.ExtractClientIP(r)))
+		// Always return success for security (prevent email enumeration)
+		return nil, nil
+	}
+
+	if err := h.requestBreakGlass(ctx, input.Body, r); err != nil {
+		errlib.LogError(err)
+		// Always return success for security (prevent email enumeration)
+	}
+
+	return nil, nil
+}
+
+// requestBreakGlass opens a request in every tenant where the user holds
+// ip_whitelist emergency and the whitelist is enforcing.
+func (h *IPWhitelistHandler) requestBreakGlass(ctx context.Context, req RequestBreakGlassRequestBody, r *http.Request) error {
+	user, err := h.q.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		if errlib.Is(err, pgx.ErrNoRows) {
+			log.Printf("RequestBreakGlass: No user found for email %s", req.Email)
+			return nil
+		}
+		return fmt.Errorf("RequestBreakGlass: failed to get user by email %s: %w", req.Email, err)
+	}
+
+	memberships, err := h.q.ListTenantMembershipsForUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("RequestBreakGlass: failed to list tenant memberships of user %s: %w", user.ID.String(), err)
+	}
+
+	for _, membership := range memberships {
+		if err := h.requestBreakGlassInTenant(ctx, r, user, membership.ID); err != nil {
+			errlib.LogError(fmt.Errorf("RequestBreakGlass: tenant %s: %w", membership.ID.String(), err))
+		}
+	}
+	return nil
+}
+
+// requestBreakGlassInTenant sends every emergency holder of the tenant their
+// own approval link. One open request per tenant is enough, so asking again
+// while one is open sends nothing.
+func (h *IPWhitelistHandler) requestBreakGlassInTenant(ctx context.Context, r *http.Request, requester *queries.User, tenantID pgtype.UUID) error {
+	tenant, err := h.q.GetTenantByID(ctx, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to get tenant: %w", err)
+	}
+
+	var currentFeatures jirachiAuthz.EnterpriseFeatures
+	if err := json.Unmarshal(tenant.EnterpriseFeatures, &currentFeatures); err != nil {
+		return fmt.Errorf("failed to parse enterprise features: %w", err)
+	}
+	if !currentFeatures.IPWhitelist.Enabled || !currentFeatures.IPWhitelist.Active {
+		return nil
+	}
+
+	allowed, err := h.holdsEmergencyPermission(ctx, tenantID, requester, currentFeatures.RBAC.Enabled)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		log.Printf("RequestBreakGlass: user %s does not hold ip_whitelist emergency in tenant %s", requester.ID.String(), tenantID.String()) // #nosec G706 -- database UUIDs, not user input
+		return nil
+	}
+
+	open, err := h.q.HasOpenIPWhitelistBreakGlassRequest(ctx, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to check for an open break-glass request: %w", err)
+	}
+	if open {
+		log.Printf("RequestBreakGlass: tenant %s already has an open break-glass request", tenantID.String()) // #nosec G706 -- tenantID is a database UUID, not user input
+		return nil
+	}
+
+	holders, err := h.q.GetIPWhitelistEmergencyHolders(ctx, &queries.GetIPWhitelistEmergencyHoldersParams{
+		TenantID:    tenantID,
+		RbacEnabled: currentFeatures.RBAC.Enabled,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get emergency holders: %w", err)
+	}
+	if len(holders) < breakGlassApprovalsRequired {
+		log.Printf("RequestBreakGlass: tenant %s has %d emergency holders, fewer than the %d approvals required", tenantID.String(), len(holders), breakGlassApprovalsRequired) // #nosec G706 -- tenantID is a database UUID, not user input
+		return nil
+	}
+
+	expiresAt := time.Now().Add(breakGlassRequestTTL)
+
+	tx, err := h.dbConn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errlib.Is(rbErr, pgx.ErrTxClosed) && !errlib.Is(rbErr, sql.ErrTxDone) {
+			errlib.LogError(fmt.Errorf("RequestBreakGlass: failed to rollback transaction: %w", rbErr))
+		}
+	}()
+	qtx := h.q.WithTx(tx)
+
+	requestID, err := qtx.CreateIPWhitelistBreakGlassRequest(ctx, &queries.CreateIPWhitelistBreakGlassRequestParams{
+		TenantID:    tenantID,
+		RequestedBy: requester.ID,
+		ExpiresAt:   pgtype.Timestamptz{Time: expiresAt, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create break-glass request: %w", err)
+	}
+
+	tokens := make([]string, 0, len(holders))
+	for _, holder := range holders {
+		tokenUUID, err := utils.NewUUID()
+		if err != nil {
+			return fmt.Errorf("failed to generate approval token: %w", err)
+		}
+		token := tokenUUID.String()
+		if err := qtx.CreateIPWhitelistBreakGlassApproval(ctx, &queries.CreateIPWhitelistBreakGlassApprovalParams{
+			RequestID: requestID,
+			UserID:    holder.ID,
+			TokenHash: hashBreakGlassSecret(token),
+		}); err != nil {
+			return fmt.Errorf("failed to create break-glass approval: %w", err)
+		}
+		tokens = append(tokens, token)
+	}
+
+	if currentFeatures.AuditLog.Enabled {
+		if err := insertBreakGlassAuditLog(ctx, qtx, r, tenantID, requester, auditlog.ActionBreakGlassRequested, pgtype.Text{String: requestID.String(), Valid: true}, map[string]interface{}{
+			"approvers_notified": len(holders),
+		}); err != nil {
+			return fmt.Errorf("failed to insert audit log: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	notified := make(map[pgtype.UUID]bool, len(holders))
+	for i, holder := range holders {
+		notified[holder.ID] = true
+		if err := h.sendBreakGlassApprovalEmail(tenant.Name, requester, holder, tokens[i]); err != nil {
+			errlib.LogError(fmt.Errorf("RequestBreakGlass: failed to send approval link to user %s: %w", holder.ID.String(), err))
+		}
+	}
+
+	// Editors who can approve already heard about it from their approval email.
+	subject := fmt.Sprintf("【%s】IPアドレス制限の緊急解除がリクエストされました", tenant.Name)
+	plainTextContent := fmt.Sprintf("%s（%s）がIPアドレス制限の緊急解除をリクエストしました。緊急解除の権限を持つ管理者%d名が承認すると、IPアドレス制限が解除されます。\n\nお心当たりがない場合は、緊急解除の権限を持つ管理者にご確認ください。",
+		requester.Name, requester.Email, breakGlassApprovalsRequired)
+	htmlContent := fmt.Sprintf("<p>%s（%s）がIPアドレス制限の緊急解除をリクエストしました。緊急解除の権限を持つ管理者%d名が承認すると、IPアドレス制限が解除されます。</p><p>お心当たりがない場合は、緊急解除の権限を持つ管理者にご確認ください。</p>",
+		html.EscapeString(requester.Name), html.EscapeString(requester.Email), breakGlassApprovalsRequired)
+	h.notifyIPWhitelistEditors(ctx, tenantID, currentFeatures.RBAC.Enabled, notified, subject, plainTextContent, htmlContent)
+
+	return nil
+}
+
+func (h *IPWhitelistHandler) sendBreakGlassApprovalEmail(tenantName string, requester *queries.User, holder *queries.GetIPWhitelistEmergencyHoldersRow, token string) error {
+	approveLink := fmt.Sprintf("%s/ip-whitelist-recovery/approve?token=%s", h.env.FrontendURL, token)
+
+	subject := fmt.Sprintf("【%s】IPアドレス制限の緊急解除の承認依頼", tenantName)
+	plainTextContent := fmt.Sprintf("%s様\n\n%s（%s）がIPアドレス制限の緊急解除をリクエストしました。緊急解除の権限を持つ管理者%d名が承認すると、IPアドレス制限が解除されます。\n\n承認する場合は、以下のリンクを開いてください（24時間有効）。\n%s\n\nお心当たりがない場合は、このリンクを開かず、リクエストした方にご確認ください。",
+		holder.Name, requester.Name, requester.Email, breakGlassApprovalsRequired, approveLink)
+	htmlContent := fmt.Sprintf("<p>%s様</p><p>%s（%s）がIPアドレス制限の緊急解除をリクエストしました。緊急解除の権限を持つ管理者%d名が承認すると、IPアドレス制限が解除されます。</p><p><a href=\"%s\" style=\"background-color: #dc3545; color: white; padding: 10px 20px; text-decoration: none; border-radius: 4px; display: inline-block;\">緊急解除を承認する</a></p><p><small>※このリンクは24時間有効です</small></p><p><small>※お心当たりがない場合は、このリンクを開かず、リクエストした方にご確認ください。</small></p>",
+		html.EscapeString(holder.Name), html.EscapeString(requester.Name), html.EscapeString(requester.Email), breakGlassApprovalsRequired, approveLink)
+
+	return h.sendMail([]sendgridlib.SendGridEmailAddress{{Email: holder.Email, Name: holder.Name}}, subject, plainTextContent, htmlContent)
+}
@@ -0,0 +1,112 @@
+// Feature doc: docs/features/ip-whitelisting.md, docs/features/audit-logging.md
+package ip_whitelist
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/netip"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"dislyze/jirachi/auditlog"
+	libctx "dislyze/jirachi/ctx"
+	"dislyze/jirachi/errlib"
+	"lugia/lib/authz"
+	"lugia/lib/iputils"
+	"lugia/lib/middleware"
+	"lugia/queries"
+)
+
+var StartMonitorOp = huma.Operation{
+	OperationID: "start-whitelist-monitor",
+	Method:      http.MethodPost,
+	Path:        "/ip-whitelist/monitor",
+}
+
+type StartMonitorInput struct{}
+
+func (h *IPWhitelistHandler) StartMonitor(ctx context.Context, input *StartMonitorInput) (*struct{}, error) {
+	r := middleware.GetHTTPRequest(ctx)
+
+	if !h.rateLimiter.Allow(libctx.GetUserID(ctx).String(), r) {
+		return nil, errlib.NewError(fmt.Errorf("rate limit exceeded for start whitelist monitor"), http.StatusTooManyRequests)
+	}
+
+	if err := h.startMonitor(ctx, r); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// startMonitor puts the whitelist in monitor mode. Unlike activation it needs no
+// safety check or emergency link, since nothing is blocked; if the whitelist
+// was enforcing, this stops enforcement.
+func (h *IPWhitelistHandler) startMonitor(ctx context.Context, r *http.Request) error {
+	tenantID := libctx.GetTenantID(ctx)
+	userID := libctx.GetUserID(ctx)
+	enterpriseFeatures := libctx.GetEnterpriseFeatures(ctx)
+
+	enterpriseFeatures.IPWhitelist.Active = false
+	enterpriseFeatures.IPWhitelist.Monitor = true
+
+	updatedFeaturesJSON, err := json.Marshal(enterpriseFeatures)
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("StartMonitor: failed to marshal enterprise features: %w", err), http.StatusInternalServerError)
+	}
+
+	tx, err := h.dbConn.Begin(ctx)
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("StartMonitor: failed to begin transaction: %w", err), http.StatusInternalServerError)
+	}
+	defer func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errlib.Is(rbErr, pgx.ErrTxClosed) && !errlib.Is(rbErr, sql.ErrTxDone) {
+			errlib.LogError(fmt.Errorf("StartMonitor: failed to rollback transaction: %w", rbErr))
+		}
+	}()
+	qtx := h.q.WithTx(tx)
+
+	err = qtx.UpdateTenantEnterpriseFeatures(ctx, &queries.UpdateTenantEnterpriseFeaturesParams{
+		EnterpriseFeatures: updatedFeaturesJSON,
+		ID:                 tenantID,
+	})
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("StartMonitor: failed to update tenant enterprise features: %w", err), http.StatusInternalServerError)
+	}
+
+	if authz.TenantHasFeature(ctx, authz.FeatureAuditLog) {
+		actor, err := qtx.GetUserByID(ctx, userID)
+		if err != nil {
+			return errlib.NewError(fmt.Errorf("StartMonitor: failed to get actor for audit log: %w", err), http.StatusInternalServerError)
+		}
+		metadata, _ := json.Marshal(map[string]string{
+			"actor_name":  actor.Name,
+			"actor_email": actor.Email,
+		})
+		ipAddr, _ := netip.ParseAddr(iputils.ExtractClientIP(r))
+		err = qtx.InsertAuditLog(ctx, &queries.InsertAuditLogParams{
+			TenantID:     tenantID,
+			ActorID:      userID,
+			ResourceType: string(auditlog.ResourceIPWhitelist),
+			Action:       string(auditlog.ActionMonitorStarted),
+			Outcome:      string(auditlog.OutcomeSuccess),
+			ResourceID:   pgtype.Text{},
+			Metadata:     metadata,
+			IpAddress:    &ipAddr,
+			UserAgent:    pgtype.Text{String: r.UserAgent(), Valid: true},
+		})
+		if err != nil {
+			return errlib.NewError(fmt.Errorf("StartMonitor: failed to insert audit log: %w", err), http.StatusInternalServerError)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return errlib.NewError(fmt.Errorf("StartMonitor: failed to commit transaction: %w", err), http.StatusInternalServerError)
+	}
+
+	return nil
+}
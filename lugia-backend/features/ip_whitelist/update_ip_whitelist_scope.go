@@ -0,0 +1,124 @@
+// Feature doc: docs/features/ip-whitelisting.md, docs/features/audit-logging.md
+package ip_whitelist
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/netip"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"dislyze/jirachi/auditlog"
+	libctx "dislyze/jirachi/ctx"
+	"dislyze/jirachi/errlib"
+	"lugia/lib/authz"
+	"lugia/lib/iputils"
+	"lugia/lib/middleware"
+	"lugia/queries"
+)
+
+var UpdateIPWhitelistScopeOp = huma.Operation{
+	OperationID: "update-ip-whitelist-scope",
+	Method:      http.MethodPost,
+	Path:        "/ip-whitelist/scopes/{id}/update",
+}
+
+type UpdateIPWhitelistScopeInput struct {
+	ID   string `path:"id"`
+	Body UpdateIPWhitelistScopeRequest
+}
+
+type UpdateIPWhitelistScopeRequest struct {
+	Exempt bool `json:"exempt"`
+}
+
+func (h *IPWhitelistHandler) UpdateIPWhitelistScope(ctx context.Context, input *UpdateIPWhitelistScopeInput) (*struct{}, error) {
+	var id pgtype.UUID
+	if err := id.Scan(input.ID); err != nil {
+		return nil, errlib.NewError(fmt.Errorf("invalid IP whitelist scope ID format: %w", err), http.StatusBadRequest)
+	}
+
+	err := h.updateIPWhitelistScope(ctx, id, input.Body)
+	if err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (h *IPWhitelistHandler) updateIPWhitelistScope(ctx context.Context, id pgtype.UUID, req UpdateIPWhitelistScopeRequest) error {
+	tenantID := libctx.GetTenantID(ctx)
+	actorID := libctx.GetUserID(ctx)
+
+	scope, err := h.q.GetIPWhitelistScopeByID(ctx, &queries.GetIPWhitelistScopeByIDParams{
+		ID:       id,
+		TenantID: tenantID,
+	})
+	if err != nil {
+		if errlib.Is(err, pgx.ErrNoRows) {
+			return errlib.NewError(err, http.StatusNotFound)
+		}
+		return errlib.NewError(err, http.StatusInternalServerError)
+	}
+	if scope.Exempt == req.Exempt {
+		return nil
+	}
+
+	tx, err := h.dbConn.Begin(ctx)
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("UpdateIPWhitelistScope: failed to begin transaction: %w", err), http.StatusInternalServerError)
+	}
+	defer func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errlib.Is(rbErr, pgx.ErrTxClosed) && !errlib.Is(rbErr, sql.ErrTxDone) {
+			errlib.LogError(fmt.Errorf("UpdateIPWhitelistScope: failed to rollback transaction: %w", rbErr))
+		}
+	}()
+	qtx := h.q.WithTx(tx)
+
+	err = qtx.UpdateIPWhitelistScopeExempt(ctx, &queries.UpdateIPWhitelistScopeExemptParams{
+		Exempt:   req.Exempt,
+		ID:       id,
+		TenantID: tenantID,
+	})
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("UpdateIPWhitelistScope: failed to update scope: %w", err), http.StatusInternalServerError)
+	}
+
+	if authz.TenantHasFeature(ctx, authz.FeatureAuditLog) {
+		r := middleware.GetHTTPRequest(ctx)
+		actor, err := qtx.GetUserByID(ctx, actorID)
+		if err != nil {
+			return errlib.NewError(fmt.Errorf("UpdateIPWhitelistScope: failed to get actor for audit log: %w", err), http.StatusInternalServerError)
+		}
+		scope.Exempt = req.Exempt
+		metadata, _ := json.Marshal(scopeAuditMetadata(scope, map[string]any{
+			"actor_name":  actor.Name,
+			"actor_email": actor.Email,
+		}))
+		ipAddr, _ := netip.ParseAddr(iputils.ExtractClientIP(r))
+		err = qtx.InsertAuditLog(ctx, &queries.InsertAuditLogParams{
+			TenantID:     tenantID,
+			ActorID:      actorID,
+			ResourceType: string(auditlog.ResourceIPWhitelist),
+			Action:       string(auditlog.ActionIPScopeUpdated),
+			Outcome:      string(auditlog.OutcomeSuccess),
+			ResourceID:   pgtype.Text{String: id.String(), Valid: true},
+			Metadata:     metadata,
+			IpAddress:    &ipAddr,
+			UserAgent:    pgtype.Text{String: r.UserAgent(), Valid: true},
+		})
+		if err != nil {
+			return errlib.NewError(fmt.Errorf("UpdateIPWhitelistScope: failed to insert audit log: %w", err), http.StatusInternalServerError)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return errlib.NewError(fmt.Errorf("UpdateIPWhitelistScope: failed to commit transaction: %w", err), http.StatusInternalServerError)
+	}
+
+	return nil
+}
@@ -0,0 +1,154 @@
+// Feature doc: docs/features/separation-of-duties.md, docs/features/audit-logging.md
+package roles
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"dislyze/jirachi/auditlog"
+	libctx "dislyze/jirachi/ctx"
+	"dislyze/jirachi/errlib"
+	"lugia/lib/authz"
+	"lugia/lib/iputils"
+	"lugia/lib/middleware"
+	"lugia/queries"
+)
+
+var CreateConflictSetOp = huma.Operation{
+	OperationID: "create-role-conflict-set",
+	Method:      http.MethodPost,
+	Path:        "/roles/conflict-sets/create",
+}
+
+type CreateConflictSetInput struct {
+	Body CreateConflictSetRequestBody
+}
+
+type CreateConflictSetRequestBody struct {
+	Name    string   `json:"name" minLength:"1" maxLength:"255"`
+	RoleIDs []string `json:"role_ids" minItems:"2"`
+}
+
+func (r *CreateConflictSetRequestBody) Resolve(ctx huma.Context) []error {
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" {
+		return []error{fmt.Errorf("name is required")}
+	}
+	return nil
+}
+
+func (h *RolesHandler) CreateConflictSet(ctx context.Context, input *CreateConflictSetInput) (*struct{}, error) {
+	roleIDs, err := parseIncludedRoleIDs("CreateConflictSet", input.Body.RoleIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(roleIDs) < 2 {
+		return nil, errlib.NewErrorWithDetail(fmt.Errorf("CreateConflictSet: a conflict set needs two distinct roles"), http.StatusBadRequest, "2つ以上の異なるロールを選択してください。")
+	}
+
+	if err := h.createConflictSet(ctx, input.Body.Name, roleIDs); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// createConflictSet doesn't check existing assignments: users who already
+// hold conflicting roles show up in GetConflictViolations instead.
+func (h *RolesHandler) createConflictSet(ctx context.Context, name string, roleIDs []pgtype.UUID) error {
+	tenantID := libctx.GetTenantID(ctx)
+
+	tx, err := h.dbConn.Begin(ctx)
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("CreateConflictSet: failed to begin transaction: %w", err), http.StatusInternalServerError)
+	}
+	defer func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errlib.Is(rbErr, pgx.ErrTxClosed) && !errlib.Is(rbErr, sql.ErrTxDone) {
+			errlib.LogError(fmt.Errorf("CreateConflictSet: failed to rollback transaction: %w", rbErr))
+		}
+	}()
+	qtx := h.q.WithTx(tx)
+
+	validRoleIDs, err := qtx.ValidateRolesBelongToTenant(ctx, &queries.ValidateRolesBelongToTenantParams{
+		Column1:  roleIDs,
+		TenantID: tenantID,
+	})
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("CreateConflictSet: failed to validate roles: %w", err), http.StatusInternalServerError)
+	}
+	if len(validRoleIDs) != len(roleIDs) {
+		return errlib.NewErrorWithDetail(fmt.Errorf("CreateConflictSet: some role IDs do not belong to tenant"), http.StatusBadRequest, "一部のロールが無効です。")
+	}
+
+	exists, err := qtx.CheckRoleConflictSetNameExists(ctx, &queries.CheckRoleConflictSetNameExistsParams{
+		TenantID: tenantID,
+		Name:     name,
+	})
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("CreateConflictSet: failed to check name exists: %w", err), http.StatusInternalServerError)
+	}
+	if exists {
+		return errlib.NewErrorWithDetail(fmt.Errorf("CreateConflictSet: conflict set name already exists"), http.StatusBadRequest, "この名前の職務分掌ルールは既に存在します。")
+	}
+
+	setID, err := qtx.CreateRoleConflictSet(ctx, &queries.CreateRoleConflictSetParams{
+		TenantID: tenantID,
+		Name:     name,
+	})
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("CreateConflictSet: failed to create conflict set: %w", err), http.StatusInternalServerError)
+	}
+
+	err = qtx.CreateRoleConflictSetRolesBulk(ctx, &queries.CreateRoleConflictSetRolesBulkParams{
+		ConflictSetID: setID,
+		RoleIds:       roleIDs,
+		TenantID:      tenantID,
+	})
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("CreateConflictSet: failed to add roles to conflict set: %w", err), http.StatusInternalServerError)
+	}
+
+	if authz.TenantHasFeature(ctx, authz.FeatureAuditLog) {
+		r := middleware.GetHTTPRequest(ctx)
+		actor, err := qtx.GetUserByID(ctx, libctx.GetUserID(ctx))
+		if err != nil {
+			return errlib.NewError(fmt.Errorf("CreateConflictSet: failed to get actor for audit log: %w", err), http.StatusInternalServerError)
+		}
+
+		metadata, _ := json.Marshal(map[string]string{
+			"actor_name":        actor.Name,
+			"actor_email":       actor.Email,
+			"conflict_set_name": name,
+		})
+
+		ipAddr, _ := netip.ParseAddr(iputils.ExtractClientIP(r))
+		err = qtx.InsertAuditLog(ctx, &queries.InsertAuditLogParams{
+			TenantID:     tenantID,
+			ActorID:      actor.ID,
+			ResourceType: string(auditlog.ResourceRoleConflictSet),
+			Action:       string(auditlog.ActionCreated),
+			Outcome:      string(auditlog.OutcomeSuccess),
+			ResourceID:   pgtype.Text{String: setID.String(), Valid: true},
+			Metadata:     metadata,
+			IpAddress:    &ipAddr,
+			UserAgent:    pgtype.Text{String: r.UserAgent(), Valid: true},
+		})
+		if err != nil {
+			return errlib.NewError(fmt.Errorf("CreateConflictSet: failed to insert audit log: %w", err), http.StatusInternalServerError)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return errlib.NewError(fmt.Errorf("CreateConflictSet: failed to commit transaction: %w", err), http.StatusInternalServerError)
+	}
+
+	return nil
+}
@@ -35,7 +35,17 @@ type CreateRoleInput struct {
 type CreateRoleRequestBody struct {
 	Name          string   `json:"name" minLength:"1"`
 	Description   string   `json:"description"`
-	PermissionIDs []string `json:"permission_ids" minItems:"1"`
+	PermissionIDs []string `json:"permission_ids"`
+	// IncludedRoleIDs are roles whose permissions the new role carries as
+	// well, transitively.
+	IncludedRoleIDs []string `json:"included_role_ids,omitempty"`
+}
+
+func (r *CreateRoleRequestBody) Resolve(ctx huma.Context) []error {
+	if len(r.PermissionIDs) == 0 && len(r.IncludedRoleIDs) == 0 {
+		return []error{fmt.Errorf("a role needs at least one permission or included role")}
+	}
+	return nil
 }
 
 func (h *RolesHandler) CreateRole(ctx context.Context, input *CreateRoleInput) (*struct{}, error) {
@@ -75,6 +85,11 @@ func (h *RolesHandler) createRole(ctx context.Context, req CreateRoleRequestBody
 		}
 	}
 
+	includedRoleIDs, err := parseIncludedRoleIDs("CreateRole", req.IncludedRoleIDs)
+	if err != nil {
+		return err
+	}
+
 	tx, err := h.dbConn.Begin(ctx)
 	if err != nil {
 		return errlib.NewError(fmt.Errorf("CreateRole: failed to begin transaction: %w", err), http.StatusInternalServerError)
@@ -86,59 +101,103 @@ func (h *RolesHandler) createRole(ctx context.Context, req CreateRoleRequestBody
 	}()
 	qtx := h.q.WithTx(tx)
 
-	description := pgtype.Text{String: req.Description, Valid: req.Description != ""}
-	createdRole, err := qtx.CreateRole(ctx, &queries.CreateRoleParams{
-		TenantID:    tenantID,
-		Name:        req.Name,
-		Description: description,
-		IsDefault:   false,
-	})
+	roleID, err := insertCustomRole(ctx, qtx, "CreateRole", tenantID, req.Name, req.Description, permissionIDs, includedRoleIDs)
 	if err != nil {
-		return errlib.NewError(fmt.Errorf("CreateRole: failed to create role: %w", err), http.StatusInternalServerError)
+		return err
 	}
 
-	err = qtx.CreateRolePermissionsBulk(ctx, &queries.CreateRolePermissionsBulkParams{
-		RoleID:        createdRole.ID,
-		PermissionIds: permissionIDs,
-		TenantID:      tenantID,
+	if err := logRoleCreated(ctx, qtx, "CreateRole", roleID, map[string]string{"role_name": req.Name}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return errlib.NewError(fmt.Errorf("CreateRole: failed to commit transaction: %w", err), http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
+// insertCustomRole creates a custom role with its permissions and included
+// roles. CreateRole, DuplicateRole and CreateRoleFromTemplate all go through
+// it, so a role gets the same checks however it was made.
+func insertCustomRole(ctx context.Context, qtx *queries.Queries, op string, tenantID pgtype.UUID, name, description string, permissionIDs, includedRoleIDs []pgtype.UUID) (pgtype.UUID, error) {
+	createdRole, err := qtx.CreateRole(ctx, &queries.CreateRoleParams{
+		TenantID:    tenantID,
+		Name:        name,
+		Description: pgtype.Text{String: description, Valid: description != ""},
+		IsDefault:   false,
 	})
 	if err != nil {
-		return errlib.NewError(fmt.Errorf("CreateRole: failed to assign permissions to role: %w", err), http.StatusInternalServerError)
+		return pgtype.UUID{}, errlib.NewError(fmt.Errorf("%s: failed to create role: %w", op, err), http.StatusInternalServerError)
 	}
 
-	if authz.TenantHasFeature(ctx, authz.FeatureAuditLog) {
-		r := middleware.GetHTTPRequest(ctx)
-		actor, err := qtx.GetUserByID(ctx, libctx.GetUserID(ctx))
+	if len(permissionIDs) > 0 {
+		err = qtx.CreateRolePermissionsBulk(ctx, &queries.CreateRolePermissionsBulkParams{
+			RoleID:        createdRole.ID,
+			PermissionIds: permissionIDs,
+			TenantID:      tenantID,
+		})
 		if err != nil {
-			return errlib.NewError(fmt.Errorf("CreateRole: failed to get actor for audit log: %w", err), http.StatusInternalServerError)
+			return pgtype.UUID{}, errlib.NewError(fmt.Errorf("%s: failed to assign permissions to role: %w", op, err), http.StatusInternalServerError)
 		}
+	}
 
-		metadata, _ := json.Marshal(map[string]string{
-			"actor_name":  actor.Name,
-			"actor_email": actor.Email,
-			"role_name":   req.Name,
-		})
+	if err := setRoleInclusions(ctx, qtx, op, tenantID, createdRole.ID, includedRoleIDs); err != nil {
+		return pgtype.UUID{}, err
+	}
 
-		ipAddr, _ := netip.ParseAddr(iputils.ExtractClientIP(r))
-		err = qtx.InsertAuditLog(ctx, &queries.InsertAuditLogParams{
-			TenantID:     tenantID,
-			ActorID:      actor.ID,
-			ResourceType: string(auditlog.ResourceRole),
-			Action:       string(auditlog.ActionCreated),
-			Outcome:      string(auditlog.OutcomeSuccess),
-			ResourceID:   pgtype.Text{String: createdRole.ID.String(), Valid: true},
-			Metadata:     metadata,
-			IpAddress:    &ipAddr,
-			UserAgent:    pgtype.Text{String: r.UserAgent(), Valid: true},
-		})
-		if err != nil {
-			return errlib.NewError(fmt.Errorf("CreateRole: failed to insert audit log: %w", err), http.StatusInternalServerError)
-		}
+	return createdRole.ID, nil
+}
+
+// checkRoleNameAvailable returns a 400 when the tenant already has a role
+// called name.
+func checkRoleNameAvailable(ctx context.Context, q *queries.Queries, op string, tenantID pgtype.UUID, name string) error {
+	exists, err := q.CheckRoleNameExists(ctx, &queries.CheckRoleNameExistsParams{
+		TenantID: tenantID,
+		Name:     name,
+		// No role has the nil UUID, so this excludes nothing.
+		ID: pgtype.UUID{Valid: true},
+	})
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("%s: failed to check role name exists: %w", op, err), http.StatusInternalServerError)
 	}
+	if exists {
+		return errlib.NewErrorWithDetail(fmt.Errorf("%s: role name %s already exists", op, name), http.StatusBadRequest, "この名前のロールは既に存在します。")
+	}
+	return nil
+}
 
-	if err := tx.Commit(ctx); err != nil {
-		return errlib.NewError(fmt.Errorf("CreateRole: failed to commit transaction: %w", err), http.StatusInternalServerError)
+// logRoleCreated records a role created audit entry. metadata is extended
+// with the actor's name and email.
+func logRoleCreated(ctx context.Context, qtx *queries.Queries, op string, roleID pgtype.UUID, metadata map[string]string) error {
+	if !authz.TenantHasFeature(ctx, authz.FeatureAuditLog) {
+		return nil
 	}
 
+	r := middleware.GetHTTPRequest(ctx)
+	actor, err := qtx.GetUserByID(ctx, libctx.GetUserID(ctx))
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("%s: failed to get actor for audit log: %w", op, err), http.StatusInternalServerError)
+	}
+
+	metadata["actor_name"] = actor.Name
+	metadata["actor_email"] = actor.Email
+	metadataJSON, _ := json.Marshal(metadata)
+
+	ipAddr, _ := netip.ParseAddr(iputils.ExtractClientIP(r))
+	err = qtx.InsertAuditLog(ctx, &queries.InsertAuditLogParams{
+		TenantID:     libctx.GetTenantID(ctx),
+		ActorID:      actor.ID,
+		ResourceType: string(auditlog.ResourceRole),
+		Action:       string(auditlog.ActionCreated),
+		Outcome:      string(auditlog.OutcomeSuccess),
+		ResourceID:   pgtype.Text{String: roleID.String(), Valid: true},
+		Metadata:     metadataJSON,
+		IpAddress:    &ipAddr,
+		UserAgent:    pgtype.Text{String: r.UserAgent(), Valid: true},
+	})
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("%s: failed to insert audit log: %w", op, err), http.StatusInternalServerError)
+	}
 	return nil
 }
@@ -0,0 +1,115 @@
+// Feature doc: docs/features/separation-of-duties.md, docs/features/audit-logging.md
+package roles
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/netip"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"dislyze/jirachi/auditlog"
+	libctx "dislyze/jirachi/ctx"
+	"dislyze/jirachi/errlib"
+	"lugia/lib/authz"
+	"lugia/lib/iputils"
+	"lugia/lib/middleware"
+	"lugia/queries"
+)
+
+var DeleteConflictSetOp = huma.Operation{
+	OperationID: "delete-role-conflict-set",
+	Method:      http.MethodPost,
+	Path:        "/roles/conflict-sets/{setID}/delete",
+}
+
+type DeleteConflictSetInput struct {
+	SetID string `path:"setID"`
+}
+
+func (h *RolesHandler) DeleteConflictSet(ctx context.Context, input *DeleteConflictSetInput) (*struct{}, error) {
+	var setID pgtype.UUID
+	if err := setID.Scan(input.SetID); err != nil {
+		return nil, errlib.NewError(fmt.Errorf("DeleteConflictSet: invalid conflict set ID format: %w", err), http.StatusBadRequest)
+	}
+
+	if err := h.deleteConflictSet(ctx, setID); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (h *RolesHandler) deleteConflictSet(ctx context.Context, setID pgtype.UUID) error {
+	tenantID := libctx.GetTenantID(ctx)
+
+	set, err := h.q.GetRoleConflictSetByID(ctx, &queries.GetRoleConflictSetByIDParams{
+		ID:       setID,
+		TenantID: tenantID,
+	})
+	if err != nil {
+		if errlib.Is(err, pgx.ErrNoRows) {
+			return errlib.NewError(fmt.Errorf("DeleteConflictSet: conflict set %s not found in tenant %s", setID.String(), tenantID.String()), http.StatusNotFound)
+		}
+		return errlib.NewError(fmt.Errorf("DeleteConflictSet: failed to get conflict set: %w", err), http.StatusInternalServerError)
+	}
+
+	tx, err := h.dbConn.Begin(ctx)
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("DeleteConflictSet: failed to begin transaction: %w", err), http.StatusInternalServerError)
+	}
+	defer func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errlib.Is(rbErr, pgx.ErrTxClosed) && !errlib.Is(rbErr, sql.ErrTxDone) {
+			errlib.LogError(fmt.Errorf("DeleteConflictSet: failed to rollback transaction: %w", rbErr))
+		}
+	}()
+	qtx := h.q.WithTx(tx)
+
+	err = qtx.DeleteRoleConflictSet(ctx, &queries.DeleteRoleConflictSetParams{
+		ID:       setID,
+		TenantID: tenantID,
+	})
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("DeleteConflictSet: failed to delete conflict set: %w", err), http.StatusInternalServerError)
+	}
+
+	if authz.TenantHasFeature(ctx, authz.FeatureAuditLog) {
+		r := middleware.GetHTTPRequest(ctx)
+		actor, err := qtx.GetUserByID(ctx, libctx.GetUserID(ctx))
+		if err != nil {
+			return errlib.NewError(fmt.Errorf("DeleteConflictSet: failed to get actor for audit log: %w", err), http.StatusInternalServerError)
+		}
+
+		metadata, _ := json.Marshal(map[string]string{
+			"actor_name":        actor.Name,
+			"actor_email":       actor.Email,
+			"conflict_set_name": set.Name,
+		})
+
+		ipAddr, _ := netip.ParseAddr(iputils.ExtractClientIP(r))
+		err = qtx.InsertAuditLog(ctx, &queries.InsertAuditLogParams{
+			TenantID:     tenantID,
+			ActorID:      actor.ID,
+			ResourceType: string(auditlog.ResourceRoleConflictSet),
+			Action:       string(auditlog.ActionDeleted),
+			Outcome:      string(auditlog.OutcomeSuccess),
+			ResourceID:   pgtype.Text{String: setID.String(), Valid: true},
+			Metadata:     metadata,
+			IpAddress:    &ipAddr,
+			UserAgent:    pgtype.Text{String: r.UserAgent(), Valid: true},
+		})
+		if err != nil {
+			return errlib.NewError(fmt.Errorf("DeleteConflictSet: failed to insert audit log: %w", err), http.StatusInternalServerError)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return errlib.NewError(fmt.Errorf("DeleteConflictSet: failed to commit transaction: %w", err), http.StatusInternalServerError)
+	}
+
+	return nil
+}
@@ -86,6 +86,25 @@ func (h *RolesHandler) deleteRole(ctx context.Context, roleID pgtype.UUID) error
 	}()
 	qtx := h.q.WithTx(tx)
 
+	// Checked under the lock setRoleInclusions takes, so a concurrent update
+	// can't include the role between the check and the delete; the cascade
+	// would otherwise silently drop the new inclusion.
+	if err := qtx.LockTenantForRoleChange(ctx, tenantID); err != nil {
+		return errlib.NewError(fmt.Errorf("DeleteRole: failed to lock tenant %s: %w", tenantID.String(), err), http.StatusInternalServerError)
+	}
+
+	included, err := qtx.CheckRoleIncluded(ctx, &queries.CheckRoleIncludedParams{
+		IncludedRoleID: roleID,
+		TenantID:       tenantID,
+	})
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("DeleteRole: failed to check if role is included by other roles: %w", err), http.StatusInternalServerError)
+	}
+
+	if included {
+		return errlib.NewErrorWithDetail(fmt.Errorf("DeleteRole: role is included by other roles"), http.StatusBadRequest, "このロールは他のロールに含まれているため削除できません。")
+	}
+
 	err = qtx.DeleteRolePermissions(ctx, &queries.DeleteRolePermissionsParams{
 		RoleID:   roleID,
 		TenantID: tenantID,
@@ -102,6 +121,14 @@ func (h *RolesHandler) deleteRole(ctx context.Context, roleID pgtype.UUID) error
 		return errlib.NewError(fmt.Errorf("DeleteRole: failed to delete role: %w", err), http.StatusInternalServerError)
 	}
 
+	manageable, err := authz.TenantStaysManageable(ctx, qtx, tenantID)
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("DeleteRole: %w", err), http.StatusInternalServerError)
+	}
+	if !manageable {
+		return errlib.NewErrorWithDetail(fmt.Errorf("DeleteRole: deleting role would leave tenant %s without an active administrator", tenantID.String()), http.StatusConflict, authz.LastAdministratorDetail)
+	}
+
 	if authz.TenantHasFeature(ctx, authz.FeatureAuditLog) {
 		r := middleware.GetHTTPRequest(ctx)
 		actor, err := qtx.GetUserByID(ctx, libctx.GetUserID(ctx))
@@ -135,6 +162,7 @@ func (h *RolesHandler) deleteRole(ctx context.Context, roleID pgtype.UUID) error
 	if err := tx.Commit(ctx); err != nil {
 		return errlib.NewError(fmt.Errorf("DeleteRole: failed to commit transaction: %w", err), http.StatusInternalServerError)
 	}
+	authz.InvalidateTenantPermissions(tenantID)
 
 	return nil
 }
@@ -0,0 +1,137 @@
+// Feature doc: docs/features/rbac.md, docs/features/audit-logging.md
+package roles
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	libctx "dislyze/jirachi/ctx"
+	"dislyze/jirachi/errlib"
+	"lugia/queries"
+)
+
+var DuplicateRoleOp = huma.Operation{
+	OperationID: "duplicate-role",
+	Method:      http.MethodPost,
+	Path:        "/roles/{roleID}/duplicate",
+}
+
+type DuplicateRoleInput struct {
+	RoleID string `path:"roleID"`
+	Body   DuplicateRoleRequestBody
+}
+
+type DuplicateRoleRequestBody struct {
+	Name string `json:"name" minLength:"1" maxLength:"255"`
+}
+
+func (r *DuplicateRoleRequestBody) Resolve(ctx huma.Context) []error {
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" {
+		return []error{fmt.Errorf("name is required")}
+	}
+	return nil
+}
+
+func (h *RolesHandler) DuplicateRole(ctx context.Context, input *DuplicateRoleInput) (*struct{}, error) {
+	var roleID pgtype.UUID
+	if err := roleID.Scan(input.RoleID); err != nil {
+		return nil, errlib.NewError(fmt.Errorf("DuplicateRole: invalid role ID format: %w", err), http.StatusBadRequest)
+	}
+
+	if err := h.duplicateRole(ctx, roleID, input.Body.Name); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// duplicateRole copies the role's permissions, included roles and conflict
+// set memberships into a new custom role. Default roles can be duplicated
+// too; the copy is always custom, so it can be edited.
+func (h *RolesHandler) duplicateRole(ctx context.Context, sourceID pgtype.UUID, name string) error {
+	tenantID := libctx.GetTenantID(ctx)
+
+	source, err := h.q.GetRoleByID(ctx, &queries.GetRoleByIDParams{
+		ID:       sourceID,
+		TenantID: tenantID,
+	})
+	if err != nil {
+		if errlib.Is(err, pgx.ErrNoRows) {
+			return errlib.NewError(fmt.Errorf("DuplicateRole: role %s not found in tenant %s", sourceID.String(), tenantID.String()), http.StatusNotFound)
+		}
+		return errlib.NewError(fmt.Errorf("DuplicateRole: failed to get role: %w", err), http.StatusInternalServerError)
+	}
+
+	if err := checkRoleNameAvailable(ctx, h.q, "DuplicateRole", tenantID, name); err != nil {
+		return err
+	}
+
+	tx, err := h.dbConn.Begin(ctx)
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("DuplicateRole: failed to begin transaction: %w", err), http.StatusInternalServerError)
+	}
+	defer func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errlib.Is(rbErr, pgx.ErrTxClosed) && !errlib.Is(rbErr, sql.ErrTxDone) {
+			errlib.LogError(fmt.Errorf("DuplicateRole: failed to rollback transaction: %w", rbErr))
+		}
+	}()
+	qtx := h.q.WithTx(tx)
+
+	permissionIDs, err := qtx.GetRolePermissionIDs(ctx, &queries.GetRolePermissionIDsParams{
+		RoleID:   sourceID,
+		TenantID: tenantID,
+	})
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("DuplicateRole: failed to get permissions of role %s: %w", sourceID.String(), err), http.StatusInternalServerError)
+	}
+
+	includedRoleIDs, err := qtx.GetIncludedRoleIDs(ctx, &queries.GetIncludedRoleIDsParams{
+		RoleID:   sourceID,
+		TenantID: tenantID,
+	})
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("DuplicateRole: failed to get included roles of role %s: %w", sourceID.String(), err), http.StatusInternalServerError)
+	}
+
+	description := ""
+	if source.Description.Valid {
+		description = source.Description.String
+	}
+	roleID, err := insertCustomRole(ctx, qtx, "DuplicateRole", tenantID, name, description, permissionIDs, includedRoleIDs)
+	if err != nil {
+		return err
+	}
+
+	// Without this, duplicating one role of a conflict set would give an
+	// equivalent role the set doesn't know about.
+	err = qtx.CopyRoleConflictSetMemberships(ctx, &queries.CopyRoleConflictSetMembershipsParams{
+		NewRoleID:    roleID,
+		SourceRoleID: sourceID,
+		TenantID:     tenantID,
+	})
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("DuplicateRole: failed to copy conflict set memberships: %w", err), http.StatusInternalServerError)
+	}
+
+	err = logRoleCreated(ctx, qtx, "DuplicateRole", roleID, map[string]string{
+		"role_name":        name,
+		"source_role_id":   sourceID.String(),
+		"source_role_name": source.Name,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return errlib.NewError(fmt.Errorf("DuplicateRole: failed to commit transaction: %w", err), http.StatusInternalServerError)
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+// Feature doc: docs/features/separation-of-duties.md
+package roles
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	libctx "dislyze/jirachi/ctx"
+	"dislyze/jirachi/errlib"
+)
+
+var GetConflictSetsOp = huma.Operation{
+	OperationID: "get-role-conflict-sets",
+	Method:      http.MethodGet,
+	Path:        "/roles/conflict-sets",
+}
+
+type ConflictSetRole struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type ConflictSetInfo struct {
+	ID        string            `json:"id"`
+	Name      string            `json:"name"`
+	Roles     []ConflictSetRole `json:"roles" nullable:"false"`
+	CreatedAt string            `json:"created_at"`
+}
+
+type GetConflictSetsInput struct{}
+
+type GetConflictSetsResponse struct {
+	ConflictSets []ConflictSetInfo `json:"conflict_sets" nullable:"false"`
+}
+
+type GetConflictSetsOutput struct {
+	Body GetConflictSetsResponse
+}
+
+func (h *RolesHandler) GetConflictSets(ctx context.Context, input *GetConflictSetsInput) (*GetConflictSetsOutput, error) {
+	response, err := h.getConflictSets(ctx, libctx.GetTenantID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return &GetConflictSetsOutput{Body: *response}, nil
+}
+
+func (h *RolesHandler) getConflictSets(ctx context.Context, tenantID pgtype.UUID) (*GetConflictSetsResponse, error) {
+	rows, err := h.q.GetRoleConflictSets(ctx, tenantID)
+	if err != nil {
+		return nil, errlib.NewError(fmt.Errorf("GetConflictSets: failed to get conflict sets: %w", err), http.StatusInternalServerError)
+	}
+
+	response := &GetConflictSetsResponse{ConflictSets: []ConflictSetInfo{}}
+	index := map[string]int{}
+	for _, row := range rows {
+		setID := row.ID.String()
+		i, ok := index[setID]
+		if !ok {
+			i = len(response.ConflictSets)
+			index[setID] = i
+			response.ConflictSets = append(response.ConflictSets, ConflictSetInfo{
+				ID:        setID,
+				Name:      row.Name,
+				Roles:     []ConflictSetRole{},
+				CreatedAt: row.CreatedAt.Time.Format(time.RFC3339),
+			})
+		}
+		if row.RoleID.Valid {
+			response.ConflictSets[i].Roles = append(response.ConflictSets[i].Roles, ConflictSetRole{
+				ID:   row.RoleID.String(),
+				Name: row.RoleName.String,
+			})
+		}
+	}
+
+	return response, nil
+}
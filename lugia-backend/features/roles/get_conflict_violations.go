@@ -0,0 +1,95 @@
+// Feature doc: docs/features/separation-of-duties.md
+package roles
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	libctx "dislyze/jirachi/ctx"
+	"dislyze/jirachi/errlib"
+	"lugia/lib/authz"
+)
+
+var GetConflictViolationsOp = huma.Operation{
+	OperationID: "get-role-conflict-violations",
+	Method:      http.MethodGet,
+	Path:        "/roles/conflict-sets/violations",
+}
+
+// ConflictViolation is one member holding two or more roles of a conflict
+// set, directly or through included roles.
+type ConflictViolation struct {
+	UserID    string            `json:"user_id"`
+	UserName  string            `json:"user_name"`
+	UserEmail string            `json:"user_email"`
+	SetID     string            `json:"set_id"`
+	SetName   string            `json:"set_name"`
+	Roles     []ConflictSetRole `json:"roles" nullable:"false"`
+}
+
+type GetConflictViolationsInput struct{}
+
+type GetConflictViolationsResponse struct {
+	Violations []ConflictViolation `json:"violations" nullable:"false"`
+}
+
+type GetConflictViolationsOutput struct {
+	Body GetConflictViolationsResponse
+}
+
+func (h *RolesHandler) GetConflictViolations(ctx context.Context, input *GetConflictViolationsInput) (*GetConflictViolationsOutput, error) {
+	response, err := h.getConflictViolations(ctx, libctx.GetTenantID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return &GetConflictViolationsOutput{Body: *response}, nil
+}
+
+func (h *RolesHandler) getConflictViolations(ctx context.Context, tenantID pgtype.UUID) (*GetConflictViolationsResponse, error) {
+	response := &GetConflictViolationsResponse{Violations: []ConflictViolation{}}
+
+	sod, err := authz.LoadSeparationOfDuties(ctx, h.q, tenantID)
+	if err != nil {
+		return nil, errlib.NewError(fmt.Errorf("GetConflictViolations: %w", err), http.StatusInternalServerError)
+	}
+	if len(sod.Sets) == 0 {
+		return response, nil
+	}
+
+	rows, err := h.q.GetTenantActiveRoleAssignments(ctx, tenantID)
+	if err != nil {
+		return nil, errlib.NewError(fmt.Errorf("GetConflictViolations: failed to get role assignments: %w", err), http.StatusInternalServerError)
+	}
+
+	// Rows are ordered by user, so each user's roles are contiguous.
+	for start := 0; start < len(rows); {
+		end := start
+		roleIDs := []string{}
+		for end < len(rows) && rows[end].UserID == rows[start].UserID {
+			roleIDs = append(roleIDs, rows[end].RoleID.String())
+			end++
+		}
+
+		for _, c := range sod.Conflicts(roleIDs) {
+			roles := make([]ConflictSetRole, len(c.Roles))
+			for i, role := range c.Roles {
+				roles[i] = ConflictSetRole{ID: role.ID, Name: role.Name}
+			}
+			response.Violations = append(response.Violations, ConflictViolation{
+				UserID:    rows[start].UserID.String(),
+				UserName:  rows[start].Name,
+				UserEmail: rows[start].Email,
+				SetID:     c.SetID,
+				SetName:   c.SetName,
+				Roles:     roles,
+			})
+		}
+		start = end
+	}
+
+	return response, nil
+}
@@ -51,9 +51,9 @@ func (h *RolesHandler) getPermissions(ctx context.Context) (*GetPermissionsRespo
 
 	permissionInfos := make([]Permission, 0, len(permissions))
 	for _, permission := range permissions {
-		feature, ok := resourceToFeature[permission.Resource]
+		feature, ok := authz.FeatureForResource(permission.Resource)
 		if !ok {
-			return nil, errlib.NewError(fmt.Errorf("GetPermissions: permission resource %q not in resourceToFeature map", permission.Resource), http.StatusInternalServerError)
+			return nil, errlib.NewError(fmt.Errorf("GetPermissions: permission resource %q not in the permission registry", permission.Resource), http.StatusInternalServerError)
 		}
 		if feature != "" && !authz.TenantHasFeature(ctx, feature) {
 			continue
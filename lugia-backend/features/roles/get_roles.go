@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"sort"
 
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/jackc/pgx/v5"
@@ -15,18 +16,6 @@ import (
 	"lugia/lib/authz"
 )
 
-// resourceToFeature maps every permission resource to its enterprise feature gate.
-// Core resources use "" (always visible). Feature-gated resources use the feature constant.
-// Every resource in the permissions table MUST have an entry here — a missing
-// entry returns 500 to prevent silently exposing ungated permissions.
-var resourceToFeature = map[string]authz.EnterpriseFeature{
-	"tenant":       "",
-	"users":        "",
-	"roles":        "",
-	"ip_whitelist": authz.FeatureIPWhitelist,
-	"audit_log":    authz.FeatureAuditLog,
-}
-
 var GetRolesOp = huma.Operation{
 	OperationID: "get-roles",
 	Method:      http.MethodGet,
@@ -48,12 +37,30 @@ type Permission struct {
 	Description string `json:"description"`
 }
 
+// InheritedPermission is a permission a role carries only through the roles
+// it includes. InheritedFrom lists the names of those roles, nearest first.
+type InheritedPermission struct {
+	ID            string   `json:"id"`
+	Resource      string   `json:"resource"`
+	Action        string   `json:"action"`
+	Description   string   `json:"description"`
+	InheritedFrom []string `json:"inherited_from" nullable:"false"`
+}
+
+type IncludedRole struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
 type RoleInfo struct {
-	ID          string       `json:"id"`
-	Name        string       `json:"name"`
-	Description string       `json:"description"`
-	IsDefault   bool         `json:"is_default"`
-	Permissions []Permission `json:"permissions" nullable:"false"`
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	IsDefault   bool   `json:"is_default"`
+	// Permissions are the role's direct permissions.
+	Permissions          []Permission          `json:"permissions" nullable:"false"`
+	IncludedRoles        []IncludedRole        `json:"included_roles" nullable:"false"`
+	InheritedPermissions []InheritedPermission `json:"inherited_permissions" nullable:"false"`
 }
 
 type GetRolesInput struct{}
@@ -96,18 +103,20 @@ func (h *RolesHandler) getRoles(ctx context.Context, tenantID pgtype.UUID) (*Get
 		if _, exists := roleMap[roleID]; !exists {
 			roleOrder = append(roleOrder, roleID)
 			roleMap[roleID] = &RoleInfo{
-				ID:          roleID,
-				Name:        row.Name,
-				Description: row.Description.String,
-				IsDefault:   row.IsDefault,
-				Permissions: []Permission{},
+				ID:                   roleID,
+				Name:                 row.Name,
+				Description:          row.Description.String,
+				IsDefault:            row.IsDefault,
+				Permissions:          []Permission{},
+				IncludedRoles:        []IncludedRole{},
+				InheritedPermissions: []InheritedPermission{},
 			}
 		}
 
 		if row.PermissionDescription.Valid {
-			feature, ok := resourceToFeature[row.Resource.String]
+			feature, ok := authz.FeatureForResource(row.Resource.String)
 			if !ok {
-				return nil, errlib.NewError(fmt.Errorf("GetRoles: permission resource %q not in resourceToFeature map", row.Resource.String), http.StatusInternalServerError)
+				return nil, errlib.NewError(fmt.Errorf("GetRoles: permission resource %q not in the permission registry", row.Resource.String), http.StatusInternalServerError)
 			}
 			if feature != "" && !authz.TenantHasFeature(ctx, feature) {
 				continue
@@ -122,6 +131,15 @@ func (h *RolesHandler) getRoles(ctx context.Context, tenantID pgtype.UUID) (*Get
 		}
 	}
 
+	inclusionRows, err := h.q.GetTenantRoleInclusions(ctx, tenantID)
+	if err != nil {
+		return nil, errlib.NewError(fmt.Errorf("GetRoles: failed to get role inclusions: %w", err), http.StatusInternalServerError)
+	}
+	inclusions := toRoleInclusions(inclusionRows)
+	for _, roleID := range roleOrder {
+		addInheritedPermissions(roleMap, inclusions, roleID)
+	}
+
 	roleInfos := make([]RoleInfo, len(roleOrder))
 	for i, roleID := range roleOrder {
 		roleInfos[i] = *roleMap[roleID]
@@ -133,3 +151,47 @@ func (h *RolesHandler) getRoles(ctx context.Context, tenantID pgtype.UUID) (*Get
 
 	return response, nil
 }
+
+// addInheritedPermissions fills in the roles roleID includes directly and the
+// permissions it reaches through them that it doesn't already grant itself.
+func addInheritedPermissions(roleMap map[string]*RoleInfo, inclusions authz.RoleInclusions, roleID string) {
+	role := roleMap[roleID]
+	for _, includedID := range inclusions[roleID] {
+		if included, ok := roleMap[includedID]; ok {
+			role.IncludedRoles = append(role.IncludedRoles, IncludedRole{ID: included.ID, Name: included.Name})
+		}
+	}
+
+	direct := make(map[string]bool, len(role.Permissions))
+	for _, p := range role.Permissions {
+		direct[p.ID] = true
+	}
+	index := map[string]int{}
+	for _, includedID := range inclusions.IncludedRoles(roleID) {
+		included, ok := roleMap[includedID]
+		if !ok {
+			continue
+		}
+		for _, p := range included.Permissions {
+			if direct[p.ID] {
+				continue
+			}
+			i, ok := index[p.ID]
+			if !ok {
+				i = len(role.InheritedPermissions)
+				index[p.ID] = i
+				role.InheritedPermissions = append(role.InheritedPermissions, InheritedPermission{
+					ID:            p.ID,
+					Resource:      p.Resource,
+					Action:        p.Action,
+					Description:   p.Description,
+					InheritedFrom: []string{},
+				})
+			}
+			role.InheritedPermissions[i].InheritedFrom = append(role.InheritedPermissions[i].InheritedFrom, included.Name)
+		}
+	}
+	sort.SliceStable(role.InheritedPermissions, func(i, j int) bool {
+		return role.InheritedPermissions[i].Description < role.InheritedPermissions[j].Description
+	})
+}
@@ -0,0 +1,106 @@
+// Feature doc: docs/features/rbac.md, docs/features/separation-of-duties.md
+package roles
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"dislyze/jirachi/errlib"
+	"lugia/lib/authz"
+	"lugia/queries"
+)
+
+func parseIncludedRoleIDs(op string, ids []string) ([]pgtype.UUID, error) {
+	seen := make(map[pgtype.UUID]bool, len(ids))
+	roleIDs := make([]pgtype.UUID, 0, len(ids))
+	for _, idStr := range ids {
+		var roleID pgtype.UUID
+		if err := roleID.Scan(idStr); err != nil {
+			return nil, errlib.NewError(fmt.Errorf("%s: invalid included role ID format %s: %w", op, idStr, err), http.StatusBadRequest)
+		}
+		if seen[roleID] {
+			continue
+		}
+		seen[roleID] = true
+		roleIDs = append(roleIDs, roleID)
+	}
+	return roleIDs, nil
+}
+
+// setRoleInclusions replaces the roles roleID includes. It locks the tenant
+// before reading the inclusion graph, so two concurrent edits can't each add
+// one half of a cycle.
+func setRoleInclusions(ctx context.Context, qtx *queries.Queries, op string, tenantID, roleID pgtype.UUID, includedIDs []pgtype.UUID) error {
+	if err := qtx.LockTenantForRoleChange(ctx, tenantID); err != nil {
+		return errlib.NewError(fmt.Errorf("%s: failed to lock tenant %s: %w", op, tenantID.String(), err), http.StatusInternalServerError)
+	}
+
+	if len(includedIDs) > 0 {
+		validRoleIDs, err := qtx.ValidateRolesBelongToTenant(ctx, &queries.ValidateRolesBelongToTenantParams{
+			Column1:  includedIDs,
+			TenantID: tenantID,
+		})
+		if err != nil {
+			return errlib.NewError(fmt.Errorf("%s: failed to validate included roles: %w", op, err), http.StatusInternalServerError)
+		}
+		if len(validRoleIDs) != len(includedIDs) {
+			return errlib.NewErrorWithDetail(fmt.Errorf("%s: some included role IDs do not belong to tenant", op), http.StatusBadRequest, "一部のロールが無効です。")
+		}
+
+		rows, err := qtx.GetTenantRoleInclusions(ctx, tenantID)
+		if err != nil {
+			return errlib.NewError(fmt.Errorf("%s: failed to get role inclusions: %w", op, err), http.StatusInternalServerError)
+		}
+		graph := toRoleInclusions(rows)
+		included := make([]string, len(includedIDs))
+		for i, id := range includedIDs {
+			included[i] = id.String()
+		}
+		if graph.WouldCreateCycle(roleID.String(), included) {
+			return errlib.NewErrorWithDetail(fmt.Errorf("%s: including %v in role %s would create a cycle", op, included, roleID.String()), http.StatusBadRequest, "ロールの包含関係が循環するため保存できません。")
+		}
+	}
+
+	err := qtx.DeleteRoleInclusions(ctx, &queries.DeleteRoleInclusionsParams{
+		RoleID:   roleID,
+		TenantID: tenantID,
+	})
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("%s: failed to delete existing role inclusions: %w", op, err), http.StatusInternalServerError)
+	}
+
+	if len(includedIDs) > 0 {
+		err = qtx.CreateRoleInclusionsBulk(ctx, &queries.CreateRoleInclusionsBulkParams{
+			RoleID:          roleID,
+			IncludedRoleIds: includedIDs,
+			TenantID:        tenantID,
+		})
+		if err != nil {
+			return errlib.NewError(fmt.Errorf("%s: failed to include roles: %w", op, err), http.StatusInternalServerError)
+		}
+	}
+
+	// A role that reaches two roles of a conflict set would break it for
+	// everyone it's assigned to.
+	sod, err := authz.LoadSeparationOfDuties(ctx, qtx, tenantID)
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("%s: %w", op, err), http.StatusInternalServerError)
+	}
+	if conflicts := sod.Conflicts([]string{roleID.String()}); len(conflicts) > 0 {
+		return errlib.NewErrorWithDetail(fmt.Errorf("%s: role %s would break separation of duties set %s", op, roleID.String(), conflicts[0].SetID), http.StatusConflict, authz.ConflictDetail(conflicts[0]))
+	}
+
+	return nil
+}
+
+func toRoleInclusions(rows []*queries.GetTenantRoleInclusionsRow) authz.RoleInclusions {
+	graph := authz.RoleInclusions{}
+	for _, row := range rows {
+		roleID := row.RoleID.String()
+		graph[roleID] = append(graph[roleID], row.IncludedRoleID.String())
+	}
+	return graph
+}
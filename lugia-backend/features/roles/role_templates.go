@@ -0,0 +1,150 @@
+// Feature doc: docs/features/rbac.md, docs/features/audit-logging.md
+package roles
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/jackc/pgx/v5"
+
+	libctx "dislyze/jirachi/ctx"
+	"dislyze/jirachi/errlib"
+	"lugia/lib/authz"
+)
+
+var GetRoleTemplatesOp = huma.Operation{
+	OperationID: "get-role-templates",
+	Method:      http.MethodGet,
+	Path:        "/roles/templates",
+}
+
+var CreateRoleFromTemplateOp = huma.Operation{
+	OperationID: "create-role-from-template",
+	Method:      http.MethodPost,
+	Path:        "/roles/templates/{templateKey}/create",
+}
+
+type RoleTemplatePermission struct {
+	Resource    string `json:"resource"`
+	Action      string `json:"action"`
+	Description string `json:"description"`
+}
+
+type RoleTemplateInfo struct {
+	Key         string                   `json:"key"`
+	Name        string                   `json:"name"`
+	Description string                   `json:"description"`
+	Permissions []RoleTemplatePermission `json:"permissions" nullable:"false"`
+}
+
+type GetRoleTemplatesInput struct{}
+
+type GetRoleTemplatesResponse struct {
+	Templates []RoleTemplateInfo `json:"templates" nullable:"false"`
+}
+
+type GetRoleTemplatesOutput struct {
+	Body GetRoleTemplatesResponse
+}
+
+func (h *RolesHandler) GetRoleTemplates(ctx context.Context, input *GetRoleTemplatesInput) (*GetRoleTemplatesOutput, error) {
+	templates := make([]RoleTemplateInfo, len(authz.RoleTemplates))
+	for i, t := range authz.RoleTemplates {
+		permissions := make([]RoleTemplatePermission, len(t.Permissions))
+		for j, p := range t.Permissions {
+			permissions[j] = RoleTemplatePermission{
+				Resource:    p.Resource.String(),
+				Action:      p.Action,
+				Description: p.Description,
+			}
+		}
+		templates[i] = RoleTemplateInfo{
+			Key:         t.Key,
+			Name:        t.Name,
+			Description: t.Description,
+			Permissions: permissions,
+		}
+	}
+	return &GetRoleTemplatesOutput{Body: GetRoleTemplatesResponse{Templates: templates}}, nil
+}
+
+type CreateRoleFromTemplateInput struct {
+	TemplateKey string `path:"templateKey"`
+	Body        CreateRoleFromTemplateRequestBody
+}
+
+type CreateRoleFromTemplateRequestBody struct {
+	// Name defaults to the template's name.
+	Name string `json:"name,omitempty" maxLength:"255"`
+}
+
+func (h *RolesHandler) CreateRoleFromTemplate(ctx context.Context, input *CreateRoleFromTemplateInput) (*struct{}, error) {
+	template, ok := authz.LookupRoleTemplate(input.TemplateKey)
+	if !ok {
+		return nil, errlib.NewError(fmt.Errorf("CreateRoleFromTemplate: unknown template %s", input.TemplateKey), http.StatusNotFound)
+	}
+
+	name := strings.TrimSpace(input.Body.Name)
+	if name == "" {
+		name = template.Name
+	}
+
+	if err := h.createRoleFromTemplate(ctx, template, name); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// createRoleFromTemplate grants every template permission, including ones
+// for enterprise features the tenant doesn't have yet; like the 管理者 role's
+// grants, they take effect once the feature is enabled.
+func (h *RolesHandler) createRoleFromTemplate(ctx context.Context, template authz.RoleTemplate, name string) error {
+	tenantID := libctx.GetTenantID(ctx)
+
+	if err := checkRoleNameAvailable(ctx, h.q, "CreateRoleFromTemplate", tenantID, name); err != nil {
+		return err
+	}
+
+	allPermissions, err := h.q.GetAllPermissions(ctx)
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("CreateRoleFromTemplate: failed to get all permissions: %w", err), http.StatusInternalServerError)
+	}
+	permissionIDs, err := template.PermissionIDs(allPermissions)
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("CreateRoleFromTemplate: %w", err), http.StatusInternalServerError)
+	}
+
+	tx, err := h.dbConn.Begin(ctx)
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("CreateRoleFromTemplate: failed to begin transaction: %w", err), http.StatusInternalServerError)
+	}
+	defer func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errlib.Is(rbErr, pgx.ErrTxClosed) && !errlib.Is(rbErr, sql.ErrTxDone) {
+			errlib.LogError(fmt.Errorf("CreateRoleFromTemplate: failed to rollback transaction: %w", rbErr))
+		}
+	}()
+	qtx := h.q.WithTx(tx)
+
+	roleID, err := insertCustomRole(ctx, qtx, "CreateRoleFromTemplate", tenantID, name, template.Description, permissionIDs, nil)
+	if err != nil {
+		return err
+	}
+
+	err = logRoleCreated(ctx, qtx, "CreateRoleFromTemplate", roleID, map[string]string{
+		"role_name": name,
+		"template":  template.Key,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return errlib.NewError(fmt.Errorf("CreateRoleFromTemplate: failed to commit transaction: %w", err), http.StatusInternalServerError)
+	}
+
+	return nil
+}
@@ -37,6 +37,10 @@ type UpdateRoleRequestBody struct {
 	Name          string   `json:"name" minLength:"1"`
 	Description   string   `json:"description"`
 	PermissionIDs []string `json:"permission_ids"`
+	// IncludedRoleIDs replaces the role's included roles, like PermissionIDs
+	// replaces its permissions. When it is omitted the inclusions are left as
+	// they are; an empty list removes them all.
+	IncludedRoleIDs *[]string `json:"included_role_ids,omitempty"`
 }
 
 func (h *RolesHandler) UpdateRole(ctx context.Context, input *UpdateRoleInput) (*struct{}, error) {
@@ -98,6 +102,14 @@ func (h *RolesHandler) updateRole(ctx context.Context, roleID pgtype.UUID, req U
 		}
 	}
 
+	var includedRoleIDs []pgtype.UUID
+	if req.IncludedRoleIDs != nil {
+		includedRoleIDs, err = parseIncludedRoleIDs("UpdateRole", *req.IncludedRoleIDs)
+		if err != nil {
+			return err
+		}
+	}
+
 	if req.Name != role.Name {
 		exists, err := h.q.CheckRoleNameExists(ctx, &queries.CheckRoleNameExistsParams{
 			TenantID: tenantID,
@@ -153,6 +165,20 @@ func (h *RolesHandler) updateRole(ctx context.Context, roleID pgtype.UUID, req U
 		}
 	}
 
+	if req.IncludedRoleIDs != nil {
+		if err := setRoleInclusions(ctx, qtx, "UpdateRole", tenantID, roleID, includedRoleIDs); err != nil {
+			return err
+		}
+	}
+
+	manageable, err := authz.TenantStaysManageable(ctx, qtx, tenantID)
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("UpdateRole: %w", err), http.StatusInternalServerError)
+	}
+	if !manageable {
+		return errlib.NewErrorWithDetail(fmt.Errorf("UpdateRole: updating role would leave tenant %s without an active administrator", tenantID.String()), http.StatusConflict, authz.LastAdministratorDetail)
+	}
+
 	if authz.TenantHasFeature(ctx, authz.FeatureAuditLog) {
 		r := middleware.GetHTTPRequest(ctx)
 		actor, err := qtx.GetUserByID(ctx, libctx.GetUserID(ctx))
@@ -186,6 +212,7 @@ func (h *RolesHandler) updateRole(ctx context.Context, roleID pgtype.UUID, req U
 	if err := tx.Commit(ctx); err != nil {
 		return errlib.NewError(fmt.Errorf("UpdateRole: failed to commit transaction: %w", err), http.StatusInternalServerError)
 	}
+	authz.InvalidateTenantPermissions(tenantID)
 
 	return nil
 }
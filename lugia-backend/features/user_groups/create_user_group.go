@@ -0,0 +1,125 @@
+// Feature doc: docs/features/user-groups.md, docs/features/audit-logging.md
+package user_groups
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/netip"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"dislyze/jirachi/auditlog"
+	libctx "dislyze/jirachi/ctx"
+	"dislyze/jirachi/errlib"
+	"lugia/lib/authz"
+	"lugia/lib/iputils"
+	"lugia/lib/middleware"
+	"lugia/queries"
+)
+
+var CreateUserGroupOp = huma.Operation{
+	OperationID: "create-user-group",
+	Method:      http.MethodPost,
+	Path:        "/users/groups/create",
+}
+
+type CreateUserGroupInput struct {
+	Body UserGroupRequestBody
+}
+
+func (h *UserGroupsHandler) CreateUserGroup(ctx context.Context, input *CreateUserGroupInput) (*struct{}, error) {
+	memberIDs, err := parseUserIDs("CreateUserGroup", input.Body.MemberIDs)
+	if err != nil {
+		return nil, err
+	}
+	adminIDs, err := parseUserIDs("CreateUserGroup", input.Body.AdminIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.createUserGroup(ctx, input.Body.Name, memberIDs, adminIDs); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (h *UserGroupsHandler) createUserGroup(ctx context.Context, name string, memberIDs, adminIDs []pgtype.UUID) error {
+	tenantID := libctx.GetTenantID(ctx)
+	actorID := libctx.GetUserID(ctx)
+
+	tx, err := h.dbConn.Begin(ctx)
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("CreateUserGroup: failed to begin transaction: %w", err), http.StatusInternalServerError)
+	}
+	defer func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errlib.Is(rbErr, pgx.ErrTxClosed) && !errlib.Is(rbErr, sql.ErrTxDone) {
+			errlib.LogError(fmt.Errorf("CreateUserGroup: failed to rollback transaction: %w", rbErr))
+		}
+	}()
+	qtx := h.q.WithTx(tx)
+
+	exists, err := qtx.CheckUserGroupNameExists(ctx, &queries.CheckUserGroupNameExistsParams{
+		TenantID: tenantID,
+		Name:     name,
+	})
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("CreateUserGroup: failed to check name exists: %w", err), http.StatusInternalServerError)
+	}
+	if exists {
+		return errlib.NewErrorWithDetail(fmt.Errorf("CreateUserGroup: user group name already exists"), http.StatusBadRequest, "この名前のグループは既に存在します。")
+	}
+
+	groupID, err := qtx.CreateUserGroup(ctx, &queries.CreateUserGroupParams{
+		TenantID: tenantID,
+		Name:     name,
+	})
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("CreateUserGroup: failed to create user group: %w", err), http.StatusInternalServerError)
+	}
+
+	if err := setGroupUsers(ctx, qtx, "CreateUserGroup", tenantID, groupID, actorID, memberIDs, adminIDs); err != nil {
+		return err
+	}
+
+	if authz.TenantHasFeature(ctx, authz.FeatureAuditLog) {
+		r := middleware.GetHTTPRequest(ctx)
+		actor, err := qtx.GetUserByID(ctx, actorID)
+		if err != nil {
+			return errlib.NewError(fmt.Errorf("CreateUserGroup: failed to get actor for audit log: %w", err), http.StatusInternalServerError)
+		}
+
+		metadata, _ := json.Marshal(map[string]string{
+			"actor_name":  actor.Name,
+			"actor_email": actor.Email,
+			"group_name":  name,
+			"admin_ids":   joinUUIDs(adminIDs),
+		})
+
+		ipAddr, _ := netip.ParseAddr(iputils.ExtractClientIP(r))
+		err = qtx.InsertAuditLog(ctx, &queries.InsertAuditLogParams{
+			TenantID:     tenantID,
+			ActorID:      actor.ID,
+			ResourceType: string(auditlog.ResourceUserGroup),
+			Action:       string(auditlog.ActionCreated),
+			Outcome:      string(auditlog.OutcomeSuccess),
+			ResourceID:   pgtype.Text{String: groupID.String(), Valid: true},
+			Metadata:     metadata,
+			IpAddress:    &ipAddr,
+			UserAgent:    pgtype.Text{String: r.UserAgent(), Valid: true},
+		})
+		if err != nil {
+			return errlib.NewError(fmt.Errorf("CreateUserGroup: failed to insert audit log: %w", err), http.StatusInternalServerError)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return errlib.NewError(fmt.Errorf("CreateUserGroup: failed to commit transaction: %w", err), http.StatusInternalServerError)
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+// Feature doc: docs/features/user-groups.md, docs/features/audit-logging.md
+package user_groups
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/netip"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"dislyze/jirachi/auditlog"
+	libctx "dislyze/jirachi/ctx"
+	"dislyze/jirachi/errlib"
+	"lugia/lib/authz"
+	"lugia/lib/iputils"
+	"lugia/lib/middleware"
+	"lugia/queries"
+)
+
+var DeleteUserGroupOp = huma.Operation{
+	OperationID: "delete-user-group",
+	Method:      http.MethodPost,
+	Path:        "/users/groups/{groupID}/delete",
+}
+
+type DeleteUserGroupInput struct {
+	GroupID string `path:"groupID"`
+}
+
+func (h *UserGroupsHandler) DeleteUserGroup(ctx context.Context, input *DeleteUserGroupInput) (*struct{}, error) {
+	var groupID pgtype.UUID
+	if err := groupID.Scan(input.GroupID); err != nil {
+		return nil, errlib.NewError(fmt.Errorf("DeleteUserGroup: invalid group ID format: %w", err), http.StatusBadRequest)
+	}
+
+	if err := h.deleteUserGroup(ctx, groupID); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// deleteUserGroup removes the group along with its members and admin grants;
+// the users themselves are untouched.
+func (h *UserGroupsHandler) deleteUserGroup(ctx context.Context, groupID pgtype.UUID) error {
+	tenantID := libctx.GetTenantID(ctx)
+
+	group, err := h.q.GetUserGroupByID(ctx, &queries.GetUserGroupByIDParams{
+		ID:       groupID,
+		TenantID: tenantID,
+	})
+	if err != nil {
+		if errlib.Is(err, pgx.ErrNoRows) {
+			return errlib.NewError(fmt.Errorf("DeleteUserGroup: group %s not found in tenant %s", groupID.String(), tenantID.String()), http.StatusNotFound)
+		}
+		return errlib.NewError(fmt.Errorf("DeleteUserGroup: failed to get group: %w", err), http.StatusInternalServerError)
+	}
+
+	tx, err := h.dbConn.Begin(ctx)
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("DeleteUserGroup: failed to begin transaction: %w", err), http.StatusInternalServerError)
+	}
+	defer func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errlib.Is(rbErr, pgx.ErrTxClosed) && !errlib.Is(rbErr, sql.ErrTxDone) {
+			errlib.LogError(fmt.Errorf("DeleteUserGroup: failed to rollback transaction: %w", rbErr))
+		}
+	}()
+	qtx := h.q.WithTx(tx)
+
+	err = qtx.DeleteUserGroup(ctx, &queries.DeleteUserGroupParams{
+		ID:       groupID,
+		TenantID: tenantID,
+	})
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("DeleteUserGroup: failed to delete group: %w", err), http.StatusInternalServerError)
+	}
+
+	if authz.TenantHasFeature(ctx, authz.FeatureAuditLog) {
+		r := middleware.GetHTTPRequest(ctx)
+		actor, err := qtx.GetUserByID(ctx, libctx.GetUserID(ctx))
+		if err != nil {
+			return errlib.NewError(fmt.Errorf("DeleteUserGroup: failed to get actor for audit log: %w", err), http.StatusInternalServerError)
+		}
+
+		metadata, _ := json.Marshal(map[string]string{
+			"actor_name":  actor.Name,
+			"actor_email": actor.Email,
+			"group_name":  group.Name,
+		})
+
+		ipAddr, _ := netip.ParseAddr(iputils.ExtractClientIP(r))
+		err = qtx.InsertAuditLog(ctx, &queries.InsertAuditLogParams{
+			TenantID:     tenantID,
+			ActorID:      actor.ID,
+			ResourceType: string(auditlog.ResourceUserGroup),
+			Action:       string(auditlog.ActionDeleted),
+			Outcome:      string(auditlog.OutcomeSuccess),
+			ResourceID:   pgtype.Text{String: groupID.String(), Valid: true},
+			Metadata:     metadata,
+			IpAddress:    &ipAddr,
+			UserAgent:    pgtype.Text{String: r.UserAgent(), Valid: true},
+		})
+		if err != nil {
+			return errlib.NewError(fmt.Errorf("DeleteUserGroup: failed to insert audit log: %w", err), http.StatusInternalServerError)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return errlib.NewError(fmt.Errorf("DeleteUserGroup: failed to commit transaction: %w", err), http.StatusInternalServerError)
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+// Feature doc: docs/features/user-groups.md
+package user_groups
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	libctx "dislyze/jirachi/ctx"
+	"dislyze/jirachi/errlib"
+)
+
+var GetUserGroupsOp = huma.Operation{
+	OperationID: "get-user-groups",
+	Method:      http.MethodGet,
+	Path:        "/users/groups",
+}
+
+type UserGroupUser struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+type UserGroupInfo struct {
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Members   []UserGroupUser `json:"members" nullable:"false"`
+	Admins    []UserGroupUser `json:"admins" nullable:"false"`
+	CreatedAt string          `json:"created_at"`
+}
+
+type GetUserGroupsInput struct{}
+
+type GetUserGroupsResponse struct {
+	Groups []UserGroupInfo `json:"groups" nullable:"false"`
+}
+
+type GetUserGroupsOutput struct {
+	Body GetUserGroupsResponse
+}
+
+func (h *UserGroupsHandler) GetUserGroups(ctx context.Context, input *GetUserGroupsInput) (*GetUserGroupsOutput, error) {
+	response, err := h.getUserGroups(ctx, libctx.GetTenantID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return &GetUserGroupsOutput{Body: *response}, nil
+}
+
+func (h *UserGroupsHandler) getUserGroups(ctx context.Context, tenantID pgtype.UUID) (*GetUserGroupsResponse, error) {
+	groups, err := h.q.GetUserGroups(ctx, tenantID)
+	if err != nil {
+		return nil, errlib.NewError(fmt.Errorf("GetUserGroups: failed to get groups: %w", err), http.StatusInternalServerError)
+	}
+	members, err := h.q.GetUserGroupMembers(ctx, tenantID)
+	if err != nil {
+		return nil, errlib.NewError(fmt.Errorf("GetUserGroups: failed to get members: %w", err), http.StatusInternalServerError)
+	}
+	admins, err := h.q.GetUserGroupAdmins(ctx, tenantID)
+	if err != nil {
+		return nil, errlib.NewError(fmt.Errorf("GetUserGroups: failed to get admins: %w", err), http.StatusInternalServerError)
+	}
+
+	response := &GetUserGroupsResponse{Groups: make([]UserGroupInfo, len(groups))}
+	index := make(map[string]int, len(groups))
+	for i, g := range groups {
+		index[g.ID.String()] = i
+		response.Groups[i] = UserGroupInfo{
+			ID:        g.ID.String(),
+			Name:      g.Name,
+			Members:   []UserGroupUser{},
+			Admins:    []UserGroupUser{},
+			CreatedAt: g.CreatedAt.Time.Format(time.RFC3339),
+		}
+	}
+	for _, m := range members {
+		i := index[m.GroupID.String()]
+		response.Groups[i].Members = append(response.Groups[i].Members, UserGroupUser{ID: m.UserID.String(), Name: m.Name, Email: m.Email})
+	}
+	for _, a := range admins {
+		i := index[a.GroupID.String()]
+		response.Groups[i].Admins = append(response.Groups[i].Admins, UserGroupUser{ID: a.UserID.String(), Name: a.Name, Email: a.Email})
+	}
+
+	return response, nil
+}
@@ -0,0 +1,122 @@
+// Feature doc: docs/features/user-groups.md
+package user_groups
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"dislyze/jirachi/errlib"
+	"lugia/queries"
+)
+
+type UserGroupsHandler struct {
+	dbConn *pgxpool.Pool
+	q      *queries.Queries
+}
+
+func NewUserGroupsHandler(dbConn *pgxpool.Pool, q *queries.Queries) *UserGroupsHandler {
+	return &UserGroupsHandler{
+		dbConn: dbConn,
+		q:      q,
+	}
+}
+
+// UserGroupRequestBody is shared by create and update; member_ids and
+// admin_ids replace the group's lists.
+type UserGroupRequestBody struct {
+	Name      string   `json:"name" minLength:"1" maxLength:"255"`
+	MemberIDs []string `json:"member_ids"`
+	AdminIDs  []string `json:"admin_ids"`
+}
+
+func (r *UserGroupRequestBody) Resolve(ctx huma.Context) []error {
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" {
+		return []error{fmt.Errorf("name is required")}
+	}
+	return nil
+}
+
+// parseUserIDs parses and de-duplicates ids.
+func parseUserIDs(op string, ids []string) ([]pgtype.UUID, error) {
+	seen := make(map[string]bool, len(ids))
+	result := make([]pgtype.UUID, 0, len(ids))
+	for _, id := range ids {
+		var userID pgtype.UUID
+		if err := userID.Scan(id); err != nil {
+			return nil, errlib.NewError(fmt.Errorf("%s: invalid user ID format %s: %w", op, id, err), http.StatusBadRequest)
+		}
+		if seen[userID.String()] {
+			continue
+		}
+		seen[userID.String()] = true
+		result = append(result, userID)
+	}
+	return result, nil
+}
+
+// setGroupUsers replaces the group's members and admins. Every user must be
+// a member of the tenant.
+func setGroupUsers(ctx context.Context, qtx *queries.Queries, op string, tenantID, groupID, actorID pgtype.UUID, memberIDs, adminIDs []pgtype.UUID) error {
+	for _, ids := range [][]pgtype.UUID{memberIDs, adminIDs} {
+		valid, err := qtx.ValidateUsersBelongToTenant(ctx, &queries.ValidateUsersBelongToTenantParams{
+			TenantID: tenantID,
+			UserIds:  ids,
+		})
+		if err != nil {
+			return errlib.NewError(fmt.Errorf("%s: failed to validate users: %w", op, err), http.StatusInternalServerError)
+		}
+		if len(valid) != len(ids) {
+			return errlib.NewErrorWithDetail(fmt.Errorf("%s: some users are not members of tenant %s", op, tenantID.String()), http.StatusBadRequest, "一部のユーザーが無効です。")
+		}
+	}
+
+	err := qtx.DeleteUserGroupMembers(ctx, &queries.DeleteUserGroupMembersParams{
+		GroupID:  groupID,
+		TenantID: tenantID,
+	})
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("%s: failed to clear members: %w", op, err), http.StatusInternalServerError)
+	}
+	err = qtx.AddUserGroupMembersBulk(ctx, &queries.AddUserGroupMembersBulkParams{
+		GroupID:  groupID,
+		UserIds:  memberIDs,
+		TenantID: tenantID,
+	})
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("%s: failed to add members: %w", op, err), http.StatusInternalServerError)
+	}
+
+	err = qtx.DeleteUserGroupAdmins(ctx, &queries.DeleteUserGroupAdminsParams{
+		GroupID:  groupID,
+		TenantID: tenantID,
+	})
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("%s: failed to clear admins: %w", op, err), http.StatusInternalServerError)
+	}
+	err = qtx.AddUserGroupAdminsBulk(ctx, &queries.AddUserGroupAdminsBulkParams{
+		GroupID:   groupID,
+		UserIds:   adminIDs,
+		TenantID:  tenantID,
+		GrantedBy: actorID,
+	})
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("%s: failed to add admins: %w", op, err), http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
+func joinUUIDs(ids []pgtype.UUID) string {
+	s := make([]string, len(ids))
+	for i, id := range ids {
+		s[i] = id.String()
+	}
+	return strings.Join(s, ",")
+}
@@ -0,0 +1,170 @@
+// Feature doc: docs/features/user-groups.md, docs/features/audit-logging.md
+package user_groups
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/netip"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"dislyze/jirachi/auditlog"
+	libctx "dislyze/jirachi/ctx"
+	"dislyze/jirachi/errlib"
+	"lugia/lib/authz"
+	"lugia/lib/iputils"
+	"lugia/lib/middleware"
+	"lugia/queries"
+)
+
+var UpdateUserGroupOp = huma.Operation{
+	OperationID: "update-user-group",
+	Method:      http.MethodPost,
+	Path:        "/users/groups/{groupID}/update",
+}
+
+type UpdateUserGroupInput struct {
+	GroupID string `path:"groupID"`
+	Body    UserGroupRequestBody
+}
+
+func (h *UserGroupsHandler) UpdateUserGroup(ctx context.Context, input *UpdateUserGroupInput) (*struct{}, error) {
+	var groupID pgtype.UUID
+	if err := groupID.Scan(input.GroupID); err != nil {
+		return nil, errlib.NewError(fmt.Errorf("UpdateUserGroup: invalid group ID format: %w", err), http.StatusBadRequest)
+	}
+	memberIDs, err := parseUserIDs("UpdateUserGroup", input.Body.MemberIDs)
+	if err != nil {
+		return nil, err
+	}
+	adminIDs, err := parseUserIDs("UpdateUserGroup", input.Body.AdminIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.updateUserGroup(ctx, groupID, input.Body.Name, memberIDs, adminIDs); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// updateUserGroup replaces the group's name, members and admins. Admin grants
+// are audited as added and removed IDs since they change who can manage whom.
+func (h *UserGroupsHandler) updateUserGroup(ctx context.Context, groupID pgtype.UUID, name string, memberIDs, adminIDs []pgtype.UUID) error {
+	tenantID := libctx.GetTenantID(ctx)
+	actorID := libctx.GetUserID(ctx)
+
+	tx, err := h.dbConn.Begin(ctx)
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("UpdateUserGroup: failed to begin transaction: %w", err), http.StatusInternalServerError)
+	}
+	defer func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errlib.Is(rbErr, pgx.ErrTxClosed) && !errlib.Is(rbErr, sql.ErrTxDone) {
+			errlib.LogError(fmt.Errorf("UpdateUserGroup: failed to rollback transaction: %w", rbErr))
+		}
+	}()
+	qtx := h.q.WithTx(tx)
+
+	group, err := qtx.GetUserGroupByID(ctx, &queries.GetUserGroupByIDParams{
+		ID:       groupID,
+		TenantID: tenantID,
+	})
+	if err != nil {
+		if errlib.Is(err, pgx.ErrNoRows) {
+			return errlib.NewError(fmt.Errorf("UpdateUserGroup: group %s not found in tenant %s", groupID.String(), tenantID.String()), http.StatusNotFound)
+		}
+		return errlib.NewError(fmt.Errorf("UpdateUserGroup: failed to get group: %w", err), http.StatusInternalServerError)
+	}
+
+	if group.Name != name {
+		exists, err := qtx.CheckUserGroupNameExists(ctx, &queries.CheckUserGroupNameExistsParams{
+			TenantID: tenantID,
+			Name:     name,
+		})
+		if err != nil {
+			return errlib.NewError(fmt.Errorf("UpdateUserGroup: failed to check name exists: %w", err), http.StatusInternalServerError)
+		}
+		if exists {
+			return errlib.NewErrorWithDetail(fmt.Errorf("UpdateUserGroup: user group name already exists"), http.StatusBadRequest, "この名前のグループは既に存在します。")
+		}
+		err = qtx.UpdateUserGroupName(ctx, &queries.UpdateUserGroupNameParams{
+			ID:       groupID,
+			TenantID: tenantID,
+			Name:     name,
+		})
+		if err != nil {
+			return errlib.NewError(fmt.Errorf("UpdateUserGroup: failed to rename group: %w", err), http.StatusInternalServerError)
+		}
+	}
+
+	allAdmins, err := qtx.GetUserGroupAdmins(ctx, tenantID)
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("UpdateUserGroup: failed to get current admins: %w", err), http.StatusInternalServerError)
+	}
+	previous := make(map[pgtype.UUID]bool)
+	for _, a := range allAdmins {
+		if a.GroupID == groupID {
+			previous[a.UserID] = true
+		}
+	}
+	var added, removed []pgtype.UUID
+	next := make(map[pgtype.UUID]bool, len(adminIDs))
+	for _, id := range adminIDs {
+		next[id] = true
+		if !previous[id] {
+			added = append(added, id)
+		}
+	}
+	for id := range previous {
+		if !next[id] {
+			removed = append(removed, id)
+		}
+	}
+
+	if err := setGroupUsers(ctx, qtx, "UpdateUserGroup", tenantID, groupID, actorID, memberIDs, adminIDs); err != nil {
+		return err
+	}
+
+	if authz.TenantHasFeature(ctx, authz.FeatureAuditLog) {
+		r := middleware.GetHTTPRequest(ctx)
+		actor, err := qtx.GetUserByID(ctx, actorID)
+		if err != nil {
+			return errlib.NewError(fmt.Errorf("UpdateUserGroup: failed to get actor for audit log: %w", err), http.StatusInternalServerError)
+		}
+
+		metadata, _ := json.Marshal(map[string]string{
+			"actor_name":        actor.Name,
+			"actor_email":       actor.Email,
+			"group_name":        name,
+			"added_admin_ids":   joinUUIDs(added),
+			"removed_admin_ids": joinUUIDs(removed),
+		})
+
+		ipAddr, _ := netip.ParseAddr(iputils.ExtractClientIP(r))
+		err = qtx.InsertAuditLog(ctx, &queries.InsertAuditLogParams{
+			TenantID:     tenantID,
+			ActorID:      actor.ID,
+			ResourceType: string(auditlog.ResourceUserGroup),
+			Action:       string(auditlog.ActionUpdated),
+			Outcome:      string(auditlog.OutcomeSuccess),
+			ResourceID:   pgtype.Text{String: groupID.String(), Valid: true},
+			Metadata:     metadata,
+			IpAddress:    &ipAddr,
+			UserAgent:    pgtype.Text{String: r.UserAgent(), Valid: true},
+		})
+		if err != nil {
+			return errlib.NewError(fmt.Errorf("UpdateUserGroup: failed to insert audit log: %w", err), http.StatusInternalServerError)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return errlib.NewError(fmt.Errorf("UpdateUserGroup: failed to commit transaction: %w", err), http.StatusInternalServerError)
+	}
+
+	return nil
+}
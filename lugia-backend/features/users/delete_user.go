@@ -1,4 +1,4 @@
-// Feature doc: docs/features/user-management.md, docs/features/audit-logging.md
+// Feature doc: docs/features/user-management.md, docs/features/user-groups.md, docs/features/audit-logging.md
 package users
 
 import (
@@ -64,14 +64,25 @@ func (h *UsersHandler) deleteUser(ctx context.Context, targetUserID, invokerUser
 		return errlib.NewError(fmt.Errorf("DeleteUser: failed to get target user %s: %w", targetUserID.String(), err), http.StatusInternalServerError)
 	}
 
-	if invokerTenantID != targetDBUser.TenantID {
-		return errlib.NewError(fmt.Errorf("DeleteUser: invoker %s (tenant %s) attempting to delete user %s (tenant %s) in different tenant", invokerUserID.String(), invokerTenantID.String(), targetUserID.String(), targetDBUser.TenantID.String()), http.StatusForbidden)
+	isMember, err := h.q.IsTenantMember(ctx, &queries.IsTenantMemberParams{
+		TenantID: invokerTenantID,
+		UserID:   targetUserID,
+	})
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("DeleteUser: failed to check membership of user %s: %w", targetUserID.String(), err), http.StatusInternalServerError)
+	}
+	if !isMember {
+		return errlib.NewError(fmt.Errorf("DeleteUser: invoker %s (tenant %s) attempting to delete user %s who is not a member of the tenant", invokerUserID.String(), invokerTenantID.String(), targetUserID.String()), http.StatusForbidden)
 	}
 
 	if invokerUserID == targetUserID {
 		return errlib.NewErrorWithDetail(fmt.Errorf("DeleteUser: user %s attempting to delete themselves", invokerUserID.String()), http.StatusConflict, "自分自身を削除することはできません。")
 	}
 
+	if err := checkUserInScope(ctx, h.q, "DeleteUser", invokerTenantID, targetUserID); err != nil {
+		return err
+	}
+
 	tx, err := h.dbConn.Begin(ctx)
 	if err != nil {
 		return errlib.NewError(fmt.Errorf("DeleteUser: failed to begin transaction: %w", err), http.StatusInternalServerError)
@@ -83,10 +94,37 @@ func (h *UsersHandler) deleteUser(ctx context.Context, targetUserID, invokerUser
 	}()
 	qtx := h.q.WithTx(tx)
 
-	if err := qtx.MarkUserDeletedAndAnonymize(ctx, targetUserID); err != nil {
+	memberships, err := qtx.ListTenantMembershipsForUser(ctx, targetUserID)
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("DeleteUser: failed to list memberships of user %s: %w", targetUserID.String(), err), http.StatusInternalServerError)
+	}
+
+	// An account that still belongs to other tenants only loses its access to
+	// this one; anonymizing it would lock the user out everywhere.
+	membershipOnly := len(memberships) > 1
+	if membershipOnly {
+		if err := removeTenantMembership(ctx, qtx, targetDBUser, invokerTenantID, memberships); err != nil {
+			return errlib.NewError(fmt.Errorf("DeleteUser: %w", err), http.StatusInternalServerError)
+		}
+	} else if err := qtx.MarkUserDeletedAndAnonymize(ctx, targetUserID); err != nil {
 		return errlib.NewError(fmt.Errorf("DeleteUser: failed to anonymize user %s: %w", targetUserID.String(), err), http.StatusInternalServerError)
 	}
 
+	if err := qtx.RemoveUserFromTenantGroups(ctx, &queries.RemoveUserFromTenantGroupsParams{
+		UserID:   targetUserID,
+		TenantID: invokerTenantID,
+	}); err != nil {
+		return errlib.NewError(fmt.Errorf("DeleteUser: failed to remove user %s from groups: %w", targetUserID.String(), err), http.StatusInternalServerError)
+	}
+
+	manageable, err := authz.TenantStaysManageable(ctx, qtx, invokerTenantID)
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("DeleteUser: %w", err), http.StatusInternalServerError)
+	}
+	if !manageable {
+		return errlib.NewErrorWithDetail(fmt.Errorf("DeleteUser: deleting user would leave tenant %s without an active administrator", invokerTenantID.String()), http.StatusConflict, authz.LastAdministratorDetail)
+	}
+
 	if authz.TenantHasFeature(ctx, authz.FeatureAuditLog) {
 		actorDBUser, err := qtx.GetUserByID(ctx, invokerUserID)
 		if err != nil {
@@ -94,12 +132,16 @@ func (h *UsersHandler) deleteUser(ctx context.Context, targetUserID, invokerUser
 		}
 
 		r := middleware.GetHTTPRequest(ctx)
-		metadata, _ := json.Marshal(map[string]string{
+		metadataMap := map[string]string{
 			"actor_name":         actorDBUser.Name,
 			"actor_email":        actorDBUser.Email,
 			"deleted_user_name":  targetDBUser.Name,
 			"deleted_user_email": targetDBUser.Email,
-		})
+		}
+		if membershipOnly {
+			metadataMap["membership_only"] = "true"
+		}
+		metadata, _ := json.Marshal(metadataMap)
 
 		ipAddr, _ := netip.ParseAddr(iputils.ExtractClientIP(r))
 		err = qtx.InsertAuditLog(ctx, &queries.InsertAuditLogParams{
@@ -121,6 +163,59 @@ func (h *UsersHandler) deleteUser(ctx context.Context, targetUserID, invokerUser
 	if err := tx.Commit(ctx); err != nil {
 		return errlib.NewError(fmt.Errorf("DeleteUser: failed to commit transaction for user %s: %w", targetUserID.String(), err), http.StatusInternalServerError)
 	}
+	authz.InvalidateTenantPermissions(invokerTenantID)
+
+	return nil
+}
+
+// removeTenantMembership detaches a multi-tenant user from tenantID: their
+// roles, pending invitations and sessions in the tenant go away, and their
+// home tenant moves to the next remaining membership when needed.
+func removeTenantMembership(ctx context.Context, qtx *queries.Queries, user *queries.User, tenantID pgtype.UUID, memberships []*queries.ListTenantMembershipsForUserRow) error {
+	if err := qtx.RemoveUserRolesInTenant(ctx, &queries.RemoveUserRolesInTenantParams{
+		UserID:   user.ID,
+		TenantID: tenantID,
+	}); err != nil {
+		return fmt.Errorf("failed to remove roles of user %s: %w", user.ID.String(), err)
+	}
+
+	if err := qtx.DeleteInvitationTokensByUserIDAndTenantID(ctx, &queries.DeleteInvitationTokensByUserIDAndTenantIDParams{
+		UserID:   user.ID,
+		TenantID: tenantID,
+	}); err != nil {
+		return fmt.Errorf("failed to delete invitation tokens of user %s: %w", user.ID.String(), err)
+	}
 
+	isHome := user.TenantID == tenantID
+	if err := qtx.RevokeRefreshTokensForTenant(ctx, &queries.RevokeRefreshTokensForTenantParams{
+		UserID:       user.ID,
+		TenantID:     tenantID,
+		IsHomeTenant: isHome,
+	}); err != nil {
+		return fmt.Errorf("failed to revoke sessions of user %s: %w", user.ID.String(), err)
+	}
+
+	if err := qtx.RemoveTenantMembership(ctx, &queries.RemoveTenantMembershipParams{
+		TenantID: tenantID,
+		UserID:   user.ID,
+	}); err != nil {
+		return fmt.Errorf("failed to remove membership of user %s: %w", user.ID.String(), err)
+	}
+
+	if !isHome {
+		return nil
+	}
+	for _, m := range memberships {
+		if m.ID == tenantID {
+			continue
+		}
+		if err := qtx.UpdateUserHomeTenant(ctx, &queries.UpdateUserHomeTenantParams{
+			TenantID: m.ID,
+			UserID:   user.ID,
+		}); err != nil {
+			return fmt.Errorf("failed to move home tenant of user %s: %w", user.ID.String(), err)
+		}
+		break
+	}
 	return nil
 }
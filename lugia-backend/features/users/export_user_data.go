@@ -0,0 +1,285 @@
+// Feature doc: docs/features/user-management.md, docs/features/profile-management.md, docs/features/audit-logging.md
+package users
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"dislyze/jirachi/auditlog"
+	libctx "dislyze/jirachi/ctx"
+	"dislyze/jirachi/errlib"
+	"lugia/lib/authz"
+	"lugia/lib/iputils"
+	"lugia/lib/middleware"
+	"lugia/queries"
+)
+
+var ExportUserDataOp = huma.Operation{
+	OperationID: "export-user-data",
+	Method:      http.MethodGet,
+	Path:        "/users/{userID}/export",
+}
+
+var ExportMyDataOp = huma.Operation{
+	OperationID: "export-my-data",
+	Method:      http.MethodGet,
+	Path:        "/me/export",
+}
+
+type ExportedProfile struct {
+	ID          string  `json:"id"`
+	Email       string  `json:"email"`
+	Name        string  `json:"name"`
+	Status      string  `json:"status"`
+	SSOLinked   bool    `json:"sso_linked"`
+	CreatedAt   string  `json:"created_at"`
+	UpdatedAt   string  `json:"updated_at"`
+	LastLoginAt *string `json:"last_login_at"`
+}
+
+type ExportedSession struct {
+	ID         string  `json:"id"`
+	DeviceInfo *string `json:"device_info"`
+	IPAddress  *string `json:"ip_address"`
+	CreatedAt  string  `json:"created_at"`
+	ExpiresAt  string  `json:"expires_at"`
+	UsedAt     *string `json:"used_at"`
+	RevokedAt  *string `json:"revoked_at"`
+}
+
+type ExportedAuditLog struct {
+	ID           string          `json:"id"`
+	ActorID      string          `json:"actor_id"`
+	ResourceType string          `json:"resource_type"`
+	Action       string          `json:"action"`
+	Outcome      string          `json:"outcome"`
+	ResourceID   *string         `json:"resource_id"`
+	Metadata     json.RawMessage `json:"metadata"`
+	IPAddress    *string         `json:"ip_address"`
+	UserAgent    *string         `json:"user_agent"`
+	CreatedAt    string          `json:"created_at"`
+}
+
+type UserDataExport struct {
+	ExportedAt string             `json:"exported_at"`
+	Profile    ExportedProfile    `json:"profile"`
+	Roles      []UserRole         `json:"roles" nullable:"false"`
+	Sessions   []ExportedSession  `json:"sessions" nullable:"false"`
+	AuditLogs  []ExportedAuditLog `json:"audit_logs" nullable:"false"`
+}
+
+type ExportUserDataInput struct {
+	UserID string `path:"userID"`
+}
+
+type ExportMyDataInput struct{}
+
+type ExportUserDataOutput struct {
+	ContentDisposition string `header:"Content-Disposition"`
+	Body               UserDataExport
+}
+
+func (h *UsersHandler) ExportUserData(ctx context.Context, input *ExportUserDataInput) (*ExportUserDataOutput, error) {
+	var targetUserID pgtype.UUID
+	if err := targetUserID.Scan(input.UserID); err != nil {
+		return nil, errlib.NewError(fmt.Errorf("ExportUserData: invalid user ID format: %w", err), http.StatusBadRequest)
+	}
+
+	export, err := h.exportUserData(ctx, targetUserID)
+	if err != nil {
+		return nil, err
+	}
+	return &ExportUserDataOutput{ContentDisposition: exportDisposition(export), Body: *export}, nil
+}
+
+func (h *UsersHandler) ExportMyData(ctx context.Context, input *ExportMyDataInput) (*ExportUserDataOutput, error) {
+	export, err := h.exportUserData(ctx, libctx.GetUserID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return &ExportUserDataOutput{ContentDisposition: exportDisposition(export), Body: *export}, nil
+}
+
+// exportDisposition names the download after the user and the export date, so
+// repeated exports of several users don't overwrite each other.
+func exportDisposition(export *UserDataExport) string {
+	return fmt.Sprintf(`attachment; filename="user-data-%s-%s.json"`, export.Profile.ID, export.ExportedAt[:len(time.DateOnly)])
+}
+
+func (h *UsersHandler) exportUserData(ctx context.Context, targetUserID pgtype.UUID) (*UserDataExport, error) {
+	invokerUserID := libctx.GetUserID(ctx)
+	invokerTenantID := libctx.GetTenantID(ctx)
+
+	tx, err := h.dbConn.Begin(ctx)
+	if err != nil {
+		return nil, errlib.NewError(fmt.Errorf("ExportUserData: failed to begin transaction: %w", err), http.StatusInternalServerError)
+	}
+	defer func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errlib.Is(rbErr, pgx.ErrTxClosed) && !errlib.Is(rbErr, sql.ErrTxDone) {
+			errlib.LogError(fmt.Errorf("ExportUserData: failed to rollback transaction: %w", rbErr))
+		}
+	}()
+	qtx := h.q.WithTx(tx)
+
+	targetDBUser, err := qtx.GetUserByID(ctx, targetUserID)
+	if err != nil {
+		if errlib.Is(err, pgx.ErrNoRows) {
+			return nil, errlib.NewError(fmt.Errorf("ExportUserData: target user with ID %s not found: %w", targetUserID.String(), err), http.StatusNotFound)
+		}
+		return nil, errlib.NewError(fmt.Errorf("ExportUserData: failed to get target user %s: %w", targetUserID.String(), err), http.StatusInternalServerError)
+	}
+
+	isMember, err := qtx.IsTenantMember(ctx, &queries.IsTenantMemberParams{
+		TenantID: invokerTenantID,
+		UserID:   targetUserID,
+	})
+	if err != nil {
+		return nil, errlib.NewError(fmt.Errorf("ExportUserData: failed to check membership of user %s: %w", targetUserID.String(), err), http.StatusInternalServerError)
+	}
+	if !isMember {
+		return nil, errlib.NewError(fmt.Errorf("ExportUserData: invoker %s (tenant %s) attempting to export user %s who is not a member of the tenant", invokerUserID.String(), invokerTenantID.String(), targetUserID.String()), http.StatusForbidden)
+	}
+
+	// The impersonation account is invisible to tenant admins everywhere else,
+	// so it must not be discoverable through exports either.
+	if targetDBUser.IsInternalUser && invokerUserID != targetUserID {
+		return nil, errlib.NewError(fmt.Errorf("ExportUserData: user %s is an internal user", targetUserID.String()), http.StatusNotFound)
+	}
+
+	export := &UserDataExport{
+		ExportedAt: time.Now().UTC().Format(time.RFC3339),
+		Profile: ExportedProfile{
+			ID:          targetDBUser.ID.String(),
+			Email:       targetDBUser.Email,
+			Name:        targetDBUser.Name,
+			Status:      targetDBUser.Status,
+			SSOLinked:   targetDBUser.ExternalSsoID.Valid,
+			CreatedAt:   targetDBUser.CreatedAt.Time.Format(time.RFC3339),
+			UpdatedAt:   targetDBUser.UpdatedAt.Time.Format(time.RFC3339),
+			LastLoginAt: formatOptionalTime(targetDBUser.LastLoginAt),
+		},
+		Roles:     []UserRole{},
+		Sessions:  []ExportedSession{},
+		AuditLogs: []ExportedAuditLog{},
+	}
+
+	roleRows, err := qtx.GetUserRolesWithDetails(ctx, &queries.GetUserRolesWithDetailsParams{
+		UserID:   targetUserID,
+		TenantID: invokerTenantID,
+	})
+	if err != nil {
+		return nil, errlib.NewError(fmt.Errorf("ExportUserData: failed to get roles for user %s: %w", targetUserID.String(), err), http.StatusInternalServerError)
+	}
+	for _, row := range roleRows {
+		export.Roles = append(export.Roles, UserRole{
+			ID:          row.ID.String(),
+			Name:        row.Name,
+			Description: row.Description.String,
+		})
+	}
+
+	sessionRows, err := qtx.GetRefreshTokensByUserID(ctx, targetUserID)
+	if err != nil {
+		return nil, errlib.NewError(fmt.Errorf("ExportUserData: failed to get sessions for user %s: %w", targetUserID.String(), err), http.StatusInternalServerError)
+	}
+	for _, row := range sessionRows {
+		export.Sessions = append(export.Sessions, ExportedSession{
+			ID:         row.ID.String(),
+			DeviceInfo: optionalText(row.DeviceInfo),
+			IPAddress:  optionalText(row.IpAddress),
+			CreatedAt:  row.CreatedAt.Time.Format(time.RFC3339),
+			ExpiresAt:  row.ExpiresAt.Time.Format(time.RFC3339),
+			UsedAt:     formatOptionalTime(row.UsedAt),
+			RevokedAt:  formatOptionalTime(row.RevokedAt),
+		})
+	}
+
+	auditRows, err := qtx.ListAuditLogsForUser(ctx, &queries.ListAuditLogsForUserParams{
+		TenantID: invokerTenantID,
+		UserID:   targetUserID,
+	})
+	if err != nil {
+		return nil, errlib.NewError(fmt.Errorf("ExportUserData: failed to get audit logs for user %s: %w", targetUserID.String(), err), http.StatusInternalServerError)
+	}
+	for _, row := range auditRows {
+		entry := ExportedAuditLog{
+			ID:           row.ID.String(),
+			ActorID:      row.ActorID.String(),
+			ResourceType: row.ResourceType,
+			Action:       row.Action,
+			Outcome:      row.Outcome,
+			ResourceID:   optionalText(row.ResourceID),
+			Metadata:     row.Metadata,
+			UserAgent:    optionalText(row.UserAgent),
+			CreatedAt:    row.CreatedAt.Time.Format(time.RFC3339),
+		}
+		if row.IpAddress != nil {
+			s := row.IpAddress.String()
+			entry.IPAddress = &s
+		}
+		export.AuditLogs = append(export.AuditLogs, entry)
+	}
+
+	// Compliance: an export discloses personal data, so like the user list it
+	// must not succeed without a record of who pulled it.
+	if authz.TenantHasFeature(ctx, authz.FeatureAuditLog) {
+		actorDBUser, err := qtx.GetUserByID(ctx, invokerUserID)
+		if err != nil {
+			return nil, errlib.NewError(fmt.Errorf("ExportUserData: failed to get actor user details for audit log: %w", err), http.StatusInternalServerError)
+		}
+
+		r := middleware.GetHTTPRequest(ctx)
+		metadata, _ := json.Marshal(map[string]string{
+			"actor_name":          actorDBUser.Name,
+			"actor_email":         actorDBUser.Email,
+			"exported_user_name":  targetDBUser.Name,
+			"exported_user_email": targetDBUser.Email,
+		})
+
+		ipAddr, _ := netip.ParseAddr(iputils.ExtractClientIP(r))
+		err = qtx.InsertAuditLog(ctx, &queries.InsertAuditLogParams{
+			TenantID:     invokerTenantID,
+			ActorID:      invokerUserID,
+			ResourceType: string(auditlog.ResourceUser),
+			Action:       string(auditlog.ActionDataExported),
+			Outcome:      string(auditlog.OutcomeSuccess),
+			ResourceID:   pgtype.Text{String: targetUserID.String(), Valid: true},
+			Metadata:     metadata,
+			IpAddress:    &ipAddr,
+			UserAgent:    pgtype.Text{String: r.UserAgent(), Valid: true},
+		})
+		if err != nil {
+			return nil, errlib.NewError(fmt.Errorf("ExportUserData: failed to insert audit log: %w", err), http.StatusInternalServerError)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, errlib.NewError(fmt.Errorf("ExportUserData: failed to commit transaction for user %s: %w", targetUserID.String(), err), http.StatusInternalServerError)
+	}
+
+	return export, nil
+}
+
+func optionalText(t pgtype.Text) *string {
+	if !t.Valid {
+		return nil
+	}
+	return &t.String
+}
+
+func formatOptionalTime(t pgtype.Timestamptz) *string {
+	if !t.Valid {
+		return nil
+	}
+	s := t.Time.Format(time.RFC3339)
+	return &s
+}
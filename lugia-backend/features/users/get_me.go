@@ -1,4 +1,4 @@
-// Feature doc: docs/features/profile-management.md
+// Feature doc: docs/features/profile-management.md, docs/features/user-groups.md
 package users
 
 import (
@@ -13,6 +13,7 @@ import (
 	"dislyze/jirachi/authz"
 	libctx "dislyze/jirachi/ctx"
 	"dislyze/jirachi/errlib"
+	libAuthz "lugia/lib/authz"
 	"lugia/queries"
 )
 
@@ -36,6 +37,9 @@ type MeResponse struct {
 	UserName           string                   `json:"user_name"`
 	Permissions        []string                 `json:"permissions" nullable:"false"`
 	EnterpriseFeatures ClientEnterpriseFeatures `json:"enterprise_features"`
+	// AdministeredGroupIDs are the user groups the user is a group admin of,
+	// empty while RBAC is off.
+	AdministeredGroupIDs []string `json:"administered_group_ids" nullable:"false"`
 }
 
 type GetMeInput struct{}
@@ -86,6 +90,17 @@ func (h *UsersHandler) getMe(ctx context.Context) (*MeResponse, error) {
 		return nil, errlib.NewError(fmt.Errorf("GetMe: failed to get user permissions for user %s: %w", userID.String(), err), http.StatusInternalServerError)
 	}
 
+	scope, err := libAuthz.LoadUserScope(ctx, h.q, tenantID, userID)
+	if err != nil {
+		return nil, errlib.NewError(fmt.Errorf("GetMe: %w", err), http.StatusInternalServerError)
+	}
+	administeredGroupIDs := []string{}
+	if scope != nil {
+		for _, id := range scope.GroupIDs {
+			administeredGroupIDs = append(administeredGroupIDs, id.String())
+		}
+	}
+
 	permissionsRes := make([]string, len(permissionRows))
 	for i, row := range permissionRows {
 		permissionsRes[i] = fmt.Sprintf("%s.%s", row.Resource, row.Action)
@@ -102,6 +117,7 @@ func (h *UsersHandler) getMe(ctx context.Context) (*MeResponse, error) {
 			IPWhitelist: enterpriseFeatures.IPWhitelist,
 			AuditLog:    enterpriseFeatures.AuditLog,
 		},
+		AdministeredGroupIDs: administeredGroupIDs,
 	}
 
 	return response, nil
@@ -0,0 +1,68 @@
+// Feature doc: docs/features/authentication.md
+package users
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	libctx "dislyze/jirachi/ctx"
+	"dislyze/jirachi/errlib"
+)
+
+var GetMyTenantsOp = huma.Operation{
+	OperationID: "get-my-tenants",
+	Method:      http.MethodGet,
+	Path:        "/me/tenants",
+}
+
+type MyTenant struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	AuthMethod string `json:"auth_method"`
+	IsHome     bool   `json:"is_home"`
+	IsCurrent  bool   `json:"is_current"`
+}
+
+type GetMyTenantsResponse struct {
+	Tenants []MyTenant `json:"tenants" nullable:"false"`
+}
+
+type GetMyTenantsInput struct{}
+
+type GetMyTenantsOutput struct {
+	Body GetMyTenantsResponse
+}
+
+func (h *UsersHandler) GetMyTenants(ctx context.Context, input *GetMyTenantsInput) (*GetMyTenantsOutput, error) {
+	response, err := h.getMyTenants(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &GetMyTenantsOutput{Body: *response}, nil
+}
+
+func (h *UsersHandler) getMyTenants(ctx context.Context) (*GetMyTenantsResponse, error) {
+	userID := libctx.GetUserID(ctx)
+	tenantID := libctx.GetTenantID(ctx)
+
+	memberships, err := h.q.ListTenantMembershipsForUser(ctx, userID)
+	if err != nil {
+		return nil, errlib.NewError(fmt.Errorf("GetMyTenants: failed to list memberships for user %s: %w", userID.String(), err), http.StatusInternalServerError)
+	}
+
+	tenants := make([]MyTenant, 0, len(memberships))
+	for _, m := range memberships {
+		tenants = append(tenants, MyTenant{
+			ID:         m.ID.String(),
+			Name:       m.Name,
+			AuthMethod: m.AuthMethod,
+			IsHome:     m.IsHome,
+			IsCurrent:  m.ID == tenantID,
+		})
+	}
+
+	return &GetMyTenantsResponse{Tenants: tenants}, nil
+}
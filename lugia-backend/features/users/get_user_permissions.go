@@ -0,0 +1,188 @@
+// Feature doc: docs/features/rbac.md
+package users
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	libctx "dislyze/jirachi/ctx"
+	"dislyze/jirachi/errlib"
+	"lugia/lib/authz"
+	"lugia/queries"
+)
+
+var GetUserPermissionsOp = huma.Operation{
+	OperationID: "get-user-permissions",
+	Method:      http.MethodGet,
+	Path:        "/users/{userID}/permissions",
+}
+
+type PermissionGrantingRole struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	ViaFallback bool   `json:"via_fallback"`
+	// InheritedFrom is the included role that carries the permission, when
+	// the assigned role only has it through composition.
+	InheritedFrom *string `json:"inherited_from,omitempty"`
+}
+
+type EffectivePermission struct {
+	Resource  string                   `json:"resource"`
+	Action    string                   `json:"action"`
+	GrantedBy []PermissionGrantingRole `json:"granted_by" nullable:"false"`
+}
+
+type PermissionCheckResult struct {
+	Resource  string                   `json:"resource"`
+	Action    string                   `json:"action"`
+	Allowed   bool                     `json:"allowed"`
+	Reason    string                   `json:"reason" enum:"granted,view_implied,not_granted,feature_disabled"`
+	GrantedBy []PermissionGrantingRole `json:"granted_by" nullable:"false"`
+}
+
+type GetUserPermissionsResponse struct {
+	RBACEnabled      bool                     `json:"rbac_enabled"`
+	FallbackInEffect bool                     `json:"fallback_in_effect"`
+	IgnoredRoles     []PermissionGrantingRole `json:"ignored_roles" nullable:"false"`
+	Permissions      []EffectivePermission    `json:"permissions" nullable:"false"`
+	Check            *PermissionCheckResult   `json:"check,omitempty"`
+}
+
+type GetUserPermissionsInput struct {
+	UserID   string `path:"userID"`
+	Resource string `query:"resource"`
+	Action   string `query:"action"`
+}
+
+type GetUserPermissionsOutput struct {
+	Body GetUserPermissionsResponse
+}
+
+func (h *UsersHandler) GetUserPermissions(ctx context.Context, input *GetUserPermissionsInput) (*GetUserPermissionsOutput, error) {
+	var targetUserID pgtype.UUID
+	if err := targetUserID.Scan(input.UserID); err != nil {
+		return nil, errlib.NewError(fmt.Errorf("GetUserPermissions: invalid user ID format: %w", err), http.StatusBadRequest)
+	}
+
+	var check *authz.Permission
+	if input.Resource != "" || input.Action != "" {
+		permission, ok := authz.LookupPermission(input.Resource, input.Action)
+		if !ok {
+			return nil, errlib.NewError(fmt.Errorf("GetUserPermissions: unknown permission %q.%q", input.Resource, input.Action), http.StatusBadRequest)
+		}
+		check = &permission
+	}
+
+	response, err := h.getUserPermissions(ctx, targetUserID, check)
+	if err != nil {
+		return nil, err
+	}
+	return &GetUserPermissionsOutput{Body: *response}, nil
+}
+
+func (h *UsersHandler) getUserPermissions(ctx context.Context, targetUserID pgtype.UUID, check *authz.Permission) (*GetUserPermissionsResponse, error) {
+	tenantID := libctx.GetTenantID(ctx)
+
+	isMember, err := h.q.IsTenantMember(ctx, &queries.IsTenantMemberParams{
+		TenantID: tenantID,
+		UserID:   targetUserID,
+	})
+	if err != nil {
+		return nil, errlib.NewError(fmt.Errorf("GetUserPermissions: failed to check membership of user %s: %w", targetUserID.String(), err), http.StatusInternalServerError)
+	}
+	if !isMember {
+		return nil, errlib.NewError(fmt.Errorf("GetUserPermissions: user %s is not a member of tenant %s", targetUserID.String(), tenantID.String()), http.StatusNotFound)
+	}
+
+	userRows, err := h.q.GetUserRoleGrants(ctx, &queries.GetUserRoleGrantsParams{
+		UserID:   targetUserID,
+		TenantID: tenantID,
+	})
+	if err != nil {
+		return nil, errlib.NewError(fmt.Errorf("GetUserPermissions: failed to get role grants for user %s: %w", targetUserID.String(), err), http.StatusInternalServerError)
+	}
+	userGrants := make([]authz.RoleGrant, 0, len(userRows))
+	for _, row := range userRows {
+		userGrants = append(userGrants, authz.RoleGrant{
+			RoleID:        row.RoleID.String(),
+			RoleName:      row.RoleName,
+			IsDefault:     row.IsDefault,
+			InheritedFrom: row.InheritedFrom.String,
+			Resource:      row.Resource.String,
+			Action:        row.Action.String,
+		})
+	}
+
+	viewerRows, err := h.q.GetViewerRoleGrants(ctx, tenantID)
+	if err != nil {
+		return nil, errlib.NewError(fmt.Errorf("GetUserPermissions: failed to get viewer role grants: %w", err), http.StatusInternalServerError)
+	}
+	viewerGrants := make([]authz.RoleGrant, 0, len(viewerRows))
+	for _, row := range viewerRows {
+		viewerGrants = append(viewerGrants, authz.RoleGrant{
+			RoleID:    row.RoleID.String(),
+			RoleName:  row.RoleName,
+			IsDefault: row.IsDefault,
+			Resource:  row.Resource.String,
+			Action:    row.Action.String,
+		})
+	}
+
+	effective := authz.ResolveEffectivePermissions(userGrants, viewerGrants, authz.TenantHasFeature(ctx, authz.FeatureRBAC))
+
+	response := &GetUserPermissionsResponse{
+		RBACEnabled:      effective.RBACEnabled,
+		FallbackInEffect: effective.FallbackInEffect,
+		IgnoredRoles:     toPermissionGrantingRoles(effective.IgnoredRoles),
+		Permissions:      make([]EffectivePermission, 0, len(effective.Permissions)),
+	}
+
+	for _, p := range effective.Permissions {
+		feature, ok := authz.FeatureForResource(p.Resource)
+		if !ok {
+			return nil, errlib.NewError(fmt.Errorf("GetUserPermissions: permission resource %q not in the permission registry", p.Resource), http.StatusInternalServerError)
+		}
+		if feature != "" && !authz.TenantHasFeature(ctx, feature) {
+			continue
+		}
+		response.Permissions = append(response.Permissions, EffectivePermission{
+			Resource:  p.Resource,
+			Action:    p.Action,
+			GrantedBy: toPermissionGrantingRoles(p.GrantedBy),
+		})
+	}
+
+	if check != nil {
+		decision := effective.Check(*check, check.Feature == "" || authz.TenantHasFeature(ctx, check.Feature))
+		response.Check = &PermissionCheckResult{
+			Resource:  check.Resource.String(),
+			Action:    check.Action,
+			Allowed:   decision.Allowed,
+			Reason:    string(decision.Reason),
+			GrantedBy: toPermissionGrantingRoles(decision.GrantedBy),
+		}
+	}
+
+	return response, nil
+}
+
+func toPermissionGrantingRoles(roles []authz.GrantingRole) []PermissionGrantingRole {
+	out := make([]PermissionGrantingRole, 0, len(roles))
+	for _, r := range roles {
+		role := PermissionGrantingRole{
+			ID:          r.ID,
+			Name:        r.Name,
+			ViaFallback: r.ViaFallback,
+		}
+		if r.InheritedFrom != "" {
+			inheritedFrom := r.InheritedFrom
+			role.InheritedFrom = &inheritedFrom
+		}
+		out = append(out, role)
+	}
+	return out
+}
@@ -7,8 +7,8 @@ import (
 	"fmt"
 	"net/http"
 	"net/netip"
+	"time"
 
-	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 
 	"dislyze/jirachi/auditlog"
@@ -37,30 +37,52 @@ type UserRole struct {
 }
 
 type UserInfo struct {
-	ID        string     `json:"id"`
-	Email     string     `json:"email"`
-	Name      string     `json:"name"`
-	Status    string     `json:"status"`
-	CreatedAt string     `json:"created_at"`
-	UpdatedAt string     `json:"updated_at"`
-	Roles     []UserRole `json:"roles" nullable:"false"`
+	ID          string     `json:"id"`
+	Email       string     `json:"email"`
+	Name        string     `json:"name"`
+	Status      string     `json:"status"`
+	CreatedAt   string     `json:"created_at"`
+	UpdatedAt   string     `json:"updated_at"`
+	LastLoginAt *string    `json:"last_login_at"`
+	Roles       []UserRole `json:"roles" nullable:"false"`
 }
 
 type GetUsersResponse struct {
 	Users      []UserInfo                    `json:"users" nullable:"false"`
 	Pagination pagination.PaginationMetadata `json:"pagination"`
+	NextCursor *string                       `json:"next_cursor"`
 }
 
+// When Cursor is set the list continues after it and Page is ignored.
 type GetUsersInput struct {
-	Page   int    `query:"page" default:"1" minimum:"1"`
-	Limit  int    `query:"limit" default:"50" minimum:"1" maximum:"100"`
-	Search string `query:"search" maxLength:"100"`
+	Page          int    `query:"page" default:"1" minimum:"1"`
+	Limit         int    `query:"limit" default:"50" minimum:"1" maximum:"100"`
+	Search        string `query:"search" maxLength:"100"`
+	Status        string `query:"status" enum:"active,pending_verification,suspended"`
+	RoleID        string `query:"role_id"`
+	SSOLinked     string `query:"sso_linked" enum:"true,false"`
+	CreatedFrom   string `query:"created_from"`
+	CreatedTo     string `query:"created_to"`
+	LastLoginFrom string `query:"last_login_from"`
+	LastLoginTo   string `query:"last_login_to"`
+	Sort          string `query:"sort" default:"created_at" enum:"created_at,last_login_at,name,email"`
+	Order         string `query:"order" default:"desc" enum:"asc,desc"`
+	Cursor        string `query:"cursor"`
 }
 
 type GetUsersOutput struct {
 	Body GetUsersResponse
 }
 
+type userListParams struct {
+	filters    queries.CountUsersFilteredParams
+	sortBy     string
+	order      string
+	cursorID   pgtype.UUID
+	cursorTime pgtype.Timestamptz
+	cursorText string
+}
+
 func (h *UsersHandler) GetUsers(ctx context.Context, input *GetUsersInput) (*GetUsersOutput, error) {
 	tenantID := libctx.GetTenantID(ctx)
 
@@ -73,20 +95,102 @@ func (h *UsersHandler) GetUsers(ctx context.Context, input *GetUsersInput) (*Get
 		return nil, huma.Error400BadRequest("invalid page/limit combination", err)
 	}
 
+	listParams, err := buildUserListParams(tenantID, input)
+	if err != nil {
+		return nil, err
+	}
+	if listParams.cursorID.Valid {
+		offset = 0
+	}
+
 	paginationParams := pagination.QueryParams{
 		Page:   input.Page,
 		Limit:  limit,
 		Offset: offset,
 	}
 
-	response, err := h.getUsers(ctx, tenantID, paginationParams, input.Search)
+	response, err := h.getUsers(ctx, tenantID, paginationParams, listParams)
 	if err != nil {
 		return nil, err
 	}
 	return &GetUsersOutput{Body: *response}, nil
 }
 
-func (h *UsersHandler) getUsers(ctx context.Context, tenantID pgtype.UUID, paginationParams pagination.QueryParams, searchTerm string) (*GetUsersResponse, error) {
+func buildUserListParams(tenantID pgtype.UUID, input *GetUsersInput) (*userListParams, error) {
+	params := &userListParams{
+		filters: queries.CountUsersFilteredParams{
+			TenantID:   tenantID,
+			SearchTerm: input.Search,
+			Status:     input.Status,
+		},
+		sortBy: input.Sort,
+		order:  input.Order,
+	}
+
+	if input.RoleID != "" {
+		if err := params.filters.RoleID.Scan(input.RoleID); err != nil {
+			return nil, huma.Error400BadRequest("invalid role_id format")
+		}
+	}
+
+	if input.SSOLinked != "" {
+		params.filters.SsoLinked = pgtype.Bool{Bool: input.SSOLinked == "true", Valid: true}
+	}
+
+	dateFilters := []struct {
+		name  string
+		value string
+		dest  *pgtype.Timestamptz
+	}{
+		{"created_from", input.CreatedFrom, &params.filters.CreatedFrom},
+		{"created_to", input.CreatedTo, &params.filters.CreatedTo},
+		{"last_login_from", input.LastLoginFrom, &params.filters.LastLoginFrom},
+		{"last_login_to", input.LastLoginTo, &params.filters.LastLoginTo},
+	}
+	for _, f := range dateFilters {
+		if f.value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, f.value)
+		if err != nil {
+			return nil, huma.Error400BadRequest(fmt.Sprintf("invalid %s format, expected RFC3339", f.name))
+		}
+		*f.dest = pgtype.Timestamptz{Time: t, Valid: true}
+	}
+
+	if input.Cursor != "" {
+		cursor, err := pagination.DecodeCursor(input.Cursor)
+		if err != nil {
+			return nil, huma.Error400BadRequest("invalid cursor")
+		}
+		if cursor.Sort != input.Sort || cursor.Order != input.Order {
+			return nil, huma.Error400BadRequest("cursor does not match sort and order")
+		}
+		if err := params.cursorID.Scan(cursor.ID); err != nil {
+			return nil, huma.Error400BadRequest("invalid cursor")
+		}
+
+		switch input.Sort {
+		case "created_at", "last_login_at":
+			// An empty value marks a user who never logged in, which the query sorts as -infinity.
+			if cursor.Value == "" {
+				params.cursorTime = pgtype.Timestamptz{InfinityModifier: pgtype.NegativeInfinity, Valid: true}
+				break
+			}
+			t, err := time.Parse(time.RFC3339Nano, cursor.Value)
+			if err != nil {
+				return nil, huma.Error400BadRequest("invalid cursor")
+			}
+			params.cursorTime = pgtype.Timestamptz{Time: t, Valid: true}
+		default:
+			params.cursorText = cursor.Value
+		}
+	}
+
+	return params, nil
+}
+
+func (h *UsersHandler) getUsers(ctx context.Context, tenantID pgtype.UUID, paginationParams pagination.QueryParams, listParams *userListParams) (*GetUsersResponse, error) {
 	// Compliance: audit log failure must block the request. If we can't prove
 	// who accessed personal data, we must deny access (GDPR Article 30).
 	if libAuthz.TenantHasFeature(ctx, libAuthz.FeatureAuditLog) {
@@ -119,30 +223,28 @@ func (h *UsersHandler) getUsers(ctx context.Context, tenantID pgtype.UUID, pagin
 		}
 	}
 
-	totalCount, err := h.q.CountUsersByTenantID(ctx, &queries.CountUsersByTenantIDParams{
-		TenantID: tenantID,
-		Column2:  searchTerm,
-	})
+	filters := listParams.filters
+	totalCount, err := h.q.CountUsersFiltered(ctx, &filters)
 	if err != nil {
 		return nil, errlib.NewError(fmt.Errorf("GetUsers: failed to count users: %w", err), http.StatusInternalServerError)
 	}
 
-	usersWithRoles, err := h.q.GetUsersWithRolesRespectingRBAC(ctx, &queries.GetUsersWithRolesRespectingRBACParams{
-		TenantID:    tenantID,
-		SearchTerm:  searchTerm,
-		LimitCount:  paginationParams.Limit,
-		OffsetCount: paginationParams.Offset,
+	// Fetch one extra user to learn whether another page follows without a second query.
+	userIDs, err := h.listUserIDs(ctx, listParams, paginationParams.Offset, paginationParams.Limit+1)
+	if err != nil {
+		return nil, errlib.NewError(fmt.Errorf("GetUsers: failed to list users: %w", err), http.StatusInternalServerError)
+	}
+	hasMore := len(userIDs) > int(paginationParams.Limit)
+	if hasMore {
+		userIDs = userIDs[:paginationParams.Limit]
+	}
+
+	usersWithRoles, err := h.q.GetUsersWithRolesByIDs(ctx, &queries.GetUsersWithRolesByIDsParams{
+		UserIds:     userIDs,
+		TenantID:    filters.TenantID,
 		RbacEnabled: libAuthz.TenantHasFeature(ctx, libAuthz.FeatureRBAC),
 	})
 	if err != nil {
-		if errlib.Is(err, pgx.ErrNoRows) {
-			paginationMetadata := pagination.CalculateMetadata(paginationParams.Page, paginationParams.Limit, totalCount)
-			response := &GetUsersResponse{
-				Users:      []UserInfo{},
-				Pagination: paginationMetadata,
-			}
-			return response, nil
-		}
 		return nil, errlib.NewError(fmt.Errorf("GetUsers: failed to get users with roles: %w", err), http.StatusInternalServerError)
 	}
 
@@ -153,15 +255,20 @@ func (h *UsersHandler) getUsers(ctx context.Context, tenantID pgtype.UUID, pagin
 
 		if _, exists := userMap[userID]; !exists {
 			userOrder = append(userOrder, userID)
-			userMap[userID] = &UserInfo{
+			userInfo := &UserInfo{
 				ID:        userID,
 				Email:     row.Email,
 				Name:      row.Name,
 				Status:    row.Status,
-				CreatedAt: row.CreatedAt.Time.Format("2006-01-02T15:04:05Z07:00"),
-				UpdatedAt: row.UpdatedAt.Time.Format("2006-01-02T15:04:05Z07:00"),
+				CreatedAt: row.CreatedAt.Time.Format(time.RFC3339),
+				UpdatedAt: row.UpdatedAt.Time.Format(time.RFC3339),
 				Roles:     []UserRole{},
 			}
+			if row.LastLoginAt.Valid {
+				lastLoginAt := row.LastLoginAt.Time.Format(time.RFC3339)
+				userInfo.LastLoginAt = &lastLoginAt
+			}
+			userMap[userID] = userInfo
 		}
 
 		role := UserRole{
@@ -172,6 +279,22 @@ func (h *UsersHandler) getUsers(ctx context.Context, tenantID pgtype.UUID, pagin
 		userMap[userID].Roles = append(userMap[userID].Roles, role)
 	}
 
+	var nextCursor *string
+	if hasMore && len(userOrder) > 0 {
+		last := lastRowForUser(usersWithRoles, userOrder[len(userOrder)-1])
+
+		cursor, err := pagination.EncodeCursor(pagination.Cursor{
+			Sort:  listParams.sortBy,
+			Order: listParams.order,
+			Value: cursorValue(last, listParams.sortBy),
+			ID:    last.ID.String(),
+		})
+		if err != nil {
+			return nil, errlib.NewError(fmt.Errorf("GetUsers: %w", err), http.StatusInternalServerError)
+		}
+		nextCursor = &cursor
+	}
+
 	userInfos := make([]UserInfo, len(userOrder))
 	for i, userID := range userOrder {
 		userInfos[i] = *userMap[userID]
@@ -182,7 +305,97 @@ func (h *UsersHandler) getUsers(ctx context.Context, tenantID pgtype.UUID, pagin
 	response := &GetUsersResponse{
 		Users:      userInfos,
 		Pagination: paginationMetadata,
+		NextCursor: nextCursor,
 	}
 
 	return response, nil
 }
+
+// listUserIDs runs the ListUserIDsBy* query for the requested sort. Each sort
+// has its own query so Postgres can walk its index; the time-sorted and the
+// text-sorted queries share parameter types, so one set of params serves both
+// directions of each.
+func (h *UsersHandler) listUserIDs(ctx context.Context, listParams *userListParams, offset, limit int32) ([]pgtype.UUID, error) {
+	f := listParams.filters
+	timeParams := queries.ListUserIDsByCreatedAtDescParams{
+		TenantID:      f.TenantID,
+		SearchTerm:    f.SearchTerm,
+		Status:        f.Status,
+		RoleID:        f.RoleID,
+		SsoLinked:     f.SsoLinked,
+		CreatedFrom:   f.CreatedFrom,
+		CreatedTo:     f.CreatedTo,
+		LastLoginFrom: f.LastLoginFrom,
+		LastLoginTo:   f.LastLoginTo,
+		CursorID:      listParams.cursorID,
+		CursorTime:    listParams.cursorTime,
+		OffsetCount:   offset,
+		LimitCount:    limit,
+	}
+	textParams := queries.ListUserIDsByNameDescParams{
+		TenantID:      f.TenantID,
+		SearchTerm:    f.SearchTerm,
+		Status:        f.Status,
+		RoleID:        f.RoleID,
+		SsoLinked:     f.SsoLinked,
+		CreatedFrom:   f.CreatedFrom,
+		CreatedTo:     f.CreatedTo,
+		LastLoginFrom: f.LastLoginFrom,
+		LastLoginTo:   f.LastLoginTo,
+		CursorID:      listParams.cursorID,
+		CursorText:    listParams.cursorText,
+		OffsetCount:   offset,
+		LimitCount:    limit,
+	}
+	desc := listParams.order == "desc"
+
+	switch listParams.sortBy {
+	case "last_login_at":
+		if desc {
+			return h.q.ListUserIDsByLastLoginAtDesc(ctx, (*queries.ListUserIDsByLastLoginAtDescParams)(&timeParams))
+		}
+		return h.q.ListUserIDsByLastLoginAtAsc(ctx, (*queries.ListUserIDsByLastLoginAtAscParams)(&timeParams))
+	case "name":
+		if desc {
+			return h.q.ListUserIDsByNameDesc(ctx, &textParams)
+		}
+		return h.q.ListUserIDsByNameAsc(ctx, (*queries.ListUserIDsByNameAscParams)(&textParams))
+	case "email":
+		if desc {
+			return h.q.ListUserIDsByEmailDesc(ctx, (*queries.ListUserIDsByEmailDescParams)(&textParams))
+		}
+		return h.q.ListUserIDsByEmailAsc(ctx, (*queries.ListUserIDsByEmailAscParams)(&textParams))
+	default:
+		if desc {
+			return h.q.ListUserIDsByCreatedAtDesc(ctx, &timeParams)
+		}
+		return h.q.ListUserIDsByCreatedAtAsc(ctx, (*queries.ListUserIDsByCreatedAtAscParams)(&timeParams))
+	}
+}
+
+func lastRowForUser(rows []*queries.GetUsersWithRolesByIDsRow, userID string) *queries.GetUsersWithRolesByIDsRow {
+	for i := len(rows) - 1; i >= 0; i-- {
+		if rows[i].ID.String() == userID {
+			return rows[i]
+		}
+	}
+	return nil
+}
+
+// cursorValue must round-trip exactly through buildUserListParams, so
+// timestamps keep full precision rather than the second-level API format.
+func cursorValue(row *queries.GetUsersWithRolesByIDsRow, sortBy string) string {
+	switch sortBy {
+	case "last_login_at":
+		if !row.LastLoginAt.Valid {
+			return ""
+		}
+		return row.LastLoginAt.Time.Format(time.RFC3339Nano)
+	case "name":
+		return row.Name
+	case "email":
+		return row.Email
+	default:
+		return row.CreatedAt.Time.Format(time.RFC3339Nano)
+	}
+}
@@ -1,4 +1,4 @@
-// Feature doc: docs/features/user-management.md, docs/features/audit-logging.md
+// Feature doc: docs/features/user-management.md, docs/features/separation-of-duties.md, docs/features/user-groups.md, docs/features/audit-logging.md
 package users
 
 import (
@@ -46,13 +46,19 @@ type InviteUserRequestBody struct {
 	Email   string   `json:"email" minLength:"1" pattern:"@"`
 	Name    string   `json:"name" minLength:"1"`
 	RoleIDs []string `json:"role_ids" minItems:"1"`
+	// RoleExpiresAt makes individual assignments time-bound, as in
+	// UpdateUserRolesRequestBody.
+	RoleExpiresAt map[string]time.Time `json:"role_expires_at,omitempty"`
+	// GroupID adds the invited user to a user group. Group admins must name
+	// one of the groups they administer.
+	GroupID string `json:"group_id,omitempty"`
 }
 
 func (r *InviteUserRequestBody) Resolve(ctx huma.Context) []error {
 	if r.RoleIDs == nil {
 		return []error{fmt.Errorf("role_ids is required")}
 	}
-	return nil
+	return validateRoleExpiresAt(r.RoleIDs, r.RoleExpiresAt, time.Now())
 }
 
 func (h *UsersHandler) InviteUser(ctx context.Context, input *InviteUserInput) (*struct{}, error) {
@@ -66,19 +72,62 @@ func (h *UsersHandler) InviteUser(ctx context.Context, input *InviteUserInput) (
 func (h *UsersHandler) inviteUser(ctx context.Context, req InviteUserRequestBody) error {
 	tenantID := libctx.GetTenantID(ctx)
 
+	var groupID pgtype.UUID
+	if req.GroupID != "" {
+		if err := groupID.Scan(req.GroupID); err != nil {
+			return errlib.NewError(fmt.Errorf("InviteUser: invalid group ID format %s: %w", req.GroupID, err), http.StatusBadRequest)
+		}
+		_, err := h.q.GetUserGroupByID(ctx, &queries.GetUserGroupByIDParams{
+			ID:       groupID,
+			TenantID: tenantID,
+		})
+		if err != nil {
+			if errlib.Is(err, pgx.ErrNoRows) {
+				return errlib.NewErrorWithDetail(fmt.Errorf("InviteUser: group %s not found in tenant %s", groupID.String(), tenantID.String()), http.StatusBadRequest, "グループが見つかりません。")
+			}
+			return errlib.NewError(fmt.Errorf("InviteUser: failed to get group: %w", err), http.StatusInternalServerError)
+		}
+	}
+	if err := checkGroupInScope(ctx, "InviteUser", groupID); err != nil {
+		return err
+	}
+
+	roleIDs, err := parseUUIDs(req.RoleIDs)
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("InviteUser: invalid role ID format: %w", err), http.StatusBadRequest)
+	}
+	if err := checkRolesInScope(ctx, h.q, "InviteUser", tenantID, roleIDs); err != nil {
+		return err
+	}
+
 	tenant, err := h.q.GetTenantByID(ctx, tenantID)
 	if err != nil {
 		return errlib.NewError(fmt.Errorf("InviteUser: failed to get tenant: %w", err), http.StatusInternalServerError)
 	}
 
 	if tenant.AuthMethod == "sso" {
-		return h.inviteSSOUser(ctx, req, tenant)
+		return h.inviteSSOUser(ctx, req, tenant, groupID)
 	}
 
-	return h.invitePasswordUser(ctx, req)
+	return h.invitePasswordUser(ctx, req, tenant, groupID)
+}
+
+// addInvitedUserToGroup does nothing when no group was requested.
+func addInvitedUserToGroup(ctx context.Context, qtx *queries.Queries, groupID, userID, tenantID pgtype.UUID) error {
+	if !groupID.Valid {
+		return nil
+	}
+	if err := qtx.AddUserGroupMembersBulk(ctx, &queries.AddUserGroupMembersBulkParams{
+		GroupID:  groupID,
+		UserIds:  []pgtype.UUID{userID},
+		TenantID: tenantID,
+	}); err != nil {
+		return fmt.Errorf("failed to add user %s to group %s: %w", userID.String(), groupID.String(), err)
+	}
+	return nil
 }
 
-func (h *UsersHandler) invitePasswordUser(ctx context.Context, req InviteUserRequestBody) error {
+func (h *UsersHandler) invitePasswordUser(ctx context.Context, req InviteUserRequestBody, tenant *queries.Tenant, groupID pgtype.UUID) error {
 	tenantID := libctx.GetTenantID(ctx)
 	inviterUserID := libctx.GetUserID(ctx)
 
@@ -87,9 +136,9 @@ func (h *UsersHandler) invitePasswordUser(ctx context.Context, req InviteUserReq
 		return errlib.NewError(fmt.Errorf("InviteUser: failed to get inviter's user details for UserID %s: %w", inviterUserID.String(), err), http.StatusInternalServerError)
 	}
 
-	_, err = h.q.GetUserByEmail(ctx, req.Email)
+	existingUser, err := h.q.GetUserByEmail(ctx, req.Email)
 	if err == nil {
-		return errlib.NewErrorWithDetail(fmt.Errorf("InviteUser: attempt to invite existing email: %s", req.Email), http.StatusConflict, "このメールアドレスは既に使用されています。")
+		return h.addExistingUserToTenant(ctx, req, tenant, existingUser, inviterDBUser, groupID)
 	}
 	if !errlib.Is(err, pgx.ErrNoRows) {
 		return errlib.NewError(fmt.Errorf("InviteUser: GetUserByEmail failed: %w", err), http.StatusInternalServerError)
@@ -144,17 +193,32 @@ func (h *UsersHandler) invitePasswordUser(ctx context.Context, req InviteUserReq
 		return errlib.NewErrorWithDetail(fmt.Errorf("InviteUser: some role IDs do not belong to tenant"), http.StatusBadRequest, "一部のロールが無効です。")
 	}
 
+	expiries := roleExpiries(req.RoleExpiresAt)
 	for _, roleID := range roleIDs {
 		err = qtx.AssignRoleToUser(ctx, &queries.AssignRoleToUserParams{
-			UserID:   createdUserID,
-			RoleID:   roleID,
-			TenantID: tenantID,
+			UserID:    createdUserID,
+			RoleID:    roleID,
+			TenantID:  tenantID,
+			ExpiresAt: expiries[roleID.String()],
+			GrantedBy: inviterUserID,
 		})
 		if err != nil {
 			return errlib.NewError(fmt.Errorf("InviteUser: failed to assign role %s to user: %w", roleID.String(), err), http.StatusInternalServerError)
 		}
 	}
 
+	if err := addInvitedUserToGroup(ctx, qtx, groupID, createdUserID, tenantID); err != nil {
+		return errlib.NewError(fmt.Errorf("InviteUser: %w", err), http.StatusInternalServerError)
+	}
+
+	conflicts, err := libAuthz.UserRoleConflicts(ctx, qtx, tenantID, createdUserID)
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("InviteUser: %w", err), http.StatusInternalServerError)
+	}
+	if len(conflicts) > 0 {
+		return errlib.NewErrorWithDetail(fmt.Errorf("InviteUser: requested roles break separation of duties set %s", conflicts[0].SetID), http.StatusConflict, libAuthz.ConflictDetail(conflicts[0]))
+	}
+
 	tokenBytes := make([]byte, 32)
 	if _, err := rand.Read(tokenBytes); err != nil {
 		return errlib.NewError(fmt.Errorf("InviteUser: failed to generate random bytes for invitation token: %w", err), http.StatusInternalServerError)
@@ -251,7 +315,7 @@ func (h *UsersHandler) invitePasswordUser(ctx context.Context, req InviteUserReq
 	return nil
 }
 
-func (h *UsersHandler) inviteSSOUser(ctx context.Context, req InviteUserRequestBody, tenant *queries.Tenant) error {
+func (h *UsersHandler) inviteSSOUser(ctx context.Context, req InviteUserRequestBody, tenant *queries.Tenant, groupID pgtype.UUID) error {
 	tenantID := libctx.GetTenantID(ctx)
 	inviterUserID := libctx.GetUserID(ctx)
 
@@ -286,9 +350,9 @@ func (h *UsersHandler) inviteSSOUser(ctx context.Context, req InviteUserRequestB
 		return errlib.NewError(fmt.Errorf("InviteUser: failed to get inviter's user details for UserID %s: %w", inviterUserID.String(), err), http.StatusInternalServerError)
 	}
 
-	_, err = h.q.GetUserByEmail(ctx, req.Email)
+	existingUser, err := h.q.GetUserByEmail(ctx, req.Email)
 	if err == nil {
-		return errlib.NewErrorWithDetail(fmt.Errorf("InviteUser: attempt to invite existing email: %s", req.Email), http.StatusConflict, "このメールアドレスは既に使用されています。")
+		return h.addExistingUserToTenant(ctx, req, tenant, existingUser, inviterDBUser, groupID)
 	}
 	if !errlib.Is(err, pgx.ErrNoRows) {
 		return errlib.NewError(fmt.Errorf("InviteUser: GetUserByEmail failed: %w", err), http.StatusInternalServerError)
@@ -338,17 +402,32 @@ func (h *UsersHandler) inviteSSOUser(ctx context.Context, req InviteUserRequestB
 		return errlib.NewError(fmt.Errorf("InviteUser: some role IDs do not belong to tenant"), http.StatusBadRequest)
 	}
 
+	expiries := roleExpiries(req.RoleExpiresAt)
 	for _, roleID := range roleIDs {
 		err = qtx.AssignRoleToUser(ctx, &queries.AssignRoleToUserParams{
-			UserID:   createdUserID,
-			RoleID:   roleID,
-			TenantID: tenantID,
+			UserID:    createdUserID,
+			RoleID:    roleID,
+			TenantID:  tenantID,
+			ExpiresAt: expiries[roleID.String()],
+			GrantedBy: inviterUserID,
 		})
 		if err != nil {
 			return errlib.NewError(fmt.Errorf("InviteUser: failed to assign role %s to user: %w", roleID.String(), err), http.StatusInternalServerError)
 		}
 	}
 
+	if err := addInvitedUserToGroup(ctx, qtx, groupID, createdUserID, tenantID); err != nil {
+		return errlib.NewError(fmt.Errorf("InviteUser: %w", err), http.StatusInternalServerError)
+	}
+
+	conflicts, err := libAuthz.UserRoleConflicts(ctx, qtx, tenantID, createdUserID)
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("InviteUser: %w", err), http.StatusInternalServerError)
+	}
+	if len(conflicts) > 0 {
+		return errlib.NewErrorWithDetail(fmt.Errorf("InviteUser: requested roles break separation of duties set %s", conflicts[0].SetID), http.StatusConflict, libAuthz.ConflictDetail(conflicts[0]))
+	}
+
 	subject := fmt.Sprintf("%sさんから%s様へのdislyzeへのご招待", inviterDBUser.Name, req.Name)
 	invitationLink := fmt.Sprintf("%s/auth/sso/login?email=%s",
 		h.env.FrontendURL,
@@ -421,3 +500,161 @@ func (h *UsersHandler) inviteSSOUser(ctx context.Context, req InviteUserRequestB
 
 	return nil
 }
+
+// addExistingUserToTenant handles an invite for an email that already has an
+// account: instead of creating a second identity, the account becomes a member
+// of the inviting tenant with the requested roles and is notified by email.
+func (h *UsersHandler) addExistingUserToTenant(ctx context.Context, req InviteUserRequestBody, tenant *queries.Tenant, existingUser *queries.User, inviterDBUser *queries.User, groupID pgtype.UUID) error {
+	if existingUser.IsInternalUser {
+		return errlib.NewErrorWithDetail(fmt.Errorf("InviteUser: attempt to invite internal user: %s", req.Email), http.StatusConflict, "このメールアドレスは既に使用されています。")
+	}
+
+	isMember, err := h.q.IsTenantMember(ctx, &queries.IsTenantMemberParams{
+		TenantID: tenant.ID,
+		UserID:   existingUser.ID,
+	})
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("InviteUser: failed to check membership of user %s: %w", existingUser.ID.String(), err), http.StatusInternalServerError)
+	}
+	if isMember {
+		return errlib.NewErrorWithDetail(fmt.Errorf("InviteUser: user %s is already a member of tenant %s", existingUser.ID.String(), tenant.ID.String()), http.StatusConflict, "このメールアドレスは既に使用されています。")
+	}
+
+	roleIDs := make([]pgtype.UUID, len(req.RoleIDs))
+	for i, roleIDStr := range req.RoleIDs {
+		var roleID pgtype.UUID
+		if err := roleID.Scan(roleIDStr); err != nil {
+			return errlib.NewError(fmt.Errorf("InviteUser: invalid role ID format %s: %w", roleIDStr, err), http.StatusBadRequest)
+		}
+		roleIDs[i] = roleID
+	}
+
+	tx, err := h.dbConn.Begin(ctx)
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("InviteUser: failed to begin transaction: %w", err), http.StatusInternalServerError)
+	}
+	defer func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errlib.Is(rbErr, pgx.ErrTxClosed) && !errlib.Is(rbErr, sql.ErrTxDone) {
+			errlib.LogError(fmt.Errorf("InviteUser: failed to rollback transaction: %w", rbErr))
+		}
+	}()
+	qtx := h.q.WithTx(tx)
+
+	validRoleIDs, err := qtx.ValidateRolesBelongToTenant(ctx, &queries.ValidateRolesBelongToTenantParams{
+		Column1:  roleIDs,
+		TenantID: tenant.ID,
+	})
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("InviteUser: failed to validate roles: %w", err), http.StatusInternalServerError)
+	}
+	if len(validRoleIDs) != len(roleIDs) {
+		return errlib.NewErrorWithDetail(fmt.Errorf("InviteUser: some role IDs do not belong to tenant"), http.StatusBadRequest, "一部のロールが無効です。")
+	}
+
+	if err := qtx.AddTenantMembership(ctx, &queries.AddTenantMembershipParams{
+		TenantID: tenant.ID,
+		UserID:   existingUser.ID,
+	}); err != nil {
+		return errlib.NewError(fmt.Errorf("InviteUser: failed to add membership for user %s: %w", existingUser.ID.String(), err), http.StatusInternalServerError)
+	}
+
+	expiries := roleExpiries(req.RoleExpiresAt)
+	for _, roleID := range roleIDs {
+		err = qtx.AssignRoleToUser(ctx, &queries.AssignRoleToUserParams{
+			UserID:    existingUser.ID,
+			RoleID:    roleID,
+			TenantID:  tenant.ID,
+			ExpiresAt: expiries[roleID.String()],
+			GrantedBy: inviterDBUser.ID,
+		})
+		if err != nil {
+			return errlib.NewError(fmt.Errorf("InviteUser: failed to assign role %s to user: %w", roleID.String(), err), http.StatusInternalServerError)
+		}
+	}
+
+	if err := addInvitedUserToGroup(ctx, qtx, groupID, existingUser.ID, tenant.ID); err != nil {
+		return errlib.NewError(fmt.Errorf("InviteUser: %w", err), http.StatusInternalServerError)
+	}
+
+	conflicts, err := libAuthz.UserRoleConflicts(ctx, qtx, tenant.ID, existingUser.ID)
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("InviteUser: %w", err), http.StatusInternalServerError)
+	}
+	if len(conflicts) > 0 {
+		return errlib.NewErrorWithDetail(fmt.Errorf("InviteUser: requested roles break separation of duties set %s", conflicts[0].SetID), http.StatusConflict, libAuthz.ConflictDetail(conflicts[0]))
+	}
+
+	loginLink := fmt.Sprintf("%s/auth/login", h.env.FrontendURL)
+	if tenant.AuthMethod == "sso" {
+		loginLink = fmt.Sprintf("%s/auth/sso/login?email=%s", h.env.FrontendURL, url.QueryEscape(existingUser.Email))
+	}
+
+	subject := fmt.Sprintf("%sさんから%sへのご招待", inviterDBUser.Name, tenant.Name)
+	plainTextContent := fmt.Sprintf("%s様、\n\n%sさんがあなたを%sに追加しました。\n\n現在のアカウントでログインし、テナントを切り替えてご利用ください。\n%s\n\nこのメールにお心当たりがない場合は、無視してください。", existingUser.Name, inviterDBUser.Name, tenant.Name, loginLink)
+	htmlContent := fmt.Sprintf(`<p>%s様</p>
+	<p>%sさんがあなたを%sに追加しました。</p>
+	<p>現在のアカウントでログインし、テナントを切り替えてご利用ください。</p>
+	<p><a href="%s">ログインする</a></p>
+	<p>このメールにお心当たりがない場合は、無視してください。</p>`, existingUser.Name, inviterDBUser.Name, tenant.Name, loginLink)
+
+	sgMailBody := sendgridlib.SendGridMailRequestBody{
+		Personalizations: []sendgridlib.SendGridPersonalization{
+			{
+				To:      []sendgridlib.SendGridEmailAddress{{Email: existingUser.Email, Name: existingUser.Name}},
+				Subject: subject,
+			},
+		},
+		From:    sendgridlib.SendGridEmailAddress{Email: sendgridlib.SendGridFromEmail, Name: sendgridlib.SendGridFromName},
+		Content: []sendgridlib.SendGridContent{{Type: "text/plain", Value: plainTextContent}, {Type: "text/html", Value: htmlContent}},
+	}
+
+	bodyBytes, err := json.Marshal(sgMailBody)
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("InviteUser: failed to marshal SendGrid request body: %w", err), http.StatusInternalServerError)
+	}
+
+	sendgridRequest := sendgrid.GetRequest(h.env.SendgridAPIKey, "/v3/mail/send", h.env.SendgridAPIUrl)
+	sendgridRequest.Method = "POST"
+	sendgridRequest.Body = bodyBytes
+	response, err := sendgrid.API(sendgridRequest)
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("InviteUser: SendGrid API call failed: %w", err), http.StatusInternalServerError)
+	}
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return errlib.NewError(fmt.Errorf("InviteUser: SendGrid API returned error status code: %d, Body: %s", response.StatusCode, response.Body), http.StatusInternalServerError)
+	}
+
+	if libAuthz.TenantHasFeature(ctx, libAuthz.FeatureAuditLog) {
+		r := middleware.GetHTTPRequest(ctx)
+		metadata, _ := json.Marshal(map[string]string{
+			"actor_name":       inviterDBUser.Name,
+			"actor_email":      inviterDBUser.Email,
+			"invited_email":    existingUser.Email,
+			"invited_name":     existingUser.Name,
+			"existing_account": "true",
+		})
+
+		ipAddr, _ := netip.ParseAddr(iputils.ExtractClientIP(r))
+		err = qtx.InsertAuditLog(ctx, &queries.InsertAuditLogParams{
+			TenantID:     tenant.ID,
+			ActorID:      inviterDBUser.ID,
+			ResourceType: string(auditlog.ResourceUser),
+			Action:       string(auditlog.ActionInvited),
+			Outcome:      string(auditlog.OutcomeSuccess),
+			ResourceID:   pgtype.Text{String: existingUser.ID.String(), Valid: true},
+			Metadata:     metadata,
+			IpAddress:    &ipAddr,
+			UserAgent:    pgtype.Text{String: r.UserAgent(), Valid: true},
+		})
+		if err != nil {
+			return errlib.NewError(fmt.Errorf("InviteUser: failed to insert audit log: %w", err), http.StatusInternalServerError)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return errlib.NewError(fmt.Errorf("InviteUser: failed to commit transaction: %w", err), http.StatusInternalServerError)
+	}
+
+	return nil
+}
@@ -1,4 +1,4 @@
-// Feature doc: docs/features/user-management.md, docs/features/audit-logging.md
+// Feature doc: docs/features/user-management.md, docs/features/user-groups.md, docs/features/audit-logging.md
 package users
 
 import (
@@ -61,6 +61,10 @@ func (h *UsersHandler) ResendInvite(ctx context.Context, input *ResendInviteInpu
 func (h *UsersHandler) resendInvite(ctx context.Context, targetUserID pgtype.UUID) error {
 	invokerTenantID := libctx.GetTenantID(ctx)
 
+	if err := checkUserInScope(ctx, h.q, "ResendInvite", invokerTenantID, targetUserID); err != nil {
+		return err
+	}
+
 	tenant, err := h.q.GetTenantByID(ctx, invokerTenantID)
 	if err != nil {
 		return errlib.NewError(fmt.Errorf("ResendInvite: failed to get tenant: %w", err), http.StatusInternalServerError)
@@ -93,8 +97,15 @@ func (h *UsersHandler) resendPasswordInvite(ctx context.Context, targetUserID pg
 		return errlib.NewError(fmt.Errorf("ResendInvite: failed to get target user %s: %w", targetUserID.String(), err), http.StatusInternalServerError)
 	}
 
-	if invokerTenantID != targetDBUser.TenantID {
-		return errlib.NewError(fmt.Errorf("ResendInvite: invoker %s (tenant %s) attempting to resend invite for user %s (tenant %s) in different tenant", invokerUserID.String(), invokerTenantID.String(), targetUserID.String(), targetDBUser.TenantID.String()), http.StatusForbidden)
+	isMember, err := h.q.IsTenantMember(ctx, &queries.IsTenantMemberParams{
+		TenantID: invokerTenantID,
+		UserID:   targetUserID,
+	})
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("ResendInvite: failed to check membership of user %s: %w", targetUserID.String(), err), http.StatusInternalServerError)
+	}
+	if !isMember {
+		return errlib.NewError(fmt.Errorf("ResendInvite: invoker %s (tenant %s) attempting to resend invite for user %s who is not a member of the tenant", invokerUserID.String(), invokerTenantID.String(), targetUserID.String()), http.StatusForbidden)
 	}
 
 	if targetDBUser.Status != "pending_verification" {
@@ -114,7 +125,7 @@ func (h *UsersHandler) resendPasswordInvite(ctx context.Context, targetUserID pg
 
 	if err := qtx.DeleteInvitationTokensByUserIDAndTenantID(ctx, &queries.DeleteInvitationTokensByUserIDAndTenantIDParams{
 		UserID:   targetUserID,
-		TenantID: targetDBUser.TenantID,
+		TenantID: invokerTenantID,
 	}); err != nil {
 		return errlib.NewError(fmt.Errorf("ResendInvite: failed to delete existing invitation tokens for user %s: %w", targetUserID.String(), err), http.StatusInternalServerError)
 	}
@@ -130,7 +141,7 @@ func (h *UsersHandler) resendPasswordInvite(ctx context.Context, targetUserID pg
 
 	_, err = qtx.CreateInvitationToken(ctx, &queries.CreateInvitationTokenParams{
 		TokenHash: hashedTokenStr,
-		TenantID:  targetDBUser.TenantID,
+		TenantID:  invokerTenantID,
 		UserID:    targetUserID,
 		ExpiresAt: pgtype.Timestamptz{Time: expiresAt, Valid: true},
 	})
@@ -237,8 +248,15 @@ func (h *UsersHandler) resendSSOInvite(ctx context.Context, targetUserID pgtype.
 		return errlib.NewError(fmt.Errorf("ResendInvite: failed to get target user %s: %w", targetUserID.String(), err), http.StatusInternalServerError)
 	}
 
-	if invokerTenantID != targetDBUser.TenantID {
-		return errlib.NewError(fmt.Errorf("ResendInvite: invoker %s (tenant %s) attempting to resend invite for user %s (tenant %s) in different tenant", invokerUserID.String(), invokerTenantID.String(), targetUserID.String(), targetDBUser.TenantID.String()), http.StatusForbidden)
+	isMember, err := h.q.IsTenantMember(ctx, &queries.IsTenantMemberParams{
+		TenantID: invokerTenantID,
+		UserID:   targetUserID,
+	})
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("ResendInvite: failed to check membership of user %s: %w", targetUserID.String(), err), http.StatusInternalServerError)
+	}
+	if !isMember {
+		return errlib.NewError(fmt.Errorf("ResendInvite: invoker %s (tenant %s) attempting to resend invite for user %s who is not a member of the tenant", invokerUserID.String(), invokerTenantID.String(), targetUserID.String()), http.StatusForbidden)
 	}
 
 	if targetDBUser.Status != "pending_verification" {
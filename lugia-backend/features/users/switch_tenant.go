@@ -0,0 +1,206 @@
+// Feature doc: docs/features/authentication.md, docs/features/audit-logging.md
+package users
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"dislyze/jirachi/auditlog"
+	"dislyze/jirachi/authz"
+	libctx "dislyze/jirachi/ctx"
+	"dislyze/jirachi/errlib"
+	"dislyze/jirachi/jwt"
+	"dislyze/jirachi/logger"
+	"lugia/lib/iputils"
+	"lugia/lib/middleware"
+	"lugia/queries"
+)
+
+var SwitchTenantOp = huma.Operation{
+	OperationID: "switch-tenant",
+	Method:      http.MethodPost,
+	Path:        "/me/tenants/switch",
+}
+
+type SwitchTenantInput struct {
+	Body SwitchTenantRequestBody
+}
+
+type SwitchTenantRequestBody struct {
+	TenantID string `json:"tenant_id" minLength:"1"`
+}
+
+func (h *UsersHandler) SwitchTenant(ctx context.Context, input *SwitchTenantInput) (*struct{}, error) {
+	r := middleware.GetHTTPRequest(ctx)
+	w := middleware.GetResponseWriter(ctx)
+
+	var targetTenantID pgtype.UUID
+	if err := targetTenantID.Scan(input.Body.TenantID); err != nil {
+		return nil, errlib.NewError(fmt.Errorf("SwitchTenant: invalid tenant ID format: %w", err), http.StatusBadRequest)
+	}
+
+	tokenPair, err := h.switchTenant(ctx, r, targetTenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     "dislyze_access_token",
+		Value:    tokenPair.AccessToken,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   h.env.IsCookieSecure(),
+		SameSite: http.SameSiteStrictMode,
+		MaxAge:   int(tokenPair.ExpiresIn),
+	})
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     "dislyze_refresh_token",
+		Value:    tokenPair.RefreshToken,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   h.env.IsCookieSecure(),
+		SameSite: http.SameSiteStrictMode,
+		MaxAge:   7 * 24 * 60 * 60, // 7 days
+	})
+
+	logger.LogAuthEvent(logger.AuthEvent{
+		EventType: "tenant_switch",
+		Service:   "lugia",
+		UserID:    libctx.GetUserID(ctx).String(),
+		IPAddress: r.RemoteAddr,
+		UserAgent: r.UserAgent(),
+		Timestamp: time.Now(),
+		Success:   true,
+	})
+
+	return nil, nil
+}
+
+func (h *UsersHandler) switchTenant(ctx context.Context, r *http.Request, targetTenantID pgtype.UUID) (*jwt.TokenPair, error) {
+	userID := libctx.GetUserID(ctx)
+	currentTenantID := libctx.GetTenantID(ctx)
+
+	if targetTenantID == currentTenantID {
+		return nil, errlib.NewError(fmt.Errorf("SwitchTenant: user %s is already in tenant %s", userID.String(), targetTenantID.String()), http.StatusBadRequest)
+	}
+
+	tx, err := h.dbConn.Begin(ctx)
+	if err != nil {
+		return nil, errlib.NewError(fmt.Errorf("SwitchTenant: failed to begin transaction: %w", err), http.StatusInternalServerError)
+	}
+	defer func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errlib.Is(rbErr, pgx.ErrTxClosed) && !errlib.Is(rbErr, sql.ErrTxDone) {
+			errlib.LogError(fmt.Errorf("SwitchTenant: failed to rollback transaction: %w", rbErr))
+		}
+	}()
+	qtx := h.q.WithTx(tx)
+
+	isMember, err := qtx.IsTenantMember(ctx, &queries.IsTenantMemberParams{
+		TenantID: targetTenantID,
+		UserID:   userID,
+	})
+	if err != nil {
+		return nil, errlib.NewError(fmt.Errorf("SwitchTenant: failed to check membership of user %s: %w", userID.String(), err), http.StatusInternalServerError)
+	}
+	if !isMember {
+		return nil, errlib.NewError(fmt.Errorf("SwitchTenant: user %s is not a member of tenant %s", userID.String(), targetTenantID.String()), http.StatusForbidden)
+	}
+
+	tenant, err := qtx.GetTenantByID(ctx, targetTenantID)
+	if err != nil {
+		return nil, errlib.NewError(fmt.Errorf("SwitchTenant: failed to get tenant %s: %w", targetTenantID.String(), err), http.StatusInternalServerError)
+	}
+
+	// An SSO-only tenant trusts its IdP for authentication; a session from
+	// another tenant must not bypass it.
+	if tenant.AuthMethod == "sso" {
+		return nil, errlib.NewErrorWithDetail(fmt.Errorf("SwitchTenant: tenant %s is SSO-only", targetTenantID.String()), http.StatusForbidden, "このテナントはSSO専用です。SSOでログインしてください。")
+	}
+
+	// Entering a tenant is a sign-in to it, so its whitelist applies before
+	// any token is issued. The check runs outside the transaction so a block
+	// still reaches the target tenant's audit log.
+	if err := middleware.CheckSignInIPWhitelist(r, h.q, targetTenantID, userID); err != nil {
+		if errlib.Is(err, middleware.ErrIPNotWhitelisted) {
+			return nil, errlib.NewErrorWithDetail(fmt.Errorf("SwitchTenant: user %s blocked by the IP whitelist of tenant %s: %w", userID.String(), targetTenantID.String(), err), http.StatusForbidden, "このIPアドレスからこのテナントへのアクセスは許可されていません。")
+		}
+		return nil, errlib.NewError(fmt.Errorf("SwitchTenant: failed to check IP whitelist of tenant %s: %w", targetTenantID.String(), err), http.StatusInternalServerError)
+	}
+
+	user, err := qtx.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, errlib.NewError(fmt.Errorf("SwitchTenant: failed to get user %s: %w", userID.String(), err), http.StatusInternalServerError)
+	}
+
+	// The session being replaced can no longer be rotated.
+	if refreshCookie, cookieErr := r.Cookie("dislyze_refresh_token"); cookieErr == nil {
+		if claims, jwtErr := jwt.ValidateToken(refreshCookie.Value, []byte(h.env.AuthJWTSecret)); jwtErr == nil && claims.UserID == userID {
+			if err := qtx.UpdateRefreshTokenUsed(ctx, claims.JTI); err != nil {
+				return nil, errlib.NewError(fmt.Errorf("SwitchTenant: failed to mark current refresh token as used: %w", err), http.StatusInternalServerError)
+			}
+		}
+	}
+
+	tokenPair, err := jwt.GenerateTokenPair(userID, tenant.ID, []byte(h.env.AuthJWTSecret))
+	if err != nil {
+		return nil, errlib.NewError(fmt.Errorf("SwitchTenant: failed to generate token pair: %w", err), http.StatusInternalServerError)
+	}
+
+	_, err = qtx.CreateRefreshToken(ctx, &queries.CreateRefreshTokenParams{
+		UserID:     userID,
+		Jti:        tokenPair.JTI,
+		DeviceInfo: pgtype.Text{String: r.UserAgent(), Valid: true},
+		IpAddress:  pgtype.Text{String: iputils.ExtractClientIP(r), Valid: true},
+		ExpiresAt:  pgtype.Timestamptz{Time: time.Now().Add(7 * 24 * time.Hour), Valid: true},
+		TenantID:   tenant.ID,
+	})
+	if err != nil {
+		return nil, errlib.NewError(fmt.Errorf("SwitchTenant: failed to store refresh token: %w", err), http.StatusInternalServerError)
+	}
+
+	// The audit entry belongs to the tenant being entered, so its own feature
+	// flags decide whether it is written.
+	var ef authz.EnterpriseFeatures
+	if err := json.Unmarshal(tenant.EnterpriseFeatures, &ef); err != nil {
+		return nil, errlib.NewError(fmt.Errorf("SwitchTenant: failed to unmarshal features for tenant %s: %w", tenant.ID.String(), err), http.StatusInternalServerError)
+	}
+	if ef.AuditLog.Enabled {
+		metadata, _ := json.Marshal(map[string]string{
+			"actor_name":     user.Name,
+			"actor_email":    user.Email,
+			"from_tenant_id": currentTenantID.String(),
+		})
+
+		ipAddr, _ := netip.ParseAddr(iputils.ExtractClientIP(r))
+		err = qtx.InsertAuditLog(ctx, &queries.InsertAuditLogParams{
+			TenantID:     tenant.ID,
+			ActorID:      userID,
+			ResourceType: string(auditlog.ResourceAuth),
+			Action:       string(auditlog.ActionTenantSwitched),
+			Outcome:      string(auditlog.OutcomeSuccess),
+			ResourceID:   pgtype.Text{},
+			Metadata:     metadata,
+			IpAddress:    &ipAddr,
+			UserAgent:    pgtype.Text{String: r.UserAgent(), Valid: true},
+		})
+		if err != nil {
+			return nil, errlib.NewError(fmt.Errorf("SwitchTenant: failed to insert audit log: %w", err), http.StatusInternalServerError)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, errlib.NewError(fmt.Errorf("SwitchTenant: failed to commit transaction: %w", err), http.StatusInternalServerError)
+	}
+
+	return tokenPair, nil
+}
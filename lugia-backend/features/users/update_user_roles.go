@@ -1,4 +1,4 @@
-// Feature doc: docs/features/user-management.md, docs/features/audit-logging.md
+// Feature doc: docs/features/user-management.md, docs/features/separation-of-duties.md, docs/features/user-groups.md, docs/features/audit-logging.md
 package users
 
 import (
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/netip"
+	"time"
 
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/jackc/pgx/v5"
@@ -35,13 +36,61 @@ type UpdateUserRolesInput struct {
 
 type UpdateUserRolesRequestBody struct {
 	RoleIDs []string `json:"role_ids" minItems:"1"`
+	// RoleExpiresAt makes individual assignments time-bound. Keys are role IDs
+	// from role_ids; roles without an entry are permanent.
+	RoleExpiresAt map[string]time.Time `json:"role_expires_at,omitempty"`
 }
 
 func (r *UpdateUserRolesRequestBody) Resolve(ctx huma.Context) []error {
 	if r.RoleIDs == nil {
 		return []error{fmt.Errorf("role_ids is required")}
 	}
-	return nil
+	return validateRoleExpiresAt(r.RoleIDs, r.RoleExpiresAt, time.Now())
+}
+
+// validateRoleExpiresAt checks that every expiry belongs to a requested role
+// and lies in the future.
+func validateRoleExpiresAt(roleIDs []string, expiresAt map[string]time.Time, now time.Time) []error {
+	requested := make(map[string]bool, len(roleIDs))
+	for _, id := range roleIDs {
+		var roleID pgtype.UUID
+		if err := roleID.Scan(id); err == nil {
+			requested[roleID.String()] = true
+		}
+	}
+
+	var errs []error
+	for id, t := range expiresAt {
+		var roleID pgtype.UUID
+		if err := roleID.Scan(id); err != nil || !requested[roleID.String()] {
+			errs = append(errs, fmt.Errorf("role_expires_at: %q is not in role_ids", id))
+			continue
+		}
+		if !t.After(now) {
+			errs = append(errs, fmt.Errorf("role_expires_at: expiry for %q must be in the future", id))
+		}
+	}
+	return errs
+}
+
+// roleExpiries keys expires_at by canonical role ID so it can be looked up with
+// pgtype.UUID.String(). Keys must already have passed validateRoleExpiresAt.
+func roleExpiries(expiresAt map[string]time.Time) map[string]pgtype.Timestamptz {
+	result := make(map[string]pgtype.Timestamptz, len(expiresAt))
+	for id, t := range expiresAt {
+		var roleID pgtype.UUID
+		if err := roleID.Scan(id); err == nil {
+			result[roleID.String()] = pgtype.Timestamptz{Time: t, Valid: true}
+		}
+	}
+	return result
+}
+
+func sameExpiry(a, b pgtype.Timestamptz) bool {
+	if a.Valid != b.Valid {
+		return false
+	}
+	return !a.Valid || a.Time.Equal(b.Time)
 }
 
 func parseUUIDs(ids []string) ([]pgtype.UUID, error) {
@@ -81,13 +130,13 @@ func (h *UsersHandler) UpdateUserRoles(ctx context.Context, input *UpdateUserRol
 		return nil, errlib.NewError(fmt.Errorf("invalid role ID format: %w", err), http.StatusBadRequest)
 	}
 
-	if err := h.updateUserRoles(ctx, targetUserID, roleIDs); err != nil {
+	if err := h.updateUserRoles(ctx, targetUserID, roleIDs, roleExpiries(input.Body.RoleExpiresAt)); err != nil {
 		return nil, err
 	}
 	return nil, nil
 }
 
-func (h *UsersHandler) updateUserRoles(ctx context.Context, targetUserID pgtype.UUID, roleIDs []pgtype.UUID) error {
+func (h *UsersHandler) updateUserRoles(ctx context.Context, targetUserID pgtype.UUID, roleIDs []pgtype.UUID, expiries map[string]pgtype.Timestamptz) error {
 	requestingUserID := libctx.GetUserID(ctx)
 	requestingTenantID := libctx.GetTenantID(ctx)
 
@@ -103,8 +152,19 @@ func (h *UsersHandler) updateUserRoles(ctx context.Context, targetUserID pgtype.
 		return errlib.NewError(fmt.Errorf("UpdateUserRoles: failed to get target user %s: %w", targetUserID.String(), err), http.StatusInternalServerError)
 	}
 
-	if requestingTenantID != targetUser.TenantID {
-		return errlib.NewError(fmt.Errorf("UpdateUserRoles: requesting user %s (tenant %s) attempting to update user %s (tenant %s) in different tenant", requestingUserID.String(), requestingTenantID.String(), targetUserID.String(), targetUser.TenantID.String()), http.StatusForbidden)
+	isMember, err := h.q.IsTenantMember(ctx, &queries.IsTenantMemberParams{
+		TenantID: requestingTenantID,
+		UserID:   targetUserID,
+	})
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("UpdateUserRoles: failed to check membership of user %s: %w", targetUserID.String(), err), http.StatusInternalServerError)
+	}
+	if !isMember {
+		return errlib.NewError(fmt.Errorf("UpdateUserRoles: requesting user %s (tenant %s) attempting to update user %s who is not a member of the tenant", requestingUserID.String(), requestingTenantID.String(), targetUserID.String()), http.StatusForbidden)
+	}
+
+	if err := checkUserInScope(ctx, h.q, "UpdateUserRoles", requestingTenantID, targetUserID); err != nil {
+		return err
 	}
 
 	validRoleIDs, err := h.q.ValidateRolesBelongToTenant(ctx, &queries.ValidateRolesBelongToTenantParams{
@@ -118,18 +178,37 @@ func (h *UsersHandler) updateUserRoles(ctx context.Context, targetUserID pgtype.
 		return errlib.NewError(fmt.Errorf("UpdateUserRoles: some roles don't belong to tenant"), http.StatusBadRequest)
 	}
 
-	currentRoleIDs, err := h.q.GetUserRoleIDs(ctx, &queries.GetUserRoleIDsParams{
+	if err := checkRolesInScope(ctx, h.q, "UpdateUserRoles", requestingTenantID, roleIDs); err != nil {
+		return err
+	}
+
+	currentAssignments, err := h.q.GetUserRoleAssignments(ctx, &queries.GetUserRoleAssignmentsParams{
 		UserID:   targetUserID,
 		TenantID: requestingTenantID,
 	})
 	if err != nil {
 		return errlib.NewError(fmt.Errorf("UpdateUserRoles: failed to get current user roles: %w", err), http.StatusInternalServerError)
 	}
+	currentRoleIDs := make([]pgtype.UUID, len(currentAssignments))
+	currentExpiries := make(map[string]pgtype.Timestamptz, len(currentAssignments))
+	for i, a := range currentAssignments {
+		currentRoleIDs[i] = a.RoleID
+		currentExpiries[a.RoleID.String()] = a.ExpiresAt
+	}
 
 	toAdd := difference(roleIDs, currentRoleIDs)
 	toRemove := difference(currentRoleIDs, roleIDs)
 
-	if len(toRemove) > 0 || len(toAdd) > 0 {
+	// Roles kept across the update whose expiry was set, changed or cleared.
+	var toReexpire []pgtype.UUID
+	for _, roleID := range roleIDs {
+		current, ok := currentExpiries[roleID.String()]
+		if ok && !sameExpiry(current, expiries[roleID.String()]) {
+			toReexpire = append(toReexpire, roleID)
+		}
+	}
+
+	if len(toRemove) > 0 || len(toAdd) > 0 || len(toReexpire) > 0 {
 		tx, err := h.dbConn.Begin(ctx)
 		if err != nil {
 			return errlib.NewError(fmt.Errorf("UpdateUserRoles: failed to begin transaction: %w", err), http.StatusInternalServerError)
@@ -157,9 +236,11 @@ func (h *UsersHandler) updateUserRoles(ctx context.Context, targetUserID pgtype.
 			addRolesInput := make([]*queries.AddRolesToUserParams, len(toAdd))
 			for i, roleID := range toAdd {
 				addRolesInput[i] = &queries.AddRolesToUserParams{
-					UserID:   targetUserID,
-					RoleID:   roleID,
-					TenantID: requestingTenantID,
+					UserID:    targetUserID,
+					RoleID:    roleID,
+					TenantID:  requestingTenantID,
+					ExpiresAt: expiries[roleID.String()],
+					GrantedBy: requestingUserID,
 				}
 			}
 
@@ -169,6 +250,35 @@ func (h *UsersHandler) updateUserRoles(ctx context.Context, targetUserID pgtype.
 			}
 		}
 
+		for _, roleID := range toReexpire {
+			err = qtx.SetUserRoleExpiry(ctx, &queries.SetUserRoleExpiryParams{
+				ExpiresAt: expiries[roleID.String()],
+				GrantedBy: requestingUserID,
+				UserID:    targetUserID,
+				TenantID:  requestingTenantID,
+				RoleID:    roleID,
+			})
+			if err != nil {
+				return errlib.NewError(fmt.Errorf("UpdateUserRoles: failed to update expiry of role %s: %w", roleID.String(), err), http.StatusInternalServerError)
+			}
+		}
+
+		manageable, err := authz.TenantStaysManageable(ctx, qtx, requestingTenantID)
+		if err != nil {
+			return errlib.NewError(fmt.Errorf("UpdateUserRoles: %w", err), http.StatusInternalServerError)
+		}
+		if !manageable {
+			return errlib.NewErrorWithDetail(fmt.Errorf("UpdateUserRoles: updating roles of user %s would leave tenant %s without an active administrator", targetUserID.String(), requestingTenantID.String()), http.StatusConflict, authz.LastAdministratorDetail)
+		}
+
+		conflicts, err := authz.UserRoleConflicts(ctx, qtx, requestingTenantID, targetUserID)
+		if err != nil {
+			return errlib.NewError(fmt.Errorf("UpdateUserRoles: %w", err), http.StatusInternalServerError)
+		}
+		if len(conflicts) > 0 {
+			return errlib.NewErrorWithDetail(fmt.Errorf("UpdateUserRoles: roles of user %s break separation of duties set %s", targetUserID.String(), conflicts[0].SetID), http.StatusConflict, authz.ConflictDetail(conflicts[0]))
+		}
+
 		if authz.TenantHasFeature(ctx, authz.FeatureAuditLog) {
 			actorDBUser, err := qtx.GetUserByID(ctx, requestingUserID)
 			if err != nil {
@@ -203,6 +313,7 @@ func (h *UsersHandler) updateUserRoles(ctx context.Context, targetUserID pgtype.
 		if err = tx.Commit(ctx); err != nil {
 			return errlib.NewError(fmt.Errorf("UpdateUserRoles: failed to commit transaction: %w", err), http.StatusInternalServerError)
 		}
+		authz.InvalidateTenantPermissions(requestingTenantID)
 	}
 
 	return nil
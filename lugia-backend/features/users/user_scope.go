@@ -0,0 +1,73 @@
+// Feature doc: docs/features/user-groups.md
+package users
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"dislyze/jirachi/errlib"
+	"lugia/lib/authz"
+	"lugia/queries"
+)
+
+// checkUserInScope refuses a group admin acting on targetUserID when the
+// target isn't a member of one of their groups, or holds a role with
+// permissions the group admin lacks. It does nothing for tenant-wide admins.
+func checkUserInScope(ctx context.Context, q *queries.Queries, op string, tenantID, targetUserID pgtype.UUID) error {
+	if authz.GetUserScope(ctx) == nil {
+		return nil
+	}
+
+	inScope, err := authz.UserInScope(ctx, q, tenantID, targetUserID)
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("%s: %w", op, err), http.StatusInternalServerError)
+	}
+	if !inScope {
+		return errlib.NewErrorWithDetail(fmt.Errorf("%s: user %s is outside the actor's groups", op, targetUserID.String()), http.StatusForbidden, authz.OutOfScopeDetail)
+	}
+
+	assignments, err := q.GetUserRoleAssignments(ctx, &queries.GetUserRoleAssignmentsParams{
+		UserID:   targetUserID,
+		TenantID: tenantID,
+	})
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("%s: failed to get roles of user %s: %w", op, targetUserID.String(), err), http.StatusInternalServerError)
+	}
+	roleIDs := make([]pgtype.UUID, len(assignments))
+	for i, a := range assignments {
+		roleIDs[i] = a.RoleID
+	}
+	return checkRolesInScope(ctx, q, op, tenantID, roleIDs)
+}
+
+// checkRolesInScope refuses a group admin granting roles with permissions they
+// lack. It does nothing for tenant-wide admins.
+func checkRolesInScope(ctx context.Context, q *queries.Queries, op string, tenantID pgtype.UUID, roleIDs []pgtype.UUID) error {
+	within, err := authz.RolesWithinScope(ctx, q, tenantID, roleIDs)
+	if err != nil {
+		return errlib.NewError(fmt.Errorf("%s: %w", op, err), http.StatusInternalServerError)
+	}
+	if !within {
+		return errlib.NewErrorWithDetail(fmt.Errorf("%s: roles carry permissions the group admin lacks", op), http.StatusForbidden, authz.RolesBeyondScopeDetail)
+	}
+	return nil
+}
+
+// checkGroupInScope refuses a group admin inviting into a group they don't
+// administer, or into no group at all. groupID may be invalid for tenant-wide
+// admins, meaning no group.
+func checkGroupInScope(ctx context.Context, op string, groupID pgtype.UUID) error {
+	scope := authz.GetUserScope(ctx)
+	if scope == nil {
+		return nil
+	}
+	for _, id := range scope.GroupIDs {
+		if groupID.Valid && id == groupID {
+			return nil
+		}
+	}
+	return errlib.NewErrorWithDetail(fmt.Errorf("%s: group admin must invite into one of their groups", op), http.StatusForbidden, "管理しているグループを選択してください。")
+}
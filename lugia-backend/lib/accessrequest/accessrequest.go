@@ -0,0 +1,57 @@
+package accessrequest
+
+import (
+	"time"
+)
+
+// Status is the lifecycle state of a just-in-time access request.
+type Status string
+
+const (
+	StatusPending  Status = "pending"
+	StatusApproved Status = "approved"
+	StatusDenied   Status = "denied"
+	StatusExpired  Status = "expired"
+)
+
+const (
+	MinDurationHours = 1
+	// MaxDurationHours caps a single grant at 30 days; longer access should be
+	// a regular role assignment.
+	MaxDurationHours = 30 * 24
+	// PendingTTL is how long a request waits for a decision before the
+	// maintenance runner expires it.
+	PendingTTL = 7 * 24 * time.Hour
+)
+
+// transitions lists where each status may move. Only pending requests can
+// change; every decision is final.
+var transitions = map[Status][]Status{
+	StatusPending: {StatusApproved, StatusDenied, StatusExpired},
+}
+
+// ParseStatus returns the Status for s, or false if s is not a known status.
+func ParseStatus(s string) (Status, bool) {
+	switch Status(s) {
+	case StatusPending, StatusApproved, StatusDenied, StatusExpired:
+		return Status(s), true
+	}
+	return "", false
+}
+
+// CanTransitionTo reports whether a request in status s may move to next.
+func (s Status) CanTransitionTo(next Status) bool {
+	for _, allowed := range transitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// GrantedUntil returns when a role granted at decidedAt for durationHours
+// expires. The clock starts at approval, not at request time, so a request
+// that waited for a decision still gets its full duration.
+func GrantedUntil(decidedAt time.Time, durationHours int32) time.Time {
+	return decidedAt.Add(time.Duration(durationHours) * time.Hour)
+}
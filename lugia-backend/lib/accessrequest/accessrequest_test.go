@@ -0,0 +1,48 @@
+package accessrequest
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from Status
+		to   Status
+		want bool
+	}{
+		{StatusPending, StatusApproved, true},
+		{StatusPending, StatusDenied, true},
+		{StatusPending, StatusExpired, true},
+		{StatusPending, StatusPending, false},
+		{StatusApproved, StatusDenied, false},
+		{StatusApproved, StatusExpired, false},
+		{StatusDenied, StatusApproved, false},
+		{StatusExpired, StatusApproved, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			if got := tt.from.CanTransitionTo(tt.to); got != tt.want {
+				t.Errorf("%s.CanTransitionTo(%s) = %v, want %v", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseStatus(t *testing.T) {
+	if s, ok := ParseStatus("approved"); !ok || s != StatusApproved {
+		t.Errorf("ParseStatus(approved) = (%q, %v), want (approved, true)", s, ok)
+	}
+	if _, ok := ParseStatus("cancelled"); ok {
+		t.Error("ParseStatus(cancelled) = true, want false")
+	}
+}
+
+func TestGrantedUntil(t *testing.T) {
+	decidedAt := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	want := time.Date(2025, 1, 2, 17, 0, 0, 0, time.UTC)
+	if got := GrantedUntil(decidedAt, 32); !got.Equal(want) {
+		t.Errorf("GrantedUntil() = %v, want %v", got, want)
+	}
+}
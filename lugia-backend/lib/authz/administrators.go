@@ -0,0 +1,36 @@
+package authz
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	libctx "dislyze/jirachi/ctx"
+	"lugia/queries"
+)
+
+// LastAdministratorDetail is shown when a change is refused by TenantStaysManageable.
+const LastAdministratorDetail = "この変更を行うと、ユーザーとロールを管理できる有効なユーザーがいなくなるため実行できません。"
+
+// TenantStaysManageable reports whether the tenant still has at least one
+// active user who can assign roles to users and one with roles edit. Call it
+// with the transaction's queries after the change has been applied, right
+// before commit. It locks the tenant row first so that two concurrent
+// changes, each of which looks safe on its own, can't together remove the
+// last administrator.
+func TenantStaysManageable(ctx context.Context, qtx *queries.Queries, tenantID pgtype.UUID) (bool, error) {
+	if err := qtx.LockTenantForRoleChange(ctx, tenantID); err != nil {
+		return false, fmt.Errorf("TenantStaysManageable: failed to lock tenant %s: %w", tenantID.String(), err)
+	}
+
+	counts, err := qtx.CountTenantAdministrators(ctx, &queries.CountTenantAdministratorsParams{
+		TenantID:    tenantID,
+		RbacEnabled: libctx.GetEnterpriseFeatureEnabled(ctx, "rbac"),
+	})
+	if err != nil {
+		return false, fmt.Errorf("TenantStaysManageable: failed to count administrators for tenant %s: %w", tenantID.String(), err)
+	}
+
+	return counts.UsersEditors > 0 && counts.RolesEditors > 0, nil
+}
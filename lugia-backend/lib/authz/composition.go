@@ -0,0 +1,48 @@
+package authz
+
+// RoleInclusions maps a role ID to the IDs of the roles it directly includes.
+// A role carries the permissions of every role reachable from it, which is
+// how the permission queries expand a user's roles.
+type RoleInclusions map[string][]string
+
+// IncludedRoles returns every role reachable from roleID, nearest first,
+// without roleID itself. It terminates on cycles even though the handlers
+// never save one.
+func (g RoleInclusions) IncludedRoles(roleID string) []string {
+	seen := map[string]bool{roleID: true}
+	var out []string
+	queue := append([]string{}, g[roleID]...)
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		out = append(out, id)
+		queue = append(queue, g[id]...)
+	}
+	return out
+}
+
+// WouldCreateCycle reports whether replacing roleID's inclusions with
+// includedIDs would let the role reach itself.
+func (g RoleInclusions) WouldCreateCycle(roleID string, includedIDs []string) bool {
+	next := make(RoleInclusions, len(g)+1)
+	for id, included := range g {
+		next[id] = included
+	}
+	next[roleID] = includedIDs
+
+	for _, id := range includedIDs {
+		if id == roleID {
+			return true
+		}
+		for _, reachable := range next.IncludedRoles(id) {
+			if reachable == roleID {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package authz
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRoleInclusions(t *testing.T) {
+	g := RoleInclusions{
+		"auditor":  {"viewer"},
+		"manager":  {"auditor", "inviter"},
+		"inviter":  {"viewer"},
+		"operator": {"manager"},
+	}
+
+	t.Run("included roles are expanded transitively", func(t *testing.T) {
+		got := g.IncludedRoles("operator")
+		want := []string{"manager", "auditor", "inviter", "viewer"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("IncludedRoles(operator) = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("a role with no inclusions reaches nothing", func(t *testing.T) {
+		if got := g.IncludedRoles("viewer"); len(got) != 0 {
+			t.Errorf("IncludedRoles(viewer) = %v, want none", got)
+		}
+	})
+
+	t.Run("an existing cycle still terminates", func(t *testing.T) {
+		cyclic := RoleInclusions{"a": {"b"}, "b": {"a"}}
+		if got := cyclic.IncludedRoles("a"); !reflect.DeepEqual(got, []string{"b"}) {
+			t.Errorf("IncludedRoles(a) = %v, want [b]", got)
+		}
+	})
+
+	cycles := []struct {
+		name     string
+		roleID   string
+		included []string
+		want     bool
+	}{
+		{"self inclusion", "viewer", []string{"viewer"}, true},
+		{"direct cycle", "auditor", []string{"manager"}, true},
+		{"indirect cycle", "viewer", []string{"operator"}, true},
+		{"diamond is not a cycle", "operator", []string{"manager", "inviter"}, false},
+		{"new role", "new", []string{"operator"}, false},
+	}
+	for _, tc := range cycles {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := g.WouldCreateCycle(tc.roleID, tc.included); got != tc.want {
+				t.Errorf("WouldCreateCycle(%s, %v) = %v, want %v", tc.roleID, tc.included, got, tc.want)
+			}
+		})
+	}
+}
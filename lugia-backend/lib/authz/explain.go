@@ -0,0 +1,183 @@
+package authz
+
+import (
+	"sort"
+)
+
+// RoleGrant is one permission carried by a role, as loaded to explain a user's
+// access. Resource and Action are empty for a role that grants nothing.
+type RoleGrant struct {
+	RoleID    string
+	RoleName  string
+	IsDefault bool
+	// InheritedFrom names the included role that actually carries the
+	// permission; empty when RoleID grants it directly.
+	InheritedFrom string
+	Resource      string
+	Action        string
+}
+
+type GrantingRole struct {
+	ID   string
+	Name string
+	// ViaFallback marks the default 閲覧者 role applied because none of the
+	// user's own roles grant the permission.
+	ViaFallback   bool
+	InheritedFrom string
+}
+
+type EffectivePermission struct {
+	Resource  string
+	Action    string
+	GrantedBy []GrantingRole
+}
+
+// EffectivePermissions explains what UserHasPermission will answer for one
+// user. It mirrors the query: with RBAC off only default roles count, and the
+// tenant's default 閲覧者 role is consulted for any check the counted roles
+// don't satisfy.
+type EffectivePermissions struct {
+	RBACEnabled bool
+	// FallbackInEffect is true when none of the user's roles count, so
+	// everything they can do comes from the 閲覧者 role.
+	FallbackInEffect bool
+	// IgnoredRoles are assigned custom roles that don't count because RBAC is
+	// off.
+	IgnoredRoles []GrantingRole
+	Permissions  []EffectivePermission
+
+	counted  []RoleGrant
+	fallback []RoleGrant
+}
+
+type DecisionReason string
+
+const (
+	// ReasonGranted: a role grants exactly the requested action.
+	ReasonGranted DecisionReason = "granted"
+	// ReasonViewImplied: "view" was requested and a role grants another
+	// action on the resource.
+	ReasonViewImplied DecisionReason = "view_implied"
+	// ReasonNotGranted: no counted role, and not the 閲覧者 fallback, grants it.
+	ReasonNotGranted DecisionReason = "not_granted"
+	// ReasonFeatureDisabled: the permission's enterprise feature is off for
+	// the tenant, so routes that need it are unavailable regardless of roles.
+	ReasonFeatureDisabled DecisionReason = "feature_disabled"
+)
+
+type Decision struct {
+	Allowed   bool
+	Reason    DecisionReason
+	GrantedBy []GrantingRole
+}
+
+// ResolveEffectivePermissions builds the explanation from the user's role
+// grants in the tenant and the grants of the tenant's default 閲覧者 role.
+func ResolveEffectivePermissions(userGrants, viewerGrants []RoleGrant, rbacEnabled bool) *EffectivePermissions {
+	e := &EffectivePermissions{
+		RBACEnabled:  rbacEnabled,
+		IgnoredRoles: []GrantingRole{},
+		Permissions:  []EffectivePermission{},
+	}
+
+	ignored := map[string]bool{}
+	countedRoles := map[string]bool{}
+	for _, g := range userGrants {
+		if !rbacEnabled && !g.IsDefault {
+			if !ignored[g.RoleID] {
+				ignored[g.RoleID] = true
+				e.IgnoredRoles = append(e.IgnoredRoles, GrantingRole{ID: g.RoleID, Name: g.RoleName})
+			}
+			continue
+		}
+		countedRoles[g.RoleID] = true
+		if g.Resource != "" {
+			e.counted = append(e.counted, g)
+		}
+	}
+	e.FallbackInEffect = len(countedRoles) == 0
+
+	for _, g := range viewerGrants {
+		if g.Resource != "" {
+			e.fallback = append(e.fallback, g)
+		}
+	}
+
+	index := map[string]int{}
+	// A composite role can reach the same permission more than once; the
+	// first grant listed (direct before inherited) is the one shown.
+	listed := map[string]bool{}
+	add := func(g RoleGrant, viaFallback bool) {
+		key := g.Resource + "." + g.Action
+		if listed[key+"/"+g.RoleID] {
+			return
+		}
+		listed[key+"/"+g.RoleID] = true
+		i, ok := index[key]
+		if !ok {
+			i = len(e.Permissions)
+			index[key] = i
+			e.Permissions = append(e.Permissions, EffectivePermission{Resource: g.Resource, Action: g.Action})
+		}
+		e.Permissions[i].GrantedBy = append(e.Permissions[i].GrantedBy, GrantingRole{ID: g.RoleID, Name: g.RoleName, ViaFallback: viaFallback, InheritedFrom: g.InheritedFrom})
+	}
+	for _, g := range e.counted {
+		add(g, false)
+	}
+	for _, g := range e.fallback {
+		if _, ok := index[g.Resource+"."+g.Action]; !ok {
+			add(g, true)
+		}
+	}
+
+	sort.SliceStable(e.Permissions, func(i, j int) bool {
+		if e.Permissions[i].Resource != e.Permissions[j].Resource {
+			return e.Permissions[i].Resource < e.Permissions[j].Resource
+		}
+		return e.Permissions[i].Action < e.Permissions[j].Action
+	})
+
+	return e
+}
+
+// Check answers the way UserHasPermission would for permission and says why.
+// featureEnabled is whether the tenant has permission.Feature turned on.
+func (e *EffectivePermissions) Check(permission Permission, featureEnabled bool) Decision {
+	if permission.Feature != "" && !featureEnabled {
+		return Decision{Allowed: false, Reason: ReasonFeatureDisabled, GrantedBy: []GrantingRole{}}
+	}
+
+	// The counted roles are consulted first; the 閲覧者 role only when they
+	// don't satisfy the check.
+	for _, source := range []struct {
+		grants      []RoleGrant
+		viaFallback bool
+	}{{e.counted, false}, {e.fallback, true}} {
+		if roles := matchingRoles(source.grants, permission, true, source.viaFallback); len(roles) > 0 {
+			return Decision{Allowed: true, Reason: ReasonGranted, GrantedBy: roles}
+		}
+		if permission.Action == ActionView {
+			if roles := matchingRoles(source.grants, permission, false, source.viaFallback); len(roles) > 0 {
+				return Decision{Allowed: true, Reason: ReasonViewImplied, GrantedBy: roles}
+			}
+		}
+	}
+
+	return Decision{Allowed: false, Reason: ReasonNotGranted, GrantedBy: []GrantingRole{}}
+}
+
+func matchingRoles(grants []RoleGrant, permission Permission, exact bool, viaFallback bool) []GrantingRole {
+	var roles []GrantingRole
+	seen := map[string]bool{}
+	for _, g := range grants {
+		if g.Resource != permission.Resource.String() || seen[g.RoleID] {
+			continue
+		}
+		if exact && g.Action != permission.Action {
+			continue
+		}
+		seen[g.RoleID] = true
+		roles = append(roles, GrantingRole{ID: g.RoleID, Name: g.RoleName, ViaFallback: viaFallback, InheritedFrom: g.InheritedFrom})
+	}
+	return roles
+}
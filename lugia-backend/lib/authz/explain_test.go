@@ -0,0 +1,111 @@
+package authz
+
+import (
+	"testing"
+)
+
+func TestResolveEffectivePermissions(t *testing.T) {
+	admin := []RoleGrant{
+		{RoleID: "admin", RoleName: "管理者", IsDefault: true, Resource: "users", Action: "edit"},
+		{RoleID: "admin", RoleName: "管理者", IsDefault: true, Resource: "users", Action: "invite"},
+	}
+	custom := []RoleGrant{
+		{RoleID: "custom", RoleName: "招待担当", IsDefault: false, Resource: "users", Action: "invite"},
+	}
+	viewer := []RoleGrant{
+		{RoleID: "viewer", RoleName: "閲覧者", IsDefault: true, Resource: "tenant", Action: "view"},
+	}
+
+	t.Run("roles granting the same permission are all listed", func(t *testing.T) {
+		e := ResolveEffectivePermissions(append(append([]RoleGrant{}, admin...), custom...), nil, true)
+
+		if e.FallbackInEffect {
+			t.Error("FallbackInEffect = true, want false")
+		}
+		if len(e.Permissions) != 2 {
+			t.Fatalf("len(Permissions) = %d, want 2", len(e.Permissions))
+		}
+		invite := e.Permissions[1]
+		if invite.Action != "invite" || len(invite.GrantedBy) != 2 {
+			t.Errorf("Permissions[1] = %+v, want users.invite granted by two roles", invite)
+		}
+	})
+
+	t.Run("custom roles are ignored when RBAC is off", func(t *testing.T) {
+		e := ResolveEffectivePermissions(custom, viewer, false)
+
+		if !e.FallbackInEffect {
+			t.Error("FallbackInEffect = false, want true")
+		}
+		if len(e.IgnoredRoles) != 1 || e.IgnoredRoles[0].ID != "custom" {
+			t.Errorf("IgnoredRoles = %+v, want the custom role", e.IgnoredRoles)
+		}
+		if len(e.Permissions) != 1 || !e.Permissions[0].GrantedBy[0].ViaFallback {
+			t.Errorf("Permissions = %+v, want only tenant.view via the fallback", e.Permissions)
+		}
+	})
+
+	t.Run("fallback role fills checks the counted roles don't satisfy", func(t *testing.T) {
+		e := ResolveEffectivePermissions(admin, viewer, true)
+
+		if e.FallbackInEffect {
+			t.Error("FallbackInEffect = true, want false")
+		}
+		d := e.Check(PermTenantView, true)
+		if !d.Allowed || d.Reason != ReasonGranted || len(d.GrantedBy) != 1 || !d.GrantedBy[0].ViaFallback {
+			t.Errorf("Check(tenant.view) = %+v, want granted via the fallback", d)
+		}
+	})
+
+	t.Run("inherited grants name the included role once per assigned role", func(t *testing.T) {
+		composite := []RoleGrant{
+			{RoleID: "composite", RoleName: "運用担当", Resource: "users", Action: "invite"},
+			{RoleID: "composite", RoleName: "運用担当", InheritedFrom: "招待担当", Resource: "users", Action: "invite"},
+			{RoleID: "composite", RoleName: "運用担当", InheritedFrom: "監査担当", Resource: "audit_log", Action: "view"},
+		}
+		e := ResolveEffectivePermissions(composite, nil, true)
+
+		if len(e.Permissions) != 2 {
+			t.Fatalf("len(Permissions) = %d, want 2", len(e.Permissions))
+		}
+		invite := e.Permissions[1]
+		if len(invite.GrantedBy) != 1 || invite.GrantedBy[0].InheritedFrom != "" {
+			t.Errorf("users.invite GrantedBy = %+v, want the direct grant only", invite.GrantedBy)
+		}
+		d := e.Check(PermAuditLogView, true)
+		if !d.Allowed || len(d.GrantedBy) != 1 || d.GrantedBy[0].InheritedFrom != "監査担当" {
+			t.Errorf("Check(audit_log.view) = %+v, want granted through 監査担当", d)
+		}
+	})
+}
+
+func TestEffectivePermissionsCheck(t *testing.T) {
+	e := ResolveEffectivePermissions([]RoleGrant{
+		{RoleID: "r1", RoleName: "招待担当", IsDefault: false, Resource: "users", Action: "invite"},
+		{RoleID: "r1", RoleName: "招待担当", IsDefault: false, Resource: "ip_whitelist", Action: "edit"},
+	}, nil, true)
+
+	tests := []struct {
+		name           string
+		permission     Permission
+		featureEnabled bool
+		wantAllowed    bool
+		wantReason     DecisionReason
+	}{
+		{"exact action", PermUsersInvite, true, true, ReasonGranted},
+		{"view implied by another action", PermUsersView, true, true, ReasonViewImplied},
+		{"other action not implied", PermUsersDelete, true, false, ReasonNotGranted},
+		{"edit does not imply fine-grained action", PermIPWhitelistEmergency, true, false, ReasonNotGranted},
+		{"feature disabled", PermIPWhitelistEdit, false, false, ReasonFeatureDisabled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := e.Check(tt.permission, tt.featureEnabled)
+			if d.Allowed != tt.wantAllowed || d.Reason != tt.wantReason {
+				t.Errorf("Check(%s) = (%v, %s), want (%v, %s)", tt.permission, d.Allowed, d.Reason, tt.wantAllowed, tt.wantReason)
+			}
+		})
+	}
+
+}
@@ -10,3 +10,11 @@ func GetIPWhitelistActive(ctx context.Context) bool {
 	ipConfig := libctx.GetIPWhitelistConfig(ctx)
 	return ipConfig.Active
 }
+
+// GetIPWhitelistMonitor reports whether the whitelist is in monitor mode:
+// requests it would block are recorded but let through. Enforcement wins if
+// both flags are set.
+func GetIPWhitelistMonitor(ctx context.Context) bool {
+	ipConfig := libctx.GetIPWhitelistConfig(ctx)
+	return ipConfig.Monitor && !ipConfig.Active
+}
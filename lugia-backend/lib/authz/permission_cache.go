@@ -0,0 +1,166 @@
+package authz
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	jirachiAuthz "dislyze/jirachi/authz"
+	"lugia/queries"
+)
+
+// defaultPermissionCacheTTL bounds how long a role change made through another
+// lugia process can go unnoticed here. Changes made through this process
+// invalidate the tenant's entries as soon as they commit.
+const defaultPermissionCacheTTL = 30 * time.Second
+
+// permissionCacheMaxEntries caps memory use. Past it, expired entries are
+// dropped, and if that isn't enough the cache starts over.
+const permissionCacheMaxEntries = 10000
+
+type permissionCacheKey struct {
+	tenantID    pgtype.UUID
+	userID      pgtype.UUID
+	rbacEnabled bool
+}
+
+type permissionCacheEntry struct {
+	permissions jirachiAuthz.PermissionSet
+	expiresAt   time.Time
+}
+
+// permissionCache holds loaded permission sets per tenant, user and RBAC
+// status. Each tenant has a generation that invalidation bumps, so a load
+// that read the database before a role change can't store its stale result
+// after the change has invalidated the tenant.
+type permissionCache struct {
+	mu          sync.Mutex
+	ttl         time.Duration
+	now         func() time.Time
+	entries     map[permissionCacheKey]permissionCacheEntry
+	generations map[pgtype.UUID]uint64
+}
+
+func newPermissionCache(ttl time.Duration, now func() time.Time) *permissionCache {
+	return &permissionCache{
+		ttl:         ttl,
+		now:         now,
+		entries:     map[permissionCacheKey]permissionCacheEntry{},
+		generations: map[pgtype.UUID]uint64{},
+	}
+}
+
+var userPermissions = newPermissionCache(defaultPermissionCacheTTL, time.Now)
+
+// get returns the cached set for key, or the tenant's current generation to
+// pass to put once the set has been loaded.
+func (c *permissionCache) get(key permissionCacheKey) (jirachiAuthz.PermissionSet, uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if ok && c.now().Before(entry.expiresAt) {
+		return entry.permissions, 0, true
+	}
+	if ok {
+		delete(c.entries, key)
+	}
+	return nil, c.generations[key.tenantID], false
+}
+
+// put stores permissions unless the tenant was invalidated since generation
+// was read. lapsesAt is when the earliest time-bound grant in the set ends;
+// the entry never outlives it. A zero lapsesAt means no grant lapses.
+func (c *permissionCache) put(key permissionCacheKey, generation uint64, permissions jirachiAuthz.PermissionSet, lapsesAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.ttl <= 0 || c.generations[key.tenantID] != generation {
+		return
+	}
+
+	now := c.now()
+	expiresAt := now.Add(c.ttl)
+	if !lapsesAt.IsZero() && lapsesAt.Before(expiresAt) {
+		expiresAt = lapsesAt
+	}
+
+	if len(c.entries) >= permissionCacheMaxEntries {
+		for k, entry := range c.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= permissionCacheMaxEntries {
+			c.entries = map[permissionCacheKey]permissionCacheEntry{}
+		}
+	}
+
+	c.entries[key] = permissionCacheEntry{permissions: permissions, expiresAt: expiresAt}
+}
+
+func (c *permissionCache) invalidateTenant(tenantID pgtype.UUID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generations[tenantID]++
+	for key := range c.entries {
+		if key.tenantID == tenantID {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// SetPermissionCacheTTL replaces the default TTL from PERMISSION_CACHE_TTL.
+// Zero turns caching off, which tests that change roles directly in the
+// database rely on.
+func SetPermissionCacheTTL(ttl time.Duration) {
+	userPermissions.mu.Lock()
+	defer userPermissions.mu.Unlock()
+
+	userPermissions.ttl = ttl
+	userPermissions.entries = map[permissionCacheKey]permissionCacheEntry{}
+}
+
+// LoadUserPermissions returns everything the user holds in the tenant: their
+// unexpired roles (only default ones when RBAC is off), the roles those
+// include, and 閲覧者, which applies to any check their own roles don't
+// satisfy. Sets are cached for up to PERMISSION_CACHE_TTL.
+func LoadUserPermissions(ctx context.Context, q *queries.Queries, tenantID, userID pgtype.UUID, rbacEnabled bool) (jirachiAuthz.PermissionSet, error) {
+	key := permissionCacheKey{tenantID: tenantID, userID: userID, rbacEnabled: rbacEnabled}
+	permissions, generation, ok := userPermissions.get(key)
+	if ok {
+		return permissions, nil
+	}
+
+	rows, err := q.GetUserPermissionSet(ctx, &queries.GetUserPermissionSetParams{
+		UserID:      userID,
+		TenantID:    tenantID,
+		RbacEnabled: rbacEnabled,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("LoadUserPermissions: failed to get permissions of user %s in tenant %s: %w", userID.String(), tenantID.String(), err)
+	}
+
+	permissions = jirachiAuthz.PermissionSet{}
+	var lapsesAt time.Time
+	for _, row := range rows {
+		permissions.Add(row.Resource, row.Action)
+		if row.ExpiresAt.Valid && (lapsesAt.IsZero() || row.ExpiresAt.Time.Before(lapsesAt)) {
+			lapsesAt = row.ExpiresAt.Time
+		}
+	}
+
+	userPermissions.put(key, generation, permissions, lapsesAt)
+	return permissions, nil
+}
+
+// InvalidateTenantPermissions drops the tenant's cached permission sets. Call
+// it after committing anything that changes who holds which permission: role
+// assignments, role permissions, role inclusions or membership.
+func InvalidateTenantPermissions(tenantID pgtype.UUID) {
+	userPermissions.invalidateTenant(tenantID)
+}
@@ -0,0 +1,90 @@
+package authz
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	jirachiAuthz "dislyze/jirachi/authz"
+)
+
+func TestPermissionCache(t *testing.T) {
+	now := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	c := newPermissionCache(30*time.Second, func() time.Time { return now })
+
+	tenantA := pgtype.UUID{Bytes: [16]byte{1}, Valid: true}
+	tenantB := pgtype.UUID{Bytes: [16]byte{2}, Valid: true}
+	user := pgtype.UUID{Bytes: [16]byte{3}, Valid: true}
+	keyA := permissionCacheKey{tenantID: tenantA, userID: user, rbacEnabled: true}
+	keyB := permissionCacheKey{tenantID: tenantB, userID: user, rbacEnabled: true}
+
+	set := jirachiAuthz.PermissionSet{}
+	set.Add("users", "view")
+
+	t.Run("a stored set is served until the TTL passes", func(t *testing.T) {
+		_, generation, ok := c.get(keyA)
+		if ok {
+			t.Fatal("get on an empty cache hit")
+		}
+		c.put(keyA, generation, set, time.Time{})
+
+		if _, _, ok := c.get(keyA); !ok {
+			t.Error("get right after put missed")
+		}
+		if _, _, ok := c.get(permissionCacheKey{tenantID: tenantA, userID: user, rbacEnabled: false}); ok {
+			t.Error("a set loaded with RBAC on was served with RBAC off")
+		}
+
+		now = now.Add(30 * time.Second)
+		if _, _, ok := c.get(keyA); ok {
+			t.Error("get after the TTL hit")
+		}
+	})
+
+	t.Run("an entry ends when its earliest grant lapses", func(t *testing.T) {
+		_, generation, _ := c.get(keyA)
+		c.put(keyA, generation, set, now.Add(5*time.Second))
+
+		now = now.Add(5 * time.Second)
+		if _, _, ok := c.get(keyA); ok {
+			t.Error("get after a grant lapsed hit")
+		}
+	})
+
+	t.Run("invalidation drops only that tenant", func(t *testing.T) {
+		_, generationA, _ := c.get(keyA)
+		c.put(keyA, generationA, set, time.Time{})
+		_, generationB, _ := c.get(keyB)
+		c.put(keyB, generationB, set, time.Time{})
+
+		c.invalidateTenant(tenantA)
+
+		if _, _, ok := c.get(keyA); ok {
+			t.Error("invalidated tenant still hit")
+		}
+		if _, _, ok := c.get(keyB); !ok {
+			t.Error("other tenant was dropped")
+		}
+	})
+
+	t.Run("a load that started before invalidation is not stored", func(t *testing.T) {
+		_, generation, _ := c.get(keyA)
+		c.invalidateTenant(tenantA)
+		c.put(keyA, generation, set, time.Time{})
+
+		if _, _, ok := c.get(keyA); ok {
+			t.Error("stale load was stored")
+		}
+	})
+
+	t.Run("a zero TTL stores nothing", func(t *testing.T) {
+		off := newPermissionCache(0, func() time.Time { return now })
+		_, generation, _ := off.get(keyA)
+		off.put(keyA, generation, set, time.Time{})
+
+		if _, _, ok := off.get(keyA); ok {
+			t.Error("get hit with caching off")
+		}
+	})
+}
@@ -2,11 +2,8 @@ package authz
 
 import (
 	"context"
-	"fmt"
 
 	libctx "dislyze/jirachi/ctx"
-	"dislyze/jirachi/errlib"
-	"lugia/queries"
 )
 
 type Resource string
@@ -26,27 +23,20 @@ const (
 	ResourceAuditLog    Resource = "audit_log"
 )
 
+// A "view" check is satisfied by any action on the same resource; every other
+// action must be granted explicitly. "edit" no longer implies the finer-grained
+// actions below.
 const (
-	ActionView = "view"
-	ActionEdit = "edit"
+	ActionView        = "view"
+	ActionEdit        = "edit"
+	ActionInvite      = "invite"
+	ActionDelete      = "delete"
+	ActionAssignRoles = "assign_roles"
+	ActionEmergency   = "emergency"
 )
 
-func UserHasPermission(ctx context.Context, db *queries.Queries, resource Resource, action string) bool {
-	userID := libctx.GetUserID(ctx)
-	tenantID := libctx.GetTenantID(ctx)
-
-	rbacEnabled := libctx.GetEnterpriseFeatureEnabled(ctx, "rbac")
-
-	hasPermission, err := db.UserHasPermission(ctx, &queries.UserHasPermissionParams{
-		UserID:      userID,
-		TenantID:    tenantID,
-		Resource:    resource.String(),
-		Action:      action,
-		RbacEnabled: rbacEnabled,
-	})
-	if err != nil {
-		errlib.LogError(fmt.Errorf("failed to check user permission: %w", err))
-		return false
-	}
-	return hasPermission
+// UserHasPermission checks the permission set LoadTenantAndUserContext put in
+// the request context, so it never queries the database.
+func UserHasPermission(ctx context.Context, permission Permission) bool {
+	return libctx.GetPermissions(ctx).Allows(permission.Resource.String(), permission.Action)
 }
@@ -0,0 +1,143 @@
+package authz
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"dislyze/jirachi/errlib"
+	"lugia/queries"
+)
+
+// Permission is a resource/action pair that roles can grant. Registry is the
+// source of truth for the permissions table: SyncPermissions inserts entries
+// that are missing and flags rows the registry no longer declares, so adding a
+// permission only needs an entry here.
+type Permission struct {
+	Resource Resource
+	Action   string
+	// Feature gates the permission behind an enterprise feature; "" means the
+	// resource is always available.
+	Feature EnterpriseFeature
+	// Description is the Japanese label shown in the role editor, and
+	// DescriptionEN the English one.
+	Description   string
+	DescriptionEN string
+}
+
+func (p Permission) String() string {
+	return fmt.Sprintf("%s.%s", p.Resource, p.Action)
+}
+
+var (
+	PermTenantView = Permission{Resource: ResourceTenant, Action: ActionView, Description: "テナント情報の閲覧", DescriptionEN: "View tenant information"}
+	PermTenantEdit = Permission{Resource: ResourceTenant, Action: ActionEdit, Description: "テナント情報の編集", DescriptionEN: "Edit tenant information"}
+
+	PermUsersView        = Permission{Resource: ResourceUsers, Action: ActionView, Description: "ユーザー一覧の閲覧", DescriptionEN: "View the user list"}
+	PermUsersEdit        = Permission{Resource: ResourceUsers, Action: ActionEdit, Description: "ユーザーの編集", DescriptionEN: "Edit users"}
+	PermUsersInvite      = Permission{Resource: ResourceUsers, Action: ActionInvite, Description: "ユーザーの招待", DescriptionEN: "Invite users"}
+	PermUsersDelete      = Permission{Resource: ResourceUsers, Action: ActionDelete, Description: "ユーザーの削除", DescriptionEN: "Delete users"}
+	PermUsersAssignRoles = Permission{Resource: ResourceUsers, Action: ActionAssignRoles, Description: "ユーザーへのロール割り当て", DescriptionEN: "Assign roles to users"}
+
+	PermRolesView = Permission{Resource: ResourceRoles, Action: ActionView, Description: "ロール一覧の閲覧", DescriptionEN: "View the role list"}
+	PermRolesEdit = Permission{Resource: ResourceRoles, Action: ActionEdit, Description: "ロールの編集", DescriptionEN: "Edit roles"}
+
+	PermIPWhitelistView      = Permission{Resource: ResourceIPWhitelist, Action: ActionView, Feature: FeatureIPWhitelist, Description: "IP制限画面の閲覧", DescriptionEN: "View IP restriction settings"}
+	PermIPWhitelistEdit      = Permission{Resource: ResourceIPWhitelist, Action: ActionEdit, Feature: FeatureIPWhitelist, Description: "IP制限画面の編集", DescriptionEN: "Edit IP restriction settings"}
+	PermIPWhitelistEmergency = Permission{Resource: ResourceIPWhitelist, Action: ActionEmergency, Feature: FeatureIPWhitelist, Description: "IP制限の緊急解除", DescriptionEN: "Lift IP restrictions in an emergency"}
+
+	PermAuditLogView = Permission{Resource: ResourceAuditLog, Action: ActionView, Feature: FeatureAuditLog, Description: "監査ログの閲覧", DescriptionEN: "View audit logs"}
+)
+
+var Registry = []Permission{
+	PermTenantView,
+	PermTenantEdit,
+	PermUsersView,
+	PermUsersEdit,
+	PermUsersInvite,
+	PermUsersDelete,
+	PermUsersAssignRoles,
+	PermRolesView,
+	PermRolesEdit,
+	PermIPWhitelistView,
+	PermIPWhitelistEdit,
+	PermIPWhitelistEmergency,
+	PermAuditLogView,
+}
+
+// LookupPermission returns the registry entry for resource and action.
+func LookupPermission(resource, action string) (Permission, bool) {
+	for _, p := range Registry {
+		if p.Resource.String() == resource && p.Action == action {
+			return p, true
+		}
+	}
+	return Permission{}, false
+}
+
+// FeatureForResource returns the enterprise feature that gates resource. ok is
+// false for resources the registry does not declare, which callers must treat
+// as an error rather than exposing an ungated permission.
+func FeatureForResource(resource string) (feature EnterpriseFeature, ok bool) {
+	for _, p := range Registry {
+		if p.Resource.String() == resource {
+			return p.Feature, true
+		}
+	}
+	return "", false
+}
+
+// SyncPermissions reconciles the permissions table with Registry. Missing
+// entries are inserted, changed descriptions are updated, and rows the
+// registry no longer declares get removed_at set. Flagged rows keep their
+// role grants but are no longer offered in the role editor.
+func SyncPermissions(ctx context.Context, pool *pgxpool.Pool, q *queries.Queries) error {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("SyncPermissions: failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errlib.Is(rbErr, pgx.ErrTxClosed) {
+			errlib.LogError(fmt.Errorf("SyncPermissions: failed to rollback transaction: %w", rbErr))
+		}
+	}()
+	qtx := q.WithTx(tx)
+
+	resources := make([]string, 0, len(Registry))
+	actions := make([]string, 0, len(Registry))
+	var changed int64
+	for _, p := range Registry {
+		n, err := qtx.UpsertPermission(ctx, &queries.UpsertPermissionParams{
+			Resource:      p.Resource.String(),
+			Action:        p.Action,
+			Description:   p.Description,
+			DescriptionEn: p.DescriptionEN,
+		})
+		if err != nil {
+			return fmt.Errorf("SyncPermissions: failed to upsert %s: %w", p, err)
+		}
+		changed += n
+		resources = append(resources, p.Resource.String())
+		actions = append(actions, p.Action)
+	}
+
+	removed, err := qtx.MarkPermissionsRemoved(ctx, &queries.MarkPermissionsRemovedParams{
+		Resources: resources,
+		Actions:   actions,
+	})
+	if err != nil {
+		return fmt.Errorf("SyncPermissions: failed to flag removed permissions: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("SyncPermissions: failed to commit transaction: %w", err)
+	}
+
+	if changed > 0 || removed > 0 {
+		log.Printf("SyncPermissions: %d permissions inserted or updated, %d flagged as removed", changed, removed)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package authz
+
+import (
+	"testing"
+)
+
+func TestRegistryIsConsistent(t *testing.T) {
+	seen := map[string]bool{}
+	features := map[Resource]EnterpriseFeature{}
+	hasView := map[Resource]bool{}
+
+	for _, p := range Registry {
+		if seen[p.String()] {
+			t.Errorf("permission %s is registered twice", p)
+		}
+		seen[p.String()] = true
+
+		if p.Description == "" || p.DescriptionEN == "" {
+			t.Errorf("permission %s is missing a Japanese or English description", p)
+		}
+
+		if feature, ok := features[p.Resource]; ok && feature != p.Feature {
+			t.Errorf("permission %s is gated by %q, but other %s permissions use %q", p, p.Feature, p.Resource, feature)
+		}
+		features[p.Resource] = p.Feature
+
+		if p.Action == ActionView {
+			hasView[p.Resource] = true
+		}
+	}
+
+	for resource := range features {
+		if !hasView[resource] {
+			t.Errorf("resource %s has no view permission", resource)
+		}
+	}
+}
+
+func TestFeatureForResource(t *testing.T) {
+	tests := []struct {
+		resource    string
+		wantFeature EnterpriseFeature
+		wantOK      bool
+	}{
+		{"users", "", true},
+		{"ip_whitelist", FeatureIPWhitelist, true},
+		{"audit_log", FeatureAuditLog, true},
+		{"unknown", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.resource, func(t *testing.T) {
+			feature, ok := FeatureForResource(tt.resource)
+			if feature != tt.wantFeature || ok != tt.wantOK {
+				t.Errorf("FeatureForResource(%q) = (%q, %v), want (%q, %v)", tt.resource, feature, ok, tt.wantFeature, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package authz
+
+import (
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"lugia/queries"
+)
+
+// RoleTemplate is a built-in starting point for a custom role. Instantiating
+// one creates an ordinary custom role with a copy of the template's
+// permissions; later changes to the template don't reach roles already
+// created from it.
+type RoleTemplate struct {
+	Key         string
+	Name        string
+	Description string
+	Permissions []Permission
+}
+
+var (
+	TemplateReadOnlyAuditor = RoleTemplate{
+		Key:         "readonly_auditor",
+		Name:        "読み取り専用監査担当",
+		Description: "すべての設定と監査ログの閲覧のみ",
+		Permissions: []Permission{PermTenantView, PermUsersView, PermRolesView, PermIPWhitelistView, PermAuditLogView},
+	}
+	TemplateSecurityAdmin = RoleTemplate{
+		Key:         "security_admin",
+		Name:        "セキュリティ管理者",
+		Description: "IPアドレス制限の管理と監査ログの閲覧",
+		Permissions: []Permission{PermUsersView, PermRolesView, PermIPWhitelistEdit, PermIPWhitelistEmergency, PermAuditLogView},
+	}
+	// TemplateHelpdesk can invite but not assign roles to existing users, so
+	// it can't raise anyone's access, its holder's included.
+	TemplateHelpdesk = RoleTemplate{
+		Key:         "helpdesk",
+		Name:        "ヘルプデスク",
+		Description: "ユーザー一覧の閲覧とユーザーの招待",
+		Permissions: []Permission{PermUsersView, PermUsersInvite, PermRolesView},
+	}
+)
+
+var RoleTemplates = []RoleTemplate{
+	TemplateReadOnlyAuditor,
+	TemplateSecurityAdmin,
+	TemplateHelpdesk,
+}
+
+func LookupRoleTemplate(key string) (RoleTemplate, bool) {
+	for _, t := range RoleTemplates {
+		if t.Key == key {
+			return t, true
+		}
+	}
+	return RoleTemplate{}, false
+}
+
+// PermissionIDs maps the template's permissions to the rows returned by
+// GetAllPermissions. Every template permission is in the registry, so a
+// missing row means SyncPermissions hasn't run.
+func (t RoleTemplate) PermissionIDs(all []*queries.GetAllPermissionsRow) ([]pgtype.UUID, error) {
+	ids := make(map[string]pgtype.UUID, len(all))
+	for _, p := range all {
+		ids[p.Resource+"."+p.Action] = p.ID
+	}
+
+	permissionIDs := make([]pgtype.UUID, 0, len(t.Permissions))
+	for _, p := range t.Permissions {
+		id, ok := ids[p.String()]
+		if !ok {
+			return nil, fmt.Errorf("RoleTemplate %s: permission %s is not in the database", t.Key, p)
+		}
+		permissionIDs = append(permissionIDs, id)
+	}
+	return permissionIDs, nil
+}
@@ -0,0 +1,62 @@
+package authz
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"lugia/queries"
+)
+
+func TestRoleTemplatesAreConsistent(t *testing.T) {
+	keys := map[string]bool{}
+	names := map[string]bool{"管理者": true, "編集者": true, "閲覧者": true}
+
+	for _, tmpl := range RoleTemplates {
+		if keys[tmpl.Key] {
+			t.Errorf("template key %s is used twice", tmpl.Key)
+		}
+		keys[tmpl.Key] = true
+
+		if names[tmpl.Name] {
+			t.Errorf("template %s reuses the role name %s", tmpl.Key, tmpl.Name)
+		}
+		names[tmpl.Name] = true
+
+		if len(tmpl.Permissions) == 0 {
+			t.Errorf("template %s has no permissions", tmpl.Key)
+		}
+		for _, p := range tmpl.Permissions {
+			if _, ok := LookupPermission(p.Resource.String(), p.Action); !ok {
+				t.Errorf("template %s uses %s, which is not in the registry", tmpl.Key, p)
+			}
+		}
+	}
+}
+
+func TestRoleTemplatePermissionIDs(t *testing.T) {
+	all := make([]*queries.GetAllPermissionsRow, len(Registry))
+	for i, p := range Registry {
+		all[i] = &queries.GetAllPermissionsRow{
+			ID:       pgtype.UUID{Bytes: [16]byte{byte(i + 1)}, Valid: true},
+			Resource: p.Resource.String(),
+			Action:   p.Action,
+		}
+	}
+
+	t.Run("maps every permission", func(t *testing.T) {
+		ids, err := TemplateHelpdesk.PermissionIDs(all)
+		if err != nil {
+			t.Fatalf("PermissionIDs: %v", err)
+		}
+		if len(ids) != len(TemplateHelpdesk.Permissions) {
+			t.Errorf("got %d IDs, want %d", len(ids), len(TemplateHelpdesk.Permissions))
+		}
+	})
+
+	t.Run("a permission missing from the database is an error", func(t *testing.T) {
+		if _, err := TemplateHelpdesk.PermissionIDs(all[:1]); err == nil {
+			t.Error("PermissionIDs succeeded without the permission rows")
+		}
+	})
+}
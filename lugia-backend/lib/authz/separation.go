@@ -0,0 +1,143 @@
+package authz
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"lugia/queries"
+)
+
+type ConflictSetRole struct {
+	ID   string
+	Name string
+}
+
+// ConflictSet is a tenant-defined group of mutually exclusive roles: nobody
+// may hold two or more of them at once.
+type ConflictSet struct {
+	ID    string
+	Name  string
+	Roles []ConflictSetRole
+}
+
+// Conflict is one set a user (or a composite role) breaks, with the set's
+// roles they reach.
+type Conflict struct {
+	SetID   string
+	SetName string
+	Roles   []ConflictSetRole
+}
+
+// SeparationOfDuties holds what's needed to check a tenant's conflict sets.
+type SeparationOfDuties struct {
+	Sets       []ConflictSet
+	Inclusions RoleInclusions
+}
+
+// Conflicts returns every set of which roleIDs reach two or more roles,
+// following role inclusions the same way the permission queries do.
+func (s *SeparationOfDuties) Conflicts(roleIDs []string) []Conflict {
+	reached := map[string]bool{}
+	for _, id := range roleIDs {
+		reached[id] = true
+		for _, included := range s.Inclusions.IncludedRoles(id) {
+			reached[included] = true
+		}
+	}
+
+	var conflicts []Conflict
+	for _, set := range s.Sets {
+		var held []ConflictSetRole
+		for _, role := range set.Roles {
+			if reached[role.ID] {
+				held = append(held, role)
+			}
+		}
+		if len(held) >= 2 {
+			conflicts = append(conflicts, Conflict{SetID: set.ID, SetName: set.Name, Roles: held})
+		}
+	}
+	return conflicts
+}
+
+// ConflictDetail is the user-facing message for a refused change.
+func ConflictDetail(c Conflict) string {
+	names := make([]string, len(c.Roles))
+	for i, role := range c.Roles {
+		names[i] = "「" + role.Name + "」"
+	}
+	return fmt.Sprintf("職務分掌ルール「%s」により、%sを同じユーザーに割り当てることはできません。", c.SetName, strings.Join(names, "と"))
+}
+
+// LoadSeparationOfDuties reads the tenant's conflict sets and role
+// inclusions.
+func LoadSeparationOfDuties(ctx context.Context, q *queries.Queries, tenantID pgtype.UUID) (*SeparationOfDuties, error) {
+	rows, err := q.GetRoleConflictSets(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("LoadSeparationOfDuties: failed to get conflict sets for tenant %s: %w", tenantID.String(), err)
+	}
+	s := &SeparationOfDuties{Inclusions: RoleInclusions{}}
+	index := map[string]int{}
+	for _, row := range rows {
+		setID := row.ID.String()
+		i, ok := index[setID]
+		if !ok {
+			i = len(s.Sets)
+			index[setID] = i
+			s.Sets = append(s.Sets, ConflictSet{ID: setID, Name: row.Name})
+		}
+		if row.RoleID.Valid {
+			s.Sets[i].Roles = append(s.Sets[i].Roles, ConflictSetRole{ID: row.RoleID.String(), Name: row.RoleName.String})
+		}
+	}
+
+	inclusions, err := q.GetTenantRoleInclusions(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("LoadSeparationOfDuties: failed to get role inclusions for tenant %s: %w", tenantID.String(), err)
+	}
+	for _, row := range inclusions {
+		roleID := row.RoleID.String()
+		s.Inclusions[roleID] = append(s.Inclusions[roleID], row.IncludedRoleID.String())
+	}
+	return s, nil
+}
+
+// UserRoleConflicts checks the user's current, unexpired role assignments in
+// the tenant. Call it with the transaction's queries after the assignments
+// have been written, so that two concurrent changes can't each add one half
+// of a conflicting pair: it locks the tenant row first, like
+// TenantStaysManageable.
+func UserRoleConflicts(ctx context.Context, qtx *queries.Queries, tenantID, userID pgtype.UUID) ([]Conflict, error) {
+	if err := qtx.LockTenantForRoleChange(ctx, tenantID); err != nil {
+		return nil, fmt.Errorf("UserRoleConflicts: failed to lock tenant %s: %w", tenantID.String(), err)
+	}
+
+	s, err := LoadSeparationOfDuties(ctx, qtx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if len(s.Sets) == 0 {
+		return nil, nil
+	}
+
+	assignments, err := qtx.GetUserRoleAssignments(ctx, &queries.GetUserRoleAssignmentsParams{
+		UserID:   userID,
+		TenantID: tenantID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("UserRoleConflicts: failed to get role assignments of user %s: %w", userID.String(), err)
+	}
+	now := time.Now()
+	roleIDs := make([]string, 0, len(assignments))
+	for _, a := range assignments {
+		if a.ExpiresAt.Valid && !a.ExpiresAt.Time.After(now) {
+			continue
+		}
+		roleIDs = append(roleIDs, a.RoleID.String())
+	}
+	return s.Conflicts(roleIDs), nil
+}
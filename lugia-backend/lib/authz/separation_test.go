@@ -0,0 +1,47 @@
+package authz
+
+import (
+	"testing"
+)
+
+func TestSeparationOfDutiesConflicts(t *testing.T) {
+	s := &SeparationOfDuties{
+		Sets: []ConflictSet{
+			{ID: "sod", Name: "監査と管理", Roles: []ConflictSetRole{{ID: "auditor", Name: "監査担当"}, {ID: "user_admin", Name: "ユーザー管理"}}},
+			{ID: "empty", Name: "ロール削除済み", Roles: []ConflictSetRole{{ID: "auditor", Name: "監査担当"}}},
+		},
+		Inclusions: RoleInclusions{
+			"operator": {"user_admin"},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		roleIDs []string
+		want    int
+	}{
+		{"one role of the set", []string{"auditor", "viewer"}, 0},
+		{"both roles directly", []string{"auditor", "user_admin"}, 1},
+		{"one role through an inclusion", []string{"auditor", "operator"}, 1},
+		{"a set left with one role never conflicts", []string{"auditor"}, 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := s.Conflicts(tc.roleIDs)
+			if len(got) != tc.want {
+				t.Fatalf("Conflicts(%v) = %+v, want %d conflicts", tc.roleIDs, got, tc.want)
+			}
+			if tc.want > 0 && (got[0].SetID != "sod" || len(got[0].Roles) != 2) {
+				t.Errorf("Conflicts(%v)[0] = %+v, want both roles of sod", tc.roleIDs, got[0])
+			}
+		})
+	}
+
+	t.Run("detail names the set and the roles", func(t *testing.T) {
+		got := ConflictDetail(s.Conflicts([]string{"auditor", "user_admin"})[0])
+		want := "職務分掌ルール「監査と管理」により、「監査担当」と「ユーザー管理」を同じユーザーに割り当てることはできません。"
+		if got != want {
+			t.Errorf("ConflictDetail = %q, want %q", got, want)
+		}
+	})
+}
@@ -0,0 +1,109 @@
+package authz
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	jirachiAuthz "dislyze/jirachi/authz"
+	libctx "dislyze/jirachi/ctx"
+	"lugia/queries"
+)
+
+// OutOfScopeDetail is shown when a group admin acts on someone outside their groups.
+const OutOfScopeDetail = "このユーザーを管理する権限がありません。"
+
+// RolesBeyondScopeDetail is shown when a group admin tries to grant, remove or
+// delete a holder of permissions they don't have themselves.
+const RolesBeyondScopeDetail = "自分が持っていない権限を含むロールは管理できません。"
+
+type userScopeKey struct{}
+
+// UserScope limits user management to the members of the groups a delegated
+// administrator administers. RequirePermissionOrGroupAdmin puts it in the
+// context when the actor lacks the permission tenant-wide; without one, the
+// actor's permission covers every member of the tenant.
+type UserScope struct {
+	GroupIDs []pgtype.UUID
+}
+
+func WithUserScope(ctx context.Context, scope *UserScope) context.Context {
+	return context.WithValue(ctx, userScopeKey{}, scope)
+}
+
+// GetUserScope returns nil when the actor isn't limited to a scope.
+func GetUserScope(ctx context.Context) *UserScope {
+	scope, _ := ctx.Value(userScopeKey{}).(*UserScope)
+	return scope
+}
+
+// LoadUserScope returns the groups userID administers, or nil if none. Grants
+// only count while RBAC is enabled, like custom roles.
+func LoadUserScope(ctx context.Context, q *queries.Queries, tenantID, userID pgtype.UUID) (*UserScope, error) {
+	if !libctx.GetEnterpriseFeatureEnabled(ctx, "rbac") {
+		return nil, nil
+	}
+
+	groupIDs, err := q.GetAdministeredGroupIDs(ctx, &queries.GetAdministeredGroupIDsParams{
+		UserID:   userID,
+		TenantID: tenantID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("LoadUserScope: failed to get administered groups of user %s: %w", userID.String(), err)
+	}
+	if len(groupIDs) == 0 {
+		return nil, nil
+	}
+	return &UserScope{GroupIDs: groupIDs}, nil
+}
+
+// UserInScope reports whether the actor may manage targetUserID. It is always
+// true for actors without a scope.
+func UserInScope(ctx context.Context, q *queries.Queries, tenantID, targetUserID pgtype.UUID) (bool, error) {
+	scope := GetUserScope(ctx)
+	if scope == nil {
+		return true, nil
+	}
+
+	inScope, err := q.IsUserInGroups(ctx, &queries.IsUserInGroupsParams{
+		UserID:   targetUserID,
+		TenantID: tenantID,
+		GroupIds: scope.GroupIDs,
+	})
+	if err != nil {
+		return false, fmt.Errorf("UserInScope: failed to check groups of user %s: %w", targetUserID.String(), err)
+	}
+	return inScope, nil
+}
+
+// RolesWithinScope reports whether a scoped actor holds every permission that
+// roleIDs carry, counting included roles, so that delegation can't be used to
+// hand out (or take away) more than the actor has. It is always true for
+// actors without a scope.
+func RolesWithinScope(ctx context.Context, q *queries.Queries, tenantID pgtype.UUID, roleIDs []pgtype.UUID) (bool, error) {
+	if GetUserScope(ctx) == nil || len(roleIDs) == 0 {
+		return true, nil
+	}
+
+	rows, err := q.GetRolesPermissionSet(ctx, &queries.GetRolesPermissionSetParams{
+		RoleIds:  roleIDs,
+		TenantID: tenantID,
+	})
+	if err != nil {
+		return false, fmt.Errorf("RolesWithinScope: failed to get role permissions: %w", err)
+	}
+	return len(exceedingPermissions(rows, libctx.GetPermissions(ctx))) == 0, nil
+}
+
+// exceedingPermissions returns the granted permissions that held doesn't
+// allow, as "resource.action".
+func exceedingPermissions(granted []*queries.GetRolesPermissionSetRow, held jirachiAuthz.PermissionSet) []string {
+	var exceeding []string
+	for _, p := range granted {
+		if !held.Allows(p.Resource, p.Action) {
+			exceeding = append(exceeding, p.Resource+"."+p.Action)
+		}
+	}
+	return exceeding
+}
@@ -0,0 +1,66 @@
+package authz
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	jirachiAuthz "dislyze/jirachi/authz"
+	"lugia/queries"
+)
+
+func TestExceedingPermissions(t *testing.T) {
+	held := jirachiAuthz.PermissionSet{}
+	held.Add("users", "invite")
+	held.Add("roles", "view")
+
+	tests := []struct {
+		name    string
+		granted []*queries.GetRolesPermissionSetRow
+		want    []string
+	}{
+		{"nothing granted", nil, nil},
+		{"held exactly", []*queries.GetRolesPermissionSetRow{{Resource: "users", Action: "invite"}}, nil},
+		{"view implied by another action", []*queries.GetRolesPermissionSetRow{{Resource: "users", Action: "view"}}, nil},
+		{
+			"actions beyond the held set",
+			[]*queries.GetRolesPermissionSetRow{
+				{Resource: "roles", Action: "view"},
+				{Resource: "users", Action: "delete"},
+				{Resource: "tenant", Action: "view"},
+			},
+			[]string{"users.delete", "tenant.view"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := exceedingPermissions(tc.granted, held); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("exceedingPermissions() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestUnscopedActorsAreNotLimited(t *testing.T) {
+	ctx := context.Background()
+	if GetUserScope(ctx) != nil {
+		t.Fatal("GetUserScope() on a bare context should be nil")
+	}
+
+	// Neither check may touch the database without a scope.
+	inScope, err := UserInScope(ctx, nil, pgtype.UUID{}, pgtype.UUID{})
+	if err != nil || !inScope {
+		t.Errorf("UserInScope() = %v, %v; want true, nil", inScope, err)
+	}
+	within, err := RolesWithinScope(ctx, nil, pgtype.UUID{}, []pgtype.UUID{{Valid: true}})
+	if err != nil || !within {
+		t.Errorf("RolesWithinScope() = %v, %v; want true, nil", within, err)
+	}
+
+	scope := &UserScope{GroupIDs: []pgtype.UUID{{Valid: true}}}
+	if got := GetUserScope(WithUserScope(ctx, scope)); got != scope {
+		t.Errorf("GetUserScope() = %v, want %v", got, scope)
+	}
+}
@@ -2,8 +2,11 @@ package config
 
 import (
 	"fmt"
+	"net/http"
 	"os"
 
+	"lugia/lib/iputils"
+
 	"github.com/joho/godotenv"
 )
 
@@ -24,6 +27,10 @@ func (c *LugiaAuthConfig) IsCookieSecure() bool {
 	return c.env.IsCookieSecure()
 }
 
+func (c *LugiaAuthConfig) ClientIP(r *http.Request) string {
+	return iputils.ExtractClientIP(r)
+}
+
 type Env struct {
 	AppEnv                         string
 	Port                           string
@@ -43,6 +50,27 @@ type Env struct {
 	SendgridAPIUrl                 string
 	SAMLServiceProviderPrivateKey  string
 	SAMLServiceProviderCertificate string
+
+	PurgeInterval                      string
+	PurgeBatchSize                     string
+	PurgeRetentionDeletedUsers         string
+	PurgeRetentionPasswordResetTokens  string
+	PurgeRetentionEmailChangeTokens    string
+	PurgeRetentionInvitationTokens     string
+	PurgeRetentionRefreshTokens        string
+	PurgeRetentionSSOAuthRequests      string
+	PurgeRetentionIPMonitorHits        string
+	PurgeRetentionIPBreakGlassRequests string
+	IPWhitelistExpiryWarning           string
+
+	PermissionCacheTTL string
+
+	TrustedProxies      string
+	TrustedProxyHops    string
+	TrustedRealIPHeader string
+
+	GeoIPDatabasePath   string
+	GeoIPReloadInterval string
 }
 
 func LoadEnv() (*Env, error) {
@@ -87,6 +115,44 @@ func LoadEnv() (*Env, error) {
 		return nil, fmt.Errorf("missing required environment variables: %v", missing)
 	}
 
+	// Optional settings fall back to these defaults when unset.
+	// Retentions are measured from deleted_at for users and from expires_at for everything else.
+	optional := map[string]struct {
+		value        *string
+		defaultValue string
+	}{
+		"PURGE_INTERVAL":                          {&env.PurgeInterval, "1h"},
+		"PURGE_BATCH_SIZE":                        {&env.PurgeBatchSize, "500"},
+		"PURGE_RETENTION_DELETED_USERS":           {&env.PurgeRetentionDeletedUsers, "720h"},
+		"PURGE_RETENTION_PASSWORD_RESET_TOKENS":   {&env.PurgeRetentionPasswordResetTokens, "24h"},
+		"PURGE_RETENTION_EMAIL_CHANGE_TOKENS":     {&env.PurgeRetentionEmailChangeTokens, "24h"},
+		"PURGE_RETENTION_INVITATION_TOKENS":       {&env.PurgeRetentionInvitationTokens, "168h"},
+		"PURGE_RETENTION_REFRESH_TOKENS":          {&env.PurgeRetentionRefreshTokens, "720h"},
+		"PURGE_RETENTION_SSO_AUTH_REQUESTS":       {&env.PurgeRetentionSSOAuthRequests, "1h"},
+		"PURGE_RETENTION_IP_MONITOR_HITS":         {&env.PurgeRetentionIPMonitorHits, "2160h"},
+		"PURGE_RETENTION_IP_BREAK_GLASS_REQUESTS": {&env.PurgeRetentionIPBreakGlassRequests, "24h"},
+		"IP_WHITELIST_EXPIRY_WARNING":             {&env.IPWhitelistExpiryWarning, "72h"},
+		"PERMISSION_CACHE_TTL":                    {&env.PermissionCacheTTL, "30s"},
+		"TRUSTED_PROXIES":                         {&env.TrustedProxies, ""},
+		"TRUSTED_PROXY_HOPS":                      {&env.TrustedProxyHops, "1"},
+		"TRUSTED_REAL_IP_HEADER":                  {&env.TrustedRealIPHeader, "false"},
+		"GEOIP_DATABASE_PATH":                     {&env.GeoIPDatabasePath, ""},
+		"GEOIP_RELOAD_INTERVAL":                   {&env.GeoIPReloadInterval, "1h"},
+	}
+
+	for key, setting := range optional {
+		if *setting.value = os.Getenv(key); *setting.value == "" {
+			*setting.value = setting.defaultValue
+		}
+	}
+
+	// Trusting nobody behind a load balancer makes the balancer the client of
+	// every request, which would quietly defeat the IP whitelist and the
+	// per-IP rate limits.
+	if env.TrustedProxies == "" && env.AppEnv != "local" && env.AppEnv != "e2e" {
+		return nil, fmt.Errorf("TRUSTED_PROXIES must be set when APP_ENV is %q", env.AppEnv)
+	}
+
 	return env, nil
 }
 
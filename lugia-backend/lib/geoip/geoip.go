@@ -0,0 +1,103 @@
+// Package geoip resolves client addresses to countries for the IP
+// whitelist's country rules, from a MaxMind-format database file on local
+// disk. Nothing is looked up over the network.
+package geoip
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"dislyze/jirachi/errlib"
+)
+
+// database is the loaded reader, nil until Load succeeds. Lookups read it
+// without locking so a reload never holds up a request.
+var database atomic.Pointer[loaded]
+
+type loaded struct {
+	reader  *Reader
+	path    string
+	modTime time.Time
+}
+
+// loadMu serialises Load calls so the newest file always ends up loaded.
+var loadMu sync.Mutex
+
+// Load reads the database at path and makes it the one lookups use. On error
+// the previously loaded database, if any, stays in use.
+func Load(path string) error {
+	loadMu.Lock()
+	defer loadMu.Unlock()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to stat GeoIP database: %w", err)
+	}
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read GeoIP database: %w", err)
+	}
+	reader, err := NewReader(buf)
+	if err != nil {
+		return fmt.Errorf("failed to parse GeoIP database %s: %w", path, err)
+	}
+
+	database.Store(&loaded{reader: reader, path: path, modTime: info.ModTime()})
+	return nil
+}
+
+// Loaded reports whether a database is available. Without one, every
+// address resolves to no country.
+func Loaded() bool {
+	return database.Load() != nil
+}
+
+// Country returns the ISO 3166-1 alpha-2 code of the country addr is located
+// in, or "" if no database is loaded or it doesn't know the address, as is
+// the case for private and loopback ranges.
+func Country(addr netip.Addr) string {
+	db := database.Load()
+	if db == nil {
+		return ""
+	}
+	country, err := db.reader.Country(addr)
+	if err != nil {
+		errlib.LogError(fmt.Errorf("geoip: failed to look up %s: %w", addr, err))
+		return ""
+	}
+	return country
+}
+
+// Watch reloads the database whenever the file at path has changed, checking
+// every interval until ctx is cancelled. A file that fails to load is logged
+// and the previous database kept, so replacing it with a truncated download
+// doesn't switch country rules off.
+func Watch(ctx context.Context, path string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			errlib.LogError(fmt.Errorf("geoip: failed to stat %s: %w", path, err))
+			continue
+		}
+		if db := database.Load(); db != nil && db.path == path && info.ModTime().Equal(db.modTime) {
+			continue
+		}
+		if err := Load(path); err != nil {
+			errlib.LogError(fmt.Errorf("geoip: failed to reload, keeping the previous database: %w", err))
+		}
+	}
+}
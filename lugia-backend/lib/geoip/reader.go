@@ -0,0 +1,75 @@
+package geoip
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+
+	"github.com/oschwald/maxminddb-golang/v2"
+)
+
+var errCorrupt = errors.New("corrupt MaxMind DB")
+
+// Reader looks countries up in a MaxMind DB (mmdb) file held in memory, such
+// as a GeoLite2/GeoIP2 Country or City database or a compatible equivalent.
+// Parsing is left to maxminddb-golang. Lookups never touch the network.
+type Reader struct {
+	db *maxminddb.Reader
+}
+
+// countryRecord is the part of a Country or City record the whitelist uses.
+type countryRecord struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	RegisteredCountry struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"registered_country"`
+}
+
+// NewReader parses an mmdb file's contents. buf is retained.
+func NewReader(buf []byte) (*Reader, error) {
+	db, err := maxminddb.OpenBytes(buf)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errCorrupt, err)
+	}
+
+	switch db.Metadata.RecordSize {
+	case 24, 28, 32:
+	default:
+		return nil, fmt.Errorf("%w: unsupported record size %d", errCorrupt, db.Metadata.RecordSize)
+	}
+	if db.Metadata.IPVersion != 4 && db.Metadata.IPVersion != 6 {
+		return nil, fmt.Errorf("%w: unsupported IP version %d", errCorrupt, db.Metadata.IPVersion)
+	}
+	if db.Metadata.NodeCount == 0 {
+		return nil, fmt.Errorf("%w: empty search tree", errCorrupt)
+	}
+
+	return &Reader{db: db}, nil
+}
+
+// DatabaseType is the database_type from the file's metadata, such as
+// "GeoLite2-Country".
+func (r *Reader) DatabaseType() string {
+	return r.db.Metadata.DatabaseType
+}
+
+// Country returns the ISO 3166-1 alpha-2 code of the country addr is located
+// in, or "" if the database doesn't know. The country where the network is
+// registered stands in when the location itself is unknown.
+func (r *Reader) Country(addr netip.Addr) (string, error) {
+	addr = addr.Unmap()
+	if !addr.IsValid() || (addr.Is6() && r.db.Metadata.IPVersion == 4) {
+		return "", nil
+	}
+
+	var record countryRecord
+	if err := r.db.Lookup(addr).Decode(&record); err != nil {
+		return "", fmt.Errorf("%w: %w", errCorrupt, err)
+	}
+	if record.Country.ISOCode != "" {
+		return record.Country.ISOCode, nil
+	}
+	return record.RegisteredCountry.ISOCode, nil
+}
@@ -0,0 +1,311 @@
+package geoip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// metadataStart marks the beginning of the metadata section, which sits at
+// the end of every MaxMind DB file.
+var metadataStart = []byte("\xab\xcd\xefMaxMind.com")
+
+// dataSectionSeparator is the run of zero bytes between the search tree and
+// the data section.
+const dataSectionSeparator = 16
+
+// Data section type numbers used by the encoders below.
+const (
+	typePointer = 1
+	typeString  = 2
+	typeMap     = 7
+)
+
+// testNode is a search tree node for writeTestDatabase. A record is either
+// the next node or, if data is non-zero, offset data-1 in the data section.
+type testNode struct {
+	next [2]*testNode
+	data [2]uint
+}
+
+// writeTestDatabase builds a minimal IPv6 mmdb file with 24-bit records
+// mapping each prefix to a record encoded by the given data section.
+// IPv4 prefixes are stored under ::/96 as MaxMind does.
+func writeTestDatabase(t testing.TB, prefixes map[string]uint, data []byte) []byte {
+	t.Helper()
+
+	root := &testNode{}
+	for s, offset := range prefixes {
+		prefix := netip.MustParsePrefix(s)
+		bits := prefix.Bits()
+		if prefix.Addr().Is4() {
+			bits += 96
+		}
+		b := prefix.Addr().As16()
+		if prefix.Addr().Is4() {
+			b = [16]byte{}
+			v4 := prefix.Addr().As4()
+			copy(b[12:], v4[:])
+		}
+
+		n := root
+		for i := 0; i < bits-1; i++ {
+			bit := b[i/8] >> (7 - i%8) & 1
+			if n.next[bit] == nil {
+				n.next[bit] = &testNode{}
+			}
+			n = n.next[bit]
+		}
+		last := bits - 1
+		n.data[b[last/8]>>(7-last%8)&1] = offset + 1
+	}
+
+	var nodes []*testNode
+	index := map[*testNode]uint{}
+	queue := []*testNode{root}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		index[n] = uint(len(nodes))
+		nodes = append(nodes, n)
+		for _, next := range n.next {
+			if next != nil {
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	nodeCount := uint(len(nodes))
+	var buf []byte
+	for _, n := range nodes {
+		for bit := range 2 {
+			record := nodeCount
+			switch {
+			case n.next[bit] != nil:
+				record = index[n.next[bit]]
+			case n.data[bit] != 0:
+				record = nodeCount + dataSectionSeparator + n.data[bit] - 1
+			}
+			buf = append(buf, byte(record>>16), byte(record>>8), byte(record))
+		}
+	}
+	buf = append(buf, make([]byte, dataSectionSeparator)...)
+	buf = append(buf, data...)
+
+	buf = append(buf, metadataStart...)
+	buf = append(buf, encodeMapHeader(4)...)
+	buf = append(buf, encodeString("node_count")...)
+	buf = append(buf, 6<<5|4)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(nodeCount))
+	buf = append(buf, encodeString("record_size")...)
+	buf = append(buf, 5<<5|2, 0, 24)
+	buf = append(buf, encodeString("ip_version")...)
+	buf = append(buf, 5<<5|2, 0, 6)
+	buf = append(buf, encodeString("database_type")...)
+	buf = append(buf, encodeString("Test-Country")...)
+	return buf
+}
+
+func encodeString(s string) []byte {
+	return append([]byte{byte(typeString<<5 | len(s))}, s...)
+}
+
+func encodeMapHeader(size int) []byte {
+	return []byte{byte(typeMap<<5 | size)}
+}
+
+// encodeCountry encodes {key: {"iso_code": code}}.
+func encodeCountry(key, code string) []byte {
+	b := encodeMapHeader(1)
+	b = append(b, encodeString(key)...)
+	b = append(b, encodeMapHeader(1)...)
+	b = append(b, encodeString("iso_code")...)
+	return append(b, encodeString(code)...)
+}
+
+func TestReaderCountry(t *testing.T) {
+	jp := encodeCountry("country", "JP")
+	us := encodeCountry("country", "US")
+	// Only the registered country is known for this network.
+	registered := encodeCountry("registered_country", "DE")
+	// A record whose country map is a pointer to the first record's.
+	pointer := encodeMapHeader(1)
+	pointer = append(pointer, encodeString("country")...)
+	pointer = append(pointer, typePointer<<5, byte(len(encodeMapHeader(1))+len(encodeString("country"))))
+
+	var data []byte
+	offsets := map[string]uint{}
+	for _, entry := range []struct {
+		name   string
+		record []byte
+	}{{"jp", jp}, {"us", us}, {"registered", registered}, {"pointer", pointer}} {
+		offsets[entry.name] = uint(len(data))
+		data = append(data, entry.record...)
+	}
+
+	buf := writeTestDatabase(t, map[string]uint{
+		"203.0.113.0/24":  offsets["jp"],
+		"198.51.100.0/25": offsets["us"],
+		"2001:db8::/32":   offsets["us"],
+		"192.0.2.0/24":    offsets["registered"],
+		"100.64.0.0/10":   offsets["pointer"],
+	}, data)
+
+	reader, err := NewReader(buf)
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+	if got := reader.DatabaseType(); got != "Test-Country" {
+		t.Errorf("DatabaseType() = %q, want %q", got, "Test-Country")
+	}
+
+	tests := []struct {
+		name string
+		addr string
+		want string
+	}{
+		{"ipv4 in prefix", "203.0.113.7", "JP"},
+		{"ipv4-mapped ipv6", "::ffff:203.0.113.7", "JP"},
+		{"second prefix", "198.51.100.1", "US"},
+		{"just outside the prefix", "198.51.100.128", ""},
+		{"ipv6", "2001:db8::1", "US"},
+		{"ipv6 outside", "2001:db9::1", ""},
+		{"registered country fallback", "192.0.2.55", "DE"},
+		{"pointer", "100.64.1.1", "JP"},
+		{"private range", "10.0.0.1", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := reader.Country(netip.MustParseAddr(tt.addr))
+			if err != nil {
+				t.Fatalf("Country(%s) error = %v", tt.addr, err)
+			}
+			if got != tt.want {
+				t.Errorf("Country(%s) = %q, want %q", tt.addr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewReaderRejectsCorruptFiles(t *testing.T) {
+	valid := writeTestDatabase(t, map[string]uint{"203.0.113.0/24": 0}, encodeCountry("country", "JP"))
+
+	tests := []struct {
+		name string
+		buf  []byte
+	}{
+		{"empty", nil},
+		{"no metadata", valid[:len(valid)/2]},
+		{"truncated metadata", valid[:len(valid)-4]},
+		{"metadata without a tree", valid[bytes.LastIndex(valid, metadataStart):]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewReader(tt.buf); err == nil {
+				t.Errorf("NewReader() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestReaderCorruptDataDoesNotPanic(t *testing.T) {
+	t.Run("map longer than the data section", testMapLongerThanData)
+	t.Run("pointer past the data section", testPointerPastData)
+	t.Run("search tree pointing past the data section", testTreePastData)
+}
+
+func testMapLongerThanData(t *testing.T) {
+	// A map that claims more entries than the data section holds.
+	buf := writeTestDatabase(t, map[string]uint{"203.0.113.0/24": 0}, encodeMapHeader(5))
+	reader, err := NewReader(buf)
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+	if _, err := reader.Country(netip.MustParseAddr("203.0.113.1")); err == nil {
+		t.Errorf("Country() error = nil, want an error")
+	}
+}
+
+func testPointerPastData(t *testing.T) {
+	buf := writeTestDatabase(t, map[string]uint{"203.0.113.0/24": 0}, []byte{typePointer<<5 | 7, 0xff})
+	reader, err := NewReader(buf)
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+	if _, err := reader.Country(netip.MustParseAddr("203.0.113.1")); err == nil {
+		t.Errorf("Country() error = nil, want an error")
+	}
+}
+
+func testTreePastData(t *testing.T) {
+	// The record for 203.0.113.0/24 points at offset 1000 of a one byte
+	// data section.
+	buf := writeTestDatabase(t, map[string]uint{"203.0.113.0/24": 1000}, []byte{0})
+	reader, err := NewReader(buf)
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+	if _, err := reader.Country(netip.MustParseAddr("203.0.113.1")); err == nil {
+		t.Errorf("Country() error = nil, want an error")
+	}
+}
+
+func TestLoadKeepsPreviousDatabaseOnCorruptFile(t *testing.T) {
+	t.Cleanup(func() { database.Store(nil) })
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "country.mmdb")
+	valid := writeTestDatabase(t, map[string]uint{"203.0.113.0/24": 0}, encodeCountry("country", "JP"))
+	if err := os.WriteFile(path, valid, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := Load(path); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	// A download cut off halfway through.
+	if err := os.WriteFile(path, valid[:len(valid)/2], 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := Load(path); err == nil {
+		t.Errorf("Load() error = nil, want an error")
+	}
+	if got := Country(netip.MustParseAddr("203.0.113.1")); got != "JP" {
+		t.Errorf("Country() = %q after a failed reload, want %q", got, "JP")
+	}
+}
+
+// FuzzReader checks that no file, however malformed, makes NewReader or a
+// lookup panic: the middleware looks up every request's address.
+func FuzzReader(f *testing.F) {
+	jp := encodeCountry("country", "JP")
+	valid := writeTestDatabase(f, map[string]uint{
+		"203.0.113.0/24": 0,
+		"2001:db8::/32":  uint(len(jp)),
+	}, append(jp, encodeCountry("registered_country", "US")...))
+	f.Add(valid)
+	f.Add(valid[:len(valid)/2])
+	f.Add(valid[bytes.LastIndex(valid, metadataStart):])
+	f.Add([]byte{})
+
+	addrs := []netip.Addr{
+		netip.MustParseAddr("203.0.113.1"),
+		netip.MustParseAddr("198.51.100.200"),
+		netip.MustParseAddr("2001:db8::1"),
+		netip.MustParseAddr("::ffff:10.0.0.1"),
+	}
+
+	f.Fuzz(func(t *testing.T, buf []byte) {
+		reader, err := NewReader(buf)
+		if err != nil {
+			return
+		}
+		_ = reader.DatabaseType()
+		for _, addr := range addrs {
+			_, _ = reader.Country(addr)
+		}
+	})
+}
@@ -0,0 +1,164 @@
+package iputils
+
+import (
+	"net/netip"
+	"time"
+)
+
+// Prefixes shorter than these are broad: an IPv4 range over 65,536 addresses
+// is more than any one office, and an IPv6 range shorter than /32 is more than
+// a whole ISP allocation. Either one admits networks the tenant doesn't run.
+const (
+	BroadIPv4PrefixBits = 16
+	BroadIPv6PrefixBits = 32
+)
+
+// privatePrefixes are ranges that never reach us from the internet: private,
+// carrier-grade NAT, loopback and link-local, for both families. A rule inside
+// one of them can only match through a proxy that doesn't forward the client
+// address, which is almost always a mistake.
+var privatePrefixes = []netip.Prefix{
+	netip.MustParsePrefix("10.0.0.0/8"),
+	netip.MustParsePrefix("172.16.0.0/12"),
+	netip.MustParsePrefix("192.168.0.0/16"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("127.0.0.0/8"),
+	netip.MustParsePrefix("169.254.0.0/16"),
+	netip.MustParsePrefix("fc00::/7"),
+	netip.MustParsePrefix("fe80::/10"),
+	netip.MustParsePrefix("::1/128"),
+}
+
+// IsPrivatePrefix reports whether every address of prefix is in a private,
+// carrier-grade NAT, loopback or link-local range. IPv4-mapped IPv6 prefixes
+// are judged as IPv4.
+func IsPrivatePrefix(prefix netip.Prefix) bool {
+	prefix = unmapPrefix(prefix)
+	for _, private := range privatePrefixes {
+		if covers(private, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsBroadPrefix reports whether prefix is shorter than BroadIPv4PrefixBits or
+// BroadIPv6PrefixBits for its family. A private range is never broad, since it
+// admits nobody from the internet however large it is.
+func IsBroadPrefix(prefix netip.Prefix) bool {
+	prefix = unmapPrefix(prefix)
+	if IsPrivatePrefix(prefix) {
+		return false
+	}
+	if prefix.Addr().Is4() {
+		return prefix.Bits() < BroadIPv4PrefixBits
+	}
+	return prefix.Bits() < BroadIPv6PrefixBits
+}
+
+// covers reports whether every address of inner is in outer. CIDR ranges
+// either nest or are disjoint, so this is also the only way two overlap.
+func covers(outer, inner netip.Prefix) bool {
+	return outer.Addr().Is4() == inner.Addr().Is4() &&
+		outer.Bits() <= inner.Bits() &&
+		outer.Contains(inner.Addr())
+}
+
+// RuleFindingKind is what AnalyzeRules found wrong with a rule.
+type RuleFindingKind string
+
+const (
+	// RuleRedundant means another rule covers every address of this one for
+	// at least as long, so removing it changes nothing.
+	RuleRedundant RuleFindingKind = "redundant"
+	// RuleOverlapping means a wider rule covers this one but expires first,
+	// so this one only matters after that.
+	RuleOverlapping RuleFindingKind = "overlapping"
+	RuleBroad       RuleFindingKind = "broad"
+	RulePrivate     RuleFindingKind = "private"
+)
+
+// AnalyzedRule is one whitelist rule to analyze. Rules are only compared with
+// rules of the same Group. ExpiresAt is nil for a permanent rule.
+type AnalyzedRule struct {
+	ID        string
+	CIDR      string
+	Group     string
+	ExpiresAt *time.Time
+}
+
+// RuleFinding reports one problem with the rule RuleID. RelatedRuleID is the
+// covering rule for RuleRedundant and RuleOverlapping, and empty otherwise.
+type RuleFinding struct {
+	Kind          RuleFindingKind
+	RuleID        string
+	RelatedRuleID string
+}
+
+// AnalyzeRules reports redundant, overlapping, broad and private rules, in the
+// order of rules. Each rule is reported as redundant or overlapping at most
+// once, against the first rule covering it. Of two identical rules lasting
+// equally long, the later one is redundant, so rules should be given in
+// creation order. IPv4-mapped IPv6 rules are compared as IPv4, as the matcher
+// applies them, and rules that don't parse are skipped.
+func AnalyzeRules(rules []AnalyzedRule) []RuleFinding {
+	prefixes := make([]netip.Prefix, len(rules))
+	valid := make([]bool, len(rules))
+	for i, rule := range rules {
+		prefix, err := netip.ParsePrefix(rule.CIDR)
+		if err != nil {
+			continue
+		}
+		prefixes[i] = unmapPrefix(prefix)
+		valid[i] = true
+	}
+
+	var findings []RuleFinding
+	for i, rule := range rules {
+		if !valid[i] {
+			continue
+		}
+
+		var overlap *RuleFinding
+		for j, other := range rules {
+			if j == i || !valid[j] || other.Group != rule.Group || !covers(prefixes[j], prefixes[i]) {
+				continue
+			}
+			if prefixes[j] == prefixes[i] {
+				// The same range twice: the one that lasts longer, or else
+				// the earlier one, is kept.
+				if outlasts(other, rule) && (!outlasts(rule, other) || j < i) {
+					overlap = &RuleFinding{Kind: RuleRedundant, RuleID: rule.ID, RelatedRuleID: other.ID}
+					break
+				}
+				continue
+			}
+			if outlasts(other, rule) {
+				overlap = &RuleFinding{Kind: RuleRedundant, RuleID: rule.ID, RelatedRuleID: other.ID}
+				break
+			}
+			if overlap == nil {
+				overlap = &RuleFinding{Kind: RuleOverlapping, RuleID: rule.ID, RelatedRuleID: other.ID}
+			}
+		}
+		if overlap != nil {
+			findings = append(findings, *overlap)
+		}
+
+		if IsBroadPrefix(prefixes[i]) {
+			findings = append(findings, RuleFinding{Kind: RuleBroad, RuleID: rule.ID})
+		}
+		if IsPrivatePrefix(prefixes[i]) {
+			findings = append(findings, RuleFinding{Kind: RulePrivate, RuleID: rule.ID})
+		}
+	}
+	return findings
+}
+
+// outlasts reports whether a is in force at least as long as b.
+func outlasts(a, b AnalyzedRule) bool {
+	if a.ExpiresAt == nil {
+		return true
+	}
+	return b.ExpiresAt != nil && !a.ExpiresAt.Before(*b.ExpiresAt)
+}
@@ -0,0 +1,186 @@
+package iputils
+
+import (
+	"net/netip"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestIsBroadPrefix(t *testing.T) {
+	tests := []struct {
+		cidr     string
+		expected bool
+	}{
+		{"0.0.0.0/0", true},
+		{"203.0.0.0/15", true},
+		{"203.0.0.0/16", false},
+		{"203.0.113.7/32", false},
+		{"::/0", true},
+		{"2001:db8::/31", true},
+		{"2001:db8::/32", false},
+		{"2001:db8:1::/48", false},
+		// Judged as the IPv4 range it maps to.
+		{"::ffff:0.0.0.0/96", true},
+		{"::ffff:203.0.113.0/120", false},
+		// Private ranges admit nobody from the internet.
+		{"10.0.0.0/8", false},
+		{"fc00::/7", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cidr, func(t *testing.T) {
+			if got := IsBroadPrefix(netip.MustParsePrefix(tt.cidr)); got != tt.expected {
+				t.Errorf("IsBroadPrefix(%s) = %v, want %v", tt.cidr, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIsPrivatePrefix(t *testing.T) {
+	tests := []struct {
+		cidr     string
+		expected bool
+	}{
+		{"10.1.2.0/24", true},
+		{"172.16.0.0/12", true},
+		{"172.0.0.0/8", false},
+		{"192.168.1.100/32", true},
+		{"100.64.0.0/10", true},
+		{"127.0.0.1/32", true},
+		{"169.254.1.0/24", true},
+		{"203.0.113.0/24", false},
+		{"fd12:3456::/32", true},
+		{"fe80::1/128", true},
+		{"::1/128", true},
+		{"2001:db8::/32", false},
+		{"::ffff:192.168.1.0/120", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cidr, func(t *testing.T) {
+			if got := IsPrivatePrefix(netip.MustParsePrefix(tt.cidr)); got != tt.expected {
+				t.Errorf("IsPrivatePrefix(%s) = %v, want %v", tt.cidr, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAnalyzeRules(t *testing.T) {
+	soon := time.Now().Add(24 * time.Hour)
+	later := time.Now().Add(48 * time.Hour)
+
+	tests := []struct {
+		name     string
+		rules    []AnalyzedRule
+		expected []RuleFinding
+	}{
+		{
+			name: "disjoint rules",
+			rules: []AnalyzedRule{
+				{ID: "a", CIDR: "203.0.113.0/24"},
+				{ID: "b", CIDR: "198.51.100.0/24"},
+				{ID: "c", CIDR: "2001:db8::/48"},
+			},
+		},
+		{
+			name: "a narrower rule inside a permanent one is redundant",
+			rules: []AnalyzedRule{
+				{ID: "a", CIDR: "203.0.113.7/32"},
+				{ID: "b", CIDR: "203.0.113.0/24"},
+			},
+			expected: []RuleFinding{{Kind: RuleRedundant, RuleID: "a", RelatedRuleID: "b"}},
+		},
+		{
+			name: "a permanent rule inside a temporary one only overlaps",
+			rules: []AnalyzedRule{
+				{ID: "a", CIDR: "203.0.113.7/32"},
+				{ID: "b", CIDR: "203.0.113.0/24", ExpiresAt: &soon},
+			},
+			expected: []RuleFinding{{Kind: RuleOverlapping, RuleID: "a", RelatedRuleID: "b"}},
+		},
+		{
+			name: "a temporary rule inside a longer one is redundant",
+			rules: []AnalyzedRule{
+				{ID: "a", CIDR: "203.0.113.7/32", ExpiresAt: &soon},
+				{ID: "b", CIDR: "203.0.113.0/24", ExpiresAt: &later},
+			},
+			expected: []RuleFinding{{Kind: RuleRedundant, RuleID: "a", RelatedRuleID: "b"}},
+		},
+		{
+			name: "of two identical rules the later one is redundant",
+			rules: []AnalyzedRule{
+				{ID: "a", CIDR: "203.0.113.0/24"},
+				{ID: "b", CIDR: "203.0.113.0/24"},
+			},
+			expected: []RuleFinding{{Kind: RuleRedundant, RuleID: "b", RelatedRuleID: "a"}},
+		},
+		{
+			name: "of two identical rules the one expiring first is redundant",
+			rules: []AnalyzedRule{
+				{ID: "a", CIDR: "203.0.113.0/24", ExpiresAt: &soon},
+				{ID: "b", CIDR: "203.0.113.0/24"},
+			},
+			expected: []RuleFinding{{Kind: RuleRedundant, RuleID: "a", RelatedRuleID: "b"}},
+		},
+		{
+			name: "an IPv4-mapped rule duplicates its IPv4 form",
+			rules: []AnalyzedRule{
+				{ID: "a", CIDR: "203.0.113.0/24"},
+				{ID: "b", CIDR: "::ffff:203.0.113.0/120"},
+			},
+			expected: []RuleFinding{{Kind: RuleRedundant, RuleID: "b", RelatedRuleID: "a"}},
+		},
+		{
+			name: "IPv6 rules nest like IPv4 ones",
+			rules: []AnalyzedRule{
+				{ID: "a", CIDR: "2001:db8::/32"},
+				{ID: "b", CIDR: "2001:db8:1::/48"},
+			},
+			expected: []RuleFinding{{Kind: RuleRedundant, RuleID: "b", RelatedRuleID: "a"}},
+		},
+		{
+			name: "IPv6 catch-all doesn't cover IPv4",
+			rules: []AnalyzedRule{
+				{ID: "a", CIDR: "::/0"},
+				{ID: "b", CIDR: "203.0.113.0/24"},
+			},
+			expected: []RuleFinding{{Kind: RuleBroad, RuleID: "a"}},
+		},
+		{
+			name: "rules of different groups aren't compared",
+			rules: []AnalyzedRule{
+				{ID: "a", CIDR: "203.0.113.0/24"},
+				{ID: "b", CIDR: "203.0.113.7/32", Group: "scope"},
+			},
+		},
+		{
+			name: "broad and private rules",
+			rules: []AnalyzedRule{
+				{ID: "a", CIDR: "0.0.0.0/0"},
+				{ID: "b", CIDR: "192.168.1.0/24"},
+			},
+			expected: []RuleFinding{
+				{Kind: RuleBroad, RuleID: "a"},
+				{Kind: RuleRedundant, RuleID: "b", RelatedRuleID: "a"},
+				{Kind: RulePrivate, RuleID: "b"},
+			},
+		},
+		{
+			name: "unparsable rules are skipped",
+			rules: []AnalyzedRule{
+				{ID: "a", CIDR: "not-a-cidr"},
+				{ID: "b", CIDR: "203.0.113.0/24"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := AnalyzeRules(tt.rules)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("AnalyzeRules() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"net/netip"
 	"strings"
 )
 
@@ -62,33 +63,55 @@ func IsIPInCIDRList(ipStr string, cidrs []string) (bool, error) {
 	return false, nil
 }
 
-// ExtractClientIP extracts the real client IP from HTTP request
-// Handles X-Real-IP and X-Forwarded-For headers for proxy scenarios
-// Designed for GCP Load Balancer which appends real client IP to X-Forwarded-For
+// ExtractClientIP extracts the real client IP from HTTP request.
+// Forwarding headers are only read when the immediate peer is a trusted proxy
+// (see TrustedProxies), and X-Real-IP only when it is enabled explicitly;
+// otherwise, or when the header holds no valid address, the peer address is
+// the client. Behind a single GCP Load
+// Balancer, which appends the real client IP, the client is the rightmost
+// X-Forwarded-For entry.
 func ExtractClientIP(r *http.Request) string {
-	// Check X-Real-IP header first (set by some GCP configurations and Nginx)
-	if xri := r.Header.Get("X-Real-IP"); xri != "" {
-		return strings.TrimSpace(xri)
+	// RemoteAddr might not have port (e.g., in tests)
+	peer := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		peer = host
 	}
 
-	// Check X-Forwarded-For header (most common proxy header)
+	peerAddr, err := netip.ParseAddr(peer)
+	if err != nil {
+		return peer
+	}
+
+	proxies := currentTrustedProxies()
+	if !isTrustedProxy(proxies, peerAddr) {
+		return peer
+	}
+
+	// X-Real-IP is set by some Nginx configurations, which overwrite it;
+	// proxies that don't would pass on whatever the client sent.
+	if proxies.RealIPHeader {
+		if xri := strings.TrimSpace(r.Header.Get("X-Real-IP")); xri != "" {
+			if _, err := netip.ParseAddr(xri); err == nil {
+				return xri
+			}
+		}
+	}
+
+	// X-Forwarded-For can contain multiple IPs: "client, proxy1, proxy2".
+	// Every trusted proxy appends the address it received the request from,
+	// so anything left of the last Hops entries may have been sent by the
+	// client itself.
 	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
-		// X-Forwarded-For can contain multiple IPs: "client, proxy1, proxy2"
-		// For GCP Load Balancer, take the rightmost IP (real client IP added by GCP)
-		// To prevent spoofing since GCP appends the actual source IP
 		ips := strings.Split(xff, ",")
-		clientIP := strings.TrimSpace(ips[len(ips)-1])
-		if clientIP != "" {
+		index := len(ips) - proxies.Hops
+		if index < 0 {
+			index = 0
+		}
+		clientIP := strings.TrimSpace(ips[index])
+		if _, err := netip.ParseAddr(clientIP); err == nil {
 			return clientIP
 		}
 	}
 
-	// Fall back to RemoteAddr
-	ip, _, err := net.SplitHostPort(r.RemoteAddr)
-	if err != nil {
-		// RemoteAddr might not have port (e.g., in tests)
-		return r.RemoteAddr
-	}
-
-	return ip
+	return peer
 }
@@ -1,6 +1,7 @@
 package iputils
 
 import (
+	"context"
 	"net/http"
 	"testing"
 )
@@ -115,6 +116,14 @@ func TestIsIPInCIDRList(t *testing.T) {
 }
 
 func TestExtractClientIP(t *testing.T) {
+	proxies, err := ParseTrustedProxies("10.0.0.0/8", "1", "true")
+	if err != nil {
+		t.Fatalf("ParseTrustedProxies() error = %v", err)
+	}
+	defaults := currentTrustedProxies()
+	SetTrustedProxies(proxies)
+	t.Cleanup(func() { SetTrustedProxies(defaults) })
+
 	tests := []struct {
 		name       string
 		remoteAddr string
@@ -190,3 +199,271 @@ func TestExtractClientIP(t *testing.T) {
 		})
 	}
 }
+
+func TestExtractClientIPWithTrustedProxies(t *testing.T) {
+	proxies, err := ParseTrustedProxies("10.0.0.0/8, 127.0.0.1", "2", "true")
+	if err != nil {
+		t.Fatalf("ParseTrustedProxies() error = %v", err)
+	}
+	defaults := currentTrustedProxies()
+	SetTrustedProxies(proxies)
+	t.Cleanup(func() { SetTrustedProxies(defaults) })
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string]string
+		expectedIP string
+	}{
+		{
+			name:       "Untrusted peer - X-Real-IP ignored",
+			remoteAddr: "203.0.113.5:12345",
+			headers:    map[string]string{"X-Real-IP": "192.168.1.100"},
+			expectedIP: "203.0.113.5",
+		},
+		{
+			name:       "Untrusted peer - X-Forwarded-For ignored",
+			remoteAddr: "203.0.113.5:12345",
+			headers:    map[string]string{"X-Forwarded-For": "192.168.1.100, 10.0.0.2"},
+			expectedIP: "203.0.113.5",
+		},
+		{
+			name:       "Trusted peer - X-Real-IP used",
+			remoteAddr: "127.0.0.1:12345",
+			headers:    map[string]string{"X-Real-IP": "192.168.1.100"},
+			expectedIP: "192.168.1.100",
+		},
+		{
+			name:       "Trusted peer - two hops skips the spoofed entry",
+			remoteAddr: "10.0.0.1:12345",
+			headers:    map[string]string{"X-Forwarded-For": "1.2.3.4, 192.168.1.100, 10.0.0.2"},
+			expectedIP: "192.168.1.100",
+		},
+		{
+			name:       "Trusted peer - fewer entries than hops takes the leftmost",
+			remoteAddr: "10.0.0.1:12345",
+			headers:    map[string]string{"X-Forwarded-For": "192.168.1.100"},
+			expectedIP: "192.168.1.100",
+		},
+		{
+			name:       "Trusted peer - invalid header falls back to peer",
+			remoteAddr: "10.0.0.1:12345",
+			headers:    map[string]string{"X-Real-IP": "not-an-ip"},
+			expectedIP: "10.0.0.1",
+		},
+		{
+			name:       "IPv4-mapped IPv6 peer matches IPv4 rule",
+			remoteAddr: "[::ffff:127.0.0.1]:12345",
+			headers:    map[string]string{"X-Real-IP": "192.168.1.100"},
+			expectedIP: "192.168.1.100",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+
+			result := ExtractClientIP(req)
+			if result != tt.expectedIP {
+				t.Errorf("ExtractClientIP() = %v, want %v", result, tt.expectedIP)
+			}
+		})
+	}
+}
+
+func TestExtractClientIPDefaultsIgnoreForwardingHeaders(t *testing.T) {
+	tests := []struct {
+		name       string
+		proxies    TrustedProxies
+		remoteAddr string
+		headers    map[string]string
+		expectedIP string
+	}{
+		{
+			name:       "Default - spoofed X-Real-IP from an untrusted peer is ignored",
+			proxies:    TrustedProxies{Hops: 1},
+			remoteAddr: "203.0.113.5:12345",
+			headers:    map[string]string{"X-Real-IP": "192.168.1.100"},
+			expectedIP: "203.0.113.5",
+		},
+		{
+			name:       "Default - spoofed X-Forwarded-For from an untrusted peer is ignored",
+			proxies:    TrustedProxies{Hops: 1},
+			remoteAddr: "203.0.113.5:12345",
+			headers:    map[string]string{"X-Forwarded-For": "192.168.1.100"},
+			expectedIP: "203.0.113.5",
+		},
+		{
+			name:       "Trusted peer without the X-Real-IP opt-in uses X-Forwarded-For",
+			proxies:    mustParseTrustedProxies(t, "10.0.0.0/8", "1", ""),
+			remoteAddr: "10.0.0.1:12345",
+			headers: map[string]string{
+				"X-Forwarded-For": "192.168.1.100",
+				"X-Real-IP":       "192.168.1.200",
+			},
+			expectedIP: "192.168.1.100",
+		},
+		{
+			name:       "Trusted peer without the X-Real-IP opt-in ignores X-Real-IP alone",
+			proxies:    mustParseTrustedProxies(t, "10.0.0.0/8", "1", "false"),
+			remoteAddr: "10.0.0.1:12345",
+			headers:    map[string]string{"X-Real-IP": "192.168.1.200"},
+			expectedIP: "10.0.0.1",
+		},
+	}
+
+	defaults := currentTrustedProxies()
+	if len(defaults.Prefixes) != 0 || len(defaults.Hostnames) != 0 || defaults.RealIPHeader {
+		t.Fatalf("default trusted proxies = %+v, want nobody trusted", defaults)
+	}
+	t.Cleanup(func() { SetTrustedProxies(defaults) })
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetTrustedProxies(tt.proxies)
+
+			req, _ := http.NewRequest("GET", "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+
+			result := ExtractClientIP(req)
+			if result != tt.expectedIP {
+				t.Errorf("ExtractClientIP() = %v, want %v", result, tt.expectedIP)
+			}
+		})
+	}
+}
+
+func TestExtractClientIPTrustsHostnamesOnceResolved(t *testing.T) {
+	defaults := currentTrustedProxies()
+	SetTrustedProxies(mustParseTrustedProxies(t, "localhost", "1", ""))
+	t.Cleanup(func() { SetTrustedProxies(defaults) })
+
+	req, _ := http.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "127.0.0.1:12345"
+	req.Header.Set("X-Forwarded-For", "192.168.1.100")
+
+	// Requests never look hostnames up themselves.
+	if result := ExtractClientIP(req); result != "127.0.0.1" {
+		t.Fatalf("ExtractClientIP() before resolving = %v, want 127.0.0.1", result)
+	}
+
+	ResolveTrustedProxyHostnames(context.Background())
+	if !trustedProxyHostnamesResolved() {
+		t.Fatal("trustedProxyHostnamesResolved() = false after resolving localhost")
+	}
+	if result := ExtractClientIP(req); result != "192.168.1.100" {
+		t.Errorf("ExtractClientIP() after resolving = %v, want 192.168.1.100", result)
+	}
+}
+
+func mustParseTrustedProxies(t *testing.T, list, hops, realIPHeader string) TrustedProxies {
+	t.Helper()
+	proxies, err := ParseTrustedProxies(list, hops, realIPHeader)
+	if err != nil {
+		t.Fatalf("ParseTrustedProxies() error = %v", err)
+	}
+	return proxies
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	tests := []struct {
+		name          string
+		list          string
+		hops          string
+		realIPHeader  string
+		wantPrefixes  int
+		wantHostnames []string
+		wantRealIP    bool
+		wantErr       bool
+	}{
+		{
+			name:         "IPs and CIDRs",
+			list:         "10.0.0.0/8,127.0.0.1,::1",
+			hops:         "1",
+			wantPrefixes: 3,
+		},
+		{
+			name:          "Hostnames are kept for lookup",
+			list:          "lugia-frontend, 127.0.0.1, localhost",
+			hops:          "1",
+			wantPrefixes:  1,
+			wantHostnames: []string{"lugia-frontend", "localhost"},
+		},
+		{
+			name: "Empty list trusts nobody",
+			list: "",
+			hops: "1",
+		},
+		{
+			name:         "X-Real-IP is read only when enabled",
+			list:         "127.0.0.1",
+			hops:         "1",
+			realIPHeader: "true",
+			wantPrefixes: 1,
+			wantRealIP:   true,
+		},
+		{
+			name:         "Invalid X-Real-IP setting",
+			list:         "127.0.0.1",
+			hops:         "1",
+			realIPHeader: "sometimes",
+			wantErr:      true,
+		},
+		{
+			name:    "Invalid CIDR",
+			list:    "10.0.0.0/40",
+			hops:    "1",
+			wantErr: true,
+		},
+		{
+			name:    "Zero hops",
+			list:    "10.0.0.0/8",
+			hops:    "0",
+			wantErr: true,
+		},
+		{
+			name:    "Non-numeric hops",
+			list:    "10.0.0.0/8",
+			hops:    "two",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proxies, err := ParseTrustedProxies(tt.list, tt.hops, tt.realIPHeader)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseTrustedProxies() expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseTrustedProxies() unexpected error = %v", err)
+			}
+			if len(proxies.Prefixes) != tt.wantPrefixes {
+				t.Errorf("ParseTrustedProxies() prefixes = %v, want %d", proxies.Prefixes, tt.wantPrefixes)
+			}
+			if proxies.RealIPHeader != tt.wantRealIP {
+				t.Errorf("ParseTrustedProxies() RealIPHeader = %v, want %v", proxies.RealIPHeader, tt.wantRealIP)
+			}
+			if len(proxies.Hostnames) != len(tt.wantHostnames) {
+				t.Fatalf("ParseTrustedProxies() hostnames = %v, want %v", proxies.Hostnames, tt.wantHostnames)
+			}
+			for i, hostname := range tt.wantHostnames {
+				if proxies.Hostnames[i] != hostname {
+					t.Errorf("ParseTrustedProxies() hostnames = %v, want %v", proxies.Hostnames, tt.wantHostnames)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package iputils
+
+import (
+	"fmt"
+	"net/netip"
+)
+
+// CIDRMatcher reports whether an address falls in any of a fixed set of
+// prefixes. It is a binary trie over address bits built once from the
+// whitelist, so a lookup walks at most one node per bit however many rules
+// there are, where IsIPInCIDRList parses and tests every CIDR on each call.
+// Matching follows IsIPInCIDRList: IPv4-mapped IPv6 clients and prefixes are
+// treated as IPv4, and CIDRs that don't parse are skipped.
+type CIDRMatcher struct {
+	v4   *trieNode
+	v6   *trieNode
+	size int
+}
+
+type trieNode struct {
+	children [2]*trieNode
+	// terminal marks the end of a prefix: every address below this node is
+	// covered, so the walk can stop here.
+	terminal bool
+}
+
+// NewCIDRMatcher compiles cidrs into a matcher.
+func NewCIDRMatcher(cidrs []string) *CIDRMatcher {
+	m := &CIDRMatcher{v4: &trieNode{}, v6: &trieNode{}}
+	for _, cidr := range cidrs {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			continue
+		}
+		m.insert(unmapPrefix(prefix))
+	}
+	return m
+}
+
+// unmapPrefix returns an IPv4-mapped IPv6 prefix as the IPv4 prefix it
+// covers, masked. net.IPNet treats these as IPv4 ranges, so IsIPInCIDRList
+// matches them against IPv4 clients only.
+func unmapPrefix(prefix netip.Prefix) netip.Prefix {
+	if prefix.Addr().Is4In6() {
+		prefix = netip.PrefixFrom(prefix.Addr().Unmap(), max(prefix.Bits()-96, 0))
+	}
+	return prefix.Masked()
+}
+
+func (m *CIDRMatcher) insert(prefix netip.Prefix) {
+	node := m.v6
+	if prefix.Addr().Is4() {
+		node = m.v4
+	}
+
+	addr := prefix.Addr().AsSlice()
+	for i := 0; i < prefix.Bits(); i++ {
+		if node.terminal {
+			// A shorter prefix already covers this one.
+			return
+		}
+		bit := addr[i/8] >> (7 - i%8) & 1
+		if node.children[bit] == nil {
+			node.children[bit] = &trieNode{}
+		}
+		node = node.children[bit]
+	}
+	if node.terminal {
+		// The same prefix was inserted before.
+		return
+	}
+	node.terminal = true
+	// Anything more specific below is now redundant.
+	m.size -= node.children[0].terminals() + node.children[1].terminals()
+	node.children = [2]*trieNode{}
+	m.size++
+}
+
+// terminals counts the prefixes ending at or below n.
+func (n *trieNode) terminals() int {
+	if n == nil {
+		return 0
+	}
+	if n.terminal {
+		return 1
+	}
+	return n.children[0].terminals() + n.children[1].terminals()
+}
+
+// Len returns the number of prefixes the matcher holds, leaving out
+// duplicates and prefixes another one covers. It is zero when the whitelist is
+// empty or none of its CIDRs parsed.
+func (m *CIDRMatcher) Len() int {
+	return m.size
+}
+
+// Contains reports whether addr is covered by any prefix.
+func (m *CIDRMatcher) Contains(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	node := m.v6
+	if addr.Is4() {
+		node = m.v4
+	}
+
+	bytes := addr.AsSlice()
+	for i := 0; i < addr.BitLen(); i++ {
+		if node.terminal {
+			return true
+		}
+		node = node.children[bytes[i/8]>>(7-i%8)&1]
+		if node == nil {
+			return false
+		}
+	}
+	return node.terminal
+}
+
+// ContainsIP is Contains for an address string, such as the result of
+// ExtractClientIP.
+func (m *CIDRMatcher) ContainsIP(ipStr string) (bool, error) {
+	addr, err := netip.ParseAddr(ipStr)
+	if err != nil {
+		return false, fmt.Errorf("invalid client IP address: %s", ipStr)
+	}
+	return m.Contains(addr), nil
+}
@@ -0,0 +1,146 @@
+package iputils
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestCIDRMatcherMatchesIsIPInCIDRList(t *testing.T) {
+	cidrs := []string{
+		"192.168.1.0/24",
+		"192.168.1.128/25",
+		"10.0.0.1/32",
+		"172.16.0.0/12",
+		"2001:db8::/32",
+		"2001:db8:1::/48",
+		"::1/128",
+		"::ffff:203.0.113.0/120",
+		"not-a-cidr",
+	}
+	ips := []string{
+		"192.168.1.1",
+		"192.168.1.200",
+		"192.168.2.1",
+		"10.0.0.1",
+		"10.0.0.2",
+		"172.31.255.255",
+		"172.32.0.0",
+		"2001:db8::1",
+		"2001:db8:1::1",
+		"2001:db9::1",
+		"::1",
+		"::2",
+		"::ffff:192.168.1.1",
+		"203.0.113.5",
+		"::ffff:203.0.113.5",
+	}
+
+	matcher := NewCIDRMatcher(cidrs)
+	for _, ip := range ips {
+		t.Run(ip, func(t *testing.T) {
+			want, err := IsIPInCIDRList(ip, cidrs)
+			if err != nil {
+				t.Fatalf("IsIPInCIDRList() unexpected error: %v", err)
+			}
+			got, err := matcher.ContainsIP(ip)
+			if err != nil {
+				t.Fatalf("ContainsIP() unexpected error: %v", err)
+			}
+			if got != want {
+				t.Errorf("ContainsIP(%s) = %v, IsIPInCIDRList = %v", ip, got, want)
+			}
+		})
+	}
+}
+
+func TestCIDRMatcher(t *testing.T) {
+	tests := []struct {
+		name    string
+		cidrs   []string
+		ip      string
+		want    bool
+		wantLen int
+	}{
+		{"Empty matcher", nil, "192.168.1.1", false, 0},
+		{"Match all IPv4", []string{"0.0.0.0/0"}, "8.8.8.8", true, 1},
+		{"IPv4 rule does not match IPv6", []string{"0.0.0.0/0"}, "2001:db8::1", false, 1},
+		{"Unmasked CIDR is masked", []string{"192.168.1.77/24"}, "192.168.1.1", true, 1},
+		{"Covered prefix is folded", []string{"10.0.0.0/8", "10.1.0.0/16"}, "10.2.0.1", true, 1},
+		{"Covering prefix added later", []string{"10.1.0.0/16", "10.2.0.0/16", "10.0.0.0/8"}, "10.3.0.1", true, 1},
+		{"Duplicate prefix is counted once", []string{"10.0.0.0/8", "10.0.0.0/8"}, "10.0.0.1", true, 1},
+		{"Same prefix written differently is counted once", []string{"10.0.0.1/8", "::ffff:10.0.0.0/104"}, "10.0.0.1", true, 1},
+		{"Disjoint prefixes are all counted", []string{"10.0.0.0/8", "192.168.0.0/16", "2001:db8::/32"}, "192.168.1.1", true, 3},
+		{"Invalid CIDRs are skipped", []string{"nope", "10.0.0.0/8"}, "10.0.0.1", true, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matcher := NewCIDRMatcher(tt.cidrs)
+			got, err := matcher.ContainsIP(tt.ip)
+			if err != nil {
+				t.Fatalf("ContainsIP() unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ContainsIP(%s) = %v, want %v", tt.ip, got, tt.want)
+			}
+			if matcher.Len() != tt.wantLen {
+				t.Errorf("Len() = %d, want %d", matcher.Len(), tt.wantLen)
+			}
+		})
+	}
+
+	if _, err := NewCIDRMatcher(nil).ContainsIP("not-an-ip"); err == nil {
+		t.Error("ContainsIP() expected error for invalid client IP")
+	}
+}
+
+// benchmarkRules returns n disjoint rules, half IPv4 /24s and half IPv6 /48s,
+// the shape of a tenant that whitelists many offices and VPN ranges.
+func benchmarkRules(n int) []string {
+	cidrs := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		if i%2 == 0 {
+			cidrs = append(cidrs, fmt.Sprintf("10.%d.%d.0/24", i/256, i%256))
+		} else {
+			cidrs = append(cidrs, fmt.Sprintf("2001:db8:%x::/48", i))
+		}
+	}
+	return cidrs
+}
+
+// The client IP matches no rule, the worst case for the linear scan and the
+// common case for a blocked request.
+const benchmarkClientIP = "203.0.113.7"
+
+func BenchmarkIsIPInCIDRList(b *testing.B) {
+	for _, n := range []int{10, 100, 500} {
+		cidrs := benchmarkRules(n)
+		b.Run(fmt.Sprintf("rules=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := IsIPInCIDRList(benchmarkClientIP, cidrs); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkCIDRMatcher(b *testing.B) {
+	for _, n := range []int{10, 100, 500} {
+		matcher := NewCIDRMatcher(benchmarkRules(n))
+		b.Run(fmt.Sprintf("rules=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := matcher.ContainsIP(benchmarkClientIP); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkNewCIDRMatcher(b *testing.B) {
+	cidrs := benchmarkRules(500)
+	for i := 0; i < b.N; i++ {
+		NewCIDRMatcher(cidrs)
+	}
+}
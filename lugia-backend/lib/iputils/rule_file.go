@@ -0,0 +1,155 @@
+package iputils
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// RuleFileFormat is the file format of an IP whitelist import or export.
+type RuleFileFormat string
+
+const (
+	RuleFileCSV  RuleFileFormat = "csv"
+	RuleFileJSON RuleFileFormat = "json"
+)
+
+// ruleFileColumns is the CSV header, in column order. Only ip_address is
+// required; label and expires_at may be omitted or left empty.
+var ruleFileColumns = []string{"ip_address", "label", "expires_at"}
+
+// RuleRecord is one whitelist rule as it appears in an import or export file.
+// Fields are carried as written; validating them is up to the caller. Row is
+// the 1-based position of the record in the file (CSV line or JSON array
+// index), for reporting.
+type RuleRecord struct {
+	Row       int     `json:"-"`
+	IPAddress string  `json:"ip_address"`
+	Label     *string `json:"label"`
+	ExpiresAt *string `json:"expires_at"`
+}
+
+// ParseRuleFile reads rules from a CSV file with optional header, or a JSON
+// array of objects. An error means the file as a whole is unreadable; a
+// malformed value in an otherwise readable row is returned as written.
+func ParseRuleFile(format RuleFileFormat, content string) ([]RuleRecord, error) {
+	switch format {
+	case RuleFileCSV:
+		return parseRulesCSV(content)
+	case RuleFileJSON:
+		return parseRulesJSON(content)
+	default:
+		return nil, fmt.Errorf("unsupported rule file format %q", format)
+	}
+}
+
+func parseRulesCSV(content string) ([]RuleRecord, error) {
+	reader := csv.NewReader(strings.NewReader(strings.TrimPrefix(content, "\ufeff")))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var records []RuleRecord
+	for {
+		fields, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		if len(records) == 0 && strings.EqualFold(strings.TrimSpace(fields[0]), ruleFileColumns[0]) {
+			continue
+		}
+		if len(fields) > len(ruleFileColumns) {
+			return nil, fmt.Errorf("invalid CSV: line %d has %d columns, expected at most %d", line, len(fields), len(ruleFileColumns))
+		}
+
+		record := RuleRecord{Row: line, IPAddress: strings.TrimSpace(fields[0])}
+		if len(fields) > 1 && strings.TrimSpace(fields[1]) != "" {
+			label := unescapeCSVFormula(strings.TrimSpace(fields[1]))
+			record.Label = &label
+		}
+		if len(fields) > 2 && strings.TrimSpace(fields[2]) != "" {
+			expiresAt := strings.TrimSpace(fields[2])
+			record.ExpiresAt = &expiresAt
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+func parseRulesJSON(content string) ([]RuleRecord, error) {
+	var records []RuleRecord
+	decoder := json.NewDecoder(strings.NewReader(content))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&records); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	for i := range records {
+		records[i].Row = i + 1
+		records[i].IPAddress = strings.TrimSpace(records[i].IPAddress)
+	}
+	return records, nil
+}
+
+// EncodeRuleFile writes rules in a form ParseRuleFile reads back unchanged.
+func EncodeRuleFile(format RuleFileFormat, records []RuleRecord) ([]byte, error) {
+	switch format {
+	case RuleFileCSV:
+		var buf bytes.Buffer
+		writer := csv.NewWriter(&buf)
+		if err := writer.Write(ruleFileColumns); err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			var label, expiresAt string
+			if record.Label != nil {
+				label = escapeCSVFormula(*record.Label)
+			}
+			if record.ExpiresAt != nil {
+				expiresAt = *record.ExpiresAt
+			}
+			if err := writer.Write([]string{record.IPAddress, label, expiresAt}); err != nil {
+				return nil, err
+			}
+		}
+		writer.Flush()
+		return buf.Bytes(), writer.Error()
+	case RuleFileJSON:
+		if records == nil {
+			records = []RuleRecord{}
+		}
+		return json.MarshalIndent(records, "", "  ")
+	default:
+		return nil, fmt.Errorf("unsupported rule file format %q", format)
+	}
+}
+
+// csvFormulaPrefixes are the leading characters spreadsheet applications
+// evaluate as a formula when opening a CSV file.
+const csvFormulaPrefixes = "=+-@\t\r"
+
+// escapeCSVFormula quotes a label that would otherwise run as a formula when
+// an exported file is opened in a spreadsheet, the same way the audit log
+// export does. unescapeCSVFormula undoes it on import.
+func escapeCSVFormula(value string) string {
+	if value != "" && strings.ContainsRune(csvFormulaPrefixes, rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func unescapeCSVFormula(value string) string {
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune(csvFormulaPrefixes, rune(value[1])) {
+		return value[1:]
+	}
+	return value
+}
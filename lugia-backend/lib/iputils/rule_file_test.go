@@ -0,0 +1,138 @@
+package iputils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func TestParseRuleFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   RuleFileFormat
+		content  string
+		expected []RuleRecord
+		wantErr  bool
+	}{
+		{
+			"CSV with header",
+			RuleFileCSV,
+			"ip_address,label,expires_at\n192.168.1.0/24,Office,\n10.0.0.1,,2030-01-01T00:00:00Z\n",
+			[]RuleRecord{
+				{Row: 2, IPAddress: "192.168.1.0/24", Label: strPtr("Office")},
+				{Row: 3, IPAddress: "10.0.0.1", ExpiresAt: strPtr("2030-01-01T00:00:00Z")},
+			},
+			false,
+		},
+		{
+			"CSV without header or optional columns",
+			RuleFileCSV,
+			"192.168.1.0/24\n\n2001:db8::/32, VPN\n",
+			[]RuleRecord{
+				{Row: 1, IPAddress: "192.168.1.0/24"},
+				{Row: 3, IPAddress: "2001:db8::/32", Label: strPtr("VPN")},
+			},
+			false,
+		},
+		{
+			"CSV with byte order mark and quoted label",
+			RuleFileCSV,
+			"\ufeffip_address,label\n192.168.1.0/24,\"Office, 3F\"\n",
+			[]RuleRecord{
+				{Row: 2, IPAddress: "192.168.1.0/24", Label: strPtr("Office, 3F")},
+			},
+			false,
+		},
+		{
+			"CSV keeps malformed values for the caller",
+			RuleFileCSV,
+			"not-an-ip,Label\n",
+			[]RuleRecord{
+				{Row: 1, IPAddress: "not-an-ip", Label: strPtr("Label")},
+			},
+			false,
+		},
+		{"CSV with too many columns", RuleFileCSV, "192.168.1.0/24,a,b,c\n", nil, true},
+		{"CSV with unterminated quote", RuleFileCSV, "192.168.1.0/24,\"Office\n", nil, true},
+		{
+			"JSON array",
+			RuleFileJSON,
+			`[{"ip_address": " 192.168.1.0/24 ", "label": "Office"}, {"ip_address": "10.0.0.1", "label": null, "expires_at": "2030-01-01T00:00:00Z"}]`,
+			[]RuleRecord{
+				{Row: 1, IPAddress: "192.168.1.0/24", Label: strPtr("Office")},
+				{Row: 2, IPAddress: "10.0.0.1", ExpiresAt: strPtr("2030-01-01T00:00:00Z")},
+			},
+			false,
+		},
+		{"JSON with unknown field", RuleFileJSON, `[{"ip": "10.0.0.1"}]`, nil, true},
+		{"JSON object instead of array", RuleFileJSON, `{"ip_address": "10.0.0.1"}`, nil, true},
+		{"Unknown format", RuleFileFormat("xml"), "<rules/>", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseRuleFile(tt.format, tt.content)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseRuleFile() expected error, got %+v", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseRuleFile() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("ParseRuleFile() = %+v, want %+v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEncodeRuleFileRoundTrip(t *testing.T) {
+	records := []RuleRecord{
+		{Row: 1, IPAddress: "192.168.1.0/24", Label: strPtr("Office, 3F")},
+		{Row: 2, IPAddress: "2001:db8::/32", ExpiresAt: strPtr("2030-01-01T00:00:00Z")},
+		{Row: 3, IPAddress: "10.0.0.0/8", Label: strPtr("=HYPERLINK(\"http://example.com\")")},
+	}
+
+	for _, format := range []RuleFileFormat{RuleFileCSV, RuleFileJSON} {
+		t.Run(string(format), func(t *testing.T) {
+			encoded, err := EncodeRuleFile(format, records)
+			if err != nil {
+				t.Fatalf("EncodeRuleFile() unexpected error: %v", err)
+			}
+			decoded, err := ParseRuleFile(format, string(encoded))
+			if err != nil {
+				t.Fatalf("ParseRuleFile() unexpected error: %v", err)
+			}
+			// CSV rows are counted from the header line.
+			if format == RuleFileCSV {
+				for i := range decoded {
+					decoded[i].Row--
+				}
+			}
+			if !reflect.DeepEqual(decoded, records) {
+				t.Errorf("round trip = %+v, want %+v", decoded, records)
+			}
+		})
+	}
+}
+
+func TestEncodeRuleFileEscapesFormulas(t *testing.T) {
+	encoded, err := EncodeRuleFile(RuleFileCSV, []RuleRecord{
+		{IPAddress: "10.0.0.0/8", Label: strPtr("=1+1")},
+		{IPAddress: "10.0.0.1/32", Label: strPtr("-VPN")},
+		{IPAddress: "10.0.0.2/32", Label: strPtr("Office")},
+	})
+	if err != nil {
+		t.Fatalf("EncodeRuleFile() unexpected error: %v", err)
+	}
+
+	expected := "ip_address,label,expires_at\n10.0.0.0/8,'=1+1,\n10.0.0.1/32,'-VPN,\n10.0.0.2/32,Office,\n"
+	if string(encoded) != expected {
+		t.Errorf("EncodeRuleFile() = %q, want %q", encoded, expected)
+	}
+}
@@ -0,0 +1,183 @@
+package iputils
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"dislyze/jirachi/errlib"
+)
+
+// TRUSTED_PROXIES hostnames are resolved in the background, never on the
+// request path. They are looked up again every hostnameRefreshInterval, or
+// every unresolvedRetryInterval while one of them has no addresses yet,
+// because in docker compose the proxy container usually starts after the
+// backend.
+const (
+	hostnameRefreshInterval = time.Minute
+	unresolvedRetryInterval = 2 * time.Second
+	hostnameLookupTimeout   = 2 * time.Second
+)
+
+// TrustedProxies decides whose forwarding headers are believed. A request's
+// forwarding headers are only read when its immediate peer matches one of the
+// prefixes or hostnames; Hops is the number of trusted proxies that append to
+// X-Forwarded-For, so the client is the Hops-th entry from the right.
+// X-Real-IP is only read when RealIPHeader is set, because a proxy that
+// doesn't overwrite it passes on whatever the client sent.
+type TrustedProxies struct {
+	Prefixes     []netip.Prefix
+	Hostnames    []string
+	Hops         int
+	RealIPHeader bool
+}
+
+var (
+	trustedMu sync.RWMutex
+	// The default trusts nobody, so the peer address is the client until
+	// TRUSTED_PROXIES names the proxies in front of the backend.
+	trusted  = TrustedProxies{Hops: 1}
+	resolved = map[string][]netip.Addr{}
+)
+
+// ParseTrustedProxies parses TRUSTED_PROXIES, a comma separated list of IP
+// addresses, CIDR ranges and hostnames, TRUSTED_PROXY_HOPS and
+// TRUSTED_REAL_IP_HEADER. An empty list trusts nobody, so forwarding headers
+// are ignored and the peer address is the client.
+func ParseTrustedProxies(list string, hops string, realIPHeader string) (TrustedProxies, error) {
+	var proxies TrustedProxies
+
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if prefix, err := netip.ParsePrefix(entry); err == nil {
+			proxies.Prefixes = append(proxies.Prefixes, prefix.Masked())
+			continue
+		}
+		if addr, err := netip.ParseAddr(entry); err == nil {
+			addr = addr.Unmap()
+			proxies.Prefixes = append(proxies.Prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		if strings.ContainsAny(entry, "/:") {
+			return TrustedProxies{}, fmt.Errorf("invalid trusted proxy %q", entry)
+		}
+		proxies.Hostnames = append(proxies.Hostnames, entry)
+	}
+
+	hopCount, err := strconv.Atoi(strings.TrimSpace(hops))
+	if err != nil || hopCount < 1 {
+		return TrustedProxies{}, fmt.Errorf("invalid trusted proxy hop count %q: must be a positive integer", hops)
+	}
+	proxies.Hops = hopCount
+
+	if realIPHeader = strings.TrimSpace(realIPHeader); realIPHeader != "" {
+		proxies.RealIPHeader, err = strconv.ParseBool(realIPHeader)
+		if err != nil {
+			return TrustedProxies{}, fmt.Errorf("invalid trusted real IP header setting %q: must be true or false", realIPHeader)
+		}
+	}
+
+	return proxies, nil
+}
+
+// SetTrustedProxies replaces the default from TRUSTED_PROXIES,
+// TRUSTED_PROXY_HOPS and TRUSTED_REAL_IP_HEADER. Hostnames are trusted once
+// ResolveTrustedProxyHostnames has looked them up.
+func SetTrustedProxies(proxies TrustedProxies) {
+	trustedMu.Lock()
+	defer trustedMu.Unlock()
+
+	trusted = proxies
+	resolved = map[string][]netip.Addr{}
+}
+
+func currentTrustedProxies() TrustedProxies {
+	trustedMu.RLock()
+	defer trustedMu.RUnlock()
+	return trusted
+}
+
+func isTrustedProxy(proxies TrustedProxies, peer netip.Addr) bool {
+	peer = peer.Unmap()
+	for _, prefix := range proxies.Prefixes {
+		if prefix.Contains(peer) {
+			return true
+		}
+	}
+
+	trustedMu.RLock()
+	defer trustedMu.RUnlock()
+	for _, hostname := range proxies.Hostnames {
+		for _, addr := range resolved[hostname] {
+			if addr.Unmap() == peer {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// ResolveTrustedProxyHostnames looks up the hostnames in TRUSTED_PROXIES. A
+// failed lookup is logged and keeps the addresses from the previous one, so a
+// DNS hiccup doesn't make the proxy the client of every request.
+func ResolveTrustedProxyHostnames(ctx context.Context) {
+	for _, hostname := range currentTrustedProxies().Hostnames {
+		lookupCtx, cancel := context.WithTimeout(ctx, hostnameLookupTimeout)
+		addrs, err := net.DefaultResolver.LookupNetIP(lookupCtx, "ip", hostname)
+		cancel()
+		if err != nil {
+			errlib.LogError(fmt.Errorf("iputils: failed to resolve trusted proxy %q: %w", hostname, err))
+			continue
+		}
+
+		trustedMu.Lock()
+		resolved[hostname] = addrs
+		trustedMu.Unlock()
+	}
+}
+
+// WatchTrustedProxyHostnames keeps the TRUSTED_PROXIES hostnames resolved
+// until ctx is cancelled. It returns straight away when there are none.
+func WatchTrustedProxyHostnames(ctx context.Context) {
+	if len(currentTrustedProxies().Hostnames) == 0 {
+		return
+	}
+
+	for {
+		interval := hostnameRefreshInterval
+		if !trustedProxyHostnamesResolved() {
+			interval = unresolvedRetryInterval
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		ResolveTrustedProxyHostnames(ctx)
+	}
+}
+
+func trustedProxyHostnamesResolved() bool {
+	trustedMu.RLock()
+	defer trustedMu.RUnlock()
+	for _, hostname := range trusted.Hostnames {
+		if len(resolved[hostname]) == 0 {
+			return false
+		}
+	}
+	return true
+}
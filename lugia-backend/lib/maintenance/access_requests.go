@@ -0,0 +1,60 @@
+package maintenance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"dislyze/jirachi/auditlog"
+	"lugia/queries"
+)
+
+// expirePendingAccessRequests moves pending access requests past their
+// decision deadline to expired and records each as an access_request expired
+// audit entry. Nobody acted at that moment, so the entry has no actor; the
+// requester is named in the metadata. Nothing was granted, so there is no role
+// to remove.
+func expirePendingAccessRequests(ctx context.Context, q *queries.Queries, cutoff pgtype.Timestamptz, batchSize int32) (int64, error) {
+	expired, err := q.ExpirePendingAccessRequests(ctx, &queries.ExpirePendingAccessRequestsParams{
+		Cutoff:    cutoff,
+		BatchSize: batchSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, row := range expired {
+		if !row.AuditLogEnabled {
+			continue
+		}
+
+		metadata, _ := json.Marshal(map[string]string{
+			"reason":            "expired",
+			"role_id":           row.RoleID.String(),
+			"role_name":         row.RoleName,
+			"expires_at":        row.ExpiresAt.Time.Format(time.RFC3339),
+			"target_user_name":  row.RequesterName,
+			"target_user_email": row.RequesterEmail,
+		})
+
+		err := q.InsertAuditLog(ctx, &queries.InsertAuditLogParams{
+			TenantID:     row.TenantID,
+			ActorID:      pgtype.UUID{},
+			ResourceType: string(auditlog.ResourceAccessRequest),
+			Action:       string(auditlog.ActionExpired),
+			Outcome:      string(auditlog.OutcomeSuccess),
+			ResourceID:   pgtype.Text{String: row.ID.String(), Valid: true},
+			Metadata:     metadata,
+			IpAddress:    nil,
+			UserAgent:    pgtype.Text{},
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to insert audit log for expired access request %s: %w", row.ID.String(), err)
+		}
+	}
+
+	return int64(len(expired)), nil
+}
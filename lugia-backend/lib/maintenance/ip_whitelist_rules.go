@@ -0,0 +1,187 @@
+package maintenance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/sendgrid/sendgrid-go"
+
+	"dislyze/jirachi/auditlog"
+	"dislyze/jirachi/errlib"
+	"dislyze/jirachi/sendgridlib"
+	"lugia/queries"
+)
+
+// jst is the display time zone for dates in notification emails.
+var jst = time.FixedZone("JST", 9*60*60)
+
+// purgeExpiredIPWhitelistRules deletes whitelist rules whose expires_at has
+// passed. The middleware already ignores them; this removes the rows and
+// records each removal as ip_removed with reason "expired". Nobody acted at
+// that moment, so the entry has no actor; the admin who added the rule is
+// kept in the metadata. A whitelist left without a tenant-wide rule stays
+// active and denies everyone, as it would have if an editor had deleted the
+// rule: the expiry warning is the chance to avoid that, and emergency
+// deactivation the way back.
+func purgeExpiredIPWhitelistRules(ctx context.Context, q *queries.Queries, cutoff pgtype.Timestamptz, batchSize int32) (int64, error) {
+	expired, err := q.PurgeExpiredIPWhitelistRules(ctx, &queries.PurgeExpiredIPWhitelistRulesParams{
+		Cutoff:    cutoff,
+		BatchSize: batchSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, row := range expired {
+		if !row.AuditLogEnabled {
+			continue
+		}
+
+		metadata := map[string]string{
+			"reason":     "expired",
+			"ip_address": row.IpAddress,
+			"expires_at": row.ExpiresAt.Time.Format(time.RFC3339),
+		}
+		if row.CreatedBy.Valid {
+			metadata["created_by"] = row.CreatedBy.String()
+		}
+		if row.Label.Valid {
+			metadata["label"] = row.Label.String
+		}
+		metadataJSON, _ := json.Marshal(metadata)
+
+		err := q.InsertAuditLog(ctx, &queries.InsertAuditLogParams{
+			TenantID:     row.TenantID,
+			ActorID:      pgtype.UUID{},
+			ResourceType: string(auditlog.ResourceIPWhitelist),
+			Action:       string(auditlog.ActionIPRemoved),
+			Outcome:      string(auditlog.OutcomeSuccess),
+			ResourceID:   pgtype.Text{String: row.ID.String(), Valid: true},
+			Metadata:     metadataJSON,
+			IpAddress:    nil,
+			UserAgent:    pgtype.Text{},
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to insert audit log for expired IP whitelist rule %s: %w", row.ID.String(), err)
+		}
+	}
+
+	return int64(len(expired)), nil
+}
+
+// warnExpiringIPWhitelistRules emails every ip_whitelist editor of a tenant
+// once about its rules that expire within cfg.IPRuleExpiryWarning. Rules are
+// claimed by setting expiry_warned_at before sending, and a failed send is
+// logged rather than retried: a missed warning is not worth mailing every
+// other tenant in the batch twice.
+func warnExpiringIPWhitelistRules(cfg *Config) purgeFunc {
+	return func(ctx context.Context, q *queries.Queries, cutoff pgtype.Timestamptz, batchSize int32) (int64, error) {
+		claimed, err := q.ClaimExpiringIPWhitelistRules(ctx, &queries.ClaimExpiringIPWhitelistRulesParams{
+			WarnBefore: pgtype.Timestamptz{Time: cutoff.Time.Add(cfg.IPRuleExpiryWarning), Valid: true},
+			BatchSize:  batchSize,
+		})
+		if err != nil {
+			return 0, err
+		}
+
+		// Rows come back ordered by tenant, so each tenant is one contiguous run.
+		for start := 0; start < len(claimed); {
+			end := start
+			for end < len(claimed) && claimed[end].TenantID == claimed[start].TenantID {
+				end++
+			}
+			rules := claimed[start:end]
+			start = end
+
+			editors, err := q.GetIPWhitelistEditors(ctx, &queries.GetIPWhitelistEditorsParams{
+				TenantID:    rules[0].TenantID,
+				RbacEnabled: rules[0].RbacEnabled,
+			})
+			if err != nil {
+				return 0, fmt.Errorf("failed to get IP whitelist editors for tenant %s: %w", rules[0].TenantID.String(), err)
+			}
+			if len(editors) == 0 {
+				continue
+			}
+
+			if err := sendExpiryWarning(cfg, editors, rules); err != nil {
+				errlib.LogError(fmt.Errorf("warnExpiringIPWhitelistRules: failed to send warning for tenant %s: %w", rules[0].TenantID.String(), err))
+			}
+		}
+
+		return int64(len(claimed)), nil
+	}
+}
+
+func sendExpiryWarning(cfg *Config, editors []*queries.GetIPWhitelistEditorsRow, rules []*queries.ClaimExpiringIPWhitelistRulesRow) error {
+	to := make([]sendgridlib.SendGridEmailAddress, 0, len(editors))
+	for _, editor := range editors {
+		to = append(to, sendgridlib.SendGridEmailAddress{Email: editor.Email, Name: editor.Name})
+	}
+
+	subject := fmt.Sprintf("【%s】IPアドレス制限のルールがまもなく期限切れになります", rules[0].TenantName)
+	settingsLink := fmt.Sprintf("%s/settings/ip-whitelist", cfg.FrontendURL)
+
+	var plainRules, htmlRules strings.Builder
+	for _, rule := range rules {
+		expiresAt := rule.ExpiresAt.Time.In(jst).Format("2006/01/02 15:04")
+		label := ""
+		if rule.Label.Valid && rule.Label.String != "" {
+			label = fmt.Sprintf("（%s）", rule.Label.String)
+		}
+		fmt.Fprintf(&plainRules, "・%s%s：%sまで\n", rule.IpAddress, label, expiresAt)
+		fmt.Fprintf(&htmlRules, "<li>%s%s：%sまで</li>", html.EscapeString(rule.IpAddress), html.EscapeString(label), expiresAt)
+	}
+
+	plainTextContent := fmt.Sprintf("以下のIPアドレス制限のルールは有効期限を過ぎると自動的に削除されます。\n\n%s\n引き続き許可する場合は、以下のリンクから期限のないルールを追加してください。許可するルールがすべてなくなると、IPアドレス制限は有効なまま全員のアクセスを拒否します。\n%s",
+		plainRules.String(), settingsLink)
+	htmlContent := fmt.Sprintf(`<p>以下のIPアドレス制限のルールは有効期限を過ぎると自動的に削除されます。</p>
+	<ul>%s</ul>
+	<p>引き続き許可する場合は、期限のないルールを追加してください。許可するルールがすべてなくなると、IPアドレス制限は有効なまま全員のアクセスを拒否します。</p>
+	<p><a href="%s">IPアドレス制限の設定を開く</a></p>`,
+		htmlRules.String(), settingsLink)
+
+	return sendMail(cfg, to, subject, plainTextContent, htmlContent)
+}
+
+// sendMail sends one message per recipient so editors don't see each other's
+// addresses.
+func sendMail(cfg *Config, to []sendgridlib.SendGridEmailAddress, subject, plainTextContent, htmlContent string) error {
+	personalizations := make([]sendgridlib.SendGridPersonalization, 0, len(to))
+	for _, recipient := range to {
+		personalizations = append(personalizations, sendgridlib.SendGridPersonalization{
+			To:      []sendgridlib.SendGridEmailAddress{recipient},
+			Subject: subject,
+		})
+	}
+
+	sgMailBody := sendgridlib.SendGridMailRequestBody{
+		Personalizations: personalizations,
+		From:             sendgridlib.SendGridEmailAddress{Email: sendgridlib.SendGridFromEmail, Name: sendgridlib.SendGridFromName},
+		Content:          []sendgridlib.SendGridContent{{Type: "text/plain", Value: plainTextContent}, {Type: "text/html", Value: htmlContent}},
+	}
+
+	bodyBytes, err := json.Marshal(sgMailBody)
+	if err != nil {
+		return fmt.Errorf("failed to marshal SendGrid request body: %w", err)
+	}
+
+	sendgridRequest := sendgrid.GetRequest(cfg.SendgridAPIKey, "/v3/mail/send", cfg.SendgridAPIUrl)
+	sendgridRequest.Method = "POST"
+	sendgridRequest.Body = bodyBytes
+	sgResponse, err := sendgrid.API(sendgridRequest)
+	if err != nil {
+		return fmt.Errorf("SendGrid API call failed: %w", err)
+	}
+
+	if sgResponse.StatusCode < 200 || sgResponse.StatusCode >= 300 {
+		return fmt.Errorf("SendGrid returned error status code %d. Body: %s", sgResponse.StatusCode, sgResponse.Body)
+	}
+
+	return nil
+}
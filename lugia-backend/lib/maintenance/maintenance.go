@@ -0,0 +1,273 @@
+package maintenance
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"dislyze/jirachi/errlib"
+	"dislyze/jirachi/logger"
+	"lugia/lib/config"
+	"lugia/queries"
+)
+
+// maxBatchesPerRun bounds how long one job can hold the database busy in a
+// single pass. Anything left over is picked up on the next interval.
+const maxBatchesPerRun = 100
+
+type Retentions struct {
+	DeletedUsers         time.Duration
+	PasswordResetTokens  time.Duration
+	EmailChangeTokens    time.Duration
+	InvitationTokens     time.Duration
+	RefreshTokens        time.Duration
+	SSOAuthRequests      time.Duration
+	IPMonitorHits        time.Duration
+	IPBreakGlassRequests time.Duration
+}
+
+type Config struct {
+	Interval   time.Duration
+	BatchSize  int32
+	Retentions Retentions
+	// IPRuleExpiryWarning is how long before an IP whitelist rule expires
+	// its tenant's editors are emailed.
+	IPRuleExpiryWarning time.Duration
+
+	FrontendURL    string
+	SendgridAPIKey string
+	SendgridAPIUrl string
+}
+
+func NewConfig(env *config.Env) (*Config, error) {
+	cfg := &Config{
+		FrontendURL:    env.FrontendURL,
+		SendgridAPIKey: env.SendgridAPIKey,
+		SendgridAPIUrl: env.SendgridAPIUrl,
+	}
+
+	durations := []struct {
+		key   string
+		value string
+		dest  *time.Duration
+	}{
+		{"PURGE_INTERVAL", env.PurgeInterval, &cfg.Interval},
+		{"PURGE_RETENTION_DELETED_USERS", env.PurgeRetentionDeletedUsers, &cfg.Retentions.DeletedUsers},
+		{"PURGE_RETENTION_PASSWORD_RESET_TOKENS", env.PurgeRetentionPasswordResetTokens, &cfg.Retentions.PasswordResetTokens},
+		{"PURGE_RETENTION_EMAIL_CHANGE_TOKENS", env.PurgeRetentionEmailChangeTokens, &cfg.Retentions.EmailChangeTokens},
+		{"PURGE_RETENTION_INVITATION_TOKENS", env.PurgeRetentionInvitationTokens, &cfg.Retentions.InvitationTokens},
+		{"PURGE_RETENTION_REFRESH_TOKENS", env.PurgeRetentionRefreshTokens, &cfg.Retentions.RefreshTokens},
+		{"PURGE_RETENTION_SSO_AUTH_REQUESTS", env.PurgeRetentionSSOAuthRequests, &cfg.Retentions.SSOAuthRequests},
+		{"PURGE_RETENTION_IP_MONITOR_HITS", env.PurgeRetentionIPMonitorHits, &cfg.Retentions.IPMonitorHits},
+		{"PURGE_RETENTION_IP_BREAK_GLASS_REQUESTS", env.PurgeRetentionIPBreakGlassRequests, &cfg.Retentions.IPBreakGlassRequests},
+		{"IP_WHITELIST_EXPIRY_WARNING", env.IPWhitelistExpiryWarning, &cfg.IPRuleExpiryWarning},
+	}
+	for _, d := range durations {
+		parsed, err := time.ParseDuration(d.value)
+		if err != nil {
+			return nil, fmt.Errorf("NewConfig: invalid %s %q: %w", d.key, d.value, err)
+		}
+		if parsed < 0 {
+			return nil, fmt.Errorf("NewConfig: %s must not be negative, got %q", d.key, d.value)
+		}
+		*d.dest = parsed
+	}
+	if cfg.Interval == 0 {
+		return nil, fmt.Errorf("NewConfig: PURGE_INTERVAL must be positive")
+	}
+
+	batchSize, err := strconv.ParseInt(env.PurgeBatchSize, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("NewConfig: invalid PURGE_BATCH_SIZE %q: %w", env.PurgeBatchSize, err)
+	}
+	if batchSize <= 0 {
+		return nil, fmt.Errorf("NewConfig: PURGE_BATCH_SIZE must be positive, got %d", batchSize)
+	}
+	cfg.BatchSize = int32(batchSize)
+
+	return cfg, nil
+}
+
+type purgeFunc func(ctx context.Context, q *queries.Queries, cutoff pgtype.Timestamptz, batchSize int32) (int64, error)
+
+type job struct {
+	name      string
+	retention time.Duration
+	purge     purgeFunc
+}
+
+// Runner periodically deletes rows that have outlived their retention. It is
+// safe to run on every instance: each batch takes a per-job advisory lock, and
+// an instance that loses the race skips the job until the next interval.
+type Runner struct {
+	dbConn *pgxpool.Pool
+	q      *queries.Queries
+	cfg    *Config
+	jobs   []job
+}
+
+func NewRunner(dbConn *pgxpool.Pool, q *queries.Queries, cfg *Config) *Runner {
+	return &Runner{
+		dbConn: dbConn,
+		q:      q,
+		cfg:    cfg,
+		jobs: []job{
+			{"password_reset_tokens", cfg.Retentions.PasswordResetTokens, func(ctx context.Context, q *queries.Queries, cutoff pgtype.Timestamptz, batchSize int32) (int64, error) {
+				return q.PurgeExpiredPasswordResetTokens(ctx, &queries.PurgeExpiredPasswordResetTokensParams{Cutoff: cutoff, BatchSize: batchSize})
+			}},
+			{"email_change_tokens", cfg.Retentions.EmailChangeTokens, func(ctx context.Context, q *queries.Queries, cutoff pgtype.Timestamptz, batchSize int32) (int64, error) {
+				return q.PurgeExpiredEmailChangeTokens(ctx, &queries.PurgeExpiredEmailChangeTokensParams{Cutoff: cutoff, BatchSize: batchSize})
+			}},
+			{"invitation_tokens", cfg.Retentions.InvitationTokens, func(ctx context.Context, q *queries.Queries, cutoff pgtype.Timestamptz, batchSize int32) (int64, error) {
+				return q.PurgeExpiredInvitationTokens(ctx, &queries.PurgeExpiredInvitationTokensParams{Cutoff: cutoff, BatchSize: batchSize})
+			}},
+			{"refresh_tokens", cfg.Retentions.RefreshTokens, func(ctx context.Context, q *queries.Queries, cutoff pgtype.Timestamptz, batchSize int32) (int64, error) {
+				return q.PurgeExpiredRefreshTokens(ctx, &queries.PurgeExpiredRefreshTokensParams{Cutoff: cutoff, BatchSize: batchSize})
+			}},
+			{"sso_auth_requests", cfg.Retentions.SSOAuthRequests, func(ctx context.Context, q *queries.Queries, cutoff pgtype.Timestamptz, batchSize int32) (int64, error) {
+				return q.PurgeExpiredSSOAuthRequests(ctx, &queries.PurgeExpiredSSOAuthRequestsParams{Cutoff: cutoff, BatchSize: batchSize})
+			}},
+			{"ip_whitelist_monitor_hits", cfg.Retentions.IPMonitorHits, func(ctx context.Context, q *queries.Queries, cutoff pgtype.Timestamptz, batchSize int32) (int64, error) {
+				return q.PurgeIPWhitelistMonitorHits(ctx, &queries.PurgeIPWhitelistMonitorHitsParams{Cutoff: cutoff, BatchSize: batchSize})
+			}},
+			// Approvals go with their request.
+			{"ip_whitelist_break_glass_requests", cfg.Retentions.IPBreakGlassRequests, func(ctx context.Context, q *queries.Queries, cutoff pgtype.Timestamptz, batchSize int32) (int64, error) {
+				return q.PurgeExpiredIPWhitelistBreakGlassRequests(ctx, &queries.PurgeExpiredIPWhitelistBreakGlassRequestsParams{Cutoff: cutoff, BatchSize: batchSize})
+			}},
+			// Expired role assignments have no grace period: they stopped
+			// granting anything the moment they expired.
+			{"expired_user_roles", 0, purgeExpiredUserRoles},
+			// Pending access requests carry their own decision deadline.
+			{"pending_access_requests", 0, expirePendingAccessRequests},
+			// Expired whitelist rules stopped matching when they expired.
+			{"expired_ip_whitelist_rules", 0, purgeExpiredIPWhitelistRules},
+			{"ip_whitelist_expiry_warnings", 0, warnExpiringIPWhitelistRules(cfg)},
+			{"deleted_users", cfg.Retentions.DeletedUsers, func(ctx context.Context, q *queries.Queries, cutoff pgtype.Timestamptz, batchSize int32) (int64, error) {
+				return q.PurgeAnonymizedUsers(ctx, &queries.PurgeAnonymizedUsersParams{Cutoff: cutoff, BatchSize: batchSize})
+			}},
+		},
+	}
+}
+
+// Run purges immediately and then on every interval until ctx is cancelled.
+func (r *Runner) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		r.RunOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *Runner) RunOnce(ctx context.Context) {
+	for _, j := range r.jobs {
+		if ctx.Err() != nil {
+			return
+		}
+		r.runJob(ctx, j)
+	}
+}
+
+func (r *Runner) runJob(ctx context.Context, j job) {
+	start := time.Now()
+	cutoff := start.Add(-j.retention)
+
+	var deleted int64
+	batches := 0
+	for batches < maxBatchesPerRun {
+		n, acquired, err := r.purgeBatch(ctx, j, cutoff)
+		if err != nil {
+			logger.LogMaintenanceEvent(logger.MaintenanceEvent{
+				EventType: "purge",
+				Service:   "lugia",
+				Job:       j.name,
+				Deleted:   deleted,
+				Batches:   batches,
+				Cutoff:    cutoff,
+				Duration:  time.Since(start).String(),
+				Timestamp: time.Now(),
+				Success:   false,
+				Error:     err.Error(),
+			})
+			return
+		}
+		if !acquired {
+			// Another instance is purging this job right now.
+			break
+		}
+		batches++
+		deleted += n
+		if n < int64(r.cfg.BatchSize) {
+			break
+		}
+	}
+
+	if deleted == 0 {
+		return
+	}
+	logger.LogMaintenanceEvent(logger.MaintenanceEvent{
+		EventType: "purge",
+		Service:   "lugia",
+		Job:       j.name,
+		Deleted:   deleted,
+		Batches:   batches,
+		Cutoff:    cutoff,
+		Duration:  time.Since(start).String(),
+		Timestamp: time.Now(),
+		Success:   true,
+	})
+}
+
+// purgeBatch deletes one batch inside its own transaction so the advisory lock
+// and the row locks are released between batches.
+func (r *Runner) purgeBatch(ctx context.Context, j job, cutoff time.Time) (int64, bool, error) {
+	tx, err := r.dbConn.Begin(ctx)
+	if err != nil {
+		return 0, false, fmt.Errorf("purgeBatch: failed to begin transaction for %s: %w", j.name, err)
+	}
+	defer func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errlib.Is(rbErr, pgx.ErrTxClosed) && !errlib.Is(rbErr, sql.ErrTxDone) {
+			errlib.LogError(fmt.Errorf("purgeBatch: failed to rollback transaction for %s: %w", j.name, rbErr))
+		}
+	}()
+	qtx := r.q.WithTx(tx)
+
+	acquired, err := qtx.TryMaintenanceLock(ctx, lockKey(j.name))
+	if err != nil {
+		return 0, false, fmt.Errorf("purgeBatch: failed to acquire lock for %s: %w", j.name, err)
+	}
+	if !acquired {
+		return 0, false, nil
+	}
+
+	n, err := j.purge(ctx, qtx, pgtype.Timestamptz{Time: cutoff, Valid: true}, r.cfg.BatchSize)
+	if err != nil {
+		return 0, true, fmt.Errorf("purgeBatch: failed to purge %s: %w", j.name, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, true, fmt.Errorf("purgeBatch: failed to commit transaction for %s: %w", j.name, err)
+	}
+	return n, true, nil
+}
+
+// lockKey maps a job name to a stable advisory lock key. The prefix keeps the
+// keys clear of any other advisory lock user in the same database.
+func lockKey(jobName string) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte("lugia.maintenance." + jobName))
+	return int64(h.Sum64()) // #nosec G115 -- wrapping is fine, only the bit pattern matters
+}
@@ -0,0 +1,87 @@
+package maintenance
+
+import (
+	"testing"
+	"time"
+
+	"lugia/lib/config"
+)
+
+func defaultEnv() *config.Env {
+	return &config.Env{
+		PurgeInterval:                      "1h",
+		PurgeBatchSize:                     "500",
+		PurgeRetentionDeletedUsers:         "720h",
+		PurgeRetentionPasswordResetTokens:  "24h",
+		PurgeRetentionEmailChangeTokens:    "24h",
+		PurgeRetentionInvitationTokens:     "168h",
+		PurgeRetentionRefreshTokens:        "720h",
+		PurgeRetentionSSOAuthRequests:      "0s",
+		PurgeRetentionIPMonitorHits:        "2160h",
+		PurgeRetentionIPBreakGlassRequests: "24h",
+		IPWhitelistExpiryWarning:           "72h",
+	}
+}
+
+func TestNewConfig(t *testing.T) {
+	cfg, err := NewConfig(defaultEnv())
+	if err != nil {
+		t.Fatalf("NewConfig() unexpected error: %v", err)
+	}
+	if cfg.Interval != time.Hour {
+		t.Errorf("Interval = %v, want %v", cfg.Interval, time.Hour)
+	}
+	if cfg.BatchSize != 500 {
+		t.Errorf("BatchSize = %d, want 500", cfg.BatchSize)
+	}
+	if cfg.Retentions.DeletedUsers != 720*time.Hour {
+		t.Errorf("Retentions.DeletedUsers = %v, want %v", cfg.Retentions.DeletedUsers, 720*time.Hour)
+	}
+	if cfg.Retentions.SSOAuthRequests != 0 {
+		t.Errorf("Retentions.SSOAuthRequests = %v, want 0", cfg.Retentions.SSOAuthRequests)
+	}
+	if cfg.Retentions.IPBreakGlassRequests != 24*time.Hour {
+		t.Errorf("Retentions.IPBreakGlassRequests = %v, want %v", cfg.Retentions.IPBreakGlassRequests, 24*time.Hour)
+	}
+}
+
+func TestNewConfigInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(env *config.Env)
+	}{
+		{"Unparseable interval", func(env *config.Env) { env.PurgeInterval = "hourly" }},
+		{"Zero interval", func(env *config.Env) { env.PurgeInterval = "0s" }},
+		{"Negative retention", func(env *config.Env) { env.PurgeRetentionRefreshTokens = "-1h" }},
+		{"Retention without unit", func(env *config.Env) { env.PurgeRetentionDeletedUsers = "30" }},
+		{"Non-numeric batch size", func(env *config.Env) { env.PurgeBatchSize = "many" }},
+		{"Zero batch size", func(env *config.Env) { env.PurgeBatchSize = "0" }},
+		{"Batch size overflowing int32", func(env *config.Env) { env.PurgeBatchSize = "3000000000" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := defaultEnv()
+			tt.modify(env)
+			if _, err := NewConfig(env); err == nil {
+				t.Errorf("NewConfig() expected error but got none")
+			}
+		})
+	}
+}
+
+func TestLockKeysAreStableAndDistinct(t *testing.T) {
+	r := NewRunner(nil, nil, &Config{Interval: time.Hour, BatchSize: 1})
+
+	seen := make(map[int64]string)
+	for _, j := range r.jobs {
+		key := lockKey(j.name)
+		if key != lockKey(j.name) {
+			t.Errorf("lockKey(%q) is not stable", j.name)
+		}
+		if other, ok := seen[key]; ok {
+			t.Errorf("lockKey(%q) collides with lockKey(%q)", j.name, other)
+		}
+		seen[key] = j.name
+	}
+}
@@ -0,0 +1,64 @@
+package maintenance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"dislyze/jirachi/auditlog"
+	"lugia/queries"
+)
+
+// purgeExpiredUserRoles deletes time-bound role assignments whose expires_at
+// has passed. Permission checks already ignore them; this removes the rows and
+// records each removal as roles_updated with reason "expired". Nobody acted at
+// that moment, so the entry has no actor; the granter, if still known, is kept
+// in the metadata.
+func purgeExpiredUserRoles(ctx context.Context, q *queries.Queries, cutoff pgtype.Timestamptz, batchSize int32) (int64, error) {
+	expired, err := q.PurgeExpiredUserRoles(ctx, &queries.PurgeExpiredUserRolesParams{
+		Cutoff:    cutoff,
+		BatchSize: batchSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, row := range expired {
+		if !row.AuditLogEnabled {
+			continue
+		}
+
+		details := map[string]string{
+			"reason":            "expired",
+			"role_id":           row.RoleID.String(),
+			"role_name":         row.RoleName,
+			"expires_at":        row.ExpiresAt.Time.Format(time.RFC3339),
+			"target_user_name":  row.UserName,
+			"target_user_email": row.UserEmail,
+		}
+		if row.GrantedBy.Valid {
+			details["granted_by"] = row.GrantedBy.String()
+		}
+		metadata, _ := json.Marshal(details)
+
+		err := q.InsertAuditLog(ctx, &queries.InsertAuditLogParams{
+			TenantID:     row.TenantID,
+			ActorID:      pgtype.UUID{},
+			ResourceType: string(auditlog.ResourceUser),
+			Action:       string(auditlog.ActionRolesUpdated),
+			Outcome:      string(auditlog.OutcomeSuccess),
+			ResourceID:   pgtype.Text{String: row.UserID.String(), Valid: true},
+			Metadata:     metadata,
+			IpAddress:    nil,
+			UserAgent:    pgtype.Text{},
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to insert audit log for expired role %s of user %s: %w", row.RoleID.String(), row.UserID.String(), err)
+		}
+	}
+
+	return int64(len(expired)), nil
+}
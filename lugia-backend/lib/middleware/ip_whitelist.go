@@ -1,13 +1,14 @@
 package middleware
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/netip"
 	"time"
 
-	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 
 	"dislyze/jirachi/auditlog"
@@ -15,6 +16,7 @@ import (
 	"dislyze/jirachi/errlib"
 	"dislyze/jirachi/logger"
 	"lugia/lib/authz"
+	"lugia/lib/geoip"
 	"lugia/lib/iputils"
 	"lugia/queries"
 )
@@ -38,6 +40,12 @@ func IPWhitelistMiddleware(db *queries.Queries) func(http.Handler) http.Handler
 				return
 			}
 
+			if authz.GetIPWhitelistMonitor(ctx) {
+				recordWouldBeBlock(r, db, iputils.ExtractClientIP(r))
+				next.ServeHTTP(w, r)
+				return
+			}
+
 			active := authz.GetIPWhitelistActive(ctx)
 			if !active {
 				// IP whitelist not active, continue normally
@@ -45,157 +53,283 @@ func IPWhitelistMiddleware(db *queries.Queries) func(http.Handler) http.Handler
 				return
 			}
 
-			clientIP := iputils.ExtractClientIP(r)
-
-			ipConfig, err := db.GetIPWhitelistForMiddleware(ctx, tenantID)
-			if err != nil {
-				if errlib.Is(err, pgx.ErrNoRows) {
-					logger.LogAccessEvent(logger.AccessEvent{
-						EventType: "ip_whitelist",
-						UserID:    userID.String(),
-						TenantID:  tenantID.String(),
-						IPAddress: clientIP,
-						UserAgent: r.UserAgent(),
-						Timestamp: time.Now(),
-						Success:   false,
-						Error:     "IP whitelist enabled but no configuration found",
-						Feature:   "ip_whitelist",
-					})
-
-					w.WriteHeader(http.StatusInternalServerError)
+			if err := checkIPWhitelist(ctx, r, db, tenantID, userID); err != nil {
+				if errlib.Is(err, ErrIPNotWhitelisted) {
+					w.WriteHeader(http.StatusForbidden)
 					return
 				}
-
-				logger.LogAccessEvent(logger.AccessEvent{
-					EventType: "ip_whitelist",
-					UserID:    userID.String(),
-					TenantID:  tenantID.String(),
-					IPAddress: clientIP,
-					UserAgent: r.UserAgent(),
-					Timestamp: time.Now(),
-					Success:   false,
-					Error:     "Failed to load IP whitelist configuration: " + err.Error(),
-					Feature:   "ip_whitelist",
-				})
-
 				w.WriteHeader(http.StatusInternalServerError)
 				return
 			}
 
-			// Extract configuration from first row (all rows have same config)
-			if len(ipConfig) == 0 {
-				// No rules configured - deny all access
-				logger.LogAccessEvent(logger.AccessEvent{
-					EventType: "ip_whitelist",
-					UserID:    userID.String(),
-					TenantID:  tenantID.String(),
-					IPAddress: clientIP,
-					UserAgent: r.UserAgent(),
-					Timestamp: time.Now(),
-					Success:   false,
-					Error:     "Access denied: No IP addresses configured in whitelist",
-					Feature:   "ip_whitelist",
-				})
-
-				w.WriteHeader(http.StatusForbidden)
-				return
-			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
 
-			config := ipConfig[0]
-
-			if config.AllowInternalBypass && libctx.GetIsInternalUser(ctx) {
-				logger.LogAccessEvent(logger.AccessEvent{
-					EventType: "ip_whitelist",
-					UserID:    userID.String(),
-					TenantID:  tenantID.String(),
-					IPAddress: clientIP,
-					UserAgent: r.UserAgent(),
-					Timestamp: time.Now(),
-					Success:   true,
-					Error:     "Internal user bypass enabled",
-					Feature:   "ip_whitelist",
-				})
+// ErrIPNotWhitelisted is returned when the active whitelist blocks the
+// client IP.
+var ErrIPNotWhitelisted = errors.New("client IP not in whitelist")
 
-				next.ServeHTTP(w, r)
-				return
-			}
+// ipWhitelistOutcome is what the whitelist does with one request.
+type ipWhitelistOutcome int
 
-			var allowedIPs []string
-			for _, row := range ipConfig {
-				if row.IpAddress != "" {
-					allowedIPs = append(allowedIPs, row.IpAddress)
-				}
-			}
+const (
+	ipWhitelistMatched ipWhitelistOutcome = iota
+	ipWhitelistExempted
+	ipWhitelistBypassed
+	ipWhitelistEmpty
+	ipWhitelistNotMatched
+)
 
-			allowed, err := iputils.IsIPInCIDRList(clientIP, allowedIPs)
-			if err != nil {
-				logger.LogAccessEvent(logger.AccessEvent{
-					EventType: "ip_whitelist",
-					UserID:    userID.String(),
-					TenantID:  tenantID.String(),
-					IPAddress: clientIP,
-					UserAgent: r.UserAgent(),
-					Timestamp: time.Now(),
-					Success:   false,
-					Error:     "IP validation error: " + err.Error(),
-					Feature:   "ip_whitelist",
-				})
+// blocks reports whether the outcome denies the request.
+func (o ipWhitelistOutcome) blocks() bool {
+	return o == ipWhitelistEmpty || o == ipWhitelistNotMatched
+}
 
-				w.WriteHeader(http.StatusInternalServerError)
-				return
-			}
+// ipWhitelistDecision is the outcome for a request, with the unmapped client
+// address and its country when the rules had to be consulted.
+type ipWhitelistDecision struct {
+	outcome ipWhitelistOutcome
+	addr    netip.Addr
+	country string
+}
 
-			if !allowed {
-				logger.LogAccessEvent(logger.AccessEvent{
-					EventType: "ip_whitelist",
-					UserID:    userID.String(),
-					TenantID:  tenantID.String(),
-					IPAddress: clientIP,
-					UserAgent: r.UserAgent(),
-					Timestamp: time.Now(),
-					Success:   false,
-					Error:     fmt.Sprintf("Access denied: IP %s not in whitelist", clientIP),
-					Feature:   "ip_whitelist",
-				})
-
-				if authz.TenantHasFeature(ctx, authz.FeatureAuditLog) {
-					metadata, _ := json.Marshal(map[string]string{
-						"blocked_ip": clientIP,
-					})
-					ipAddr, _ := netip.ParseAddr(clientIP)
-					//nolint:auditcheck // access already denied (403), audit log is best-effort for denial events
-					if err := db.InsertAuditLog(ctx, &queries.InsertAuditLogParams{
-						TenantID:     tenantID,
-						ActorID:      userID,
-						ResourceType: string(auditlog.ResourceAccess),
-						Action:       string(auditlog.ActionIPBlocked),
-						Outcome:      string(auditlog.OutcomeFailure),
-						ResourceID:   pgtype.Text{},
-						Metadata:     metadata,
-						IpAddress:    &ipAddr,
-						UserAgent:    pgtype.Text{String: r.UserAgent(), Valid: true},
-					}); err != nil {
-						errlib.LogError(fmt.Errorf("IPWhitelistMiddleware: failed to insert audit log: %w", err))
-					}
-				}
+// decideIPWhitelist applies whitelist to a request from clientIP in
+// enforcement order: scope exemptions, then the empty-list deny, then the
+// internal-admin bypass, and last the rules. Enforcement and monitor mode both
+// decide here, so monitor mode counts exactly the requests enforcement would
+// block. The client IP is only parsed once the rules are reached; it returns
+// an error if it doesn't parse.
+func decideIPWhitelist(whitelist *ipWhitelist, scopes []*queries.GetUserIPWhitelistScopesRow, clientIP string, internalBypass bool) (ipWhitelistDecision, error) {
+	if ipWhitelistExempt(scopes) {
+		return ipWhitelistDecision{outcome: ipWhitelistExempted}, nil
+	}
+	if whitelist.empty() {
+		return ipWhitelistDecision{outcome: ipWhitelistEmpty}, nil
+	}
+	if internalBypass {
+		return ipWhitelistDecision{outcome: ipWhitelistBypassed}, nil
+	}
 
-				w.WriteHeader(http.StatusForbidden)
-				return
-			}
+	addr, err := netip.ParseAddr(clientIP)
+	if err != nil {
+		return ipWhitelistDecision{}, fmt.Errorf("invalid client IP address %q: %w", clientIP, err)
+	}
+	addr = addr.Unmap()
+	decision := ipWhitelistDecision{outcome: ipWhitelistMatched, addr: addr, country: whitelist.clientCountry(addr)}
+	if !whitelist.allows(addr, decision.country, scopes) {
+		decision.outcome = ipWhitelistNotMatched
+	}
+	return decision, nil
+}
+
+// loadUserIPWhitelist loads the tenant's whitelist and the scopes that apply
+// to userID.
+func loadUserIPWhitelist(ctx context.Context, db *queries.Queries, tenantID, userID pgtype.UUID) (*ipWhitelist, []*queries.GetUserIPWhitelistScopesRow, error) {
+	whitelist, err := loadIPWhitelist(ctx, db, tenantID)
+	if err != nil {
+		return nil, nil, err
+	}
+	scopes, err := loadUserIPWhitelistScopes(ctx, db, whitelist, tenantID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return whitelist, scopes, nil
+}
 
-			logger.LogAccessEvent(logger.AccessEvent{
-				EventType: "ip_whitelist",
-				UserID:    userID.String(),
-				TenantID:  tenantID.String(),
-				IPAddress: clientIP,
-				UserAgent: r.UserAgent(),
-				Timestamp: time.Now(),
-				Success:   true,
-				Error:     "",
-				Feature:   "ip_whitelist",
-			})
+// internalAdminBypass reports whether the tenant lets the internal user in
+// ctx past its whitelist.
+func internalAdminBypass(ctx context.Context) bool {
+	return libctx.GetIPWhitelistConfig(ctx).AllowInternalAdminBypass && libctx.GetIsInternalUser(ctx)
+}
 
-			next.ServeHTTP(w, r)
+// checkIPWhitelist enforces the tenant's active whitelist for the user,
+// reading the tenant's enterprise features and the user's internal flag from
+// ctx. It logs the decision and writes blocks to the audit log. It returns
+// ErrIPNotWhitelisted for a block; any other error means the whitelist could
+// not be evaluated.
+func checkIPWhitelist(ctx context.Context, r *http.Request, db *queries.Queries, tenantID, userID pgtype.UUID) error {
+	clientIP := iputils.ExtractClientIP(r)
+	event := logger.AccessEvent{
+		EventType: "ip_whitelist",
+		UserID:    userID.String(),
+		TenantID:  tenantID.String(),
+		IPAddress: clientIP,
+		UserAgent: r.UserAgent(),
+		Timestamp: time.Now(),
+		Feature:   "ip_whitelist",
+	}
+
+	whitelist, scopes, err := loadUserIPWhitelist(ctx, db, tenantID, userID)
+	if err != nil {
+		event.Error = "Failed to load IP whitelist configuration: " + err.Error()
+		logger.LogAccessEvent(event)
+
+		return fmt.Errorf("failed to load IP whitelist configuration: %w", err)
+	}
+
+	decision, err := decideIPWhitelist(whitelist, scopes, clientIP, internalAdminBypass(ctx))
+	if err != nil {
+		event.Error = "IP validation error: " + err.Error()
+		logger.LogAccessEvent(event)
+
+		return err
+	}
+
+	var country string
+	switch decision.outcome {
+	case ipWhitelistExempted:
+		event.Success = true
+		event.Error = "Exempted by role or user scope"
+	case ipWhitelistBypassed:
+		event.Success = true
+		event.Error = "Internal user bypass enabled"
+	case ipWhitelistEmpty:
+		// No rules configured - deny all access
+		event.Error = "Access denied: No IP addresses configured in whitelist"
+	case ipWhitelistNotMatched:
+		country = decision.country
+		if !whitelist.hasCountryRules() {
+			// Not needed to decide, but recorded with the block.
+			country = geoip.Country(decision.addr)
+		}
+		event.Error = fmt.Sprintf("Access denied: IP %s (country %q) not in whitelist", clientIP, country)
+	default:
+		event.Success = true
+	}
+	logger.LogAccessEvent(event)
+
+	if decision.outcome == ipWhitelistNotMatched && authz.TenantHasFeature(ctx, authz.FeatureAuditLog) {
+		blocked := map[string]string{
+			"blocked_ip": clientIP,
+		}
+		if country != "" {
+			blocked["country"] = country
+		}
+		metadata, _ := json.Marshal(blocked)
+		ipAddr, _ := netip.ParseAddr(clientIP)
+		//nolint:auditcheck // access already denied, audit log is best-effort for denial events
+		if err := db.InsertAuditLog(ctx, &queries.InsertAuditLogParams{
+			TenantID:     tenantID,
+			ActorID:      userID,
+			ResourceType: string(auditlog.ResourceAccess),
+			Action:       string(auditlog.ActionIPBlocked),
+			Outcome:      string(auditlog.OutcomeFailure),
+			ResourceID:   pgtype.Text{},
+			Metadata:     metadata,
+			IpAddress:    &ipAddr,
+			UserAgent:    pgtype.Text{String: r.UserAgent(), Valid: true},
+		}); err != nil {
+			errlib.LogError(fmt.Errorf("IPWhitelistMiddleware: failed to insert audit log: %w", err))
+		}
+	}
+
+	if decision.outcome.blocks() {
+		return ErrIPNotWhitelisted
+	}
+	return nil
+}
+
+// recordWouldBeBlock evaluates a request in monitor mode the way enforcement
+// would and counts it in ip_whitelist_monitor_hits if it would have been
+// blocked. Monitor mode never blocks, so failures are only logged.
+func recordWouldBeBlock(r *http.Request, db *queries.Queries, clientIP string) {
+	ctx := r.Context()
+	tenantID := libctx.GetTenantID(ctx)
+	userID := libctx.GetUserID(ctx)
+
+	whitelist, scopes, err := loadUserIPWhitelist(ctx, db, tenantID, userID)
+	if err != nil {
+		errlib.LogError(fmt.Errorf("IPWhitelistMiddleware: failed to load whitelist for monitor mode: %w", err))
+		return
+	}
+	decision, err := decideIPWhitelist(whitelist, scopes, clientIP, internalAdminBypass(ctx))
+	if err != nil {
+		errlib.LogError(fmt.Errorf("IPWhitelistMiddleware: monitor mode: %w", err))
+		return
+	}
+	if !decision.outcome.blocks() {
+		return
+	}
+
+	// The hit keeps the address as the client sent it, mapped or not.
+	ipAddr, err := netip.ParseAddr(clientIP)
+	if err != nil {
+		errlib.LogError(fmt.Errorf("IPWhitelistMiddleware: invalid client IP %q in monitor mode: %w", clientIP, err))
+		return
+	}
+	err = db.RecordIPWhitelistMonitorHit(ctx, &queries.RecordIPWhitelistMonitorHitParams{
+		TenantID:  tenantID,
+		UserID:    userID,
+		IpAddress: ipAddr,
+	})
+	if err != nil {
+		errlib.LogError(fmt.Errorf("IPWhitelistMiddleware: failed to record monitor hit: %w", err))
+		return
+	}
+
+	logger.LogAccessEvent(logger.AccessEvent{
+		EventType: "ip_whitelist",
+		UserID:    userID.String(),
+		TenantID:  tenantID.String(),
+		IPAddress: clientIP,
+		UserAgent: r.UserAgent(),
+		Timestamp: time.Now(),
+		Success:   true,
+		Error:     fmt.Sprintf("Monitor mode: IP %s not in whitelist", clientIP),
+		Feature:   "ip_whitelist",
+	})
+}
+
+// CheckSignInIPWhitelist enforces the tenant's whitelist on a sign-in: a
+// password login, an SSO callback or a token refresh. These run before
+// LoadTenantAndUserContext, so the tenant's features and the user's internal
+// flag are loaded here. Monitor mode is left to IPWhitelistMiddleware, which
+// records the requests the new session makes. A user with a live emergency
+// deactivation link may sign in from anywhere, since using the link needs a
+// session; the middleware still blocks everything else.
+func CheckSignInIPWhitelist(r *http.Request, db *queries.Queries, tenantID, userID pgtype.UUID) error {
+	ctx := r.Context()
+
+	contextData, err := db.GetTenantAndUserContext(ctx, &queries.GetTenantAndUserContextParams{
+		TenantID: tenantID,
+		UserID:   userID,
+	})
+	if err != nil {
+		return fmt.Errorf("CheckSignInIPWhitelist: failed to get context data: %w", err)
+	}
+	enterpriseFeatures, err := parseEnterpriseFeatures(contextData.EnterpriseFeatures)
+	if err != nil {
+		return fmt.Errorf("CheckSignInIPWhitelist: %w", err)
+	}
+	if !enterpriseFeatures.IPWhitelist.Enabled || !enterpriseFeatures.IPWhitelist.Active {
+		return nil
+	}
+
+	pending, err := db.HasPendingIPWhitelistEmergencyToken(ctx, &queries.HasPendingIPWhitelistEmergencyTokenParams{
+		TenantID: tenantID,
+		UserID:   userID,
+	})
+	if err != nil {
+		return fmt.Errorf("CheckSignInIPWhitelist: failed to check emergency tokens: %w", err)
+	}
+	if pending {
+		logger.LogAccessEvent(logger.AccessEvent{
+			EventType: "ip_whitelist",
+			UserID:    userID.String(),
+			TenantID:  tenantID.String(),
+			IPAddress: iputils.ExtractClientIP(r),
+			UserAgent: r.UserAgent(),
+			Timestamp: time.Now(),
+			Success:   true,
+			Error:     "Sign-in allowed: emergency deactivation link pending",
+			Feature:   "ip_whitelist",
 		})
+		return nil
 	}
+
+	ctx = libctx.WithEnterpriseFeatures(ctx, enterpriseFeatures)
+	ctx = libctx.WithIsInternalUser(ctx, contextData.IsInternalUser)
+	return checkIPWhitelist(ctx, r, db, tenantID, userID)
 }
@@ -0,0 +1,319 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"dislyze/jirachi/errlib"
+	"lugia/lib/authz"
+	"lugia/lib/geoip"
+	"lugia/lib/iputils"
+	"lugia/queries"
+)
+
+// ipWhitelistChannel is the Postgres channel the tenant_ip_whitelist trigger
+// notifies with the tenant ID of every changed rule.
+const ipWhitelistChannel = "ip_whitelist_changed"
+
+// Country rule actions, as stored in tenant_ip_whitelist_countries.action.
+const (
+	CountryRuleAllow = "allow"
+	CountryRuleDeny  = "deny"
+)
+
+// maxListenBackoff caps the wait between attempts to re-establish LISTEN.
+const maxListenBackoff = 30 * time.Second
+
+// ipWhitelist is a tenant's compiled whitelist: the tenant rules and country
+// rules, which apply to everyone, and the rules of each role or user scope.
+type ipWhitelist struct {
+	tenant *iputils.CIDRMatcher
+	// allowedCountries and deniedCountries hold ISO country codes.
+	allowedCountries map[string]bool
+	deniedCountries  map[string]bool
+	scopes           map[pgtype.UUID]*iputils.CIDRMatcher
+	// hasScopes is false when the tenant has no scopes at all, which spares
+	// the middleware looking up the user's.
+	hasScopes bool
+}
+
+// empty reports whether nothing in the tenant-wide rules admits anyone, in
+// which case every request is denied.
+func (w *ipWhitelist) empty() bool {
+	return w.tenant.Len() == 0 && len(w.allowedCountries) == 0
+}
+
+// hasCountryRules reports whether the client's country has to be resolved to
+// evaluate the whitelist.
+func (w *ipWhitelist) hasCountryRules() bool {
+	return len(w.allowedCountries) > 0 || len(w.deniedCountries) > 0
+}
+
+// allows reports whether addr, located in country ("" if unknown), may be
+// used by a user to whom the given scopes apply. A denied country blocks even
+// addresses the CIDR rules match; otherwise the address must match a CIDR
+// rule or be located in an allowed country. A user scope overrides the
+// user's role scopes. Exempt scopes are handled by the caller before this;
+// here every scope restricts, so the address must also match the rules of at
+// least one scope.
+func (w *ipWhitelist) allows(addr netip.Addr, country string, scopes []*queries.GetUserIPWhitelistScopesRow) bool {
+	if w.deniedCountries[country] {
+		return false
+	}
+	if !w.tenant.Contains(addr) && !w.allowedCountries[country] {
+		return false
+	}
+
+	scopes = effectiveIPWhitelistScopes(scopes)
+	if len(scopes) == 0 {
+		return true
+	}
+	for _, scope := range scopes {
+		if matcher, ok := w.scopes[scope.ID]; ok && matcher.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// effectiveIPWhitelistScopes narrows the scopes that apply to a user to their
+// own scope, if they have one.
+func effectiveIPWhitelistScopes(scopes []*queries.GetUserIPWhitelistScopesRow) []*queries.GetUserIPWhitelistScopesRow {
+	for _, scope := range scopes {
+		if scope.IsUserScope {
+			return []*queries.GetUserIPWhitelistScopesRow{scope}
+		}
+	}
+	return scopes
+}
+
+// ipWhitelistExempt reports whether the scopes that apply to a user exempt
+// them from the whitelist.
+func ipWhitelistExempt(scopes []*queries.GetUserIPWhitelistScopesRow) bool {
+	for _, scope := range effectiveIPWhitelistScopes(scopes) {
+		if scope.Exempt {
+			return true
+		}
+	}
+	return false
+}
+
+type ipWhitelistCacheEntry struct {
+	whitelist *ipWhitelist
+	// lapsesAt is when the first time-bound rule in the whitelist expires;
+	// zero if none does.
+	lapsesAt time.Time
+}
+
+// ipWhitelistCache holds each tenant's compiled whitelist. It has no TTL:
+// entries live until a notification for their tenant arrives or one of their
+// rules expires. That is only safe while notifications are arriving, so the
+// cache serves nothing unless the listener is connected, and everything
+// cached is dropped when it disconnects. As with the permission cache, each
+// tenant has a generation so a load that raced an invalidation is discarded.
+type ipWhitelistCache struct {
+	mu          sync.Mutex
+	now         func() time.Time
+	listening   bool
+	entries     map[pgtype.UUID]ipWhitelistCacheEntry
+	generations map[pgtype.UUID]uint64
+}
+
+func newIPWhitelistCache(now func() time.Time) *ipWhitelistCache {
+	return &ipWhitelistCache{
+		now:         now,
+		entries:     map[pgtype.UUID]ipWhitelistCacheEntry{},
+		generations: map[pgtype.UUID]uint64{},
+	}
+}
+
+var ipWhitelists = newIPWhitelistCache(time.Now)
+
+func (c *ipWhitelistCache) get(tenantID pgtype.UUID) (*ipWhitelist, uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[tenantID]
+	if ok && c.listening && (entry.lapsesAt.IsZero() || c.now().Before(entry.lapsesAt)) {
+		return entry.whitelist, 0, true
+	}
+	if ok {
+		delete(c.entries, tenantID)
+	}
+	return nil, c.generations[tenantID], false
+}
+
+func (c *ipWhitelistCache) put(tenantID pgtype.UUID, generation uint64, entry ipWhitelistCacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.listening || c.generations[tenantID] != generation {
+		return
+	}
+	c.entries[tenantID] = entry
+}
+
+func (c *ipWhitelistCache) invalidateTenant(tenantID pgtype.UUID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generations[tenantID]++
+	delete(c.entries, tenantID)
+}
+
+// setListening switches caching on or off. Either way the cache starts
+// empty: entries from before a disconnect may have missed notifications.
+func (c *ipWhitelistCache) setListening(listening bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.listening = listening
+	for tenantID := range c.generations {
+		c.generations[tenantID]++
+	}
+	c.entries = map[pgtype.UUID]ipWhitelistCacheEntry{}
+}
+
+// loadIPWhitelist returns the tenant's compiled whitelist, loading and
+// compiling it unless a cached one is still valid.
+func loadIPWhitelist(ctx context.Context, db *queries.Queries, tenantID pgtype.UUID) (*ipWhitelist, error) {
+	whitelist, generation, ok := ipWhitelists.get(tenantID)
+	if ok {
+		return whitelist, nil
+	}
+
+	rules, err := db.GetIPWhitelistForMiddleware(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	countries, err := db.GetTenantIPWhitelistCountries(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	hasScopes, err := db.TenantHasIPWhitelistScopes(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var tenantCIDRs []string
+	scopeCIDRs := map[pgtype.UUID][]string{}
+	var lapsesAt time.Time
+	for _, rule := range rules {
+		if rule.ScopeID.Valid {
+			scopeCIDRs[rule.ScopeID] = append(scopeCIDRs[rule.ScopeID], rule.IpAddress)
+		} else {
+			tenantCIDRs = append(tenantCIDRs, rule.IpAddress)
+		}
+		if rule.ExpiresAt.Valid && (lapsesAt.IsZero() || rule.ExpiresAt.Time.Before(lapsesAt)) {
+			lapsesAt = rule.ExpiresAt.Time
+		}
+	}
+
+	whitelist = &ipWhitelist{
+		tenant:           iputils.NewCIDRMatcher(tenantCIDRs),
+		allowedCountries: map[string]bool{},
+		deniedCountries:  map[string]bool{},
+		scopes:           make(map[pgtype.UUID]*iputils.CIDRMatcher, len(scopeCIDRs)),
+		hasScopes:        hasScopes,
+	}
+	for scopeID, cidrs := range scopeCIDRs {
+		whitelist.scopes[scopeID] = iputils.NewCIDRMatcher(cidrs)
+	}
+	for _, country := range countries {
+		if country.Action == CountryRuleDeny {
+			whitelist.deniedCountries[country.CountryCode] = true
+		} else {
+			whitelist.allowedCountries[country.CountryCode] = true
+		}
+	}
+
+	ipWhitelists.put(tenantID, generation, ipWhitelistCacheEntry{whitelist: whitelist, lapsesAt: lapsesAt})
+	return whitelist, nil
+}
+
+// clientCountry resolves the country addr is located in when the whitelist
+// has country rules to evaluate, and returns "" otherwise.
+func (w *ipWhitelist) clientCountry(addr netip.Addr) string {
+	if !w.hasCountryRules() {
+		return ""
+	}
+	return geoip.Country(addr)
+}
+
+// loadUserIPWhitelistScopes returns the scopes that apply to the user. They
+// aren't cached: role assignments don't notify, and most tenants have no
+// scopes, in which case nothing is queried.
+func loadUserIPWhitelistScopes(ctx context.Context, db *queries.Queries, whitelist *ipWhitelist, tenantID, userID pgtype.UUID) ([]*queries.GetUserIPWhitelistScopesRow, error) {
+	if !whitelist.hasScopes {
+		return nil, nil
+	}
+	return db.GetUserIPWhitelistScopes(ctx, &queries.GetUserIPWhitelistScopesParams{
+		TenantID:    tenantID,
+		UserID:      userID,
+		RbacEnabled: authz.TenantHasFeature(ctx, authz.FeatureRBAC),
+	})
+}
+
+// ListenForIPWhitelistChanges keeps a connection listening for whitelist
+// changes made by any instance and drops the changed tenant's cached matcher.
+// It runs until ctx is cancelled, reconnecting with backoff if the
+// connection fails; while it is down, the middleware loads rules on every
+// request as it did before the cache existed.
+func ListenForIPWhitelistChanges(ctx context.Context, pool *pgxpool.Pool) {
+	backoff := time.Second
+	for {
+		err := listenForIPWhitelistChanges(ctx, pool)
+		ipWhitelists.setListening(false)
+		if ctx.Err() != nil {
+			return
+		}
+		errlib.LogError(fmt.Errorf("ListenForIPWhitelistChanges: listener stopped, retrying in %s: %w", backoff, err))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxListenBackoff)
+	}
+}
+
+func listenForIPWhitelistChanges(ctx context.Context, pool *pgxpool.Pool) error {
+	pooled, err := pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	// The connection keeps its LISTEN for as long as it lives, so it is taken
+	// out of the pool rather than handed back to other queries.
+	conn := pooled.Hijack()
+	defer func() {
+		if err := conn.Close(context.Background()); err != nil {
+			errlib.LogError(fmt.Errorf("ListenForIPWhitelistChanges: failed to close connection: %w", err))
+		}
+	}()
+
+	if _, err := conn.Exec(ctx, "LISTEN "+ipWhitelistChannel); err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", ipWhitelistChannel, err)
+	}
+	ipWhitelists.setListening(true)
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to wait for notification: %w", err)
+		}
+
+		var tenantID pgtype.UUID
+		if err := tenantID.Scan(notification.Payload); err != nil {
+			errlib.LogError(fmt.Errorf("ListenForIPWhitelistChanges: invalid tenant ID %q: %w", notification.Payload, err))
+			continue
+		}
+		ipWhitelists.invalidateTenant(tenantID)
+	}
+}
@@ -0,0 +1,219 @@
+package middleware
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"lugia/lib/iputils"
+	"lugia/queries"
+)
+
+func TestIPWhitelistCache(t *testing.T) {
+	now := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	c := newIPWhitelistCache(func() time.Time { return now })
+
+	tenantA := pgtype.UUID{Bytes: [16]byte{1}, Valid: true}
+	tenantB := pgtype.UUID{Bytes: [16]byte{2}, Valid: true}
+	entry := ipWhitelistCacheEntry{whitelist: &ipWhitelist{tenant: iputils.NewCIDRMatcher([]string{"192.168.1.0/24"})}}
+
+	t.Run("nothing is cached until the listener connects", func(t *testing.T) {
+		_, generation, _ := c.get(tenantA)
+		c.put(tenantA, generation, entry)
+
+		if _, _, ok := c.get(tenantA); ok {
+			t.Error("get hit while not listening")
+		}
+	})
+
+	c.setListening(true)
+
+	t.Run("a stored whitelist is served while listening", func(t *testing.T) {
+		_, generation, ok := c.get(tenantA)
+		if ok {
+			t.Fatal("get on an empty cache hit")
+		}
+		c.put(tenantA, generation, entry)
+
+		whitelist, _, ok := c.get(tenantA)
+		if !ok {
+			t.Fatal("get right after put missed")
+		}
+		if whitelist != entry.whitelist {
+			t.Error("get returned a different whitelist")
+		}
+
+		now = now.Add(24 * time.Hour)
+		if _, _, ok := c.get(tenantA); !ok {
+			t.Error("an entry without expiring rules lapsed")
+		}
+	})
+
+	t.Run("an entry ends when its earliest rule expires", func(t *testing.T) {
+		c.invalidateTenant(tenantA)
+		_, generation, _ := c.get(tenantA)
+		c.put(tenantA, generation, ipWhitelistCacheEntry{whitelist: entry.whitelist, lapsesAt: now.Add(5 * time.Second)})
+
+		now = now.Add(5 * time.Second)
+		if _, _, ok := c.get(tenantA); ok {
+			t.Error("get after a rule expired hit")
+		}
+	})
+
+	t.Run("invalidation drops only that tenant", func(t *testing.T) {
+		_, generationA, _ := c.get(tenantA)
+		c.put(tenantA, generationA, entry)
+		_, generationB, _ := c.get(tenantB)
+		c.put(tenantB, generationB, entry)
+
+		c.invalidateTenant(tenantA)
+
+		if _, _, ok := c.get(tenantA); ok {
+			t.Error("invalidated tenant still hit")
+		}
+		if _, _, ok := c.get(tenantB); !ok {
+			t.Error("other tenant was dropped")
+		}
+	})
+
+	t.Run("a load that started before invalidation is not stored", func(t *testing.T) {
+		_, generation, _ := c.get(tenantA)
+		c.invalidateTenant(tenantA)
+		c.put(tenantA, generation, entry)
+
+		if _, _, ok := c.get(tenantA); ok {
+			t.Error("stale load was stored")
+		}
+	})
+
+	t.Run("disconnecting drops everything and discards loads in flight", func(t *testing.T) {
+		_, generation, _ := c.get(tenantA)
+		c.setListening(false)
+		c.setListening(true)
+		c.put(tenantA, generation, entry)
+
+		if _, _, ok := c.get(tenantA); ok {
+			t.Error("load from before the reconnect was stored")
+		}
+		if _, _, ok := c.get(tenantB); ok {
+			t.Error("entry survived a disconnect")
+		}
+	})
+}
+
+func TestIPWhitelistScopes(t *testing.T) {
+	salesRole := pgtype.UUID{Bytes: [16]byte{1}, Valid: true}
+	execRole := pgtype.UUID{Bytes: [16]byte{2}, Valid: true}
+	userScope := pgtype.UUID{Bytes: [16]byte{3}, Valid: true}
+	emptyRole := pgtype.UUID{Bytes: [16]byte{4}, Valid: true}
+
+	whitelist := &ipWhitelist{
+		tenant: iputils.NewCIDRMatcher([]string{"10.0.0.0/8", "192.168.0.0/16"}),
+		scopes: map[pgtype.UUID]*iputils.CIDRMatcher{
+			salesRole: iputils.NewCIDRMatcher([]string{"10.1.0.0/16"}),
+			userScope: iputils.NewCIDRMatcher([]string{"192.168.5.0/24"}),
+		},
+		hasScopes: true,
+	}
+
+	restrict := func(id pgtype.UUID) *queries.GetUserIPWhitelistScopesRow {
+		return &queries.GetUserIPWhitelistScopesRow{ID: id}
+	}
+	exempt := func(id pgtype.UUID) *queries.GetUserIPWhitelistScopesRow {
+		return &queries.GetUserIPWhitelistScopesRow{ID: id, Exempt: true}
+	}
+	user := func(scope *queries.GetUserIPWhitelistScopesRow) *queries.GetUserIPWhitelistScopesRow {
+		scope.IsUserScope = true
+		return scope
+	}
+
+	tests := []struct {
+		name       string
+		ip         string
+		scopes     []*queries.GetUserIPWhitelistScopesRow
+		wantExempt bool
+		wantAllow  bool
+	}{
+		{name: "no scopes uses the tenant rules", ip: "192.168.1.1", wantAllow: true},
+		{name: "no scopes outside the tenant rules", ip: "172.16.0.1", wantAllow: false},
+		{name: "role scope narrows the tenant rules", ip: "10.2.0.1", scopes: []*queries.GetUserIPWhitelistScopesRow{restrict(salesRole)}, wantAllow: false},
+		{name: "role scope allows its own ranges", ip: "10.1.2.3", scopes: []*queries.GetUserIPWhitelistScopesRow{restrict(salesRole)}, wantAllow: true},
+		{name: "scope rules outside the tenant rules don't widen them", ip: "10.1.2.3", scopes: []*queries.GetUserIPWhitelistScopesRow{restrict(userScope)}, wantAllow: false},
+		{name: "a scope without rules blocks its members", ip: "10.1.2.3", scopes: []*queries.GetUserIPWhitelistScopesRow{restrict(emptyRole)}, wantAllow: false},
+		{name: "any of several role scopes is enough", ip: "10.1.2.3", scopes: []*queries.GetUserIPWhitelistScopesRow{restrict(emptyRole), restrict(salesRole)}, wantAllow: true},
+		{name: "an exempt role exempts", ip: "172.16.0.1", scopes: []*queries.GetUserIPWhitelistScopesRow{restrict(salesRole), exempt(execRole)}, wantExempt: true},
+		{name: "a user scope overrides an exempt role", ip: "10.1.2.3", scopes: []*queries.GetUserIPWhitelistScopesRow{exempt(execRole), user(restrict(userScope))}, wantAllow: false},
+		{name: "a user scope overrides a role scope", ip: "192.168.5.9", scopes: []*queries.GetUserIPWhitelistScopesRow{restrict(salesRole), user(restrict(userScope))}, wantAllow: true},
+		{name: "an exempt user overrides a restricting role", ip: "172.16.0.1", scopes: []*queries.GetUserIPWhitelistScopesRow{restrict(salesRole), user(exempt(userScope))}, wantExempt: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ipWhitelistExempt(tt.scopes); got != tt.wantExempt {
+				t.Fatalf("ipWhitelistExempt() = %v, want %v", got, tt.wantExempt)
+			}
+			if tt.wantExempt {
+				return
+			}
+			if got := whitelist.allows(netip.MustParseAddr(tt.ip), "", tt.scopes); got != tt.wantAllow {
+				t.Errorf("allows(%s) = %v, want %v", tt.ip, got, tt.wantAllow)
+			}
+		})
+	}
+}
+
+func TestIPWhitelistCountries(t *testing.T) {
+	sales := pgtype.UUID{Bytes: [16]byte{1}, Valid: true}
+
+	whitelist := &ipWhitelist{
+		tenant:           iputils.NewCIDRMatcher([]string{"0.0.0.0/0", "10.0.0.0/8"}),
+		allowedCountries: map[string]bool{},
+		deniedCountries:  map[string]bool{"CN": true},
+		scopes: map[pgtype.UUID]*iputils.CIDRMatcher{
+			sales: iputils.NewCIDRMatcher([]string{"10.1.0.0/16"}),
+		},
+	}
+	countryOnly := &ipWhitelist{
+		tenant:           iputils.NewCIDRMatcher(nil),
+		allowedCountries: map[string]bool{"JP": true, "US": true},
+		deniedCountries:  map[string]bool{},
+	}
+
+	if whitelist.empty() || countryOnly.empty() {
+		t.Fatal("empty() = true for a whitelist with rules")
+	}
+	denyOnly := &ipWhitelist{tenant: iputils.NewCIDRMatcher(nil), deniedCountries: map[string]bool{"CN": true}}
+	if !denyOnly.empty() {
+		t.Error("empty() = false for a whitelist with only denied countries")
+	}
+
+	restrictSales := []*queries.GetUserIPWhitelistScopesRow{{ID: sales}}
+
+	tests := []struct {
+		name      string
+		whitelist *ipWhitelist
+		ip        string
+		country   string
+		scopes    []*queries.GetUserIPWhitelistScopesRow
+		want      bool
+	}{
+		{name: "allowed country", whitelist: countryOnly, ip: "203.0.113.1", country: "JP", want: true},
+		{name: "other country", whitelist: countryOnly, ip: "203.0.113.1", country: "DE", want: false},
+		{name: "unknown country matches no country rule", whitelist: countryOnly, ip: "10.0.0.1", country: "", want: false},
+		{name: "cidr rule outside denied country", whitelist: whitelist, ip: "203.0.113.1", country: "JP", want: true},
+		{name: "denied country beats a cidr rule", whitelist: whitelist, ip: "203.0.113.1", country: "CN", want: false},
+		{name: "unknown country isn't denied", whitelist: whitelist, ip: "203.0.113.1", country: "", want: true},
+		{name: "scope still narrows", whitelist: whitelist, ip: "10.2.0.1", country: "", scopes: restrictSales, want: false},
+		{name: "denied country applies to scoped users", whitelist: whitelist, ip: "10.1.0.1", country: "CN", scopes: restrictSales, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.whitelist.allows(netip.MustParseAddr(tt.ip), tt.country, tt.scopes); got != tt.want {
+				t.Errorf("allows(%s, %q) = %v, want %v", tt.ip, tt.country, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"lugia/lib/iputils"
+	"lugia/queries"
+)
+
+func TestDecideIPWhitelist(t *testing.T) {
+	office := &ipWhitelist{tenant: iputils.NewCIDRMatcher([]string{"203.0.113.0/24"})}
+	empty := &ipWhitelist{tenant: iputils.NewCIDRMatcher(nil)}
+	exempt := []*queries.GetUserIPWhitelistScopesRow{{ID: pgtype.UUID{Bytes: [16]byte{1}, Valid: true}, Exempt: true}}
+
+	tests := []struct {
+		name      string
+		whitelist *ipWhitelist
+		scopes    []*queries.GetUserIPWhitelistScopesRow
+		clientIP  string
+		bypass    bool
+		expected  ipWhitelistOutcome
+		wantErr   bool
+	}{
+		{name: "a listed address is let in", whitelist: office, clientIP: "203.0.113.7", expected: ipWhitelistMatched},
+		{name: "an IPv4-mapped listed address is let in", whitelist: office, clientIP: "::ffff:203.0.113.7", expected: ipWhitelistMatched},
+		{name: "an unlisted address is blocked", whitelist: office, clientIP: "198.51.100.7", expected: ipWhitelistNotMatched},
+		{name: "an exempt scope wins over an empty list", whitelist: empty, scopes: exempt, clientIP: "198.51.100.7", expected: ipWhitelistExempted},
+		{name: "an empty list blocks the internal admin bypass", whitelist: empty, clientIP: "198.51.100.7", bypass: true, expected: ipWhitelistEmpty},
+		{name: "the internal admin bypass skips the rules", whitelist: office, clientIP: "198.51.100.7", bypass: true, expected: ipWhitelistBypassed},
+		{name: "an exempt user needs no valid address", whitelist: office, scopes: exempt, clientIP: "unknown", expected: ipWhitelistExempted},
+		{name: "an invalid address can't be matched", whitelist: office, clientIP: "unknown", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision, err := decideIPWhitelist(tt.whitelist, tt.scopes, tt.clientIP, tt.bypass)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decideIPWhitelist() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && decision.outcome != tt.expected {
+				t.Errorf("decideIPWhitelist() outcome = %v, want %v", decision.outcome, tt.expected)
+			}
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"dislyze/jirachi/logger"
 	"encoding/json"
 	"fmt"
+	"lugia/lib/authz"
 	"lugia/queries"
 	"net/http"
 	"time"
@@ -19,6 +20,7 @@ import (
 // This includes:
 // - Tenant enterprise features (RBAC, IP whitelist, etc.)
 // - User is_internal_user flag
+// - The user's permission set, checked in memory by the Require* middlewares
 func LoadTenantAndUserContext(db *queries.Queries) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -81,9 +83,28 @@ func LoadTenantAndUserContext(db *queries.Queries) func(http.Handler) http.Handl
 				return
 			}
 
+			permissions, err := authz.LoadUserPermissions(ctx, db, tenantID, userID, enterpriseFeatures.RBAC.Enabled)
+			if err != nil {
+				logger.LogAccessEvent(logger.AccessEvent{
+					EventType: "middleware",
+					UserID:    userID.String(),
+					TenantID:  tenantID.String(),
+					IPAddress: r.RemoteAddr,
+					UserAgent: r.UserAgent(),
+					Timestamp: time.Now(),
+					Success:   false,
+					Error:     "Failed to load permissions: " + err.Error(),
+				})
+
+				errlib.LogError(fmt.Errorf("LoadContext: %w", err))
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+
 			// Store enterprise features and user metadata in context for downstream middlewares and handlers
 			newCtx := libctx.WithEnterpriseFeatures(ctx, enterpriseFeatures)
 			newCtx = libctx.WithIsInternalUser(newCtx, contextData.IsInternalUser)
+			newCtx = libctx.WithPermissions(newCtx, permissions)
 
 			next.ServeHTTP(w, r.WithContext(newCtx))
 		})
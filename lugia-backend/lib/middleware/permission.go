@@ -18,49 +18,11 @@ import (
 	"lugia/queries"
 )
 
-func RequirePermission(db *queries.Queries, resource authz.Resource, action string) func(http.Handler) http.Handler {
+func RequirePermission(db *queries.Queries, permission authz.Permission) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if !authz.UserHasPermission(r.Context(), db, resource, action) {
-				userID := libctx.GetUserID(r.Context())
-				tenantID := libctx.GetTenantID(r.Context())
-
-				logger.LogAccessEvent(logger.AccessEvent{
-					EventType: "permission",
-					UserID:    userID.String(),
-					TenantID:  tenantID.String(),
-					IPAddress: r.RemoteAddr,
-					UserAgent: r.UserAgent(),
-					Timestamp: time.Now(),
-					Success:   false,
-					Error:     fmt.Sprintf("Permission required. resource: %s, action: %s", resource, action),
-					Resource:  resource.String(),
-					Action:    action,
-				})
-
-				if authz.TenantHasFeature(r.Context(), authz.FeatureAuditLog) {
-					metadata, _ := json.Marshal(map[string]string{
-						"resource": resource.String(),
-						"action":   action,
-					})
-					ipAddr, _ := netip.ParseAddr(iputils.ExtractClientIP(r))
-					//nolint:auditcheck // access already denied (403), audit log is best-effort for denial events
-					if err := db.InsertAuditLog(r.Context(), &queries.InsertAuditLogParams{
-						TenantID:     tenantID,
-						ActorID:      userID,
-						ResourceType: string(auditlog.ResourceAccess),
-						Action:       string(auditlog.ActionPermissionDenied),
-						Outcome:      string(auditlog.OutcomeFailure),
-						ResourceID:   pgtype.Text{},
-						Metadata:     metadata,
-						IpAddress:    &ipAddr,
-						UserAgent:    pgtype.Text{String: r.UserAgent(), Valid: true},
-					}); err != nil {
-						errlib.LogError(fmt.Errorf("RequirePermission: failed to insert audit log: %w", err))
-					}
-				}
-
-				w.WriteHeader(http.StatusForbidden)
+			if !authz.UserHasPermission(r.Context(), permission) {
+				denyPermission(db, permission, w, r)
 				return
 			}
 
@@ -69,34 +31,133 @@ func RequirePermission(db *queries.Queries, resource authz.Resource, action stri
 	}
 }
 
+// RequirePermissionOrGroupAdmin also lets group admins through, with an
+// authz.UserScope in the context. Handlers behind it must check their target
+// with authz.UserInScope and authz.RolesWithinScope; the middleware alone
+// doesn't limit a group admin to their groups.
+func RequirePermissionOrGroupAdmin(db *queries.Queries, permission authz.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			if authz.UserHasPermission(ctx, permission) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			scope, err := authz.LoadUserScope(ctx, db, libctx.GetTenantID(ctx), libctx.GetUserID(ctx))
+			if err != nil {
+				errlib.LogError(fmt.Errorf("RequirePermissionOrGroupAdmin: %w", err))
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			if scope == nil {
+				denyPermission(db, permission, w, r)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(authz.WithUserScope(ctx, scope)))
+		})
+	}
+}
+
+func denyPermission(db *queries.Queries, permission authz.Permission, w http.ResponseWriter, r *http.Request) {
+	userID := libctx.GetUserID(r.Context())
+	tenantID := libctx.GetTenantID(r.Context())
+
+	logger.LogAccessEvent(logger.AccessEvent{
+		EventType: "permission",
+		UserID:    userID.String(),
+		TenantID:  tenantID.String(),
+		IPAddress: r.RemoteAddr,
+		UserAgent: r.UserAgent(),
+		Timestamp: time.Now(),
+		Success:   false,
+		Error:     fmt.Sprintf("Permission required. resource: %s, action: %s", permission.Resource, permission.Action),
+		Resource:  permission.Resource.String(),
+		Action:    permission.Action,
+	})
+
+	if authz.TenantHasFeature(r.Context(), authz.FeatureAuditLog) {
+		metadata, _ := json.Marshal(map[string]string{
+			"resource": permission.Resource.String(),
+			"action":   permission.Action,
+		})
+		ipAddr, _ := netip.ParseAddr(iputils.ExtractClientIP(r))
+		//nolint:auditcheck // access already denied (403), audit log is best-effort for denial events
+		if err := db.InsertAuditLog(r.Context(), &queries.InsertAuditLogParams{
+			TenantID:     tenantID,
+			ActorID:      userID,
+			ResourceType: string(auditlog.ResourceAccess),
+			Action:       string(auditlog.ActionPermissionDenied),
+			Outcome:      string(auditlog.OutcomeFailure),
+			ResourceID:   pgtype.Text{},
+			Metadata:     metadata,
+			IpAddress:    &ipAddr,
+			UserAgent:    pgtype.Text{String: r.UserAgent(), Valid: true},
+		}); err != nil {
+			errlib.LogError(fmt.Errorf("RequirePermission: failed to insert audit log: %w", err))
+		}
+	}
+
+	w.WriteHeader(http.StatusForbidden)
+}
+
 func RequireTenantEdit(db *queries.Queries) func(http.Handler) http.Handler {
-	return RequirePermission(db, authz.ResourceTenant, authz.ActionEdit)
+	return RequirePermission(db, authz.PermTenantEdit)
 }
 
 func RequireUsersView(db *queries.Queries) func(http.Handler) http.Handler {
-	return RequirePermission(db, authz.ResourceUsers, authz.ActionView)
+	return RequirePermission(db, authz.PermUsersView)
 }
 
 func RequireUsersEdit(db *queries.Queries) func(http.Handler) http.Handler {
-	return RequirePermission(db, authz.ResourceUsers, authz.ActionEdit)
+	return RequirePermission(db, authz.PermUsersEdit)
+}
+
+func RequireUsersInvite(db *queries.Queries) func(http.Handler) http.Handler {
+	return RequirePermission(db, authz.PermUsersInvite)
+}
+
+func RequireUsersDelete(db *queries.Queries) func(http.Handler) http.Handler {
+	return RequirePermission(db, authz.PermUsersDelete)
+}
+
+func RequireUsersAssignRoles(db *queries.Queries) func(http.Handler) http.Handler {
+	return RequirePermission(db, authz.PermUsersAssignRoles)
+}
+
+func RequireUsersInviteInScope(db *queries.Queries) func(http.Handler) http.Handler {
+	return RequirePermissionOrGroupAdmin(db, authz.PermUsersInvite)
+}
+
+func RequireUsersDeleteInScope(db *queries.Queries) func(http.Handler) http.Handler {
+	return RequirePermissionOrGroupAdmin(db, authz.PermUsersDelete)
+}
+
+func RequireUsersAssignRolesInScope(db *queries.Queries) func(http.Handler) http.Handler {
+	return RequirePermissionOrGroupAdmin(db, authz.PermUsersAssignRoles)
 }
 
 func RequireRolesView(db *queries.Queries) func(http.Handler) http.Handler {
-	return RequirePermission(db, authz.ResourceRoles, authz.ActionView)
+	return RequirePermission(db, authz.PermRolesView)
 }
 
 func RequireRolesEdit(db *queries.Queries) func(http.Handler) http.Handler {
-	return RequirePermission(db, authz.ResourceRoles, authz.ActionEdit)
+	return RequirePermission(db, authz.PermRolesEdit)
 }
 
 func RequireIPWhitelistView(db *queries.Queries) func(http.Handler) http.Handler {
-	return RequirePermission(db, authz.ResourceIPWhitelist, authz.ActionView)
+	return RequirePermission(db, authz.PermIPWhitelistView)
 }
 
 func RequireIPWhitelistEdit(db *queries.Queries) func(http.Handler) http.Handler {
-	return RequirePermission(db, authz.ResourceIPWhitelist, authz.ActionEdit)
+	return RequirePermission(db, authz.PermIPWhitelistEdit)
+}
+
+func RequireIPWhitelistEmergency(db *queries.Queries) func(http.Handler) http.Handler {
+	return RequirePermission(db, authz.PermIPWhitelistEmergency)
 }
 
 func RequireAuditLogView(db *queries.Queries) func(http.Handler) http.Handler {
-	return RequirePermission(db, authz.ResourceAuditLog, authz.ActionView)
+	return RequirePermission(db, authz.PermAuditLogView)
 }
@@ -0,0 +1,40 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// Cursor marks the last row of a keyset-paginated page. Sort and Order travel
+// with it so a cursor can't be replayed against a different ordering, where
+// the (value, id) comparison would silently skip or repeat rows.
+type Cursor struct {
+	Sort  string `json:"s"`
+	Order string `json:"o"`
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+func EncodeCursor(c Cursor) (string, error) {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("EncodeCursor: failed to marshal cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func DecodeCursor(s string) (*Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("DecodeCursor: invalid encoding: %w", err)
+	}
+	var c Cursor
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("DecodeCursor: invalid payload: %w", err)
+	}
+	if c.Sort == "" || c.Order == "" || c.ID == "" {
+		return nil, fmt.Errorf("DecodeCursor: missing fields")
+	}
+	return &c, nil
+}
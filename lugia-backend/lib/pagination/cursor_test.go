@@ -0,0 +1,48 @@
+package pagination
+
+import (
+	"testing"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	in := Cursor{
+		Sort:  "name",
+		Order: "asc",
+		Value: "山田 太郎",
+		ID:    "a0000000-0000-0000-0000-000000000001",
+	}
+
+	encoded, err := EncodeCursor(in)
+	if err != nil {
+		t.Fatalf("EncodeCursor() unexpected error: %v", err)
+	}
+
+	out, err := DecodeCursor(encoded)
+	if err != nil {
+		t.Fatalf("DecodeCursor() unexpected error: %v", err)
+	}
+
+	if *out != in {
+		t.Errorf("DecodeCursor() = %+v, want %+v", *out, in)
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"Empty string", ""},
+		{"Not base64", "!!!"},
+		{"Not JSON", "bm90LWpzb24"},
+		{"Missing fields", "eyJzIjoibmFtZSJ9"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeCursor(tt.input); err == nil {
+				t.Errorf("DecodeCursor(%q) expected error but got none", tt.input)
+			}
+		})
+	}
+}
@@ -14,13 +14,19 @@ import (
 	"syscall"
 	"time"
 
+	"lugia/features/access_requests"
 	"lugia/features/audit_logs"
 	"lugia/features/auth"
 	"lugia/features/ip_whitelist"
 	"lugia/features/roles"
+	"lugia/features/user_groups"
 	"lugia/features/users"
+	"lugia/lib/authz"
 	"lugia/lib/config"
 	"lugia/lib/db"
+	"lugia/lib/geoip"
+	"lugia/lib/iputils"
+	"lugia/lib/maintenance"
 	"lugia/lib/middleware"
 
 	"dislyze/jirachi/errlib"
@@ -33,6 +39,7 @@ import (
 	"github.com/danielgtaylor/huma/v2/adapters/humachi"
 	"github.com/go-chi/chi/v5"
 	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -58,15 +65,21 @@ func SetupRoutes(dbConn *pgxpool.Pool, env *config.Env, queries *queries.Queries
 	deleteUserRateLimiter := ratelimit.NewRateLimiter("lugia", 1*time.Minute, 10)
 	changeEmailRateLimiter := ratelimit.NewRateLimiter("lugia", 30*time.Minute, 1)
 	ipWhitelistRateLimiter := ratelimit.NewRateLimiter("lugia", 10*time.Minute, 30)
+	breakGlassRateLimiter := ratelimit.NewRateLimiter("lugia", 10*time.Minute, 10)
 
 	authConfig := config.NewLugiaAuthConfig(env)
 	jirachiAuthMiddleware := jirachi_auth.NewAuthMiddleware(authConfig, dbConn, authRateLimiter)
+	jirachiAuthMiddleware.SetTenantAccessCheck(func(r *http.Request, tenantID, userID pgtype.UUID) error {
+		return middleware.CheckSignInIPWhitelist(r, queries, tenantID, userID)
+	})
 
 	authHandler := auth.NewAuthHandler(dbConn, env, authRateLimiter, queries)
 	usersHandler := users.NewUsersHandler(dbConn, queries, env, resendInviteRateLimiter, deleteUserRateLimiter, changeEmailRateLimiter)
 	rolesHandler := roles.NewRolesHandler(dbConn, queries, env)
-	ipWhitelistHandler := ip_whitelist.NewIPWhitelistHandler(dbConn, queries, env, ipWhitelistRateLimiter)
+	ipWhitelistHandler := ip_whitelist.NewIPWhitelistHandler(dbConn, queries, env, ipWhitelistRateLimiter, breakGlassRateLimiter)
 	auditLogsHandler := audit_logs.NewAuditLogsHandler(dbConn, queries, env)
+	accessRequestsHandler := access_requests.NewAccessRequestsHandler(dbConn, queries, env)
+	userGroupsHandler := user_groups.NewUserGroupsHandler(dbConn, queries)
 
 	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -98,6 +111,13 @@ func SetupRoutes(dbConn *pgxpool.Pool, env *config.Env, queries *queries.Queries
 		huma.Register(authAPI, auth.VerifyResetTokenOp, authHandler.VerifyResetToken)
 		huma.Register(authAPI, auth.ResetPasswordOp, authHandler.ResetPassword)
 
+		// IP whitelist break-glass endpoints — public, since they exist for
+		// admins the whitelist has locked out of signing in
+		breakGlassAPI := humachi.New(r.With(middleware.InjectRawHTTP), humaConfig)
+		huma.Register(breakGlassAPI, ip_whitelist.RedeemRecoveryCodeOp, ipWhitelistHandler.RedeemRecoveryCode)
+		huma.Register(breakGlassAPI, ip_whitelist.RequestBreakGlassOp, ipWhitelistHandler.RequestBreakGlass)
+		huma.Register(breakGlassAPI, ip_whitelist.ApproveBreakGlassOp, ipWhitelistHandler.ApproveBreakGlass)
+
 		// Authenticated huma endpoints — all registered at the /api level
 		// to avoid chi sub-router path duplication.
 		authenticatedMiddleware := chi.Chain(
@@ -114,6 +134,11 @@ func SetupRoutes(dbConn *pgxpool.Pool, env *config.Env, queries *queries.Queries
 		huma.Register(meAPI, users.ChangePasswordOp, usersHandler.ChangePassword)
 		huma.Register(meAPI, users.ChangeEmailOp, usersHandler.ChangeEmail)
 		huma.Register(meAPI, users.VerifyChangeEmailOp, usersHandler.VerifyChangeEmail)
+		huma.Register(meAPI, users.ExportMyDataOp, usersHandler.ExportMyData)
+		huma.Register(meAPI, users.GetMyTenantsOp, usersHandler.GetMyTenants)
+		huma.Register(meAPI, users.SwitchTenantOp, usersHandler.SwitchTenant)
+		huma.Register(meAPI, access_requests.GetMyAccessRequestsOp, accessRequestsHandler.GetMyAccessRequests)
+		huma.Register(meAPI, access_requests.CreateAccessRequestOp, accessRequestsHandler.CreateAccessRequest)
 
 		// /tenant endpoints
 		tenantEditAPI := humachi.New(r.With(append(authenticatedMiddleware, middleware.RequireTenantEdit(queries))...), humaConfig)
@@ -123,34 +148,80 @@ func SetupRoutes(dbConn *pgxpool.Pool, env *config.Env, queries *queries.Queries
 		usersViewAPI := humachi.New(r.With(append(authenticatedMiddleware, middleware.RequireUsersView(queries))...), humaConfig)
 		huma.Register(usersViewAPI, users.GetUsersOp, usersHandler.GetUsers)
 		huma.Register(usersViewAPI, roles.GetUsersRolesOp, rolesHandler.GetRoles)
+		huma.Register(usersViewAPI, users.GetUserPermissionsOp, usersHandler.GetUserPermissions)
 
 		usersEditAPI := humachi.New(r.With(append(authenticatedMiddleware, middleware.RequireUsersEdit(queries))...), humaConfig)
-		huma.Register(usersEditAPI, users.InviteUserOp, usersHandler.InviteUser)
-		huma.Register(usersEditAPI, users.ResendInviteOp, usersHandler.ResendInvite)
-		huma.Register(usersEditAPI, users.UpdateUserRolesOp, usersHandler.UpdateUserRoles)
-		huma.Register(usersEditAPI, users.DeleteUserOp, usersHandler.DeleteUser)
+		huma.Register(usersEditAPI, users.ExportUserDataOp, usersHandler.ExportUserData)
+
+		usersInviteAPI := humachi.New(r.With(append(authenticatedMiddleware, middleware.RequireUsersInviteInScope(queries))...), humaConfig)
+		huma.Register(usersInviteAPI, users.InviteUserOp, usersHandler.InviteUser)
+		huma.Register(usersInviteAPI, users.ResendInviteOp, usersHandler.ResendInvite)
+
+		usersAssignRolesAPI := humachi.New(r.With(append(authenticatedMiddleware, middleware.RequireUsersAssignRolesInScope(queries))...), humaConfig)
+		huma.Register(usersAssignRolesAPI, users.UpdateUserRolesOp, usersHandler.UpdateUserRoles)
+
+		usersDeleteAPI := humachi.New(r.With(append(authenticatedMiddleware, middleware.RequireUsersDeleteInScope(queries))...), humaConfig)
+		huma.Register(usersDeleteAPI, users.DeleteUserOp, usersHandler.DeleteUser)
+
+		// /users/groups endpoints
+		userGroupsViewAPI := humachi.New(r.With(append(authenticatedMiddleware, middleware.RequireRBAC(queries), middleware.RequireUsersView(queries))...), humaConfig)
+		huma.Register(userGroupsViewAPI, user_groups.GetUserGroupsOp, userGroupsHandler.GetUserGroups)
+
+		userGroupsEditAPI := humachi.New(r.With(append(authenticatedMiddleware, middleware.RequireRBAC(queries), middleware.RequireUsersAssignRoles(queries))...), humaConfig)
+		huma.Register(userGroupsEditAPI, user_groups.CreateUserGroupOp, userGroupsHandler.CreateUserGroup)
+		huma.Register(userGroupsEditAPI, user_groups.UpdateUserGroupOp, userGroupsHandler.UpdateUserGroup)
+		huma.Register(userGroupsEditAPI, user_groups.DeleteUserGroupOp, userGroupsHandler.DeleteUserGroup)
 
 		// /roles endpoints
 		rolesViewAPI := humachi.New(r.With(append(authenticatedMiddleware, middleware.RequireRBAC(queries), middleware.RequireRolesView(queries))...), humaConfig)
 		huma.Register(rolesViewAPI, roles.GetRolesOp, rolesHandler.GetRoles)
 		huma.Register(rolesViewAPI, roles.GetPermissionsOp, rolesHandler.GetPermissions)
+		huma.Register(rolesViewAPI, roles.GetRoleTemplatesOp, rolesHandler.GetRoleTemplates)
+		huma.Register(rolesViewAPI, roles.GetConflictSetsOp, rolesHandler.GetConflictSets)
+		huma.Register(rolesViewAPI, roles.GetConflictViolationsOp, rolesHandler.GetConflictViolations)
 
 		rolesEditAPI := humachi.New(r.With(append(authenticatedMiddleware, middleware.RequireRBAC(queries), middleware.RequireRolesEdit(queries))...), humaConfig)
 		huma.Register(rolesEditAPI, roles.CreateRoleOp, rolesHandler.CreateRole)
 		huma.Register(rolesEditAPI, roles.UpdateRoleOp, rolesHandler.UpdateRole)
 		huma.Register(rolesEditAPI, roles.DeleteRoleOp, rolesHandler.DeleteRole)
+		huma.Register(rolesEditAPI, roles.DuplicateRoleOp, rolesHandler.DuplicateRole)
+		huma.Register(rolesEditAPI, roles.CreateRoleFromTemplateOp, rolesHandler.CreateRoleFromTemplate)
+		huma.Register(rolesEditAPI, roles.CreateConflictSetOp, rolesHandler.CreateConflictSet)
+		huma.Register(rolesEditAPI, roles.DeleteConflictSetOp, rolesHandler.DeleteConflictSet)
+
+		// /access-requests endpoints: approvers are whoever can edit roles
+		accessRequestsAPI := humachi.New(r.With(append(authenticatedMiddleware, middleware.RequireRolesEdit(queries))...), humaConfig)
+		huma.Register(accessRequestsAPI, access_requests.GetAccessRequestsOp, accessRequestsHandler.GetAccessRequests)
+		huma.Register(accessRequestsAPI, access_requests.ApproveAccessRequestOp, accessRequestsHandler.ApproveAccessRequest)
+		huma.Register(accessRequestsAPI, access_requests.DenyAccessRequestOp, accessRequestsHandler.DenyAccessRequest)
 
 		// /ip-whitelist endpoints
 		ipViewAPI := humachi.New(r.With(append(authenticatedMiddleware, middleware.RequireIPWhitelist(queries), middleware.RequireIPWhitelistView(queries))...), humaConfig)
 		huma.Register(ipViewAPI, ip_whitelist.GetIPWhitelistOp, ipWhitelistHandler.GetIPWhitelist)
+		huma.Register(ipViewAPI, ip_whitelist.GetMonitorReportOp, ipWhitelistHandler.GetMonitorReport)
+		huma.Register(ipViewAPI, ip_whitelist.GetIPWhitelistAnalysisOp, ipWhitelistHandler.GetIPWhitelistAnalysis)
+		huma.Register(ipViewAPI, ip_whitelist.ExportIPWhitelistOp, ipWhitelistHandler.ExportIPWhitelist)
+		huma.Register(ipViewAPI, ip_whitelist.GetIPWhitelistScopesOp, ipWhitelistHandler.GetIPWhitelistScopes)
+		huma.Register(ipViewAPI, ip_whitelist.GetIPWhitelistCountriesOp, ipWhitelistHandler.GetIPWhitelistCountries)
+		huma.Register(ipViewAPI, ip_whitelist.GetRecoveryCodesOp, ipWhitelistHandler.GetRecoveryCodes)
 
 		ipEditAPI := humachi.New(r.With(append(authenticatedMiddleware, middleware.RequireIPWhitelist(queries), middleware.RequireIPWhitelistEdit(queries))...), humaConfig)
 		huma.Register(ipEditAPI, ip_whitelist.AddIPOp, ipWhitelistHandler.AddIPToWhitelist)
+		huma.Register(ipEditAPI, ip_whitelist.ImportIPWhitelistOp, ipWhitelistHandler.ImportIPWhitelist)
 		huma.Register(ipEditAPI, ip_whitelist.UpdateIPLabelOp, ipWhitelistHandler.UpdateIPLabel)
 		huma.Register(ipEditAPI, ip_whitelist.DeleteIPOp, ipWhitelistHandler.DeleteIP)
 		huma.Register(ipEditAPI, ip_whitelist.ActivateWhitelistOp, ipWhitelistHandler.ActivateWhitelist)
 		huma.Register(ipEditAPI, ip_whitelist.DeactivateWhitelistOp, ipWhitelistHandler.DeactivateWhitelist)
-		huma.Register(ipEditAPI, ip_whitelist.EmergencyDeactivateOp, ipWhitelistHandler.EmergencyDeactivate)
+		huma.Register(ipEditAPI, ip_whitelist.StartMonitorOp, ipWhitelistHandler.StartMonitor)
+		huma.Register(ipEditAPI, ip_whitelist.CreateIPWhitelistScopeOp, ipWhitelistHandler.CreateIPWhitelistScope)
+		huma.Register(ipEditAPI, ip_whitelist.UpdateIPWhitelistScopeOp, ipWhitelistHandler.UpdateIPWhitelistScope)
+		huma.Register(ipEditAPI, ip_whitelist.DeleteIPWhitelistScopeOp, ipWhitelistHandler.DeleteIPWhitelistScope)
+		huma.Register(ipEditAPI, ip_whitelist.AddIPWhitelistCountryOp, ipWhitelistHandler.AddIPWhitelistCountry)
+		huma.Register(ipEditAPI, ip_whitelist.DeleteIPWhitelistCountryOp, ipWhitelistHandler.DeleteIPWhitelistCountry)
+
+		ipEmergencyAPI := humachi.New(r.With(append(authenticatedMiddleware, middleware.RequireIPWhitelist(queries), middleware.RequireIPWhitelistEmergency(queries))...), humaConfig)
+		huma.Register(ipEmergencyAPI, ip_whitelist.EmergencyDeactivateOp, ipWhitelistHandler.EmergencyDeactivate)
+		huma.Register(ipEmergencyAPI, ip_whitelist.IssueRecoveryCodesOp, ipWhitelistHandler.IssueRecoveryCodes)
 
 		// /audit-logs endpoints
 		auditLogViewAPI := humachi.New(r.With(append(authenticatedMiddleware, middleware.RequireAuditLog(queries), middleware.RequireAuditLogView(queries))...), humaConfig)
@@ -215,6 +286,52 @@ func main() {
 
 	appQueries := queries.New(pool)
 
+	if err := authz.SyncPermissions(context.Background(), pool, appQueries); err != nil {
+		log.Fatalf("Failed to sync permission registry: %v", err)
+	}
+
+	permissionCacheTTL, err := time.ParseDuration(env.PermissionCacheTTL)
+	if err != nil || permissionCacheTTL < 0 {
+		log.Fatalf("Invalid PERMISSION_CACHE_TTL %q: must be a non-negative duration", env.PermissionCacheTTL)
+	}
+	authz.SetPermissionCacheTTL(permissionCacheTTL)
+
+	trustedProxies, err := iputils.ParseTrustedProxies(env.TrustedProxies, env.TrustedProxyHops, env.TrustedRealIPHeader)
+	if err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES, TRUSTED_PROXY_HOPS or TRUSTED_REAL_IP_HEADER: %v", err)
+	}
+	iputils.SetTrustedProxies(trustedProxies)
+
+	trustedProxyCtx, stopTrustedProxies := context.WithCancel(context.Background())
+	defer stopTrustedProxies()
+	iputils.ResolveTrustedProxyHostnames(trustedProxyCtx)
+	go iputils.WatchTrustedProxyHostnames(trustedProxyCtx)
+
+	geoipCtx, stopGeoIP := context.WithCancel(context.Background())
+	defer stopGeoIP()
+	if env.GeoIPDatabasePath != "" {
+		geoipReloadInterval, err := time.ParseDuration(env.GeoIPReloadInterval)
+		if err != nil || geoipReloadInterval <= 0 {
+			log.Fatalf("Invalid GEOIP_RELOAD_INTERVAL %q: must be a positive duration", env.GeoIPReloadInterval)
+		}
+		if err := geoip.Load(env.GeoIPDatabasePath); err != nil {
+			log.Fatalf("Failed to load GeoIP database: %v", err)
+		}
+		go geoip.Watch(geoipCtx, env.GeoIPDatabasePath, geoipReloadInterval)
+	}
+
+	maintenanceConfig, err := maintenance.NewConfig(env)
+	if err != nil {
+		log.Fatalf("Failed to load maintenance config: %v", err)
+	}
+	maintenanceCtx, stopMaintenance := context.WithCancel(context.Background())
+	defer stopMaintenance()
+	go maintenance.NewRunner(pool, appQueries, maintenanceConfig).Run(maintenanceCtx)
+
+	listenerCtx, stopListener := context.WithCancel(context.Background())
+	defer stopListener()
+	go middleware.ListenForIPWhitelistChanges(listenerCtx, pool)
+
 	serverErrors := make(chan error, 1)
 
 	sigChan := make(chan os.Signal, 1)
@@ -243,6 +360,7 @@ func main() {
 
 	case sig := <-sigChan:
 		log.Printf("main: %v : Start shutdown", sig)
+		stopMaintenance()
 		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer shutdownCancel()
 
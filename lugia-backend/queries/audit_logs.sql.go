@@ -15,7 +15,7 @@ import (
 const CountAuditLogs = `-- name: CountAuditLogs :one
 SELECT COUNT(*)
 FROM audit_logs al
-INNER JOIN users u ON u.id = al.actor_id
+LEFT JOIN users u ON u.id = al.actor_id
 WHERE al.tenant_id = $1
 AND ($2::uuid IS NULL OR al.actor_id = $2)
 AND ($3::varchar = '' OR al.resource_type = $3)
@@ -87,8 +87,8 @@ SELECT
     al.id,
     al.tenant_id,
     al.actor_id,
-    u.name AS actor_name,
-    u.email AS actor_email,
+    COALESCE(u.name, '')::varchar AS actor_name,
+    COALESCE(u.email, '')::varchar AS actor_email,
     al.resource_type,
     al.action,
     al.outcome,
@@ -98,7 +98,7 @@ SELECT
     al.user_agent,
     al.created_at
 FROM audit_logs al
-INNER JOIN users u ON u.id = al.actor_id
+LEFT JOIN users u ON u.id = al.actor_id
 WHERE al.tenant_id = $1
 AND ($2::uuid IS NULL OR al.actor_id = $2)
 AND ($3::varchar = '' OR al.resource_type = $3)
@@ -181,3 +181,73 @@ func (q *Queries) ListAuditLogs(ctx context.Context, arg *ListAuditLogsParams) (
 	}
 	return items, nil
 }
+
+const ListAuditLogsForUser = `-- name: ListAuditLogsForUser :many
+SELECT
+    al.id,
+    al.actor_id,
+    al.resource_type,
+    al.action,
+    al.outcome,
+    al.resource_id,
+    al.metadata,
+    al.ip_address,
+    al.user_agent,
+    al.created_at
+FROM audit_logs al
+WHERE al.tenant_id = $1
+AND (
+    al.actor_id = $2
+    OR (al.resource_type = 'user' AND al.resource_id = $2::text)
+)
+ORDER BY al.created_at DESC
+`
+
+type ListAuditLogsForUserParams struct {
+	TenantID pgtype.UUID `json:"tenant_id"`
+	UserID   pgtype.UUID `json:"user_id"`
+}
+
+type ListAuditLogsForUserRow struct {
+	ID           pgtype.UUID        `json:"id"`
+	ActorID      pgtype.UUID        `json:"actor_id"`
+	ResourceType string             `json:"resource_type"`
+	Action       string             `json:"action"`
+	Outcome      string             `json:"outcome"`
+	ResourceID   pgtype.Text        `json:"resource_id"`
+	Metadata     []byte             `json:"metadata"`
+	IpAddress    *netip.Addr        `json:"ip_address"`
+	UserAgent    pgtype.Text        `json:"user_agent"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+}
+
+func (q *Queries) ListAuditLogsForUser(ctx context.Context, arg *ListAuditLogsForUserParams) ([]*ListAuditLogsForUserRow, error) {
+	rows, err := q.db.Query(ctx, ListAuditLogsForUser, arg.TenantID, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*ListAuditLogsForUserRow{}
+	for rows.Next() {
+		var i ListAuditLogsForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ActorID,
+			&i.ResourceType,
+			&i.Action,
+			&i.Outcome,
+			&i.ResourceID,
+			&i.Metadata,
+			&i.IpAddress,
+			&i.UserAgent,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -48,8 +48,9 @@ INSERT INTO refresh_tokens (
     jti,
     device_info,
     ip_address,
-    expires_at
-) VALUES ($1, $2, $3, $4, $5) RETURNING id, user_id, jti, device_info, ip_address, expires_at, created_at, used_at, revoked_at
+    expires_at,
+    tenant_id
+) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, user_id, jti, device_info, ip_address, expires_at, created_at, used_at, revoked_at, tenant_id
 `
 
 type CreateRefreshTokenParams struct {
@@ -58,6 +59,7 @@ type CreateRefreshTokenParams struct {
 	DeviceInfo pgtype.Text        `json:"device_info"`
 	IpAddress  pgtype.Text        `json:"ip_address"`
 	ExpiresAt  pgtype.Timestamptz `json:"expires_at"`
+	TenantID   pgtype.UUID        `json:"tenant_id"`
 }
 
 func (q *Queries) CreateRefreshToken(ctx context.Context, arg *CreateRefreshTokenParams) (*RefreshToken, error) {
@@ -67,6 +69,7 @@ func (q *Queries) CreateRefreshToken(ctx context.Context, arg *CreateRefreshToke
 		arg.DeviceInfo,
 		arg.IpAddress,
 		arg.ExpiresAt,
+		arg.TenantID,
 	)
 	var i RefreshToken
 	err := row.Scan(
@@ -79,6 +82,7 @@ func (q *Queries) CreateRefreshToken(ctx context.Context, arg *CreateRefreshToke
 		&i.CreatedAt,
 		&i.UsedAt,
 		&i.RevokedAt,
+		&i.TenantID,
 	)
 	return &i, err
 }
@@ -147,7 +151,7 @@ INSERT INTO users (
     external_sso_id
 ) VALUES (
     $1, $2, $3, $4, $5, $6, $7
-) RETURNING id, tenant_id, email, password_hash, name, is_internal_admin, is_internal_user, created_at, updated_at, deleted_at, status, external_sso_id
+) RETURNING id, tenant_id, email, password_hash, name, is_internal_admin, is_internal_user, created_at, updated_at, deleted_at, status, external_sso_id, last_login_at
 `
 
 type CreateUserParams struct {
@@ -184,20 +188,11 @@ func (q *Queries) CreateUser(ctx context.Context, arg *CreateUserParams) (*User,
 		&i.DeletedAt,
 		&i.Status,
 		&i.ExternalSsoID,
+		&i.LastLoginAt,
 	)
 	return &i, err
 }
 
-const DeleteExpiredSSORequests = `-- name: DeleteExpiredSSORequests :exec
-DELETE FROM sso_auth_requests
-WHERE expires_at < NOW()
-`
-
-func (q *Queries) DeleteExpiredSSORequests(ctx context.Context) error {
-	_, err := q.db.Exec(ctx, DeleteExpiredSSORequests)
-	return err
-}
-
 const DeletePasswordResetTokenByUserID = `-- name: DeletePasswordResetTokenByUserID :exec
 DELETE FROM password_reset_tokens
 WHERE user_id = $1
@@ -259,7 +254,7 @@ func (q *Queries) GetPasswordResetTokenByHash(ctx context.Context, tokenHash str
 }
 
 const GetRefreshTokenByUserID = `-- name: GetRefreshTokenByUserID :one
-SELECT id, user_id, jti, device_info, ip_address, expires_at, created_at, used_at, revoked_at FROM refresh_tokens 
+SELECT id, user_id, jti, device_info, ip_address, expires_at, created_at, used_at, revoked_at, tenant_id FROM refresh_tokens 
 WHERE user_id = $1 
 AND revoked_at IS NULL 
 AND expires_at > CURRENT_TIMESTAMP
@@ -278,10 +273,56 @@ func (q *Queries) GetRefreshTokenByUserID(ctx context.Context, userID pgtype.UUI
 		&i.CreatedAt,
 		&i.UsedAt,
 		&i.RevokedAt,
+		&i.TenantID,
 	)
 	return &i, err
 }
 
+const GetRefreshTokensByUserID = `-- name: GetRefreshTokensByUserID :many
+SELECT id, device_info, ip_address, expires_at, created_at, used_at, revoked_at
+FROM refresh_tokens
+WHERE user_id = $1
+ORDER BY created_at DESC
+`
+
+type GetRefreshTokensByUserIDRow struct {
+	ID         pgtype.UUID        `json:"id"`
+	DeviceInfo pgtype.Text        `json:"device_info"`
+	IpAddress  pgtype.Text        `json:"ip_address"`
+	ExpiresAt  pgtype.Timestamptz `json:"expires_at"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	UsedAt     pgtype.Timestamptz `json:"used_at"`
+	RevokedAt  pgtype.Timestamptz `json:"revoked_at"`
+}
+
+func (q *Queries) GetRefreshTokensByUserID(ctx context.Context, userID pgtype.UUID) ([]*GetRefreshTokensByUserIDRow, error) {
+	rows, err := q.db.Query(ctx, GetRefreshTokensByUserID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*GetRefreshTokensByUserIDRow{}
+	for rows.Next() {
+		var i GetRefreshTokensByUserIDRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.DeviceInfo,
+			&i.IpAddress,
+			&i.ExpiresAt,
+			&i.CreatedAt,
+			&i.UsedAt,
+			&i.RevokedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const GetSSOTenantByDomain = `-- name: GetSSOTenantByDomain :one
 SELECT id, enterprise_features
 FROM tenants
@@ -306,7 +347,8 @@ SELECT
     tenants.enterprise_features,
     users.is_internal_user
 FROM tenants
-JOIN users ON users.tenant_id = tenants.id
+JOIN tenant_memberships ON tenant_memberships.tenant_id = tenants.id
+JOIN users ON users.id = tenant_memberships.user_id
 WHERE tenants.id = $1 AND users.id = $2 AND users.deleted_at IS NULL
 `
 
@@ -348,7 +390,7 @@ func (q *Queries) GetTenantByID(ctx context.Context, id pgtype.UUID) (*Tenant, e
 }
 
 const GetUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, tenant_id, email, password_hash, name, is_internal_admin, is_internal_user, created_at, updated_at, deleted_at, status, external_sso_id FROM users
+SELECT id, tenant_id, email, password_hash, name, is_internal_admin, is_internal_user, created_at, updated_at, deleted_at, status, external_sso_id, last_login_at FROM users
 WHERE email = $1 AND deleted_at IS NULL
 `
 
@@ -368,12 +410,13 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (*User, erro
 		&i.DeletedAt,
 		&i.Status,
 		&i.ExternalSsoID,
+		&i.LastLoginAt,
 	)
 	return &i, err
 }
 
 const GetUserByID = `-- name: GetUserByID :one
-SELECT id, tenant_id, email, password_hash, name, is_internal_admin, is_internal_user, created_at, updated_at, deleted_at, status, external_sso_id FROM users
+SELECT id, tenant_id, email, password_hash, name, is_internal_admin, is_internal_user, created_at, updated_at, deleted_at, status, external_sso_id, last_login_at FROM users
 WHERE id = $1 AND deleted_at IS NULL
 `
 
@@ -393,6 +436,7 @@ func (q *Queries) GetUserByID(ctx context.Context, id pgtype.UUID) (*User, error
 		&i.DeletedAt,
 		&i.Status,
 		&i.ExternalSsoID,
+		&i.LastLoginAt,
 	)
 	return &i, err
 }
@@ -461,3 +505,14 @@ func (q *Queries) UpdateTenantName(ctx context.Context, arg *UpdateTenantNamePar
 	_, err := q.db.Exec(ctx, UpdateTenantName, arg.Name, arg.ID)
 	return err
 }
+
+const UpdateUserLastLoginAt = `-- name: UpdateUserLastLoginAt :exec
+UPDATE users
+SET last_login_at = CURRENT_TIMESTAMP
+WHERE id = $1
+`
+
+func (q *Queries) UpdateUserLastLoginAt(ctx context.Context, id pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, UpdateUserLastLoginAt, id)
+	return err
+}
@@ -32,6 +32,8 @@ func (r iteratorForAddRolesToUser) Values() ([]interface{}, error) {
 		r.rows[0].UserID,
 		r.rows[0].RoleID,
 		r.rows[0].TenantID,
+		r.rows[0].ExpiresAt,
+		r.rows[0].GrantedBy,
 	}, nil
 }
 
@@ -40,5 +42,5 @@ func (r iteratorForAddRolesToUser) Err() error {
 }
 
 func (q *Queries) AddRolesToUser(ctx context.Context, arg []*AddRolesToUserParams) (int64, error) {
-	return q.db.CopyFrom(ctx, []string{"user_roles"}, []string{"user_id", "role_id", "tenant_id"}, &iteratorForAddRolesToUser{rows: arg})
+	return q.db.CopyFrom(ctx, []string{"user_roles"}, []string{"user_id", "role_id", "tenant_id", "expires_at", "granted_by"}, &iteratorForAddRolesToUser{rows: arg})
 }
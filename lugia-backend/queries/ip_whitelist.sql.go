@@ -13,16 +13,18 @@ import (
 )
 
 const AddIPToWhitelist = `-- name: AddIPToWhitelist :one
-INSERT INTO tenant_ip_whitelist (tenant_id, ip_address, label, created_by)
-VALUES ($1, $2, $3, $4)
-RETURNING id, tenant_id, ip_address, label, created_by, created_at
+INSERT INTO tenant_ip_whitelist (tenant_id, ip_address, label, created_by, expires_at, scope_id)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, tenant_id, ip_address, label, created_by, created_at, expires_at, expiry_warned_at, scope_id
 `
 
 type AddIPToWhitelistParams struct {
-	TenantID  pgtype.UUID  `json:"tenant_id"`
-	IpAddress netip.Prefix `json:"ip_address"`
-	Label     pgtype.Text  `json:"label"`
-	CreatedBy pgtype.UUID  `json:"created_by"`
+	TenantID  pgtype.UUID        `json:"tenant_id"`
+	IpAddress netip.Prefix       `json:"ip_address"`
+	Label     pgtype.Text        `json:"label"`
+	CreatedBy pgtype.UUID        `json:"created_by"`
+	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
+	ScopeID   pgtype.UUID        `json:"scope_id"`
 }
 
 func (q *Queries) AddIPToWhitelist(ctx context.Context, arg *AddIPToWhitelistParams) (*TenantIpWhitelist, error) {
@@ -31,6 +33,8 @@ func (q *Queries) AddIPToWhitelist(ctx context.Context, arg *AddIPToWhitelistPar
 		arg.IpAddress,
 		arg.Label,
 		arg.CreatedBy,
+		arg.ExpiresAt,
+		arg.ScopeID,
 	)
 	var i TenantIpWhitelist
 	err := row.Scan(
@@ -40,25 +44,78 @@ func (q *Queries) AddIPToWhitelist(ctx context.Context, arg *AddIPToWhitelistPar
 		&i.Label,
 		&i.CreatedBy,
 		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.ExpiryWarnedAt,
+		&i.ScopeID,
+	)
+	return &i, err
+}
+
+const AddIPWhitelistCountry = `-- name: AddIPWhitelistCountry :one
+INSERT INTO tenant_ip_whitelist_countries (tenant_id, country_code, action, created_by)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT DO NOTHING
+RETURNING id, tenant_id, country_code, action, created_by, created_at
+`
+
+type AddIPWhitelistCountryParams struct {
+	TenantID    pgtype.UUID `json:"tenant_id"`
+	CountryCode string      `json:"country_code"`
+	Action      string      `json:"action"`
+	CreatedBy   pgtype.UUID `json:"created_by"`
+}
+
+func (q *Queries) AddIPWhitelistCountry(ctx context.Context, arg *AddIPWhitelistCountryParams) (*TenantIpWhitelistCountry, error) {
+	row := q.db.QueryRow(ctx, AddIPWhitelistCountry,
+		arg.TenantID,
+		arg.CountryCode,
+		arg.Action,
+		arg.CreatedBy,
+	)
+	var i TenantIpWhitelistCountry
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.CountryCode,
+		&i.Action,
+		&i.CreatedBy,
+		&i.CreatedAt,
 	)
 	return &i, err
 }
 
+const ApproveIPWhitelistBreakGlassRequest = `-- name: ApproveIPWhitelistBreakGlassRequest :execrows
+UPDATE ip_whitelist_break_glass_approvals
+SET approved_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND approved_at IS NULL
+`
+
+func (q *Queries) ApproveIPWhitelistBreakGlassRequest(ctx context.Context, id pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, ApproveIPWhitelistBreakGlassRequest, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const CheckIPExists = `-- name: CheckIPExists :one
 SELECT EXISTS(
     SELECT 1 
     FROM tenant_ip_whitelist 
     WHERE tenant_id = $1 AND ip_address = $2
+        AND scope_id IS NOT DISTINCT FROM $3
+        AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
 ) AS exists
 `
 
 type CheckIPExistsParams struct {
 	TenantID  pgtype.UUID  `json:"tenant_id"`
 	IpAddress netip.Prefix `json:"ip_address"`
+	ScopeID   pgtype.UUID  `json:"scope_id"`
 }
 
 func (q *Queries) CheckIPExists(ctx context.Context, arg *CheckIPExistsParams) (bool, error) {
-	row := q.db.QueryRow(ctx, CheckIPExists, arg.TenantID, arg.IpAddress)
+	row := q.db.QueryRow(ctx, CheckIPExists, arg.TenantID, arg.IpAddress, arg.ScopeID)
 	var exists bool
 	err := row.Scan(&exists)
 	return exists, err
@@ -74,6 +131,17 @@ func (q *Queries) ClearTenantIPWhitelist(ctx context.Context, tenantID pgtype.UU
 	return err
 }
 
+const CompleteIPWhitelistBreakGlassRequest = `-- name: CompleteIPWhitelistBreakGlassRequest :exec
+UPDATE ip_whitelist_break_glass_requests
+SET completed_at = CURRENT_TIMESTAMP
+WHERE id = $1
+`
+
+func (q *Queries) CompleteIPWhitelistBreakGlassRequest(ctx context.Context, id pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, CompleteIPWhitelistBreakGlassRequest, id)
+	return err
+}
+
 const CountTenantIPWhitelistRules = `-- name: CountTenantIPWhitelistRules :one
 SELECT COUNT(*)
 FROM tenant_ip_whitelist
@@ -87,28 +155,331 @@ func (q *Queries) CountTenantIPWhitelistRules(ctx context.Context, tenantID pgty
 	return count, err
 }
 
+const CreateIPWhitelistBreakGlassApproval = `-- name: CreateIPWhitelistBreakGlassApproval :exec
+INSERT INTO ip_whitelist_break_glass_approvals (request_id, user_id, token_hash)
+VALUES ($1, $2, $3)
+`
+
+type CreateIPWhitelistBreakGlassApprovalParams struct {
+	RequestID pgtype.UUID `json:"request_id"`
+	UserID    pgtype.UUID `json:"user_id"`
+	TokenHash string      `json:"token_hash"`
+}
+
+func (q *Queries) CreateIPWhitelistBreakGlassApproval(ctx context.Context, arg *CreateIPWhitelistBreakGlassApprovalParams) error {
+	_, err := q.db.Exec(ctx, CreateIPWhitelistBreakGlassApproval, arg.RequestID, arg.UserID, arg.TokenHash)
+	return err
+}
+
+const CreateIPWhitelistBreakGlassRequest = `-- name: CreateIPWhitelistBreakGlassRequest :one
+INSERT INTO ip_whitelist_break_glass_requests (tenant_id, requested_by, expires_at)
+VALUES ($1, $2, $3)
+RETURNING id
+`
+
+type CreateIPWhitelistBreakGlassRequestParams struct {
+	TenantID    pgtype.UUID        `json:"tenant_id"`
+	RequestedBy pgtype.UUID        `json:"requested_by"`
+	ExpiresAt   pgtype.Timestamptz `json:"expires_at"`
+}
+
+func (q *Queries) CreateIPWhitelistBreakGlassRequest(ctx context.Context, arg *CreateIPWhitelistBreakGlassRequestParams) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, CreateIPWhitelistBreakGlassRequest, arg.TenantID, arg.RequestedBy, arg.ExpiresAt)
+	var id pgtype.UUID
+	err := row.Scan(&id)
+	return id, err
+}
+
 const CreateIPWhitelistEmergencyToken = `-- name: CreateIPWhitelistEmergencyToken :one
 
-INSERT INTO ip_whitelist_emergency_tokens (jti)
-VALUES ($1)
-RETURNING id, jti, used_at, created_at
+INSERT INTO ip_whitelist_emergency_tokens (jti, tenant_id, user_id, expires_at)
+VALUES ($1, $2, $3, $4)
+RETURNING id, jti, used_at, created_at, tenant_id, user_id, expires_at
 `
 
+type CreateIPWhitelistEmergencyTokenParams struct {
+	Jti       pgtype.UUID        `json:"jti"`
+	TenantID  pgtype.UUID        `json:"tenant_id"`
+	UserID    pgtype.UUID        `json:"user_id"`
+	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
+}
+
 // IP Whitelist Emergency Token Operations
-func (q *Queries) CreateIPWhitelistEmergencyToken(ctx context.Context, jti pgtype.UUID) (*IpWhitelistEmergencyToken, error) {
-	row := q.db.QueryRow(ctx, CreateIPWhitelistEmergencyToken, jti)
+func (q *Queries) CreateIPWhitelistEmergencyToken(ctx context.Context, arg *CreateIPWhitelistEmergencyTokenParams) (*IpWhitelistEmergencyToken, error) {
+	row := q.db.QueryRow(ctx, CreateIPWhitelistEmergencyToken,
+		arg.Jti,
+		arg.TenantID,
+		arg.UserID,
+		arg.ExpiresAt,
+	)
 	var i IpWhitelistEmergencyToken
 	err := row.Scan(
 		&i.ID,
 		&i.Jti,
 		&i.UsedAt,
 		&i.CreatedAt,
+		&i.TenantID,
+		&i.UserID,
+		&i.ExpiresAt,
 	)
 	return &i, err
 }
 
+const CreateIPWhitelistRecoveryCode = `-- name: CreateIPWhitelistRecoveryCode :exec
+INSERT INTO ip_whitelist_recovery_codes (tenant_id, code_hash, created_by)
+VALUES ($1, $2, $3)
+`
+
+type CreateIPWhitelistRecoveryCodeParams struct {
+	TenantID  pgtype.UUID `json:"tenant_id"`
+	CodeHash  string      `json:"code_hash"`
+	CreatedBy pgtype.UUID `json:"created_by"`
+}
+
+func (q *Queries) CreateIPWhitelistRecoveryCode(ctx context.Context, arg *CreateIPWhitelistRecoveryCodeParams) error {
+	_, err := q.db.Exec(ctx, CreateIPWhitelistRecoveryCode, arg.TenantID, arg.CodeHash, arg.CreatedBy)
+	return err
+}
+
+const CreateIPWhitelistScope = `-- name: CreateIPWhitelistScope :one
+INSERT INTO ip_whitelist_scopes (tenant_id, role_id, user_id, exempt, created_by)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT DO NOTHING
+RETURNING id
+`
+
+type CreateIPWhitelistScopeParams struct {
+	TenantID  pgtype.UUID `json:"tenant_id"`
+	RoleID    pgtype.UUID `json:"role_id"`
+	UserID    pgtype.UUID `json:"user_id"`
+	Exempt    bool        `json:"exempt"`
+	CreatedBy pgtype.UUID `json:"created_by"`
+}
+
+func (q *Queries) CreateIPWhitelistScope(ctx context.Context, arg *CreateIPWhitelistScopeParams) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, CreateIPWhitelistScope,
+		arg.TenantID,
+		arg.RoleID,
+		arg.UserID,
+		arg.Exempt,
+		arg.CreatedBy,
+	)
+	var id pgtype.UUID
+	err := row.Scan(&id)
+	return id, err
+}
+
+const DeleteIPWhitelistCountry = `-- name: DeleteIPWhitelistCountry :exec
+DELETE FROM tenant_ip_whitelist_countries
+WHERE id = $1 AND tenant_id = $2
+`
+
+type DeleteIPWhitelistCountryParams struct {
+	ID       pgtype.UUID `json:"id"`
+	TenantID pgtype.UUID `json:"tenant_id"`
+}
+
+func (q *Queries) DeleteIPWhitelistCountry(ctx context.Context, arg *DeleteIPWhitelistCountryParams) error {
+	_, err := q.db.Exec(ctx, DeleteIPWhitelistCountry, arg.ID, arg.TenantID)
+	return err
+}
+
+const DeleteIPWhitelistRecoveryCodes = `-- name: DeleteIPWhitelistRecoveryCodes :exec
+
+DELETE FROM ip_whitelist_recovery_codes
+WHERE tenant_id = $1
+`
+
+// IP Whitelist Break-Glass Operations
+func (q *Queries) DeleteIPWhitelistRecoveryCodes(ctx context.Context, tenantID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, DeleteIPWhitelistRecoveryCodes, tenantID)
+	return err
+}
+
+const DeleteIPWhitelistScope = `-- name: DeleteIPWhitelistScope :one
+WITH deleted AS (
+    DELETE FROM ip_whitelist_scopes
+    WHERE ip_whitelist_scopes.id = $1 AND ip_whitelist_scopes.tenant_id = $2
+    RETURNING ip_whitelist_scopes.id
+)
+-- The scope's rules go with it (ON DELETE CASCADE); count them for the
+-- audit log first.
+SELECT COUNT(tenant_ip_whitelist.id)
+FROM deleted
+LEFT JOIN tenant_ip_whitelist ON tenant_ip_whitelist.scope_id = deleted.id
+`
+
+type DeleteIPWhitelistScopeParams struct {
+	ID       pgtype.UUID `json:"id"`
+	TenantID pgtype.UUID `json:"tenant_id"`
+}
+
+func (q *Queries) DeleteIPWhitelistScope(ctx context.Context, arg *DeleteIPWhitelistScopeParams) (int64, error) {
+	row := q.db.QueryRow(ctx, DeleteIPWhitelistScope, arg.ID, arg.TenantID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const GetIPWhitelistBreakGlassApprovalByTokenHash = `-- name: GetIPWhitelistBreakGlassApprovalByTokenHash :one
+SELECT
+    ip_whitelist_break_glass_approvals.id,
+    ip_whitelist_break_glass_approvals.request_id,
+    ip_whitelist_break_glass_approvals.user_id,
+    ip_whitelist_break_glass_approvals.approved_at,
+    ip_whitelist_break_glass_requests.tenant_id,
+    ip_whitelist_break_glass_requests.expires_at,
+    ip_whitelist_break_glass_requests.completed_at
+FROM ip_whitelist_break_glass_approvals
+JOIN ip_whitelist_break_glass_requests ON ip_whitelist_break_glass_requests.id = ip_whitelist_break_glass_approvals.request_id
+WHERE ip_whitelist_break_glass_approvals.token_hash = $1
+`
+
+type GetIPWhitelistBreakGlassApprovalByTokenHashRow struct {
+	ID          pgtype.UUID        `json:"id"`
+	RequestID   pgtype.UUID        `json:"request_id"`
+	UserID      pgtype.UUID        `json:"user_id"`
+	ApprovedAt  pgtype.Timestamptz `json:"approved_at"`
+	TenantID    pgtype.UUID        `json:"tenant_id"`
+	ExpiresAt   pgtype.Timestamptz `json:"expires_at"`
+	CompletedAt pgtype.Timestamptz `json:"completed_at"`
+}
+
+func (q *Queries) GetIPWhitelistBreakGlassApprovalByTokenHash(ctx context.Context, tokenHash string) (*GetIPWhitelistBreakGlassApprovalByTokenHashRow, error) {
+	row := q.db.QueryRow(ctx, GetIPWhitelistBreakGlassApprovalByTokenHash, tokenHash)
+	var i GetIPWhitelistBreakGlassApprovalByTokenHashRow
+	err := row.Scan(
+		&i.ID,
+		&i.RequestID,
+		&i.UserID,
+		&i.ApprovedAt,
+		&i.TenantID,
+		&i.ExpiresAt,
+		&i.CompletedAt,
+	)
+	return &i, err
+}
+
+const GetIPWhitelistBreakGlassApprovers = `-- name: GetIPWhitelistBreakGlassApprovers :many
+SELECT users.id, users.name, users.email
+FROM ip_whitelist_break_glass_approvals
+JOIN users ON users.id = ip_whitelist_break_glass_approvals.user_id
+WHERE ip_whitelist_break_glass_approvals.request_id = $1
+  AND ip_whitelist_break_glass_approvals.approved_at IS NOT NULL
+ORDER BY ip_whitelist_break_glass_approvals.approved_at
+`
+
+type GetIPWhitelistBreakGlassApproversRow struct {
+	ID    pgtype.UUID `json:"id"`
+	Name  string      `json:"name"`
+	Email string      `json:"email"`
+}
+
+func (q *Queries) GetIPWhitelistBreakGlassApprovers(ctx context.Context, requestID pgtype.UUID) ([]*GetIPWhitelistBreakGlassApproversRow, error) {
+	rows, err := q.db.Query(ctx, GetIPWhitelistBreakGlassApprovers, requestID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*GetIPWhitelistBreakGlassApproversRow{}
+	for rows.Next() {
+		var i GetIPWhitelistBreakGlassApproversRow
+		if err := rows.Scan(&i.ID, &i.Name, &i.Email); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const GetIPWhitelistCountryByID = `-- name: GetIPWhitelistCountryByID :one
+SELECT id, tenant_id, country_code, action, created_by, created_at FROM tenant_ip_whitelist_countries
+WHERE id = $1 AND tenant_id = $2
+`
+
+type GetIPWhitelistCountryByIDParams struct {
+	ID       pgtype.UUID `json:"id"`
+	TenantID pgtype.UUID `json:"tenant_id"`
+}
+
+func (q *Queries) GetIPWhitelistCountryByID(ctx context.Context, arg *GetIPWhitelistCountryByIDParams) (*TenantIpWhitelistCountry, error) {
+	row := q.db.QueryRow(ctx, GetIPWhitelistCountryByID, arg.ID, arg.TenantID)
+	var i TenantIpWhitelistCountry
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.CountryCode,
+		&i.Action,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return &i, err
+}
+
+const GetIPWhitelistEmergencyHolders = `-- name: GetIPWhitelistEmergencyHolders :many
+WITH RECURSIVE granted_roles AS (
+    SELECT user_roles.user_id, user_roles.role_id
+    FROM user_roles
+    JOIN roles ON roles.id = user_roles.role_id
+    WHERE user_roles.tenant_id = $1
+        AND (user_roles.expires_at IS NULL OR user_roles.expires_at > CURRENT_TIMESTAMP)
+        AND ($2::boolean = true OR roles.is_default = true)
+    UNION
+    SELECT granted_roles.user_id, role_inclusions.included_role_id
+    FROM granted_roles
+    JOIN role_inclusions ON role_inclusions.role_id = granted_roles.role_id
+)
+SELECT DISTINCT users.id, users.name, users.email
+FROM users
+JOIN tenant_memberships ON tenant_memberships.user_id = users.id
+JOIN granted_roles ON granted_roles.user_id = users.id
+JOIN role_permissions ON role_permissions.role_id = granted_roles.role_id
+JOIN permissions ON permissions.id = role_permissions.permission_id
+WHERE tenant_memberships.tenant_id = $1
+    AND users.status = 'active'
+    AND users.deleted_at IS NULL
+    AND users.is_internal_user = false
+    AND permissions.resource = 'ip_whitelist'
+    AND permissions.action = 'emergency'
+ORDER BY users.email
+`
+
+type GetIPWhitelistEmergencyHoldersParams struct {
+	TenantID    pgtype.UUID `json:"tenant_id"`
+	RbacEnabled bool        `json:"rbac_enabled"`
+}
+
+type GetIPWhitelistEmergencyHoldersRow struct {
+	ID    pgtype.UUID `json:"id"`
+	Name  string      `json:"name"`
+	Email string      `json:"email"`
+}
+
+func (q *Queries) GetIPWhitelistEmergencyHolders(ctx context.Context, arg *GetIPWhitelistEmergencyHoldersParams) ([]*GetIPWhitelistEmergencyHoldersRow, error) {
+	rows, err := q.db.Query(ctx, GetIPWhitelistEmergencyHolders, arg.TenantID, arg.RbacEnabled)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*GetIPWhitelistEmergencyHoldersRow{}
+	for rows.Next() {
+		var i GetIPWhitelistEmergencyHoldersRow
+		if err := rows.Scan(&i.ID, &i.Name, &i.Email); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const GetIPWhitelistEmergencyTokenByJTI = `-- name: GetIPWhitelistEmergencyTokenByJTI :one
-SELECT id, jti, used_at, created_at
+SELECT id, jti, used_at, created_at, tenant_id, user_id, expires_at
 FROM ip_whitelist_emergency_tokens
 WHERE jti = $1
 `
@@ -121,35 +492,34 @@ func (q *Queries) GetIPWhitelistEmergencyTokenByJTI(ctx context.Context, jti pgt
 		&i.Jti,
 		&i.UsedAt,
 		&i.CreatedAt,
+		&i.TenantID,
+		&i.UserID,
+		&i.ExpiresAt,
 	)
 	return &i, err
 }
 
 const GetIPWhitelistForMiddleware = `-- name: GetIPWhitelistForMiddleware :many
-WITH tenant_features AS (
-    SELECT 
-        tenants.id,
-        (tenants.enterprise_features->'ip_whitelist'->>'enabled')::boolean as ip_whitelist_enabled,
-        (tenants.enterprise_features->'ip_whitelist'->>'allow_internal_admin_bypass')::boolean as allow_internal_bypass
-    FROM tenants WHERE tenants.id = $1
-)
-SELECT 
-    tenant_features.ip_whitelist_enabled,
-    tenant_features.allow_internal_bypass,
-    tenant_ip_whitelist.ip_address::text as ip_address
-FROM tenant_features
-JOIN tenant_ip_whitelist ON tenant_features.id = tenant_ip_whitelist.tenant_id
-WHERE tenant_features.ip_whitelist_enabled = true
+SELECT
+    tenant_ip_whitelist.ip_address::text as ip_address,
+    -- The cached matcher is reloaded when the first of these passes.
+    tenant_ip_whitelist.expires_at,
+    -- NULL for tenant rules.
+    tenant_ip_whitelist.scope_id
+FROM tenant_ip_whitelist
+WHERE tenant_ip_whitelist.tenant_id = $1
+    -- Expired rules stop matching before the maintenance runner deletes them.
+    AND (tenant_ip_whitelist.expires_at IS NULL OR tenant_ip_whitelist.expires_at > CURRENT_TIMESTAMP)
 `
 
 type GetIPWhitelistForMiddlewareRow struct {
-	IpWhitelistEnabled  bool   `json:"ip_whitelist_enabled"`
-	AllowInternalBypass bool   `json:"allow_internal_bypass"`
-	IpAddress           string `json:"ip_address"`
+	IpAddress string             `json:"ip_address"`
+	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
+	ScopeID   pgtype.UUID        `json:"scope_id"`
 }
 
-func (q *Queries) GetIPWhitelistForMiddleware(ctx context.Context, id pgtype.UUID) ([]*GetIPWhitelistForMiddlewareRow, error) {
-	rows, err := q.db.Query(ctx, GetIPWhitelistForMiddleware, id)
+func (q *Queries) GetIPWhitelistForMiddleware(ctx context.Context, tenantID pgtype.UUID) ([]*GetIPWhitelistForMiddlewareRow, error) {
+	rows, err := q.db.Query(ctx, GetIPWhitelistForMiddleware, tenantID)
 	if err != nil {
 		return nil, err
 	}
@@ -157,7 +527,64 @@ func (q *Queries) GetIPWhitelistForMiddleware(ctx context.Context, id pgtype.UUI
 	items := []*GetIPWhitelistForMiddlewareRow{}
 	for rows.Next() {
 		var i GetIPWhitelistForMiddlewareRow
-		if err := rows.Scan(&i.IpWhitelistEnabled, &i.AllowInternalBypass, &i.IpAddress); err != nil {
+		if err := rows.Scan(&i.IpAddress, &i.ExpiresAt, &i.ScopeID); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const GetIPWhitelistMonitorReport = `-- name: GetIPWhitelistMonitorReport :many
+SELECT
+    host(ip_whitelist_monitor_hits.ip_address) AS ip_address,
+    users.id AS user_id,
+    users.name AS user_name,
+    users.email AS user_email,
+    SUM(ip_whitelist_monitor_hits.hits)::bigint AS hits,
+    MAX(ip_whitelist_monitor_hits.last_seen_at)::timestamptz AS last_seen_at
+FROM ip_whitelist_monitor_hits
+JOIN users ON users.id = ip_whitelist_monitor_hits.user_id
+WHERE ip_whitelist_monitor_hits.tenant_id = $1
+  AND ip_whitelist_monitor_hits.hour >= date_trunc('hour', $2::timestamptz)
+GROUP BY ip_whitelist_monitor_hits.ip_address, users.id, users.name, users.email
+ORDER BY hits DESC, ip_address, users.email
+`
+
+type GetIPWhitelistMonitorReportParams struct {
+	TenantID pgtype.UUID        `json:"tenant_id"`
+	Since    pgtype.Timestamptz `json:"since"`
+}
+
+type GetIPWhitelistMonitorReportRow struct {
+	IpAddress  string             `json:"ip_address"`
+	UserID     pgtype.UUID        `json:"user_id"`
+	UserName   string             `json:"user_name"`
+	UserEmail  string             `json:"user_email"`
+	Hits       int64              `json:"hits"`
+	LastSeenAt pgtype.Timestamptz `json:"last_seen_at"`
+}
+
+func (q *Queries) GetIPWhitelistMonitorReport(ctx context.Context, arg *GetIPWhitelistMonitorReportParams) ([]*GetIPWhitelistMonitorReportRow, error) {
+	rows, err := q.db.Query(ctx, GetIPWhitelistMonitorReport, arg.TenantID, arg.Since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*GetIPWhitelistMonitorReportRow{}
+	for rows.Next() {
+		var i GetIPWhitelistMonitorReportRow
+		if err := rows.Scan(
+			&i.IpAddress,
+			&i.UserID,
+			&i.UserName,
+			&i.UserEmail,
+			&i.Hits,
+			&i.LastSeenAt,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, &i)
@@ -168,8 +595,28 @@ func (q *Queries) GetIPWhitelistForMiddleware(ctx context.Context, id pgtype.UUI
 	return items, nil
 }
 
+const GetIPWhitelistRecoveryCodeStatus = `-- name: GetIPWhitelistRecoveryCodeStatus :one
+SELECT
+    COUNT(*) FILTER (WHERE used_at IS NULL) AS remaining,
+    MAX(created_at)::timestamptz AS issued_at
+FROM ip_whitelist_recovery_codes
+WHERE tenant_id = $1
+`
+
+type GetIPWhitelistRecoveryCodeStatusRow struct {
+	Remaining int64              `json:"remaining"`
+	IssuedAt  pgtype.Timestamptz `json:"issued_at"`
+}
+
+func (q *Queries) GetIPWhitelistRecoveryCodeStatus(ctx context.Context, tenantID pgtype.UUID) (*GetIPWhitelistRecoveryCodeStatusRow, error) {
+	row := q.db.QueryRow(ctx, GetIPWhitelistRecoveryCodeStatus, tenantID)
+	var i GetIPWhitelistRecoveryCodeStatusRow
+	err := row.Scan(&i.Remaining, &i.IssuedAt)
+	return &i, err
+}
+
 const GetIPWhitelistRuleByID = `-- name: GetIPWhitelistRuleByID :one
-SELECT id, tenant_id, ip_address, label, created_by, created_at
+SELECT id, tenant_id, ip_address, label, created_by, created_at, expires_at, expiry_warned_at, scope_id
 FROM tenant_ip_whitelist
 WHERE id = $1 AND tenant_id = $2
 `
@@ -189,12 +636,63 @@ func (q *Queries) GetIPWhitelistRuleByID(ctx context.Context, arg *GetIPWhitelis
 		&i.Label,
 		&i.CreatedBy,
 		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.ExpiryWarnedAt,
+		&i.ScopeID,
+	)
+	return &i, err
+}
+
+const GetIPWhitelistScopeByID = `-- name: GetIPWhitelistScopeByID :one
+SELECT
+    ip_whitelist_scopes.id,
+    ip_whitelist_scopes.role_id,
+    roles.name AS role_name,
+    ip_whitelist_scopes.user_id,
+    users.name AS user_name,
+    users.email AS user_email,
+    ip_whitelist_scopes.exempt,
+    ip_whitelist_scopes.created_at
+FROM ip_whitelist_scopes
+LEFT JOIN roles ON roles.id = ip_whitelist_scopes.role_id
+LEFT JOIN users ON users.id = ip_whitelist_scopes.user_id
+WHERE ip_whitelist_scopes.id = $1 AND ip_whitelist_scopes.tenant_id = $2
+`
+
+type GetIPWhitelistScopeByIDParams struct {
+	ID       pgtype.UUID `json:"id"`
+	TenantID pgtype.UUID `json:"tenant_id"`
+}
+
+type GetIPWhitelistScopeByIDRow struct {
+	ID        pgtype.UUID        `json:"id"`
+	RoleID    pgtype.UUID        `json:"role_id"`
+	RoleName  pgtype.Text        `json:"role_name"`
+	UserID    pgtype.UUID        `json:"user_id"`
+	UserName  pgtype.Text        `json:"user_name"`
+	UserEmail pgtype.Text        `json:"user_email"`
+	Exempt    bool               `json:"exempt"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+func (q *Queries) GetIPWhitelistScopeByID(ctx context.Context, arg *GetIPWhitelistScopeByIDParams) (*GetIPWhitelistScopeByIDRow, error) {
+	row := q.db.QueryRow(ctx, GetIPWhitelistScopeByID, arg.ID, arg.TenantID)
+	var i GetIPWhitelistScopeByIDRow
+	err := row.Scan(
+		&i.ID,
+		&i.RoleID,
+		&i.RoleName,
+		&i.UserID,
+		&i.UserName,
+		&i.UserEmail,
+		&i.Exempt,
+		&i.CreatedAt,
 	)
 	return &i, err
 }
 
 const GetTenantIPWhitelist = `-- name: GetTenantIPWhitelist :many
-SELECT id, tenant_id, ip_address, label, created_by, created_at
+SELECT id, tenant_id, ip_address, label, created_by, created_at, expires_at, expiry_warned_at, scope_id
 FROM tenant_ip_whitelist
 WHERE tenant_id = $1
 ORDER BY created_at ASC
@@ -216,6 +714,9 @@ func (q *Queries) GetTenantIPWhitelist(ctx context.Context, tenantID pgtype.UUID
 			&i.Label,
 			&i.CreatedBy,
 			&i.CreatedAt,
+			&i.ExpiresAt,
+			&i.ExpiryWarnedAt,
+			&i.ScopeID,
 		); err != nil {
 			return nil, err
 		}
@@ -231,6 +732,8 @@ const GetTenantIPWhitelistCIDRs = `-- name: GetTenantIPWhitelistCIDRs :many
 SELECT ip_address::text as ip_address
 FROM tenant_ip_whitelist
 WHERE tenant_id = $1
+    AND scope_id IS NULL
+    AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
 ORDER BY created_at ASC
 `
 
@@ -254,6 +757,232 @@ func (q *Queries) GetTenantIPWhitelistCIDRs(ctx context.Context, tenantID pgtype
 	return items, nil
 }
 
+const GetTenantIPWhitelistCountries = `-- name: GetTenantIPWhitelistCountries :many
+SELECT id, tenant_id, country_code, action, created_by, created_at FROM tenant_ip_whitelist_countries
+WHERE tenant_id = $1
+ORDER BY action, country_code
+`
+
+func (q *Queries) GetTenantIPWhitelistCountries(ctx context.Context, tenantID pgtype.UUID) ([]*TenantIpWhitelistCountry, error) {
+	rows, err := q.db.Query(ctx, GetTenantIPWhitelistCountries, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*TenantIpWhitelistCountry{}
+	for rows.Next() {
+		var i TenantIpWhitelistCountry
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.CountryCode,
+			&i.Action,
+			&i.CreatedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const GetTenantIPWhitelistScopes = `-- name: GetTenantIPWhitelistScopes :many
+SELECT
+    ip_whitelist_scopes.id,
+    ip_whitelist_scopes.role_id,
+    roles.name AS role_name,
+    ip_whitelist_scopes.user_id,
+    users.name AS user_name,
+    users.email AS user_email,
+    ip_whitelist_scopes.exempt,
+    ip_whitelist_scopes.created_at
+FROM ip_whitelist_scopes
+LEFT JOIN roles ON roles.id = ip_whitelist_scopes.role_id
+LEFT JOIN users ON users.id = ip_whitelist_scopes.user_id
+WHERE ip_whitelist_scopes.tenant_id = $1
+ORDER BY ip_whitelist_scopes.created_at ASC
+`
+
+type GetTenantIPWhitelistScopesRow struct {
+	ID        pgtype.UUID        `json:"id"`
+	RoleID    pgtype.UUID        `json:"role_id"`
+	RoleName  pgtype.Text        `json:"role_name"`
+	UserID    pgtype.UUID        `json:"user_id"`
+	UserName  pgtype.Text        `json:"user_name"`
+	UserEmail pgtype.Text        `json:"user_email"`
+	Exempt    bool               `json:"exempt"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+func (q *Queries) GetTenantIPWhitelistScopes(ctx context.Context, tenantID pgtype.UUID) ([]*GetTenantIPWhitelistScopesRow, error) {
+	rows, err := q.db.Query(ctx, GetTenantIPWhitelistScopes, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*GetTenantIPWhitelistScopesRow{}
+	for rows.Next() {
+		var i GetTenantIPWhitelistScopesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.RoleID,
+			&i.RoleName,
+			&i.UserID,
+			&i.UserName,
+			&i.UserEmail,
+			&i.Exempt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const GetUnusedIPWhitelistRecoveryCode = `-- name: GetUnusedIPWhitelistRecoveryCode :one
+SELECT id, tenant_id
+FROM ip_whitelist_recovery_codes
+WHERE code_hash = $1 AND used_at IS NULL
+`
+
+type GetUnusedIPWhitelistRecoveryCodeRow struct {
+	ID       pgtype.UUID `json:"id"`
+	TenantID pgtype.UUID `json:"tenant_id"`
+}
+
+func (q *Queries) GetUnusedIPWhitelistRecoveryCode(ctx context.Context, codeHash string) (*GetUnusedIPWhitelistRecoveryCodeRow, error) {
+	row := q.db.QueryRow(ctx, GetUnusedIPWhitelistRecoveryCode, codeHash)
+	var i GetUnusedIPWhitelistRecoveryCodeRow
+	err := row.Scan(&i.ID, &i.TenantID)
+	return &i, err
+}
+
+const GetUserIPWhitelistScopes = `-- name: GetUserIPWhitelistScopes :many
+-- The user's roles are counted the way permissions are: directly assigned,
+-- unexpired roles (only default roles while RBAC is off) and every role they
+-- include, transitively.
+WITH RECURSIVE granted_roles AS (
+    SELECT user_roles.role_id
+    FROM user_roles
+    JOIN roles ON roles.id = user_roles.role_id
+    WHERE user_roles.user_id = $1
+        AND user_roles.tenant_id = $2
+        AND ($3::boolean = true OR roles.is_default = true)
+        AND (user_roles.expires_at IS NULL OR user_roles.expires_at > CURRENT_TIMESTAMP)
+    UNION
+    SELECT role_inclusions.included_role_id
+    FROM granted_roles
+    JOIN role_inclusions ON role_inclusions.role_id = granted_roles.role_id
+)
+SELECT
+    ip_whitelist_scopes.id,
+    ip_whitelist_scopes.exempt,
+    ip_whitelist_scopes.user_id IS NOT NULL AS is_user_scope
+FROM ip_whitelist_scopes
+WHERE ip_whitelist_scopes.tenant_id = $2
+    AND (
+        ip_whitelist_scopes.user_id = $1
+        OR ip_whitelist_scopes.role_id IN (SELECT granted_roles.role_id FROM granted_roles)
+    )
+`
+
+type GetUserIPWhitelistScopesParams struct {
+	UserID      pgtype.UUID `json:"user_id"`
+	TenantID    pgtype.UUID `json:"tenant_id"`
+	RbacEnabled bool        `json:"rbac_enabled"`
+}
+
+type GetUserIPWhitelistScopesRow struct {
+	ID          pgtype.UUID `json:"id"`
+	Exempt      bool        `json:"exempt"`
+	IsUserScope bool        `json:"is_user_scope"`
+}
+
+// The user's roles are counted the way permissions are: directly assigned,
+// unexpired roles (only default roles while RBAC is off) and every role they
+// include, transitively.
+func (q *Queries) GetUserIPWhitelistScopes(ctx context.Context, arg *GetUserIPWhitelistScopesParams) ([]*GetUserIPWhitelistScopesRow, error) {
+	rows, err := q.db.Query(ctx, GetUserIPWhitelistScopes, arg.UserID, arg.TenantID, arg.RbacEnabled)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*GetUserIPWhitelistScopesRow{}
+	for rows.Next() {
+		var i GetUserIPWhitelistScopesRow
+		if err := rows.Scan(&i.ID, &i.Exempt, &i.IsUserScope); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const HasOpenIPWhitelistBreakGlassRequest = `-- name: HasOpenIPWhitelistBreakGlassRequest :one
+SELECT EXISTS(
+    SELECT 1
+    FROM ip_whitelist_break_glass_requests
+    WHERE tenant_id = $1
+      AND completed_at IS NULL
+      AND expires_at > CURRENT_TIMESTAMP
+) AS exists
+`
+
+func (q *Queries) HasOpenIPWhitelistBreakGlassRequest(ctx context.Context, tenantID pgtype.UUID) (bool, error) {
+	row := q.db.QueryRow(ctx, HasOpenIPWhitelistBreakGlassRequest, tenantID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const HasPendingIPWhitelistEmergencyToken = `-- name: HasPendingIPWhitelistEmergencyToken :one
+SELECT EXISTS(
+    SELECT 1
+    FROM ip_whitelist_emergency_tokens
+    WHERE tenant_id = $1
+      AND user_id = $2
+      AND used_at IS NULL
+      AND expires_at > CURRENT_TIMESTAMP
+) AS exists
+`
+
+type HasPendingIPWhitelistEmergencyTokenParams struct {
+	TenantID pgtype.UUID `json:"tenant_id"`
+	UserID   pgtype.UUID `json:"user_id"`
+}
+
+func (q *Queries) HasPendingIPWhitelistEmergencyToken(ctx context.Context, arg *HasPendingIPWhitelistEmergencyTokenParams) (bool, error) {
+	row := q.db.QueryRow(ctx, HasPendingIPWhitelistEmergencyToken, arg.TenantID, arg.UserID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const LockIPWhitelistBreakGlassRequest = `-- name: LockIPWhitelistBreakGlassRequest :one
+SELECT completed_at
+FROM ip_whitelist_break_glass_requests
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) LockIPWhitelistBreakGlassRequest(ctx context.Context, id pgtype.UUID) (pgtype.Timestamptz, error) {
+	row := q.db.QueryRow(ctx, LockIPWhitelistBreakGlassRequest, id)
+	var completed_at pgtype.Timestamptz
+	err := row.Scan(&completed_at)
+	return completed_at, err
+}
+
 const MarkIPWhitelistEmergencyTokenAsUsed = `-- name: MarkIPWhitelistEmergencyTokenAsUsed :exec
 UPDATE ip_whitelist_emergency_tokens
 SET used_at = CURRENT_TIMESTAMP
@@ -265,6 +994,24 @@ func (q *Queries) MarkIPWhitelistEmergencyTokenAsUsed(ctx context.Context, jti p
 	return err
 }
 
+const RecordIPWhitelistMonitorHit = `-- name: RecordIPWhitelistMonitorHit :exec
+INSERT INTO ip_whitelist_monitor_hits (tenant_id, user_id, ip_address, hour)
+VALUES ($1, $2, $3, date_trunc('hour', CURRENT_TIMESTAMP))
+ON CONFLICT (tenant_id, hour, user_id, ip_address)
+DO UPDATE SET hits = ip_whitelist_monitor_hits.hits + 1, last_seen_at = CURRENT_TIMESTAMP
+`
+
+type RecordIPWhitelistMonitorHitParams struct {
+	TenantID  pgtype.UUID `json:"tenant_id"`
+	UserID    pgtype.UUID `json:"user_id"`
+	IpAddress netip.Addr  `json:"ip_address"`
+}
+
+func (q *Queries) RecordIPWhitelistMonitorHit(ctx context.Context, arg *RecordIPWhitelistMonitorHitParams) error {
+	_, err := q.db.Exec(ctx, RecordIPWhitelistMonitorHit, arg.TenantID, arg.UserID, arg.IpAddress)
+	return err
+}
+
 const RemoveIPFromWhitelist = `-- name: RemoveIPFromWhitelist :exec
 DELETE FROM tenant_ip_whitelist
 WHERE id = $1 AND tenant_id = $2
@@ -280,6 +1027,21 @@ func (q *Queries) RemoveIPFromWhitelist(ctx context.Context, arg *RemoveIPFromWh
 	return err
 }
 
+const TenantHasIPWhitelistScopes = `-- name: TenantHasIPWhitelistScopes :one
+SELECT EXISTS(
+    SELECT 1
+    FROM ip_whitelist_scopes
+    WHERE tenant_id = $1
+) AS exists
+`
+
+func (q *Queries) TenantHasIPWhitelistScopes(ctx context.Context, tenantID pgtype.UUID) (bool, error) {
+	row := q.db.QueryRow(ctx, TenantHasIPWhitelistScopes, tenantID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
 const UpdateIPWhitelistLabel = `-- name: UpdateIPWhitelistLabel :exec
 UPDATE tenant_ip_whitelist
 SET label = $1
@@ -296,3 +1058,39 @@ func (q *Queries) UpdateIPWhitelistLabel(ctx context.Context, arg *UpdateIPWhite
 	_, err := q.db.Exec(ctx, UpdateIPWhitelistLabel, arg.Label, arg.ID, arg.TenantID)
 	return err
 }
+
+const UpdateIPWhitelistScopeExempt = `-- name: UpdateIPWhitelistScopeExempt :exec
+UPDATE ip_whitelist_scopes
+SET exempt = $1
+WHERE id = $2 AND tenant_id = $3
+`
+
+type UpdateIPWhitelistScopeExemptParams struct {
+	Exempt   bool        `json:"exempt"`
+	ID       pgtype.UUID `json:"id"`
+	TenantID pgtype.UUID `json:"tenant_id"`
+}
+
+func (q *Queries) UpdateIPWhitelistScopeExempt(ctx context.Context, arg *UpdateIPWhitelistScopeExemptParams) error {
+	_, err := q.db.Exec(ctx, UpdateIPWhitelistScopeExempt, arg.Exempt, arg.ID, arg.TenantID)
+	return err
+}
+
+const UseIPWhitelistRecoveryCode = `-- name: UseIPWhitelistRecoveryCode :execrows
+UPDATE ip_whitelist_recovery_codes
+SET used_at = CURRENT_TIMESTAMP, used_by = $1
+WHERE id = $2 AND used_at IS NULL
+`
+
+type UseIPWhitelistRecoveryCodeParams struct {
+	UsedBy pgtype.UUID `json:"used_by"`
+	ID     pgtype.UUID `json:"id"`
+}
+
+func (q *Queries) UseIPWhitelistRecoveryCode(ctx context.Context, arg *UseIPWhitelistRecoveryCodeParams) (int64, error) {
+	result, err := q.db.Exec(ctx, UseIPWhitelistRecoveryCode, arg.UsedBy, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
@@ -10,6 +10,22 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type AccessRequest struct {
+	ID            pgtype.UUID        `json:"id"`
+	TenantID      pgtype.UUID        `json:"tenant_id"`
+	RequesterID   pgtype.UUID        `json:"requester_id"`
+	RoleID        pgtype.UUID        `json:"role_id"`
+	Justification string             `json:"justification"`
+	DurationHours int32              `json:"duration_hours"`
+	Status        string             `json:"status"`
+	ExpiresAt     pgtype.Timestamptz `json:"expires_at"`
+	DecidedBy     pgtype.UUID        `json:"decided_by"`
+	DecidedAt     pgtype.Timestamptz `json:"decided_at"`
+	DecisionNote  pgtype.Text        `json:"decision_note"`
+	GrantedUntil  pgtype.Timestamptz `json:"granted_until"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+}
+
 type AuditLog struct {
 	ID           pgtype.UUID        `json:"id"`
 	TenantID     pgtype.UUID        `json:"tenant_id"`
@@ -44,11 +60,60 @@ type InvitationToken struct {
 	UsedAt    pgtype.Timestamptz `json:"used_at"`
 }
 
+type IpWhitelistBreakGlassApproval struct {
+	ID         pgtype.UUID        `json:"id"`
+	RequestID  pgtype.UUID        `json:"request_id"`
+	UserID     pgtype.UUID        `json:"user_id"`
+	TokenHash  string             `json:"token_hash"`
+	ApprovedAt pgtype.Timestamptz `json:"approved_at"`
+}
+
+type IpWhitelistBreakGlassRequest struct {
+	ID          pgtype.UUID        `json:"id"`
+	TenantID    pgtype.UUID        `json:"tenant_id"`
+	RequestedBy pgtype.UUID        `json:"requested_by"`
+	ExpiresAt   pgtype.Timestamptz `json:"expires_at"`
+	CompletedAt pgtype.Timestamptz `json:"completed_at"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+}
+
 type IpWhitelistEmergencyToken struct {
 	ID        pgtype.UUID        `json:"id"`
 	Jti       pgtype.UUID        `json:"jti"`
 	UsedAt    pgtype.Timestamptz `json:"used_at"`
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	TenantID  pgtype.UUID        `json:"tenant_id"`
+	UserID    pgtype.UUID        `json:"user_id"`
+	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
+}
+
+type IpWhitelistMonitorHit struct {
+	TenantID   pgtype.UUID        `json:"tenant_id"`
+	UserID     pgtype.UUID        `json:"user_id"`
+	IpAddress  netip.Addr         `json:"ip_address"`
+	Hour       pgtype.Timestamptz `json:"hour"`
+	Hits       int32              `json:"hits"`
+	LastSeenAt pgtype.Timestamptz `json:"last_seen_at"`
+}
+
+type IpWhitelistRecoveryCode struct {
+	ID        pgtype.UUID        `json:"id"`
+	TenantID  pgtype.UUID        `json:"tenant_id"`
+	CodeHash  string             `json:"code_hash"`
+	CreatedBy pgtype.UUID        `json:"created_by"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	UsedAt    pgtype.Timestamptz `json:"used_at"`
+	UsedBy    pgtype.UUID        `json:"used_by"`
+}
+
+type IpWhitelistScope struct {
+	ID        pgtype.UUID        `json:"id"`
+	TenantID  pgtype.UUID        `json:"tenant_id"`
+	RoleID    pgtype.UUID        `json:"role_id"`
+	UserID    pgtype.UUID        `json:"user_id"`
+	Exempt    bool               `json:"exempt"`
+	CreatedBy pgtype.UUID        `json:"created_by"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
 }
 
 type PasswordResetToken struct {
@@ -61,12 +126,14 @@ type PasswordResetToken struct {
 }
 
 type Permission struct {
-	ID          pgtype.UUID        `json:"id"`
-	Resource    string             `json:"resource"`
-	Action      string             `json:"action"`
-	Description string             `json:"description"`
-	CreatedAt   pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+	ID            pgtype.UUID        `json:"id"`
+	Resource      string             `json:"resource"`
+	Action        string             `json:"action"`
+	Description   string             `json:"description"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt     pgtype.Timestamptz `json:"updated_at"`
+	RemovedAt     pgtype.Timestamptz `json:"removed_at"`
+	DescriptionEn string             `json:"description_en"`
 }
 
 type RefreshToken struct {
@@ -79,6 +146,7 @@ type RefreshToken struct {
 	CreatedAt  pgtype.Timestamptz `json:"created_at"`
 	UsedAt     pgtype.Timestamptz `json:"used_at"`
 	RevokedAt  pgtype.Timestamptz `json:"revoked_at"`
+	TenantID   pgtype.UUID        `json:"tenant_id"`
 }
 
 type Role struct {
@@ -91,6 +159,27 @@ type Role struct {
 	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
 }
 
+type RoleConflictSet struct {
+	ID        pgtype.UUID        `json:"id"`
+	TenantID  pgtype.UUID        `json:"tenant_id"`
+	Name      string             `json:"name"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type RoleConflictSetRole struct {
+	ConflictSetID pgtype.UUID        `json:"conflict_set_id"`
+	RoleID        pgtype.UUID        `json:"role_id"`
+	TenantID      pgtype.UUID        `json:"tenant_id"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+}
+
+type RoleInclusion struct {
+	RoleID         pgtype.UUID        `json:"role_id"`
+	IncludedRoleID pgtype.UUID        `json:"included_role_id"`
+	TenantID       pgtype.UUID        `json:"tenant_id"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+}
+
 type RolePermission struct {
 	RoleID       pgtype.UUID        `json:"role_id"`
 	PermissionID pgtype.UUID        `json:"permission_id"`
@@ -115,15 +204,33 @@ type Tenant struct {
 	UpdatedAt          pgtype.Timestamptz `json:"updated_at"`
 }
 
-type TenantIpWhitelist struct {
-	ID        pgtype.UUID        `json:"id"`
+type TenantMembership struct {
 	TenantID  pgtype.UUID        `json:"tenant_id"`
-	IpAddress netip.Prefix       `json:"ip_address"`
-	Label     pgtype.Text        `json:"label"`
-	CreatedBy pgtype.UUID        `json:"created_by"`
+	UserID    pgtype.UUID        `json:"user_id"`
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
 }
 
+type TenantIpWhitelist struct {
+	ID             pgtype.UUID        `json:"id"`
+	TenantID       pgtype.UUID        `json:"tenant_id"`
+	IpAddress      netip.Prefix       `json:"ip_address"`
+	Label          pgtype.Text        `json:"label"`
+	CreatedBy      pgtype.UUID        `json:"created_by"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	ExpiresAt      pgtype.Timestamptz `json:"expires_at"`
+	ExpiryWarnedAt pgtype.Timestamptz `json:"expiry_warned_at"`
+	ScopeID        pgtype.UUID        `json:"scope_id"`
+}
+
+type TenantIpWhitelistCountry struct {
+	ID          pgtype.UUID        `json:"id"`
+	TenantID    pgtype.UUID        `json:"tenant_id"`
+	CountryCode string             `json:"country_code"`
+	Action      string             `json:"action"`
+	CreatedBy   pgtype.UUID        `json:"created_by"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+}
+
 type User struct {
 	ID              pgtype.UUID        `json:"id"`
 	TenantID        pgtype.UUID        `json:"tenant_id"`
@@ -137,6 +244,29 @@ type User struct {
 	DeletedAt       pgtype.Timestamptz `json:"deleted_at"`
 	Status          string             `json:"status"`
 	ExternalSsoID   pgtype.Text        `json:"external_sso_id"`
+	LastLoginAt     pgtype.Timestamptz `json:"last_login_at"`
+}
+
+type UserGroup struct {
+	ID        pgtype.UUID        `json:"id"`
+	TenantID  pgtype.UUID        `json:"tenant_id"`
+	Name      string             `json:"name"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type UserGroupAdmin struct {
+	GroupID   pgtype.UUID        `json:"group_id"`
+	UserID    pgtype.UUID        `json:"user_id"`
+	TenantID  pgtype.UUID        `json:"tenant_id"`
+	GrantedBy pgtype.UUID        `json:"granted_by"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type UserGroupMember struct {
+	GroupID   pgtype.UUID        `json:"group_id"`
+	UserID    pgtype.UUID        `json:"user_id"`
+	TenantID  pgtype.UUID        `json:"tenant_id"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
 }
 
 type UserRole struct {
@@ -144,4 +274,6 @@ type UserRole struct {
 	RoleID    pgtype.UUID        `json:"role_id"`
 	TenantID  pgtype.UUID        `json:"tenant_id"`
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
+	GrantedBy pgtype.UUID        `json:"granted_by"`
 }
@@ -13,80 +13,191 @@ import (
 type Querier interface {
 	ActivateInvitedUser(ctx context.Context, arg *ActivateInvitedUserParams) error
 	AddIPToWhitelist(ctx context.Context, arg *AddIPToWhitelistParams) (*TenantIpWhitelist, error)
+	AddIPWhitelistCountry(ctx context.Context, arg *AddIPWhitelistCountryParams) (*TenantIpWhitelistCountry, error)
 	AddRolesToUser(ctx context.Context, arg []*AddRolesToUserParams) (int64, error)
+	AddTenantMembership(ctx context.Context, arg *AddTenantMembershipParams) error
+	AddUserGroupAdminsBulk(ctx context.Context, arg *AddUserGroupAdminsBulkParams) error
+	AddUserGroupMembersBulk(ctx context.Context, arg *AddUserGroupMembersBulkParams) error
+	ApproveIPWhitelistBreakGlassRequest(ctx context.Context, id pgtype.UUID) (int64, error)
 	AssignRoleToUser(ctx context.Context, arg *AssignRoleToUserParams) error
 	CheckIPExists(ctx context.Context, arg *CheckIPExistsParams) (bool, error)
+	CheckRoleConflictSetNameExists(ctx context.Context, arg *CheckRoleConflictSetNameExistsParams) (bool, error)
 	CheckRoleInUse(ctx context.Context, arg *CheckRoleInUseParams) (bool, error)
+	CheckRoleIncluded(ctx context.Context, arg *CheckRoleIncludedParams) (bool, error)
 	CheckRoleNameExists(ctx context.Context, arg *CheckRoleNameExistsParams) (bool, error)
+	CheckUserGroupNameExists(ctx context.Context, arg *CheckUserGroupNameExistsParams) (bool, error)
+	ClaimExpiringIPWhitelistRules(ctx context.Context, arg *ClaimExpiringIPWhitelistRulesParams) ([]*ClaimExpiringIPWhitelistRulesRow, error)
 	ClearTenantIPWhitelist(ctx context.Context, tenantID pgtype.UUID) error
+	CompleteIPWhitelistBreakGlassRequest(ctx context.Context, id pgtype.UUID) error
+	CopyRoleConflictSetMemberships(ctx context.Context, arg *CopyRoleConflictSetMembershipsParams) error
 	CountAuditLogs(ctx context.Context, arg *CountAuditLogsParams) (int64, error)
+	CountTenantAdministrators(ctx context.Context, arg *CountTenantAdministratorsParams) (*CountTenantAdministratorsRow, error)
 	CountTenantIPWhitelistRules(ctx context.Context, tenantID pgtype.UUID) (int64, error)
 	CountUsersByTenantID(ctx context.Context, arg *CountUsersByTenantIDParams) (int64, error)
+	CountUsersFiltered(ctx context.Context, arg *CountUsersFilteredParams) (int64, error)
+	CreateAccessRequest(ctx context.Context, arg *CreateAccessRequestParams) (pgtype.UUID, error)
 	CreateEmailChangeToken(ctx context.Context, arg *CreateEmailChangeTokenParams) error
+	CreateIPWhitelistBreakGlassApproval(ctx context.Context, arg *CreateIPWhitelistBreakGlassApprovalParams) error
+	CreateIPWhitelistBreakGlassRequest(ctx context.Context, arg *CreateIPWhitelistBreakGlassRequestParams) (pgtype.UUID, error)
 	// IP Whitelist Emergency Token Operations
-	CreateIPWhitelistEmergencyToken(ctx context.Context, jti pgtype.UUID) (*IpWhitelistEmergencyToken, error)
+	CreateIPWhitelistEmergencyToken(ctx context.Context, arg *CreateIPWhitelistEmergencyTokenParams) (*IpWhitelistEmergencyToken, error)
+	CreateIPWhitelistRecoveryCode(ctx context.Context, arg *CreateIPWhitelistRecoveryCodeParams) error
+	CreateIPWhitelistScope(ctx context.Context, arg *CreateIPWhitelistScopeParams) (pgtype.UUID, error)
 	CreateInvitationToken(ctx context.Context, arg *CreateInvitationTokenParams) (*InvitationToken, error)
 	CreatePasswordResetToken(ctx context.Context, arg *CreatePasswordResetTokenParams) (*PasswordResetToken, error)
 	CreateRefreshToken(ctx context.Context, arg *CreateRefreshTokenParams) (*RefreshToken, error)
 	CreateRole(ctx context.Context, arg *CreateRoleParams) (*CreateRoleRow, error)
+	CreateRoleConflictSet(ctx context.Context, arg *CreateRoleConflictSetParams) (pgtype.UUID, error)
+	CreateRoleConflictSetRolesBulk(ctx context.Context, arg *CreateRoleConflictSetRolesBulkParams) error
+	CreateRoleInclusionsBulk(ctx context.Context, arg *CreateRoleInclusionsBulkParams) error
 	CreateRolePermissionsBulk(ctx context.Context, arg *CreateRolePermissionsBulkParams) error
 	CreateSSOAuthRequest(ctx context.Context, arg *CreateSSOAuthRequestParams) error
 	CreateTenant(ctx context.Context, arg *CreateTenantParams) (*Tenant, error)
 	CreateUser(ctx context.Context, arg *CreateUserParams) (*User, error)
+	CreateUserGroup(ctx context.Context, arg *CreateUserGroupParams) (pgtype.UUID, error)
+	DecideAccessRequest(ctx context.Context, arg *DecideAccessRequestParams) error
 	DeleteEmailChangeTokensByUserID(ctx context.Context, userID pgtype.UUID) error
-	DeleteExpiredSSORequests(ctx context.Context) error
+	DeleteIPWhitelistCountry(ctx context.Context, arg *DeleteIPWhitelistCountryParams) error
+	// IP Whitelist Break-Glass Operations
+	DeleteIPWhitelistRecoveryCodes(ctx context.Context, tenantID pgtype.UUID) error
+	DeleteIPWhitelistScope(ctx context.Context, arg *DeleteIPWhitelistScopeParams) (int64, error)
 	DeleteInvitationTokensByUserIDAndTenantID(ctx context.Context, arg *DeleteInvitationTokensByUserIDAndTenantIDParams) error
 	DeletePasswordResetTokenByUserID(ctx context.Context, userID pgtype.UUID) error
 	DeleteRefreshTokensByUserID(ctx context.Context, userID pgtype.UUID) error
 	DeleteRole(ctx context.Context, arg *DeleteRoleParams) error
+	DeleteRoleConflictSet(ctx context.Context, arg *DeleteRoleConflictSetParams) error
+	DeleteRoleInclusions(ctx context.Context, arg *DeleteRoleInclusionsParams) error
 	DeleteRolePermissions(ctx context.Context, arg *DeleteRolePermissionsParams) error
 	DeleteSSORequestReturning(ctx context.Context, requestID string) (*SsoAuthRequest, error)
+	DeleteUserGroup(ctx context.Context, arg *DeleteUserGroupParams) error
+	DeleteUserGroupAdmins(ctx context.Context, arg *DeleteUserGroupAdminsParams) error
+	DeleteUserGroupMembers(ctx context.Context, arg *DeleteUserGroupMembersParams) error
 	ExistsUserWithEmail(ctx context.Context, email string) (bool, error)
+	ExpirePendingAccessRequests(ctx context.Context, arg *ExpirePendingAccessRequestsParams) ([]*ExpirePendingAccessRequestsRow, error)
+	GetAccessRequestApprovers(ctx context.Context, arg *GetAccessRequestApproversParams) ([]*GetAccessRequestApproversRow, error)
+	GetAccessRequestForDecision(ctx context.Context, arg *GetAccessRequestForDecisionParams) (*GetAccessRequestForDecisionRow, error)
+	GetAdministeredGroupIDs(ctx context.Context, arg *GetAdministeredGroupIDsParams) ([]pgtype.UUID, error)
 	GetAllPermissions(ctx context.Context) ([]*GetAllPermissionsRow, error)
 	GetDefaultViewerRole(ctx context.Context, tenantID pgtype.UUID) (*Role, error)
 	GetEmailChangeTokenByHash(ctx context.Context, tokenHash string) (*EmailChangeToken, error)
+	GetIPWhitelistBreakGlassApprovalByTokenHash(ctx context.Context, tokenHash string) (*GetIPWhitelistBreakGlassApprovalByTokenHashRow, error)
+	GetIPWhitelistBreakGlassApprovers(ctx context.Context, requestID pgtype.UUID) ([]*GetIPWhitelistBreakGlassApproversRow, error)
+	GetIPWhitelistCountryByID(ctx context.Context, arg *GetIPWhitelistCountryByIDParams) (*TenantIpWhitelistCountry, error)
+	GetIPWhitelistEditors(ctx context.Context, arg *GetIPWhitelistEditorsParams) ([]*GetIPWhitelistEditorsRow, error)
+	GetIPWhitelistEmergencyHolders(ctx context.Context, arg *GetIPWhitelistEmergencyHoldersParams) ([]*GetIPWhitelistEmergencyHoldersRow, error)
 	GetIPWhitelistEmergencyTokenByJTI(ctx context.Context, jti pgtype.UUID) (*IpWhitelistEmergencyToken, error)
-	GetIPWhitelistForMiddleware(ctx context.Context, id pgtype.UUID) ([]*GetIPWhitelistForMiddlewareRow, error)
+	GetIPWhitelistForMiddleware(ctx context.Context, tenantID pgtype.UUID) ([]*GetIPWhitelistForMiddlewareRow, error)
+	GetIPWhitelistMonitorReport(ctx context.Context, arg *GetIPWhitelistMonitorReportParams) ([]*GetIPWhitelistMonitorReportRow, error)
+	GetIPWhitelistRecoveryCodeStatus(ctx context.Context, tenantID pgtype.UUID) (*GetIPWhitelistRecoveryCodeStatusRow, error)
 	GetIPWhitelistRuleByID(ctx context.Context, arg *GetIPWhitelistRuleByIDParams) (*TenantIpWhitelist, error)
+	GetIPWhitelistScopeByID(ctx context.Context, arg *GetIPWhitelistScopeByIDParams) (*GetIPWhitelistScopeByIDRow, error)
+	GetIncludedRoleIDs(ctx context.Context, arg *GetIncludedRoleIDsParams) ([]pgtype.UUID, error)
 	GetInvitationByTokenHash(ctx context.Context, tokenHash string) (*InvitationToken, error)
 	GetPasswordResetTokenByHash(ctx context.Context, tokenHash string) (*PasswordResetToken, error)
 	GetRefreshTokenByUserID(ctx context.Context, userID pgtype.UUID) (*RefreshToken, error)
+	GetRefreshTokensByUserID(ctx context.Context, userID pgtype.UUID) ([]*GetRefreshTokensByUserIDRow, error)
+	GetRequestableRoles(ctx context.Context, arg *GetRequestableRolesParams) ([]*GetRequestableRolesRow, error)
 	GetRoleByID(ctx context.Context, arg *GetRoleByIDParams) (*Role, error)
+	GetRoleConflictSetByID(ctx context.Context, arg *GetRoleConflictSetByIDParams) (*RoleConflictSet, error)
+	GetRoleConflictSets(ctx context.Context, tenantID pgtype.UUID) ([]*GetRoleConflictSetsRow, error)
+	GetRolePermissionIDs(ctx context.Context, arg *GetRolePermissionIDsParams) ([]pgtype.UUID, error)
+	GetRolesPermissionSet(ctx context.Context, arg *GetRolesPermissionSetParams) ([]*GetRolesPermissionSetRow, error)
 	GetSSOTenantByDomain(ctx context.Context, domain []byte) (*GetSSOTenantByDomainRow, error)
+	GetTenantActiveRoleAssignments(ctx context.Context, tenantID pgtype.UUID) ([]*GetTenantActiveRoleAssignmentsRow, error)
 	GetTenantAndUserContext(ctx context.Context, arg *GetTenantAndUserContextParams) (*GetTenantAndUserContextRow, error)
 	GetTenantByID(ctx context.Context, id pgtype.UUID) (*Tenant, error)
 	GetTenantIPWhitelist(ctx context.Context, tenantID pgtype.UUID) ([]*TenantIpWhitelist, error)
 	GetTenantIPWhitelistCIDRs(ctx context.Context, tenantID pgtype.UUID) ([]string, error)
+	GetTenantIPWhitelistCountries(ctx context.Context, tenantID pgtype.UUID) ([]*TenantIpWhitelistCountry, error)
+	GetTenantIPWhitelistScopes(ctx context.Context, tenantID pgtype.UUID) ([]*GetTenantIPWhitelistScopesRow, error)
+	GetTenantRoleInclusions(ctx context.Context, tenantID pgtype.UUID) ([]*GetTenantRoleInclusionsRow, error)
 	GetTenantRolesWithPermissions(ctx context.Context, tenantID pgtype.UUID) ([]*GetTenantRolesWithPermissionsRow, error)
+	GetUnusedIPWhitelistRecoveryCode(ctx context.Context, codeHash string) (*GetUnusedIPWhitelistRecoveryCodeRow, error)
 	GetUserByEmail(ctx context.Context, email string) (*User, error)
 	GetUserByID(ctx context.Context, id pgtype.UUID) (*User, error)
+	GetUserGroupAdmins(ctx context.Context, tenantID pgtype.UUID) ([]*GetUserGroupAdminsRow, error)
+	GetUserGroupByID(ctx context.Context, arg *GetUserGroupByIDParams) (*UserGroup, error)
+	GetUserGroupMembers(ctx context.Context, tenantID pgtype.UUID) ([]*GetUserGroupMembersRow, error)
+	GetUserGroups(ctx context.Context, tenantID pgtype.UUID) ([]*GetUserGroupsRow, error)
+	// The user's roles are counted the way permissions are: directly assigned,
+	// unexpired roles (only default roles while RBAC is off) and every role they
+	// include, transitively.
+	GetUserIPWhitelistScopes(ctx context.Context, arg *GetUserIPWhitelistScopesParams) ([]*GetUserIPWhitelistScopesRow, error)
+	GetUserPermissionSet(ctx context.Context, arg *GetUserPermissionSetParams) ([]*GetUserPermissionSetRow, error)
 	GetUserPermissionsWithFallback(ctx context.Context, arg *GetUserPermissionsWithFallbackParams) ([]*GetUserPermissionsWithFallbackRow, error)
-	GetUserRoleIDs(ctx context.Context, arg *GetUserRoleIDsParams) ([]pgtype.UUID, error)
+	GetUserRoleAssignments(ctx context.Context, arg *GetUserRoleAssignmentsParams) ([]*GetUserRoleAssignmentsRow, error)
+	GetUserRoleGrants(ctx context.Context, arg *GetUserRoleGrantsParams) ([]*GetUserRoleGrantsRow, error)
 	GetUserRolesWithDetails(ctx context.Context, arg *GetUserRolesWithDetailsParams) ([]*GetUserRolesWithDetailsRow, error)
+	GetUsersWithRolesByIDs(ctx context.Context, arg *GetUsersWithRolesByIDsParams) ([]*GetUsersWithRolesByIDsRow, error)
 	GetUsersWithRolesRespectingRBAC(ctx context.Context, arg *GetUsersWithRolesRespectingRBACParams) ([]*GetUsersWithRolesRespectingRBACRow, error)
+	GetViewerRoleGrants(ctx context.Context, tenantID pgtype.UUID) ([]*GetViewerRoleGrantsRow, error)
+	GrantTemporaryRole(ctx context.Context, arg *GrantTemporaryRoleParams) error
+	HasOpenIPWhitelistBreakGlassRequest(ctx context.Context, tenantID pgtype.UUID) (bool, error)
+	HasPendingAccessRequest(ctx context.Context, arg *HasPendingAccessRequestParams) (bool, error)
+	HasPendingIPWhitelistEmergencyToken(ctx context.Context, arg *HasPendingIPWhitelistEmergencyTokenParams) (bool, error)
 	InsertAuditLog(ctx context.Context, arg *InsertAuditLogParams) error
 	InviteUserToTenant(ctx context.Context, arg *InviteUserToTenantParams) (pgtype.UUID, error)
+	IsTenantMember(ctx context.Context, arg *IsTenantMemberParams) (bool, error)
+	IsUserInGroups(ctx context.Context, arg *IsUserInGroupsParams) (bool, error)
+	ListAccessRequests(ctx context.Context, arg *ListAccessRequestsParams) ([]*ListAccessRequestsRow, error)
 	ListAuditLogs(ctx context.Context, arg *ListAuditLogsParams) ([]*ListAuditLogsRow, error)
+	ListAuditLogsForUser(ctx context.Context, arg *ListAuditLogsForUserParams) ([]*ListAuditLogsForUserRow, error)
+	ListTenantMembershipsForUser(ctx context.Context, userID pgtype.UUID) ([]*ListTenantMembershipsForUserRow, error)
+	ListUserIDsByCreatedAtAsc(ctx context.Context, arg *ListUserIDsByCreatedAtAscParams) ([]pgtype.UUID, error)
+	ListUserIDsByCreatedAtDesc(ctx context.Context, arg *ListUserIDsByCreatedAtDescParams) ([]pgtype.UUID, error)
+	ListUserIDsByEmailAsc(ctx context.Context, arg *ListUserIDsByEmailAscParams) ([]pgtype.UUID, error)
+	ListUserIDsByEmailDesc(ctx context.Context, arg *ListUserIDsByEmailDescParams) ([]pgtype.UUID, error)
+	ListUserIDsByLastLoginAtAsc(ctx context.Context, arg *ListUserIDsByLastLoginAtAscParams) ([]pgtype.UUID, error)
+	ListUserIDsByLastLoginAtDesc(ctx context.Context, arg *ListUserIDsByLastLoginAtDescParams) ([]pgtype.UUID, error)
+	ListUserIDsByNameAsc(ctx context.Context, arg *ListUserIDsByNameAscParams) ([]pgtype.UUID, error)
+	ListUserIDsByNameDesc(ctx context.Context, arg *ListUserIDsByNameDescParams) ([]pgtype.UUID, error)
+	LockIPWhitelistBreakGlassRequest(ctx context.Context, id pgtype.UUID) (pgtype.Timestamptz, error)
+	LockTenantForRoleChange(ctx context.Context, id pgtype.UUID) error
 	MarkEmailChangeTokenAsUsed(ctx context.Context, id pgtype.UUID) error
 	MarkIPWhitelistEmergencyTokenAsUsed(ctx context.Context, jti pgtype.UUID) error
 	MarkInvitationTokenAsUsed(ctx context.Context, id pgtype.UUID) error
 	MarkPasswordResetTokenAsUsed(ctx context.Context, id pgtype.UUID) error
+	MarkPermissionsRemoved(ctx context.Context, arg *MarkPermissionsRemovedParams) (int64, error)
 	MarkUserDeletedAndAnonymize(ctx context.Context, id pgtype.UUID) error
+	PurgeAnonymizedUsers(ctx context.Context, arg *PurgeAnonymizedUsersParams) (int64, error)
+	PurgeExpiredEmailChangeTokens(ctx context.Context, arg *PurgeExpiredEmailChangeTokensParams) (int64, error)
+	PurgeExpiredIPWhitelistBreakGlassRequests(ctx context.Context, arg *PurgeExpiredIPWhitelistBreakGlassRequestsParams) (int64, error)
+	PurgeExpiredIPWhitelistRules(ctx context.Context, arg *PurgeExpiredIPWhitelistRulesParams) ([]*PurgeExpiredIPWhitelistRulesRow, error)
+	PurgeExpiredInvitationTokens(ctx context.Context, arg *PurgeExpiredInvitationTokensParams) (int64, error)
+	PurgeExpiredPasswordResetTokens(ctx context.Context, arg *PurgeExpiredPasswordResetTokensParams) (int64, error)
+	PurgeExpiredRefreshTokens(ctx context.Context, arg *PurgeExpiredRefreshTokensParams) (int64, error)
+	PurgeExpiredSSOAuthRequests(ctx context.Context, arg *PurgeExpiredSSOAuthRequestsParams) (int64, error)
+	PurgeExpiredUserRoles(ctx context.Context, arg *PurgeExpiredUserRolesParams) ([]*PurgeExpiredUserRolesRow, error)
+	PurgeIPWhitelistMonitorHits(ctx context.Context, arg *PurgeIPWhitelistMonitorHitsParams) (int64, error)
+	RecordIPWhitelistMonitorHit(ctx context.Context, arg *RecordIPWhitelistMonitorHitParams) error
 	RemoveIPFromWhitelist(ctx context.Context, arg *RemoveIPFromWhitelistParams) error
 	RemoveRolesFromUser(ctx context.Context, arg *RemoveRolesFromUserParams) error
+	RemoveTenantMembership(ctx context.Context, arg *RemoveTenantMembershipParams) error
+	RemoveUserFromTenantGroups(ctx context.Context, arg *RemoveUserFromTenantGroupsParams) error
+	RemoveUserRolesInTenant(ctx context.Context, arg *RemoveUserRolesInTenantParams) error
 	RevokeRefreshToken(ctx context.Context, jti pgtype.UUID) error
+	RevokeRefreshTokensForTenant(ctx context.Context, arg *RevokeRefreshTokensForTenantParams) error
+	SetUserRoleExpiry(ctx context.Context, arg *SetUserRoleExpiryParams) error
+	TenantHasIPWhitelistScopes(ctx context.Context, tenantID pgtype.UUID) (bool, error)
+	TryMaintenanceLock(ctx context.Context, lockKey int64) (bool, error)
 	UpdateIPWhitelistLabel(ctx context.Context, arg *UpdateIPWhitelistLabelParams) error
+	UpdateIPWhitelistScopeExempt(ctx context.Context, arg *UpdateIPWhitelistScopeExemptParams) error
 	UpdateRefreshTokenUsed(ctx context.Context, jti pgtype.UUID) error
 	UpdateRole(ctx context.Context, arg *UpdateRoleParams) error
 	UpdateTenantEnterpriseFeatures(ctx context.Context, arg *UpdateTenantEnterpriseFeaturesParams) error
 	UpdateTenantName(ctx context.Context, arg *UpdateTenantNameParams) error
 	UpdateUserEmail(ctx context.Context, arg *UpdateUserEmailParams) error
 	UpdateUserExternalSSOID(ctx context.Context, arg *UpdateUserExternalSSOIDParams) error
+	UpdateUserGroupName(ctx context.Context, arg *UpdateUserGroupNameParams) error
+	UpdateUserHomeTenant(ctx context.Context, arg *UpdateUserHomeTenantParams) error
+	UpdateUserLastLoginAt(ctx context.Context, id pgtype.UUID) error
 	UpdateUserName(ctx context.Context, arg *UpdateUserNameParams) error
 	UpdateUserPassword(ctx context.Context, arg *UpdateUserPasswordParams) error
 	UpdateUserStatus(ctx context.Context, arg *UpdateUserStatusParams) error
-	UserHasPermission(ctx context.Context, arg *UserHasPermissionParams) (bool, error)
+	UpsertPermission(ctx context.Context, arg *UpsertPermissionParams) (int64, error)
+	UseIPWhitelistRecoveryCode(ctx context.Context, arg *UseIPWhitelistRecoveryCodeParams) (int64, error)
 	ValidateRolesBelongToTenant(ctx context.Context, arg *ValidateRolesBelongToTenantParams) ([]pgtype.UUID, error)
+	ValidateUsersBelongToTenant(ctx context.Context, arg *ValidateUsersBelongToTenantParams) ([]pgtype.UUID, error)
 }
 
 var _ Querier = (*Queries)(nil)
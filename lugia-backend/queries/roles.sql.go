@@ -30,6 +30,25 @@ func (q *Queries) CheckRoleInUse(ctx context.Context, arg *CheckRoleInUseParams)
 	return exists, err
 }
 
+const CheckRoleIncluded = `-- name: CheckRoleIncluded :one
+SELECT EXISTS(
+    SELECT 1 FROM role_inclusions
+    WHERE included_role_id = $1 AND tenant_id = $2
+) as exists
+`
+
+type CheckRoleIncludedParams struct {
+	IncludedRoleID pgtype.UUID `json:"included_role_id"`
+	TenantID       pgtype.UUID `json:"tenant_id"`
+}
+
+func (q *Queries) CheckRoleIncluded(ctx context.Context, arg *CheckRoleIncludedParams) (bool, error) {
+	row := q.db.QueryRow(ctx, CheckRoleIncluded, arg.IncludedRoleID, arg.TenantID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
 const CheckRoleNameExists = `-- name: CheckRoleNameExists :one
 SELECT EXISTS(
     SELECT 1 FROM roles 
@@ -50,6 +69,53 @@ func (q *Queries) CheckRoleNameExists(ctx context.Context, arg *CheckRoleNameExi
 	return exists, err
 }
 
+const CountTenantAdministrators = `-- name: CountTenantAdministrators :one
+WITH RECURSIVE granted_roles AS (
+    SELECT user_roles.user_id, user_roles.role_id
+    FROM user_roles
+    JOIN roles ON roles.id = user_roles.role_id
+    WHERE user_roles.tenant_id = $1
+        -- A time-bound grant will lapse on its own, so it can't be what keeps
+        -- the tenant manageable.
+        AND user_roles.expires_at IS NULL
+        AND ($2::boolean = true OR roles.is_default = true)
+    UNION
+    SELECT granted_roles.user_id, role_inclusions.included_role_id
+    FROM granted_roles
+    JOIN role_inclusions ON role_inclusions.role_id = granted_roles.role_id
+)
+SELECT
+    COUNT(DISTINCT users.id) FILTER (WHERE permissions.resource = 'users')::int AS users_editors,
+    COUNT(DISTINCT users.id) FILTER (WHERE permissions.resource = 'roles')::int AS roles_editors
+FROM users
+JOIN tenant_memberships ON tenant_memberships.user_id = users.id
+JOIN granted_roles ON granted_roles.user_id = users.id
+JOIN role_permissions ON role_permissions.role_id = granted_roles.role_id
+JOIN permissions ON permissions.id = role_permissions.permission_id
+WHERE tenant_memberships.tenant_id = $1
+    AND users.status = 'active'
+    AND users.deleted_at IS NULL
+    AND users.is_internal_user = false
+    AND (permissions.resource, permissions.action) IN (('users', 'assign_roles'), ('roles', 'edit'))
+`
+
+type CountTenantAdministratorsParams struct {
+	TenantID    pgtype.UUID `json:"tenant_id"`
+	RbacEnabled bool        `json:"rbac_enabled"`
+}
+
+type CountTenantAdministratorsRow struct {
+	UsersEditors int32 `json:"users_editors"`
+	RolesEditors int32 `json:"roles_editors"`
+}
+
+func (q *Queries) CountTenantAdministrators(ctx context.Context, arg *CountTenantAdministratorsParams) (*CountTenantAdministratorsRow, error) {
+	row := q.db.QueryRow(ctx, CountTenantAdministrators, arg.TenantID, arg.RbacEnabled)
+	var i CountTenantAdministratorsRow
+	err := row.Scan(&i.UsersEditors, &i.RolesEditors)
+	return &i, err
+}
+
 const CreateRole = `-- name: CreateRole :one
 INSERT INTO roles (tenant_id, name, description, is_default)
 VALUES ($1, $2, $3, $4)
@@ -91,6 +157,22 @@ func (q *Queries) CreateRole(ctx context.Context, arg *CreateRoleParams) (*Creat
 	return &i, err
 }
 
+const CreateRoleInclusionsBulk = `-- name: CreateRoleInclusionsBulk :exec
+INSERT INTO role_inclusions (role_id, included_role_id, tenant_id)
+SELECT $1, UNNEST($2::uuid[]), $3
+`
+
+type CreateRoleInclusionsBulkParams struct {
+	RoleID          pgtype.UUID   `json:"role_id"`
+	IncludedRoleIds []pgtype.UUID `json:"included_role_ids"`
+	TenantID        pgtype.UUID   `json:"tenant_id"`
+}
+
+func (q *Queries) CreateRoleInclusionsBulk(ctx context.Context, arg *CreateRoleInclusionsBulkParams) error {
+	_, err := q.db.Exec(ctx, CreateRoleInclusionsBulk, arg.RoleID, arg.IncludedRoleIds, arg.TenantID)
+	return err
+}
+
 const CreateRolePermissionsBulk = `-- name: CreateRolePermissionsBulk :exec
 INSERT INTO role_permissions (role_id, permission_id, tenant_id)
 SELECT $1, UNNEST($2::uuid[]), $3
@@ -122,6 +204,21 @@ func (q *Queries) DeleteRole(ctx context.Context, arg *DeleteRoleParams) error {
 	return err
 }
 
+const DeleteRoleInclusions = `-- name: DeleteRoleInclusions :exec
+DELETE FROM role_inclusions
+WHERE role_id = $1 AND tenant_id = $2
+`
+
+type DeleteRoleInclusionsParams struct {
+	RoleID   pgtype.UUID `json:"role_id"`
+	TenantID pgtype.UUID `json:"tenant_id"`
+}
+
+func (q *Queries) DeleteRoleInclusions(ctx context.Context, arg *DeleteRoleInclusionsParams) error {
+	_, err := q.db.Exec(ctx, DeleteRoleInclusions, arg.RoleID, arg.TenantID)
+	return err
+}
+
 const DeleteRolePermissions = `-- name: DeleteRolePermissions :exec
 DELETE FROM role_permissions
 WHERE role_id = $1 AND tenant_id = $2
@@ -138,7 +235,7 @@ func (q *Queries) DeleteRolePermissions(ctx context.Context, arg *DeleteRolePerm
 }
 
 const GetAllPermissions = `-- name: GetAllPermissions :many
-SELECT id, resource, action, description FROM permissions
+SELECT id, resource, action, description FROM permissions WHERE removed_at IS NULL
 `
 
 type GetAllPermissionsRow struct {
@@ -193,6 +290,36 @@ func (q *Queries) GetDefaultViewerRole(ctx context.Context, tenantID pgtype.UUID
 	return &i, err
 }
 
+const GetIncludedRoleIDs = `-- name: GetIncludedRoleIDs :many
+SELECT included_role_id FROM role_inclusions
+WHERE role_id = $1 AND tenant_id = $2
+`
+
+type GetIncludedRoleIDsParams struct {
+	RoleID   pgtype.UUID `json:"role_id"`
+	TenantID pgtype.UUID `json:"tenant_id"`
+}
+
+func (q *Queries) GetIncludedRoleIDs(ctx context.Context, arg *GetIncludedRoleIDsParams) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, GetIncludedRoleIDs, arg.RoleID, arg.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []pgtype.UUID{}
+	for rows.Next() {
+		var included_role_id pgtype.UUID
+		if err := rows.Scan(&included_role_id); err != nil {
+			return nil, err
+		}
+		items = append(items, included_role_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const GetRoleByID = `-- name: GetRoleByID :one
 SELECT id, tenant_id, name, description, is_default, created_at, updated_at FROM roles
 WHERE id = $1 AND tenant_id = $2
@@ -218,6 +345,66 @@ func (q *Queries) GetRoleByID(ctx context.Context, arg *GetRoleByIDParams) (*Rol
 	return &i, err
 }
 
+const GetRolePermissionIDs = `-- name: GetRolePermissionIDs :many
+SELECT permission_id FROM role_permissions
+WHERE role_id = $1 AND tenant_id = $2
+`
+
+type GetRolePermissionIDsParams struct {
+	RoleID   pgtype.UUID `json:"role_id"`
+	TenantID pgtype.UUID `json:"tenant_id"`
+}
+
+func (q *Queries) GetRolePermissionIDs(ctx context.Context, arg *GetRolePermissionIDsParams) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, GetRolePermissionIDs, arg.RoleID, arg.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []pgtype.UUID{}
+	for rows.Next() {
+		var permission_id pgtype.UUID
+		if err := rows.Scan(&permission_id); err != nil {
+			return nil, err
+		}
+		items = append(items, permission_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const GetTenantRoleInclusions = `-- name: GetTenantRoleInclusions :many
+SELECT role_id, included_role_id FROM role_inclusions
+WHERE tenant_id = $1
+`
+
+type GetTenantRoleInclusionsRow struct {
+	RoleID         pgtype.UUID `json:"role_id"`
+	IncludedRoleID pgtype.UUID `json:"included_role_id"`
+}
+
+func (q *Queries) GetTenantRoleInclusions(ctx context.Context, tenantID pgtype.UUID) ([]*GetTenantRoleInclusionsRow, error) {
+	rows, err := q.db.Query(ctx, GetTenantRoleInclusions, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*GetTenantRoleInclusionsRow{}
+	for rows.Next() {
+		var i GetTenantRoleInclusionsRow
+		if err := rows.Scan(&i.RoleID, &i.IncludedRoleID); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const GetTenantRolesWithPermissions = `-- name: GetTenantRolesWithPermissions :many
 SELECT 
     roles.id, roles.name, roles.description, roles.is_default,
@@ -227,7 +414,7 @@ SELECT
     permissions.description as permission_description
 FROM roles
 LEFT JOIN role_permissions ON roles.id = role_permissions.role_id
-LEFT JOIN permissions ON role_permissions.permission_id = permissions.id
+LEFT JOIN permissions ON role_permissions.permission_id = permissions.id AND permissions.removed_at IS NULL
 WHERE roles.tenant_id = $1
 ORDER BY roles.name, permissions.description
 `
@@ -272,6 +459,40 @@ func (q *Queries) GetTenantRolesWithPermissions(ctx context.Context, tenantID pg
 	return items, nil
 }
 
+const LockTenantForRoleChange = `-- name: LockTenantForRoleChange :exec
+SELECT id FROM tenants
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) LockTenantForRoleChange(ctx context.Context, id pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, LockTenantForRoleChange, id)
+	return err
+}
+
+const MarkPermissionsRemoved = `-- name: MarkPermissionsRemoved :execrows
+UPDATE permissions
+SET removed_at = CURRENT_TIMESTAMP,
+    updated_at = CURRENT_TIMESTAMP
+WHERE removed_at IS NULL
+  AND (resource, action) NOT IN (
+    SELECT * FROM unnest($1::text[], $2::text[])
+  )
+`
+
+type MarkPermissionsRemovedParams struct {
+	Resources []string `json:"resources"`
+	Actions   []string `json:"actions"`
+}
+
+func (q *Queries) MarkPermissionsRemoved(ctx context.Context, arg *MarkPermissionsRemovedParams) (int64, error) {
+	result, err := q.db.Exec(ctx, MarkPermissionsRemoved, arg.Resources, arg.Actions)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const UpdateRole = `-- name: UpdateRole :exec
 UPDATE roles
 SET name = $1, description = $2
@@ -295,6 +516,39 @@ func (q *Queries) UpdateRole(ctx context.Context, arg *UpdateRoleParams) error {
 	return err
 }
 
+const UpsertPermission = `-- name: UpsertPermission :execrows
+INSERT INTO permissions (resource, action, description, description_en)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (resource, action) DO UPDATE
+SET description = EXCLUDED.description,
+    description_en = EXCLUDED.description_en,
+    removed_at = NULL,
+    updated_at = CURRENT_TIMESTAMP
+WHERE permissions.description IS DISTINCT FROM EXCLUDED.description
+   OR permissions.description_en IS DISTINCT FROM EXCLUDED.description_en
+   OR permissions.removed_at IS NOT NULL
+`
+
+type UpsertPermissionParams struct {
+	Resource      string `json:"resource"`
+	Action        string `json:"action"`
+	Description   string `json:"description"`
+	DescriptionEn string `json:"description_en"`
+}
+
+func (q *Queries) UpsertPermission(ctx context.Context, arg *UpsertPermissionParams) (int64, error) {
+	result, err := q.db.Exec(ctx, UpsertPermission,
+		arg.Resource,
+		arg.Action,
+		arg.Description,
+		arg.DescriptionEn,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const ValidateRolesBelongToTenant = `-- name: ValidateRolesBelongToTenant :many
 SELECT id FROM roles 
 WHERE id = ANY($1::uuid[]) AND tenant_id = $2
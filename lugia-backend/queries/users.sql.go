@@ -28,24 +28,50 @@ func (q *Queries) ActivateInvitedUser(ctx context.Context, arg *ActivateInvitedU
 }
 
 type AddRolesToUserParams struct {
-	UserID   pgtype.UUID `json:"user_id"`
-	RoleID   pgtype.UUID `json:"role_id"`
+	UserID    pgtype.UUID        `json:"user_id"`
+	RoleID    pgtype.UUID        `json:"role_id"`
+	TenantID  pgtype.UUID        `json:"tenant_id"`
+	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
+	GrantedBy pgtype.UUID        `json:"granted_by"`
+}
+
+const AddTenantMembership = `-- name: AddTenantMembership :exec
+INSERT INTO tenant_memberships (tenant_id, user_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING
+`
+
+type AddTenantMembershipParams struct {
 	TenantID pgtype.UUID `json:"tenant_id"`
+	UserID   pgtype.UUID `json:"user_id"`
+}
+
+func (q *Queries) AddTenantMembership(ctx context.Context, arg *AddTenantMembershipParams) error {
+	_, err := q.db.Exec(ctx, AddTenantMembership, arg.TenantID, arg.UserID)
+	return err
 }
 
 const AssignRoleToUser = `-- name: AssignRoleToUser :exec
-INSERT INTO user_roles (user_id, role_id, tenant_id)
-VALUES ($1, $2, $3)
+INSERT INTO user_roles (user_id, role_id, tenant_id, expires_at, granted_by)
+VALUES ($1, $2, $3, $4, $5)
 `
 
 type AssignRoleToUserParams struct {
-	UserID   pgtype.UUID `json:"user_id"`
-	RoleID   pgtype.UUID `json:"role_id"`
-	TenantID pgtype.UUID `json:"tenant_id"`
+	UserID    pgtype.UUID        `json:"user_id"`
+	RoleID    pgtype.UUID        `json:"role_id"`
+	TenantID  pgtype.UUID        `json:"tenant_id"`
+	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
+	GrantedBy pgtype.UUID        `json:"granted_by"`
 }
 
 func (q *Queries) AssignRoleToUser(ctx context.Context, arg *AssignRoleToUserParams) error {
-	_, err := q.db.Exec(ctx, AssignRoleToUser, arg.UserID, arg.RoleID, arg.TenantID)
+	_, err := q.db.Exec(ctx, AssignRoleToUser,
+		arg.UserID,
+		arg.RoleID,
+		arg.TenantID,
+		arg.ExpiresAt,
+		arg.GrantedBy,
+	)
 	return err
 }
 
@@ -74,6 +100,59 @@ func (q *Queries) CountUsersByTenantID(ctx context.Context, arg *CountUsersByTen
 	return count, err
 }
 
+const CountUsersFiltered = `-- name: CountUsersFiltered :one
+SELECT COUNT(*)
+FROM users
+JOIN tenant_memberships ON tenant_memberships.user_id = users.id
+WHERE tenant_memberships.tenant_id = $1
+AND users.is_internal_user = false
+AND users.deleted_at IS NULL
+AND (
+    $2::varchar = '' OR
+    users.name ILIKE '%' || $2 || '%' OR
+    users.email ILIKE '%' || $2 || '%'
+)
+AND ($3::varchar = '' OR users.status = $3)
+AND ($4::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM user_roles
+    WHERE user_roles.user_id = users.id AND user_roles.role_id = $4
+))
+AND ($5::boolean IS NULL OR (users.external_sso_id IS NOT NULL) = $5)
+AND ($6::timestamptz IS NULL OR users.created_at >= $6)
+AND ($7::timestamptz IS NULL OR users.created_at <= $7)
+AND ($8::timestamptz IS NULL OR users.last_login_at >= $8)
+AND ($9::timestamptz IS NULL OR users.last_login_at <= $9)
+`
+
+type CountUsersFilteredParams struct {
+	TenantID      pgtype.UUID        `json:"tenant_id"`
+	SearchTerm    string             `json:"search_term"`
+	Status        string             `json:"status"`
+	RoleID        pgtype.UUID        `json:"role_id"`
+	SsoLinked     pgtype.Bool        `json:"sso_linked"`
+	CreatedFrom   pgtype.Timestamptz `json:"created_from"`
+	CreatedTo     pgtype.Timestamptz `json:"created_to"`
+	LastLoginFrom pgtype.Timestamptz `json:"last_login_from"`
+	LastLoginTo   pgtype.Timestamptz `json:"last_login_to"`
+}
+
+func (q *Queries) CountUsersFiltered(ctx context.Context, arg *CountUsersFilteredParams) (int64, error) {
+	row := q.db.QueryRow(ctx, CountUsersFiltered,
+		arg.TenantID,
+		arg.SearchTerm,
+		arg.Status,
+		arg.RoleID,
+		arg.SsoLinked,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.LastLoginFrom,
+		arg.LastLoginTo,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const CreateEmailChangeToken = `-- name: CreateEmailChangeToken :exec
 INSERT INTO email_change_tokens (
     user_id,
@@ -208,20 +287,98 @@ func (q *Queries) GetInvitationByTokenHash(ctx context.Context, tokenHash string
 	return &i, err
 }
 
+const GetUserPermissionSet = `-- name: GetUserPermissionSet :many
+WITH RECURSIVE granted_roles AS (
+  -- User's assigned roles (filtered by RBAC status), with when the grant lapses
+  SELECT user_roles.role_id, user_roles.expires_at
+  FROM user_roles
+  JOIN roles ON user_roles.role_id = roles.id
+  WHERE user_roles.user_id = $1
+    AND user_roles.tenant_id = $2
+    AND (
+      $3 = true OR  -- RBAC enabled: use all roles
+      roles.is_default = true  -- RBAC disabled: only default roles
+    )
+    AND (user_roles.expires_at IS NULL OR user_roles.expires_at > CURRENT_TIMESTAMP)  -- expired grants no longer count
+  UNION
+  -- Plus every role they include, transitively (UNION stops at cycles)
+  SELECT role_inclusions.included_role_id, granted_roles.expires_at
+  FROM granted_roles
+  JOIN role_inclusions ON role_inclusions.role_id = granted_roles.role_id
+)
+SELECT permissions.resource, permissions.action, granted_roles.expires_at
+FROM granted_roles
+JOIN role_permissions ON granted_roles.role_id = role_permissions.role_id
+JOIN permissions ON role_permissions.permission_id = permissions.id
+UNION ALL
+-- 閲覧者 applies to any check the user's own roles don't satisfy, so its
+-- permissions are always part of the set
+SELECT permissions.resource, permissions.action, NULL::timestamptz AS expires_at
+FROM roles
+JOIN role_permissions ON roles.id = role_permissions.role_id
+JOIN permissions ON role_permissions.permission_id = permissions.id
+WHERE roles.tenant_id = $2
+  AND roles.name = '閲覧者'
+  AND roles.is_default = true
+`
+
+type GetUserPermissionSetParams struct {
+	UserID      pgtype.UUID `json:"user_id"`
+	TenantID    pgtype.UUID `json:"tenant_id"`
+	RbacEnabled interface{} `json:"rbac_enabled"`
+}
+
+type GetUserPermissionSetRow struct {
+	Resource  string             `json:"resource"`
+	Action    string             `json:"action"`
+	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
+}
+
+func (q *Queries) GetUserPermissionSet(ctx context.Context, arg *GetUserPermissionSetParams) ([]*GetUserPermissionSetRow, error) {
+	rows, err := q.db.Query(ctx, GetUserPermissionSet, arg.UserID, arg.TenantID, arg.RbacEnabled)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*GetUserPermissionSetRow{}
+	for rows.Next() {
+		var i GetUserPermissionSetRow
+		if err := rows.Scan(&i.Resource, &i.Action, &i.ExpiresAt); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const GetUserPermissionsWithFallback = `-- name: GetUserPermissionsWithFallback :many
-WITH user_permissions AS (
-  -- Get permissions from user's assigned roles (filtered by RBAC status)
-  SELECT DISTINCT permissions.resource, permissions.action, 1 as priority
+WITH RECURSIVE granted_roles AS (
+  -- User's assigned roles (filtered by RBAC status)
+  SELECT user_roles.role_id
   FROM user_roles
   JOIN roles ON user_roles.role_id = roles.id
-  JOIN role_permissions ON user_roles.role_id = role_permissions.role_id
-  JOIN permissions ON role_permissions.permission_id = permissions.id
   WHERE user_roles.user_id = $1 
     AND user_roles.tenant_id = $2
     AND (
       $3 = true OR  -- RBAC enabled: use all roles
       roles.is_default = true  -- RBAC disabled: only default roles
     )
+    AND (user_roles.expires_at IS NULL OR user_roles.expires_at > CURRENT_TIMESTAMP)  -- expired grants no longer count
+  UNION
+  -- Plus every role they include, transitively (UNION stops at cycles)
+  SELECT role_inclusions.included_role_id
+  FROM granted_roles
+  JOIN role_inclusions ON role_inclusions.role_id = granted_roles.role_id
+),
+user_permissions AS (
+  -- Get permissions from the expanded roles
+  SELECT DISTINCT permissions.resource, permissions.action, 1 as priority
+  FROM granted_roles
+  JOIN role_permissions ON granted_roles.role_id = role_permissions.role_id
+  JOIN permissions ON role_permissions.permission_id = permissions.id
 ),
 fallback_permissions AS (
   -- Fallback: Get 閲覧者 permissions if user has no valid roles
@@ -274,29 +431,103 @@ func (q *Queries) GetUserPermissionsWithFallback(ctx context.Context, arg *GetUs
 	return items, nil
 }
 
-const GetUserRoleIDs = `-- name: GetUserRoleIDs :many
-SELECT role_id FROM user_roles
+const GetUserRoleAssignments = `-- name: GetUserRoleAssignments :many
+SELECT role_id, expires_at FROM user_roles
 WHERE user_id = $1 AND tenant_id = $2
 `
 
-type GetUserRoleIDsParams struct {
+type GetUserRoleAssignmentsParams struct {
 	UserID   pgtype.UUID `json:"user_id"`
 	TenantID pgtype.UUID `json:"tenant_id"`
 }
 
-func (q *Queries) GetUserRoleIDs(ctx context.Context, arg *GetUserRoleIDsParams) ([]pgtype.UUID, error) {
-	rows, err := q.db.Query(ctx, GetUserRoleIDs, arg.UserID, arg.TenantID)
+type GetUserRoleAssignmentsRow struct {
+	RoleID    pgtype.UUID        `json:"role_id"`
+	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
+}
+
+func (q *Queries) GetUserRoleAssignments(ctx context.Context, arg *GetUserRoleAssignmentsParams) ([]*GetUserRoleAssignmentsRow, error) {
+	rows, err := q.db.Query(ctx, GetUserRoleAssignments, arg.UserID, arg.TenantID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []pgtype.UUID{}
+	items := []*GetUserRoleAssignmentsRow{}
+	for rows.Next() {
+		var i GetUserRoleAssignmentsRow
+		if err := rows.Scan(&i.RoleID, &i.ExpiresAt); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const GetUserRoleGrants = `-- name: GetUserRoleGrants :many
+WITH RECURSIVE granted_roles AS (
+    SELECT user_roles.role_id AS assigned_role_id, user_roles.role_id
+    FROM user_roles
+    WHERE user_roles.user_id = $1 AND user_roles.tenant_id = $2
+        AND (user_roles.expires_at IS NULL OR user_roles.expires_at > CURRENT_TIMESTAMP)
+    UNION
+    SELECT granted_roles.assigned_role_id, role_inclusions.included_role_id
+    FROM granted_roles
+    JOIN role_inclusions ON role_inclusions.role_id = granted_roles.role_id
+)
+SELECT
+    roles.id AS role_id,
+    roles.name AS role_name,
+    roles.is_default,
+    -- Set when the permission comes from a role the assigned role includes.
+    included_roles.name AS inherited_from,
+    permissions.resource,
+    permissions.action
+FROM granted_roles
+JOIN roles ON roles.id = granted_roles.assigned_role_id
+LEFT JOIN roles AS included_roles ON included_roles.id = granted_roles.role_id
+    AND granted_roles.role_id <> granted_roles.assigned_role_id
+LEFT JOIN role_permissions ON role_permissions.role_id = granted_roles.role_id
+LEFT JOIN permissions ON permissions.id = role_permissions.permission_id AND permissions.removed_at IS NULL
+ORDER BY roles.name, included_roles.name NULLS FIRST, permissions.resource, permissions.action
+`
+
+type GetUserRoleGrantsParams struct {
+	UserID   pgtype.UUID `json:"user_id"`
+	TenantID pgtype.UUID `json:"tenant_id"`
+}
+
+type GetUserRoleGrantsRow struct {
+	RoleID        pgtype.UUID `json:"role_id"`
+	RoleName      string      `json:"role_name"`
+	IsDefault     bool        `json:"is_default"`
+	InheritedFrom pgtype.Text `json:"inherited_from"`
+	Resource      pgtype.Text `json:"resource"`
+	Action        pgtype.Text `json:"action"`
+}
+
+func (q *Queries) GetUserRoleGrants(ctx context.Context, arg *GetUserRoleGrantsParams) ([]*GetUserRoleGrantsRow, error) {
+	rows, err := q.db.Query(ctx, GetUserRoleGrants, arg.UserID, arg.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*GetUserRoleGrantsRow{}
 	for rows.Next() {
-		var role_id pgtype.UUID
-		if err := rows.Scan(&role_id); err != nil {
+		var i GetUserRoleGrantsRow
+		if err := rows.Scan(
+			&i.RoleID,
+			&i.RoleName,
+			&i.IsDefault,
+			&i.InheritedFrom,
+			&i.Resource,
+			&i.Action,
+		); err != nil {
 			return nil, err
 		}
-		items = append(items, role_id)
+		items = append(items, &i)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, err
@@ -342,11 +573,116 @@ func (q *Queries) GetUserRolesWithDetails(ctx context.Context, arg *GetUserRoles
 	return items, nil
 }
 
+const GetUsersWithRolesByIDs = `-- name: GetUsersWithRolesByIDs :many
+WITH paginated_users AS (
+    SELECT page.id, page.position
+    FROM UNNEST($1::uuid[]) WITH ORDINALITY AS page(id, position)
+),
+user_roles_with_rbac AS (
+    SELECT DISTINCT 
+        users.id as user_id,
+        roles.id as role_id, 
+        roles.name as role_name, 
+        roles.description as role_description,
+        1 as priority
+    FROM users
+    JOIN paginated_users pu ON users.id = pu.id
+    JOIN user_roles ON users.id = user_roles.user_id AND user_roles.tenant_id = $2
+        AND (user_roles.expires_at IS NULL OR user_roles.expires_at > CURRENT_TIMESTAMP)
+    JOIN roles ON user_roles.role_id = roles.id
+    WHERE (
+        $3 = true OR  -- RBAC enabled: use all roles
+        roles.is_default = true  -- RBAC disabled: only default roles
+    )
+),
+fallback_roles AS (
+    SELECT DISTINCT 
+        users.id as user_id,
+        roles.id as role_id, 
+        roles.name as role_name, 
+        roles.description as role_description,
+        2 as priority
+    FROM users
+    JOIN paginated_users pu ON users.id = pu.id
+    JOIN roles ON roles.tenant_id = $2
+    WHERE roles.name = '閲覧者'
+    AND roles.is_default = true
+    AND NOT EXISTS (
+        SELECT 1 FROM user_roles_with_rbac urwr 
+        WHERE urwr.user_id = users.id
+    )
+)
+SELECT 
+    users.id, users.email, users.name, users.status, users.created_at, users.updated_at, users.last_login_at,
+    combined_roles.role_id, combined_roles.role_name, combined_roles.role_description
+FROM users
+JOIN paginated_users pu ON users.id = pu.id
+LEFT JOIN (
+    SELECT user_id, role_id, role_name, role_description, priority 
+    FROM user_roles_with_rbac
+    UNION ALL
+    SELECT user_id, role_id, role_name, role_description, priority 
+    FROM fallback_roles
+) combined_roles ON users.id = combined_roles.user_id
+ORDER BY pu.position, combined_roles.priority, combined_roles.role_name
+`
+
+type GetUsersWithRolesByIDsParams struct {
+	UserIds     []pgtype.UUID `json:"user_ids"`
+	TenantID    pgtype.UUID   `json:"tenant_id"`
+	RbacEnabled interface{}   `json:"rbac_enabled"`
+}
+
+type GetUsersWithRolesByIDsRow struct {
+	ID              pgtype.UUID        `json:"id"`
+	Email           string             `json:"email"`
+	Name            string             `json:"name"`
+	Status          string             `json:"status"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+	LastLoginAt     pgtype.Timestamptz `json:"last_login_at"`
+	RoleID          pgtype.UUID        `json:"role_id"`
+	RoleName        string             `json:"role_name"`
+	RoleDescription pgtype.Text        `json:"role_description"`
+}
+
+func (q *Queries) GetUsersWithRolesByIDs(ctx context.Context, arg *GetUsersWithRolesByIDsParams) ([]*GetUsersWithRolesByIDsRow, error) {
+	rows, err := q.db.Query(ctx, GetUsersWithRolesByIDs, arg.UserIds, arg.TenantID, arg.RbacEnabled)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*GetUsersWithRolesByIDsRow{}
+	for rows.Next() {
+		var i GetUsersWithRolesByIDsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Email,
+			&i.Name,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.LastLoginAt,
+			&i.RoleID,
+			&i.RoleName,
+			&i.RoleDescription,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const GetUsersWithRolesRespectingRBAC = `-- name: GetUsersWithRolesRespectingRBAC :many
 WITH paginated_users AS (
     SELECT users.id
     FROM users
-    WHERE users.tenant_id = $1
+    JOIN tenant_memberships ON tenant_memberships.user_id = users.id
+    WHERE tenant_memberships.tenant_id = $1
     AND users.is_internal_user = false
     AND users.deleted_at IS NULL
     AND (
@@ -366,7 +702,8 @@ user_roles_with_rbac AS (
         1 as priority
     FROM users
     JOIN paginated_users pu ON users.id = pu.id
-    JOIN user_roles ON users.id = user_roles.user_id AND users.tenant_id = user_roles.tenant_id
+    JOIN user_roles ON users.id = user_roles.user_id AND user_roles.tenant_id = $1
+        AND (user_roles.expires_at IS NULL OR user_roles.expires_at > CURRENT_TIMESTAMP)
     JOIN roles ON user_roles.role_id = roles.id
     WHERE (
         $5 = true OR  -- RBAC enabled: use all roles
@@ -461,6 +798,85 @@ func (q *Queries) GetUsersWithRolesRespectingRBAC(ctx context.Context, arg *GetU
 	return items, nil
 }
 
+const GetViewerRoleGrants = `-- name: GetViewerRoleGrants :many
+SELECT
+    roles.id AS role_id,
+    roles.name AS role_name,
+    roles.is_default,
+    permissions.resource,
+    permissions.action
+FROM roles
+LEFT JOIN role_permissions ON role_permissions.role_id = roles.id
+LEFT JOIN permissions ON permissions.id = role_permissions.permission_id AND permissions.removed_at IS NULL
+WHERE roles.tenant_id = $1
+    AND roles.name = '閲覧者'
+    AND roles.is_default = true
+ORDER BY permissions.resource, permissions.action
+`
+
+type GetViewerRoleGrantsRow struct {
+	RoleID    pgtype.UUID `json:"role_id"`
+	RoleName  string      `json:"role_name"`
+	IsDefault bool        `json:"is_default"`
+	Resource  pgtype.Text `json:"resource"`
+	Action    pgtype.Text `json:"action"`
+}
+
+func (q *Queries) GetViewerRoleGrants(ctx context.Context, tenantID pgtype.UUID) ([]*GetViewerRoleGrantsRow, error) {
+	rows, err := q.db.Query(ctx, GetViewerRoleGrants, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*GetViewerRoleGrantsRow{}
+	for rows.Next() {
+		var i GetViewerRoleGrantsRow
+		if err := rows.Scan(
+			&i.RoleID,
+			&i.RoleName,
+			&i.IsDefault,
+			&i.Resource,
+			&i.Action,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const GrantTemporaryRole = `-- name: GrantTemporaryRole :exec
+INSERT INTO user_roles (user_id, role_id, tenant_id, expires_at, granted_by)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (user_id, role_id) DO UPDATE
+SET expires_at = EXCLUDED.expires_at,
+    granted_by = EXCLUDED.granted_by
+-- Never shorten an existing grant or turn a permanent one into a temporary one.
+WHERE user_roles.expires_at IS NOT NULL AND user_roles.expires_at < EXCLUDED.expires_at
+`
+
+type GrantTemporaryRoleParams struct {
+	UserID    pgtype.UUID        `json:"user_id"`
+	RoleID    pgtype.UUID        `json:"role_id"`
+	TenantID  pgtype.UUID        `json:"tenant_id"`
+	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
+	GrantedBy pgtype.UUID        `json:"granted_by"`
+}
+
+func (q *Queries) GrantTemporaryRole(ctx context.Context, arg *GrantTemporaryRoleParams) error {
+	_, err := q.db.Exec(ctx, GrantTemporaryRole,
+		arg.UserID,
+		arg.RoleID,
+		arg.TenantID,
+		arg.ExpiresAt,
+		arg.GrantedBy,
+	)
+	return err
+}
+
 const InviteUserToTenant = `-- name: InviteUserToTenant :one
 INSERT INTO users (tenant_id, email, password_hash, name, status, external_sso_id)
 VALUES ($1, $2, $3, $4, $5, $6)
@@ -490,30 +906,706 @@ func (q *Queries) InviteUserToTenant(ctx context.Context, arg *InviteUserToTenan
 	return id, err
 }
 
-const MarkEmailChangeTokenAsUsed = `-- name: MarkEmailChangeTokenAsUsed :exec
-UPDATE email_change_tokens
-SET used_at = CURRENT_TIMESTAMP
-WHERE id = $1
+const IsTenantMember = `-- name: IsTenantMember :one
+SELECT EXISTS (
+    SELECT 1 FROM tenant_memberships
+    WHERE tenant_id = $1 AND user_id = $2
+)
 `
 
-func (q *Queries) MarkEmailChangeTokenAsUsed(ctx context.Context, id pgtype.UUID) error {
-	_, err := q.db.Exec(ctx, MarkEmailChangeTokenAsUsed, id)
-	return err
+type IsTenantMemberParams struct {
+	TenantID pgtype.UUID `json:"tenant_id"`
+	UserID   pgtype.UUID `json:"user_id"`
 }
 
-const MarkInvitationTokenAsUsed = `-- name: MarkInvitationTokenAsUsed :exec
-UPDATE invitation_tokens
-SET used_at = CURRENT_TIMESTAMP
-WHERE id = $1
+func (q *Queries) IsTenantMember(ctx context.Context, arg *IsTenantMemberParams) (bool, error) {
+	row := q.db.QueryRow(ctx, IsTenantMember, arg.TenantID, arg.UserID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const ListTenantMembershipsForUser = `-- name: ListTenantMembershipsForUser :many
+SELECT tenants.id, tenants.name, tenants.auth_method, (tenants.id = users.tenant_id)::boolean AS is_home
+FROM tenant_memberships
+JOIN tenants ON tenants.id = tenant_memberships.tenant_id
+JOIN users ON users.id = tenant_memberships.user_id
+WHERE tenant_memberships.user_id = $1
+ORDER BY is_home DESC, tenant_memberships.created_at, tenants.id
 `
 
-func (q *Queries) MarkInvitationTokenAsUsed(ctx context.Context, id pgtype.UUID) error {
-	_, err := q.db.Exec(ctx, MarkInvitationTokenAsUsed, id)
-	return err
+type ListTenantMembershipsForUserRow struct {
+	ID         pgtype.UUID `json:"id"`
+	Name       string      `json:"name"`
+	AuthMethod string      `json:"auth_method"`
+	IsHome     bool        `json:"is_home"`
 }
 
-const MarkUserDeletedAndAnonymize = `-- name: MarkUserDeletedAndAnonymize :exec
-UPDATE users 
+func (q *Queries) ListTenantMembershipsForUser(ctx context.Context, userID pgtype.UUID) ([]*ListTenantMembershipsForUserRow, error) {
+	rows, err := q.db.Query(ctx, ListTenantMembershipsForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*ListTenantMembershipsForUserRow{}
+	for rows.Next() {
+		var i ListTenantMembershipsForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.AuthMethod,
+			&i.IsHome,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListUserIDsByCreatedAtAsc = `-- name: ListUserIDsByCreatedAtAsc :many
+SELECT users.id
+FROM users
+JOIN tenant_memberships ON tenant_memberships.user_id = users.id
+WHERE tenant_memberships.tenant_id = $1
+AND users.is_internal_user = false
+AND users.deleted_at IS NULL
+AND (
+    $2::varchar = '' OR
+    users.name ILIKE '%' || $2 || '%' OR
+    users.email ILIKE '%' || $2 || '%'
+)
+AND ($3::varchar = '' OR users.status = $3)
+AND ($4::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM user_roles
+    WHERE user_roles.user_id = users.id AND user_roles.role_id = $4
+))
+AND ($5::boolean IS NULL OR (users.external_sso_id IS NOT NULL) = $5)
+AND ($6::timestamptz IS NULL OR users.created_at >= $6)
+AND ($7::timestamptz IS NULL OR users.created_at <= $7)
+AND ($8::timestamptz IS NULL OR users.last_login_at >= $8)
+AND ($9::timestamptz IS NULL OR users.last_login_at <= $9)
+AND ($10::uuid IS NULL OR (users.created_at, users.id) > ($11::timestamptz, $10))
+ORDER BY users.created_at ASC, users.id ASC
+LIMIT $13 OFFSET $12
+`
+
+type ListUserIDsByCreatedAtAscParams struct {
+	TenantID      pgtype.UUID        `json:"tenant_id"`
+	SearchTerm    string             `json:"search_term"`
+	Status        string             `json:"status"`
+	RoleID        pgtype.UUID        `json:"role_id"`
+	SsoLinked     pgtype.Bool        `json:"sso_linked"`
+	CreatedFrom   pgtype.Timestamptz `json:"created_from"`
+	CreatedTo     pgtype.Timestamptz `json:"created_to"`
+	LastLoginFrom pgtype.Timestamptz `json:"last_login_from"`
+	LastLoginTo   pgtype.Timestamptz `json:"last_login_to"`
+	CursorID      pgtype.UUID        `json:"cursor_id"`
+	CursorTime    pgtype.Timestamptz `json:"cursor_time"`
+	OffsetCount   int32              `json:"offset_count"`
+	LimitCount    int32              `json:"limit_count"`
+}
+
+func (q *Queries) ListUserIDsByCreatedAtAsc(ctx context.Context, arg *ListUserIDsByCreatedAtAscParams) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, ListUserIDsByCreatedAtAsc,
+		arg.TenantID,
+		arg.SearchTerm,
+		arg.Status,
+		arg.RoleID,
+		arg.SsoLinked,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.LastLoginFrom,
+		arg.LastLoginTo,
+		arg.CursorID,
+		arg.CursorTime,
+		arg.OffsetCount,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []pgtype.UUID{}
+	for rows.Next() {
+		var id pgtype.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListUserIDsByCreatedAtDesc = `-- name: ListUserIDsByCreatedAtDesc :many
+SELECT users.id
+FROM users
+JOIN tenant_memberships ON tenant_memberships.user_id = users.id
+WHERE tenant_memberships.tenant_id = $1
+AND users.is_internal_user = false
+AND users.deleted_at IS NULL
+AND (
+    $2::varchar = '' OR
+    users.name ILIKE '%' || $2 || '%' OR
+    users.email ILIKE '%' || $2 || '%'
+)
+AND ($3::varchar = '' OR users.status = $3)
+AND ($4::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM user_roles
+    WHERE user_roles.user_id = users.id AND user_roles.role_id = $4
+))
+AND ($5::boolean IS NULL OR (users.external_sso_id IS NOT NULL) = $5)
+AND ($6::timestamptz IS NULL OR users.created_at >= $6)
+AND ($7::timestamptz IS NULL OR users.created_at <= $7)
+AND ($8::timestamptz IS NULL OR users.last_login_at >= $8)
+AND ($9::timestamptz IS NULL OR users.last_login_at <= $9)
+AND ($10::uuid IS NULL OR (users.created_at, users.id) < ($11::timestamptz, $10))
+ORDER BY users.created_at DESC, users.id DESC
+LIMIT $13 OFFSET $12
+`
+
+type ListUserIDsByCreatedAtDescParams struct {
+	TenantID      pgtype.UUID        `json:"tenant_id"`
+	SearchTerm    string             `json:"search_term"`
+	Status        string             `json:"status"`
+	RoleID        pgtype.UUID        `json:"role_id"`
+	SsoLinked     pgtype.Bool        `json:"sso_linked"`
+	CreatedFrom   pgtype.Timestamptz `json:"created_from"`
+	CreatedTo     pgtype.Timestamptz `json:"created_to"`
+	LastLoginFrom pgtype.Timestamptz `json:"last_login_from"`
+	LastLoginTo   pgtype.Timestamptz `json:"last_login_to"`
+	CursorID      pgtype.UUID        `json:"cursor_id"`
+	CursorTime    pgtype.Timestamptz `json:"cursor_time"`
+	OffsetCount   int32              `json:"offset_count"`
+	LimitCount    int32              `json:"limit_count"`
+}
+
+func (q *Queries) ListUserIDsByCreatedAtDesc(ctx context.Context, arg *ListUserIDsByCreatedAtDescParams) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, ListUserIDsByCreatedAtDesc,
+		arg.TenantID,
+		arg.SearchTerm,
+		arg.Status,
+		arg.RoleID,
+		arg.SsoLinked,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.LastLoginFrom,
+		arg.LastLoginTo,
+		arg.CursorID,
+		arg.CursorTime,
+		arg.OffsetCount,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []pgtype.UUID{}
+	for rows.Next() {
+		var id pgtype.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListUserIDsByEmailAsc = `-- name: ListUserIDsByEmailAsc :many
+SELECT users.id
+FROM users
+JOIN tenant_memberships ON tenant_memberships.user_id = users.id
+WHERE tenant_memberships.tenant_id = $1
+AND users.is_internal_user = false
+AND users.deleted_at IS NULL
+AND (
+    $2::varchar = '' OR
+    users.name ILIKE '%' || $2 || '%' OR
+    users.email ILIKE '%' || $2 || '%'
+)
+AND ($3::varchar = '' OR users.status = $3)
+AND ($4::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM user_roles
+    WHERE user_roles.user_id = users.id AND user_roles.role_id = $4
+))
+AND ($5::boolean IS NULL OR (users.external_sso_id IS NOT NULL) = $5)
+AND ($6::timestamptz IS NULL OR users.created_at >= $6)
+AND ($7::timestamptz IS NULL OR users.created_at <= $7)
+AND ($8::timestamptz IS NULL OR users.last_login_at >= $8)
+AND ($9::timestamptz IS NULL OR users.last_login_at <= $9)
+AND ($10::uuid IS NULL OR (users.email, users.id) > ($11::varchar, $10))
+ORDER BY users.email ASC, users.id ASC
+LIMIT $13 OFFSET $12
+`
+
+type ListUserIDsByEmailAscParams struct {
+	TenantID      pgtype.UUID        `json:"tenant_id"`
+	SearchTerm    string             `json:"search_term"`
+	Status        string             `json:"status"`
+	RoleID        pgtype.UUID        `json:"role_id"`
+	SsoLinked     pgtype.Bool        `json:"sso_linked"`
+	CreatedFrom   pgtype.Timestamptz `json:"created_from"`
+	CreatedTo     pgtype.Timestamptz `json:"created_to"`
+	LastLoginFrom pgtype.Timestamptz `json:"last_login_from"`
+	LastLoginTo   pgtype.Timestamptz `json:"last_login_to"`
+	CursorID      pgtype.UUID        `json:"cursor_id"`
+	CursorText    string             `json:"cursor_text"`
+	OffsetCount   int32              `json:"offset_count"`
+	LimitCount    int32              `json:"limit_count"`
+}
+
+func (q *Queries) ListUserIDsByEmailAsc(ctx context.Context, arg *ListUserIDsByEmailAscParams) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, ListUserIDsByEmailAsc,
+		arg.TenantID,
+		arg.SearchTerm,
+		arg.Status,
+		arg.RoleID,
+		arg.SsoLinked,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.LastLoginFrom,
+		arg.LastLoginTo,
+		arg.CursorID,
+		arg.CursorText,
+		arg.OffsetCount,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []pgtype.UUID{}
+	for rows.Next() {
+		var id pgtype.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListUserIDsByEmailDesc = `-- name: ListUserIDsByEmailDesc :many
+SELECT users.id
+FROM users
+JOIN tenant_memberships ON tenant_memberships.user_id = users.id
+WHERE tenant_memberships.tenant_id = $1
+AND users.is_internal_user = false
+AND users.deleted_at IS NULL
+AND (
+    $2::varchar = '' OR
+    users.name ILIKE '%' || $2 || '%' OR
+    users.email ILIKE '%' || $2 || '%'
+)
+AND ($3::varchar = '' OR users.status = $3)
+AND ($4::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM user_roles
+    WHERE user_roles.user_id = users.id AND user_roles.role_id = $4
+))
+AND ($5::boolean IS NULL OR (users.external_sso_id IS NOT NULL) = $5)
+AND ($6::timestamptz IS NULL OR users.created_at >= $6)
+AND ($7::timestamptz IS NULL OR users.created_at <= $7)
+AND ($8::timestamptz IS NULL OR users.last_login_at >= $8)
+AND ($9::timestamptz IS NULL OR users.last_login_at <= $9)
+AND ($10::uuid IS NULL OR (users.email, users.id) < ($11::varchar, $10))
+ORDER BY users.email DESC, users.id DESC
+LIMIT $13 OFFSET $12
+`
+
+type ListUserIDsByEmailDescParams struct {
+	TenantID      pgtype.UUID        `json:"tenant_id"`
+	SearchTerm    string             `json:"search_term"`
+	Status        string             `json:"status"`
+	RoleID        pgtype.UUID        `json:"role_id"`
+	SsoLinked     pgtype.Bool        `json:"sso_linked"`
+	CreatedFrom   pgtype.Timestamptz `json:"created_from"`
+	CreatedTo     pgtype.Timestamptz `json:"created_to"`
+	LastLoginFrom pgtype.Timestamptz `json:"last_login_from"`
+	LastLoginTo   pgtype.Timestamptz `json:"last_login_to"`
+	CursorID      pgtype.UUID        `json:"cursor_id"`
+	CursorText    string             `json:"cursor_text"`
+	OffsetCount   int32              `json:"offset_count"`
+	LimitCount    int32              `json:"limit_count"`
+}
+
+func (q *Queries) ListUserIDsByEmailDesc(ctx context.Context, arg *ListUserIDsByEmailDescParams) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, ListUserIDsByEmailDesc,
+		arg.TenantID,
+		arg.SearchTerm,
+		arg.Status,
+		arg.RoleID,
+		arg.SsoLinked,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.LastLoginFrom,
+		arg.LastLoginTo,
+		arg.CursorID,
+		arg.CursorText,
+		arg.OffsetCount,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []pgtype.UUID{}
+	for rows.Next() {
+		var id pgtype.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListUserIDsByLastLoginAtAsc = `-- name: ListUserIDsByLastLoginAtAsc :many
+SELECT users.id
+FROM users
+JOIN tenant_memberships ON tenant_memberships.user_id = users.id
+WHERE tenant_memberships.tenant_id = $1
+AND users.is_internal_user = false
+AND users.deleted_at IS NULL
+AND (
+    $2::varchar = '' OR
+    users.name ILIKE '%' || $2 || '%' OR
+    users.email ILIKE '%' || $2 || '%'
+)
+AND ($3::varchar = '' OR users.status = $3)
+AND ($4::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM user_roles
+    WHERE user_roles.user_id = users.id AND user_roles.role_id = $4
+))
+AND ($5::boolean IS NULL OR (users.external_sso_id IS NOT NULL) = $5)
+AND ($6::timestamptz IS NULL OR users.created_at >= $6)
+AND ($7::timestamptz IS NULL OR users.created_at <= $7)
+AND ($8::timestamptz IS NULL OR users.last_login_at >= $8)
+AND ($9::timestamptz IS NULL OR users.last_login_at <= $9)
+AND ($10::uuid IS NULL OR (COALESCE(users.last_login_at, '-infinity'::timestamptz), users.id) > ($11::timestamptz, $10))
+ORDER BY COALESCE(users.last_login_at, '-infinity'::timestamptz) ASC, users.id ASC
+LIMIT $13 OFFSET $12
+`
+
+type ListUserIDsByLastLoginAtAscParams struct {
+	TenantID      pgtype.UUID        `json:"tenant_id"`
+	SearchTerm    string             `json:"search_term"`
+	Status        string             `json:"status"`
+	RoleID        pgtype.UUID        `json:"role_id"`
+	SsoLinked     pgtype.Bool        `json:"sso_linked"`
+	CreatedFrom   pgtype.Timestamptz `json:"created_from"`
+	CreatedTo     pgtype.Timestamptz `json:"created_to"`
+	LastLoginFrom pgtype.Timestamptz `json:"last_login_from"`
+	LastLoginTo   pgtype.Timestamptz `json:"last_login_to"`
+	CursorID      pgtype.UUID        `json:"cursor_id"`
+	CursorTime    pgtype.Timestamptz `json:"cursor_time"`
+	OffsetCount   int32              `json:"offset_count"`
+	LimitCount    int32              `json:"limit_count"`
+}
+
+func (q *Queries) ListUserIDsByLastLoginAtAsc(ctx context.Context, arg *ListUserIDsByLastLoginAtAscParams) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, ListUserIDsByLastLoginAtAsc,
+		arg.TenantID,
+		arg.SearchTerm,
+		arg.Status,
+		arg.RoleID,
+		arg.SsoLinked,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.LastLoginFrom,
+		arg.LastLoginTo,
+		arg.CursorID,
+		arg.CursorTime,
+		arg.OffsetCount,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []pgtype.UUID{}
+	for rows.Next() {
+		var id pgtype.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListUserIDsByLastLoginAtDesc = `-- name: ListUserIDsByLastLoginAtDesc :many
+SELECT users.id
+FROM users
+JOIN tenant_memberships ON tenant_memberships.user_id = users.id
+WHERE tenant_memberships.tenant_id = $1
+AND users.is_internal_user = false
+AND users.deleted_at IS NULL
+AND (
+    $2::varchar = '' OR
+    users.name ILIKE '%' || $2 || '%' OR
+    users.email ILIKE '%' || $2 || '%'
+)
+AND ($3::varchar = '' OR users.status = $3)
+AND ($4::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM user_roles
+    WHERE user_roles.user_id = users.id AND user_roles.role_id = $4
+))
+AND ($5::boolean IS NULL OR (users.external_sso_id IS NOT NULL) = $5)
+AND ($6::timestamptz IS NULL OR users.created_at >= $6)
+AND ($7::timestamptz IS NULL OR users.created_at <= $7)
+AND ($8::timestamptz IS NULL OR users.last_login_at >= $8)
+AND ($9::timestamptz IS NULL OR users.last_login_at <= $9)
+AND ($10::uuid IS NULL OR (COALESCE(users.last_login_at, '-infinity'::timestamptz), users.id) < ($11::timestamptz, $10))
+ORDER BY COALESCE(users.last_login_at, '-infinity'::timestamptz) DESC, users.id DESC
+LIMIT $13 OFFSET $12
+`
+
+type ListUserIDsByLastLoginAtDescParams struct {
+	TenantID      pgtype.UUID        `json:"tenant_id"`
+	SearchTerm    string             `json:"search_term"`
+	Status        string             `json:"status"`
+	RoleID        pgtype.UUID        `json:"role_id"`
+	SsoLinked     pgtype.Bool        `json:"sso_linked"`
+	CreatedFrom   pgtype.Timestamptz `json:"created_from"`
+	CreatedTo     pgtype.Timestamptz `json:"created_to"`
+	LastLoginFrom pgtype.Timestamptz `json:"last_login_from"`
+	LastLoginTo   pgtype.Timestamptz `json:"last_login_to"`
+	CursorID      pgtype.UUID        `json:"cursor_id"`
+	CursorTime    pgtype.Timestamptz `json:"cursor_time"`
+	OffsetCount   int32              `json:"offset_count"`
+	LimitCount    int32              `json:"limit_count"`
+}
+
+func (q *Queries) ListUserIDsByLastLoginAtDesc(ctx context.Context, arg *ListUserIDsByLastLoginAtDescParams) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, ListUserIDsByLastLoginAtDesc,
+		arg.TenantID,
+		arg.SearchTerm,
+		arg.Status,
+		arg.RoleID,
+		arg.SsoLinked,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.LastLoginFrom,
+		arg.LastLoginTo,
+		arg.CursorID,
+		arg.CursorTime,
+		arg.OffsetCount,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []pgtype.UUID{}
+	for rows.Next() {
+		var id pgtype.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListUserIDsByNameAsc = `-- name: ListUserIDsByNameAsc :many
+SELECT users.id
+FROM users
+JOIN tenant_memberships ON tenant_memberships.user_id = users.id
+WHERE tenant_memberships.tenant_id = $1
+AND users.is_internal_user = false
+AND users.deleted_at IS NULL
+AND (
+    $2::varchar = '' OR
+    users.name ILIKE '%' || $2 || '%' OR
+    users.email ILIKE '%' || $2 || '%'
+)
+AND ($3::varchar = '' OR users.status = $3)
+AND ($4::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM user_roles
+    WHERE user_roles.user_id = users.id AND user_roles.role_id = $4
+))
+AND ($5::boolean IS NULL OR (users.external_sso_id IS NOT NULL) = $5)
+AND ($6::timestamptz IS NULL OR users.created_at >= $6)
+AND ($7::timestamptz IS NULL OR users.created_at <= $7)
+AND ($8::timestamptz IS NULL OR users.last_login_at >= $8)
+AND ($9::timestamptz IS NULL OR users.last_login_at <= $9)
+AND ($10::uuid IS NULL OR (users.name, users.id) > ($11::varchar, $10))
+ORDER BY users.name ASC, users.id ASC
+LIMIT $13 OFFSET $12
+`
+
+type ListUserIDsByNameAscParams struct {
+	TenantID      pgtype.UUID        `json:"tenant_id"`
+	SearchTerm    string             `json:"search_term"`
+	Status        string             `json:"status"`
+	RoleID        pgtype.UUID        `json:"role_id"`
+	SsoLinked     pgtype.Bool        `json:"sso_linked"`
+	CreatedFrom   pgtype.Timestamptz `json:"created_from"`
+	CreatedTo     pgtype.Timestamptz `json:"created_to"`
+	LastLoginFrom pgtype.Timestamptz `json:"last_login_from"`
+	LastLoginTo   pgtype.Timestamptz `json:"last_login_to"`
+	CursorID      pgtype.UUID        `json:"cursor_id"`
+	CursorText    string             `json:"cursor_text"`
+	OffsetCount   int32              `json:"offset_count"`
+	LimitCount    int32              `json:"limit_count"`
+}
+
+func (q *Queries) ListUserIDsByNameAsc(ctx context.Context, arg *ListUserIDsByNameAscParams) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, ListUserIDsByNameAsc,
+		arg.TenantID,
+		arg.SearchTerm,
+		arg.Status,
+		arg.RoleID,
+		arg.SsoLinked,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.LastLoginFrom,
+		arg.LastLoginTo,
+		arg.CursorID,
+		arg.CursorText,
+		arg.OffsetCount,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []pgtype.UUID{}
+	for rows.Next() {
+		var id pgtype.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListUserIDsByNameDesc = `-- name: ListUserIDsByNameDesc :many
+SELECT users.id
+FROM users
+JOIN tenant_memberships ON tenant_memberships.user_id = users.id
+WHERE tenant_memberships.tenant_id = $1
+AND users.is_internal_user = false
+AND users.deleted_at IS NULL
+AND (
+    $2::varchar = '' OR
+    users.name ILIKE '%' || $2 || '%' OR
+    users.email ILIKE '%' || $2 || '%'
+)
+AND ($3::varchar = '' OR users.status = $3)
+AND ($4::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM user_roles
+    WHERE user_roles.user_id = users.id AND user_roles.role_id = $4
+))
+AND ($5::boolean IS NULL OR (users.external_sso_id IS NOT NULL) = $5)
+AND ($6::timestamptz IS NULL OR users.created_at >= $6)
+AND ($7::timestamptz IS NULL OR users.created_at <= $7)
+AND ($8::timestamptz IS NULL OR users.last_login_at >= $8)
+AND ($9::timestamptz IS NULL OR users.last_login_at <= $9)
+AND ($10::uuid IS NULL OR (users.name, users.id) < ($11::varchar, $10))
+ORDER BY users.name DESC, users.id DESC
+LIMIT $13 OFFSET $12
+`
+
+type ListUserIDsByNameDescParams struct {
+	TenantID      pgtype.UUID        `json:"tenant_id"`
+	SearchTerm    string             `json:"search_term"`
+	Status        string             `json:"status"`
+	RoleID        pgtype.UUID        `json:"role_id"`
+	SsoLinked     pgtype.Bool        `json:"sso_linked"`
+	CreatedFrom   pgtype.Timestamptz `json:"created_from"`
+	CreatedTo     pgtype.Timestamptz `json:"created_to"`
+	LastLoginFrom pgtype.Timestamptz `json:"last_login_from"`
+	LastLoginTo   pgtype.Timestamptz `json:"last_login_to"`
+	CursorID      pgtype.UUID        `json:"cursor_id"`
+	CursorText    string             `json:"cursor_text"`
+	OffsetCount   int32              `json:"offset_count"`
+	LimitCount    int32              `json:"limit_count"`
+}
+
+func (q *Queries) ListUserIDsByNameDesc(ctx context.Context, arg *ListUserIDsByNameDescParams) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, ListUserIDsByNameDesc,
+		arg.TenantID,
+		arg.SearchTerm,
+		arg.Status,
+		arg.RoleID,
+		arg.SsoLinked,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.LastLoginFrom,
+		arg.LastLoginTo,
+		arg.CursorID,
+		arg.CursorText,
+		arg.OffsetCount,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []pgtype.UUID{}
+	for rows.Next() {
+		var id pgtype.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const MarkEmailChangeTokenAsUsed = `-- name: MarkEmailChangeTokenAsUsed :exec
+UPDATE email_change_tokens
+SET used_at = CURRENT_TIMESTAMP
+WHERE id = $1
+`
+
+func (q *Queries) MarkEmailChangeTokenAsUsed(ctx context.Context, id pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, MarkEmailChangeTokenAsUsed, id)
+	return err
+}
+
+const MarkInvitationTokenAsUsed = `-- name: MarkInvitationTokenAsUsed :exec
+UPDATE invitation_tokens
+SET used_at = CURRENT_TIMESTAMP
+WHERE id = $1
+`
+
+func (q *Queries) MarkInvitationTokenAsUsed(ctx context.Context, id pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, MarkInvitationTokenAsUsed, id)
+	return err
+}
+
+const MarkUserDeletedAndAnonymize = `-- name: MarkUserDeletedAndAnonymize :exec
+UPDATE users 
 SET 
     email = id::text || '@deleted.invalid',
     name = '削除済みユーザー',
@@ -544,6 +1636,80 @@ func (q *Queries) RemoveRolesFromUser(ctx context.Context, arg *RemoveRolesFromU
 	return err
 }
 
+const RemoveTenantMembership = `-- name: RemoveTenantMembership :exec
+DELETE FROM tenant_memberships
+WHERE tenant_id = $1 AND user_id = $2
+`
+
+type RemoveTenantMembershipParams struct {
+	TenantID pgtype.UUID `json:"tenant_id"`
+	UserID   pgtype.UUID `json:"user_id"`
+}
+
+func (q *Queries) RemoveTenantMembership(ctx context.Context, arg *RemoveTenantMembershipParams) error {
+	_, err := q.db.Exec(ctx, RemoveTenantMembership, arg.TenantID, arg.UserID)
+	return err
+}
+
+const RemoveUserRolesInTenant = `-- name: RemoveUserRolesInTenant :exec
+DELETE FROM user_roles
+WHERE user_id = $1 AND tenant_id = $2
+`
+
+type RemoveUserRolesInTenantParams struct {
+	UserID   pgtype.UUID `json:"user_id"`
+	TenantID pgtype.UUID `json:"tenant_id"`
+}
+
+func (q *Queries) RemoveUserRolesInTenant(ctx context.Context, arg *RemoveUserRolesInTenantParams) error {
+	_, err := q.db.Exec(ctx, RemoveUserRolesInTenant, arg.UserID, arg.TenantID)
+	return err
+}
+
+const RevokeRefreshTokensForTenant = `-- name: RevokeRefreshTokensForTenant :exec
+UPDATE refresh_tokens
+SET revoked_at = CURRENT_TIMESTAMP
+WHERE user_id = $1
+AND revoked_at IS NULL
+AND (tenant_id = $2 OR (tenant_id IS NULL AND $3::boolean))
+`
+
+type RevokeRefreshTokensForTenantParams struct {
+	UserID       pgtype.UUID `json:"user_id"`
+	TenantID     pgtype.UUID `json:"tenant_id"`
+	IsHomeTenant bool        `json:"is_home_tenant"`
+}
+
+func (q *Queries) RevokeRefreshTokensForTenant(ctx context.Context, arg *RevokeRefreshTokensForTenantParams) error {
+	_, err := q.db.Exec(ctx, RevokeRefreshTokensForTenant, arg.UserID, arg.TenantID, arg.IsHomeTenant)
+	return err
+}
+
+const SetUserRoleExpiry = `-- name: SetUserRoleExpiry :exec
+UPDATE user_roles
+SET expires_at = $1, granted_by = $2
+WHERE user_id = $3 AND tenant_id = $4 AND role_id = $5
+`
+
+type SetUserRoleExpiryParams struct {
+	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
+	GrantedBy pgtype.UUID        `json:"granted_by"`
+	UserID    pgtype.UUID        `json:"user_id"`
+	TenantID  pgtype.UUID        `json:"tenant_id"`
+	RoleID    pgtype.UUID        `json:"role_id"`
+}
+
+func (q *Queries) SetUserRoleExpiry(ctx context.Context, arg *SetUserRoleExpiryParams) error {
+	_, err := q.db.Exec(ctx, SetUserRoleExpiry,
+		arg.ExpiresAt,
+		arg.GrantedBy,
+		arg.UserID,
+		arg.TenantID,
+		arg.RoleID,
+	)
+	return err
+}
+
 const UpdateUserEmail = `-- name: UpdateUserEmail :exec
 UPDATE users
 SET email = $1, updated_at = CURRENT_TIMESTAMP
@@ -576,6 +1742,22 @@ func (q *Queries) UpdateUserExternalSSOID(ctx context.Context, arg *UpdateUserEx
 	return err
 }
 
+const UpdateUserHomeTenant = `-- name: UpdateUserHomeTenant :exec
+UPDATE users
+SET tenant_id = $1, updated_at = CURRENT_TIMESTAMP
+WHERE id = $2
+`
+
+type UpdateUserHomeTenantParams struct {
+	TenantID pgtype.UUID `json:"tenant_id"`
+	UserID   pgtype.UUID `json:"user_id"`
+}
+
+func (q *Queries) UpdateUserHomeTenant(ctx context.Context, arg *UpdateUserHomeTenantParams) error {
+	_, err := q.db.Exec(ctx, UpdateUserHomeTenant, arg.TenantID, arg.UserID)
+	return err
+}
+
 const UpdateUserName = `-- name: UpdateUserName :exec
 UPDATE users
 SET name = $1, updated_at = CURRENT_TIMESTAMP
@@ -623,69 +1805,3 @@ func (q *Queries) UpdateUserStatus(ctx context.Context, arg *UpdateUserStatusPar
 	_, err := q.db.Exec(ctx, UpdateUserStatus, arg.Status, arg.ID)
 	return err
 }
-
-const UserHasPermission = `-- name: UserHasPermission :one
-WITH user_permissions AS (
-  -- Get permissions from user's assigned roles (filtered by RBAC status)
-  SELECT 1 as found
-  FROM user_roles
-  JOIN roles ON user_roles.role_id = roles.id
-  JOIN role_permissions ON user_roles.role_id = role_permissions.role_id
-  JOIN permissions ON role_permissions.permission_id = permissions.id
-  WHERE user_roles.user_id = $1 
-    AND user_roles.tenant_id = $2
-    AND permissions.resource = $3
-    AND (
-        permissions.action = $4 OR 
-        ($4 = 'view' AND permissions.action = 'edit')
-    )
-    AND (
-      $5 = true OR  -- RBAC enabled: use all roles
-      roles.is_default = true  -- RBAC disabled: only default roles
-    )
-  LIMIT 1
-),
-fallback_permissions AS (
-  -- Fallback: Check 閲覧者 permissions if user has no valid roles
-  SELECT 1 as found
-  FROM roles
-  JOIN role_permissions ON roles.id = role_permissions.role_id
-  JOIN permissions ON role_permissions.permission_id = permissions.id
-  WHERE roles.tenant_id = $2
-    AND roles.name = '閲覧者'
-    AND roles.is_default = true
-    AND permissions.resource = $3
-    AND (
-        permissions.action = $4 OR 
-        ($4 = 'view' AND permissions.action = 'edit')
-    )
-    AND NOT EXISTS (SELECT 1 FROM user_permissions)
-  LIMIT 1
-)
-SELECT EXISTS(
-    SELECT 1 FROM user_permissions
-    UNION ALL
-    SELECT 1 FROM fallback_permissions
-)
-`
-
-type UserHasPermissionParams struct {
-	UserID      pgtype.UUID `json:"user_id"`
-	TenantID    pgtype.UUID `json:"tenant_id"`
-	Resource    string      `json:"resource"`
-	Action      string      `json:"action"`
-	RbacEnabled interface{} `json:"rbac_enabled"`
-}
-
-func (q *Queries) UserHasPermission(ctx context.Context, arg *UserHasPermissionParams) (bool, error) {
-	row := q.db.QueryRow(ctx, UserHasPermission,
-		arg.UserID,
-		arg.TenantID,
-		arg.Resource,
-		arg.Action,
-		arg.RbacEnabled,
-	)
-	var exists bool
-	err := row.Scan(&exists)
-	return exists, err
-}
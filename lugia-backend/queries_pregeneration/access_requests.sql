@@ -0,0 +1,109 @@
+-- name: CreateAccessRequest :one
+INSERT INTO access_requests (tenant_id, requester_id, role_id, justification, duration_hours, expires_at)
+VALUES (@tenant_id, @requester_id, @role_id, @justification, @duration_hours, @expires_at)
+RETURNING id;
+
+-- name: HasPendingAccessRequest :one
+SELECT EXISTS (
+    SELECT 1 FROM access_requests
+    WHERE tenant_id = @tenant_id AND requester_id = @requester_id AND role_id = @role_id AND status = 'pending'
+);
+
+-- name: ListAccessRequests :many
+SELECT
+    ar.id,
+    ar.requester_id,
+    requester.name AS requester_name,
+    requester.email AS requester_email,
+    ar.role_id,
+    r.name AS role_name,
+    ar.justification,
+    ar.duration_hours,
+    ar.status,
+    ar.expires_at,
+    decider.name AS decided_by_name,
+    ar.decided_at,
+    ar.decision_note,
+    ar.granted_until,
+    ar.created_at
+FROM access_requests ar
+INNER JOIN users requester ON requester.id = ar.requester_id
+INNER JOIN roles r ON r.id = ar.role_id
+LEFT JOIN users decider ON decider.id = ar.decided_by
+WHERE ar.tenant_id = @tenant_id
+AND (@requester_id::uuid IS NULL OR ar.requester_id = @requester_id)
+AND (@status::varchar = '' OR ar.status = @status)
+ORDER BY ar.created_at DESC
+LIMIT 100;
+
+-- name: GetAccessRequestForDecision :one
+SELECT
+    ar.id,
+    ar.requester_id,
+    requester.name AS requester_name,
+    requester.email AS requester_email,
+    ar.role_id,
+    r.name AS role_name,
+    ar.justification,
+    ar.duration_hours,
+    ar.status,
+    ar.expires_at
+FROM access_requests ar
+INNER JOIN users requester ON requester.id = ar.requester_id
+INNER JOIN roles r ON r.id = ar.role_id
+WHERE ar.id = @id AND ar.tenant_id = @tenant_id
+FOR UPDATE OF ar;
+
+-- name: DecideAccessRequest :exec
+UPDATE access_requests
+SET status = @status,
+    decided_by = @decided_by,
+    decided_at = CURRENT_TIMESTAMP,
+    decision_note = @decision_note,
+    granted_until = @granted_until
+WHERE id = @id AND tenant_id = @tenant_id AND status = 'pending';
+
+-- name: GetAccessRequestApprovers :many
+WITH RECURSIVE granted_roles AS (
+    SELECT user_roles.user_id, user_roles.role_id
+    FROM user_roles
+    JOIN roles ON roles.id = user_roles.role_id
+    WHERE user_roles.tenant_id = @tenant_id
+        AND (user_roles.expires_at IS NULL OR user_roles.expires_at > CURRENT_TIMESTAMP)
+        AND (@rbac_enabled::boolean = true OR roles.is_default = true)
+    UNION
+    SELECT granted_roles.user_id, role_inclusions.included_role_id
+    FROM granted_roles
+    JOIN role_inclusions ON role_inclusions.role_id = granted_roles.role_id
+)
+SELECT DISTINCT users.id, users.name, users.email
+FROM users
+JOIN tenant_memberships ON tenant_memberships.user_id = users.id
+JOIN granted_roles ON granted_roles.user_id = users.id
+JOIN role_permissions ON role_permissions.role_id = granted_roles.role_id
+JOIN permissions ON permissions.id = role_permissions.permission_id
+WHERE tenant_memberships.tenant_id = @tenant_id
+    AND users.id <> @requester_id
+    AND users.status = 'active'
+    AND users.deleted_at IS NULL
+    AND users.is_internal_user = false
+    -- Everyone who could approve the request: roles.edit from a role that
+    -- currently counts, directly or through an included role.
+    AND permissions.resource = 'roles'
+    AND permissions.action = 'edit'
+ORDER BY users.email;
+
+-- name: GetRequestableRoles :many
+SELECT roles.id, roles.name, roles.description
+FROM roles
+WHERE roles.tenant_id = @tenant_id
+    -- Custom roles are ignored by permission checks while RBAC is off.
+    AND (@rbac_enabled::boolean = true OR roles.is_default = true)
+    -- A role already held permanently has nothing left to grant.
+    AND NOT EXISTS (
+        SELECT 1 FROM user_roles
+        WHERE user_roles.user_id = @user_id
+            AND user_roles.role_id = roles.id
+            AND user_roles.expires_at IS NULL
+    )
+ORDER BY roles.is_default DESC, roles.name;
@@ -5,7 +5,7 @@ VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
 -- name: CountAuditLogs :one
 SELECT COUNT(*)
 FROM audit_logs al
-INNER JOIN users u ON u.id = al.actor_id
+LEFT JOIN users u ON u.id = al.actor_id
 WHERE al.tenant_id = @tenant_id
 AND (@actor_id::uuid IS NULL OR al.actor_id = @actor_id)
 AND (@resource_type::varchar = '' OR al.resource_type = @resource_type)
@@ -19,8 +19,8 @@ SELECT
     al.id,
     al.tenant_id,
     al.actor_id,
-    u.name AS actor_name,
-    u.email AS actor_email,
+    COALESCE(u.name, '')::varchar AS actor_name,
+    COALESCE(u.email, '')::varchar AS actor_email,
     al.resource_type,
     al.action,
     al.outcome,
@@ -30,7 +30,7 @@ SELECT
     al.user_agent,
     al.created_at
 FROM audit_logs al
-INNER JOIN users u ON u.id = al.actor_id
+LEFT JOIN users u ON u.id = al.actor_id
 WHERE al.tenant_id = @tenant_id
 AND (@actor_id::uuid IS NULL OR al.actor_id = @actor_id)
 AND (@resource_type::varchar = '' OR al.resource_type = @resource_type)
@@ -40,3 +40,23 @@ AND (@from_date::timestamptz IS NULL OR al.created_at >= @from_date)
 AND (@to_date::timestamptz IS NULL OR al.created_at <= @to_date)
 ORDER BY al.created_at DESC
 LIMIT @limit_count OFFSET @offset_count;
+
+-- name: ListAuditLogsForUser :many
+SELECT
+    al.id,
+    al.actor_id,
+    al.resource_type,
+    al.action,
+    al.outcome,
+    al.resource_id,
+    al.metadata,
+    al.ip_address,
+    al.user_agent,
+    al.created_at
+FROM audit_logs al
+WHERE al.tenant_id = @tenant_id
+AND (
+    al.actor_id = @user_id
+    OR (al.resource_type = 'user' AND al.resource_id = @user_id::text)
+)
+ORDER BY al.created_at DESC;
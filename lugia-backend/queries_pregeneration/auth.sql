@@ -45,8 +45,9 @@ INSERT INTO refresh_tokens (
     jti,
     device_info,
     ip_address,
-    expires_at
-) VALUES ($1, $2, $3, $4, $5) RETURNING *;
+    expires_at,
+    tenant_id
+) VALUES ($1, $2, $3, $4, $5, $6) RETURNING *;
 
 -- name: RevokeRefreshToken :exec
 UPDATE refresh_tokens 
@@ -100,7 +101,8 @@ SELECT
     tenants.enterprise_features,
     users.is_internal_user
 FROM tenants
-JOIN users ON users.tenant_id = tenants.id
+JOIN tenant_memberships ON tenant_memberships.tenant_id = tenants.id
+JOIN users ON users.id = tenant_memberships.user_id
 WHERE tenants.id = @tenant_id AND users.id = @user_id AND users.deleted_at IS NULL;
 
 -- name: GetSSOTenantByDomain :one
@@ -118,6 +120,13 @@ DELETE FROM sso_auth_requests
 WHERE request_id = $1
 RETURNING request_id, tenant_id, email, expires_at;
 
--- name: DeleteExpiredSSORequests :exec
-DELETE FROM sso_auth_requests
-WHERE expires_at < NOW();
\ No newline at end of file
+-- name: UpdateUserLastLoginAt :exec
+UPDATE users
+SET last_login_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: GetRefreshTokensByUserID :many
+SELECT id, device_info, ip_address, expires_at, created_at, used_at, revoked_at
+FROM refresh_tokens
+WHERE user_id = $1
+ORDER BY created_at DESC;
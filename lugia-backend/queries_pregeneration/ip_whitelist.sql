@@ -1,20 +1,20 @@
 -- name: GetTenantIPWhitelist :many
-SELECT id, tenant_id, ip_address, label, created_by, created_at
+SELECT id, tenant_id, ip_address, label, created_by, created_at, expires_at, expiry_warned_at, scope_id
 FROM tenant_ip_whitelist
 WHERE tenant_id = $1
 ORDER BY created_at ASC;
 
 -- name: AddIPToWhitelist :one
-INSERT INTO tenant_ip_whitelist (tenant_id, ip_address, label, created_by)
-VALUES ($1, $2, $3, $4)
-RETURNING id, tenant_id, ip_address, label, created_by, created_at;
+INSERT INTO tenant_ip_whitelist (tenant_id, ip_address, label, created_by, expires_at, scope_id)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, tenant_id, ip_address, label, created_by, created_at, expires_at, expiry_warned_at, scope_id;
 
 -- name: RemoveIPFromWhitelist :exec
 DELETE FROM tenant_ip_whitelist
 WHERE id = $1 AND tenant_id = $2;
 
 -- name: GetIPWhitelistRuleByID :one
-SELECT id, tenant_id, ip_address, label, created_by, created_at
+SELECT id, tenant_id, ip_address, label, created_by, created_at, expires_at, expiry_warned_at, scope_id
 FROM tenant_ip_whitelist
 WHERE id = $1 AND tenant_id = $2;
 
@@ -31,6 +31,8 @@ WHERE tenant_id = $1;
 SELECT ip_address::text as ip_address
 FROM tenant_ip_whitelist
 WHERE tenant_id = $1
+    AND scope_id IS NULL
+    AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
 ORDER BY created_at ASC;
 
 -- name: CountTenantIPWhitelistRules :one
@@ -43,40 +45,286 @@ SELECT EXISTS(
     SELECT 1 
     FROM tenant_ip_whitelist 
     WHERE tenant_id = $1 AND ip_address = $2
+        AND scope_id IS NOT DISTINCT FROM $3
+        AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
 ) AS exists;
 
 -- IP Whitelist Emergency Token Operations
 
 -- name: CreateIPWhitelistEmergencyToken :one
-INSERT INTO ip_whitelist_emergency_tokens (jti)
-VALUES ($1)
-RETURNING id, jti, used_at, created_at;
+INSERT INTO ip_whitelist_emergency_tokens (jti, tenant_id, user_id, expires_at)
+VALUES ($1, $2, $3, $4)
+RETURNING id, jti, used_at, created_at, tenant_id, user_id, expires_at;
 
 -- name: GetIPWhitelistEmergencyTokenByJTI :one
-SELECT id, jti, used_at, created_at
+SELECT id, jti, used_at, created_at, tenant_id, user_id, expires_at
 FROM ip_whitelist_emergency_tokens
 WHERE jti = $1;
 
+-- name: HasPendingIPWhitelistEmergencyToken :one
+SELECT EXISTS(
+    SELECT 1
+    FROM ip_whitelist_emergency_tokens
+    WHERE tenant_id = $1
+      AND user_id = $2
+      AND used_at IS NULL
+      AND expires_at > CURRENT_TIMESTAMP
+) AS exists;
+
 -- name: MarkIPWhitelistEmergencyTokenAsUsed :exec
 UPDATE ip_whitelist_emergency_tokens
 SET used_at = CURRENT_TIMESTAMP
 WHERE jti = $1;
 
+-- IP Whitelist Break-Glass Operations
+
+-- name: DeleteIPWhitelistRecoveryCodes :exec
+DELETE FROM ip_whitelist_recovery_codes
+WHERE tenant_id = @tenant_id;
+
+-- name: CreateIPWhitelistRecoveryCode :exec
+INSERT INTO ip_whitelist_recovery_codes (tenant_id, code_hash, created_by)
+VALUES (@tenant_id, @code_hash, @created_by);
+
+-- name: GetIPWhitelistRecoveryCodeStatus :one
+SELECT
+    COUNT(*) FILTER (WHERE used_at IS NULL) AS remaining,
+    MAX(created_at)::timestamptz AS issued_at
+FROM ip_whitelist_recovery_codes
+WHERE tenant_id = @tenant_id;
+
+-- name: GetUnusedIPWhitelistRecoveryCode :one
+SELECT id, tenant_id
+FROM ip_whitelist_recovery_codes
+WHERE code_hash = @code_hash AND used_at IS NULL;
+
+-- name: UseIPWhitelistRecoveryCode :execrows
+UPDATE ip_whitelist_recovery_codes
+SET used_at = CURRENT_TIMESTAMP, used_by = @used_by
+WHERE id = @id AND used_at IS NULL;
+
+-- name: GetIPWhitelistEmergencyHolders :many
+WITH RECURSIVE granted_roles AS (
+    SELECT user_roles.user_id, user_roles.role_id
+    FROM user_roles
+    JOIN roles ON roles.id = user_roles.role_id
+    WHERE user_roles.tenant_id = @tenant_id
+        AND (user_roles.expires_at IS NULL OR user_roles.expires_at > CURRENT_TIMESTAMP)
+        AND (@rbac_enabled::boolean = true OR roles.is_default = true)
+    UNION
+    SELECT granted_roles.user_id, role_inclusions.included_role_id
+    FROM granted_roles
+    JOIN role_inclusions ON role_inclusions.role_id = granted_roles.role_id
+)
+SELECT DISTINCT users.id, users.name, users.email
+FROM users
+JOIN tenant_memberships ON tenant_memberships.user_id = users.id
+JOIN granted_roles ON granted_roles.user_id = users.id
+JOIN role_permissions ON role_permissions.role_id = granted_roles.role_id
+JOIN permissions ON permissions.id = role_permissions.permission_id
+WHERE tenant_memberships.tenant_id = @tenant_id
+    AND users.status = 'active'
+    AND users.deleted_at IS NULL
+    AND users.is_internal_user = false
+    AND permissions.resource = 'ip_whitelist'
+    AND permissions.action = 'emergency'
+ORDER BY users.email;
+
+-- name: HasOpenIPWhitelistBreakGlassRequest :one
+SELECT EXISTS(
+    SELECT 1
+    FROM ip_whitelist_break_glass_requests
+    WHERE tenant_id = @tenant_id
+      AND completed_at IS NULL
+      AND expires_at > CURRENT_TIMESTAMP
+) AS exists;
+
+-- name: CreateIPWhitelistBreakGlassRequest :one
+INSERT INTO ip_whitelist_break_glass_requests (tenant_id, requested_by, expires_at)
+VALUES (@tenant_id, @requested_by, @expires_at)
+RETURNING id;
+
+-- name: CreateIPWhitelistBreakGlassApproval :exec
+INSERT INTO ip_whitelist_break_glass_approvals (request_id, user_id, token_hash)
+VALUES (@request_id, @user_id, @token_hash);
+
+-- name: GetIPWhitelistBreakGlassApprovalByTokenHash :one
+SELECT
+    ip_whitelist_break_glass_approvals.id,
+    ip_whitelist_break_glass_approvals.request_id,
+    ip_whitelist_break_glass_approvals.user_id,
+    ip_whitelist_break_glass_approvals.approved_at,
+    ip_whitelist_break_glass_requests.tenant_id,
+    ip_whitelist_break_glass_requests.expires_at,
+    ip_whitelist_break_glass_requests.completed_at
+FROM ip_whitelist_break_glass_approvals
+JOIN ip_whitelist_break_glass_requests ON ip_whitelist_break_glass_requests.id = ip_whitelist_break_glass_approvals.request_id
+WHERE ip_whitelist_break_glass_approvals.token_hash = @token_hash;
+
+-- name: LockIPWhitelistBreakGlassRequest :one
+SELECT completed_at
+FROM ip_whitelist_break_glass_requests
+WHERE id = @id
+FOR UPDATE;
+
+-- name: ApproveIPWhitelistBreakGlassRequest :execrows
+UPDATE ip_whitelist_break_glass_approvals
+SET approved_at = CURRENT_TIMESTAMP
+WHERE id = @id AND approved_at IS NULL;
+
+-- name: GetIPWhitelistBreakGlassApprovers :many
+SELECT users.id, users.name, users.email
+FROM ip_whitelist_break_glass_approvals
+JOIN users ON users.id = ip_whitelist_break_glass_approvals.user_id
+WHERE ip_whitelist_break_glass_approvals.request_id = @request_id
+  AND ip_whitelist_break_glass_approvals.approved_at IS NOT NULL
+ORDER BY ip_whitelist_break_glass_approvals.approved_at;
+
+-- name: CompleteIPWhitelistBreakGlassRequest :exec
+UPDATE ip_whitelist_break_glass_requests
+SET completed_at = CURRENT_TIMESTAMP
+WHERE id = @id;
+
 
 
 
 -- name: GetIPWhitelistForMiddleware :many
-WITH tenant_features AS (
-    SELECT 
-        tenants.id,
-        (tenants.enterprise_features->'ip_whitelist'->>'enabled')::boolean as ip_whitelist_enabled,
-        (tenants.enterprise_features->'ip_whitelist'->>'allow_internal_admin_bypass')::boolean as allow_internal_bypass
-    FROM tenants WHERE tenants.id = $1
+SELECT
+    tenant_ip_whitelist.ip_address::text as ip_address,
+    -- The cached matcher is reloaded when the first of these passes.
+    tenant_ip_whitelist.expires_at,
+    -- NULL for tenant rules.
+    tenant_ip_whitelist.scope_id
+FROM tenant_ip_whitelist
+WHERE tenant_ip_whitelist.tenant_id = $1
+    -- Expired rules stop matching before the maintenance runner deletes them.
+    AND (tenant_ip_whitelist.expires_at IS NULL OR tenant_ip_whitelist.expires_at > CURRENT_TIMESTAMP);
+
+-- name: TenantHasIPWhitelistScopes :one
+SELECT EXISTS(
+    SELECT 1
+    FROM ip_whitelist_scopes
+    WHERE tenant_id = $1
+) AS exists;
+
+-- name: GetUserIPWhitelistScopes :many
+-- The user's roles are counted the way permissions are: directly assigned,
+-- unexpired roles (only default roles while RBAC is off) and every role they
+-- include, transitively.
+WITH RECURSIVE granted_roles AS (
+    SELECT user_roles.role_id
+    FROM user_roles
+    JOIN roles ON roles.id = user_roles.role_id
+    WHERE user_roles.user_id = @user_id
+        AND user_roles.tenant_id = @tenant_id
+        AND (@rbac_enabled::boolean = true OR roles.is_default = true)
+        AND (user_roles.expires_at IS NULL OR user_roles.expires_at > CURRENT_TIMESTAMP)
+    UNION
+    SELECT role_inclusions.included_role_id
+    FROM granted_roles
+    JOIN role_inclusions ON role_inclusions.role_id = granted_roles.role_id
+)
+SELECT
+    ip_whitelist_scopes.id,
+    ip_whitelist_scopes.exempt,
+    ip_whitelist_scopes.user_id IS NOT NULL AS is_user_scope
+FROM ip_whitelist_scopes
+WHERE ip_whitelist_scopes.tenant_id = @tenant_id
+    AND (
+        ip_whitelist_scopes.user_id = @user_id
+        OR ip_whitelist_scopes.role_id IN (SELECT granted_roles.role_id FROM granted_roles)
+    );
+
+-- name: GetTenantIPWhitelistScopes :many
+SELECT
+    ip_whitelist_scopes.id,
+    ip_whitelist_scopes.role_id,
+    roles.name AS role_name,
+    ip_whitelist_scopes.user_id,
+    users.name AS user_name,
+    users.email AS user_email,
+    ip_whitelist_scopes.exempt,
+    ip_whitelist_scopes.created_at
+FROM ip_whitelist_scopes
+LEFT JOIN roles ON roles.id = ip_whitelist_scopes.role_id
+LEFT JOIN users ON users.id = ip_whitelist_scopes.user_id
+WHERE ip_whitelist_scopes.tenant_id = $1
+ORDER BY ip_whitelist_scopes.created_at ASC;
+
+-- name: GetIPWhitelistScopeByID :one
+SELECT
+    ip_whitelist_scopes.id,
+    ip_whitelist_scopes.role_id,
+    roles.name AS role_name,
+    ip_whitelist_scopes.user_id,
+    users.name AS user_name,
+    users.email AS user_email,
+    ip_whitelist_scopes.exempt,
+    ip_whitelist_scopes.created_at
+FROM ip_whitelist_scopes
+LEFT JOIN roles ON roles.id = ip_whitelist_scopes.role_id
+LEFT JOIN users ON users.id = ip_whitelist_scopes.user_id
+WHERE ip_whitelist_scopes.id = $1 AND ip_whitelist_scopes.tenant_id = $2;
+
+-- name: CreateIPWhitelistScope :one
+INSERT INTO ip_whitelist_scopes (tenant_id, role_id, user_id, exempt, created_by)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT DO NOTHING
+RETURNING id;
+
+-- name: UpdateIPWhitelistScopeExempt :exec
+UPDATE ip_whitelist_scopes
+SET exempt = $1
+WHERE id = $2 AND tenant_id = $3;
+
+-- name: DeleteIPWhitelistScope :one
+WITH deleted AS (
+    DELETE FROM ip_whitelist_scopes
+    WHERE ip_whitelist_scopes.id = $1 AND ip_whitelist_scopes.tenant_id = $2
+    RETURNING ip_whitelist_scopes.id
 )
-SELECT 
-    tenant_features.ip_whitelist_enabled,
-    tenant_features.allow_internal_bypass,
-    tenant_ip_whitelist.ip_address::text as ip_address
-FROM tenant_features
-JOIN tenant_ip_whitelist ON tenant_features.id = tenant_ip_whitelist.tenant_id
-WHERE tenant_features.ip_whitelist_enabled = true;
\ No newline at end of file
+-- The scope's rules go with it (ON DELETE CASCADE); count them for the
+-- audit log first.
+SELECT COUNT(tenant_ip_whitelist.id)
+FROM deleted
+LEFT JOIN tenant_ip_whitelist ON tenant_ip_whitelist.scope_id = deleted.id;
+
+-- name: GetTenantIPWhitelistCountries :many
+SELECT * FROM tenant_ip_whitelist_countries
+WHERE tenant_id = $1
+ORDER BY action, country_code;
+
+-- name: GetIPWhitelistCountryByID :one
+SELECT * FROM tenant_ip_whitelist_countries
+WHERE id = $1 AND tenant_id = $2;
+
+-- name: AddIPWhitelistCountry :one
+INSERT INTO tenant_ip_whitelist_countries (tenant_id, country_code, action, created_by)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT DO NOTHING
+RETURNING *;
+
+-- name: DeleteIPWhitelistCountry :exec
+DELETE FROM tenant_ip_whitelist_countries
+WHERE id = $1 AND tenant_id = $2;
+
+-- name: RecordIPWhitelistMonitorHit :exec
+INSERT INTO ip_whitelist_monitor_hits (tenant_id, user_id, ip_address, hour)
+VALUES (@tenant_id, @user_id, @ip_address, date_trunc('hour', CURRENT_TIMESTAMP))
+ON CONFLICT (tenant_id, hour, user_id, ip_address)
+DO UPDATE SET hits = ip_whitelist_monitor_hits.hits + 1, last_seen_at = CURRENT_TIMESTAMP;
+
+-- name: GetIPWhitelistMonitorReport :many
+SELECT
+    host(ip_whitelist_monitor_hits.ip_address) AS ip_address,
+    users.id AS user_id,
+    users.name AS user_name,
+    users.email AS user_email,
+    SUM(ip_whitelist_monitor_hits.hits)::bigint AS hits,
+    MAX(ip_whitelist_monitor_hits.last_seen_at)::timestamptz AS last_seen_at
+FROM ip_whitelist_monitor_hits
+JOIN users ON users.id = ip_whitelist_monitor_hits.user_id
+WHERE ip_whitelist_monitor_hits.tenant_id = @tenant_id
+  AND ip_whitelist_monitor_hits.hour >= date_trunc('hour', @since::timestamptz)
+GROUP BY ip_whitelist_monitor_hits.ip_address, users.id, users.name, users.email
+ORDER BY hits DESC, ip_address, users.email;
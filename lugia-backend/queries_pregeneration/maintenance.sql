@@ -0,0 +1,209 @@
+-- name: TryMaintenanceLock :one
+SELECT pg_try_advisory_xact_lock(@lock_key::bigint) AS acquired;
+
+-- name: PurgeExpiredPasswordResetTokens :execrows
+DELETE FROM password_reset_tokens
+WHERE id IN (
+    SELECT id FROM password_reset_tokens
+    WHERE expires_at < @cutoff::timestamptz
+    LIMIT @batch_size::int
+);
+
+-- name: PurgeExpiredEmailChangeTokens :execrows
+DELETE FROM email_change_tokens
+WHERE id IN (
+    SELECT id FROM email_change_tokens
+    WHERE expires_at < @cutoff::timestamptz
+    LIMIT @batch_size::int
+);
+
+-- name: PurgeExpiredInvitationTokens :execrows
+DELETE FROM invitation_tokens
+WHERE id IN (
+    SELECT id FROM invitation_tokens
+    WHERE expires_at < @cutoff::timestamptz
+    LIMIT @batch_size::int
+);
+
+-- name: PurgeExpiredRefreshTokens :execrows
+DELETE FROM refresh_tokens
+WHERE id IN (
+    SELECT id FROM refresh_tokens
+    WHERE expires_at < @cutoff::timestamptz
+    LIMIT @batch_size::int
+);
+
+-- name: PurgeExpiredSSOAuthRequests :execrows
+DELETE FROM sso_auth_requests
+WHERE request_id IN (
+    SELECT request_id FROM sso_auth_requests
+    WHERE expires_at < @cutoff::timestamptz
+    LIMIT @batch_size::int
+);
+
+-- name: PurgeAnonymizedUsers :execrows
+WITH purgeable AS (
+    -- audit_logs.actor_id and tenant_ip_whitelist.created_by are set to NULL
+    -- by their foreign keys.
+    SELECT users.id FROM users
+    WHERE users.deleted_at < @cutoff::timestamptz
+    LIMIT @batch_size::int
+),
+deleted_user_roles AS (
+    DELETE FROM user_roles WHERE user_id IN (SELECT id FROM purgeable)
+),
+deleted_refresh_tokens AS (
+    DELETE FROM refresh_tokens WHERE user_id IN (SELECT id FROM purgeable)
+),
+deleted_password_reset_tokens AS (
+    DELETE FROM password_reset_tokens WHERE user_id IN (SELECT id FROM purgeable)
+),
+deleted_email_change_tokens AS (
+    DELETE FROM email_change_tokens WHERE user_id IN (SELECT id FROM purgeable)
+),
+deleted_invitation_tokens AS (
+    DELETE FROM invitation_tokens WHERE user_id IN (SELECT id FROM purgeable)
+)
+DELETE FROM users
+WHERE id IN (SELECT id FROM purgeable);
+
+-- name: PurgeExpiredUserRoles :many
+WITH expired AS (
+    DELETE FROM user_roles
+    WHERE (user_id, role_id) IN (
+        SELECT user_id, role_id FROM user_roles
+        WHERE expires_at <= @cutoff::timestamptz
+        LIMIT @batch_size::int
+    )
+    RETURNING user_id, role_id, tenant_id, expires_at, granted_by
+)
+SELECT
+    expired.user_id,
+    expired.role_id,
+    expired.tenant_id,
+    expired.expires_at,
+    expired.granted_by,
+    roles.name AS role_name,
+    users.name AS user_name,
+    users.email AS user_email,
+    COALESCE((tenants.enterprise_features->'audit_log'->>'enabled')::boolean, false)::boolean AS audit_log_enabled
+FROM expired
+JOIN roles ON roles.id = expired.role_id
+JOIN users ON users.id = expired.user_id
+JOIN tenants ON tenants.id = expired.tenant_id;
+
+-- name: ExpirePendingAccessRequests :many
+WITH expired AS (
+    UPDATE access_requests
+    SET status = 'expired'
+    WHERE id IN (
+        SELECT id FROM access_requests
+        WHERE status = 'pending' AND expires_at <= @cutoff::timestamptz
+        LIMIT @batch_size::int
+    )
+    RETURNING id, tenant_id, requester_id, role_id, expires_at
+)
+SELECT
+    expired.id,
+    expired.tenant_id,
+    expired.requester_id,
+    expired.role_id,
+    expired.expires_at,
+    roles.name AS role_name,
+    users.name AS requester_name,
+    users.email AS requester_email,
+    COALESCE((tenants.enterprise_features->'audit_log'->>'enabled')::boolean, false)::boolean AS audit_log_enabled
+FROM expired
+JOIN roles ON roles.id = expired.role_id
+JOIN users ON users.id = expired.requester_id
+JOIN tenants ON tenants.id = expired.tenant_id;
+
+-- name: PurgeIPWhitelistMonitorHits :execrows
+DELETE FROM ip_whitelist_monitor_hits
+WHERE (tenant_id, hour, user_id, ip_address) IN (
+    SELECT tenant_id, hour, user_id, ip_address FROM ip_whitelist_monitor_hits
+    WHERE hour < @cutoff::timestamptz
+    LIMIT @batch_size::int
+);
+
+-- name: PurgeExpiredIPWhitelistBreakGlassRequests :execrows
+DELETE FROM ip_whitelist_break_glass_requests
+WHERE id IN (
+    SELECT id FROM ip_whitelist_break_glass_requests
+    WHERE expires_at < @cutoff::timestamptz
+    LIMIT @batch_size::int
+);
+
+-- name: PurgeExpiredIPWhitelistRules :many
+WITH expired AS (
+    DELETE FROM tenant_ip_whitelist
+    WHERE id IN (
+        SELECT id FROM tenant_ip_whitelist
+        WHERE expires_at <= @cutoff::timestamptz
+        LIMIT @batch_size::int
+    )
+    RETURNING id, tenant_id, ip_address, label, created_by, expires_at
+)
+SELECT
+    expired.id,
+    expired.tenant_id,
+    expired.ip_address::text AS ip_address,
+    expired.label,
+    expired.created_by,
+    expired.expires_at,
+    COALESCE((tenants.enterprise_features->'audit_log'->>'enabled')::boolean, false)::boolean AS audit_log_enabled
+FROM expired
+JOIN tenants ON tenants.id = expired.tenant_id;
+
+-- name: ClaimExpiringIPWhitelistRules :many
+WITH claimed AS (
+    UPDATE tenant_ip_whitelist
+    SET expiry_warned_at = CURRENT_TIMESTAMP
+    WHERE id IN (
+        SELECT id FROM tenant_ip_whitelist
+        WHERE expiry_warned_at IS NULL
+            AND expires_at > CURRENT_TIMESTAMP
+            AND expires_at <= @warn_before::timestamptz
+        ORDER BY tenant_id, expires_at
+        LIMIT @batch_size::int
+    )
+    RETURNING id, tenant_id, ip_address, label, expires_at
+)
+SELECT
+    claimed.id,
+    claimed.tenant_id,
+    claimed.ip_address::text AS ip_address,
+    claimed.label,
+    claimed.expires_at,
+    tenants.name AS tenant_name,
+    COALESCE((tenants.enterprise_features->'rbac'->>'enabled')::boolean, false)::boolean AS rbac_enabled
+FROM claimed
+JOIN tenants ON tenants.id = claimed.tenant_id
+ORDER BY claimed.tenant_id, claimed.expires_at;
+
+-- name: GetIPWhitelistEditors :many
+WITH RECURSIVE granted_roles AS (
+    SELECT user_roles.user_id, user_roles.role_id
+    FROM user_roles
+    JOIN roles ON roles.id = user_roles.role_id
+    WHERE user_roles.tenant_id = @tenant_id
+        AND (user_roles.expires_at IS NULL OR user_roles.expires_at > CURRENT_TIMESTAMP)
+        AND (@rbac_enabled::boolean = true OR roles.is_default = true)
+    UNION
+    SELECT granted_roles.user_id, role_inclusions.included_role_id
+    FROM granted_roles
+    JOIN role_inclusions ON role_inclusions.role_id = granted_roles.role_id
+)
+SELECT DISTINCT users.id, users.name, users.email
+FROM users
+JOIN tenant_memberships ON tenant_memberships.user_id = users.id
+JOIN granted_roles ON granted_roles.user_id = users.id
+JOIN role_permissions ON role_permissions.role_id = granted_roles.role_id
+JOIN permissions ON permissions.id = role_permissions.permission_id
+WHERE tenant_memberships.tenant_id = @tenant_id
+    AND users.status = 'active'
+    AND users.deleted_at IS NULL
+    AND users.is_internal_user = false
+    AND permissions.resource = 'ip_whitelist'
+    AND permissions.action = 'edit'
+ORDER BY users.email;
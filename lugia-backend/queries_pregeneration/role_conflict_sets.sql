@@ -0,0 +1,52 @@
+-- name: GetRoleConflictSets :many
+SELECT
+    role_conflict_sets.id,
+    role_conflict_sets.name,
+    role_conflict_sets.created_at,
+    roles.id AS role_id,
+    roles.name AS role_name
+FROM role_conflict_sets
+LEFT JOIN role_conflict_set_roles ON role_conflict_set_roles.conflict_set_id = role_conflict_sets.id
+LEFT JOIN roles ON roles.id = role_conflict_set_roles.role_id
+WHERE role_conflict_sets.tenant_id = @tenant_id
+ORDER BY role_conflict_sets.name, roles.name;
+
+-- name: GetRoleConflictSetByID :one
+SELECT * FROM role_conflict_sets
+WHERE id = @id AND tenant_id = @tenant_id;
+
+-- name: CheckRoleConflictSetNameExists :one
+SELECT EXISTS(
+    SELECT 1 FROM role_conflict_sets
+    WHERE tenant_id = @tenant_id AND name = @name
+) as exists;
+
+-- name: CreateRoleConflictSet :one
+INSERT INTO role_conflict_sets (tenant_id, name)
+VALUES (@tenant_id, @name)
+RETURNING id;
+
+-- name: CreateRoleConflictSetRolesBulk :exec
+INSERT INTO role_conflict_set_roles (conflict_set_id, role_id, tenant_id)
+SELECT @conflict_set_id, UNNEST(@role_ids::uuid[]), @tenant_id;
+
+-- name: DeleteRoleConflictSet :exec
+DELETE FROM role_conflict_sets
+WHERE id = @id AND tenant_id = @tenant_id;
+
+-- name: GetTenantActiveRoleAssignments :many
+SELECT users.id AS user_id, users.name, users.email, user_roles.role_id
+FROM users
+JOIN tenant_memberships ON tenant_memberships.user_id = users.id
+JOIN user_roles ON user_roles.user_id = users.id AND user_roles.tenant_id = tenant_memberships.tenant_id
+WHERE tenant_memberships.tenant_id = @tenant_id
+    AND users.deleted_at IS NULL
+    AND users.is_internal_user = false
+    AND (user_roles.expires_at IS NULL OR user_roles.expires_at > CURRENT_TIMESTAMP)
+ORDER BY users.email, user_roles.role_id;
+
+-- name: CopyRoleConflictSetMemberships :exec
+INSERT INTO role_conflict_set_roles (conflict_set_id, role_id, tenant_id)
+SELECT conflict_set_id, @new_role_id, tenant_id
+FROM role_conflict_set_roles
+WHERE role_id = @source_role_id AND tenant_id = @tenant_id;
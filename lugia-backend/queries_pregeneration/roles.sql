@@ -11,12 +11,12 @@ SELECT
     permissions.description as permission_description
 FROM roles
 LEFT JOIN role_permissions ON roles.id = role_permissions.role_id
-LEFT JOIN permissions ON role_permissions.permission_id = permissions.id
+LEFT JOIN permissions ON role_permissions.permission_id = permissions.id AND permissions.removed_at IS NULL
 WHERE roles.tenant_id = $1
 ORDER BY roles.name, permissions.description;
 
 -- name: GetAllPermissions :many
-SELECT id, resource, action, description FROM permissions;
+SELECT id, resource, action, description FROM permissions WHERE removed_at IS NULL;
 
 -- name: CreateRole :one
 INSERT INTO roles (tenant_id, name, description, is_default)
@@ -27,6 +27,32 @@ RETURNING id, tenant_id, name, description, created_at, updated_at;
 INSERT INTO role_permissions (role_id, permission_id, tenant_id)
 SELECT @role_id, UNNEST(@permission_ids::uuid[]), @tenant_id;
 
+-- name: GetRolePermissionIDs :many
+SELECT permission_id FROM role_permissions
+WHERE role_id = $1 AND tenant_id = $2;
+
+-- name: GetIncludedRoleIDs :many
+SELECT included_role_id FROM role_inclusions
+WHERE role_id = $1 AND tenant_id = $2;
+
+-- name: GetTenantRoleInclusions :many
+SELECT role_id, included_role_id FROM role_inclusions
+WHERE tenant_id = $1;
+
+-- name: CreateRoleInclusionsBulk :exec
+INSERT INTO role_inclusions (role_id, included_role_id, tenant_id)
+SELECT @role_id, UNNEST(@included_role_ids::uuid[]), @tenant_id;
+
+-- name: DeleteRoleInclusions :exec
+DELETE FROM role_inclusions
+WHERE role_id = $1 AND tenant_id = $2;
+
+-- name: CheckRoleIncluded :one
+SELECT EXISTS(
+    SELECT 1 FROM role_inclusions
+    WHERE included_role_id = $1 AND tenant_id = $2
+) as exists;
+
 -- name: GetRoleByID :one
 SELECT * FROM roles
 WHERE id = $1 AND tenant_id = $2;
@@ -59,3 +85,58 @@ WHERE id = $1 AND tenant_id = $2 AND is_default = false;
 -- name: GetDefaultViewerRole :one
 SELECT * FROM roles
 WHERE tenant_id = $1 AND is_default = true AND name = '閲覧者';
+
+-- name: LockTenantForRoleChange :exec
+SELECT id FROM tenants
+WHERE id = $1
+FOR UPDATE;
+
+-- name: CountTenantAdministrators :one
+WITH RECURSIVE granted_roles AS (
+    SELECT user_roles.user_id, user_roles.role_id
+    FROM user_roles
+    JOIN roles ON roles.id = user_roles.role_id
+    WHERE user_roles.tenant_id = @tenant_id
+        -- A time-bound grant will lapse on its own, so it can't be what keeps
+        -- the tenant manageable.
+        AND user_roles.expires_at IS NULL
+        AND (@rbac_enabled::boolean = true OR roles.is_default = true)
+    UNION
+    SELECT granted_roles.user_id, role_inclusions.included_role_id
+    FROM granted_roles
+    JOIN role_inclusions ON role_inclusions.role_id = granted_roles.role_id
+)
+SELECT
+    COUNT(DISTINCT users.id) FILTER (WHERE permissions.resource = 'users')::int AS users_editors,
+    COUNT(DISTINCT users.id) FILTER (WHERE permissions.resource = 'roles')::int AS roles_editors
+FROM users
+JOIN tenant_memberships ON tenant_memberships.user_id = users.id
+JOIN granted_roles ON granted_roles.user_id = users.id
+JOIN role_permissions ON role_permissions.role_id = granted_roles.role_id
+JOIN permissions ON permissions.id = role_permissions.permission_id
+WHERE tenant_memberships.tenant_id = @tenant_id
+    AND users.status = 'active'
+    AND users.deleted_at IS NULL
+    AND users.is_internal_user = false
+    AND (permissions.resource, permissions.action) IN (('users', 'assign_roles'), ('roles', 'edit'));
+
+-- name: UpsertPermission :execrows
+INSERT INTO permissions (resource, action, description, description_en)
+VALUES (@resource, @action, @description, @description_en)
+ON CONFLICT (resource, action) DO UPDATE
+SET description = EXCLUDED.description,
+    description_en = EXCLUDED.description_en,
+    removed_at = NULL,
+    updated_at = CURRENT_TIMESTAMP
+WHERE permissions.description IS DISTINCT FROM EXCLUDED.description
+   OR permissions.description_en IS DISTINCT FROM EXCLUDED.description_en
+   OR permissions.removed_at IS NOT NULL;
+
+-- name: MarkPermissionsRemoved :execrows
+UPDATE permissions
+SET removed_at = CURRENT_TIMESTAMP,
+    updated_at = CURRENT_TIMESTAMP
+WHERE removed_at IS NULL
+  AND (resource, action) NOT IN (
+    SELECT * FROM unnest(@resources::text[], @actions::text[])
+  );
@@ -0,0 +1,102 @@
+-- name: GetUserGroups :many
+SELECT id, name, created_at
+FROM user_groups
+WHERE tenant_id = @tenant_id
+ORDER BY name;
+
+-- name: GetUserGroupMembers :many
+SELECT user_group_members.group_id, users.id AS user_id, users.name, users.email
+FROM user_group_members
+JOIN users ON users.id = user_group_members.user_id
+WHERE user_group_members.tenant_id = @tenant_id
+ORDER BY users.name;
+
+-- name: GetUserGroupAdmins :many
+SELECT user_group_admins.group_id, users.id AS user_id, users.name, users.email
+FROM user_group_admins
+JOIN users ON users.id = user_group_admins.user_id
+WHERE user_group_admins.tenant_id = @tenant_id
+ORDER BY users.name;
+
+-- name: GetUserGroupByID :one
+SELECT * FROM user_groups
+WHERE id = @id AND tenant_id = @tenant_id;
+
+-- name: CheckUserGroupNameExists :one
+SELECT EXISTS(
+    SELECT 1 FROM user_groups
+    WHERE tenant_id = @tenant_id AND name = @name
+) as exists;
+
+-- name: CreateUserGroup :one
+INSERT INTO user_groups (tenant_id, name)
+VALUES (@tenant_id, @name)
+RETURNING id;
+
+-- name: UpdateUserGroupName :exec
+UPDATE user_groups
+SET name = @name
+WHERE id = @id AND tenant_id = @tenant_id;
+
+-- name: DeleteUserGroup :exec
+DELETE FROM user_groups
+WHERE id = @id AND tenant_id = @tenant_id;
+
+-- name: DeleteUserGroupMembers :exec
+DELETE FROM user_group_members
+WHERE group_id = @group_id AND tenant_id = @tenant_id;
+
+-- name: AddUserGroupMembersBulk :exec
+INSERT INTO user_group_members (group_id, user_id, tenant_id)
+SELECT @group_id, UNNEST(@user_ids::uuid[]), @tenant_id
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteUserGroupAdmins :exec
+DELETE FROM user_group_admins
+WHERE group_id = @group_id AND tenant_id = @tenant_id;
+
+-- name: AddUserGroupAdminsBulk :exec
+INSERT INTO user_group_admins (group_id, user_id, tenant_id, granted_by)
+SELECT @group_id, UNNEST(@user_ids::uuid[]), @tenant_id, @granted_by
+ON CONFLICT DO NOTHING;
+
+-- name: RemoveUserFromTenantGroups :exec
+WITH removed_members AS (
+    DELETE FROM user_group_members
+    WHERE user_group_members.user_id = @user_id AND user_group_members.tenant_id = @tenant_id
+)
+DELETE FROM user_group_admins
+WHERE user_group_admins.user_id = @user_id AND user_group_admins.tenant_id = @tenant_id;
+
+-- name: GetAdministeredGroupIDs :many
+SELECT group_id
+FROM user_group_admins
+WHERE user_id = @user_id AND tenant_id = @tenant_id;
+
+-- name: IsUserInGroups :one
+SELECT EXISTS(
+    SELECT 1 FROM user_group_members
+    WHERE user_id = @user_id
+      AND tenant_id = @tenant_id
+      AND group_id = ANY(@group_ids::uuid[])
+) as exists;
+
+-- name: ValidateUsersBelongToTenant :many
+SELECT user_id
+FROM tenant_memberships
+WHERE tenant_id = @tenant_id AND user_id = ANY(@user_ids::uuid[]);
+
+-- name: GetRolesPermissionSet :many
+WITH RECURSIVE granted_roles AS (
+  SELECT roles.id AS role_id
+  FROM roles
+  WHERE roles.id = ANY(@role_ids::uuid[]) AND roles.tenant_id = @tenant_id
+  UNION
+  SELECT role_inclusions.included_role_id
+  FROM granted_roles
+  JOIN role_inclusions ON role_inclusions.role_id = granted_roles.role_id
+)
+SELECT DISTINCT permissions.resource, permissions.action
+FROM granted_roles
+JOIN role_permissions ON granted_roles.role_id = role_permissions.role_id
+JOIN permissions ON role_permissions.permission_id = permissions.id;
@@ -98,76 +98,82 @@ UPDATE email_change_tokens
 SET used_at = CURRENT_TIMESTAMP
 WHERE id = $1;
 
--- name: UserHasPermission :one
-WITH user_permissions AS (
-  -- Get permissions from user's assigned roles (filtered by RBAC status)
-  SELECT 1 as found
+-- name: GetUserPermissionSet :many
+WITH RECURSIVE granted_roles AS (
+  -- User's assigned roles (filtered by RBAC status), with when the grant lapses
+  SELECT user_roles.role_id, user_roles.expires_at
   FROM user_roles
   JOIN roles ON user_roles.role_id = roles.id
-  JOIN role_permissions ON user_roles.role_id = role_permissions.role_id
-  JOIN permissions ON role_permissions.permission_id = permissions.id
-  WHERE user_roles.user_id = @user_id 
+  WHERE user_roles.user_id = @user_id
     AND user_roles.tenant_id = @tenant_id
-    AND permissions.resource = @resource
-    AND (
-        permissions.action = @action OR 
-        (@action = 'view' AND permissions.action = 'edit')
-    )
     AND (
       @rbac_enabled = true OR  -- RBAC enabled: use all roles
       roles.is_default = true  -- RBAC disabled: only default roles
     )
-  LIMIT 1
-),
-fallback_permissions AS (
-  -- Fallback: Check 閲覧者 permissions if user has no valid roles
-  SELECT 1 as found
-  FROM roles
-  JOIN role_permissions ON roles.id = role_permissions.role_id
-  JOIN permissions ON role_permissions.permission_id = permissions.id
-  WHERE roles.tenant_id = @tenant_id
-    AND roles.name = '閲覧者'
-    AND roles.is_default = true
-    AND permissions.resource = @resource
-    AND (
-        permissions.action = @action OR 
-        (@action = 'view' AND permissions.action = 'edit')
-    )
-    AND NOT EXISTS (SELECT 1 FROM user_permissions)
-  LIMIT 1
+    AND (user_roles.expires_at IS NULL OR user_roles.expires_at > CURRENT_TIMESTAMP)  -- expired grants no longer count
+  UNION
+  -- Plus every role they include, transitively (UNION stops at cycles)
+  SELECT role_inclusions.included_role_id, granted_roles.expires_at
+  FROM granted_roles
+  JOIN role_inclusions ON role_inclusions.role_id = granted_roles.role_id
 )
-SELECT EXISTS(
-    SELECT 1 FROM user_permissions
-    UNION ALL
-    SELECT 1 FROM fallback_permissions
-);
-
--- name: GetUserRoleIDs :many
-SELECT role_id FROM user_roles
-WHERE user_id = $1 AND tenant_id = $2;
+SELECT permissions.resource, permissions.action, granted_roles.expires_at
+FROM granted_roles
+JOIN role_permissions ON granted_roles.role_id = role_permissions.role_id
+JOIN permissions ON role_permissions.permission_id = permissions.id
+UNION ALL
+-- 閲覧者 applies to any check the user's own roles don't satisfy, so its
+-- permissions are always part of the set
+SELECT permissions.resource, permissions.action, NULL::timestamptz AS expires_at
+FROM roles
+JOIN role_permissions ON roles.id = role_permissions.role_id
+JOIN permissions ON role_permissions.permission_id = permissions.id
+WHERE roles.tenant_id = @tenant_id
+  AND roles.name = '閲覧者'
+  AND roles.is_default = true;
 
 -- name: AddRolesToUser :copyfrom
-INSERT INTO user_roles (user_id, role_id, tenant_id)
-VALUES ($1, $2, $3);
+INSERT INTO user_roles (user_id, role_id, tenant_id, expires_at, granted_by)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: GetUserRoleAssignments :many
+SELECT role_id, expires_at FROM user_roles
+WHERE user_id = @user_id AND tenant_id = @tenant_id;
+
+-- name: SetUserRoleExpiry :exec
+UPDATE user_roles
+SET expires_at = @expires_at, granted_by = @granted_by
+WHERE user_id = @user_id AND tenant_id = @tenant_id AND role_id = @role_id;
 
 -- name: RemoveRolesFromUser :exec
 DELETE FROM user_roles
 WHERE user_id = $1 AND tenant_id = $2 AND role_id = ANY($3::uuid[]);
 
 -- name: GetUserPermissionsWithFallback :many
-WITH user_permissions AS (
-  -- Get permissions from user's assigned roles (filtered by RBAC status)
-  SELECT DISTINCT permissions.resource, permissions.action, 1 as priority
+WITH RECURSIVE granted_roles AS (
+  -- User's assigned roles (filtered by RBAC status)
+  SELECT user_roles.role_id
   FROM user_roles
   JOIN roles ON user_roles.role_id = roles.id
-  JOIN role_permissions ON user_roles.role_id = role_permissions.role_id
-  JOIN permissions ON role_permissions.permission_id = permissions.id
   WHERE user_roles.user_id = @user_id 
     AND user_roles.tenant_id = @tenant_id
     AND (
       @rbac_enabled = true OR  -- RBAC enabled: use all roles
       roles.is_default = true  -- RBAC disabled: only default roles
     )
+    AND (user_roles.expires_at IS NULL OR user_roles.expires_at > CURRENT_TIMESTAMP)  -- expired grants no longer count
+  UNION
+  -- Plus every role they include, transitively (UNION stops at cycles)
+  SELECT role_inclusions.included_role_id
+  FROM granted_roles
+  JOIN role_inclusions ON role_inclusions.role_id = granted_roles.role_id
+),
+user_permissions AS (
+  -- Get permissions from the expanded roles
+  SELECT DISTINCT permissions.resource, permissions.action, 1 as priority
+  FROM granted_roles
+  JOIN role_permissions ON granted_roles.role_id = role_permissions.role_id
+  JOIN permissions ON role_permissions.permission_id = permissions.id
 ),
 fallback_permissions AS (
   -- Fallback: Get 閲覧者 permissions if user has no valid roles
@@ -188,6 +194,48 @@ FROM (
 ) combined
 ORDER BY priority, resource, action;
 
+-- name: GetUserRoleGrants :many
+WITH RECURSIVE granted_roles AS (
+    SELECT user_roles.role_id AS assigned_role_id, user_roles.role_id
+    FROM user_roles
+    WHERE user_roles.user_id = @user_id AND user_roles.tenant_id = @tenant_id
+        AND (user_roles.expires_at IS NULL OR user_roles.expires_at > CURRENT_TIMESTAMP)
+    UNION
+    SELECT granted_roles.assigned_role_id, role_inclusions.included_role_id
+    FROM granted_roles
+    JOIN role_inclusions ON role_inclusions.role_id = granted_roles.role_id
+)
+SELECT
+    roles.id AS role_id,
+    roles.name AS role_name,
+    roles.is_default,
+    -- Set when the permission comes from a role the assigned role includes.
+    included_roles.name AS inherited_from,
+    permissions.resource,
+    permissions.action
+FROM granted_roles
+JOIN roles ON roles.id = granted_roles.assigned_role_id
+LEFT JOIN roles AS included_roles ON included_roles.id = granted_roles.role_id
+    AND granted_roles.role_id <> granted_roles.assigned_role_id
+LEFT JOIN role_permissions ON role_permissions.role_id = granted_roles.role_id
+LEFT JOIN permissions ON permissions.id = role_permissions.permission_id AND permissions.removed_at IS NULL
+ORDER BY roles.name, included_roles.name NULLS FIRST, permissions.resource, permissions.action;
+
+-- name: GetViewerRoleGrants :many
+SELECT
+    roles.id AS role_id,
+    roles.name AS role_name,
+    roles.is_default,
+    permissions.resource,
+    permissions.action
+FROM roles
+LEFT JOIN role_permissions ON role_permissions.role_id = roles.id
+LEFT JOIN permissions ON permissions.id = role_permissions.permission_id AND permissions.removed_at IS NULL
+WHERE roles.tenant_id = @tenant_id
+    AND roles.name = '閲覧者'
+    AND roles.is_default = true
+ORDER BY permissions.resource, permissions.action;
+
 -- name: GetUserRolesWithDetails :many
 SELECT roles.id, roles.name, roles.description
 FROM user_roles
@@ -198,7 +246,8 @@ WHERE user_roles.user_id = $1 AND user_roles.tenant_id = $2;
 WITH paginated_users AS (
     SELECT users.id
     FROM users
-    WHERE users.tenant_id = @tenant_id
+    JOIN tenant_memberships ON tenant_memberships.user_id = users.id
+    WHERE tenant_memberships.tenant_id = @tenant_id
     AND users.is_internal_user = false
     AND users.deleted_at IS NULL
     AND (
@@ -218,7 +267,8 @@ user_roles_with_rbac AS (
         1 as priority
     FROM users
     JOIN paginated_users pu ON users.id = pu.id
-    JOIN user_roles ON users.id = user_roles.user_id AND users.tenant_id = user_roles.tenant_id
+    JOIN user_roles ON users.id = user_roles.user_id AND user_roles.tenant_id = @tenant_id
+        AND (user_roles.expires_at IS NULL OR user_roles.expires_at > CURRENT_TIMESTAMP)
     JOIN roles ON user_roles.role_id = roles.id
     WHERE (
         @rbac_enabled = true OR  -- RBAC enabled: use all roles
@@ -256,6 +306,344 @@ LEFT JOIN (
 ) combined_roles ON users.id = combined_roles.user_id
 ORDER BY users.created_at DESC, users.id, combined_roles.priority, combined_roles.role_name;
 
+-- name: CountUsersFiltered :one
+SELECT COUNT(*)
+FROM users
+JOIN tenant_memberships ON tenant_memberships.user_id = users.id
+WHERE tenant_memberships.tenant_id = @tenant_id
+AND users.is_internal_user = false
+AND users.deleted_at IS NULL
+AND (
+    @search_term::varchar = '' OR
+    users.name ILIKE '%' || @search_term || '%' OR
+    users.email ILIKE '%' || @search_term || '%'
+)
+AND (@status::varchar = '' OR users.status = @status)
+AND (@role_id::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM user_roles
+    WHERE user_roles.user_id = users.id AND user_roles.role_id = @role_id
+))
+AND (sqlc.narg('sso_linked')::boolean IS NULL OR (users.external_sso_id IS NOT NULL) = sqlc.narg('sso_linked'))
+AND (@created_from::timestamptz IS NULL OR users.created_at >= @created_from)
+AND (@created_to::timestamptz IS NULL OR users.created_at <= @created_to)
+AND (@last_login_from::timestamptz IS NULL OR users.last_login_at >= @last_login_from)
+AND (@last_login_to::timestamptz IS NULL OR users.last_login_at <= @last_login_to);
+
+-- The ListUserIDsBy* queries page through the user list, one query per sort
+-- column and direction so that each has a static ORDER BY and a single
+-- (sort value, id) keyset predicate the sort indexes can serve. They share
+-- CountUsersFiltered's filters, and GetUsersWithRolesByIDs loads the page.
+-- Users who never logged in sort as -infinity so the comparison stays total.
+
+-- name: ListUserIDsByCreatedAtDesc :many
+SELECT users.id
+FROM users
+JOIN tenant_memberships ON tenant_memberships.user_id = users.id
+WHERE tenant_memberships.tenant_id = @tenant_id
+AND users.is_internal_user = false
+AND users.deleted_at IS NULL
+AND (
+    @search_term::varchar = '' OR
+    users.name ILIKE '%' || @search_term || '%' OR
+    users.email ILIKE '%' || @search_term || '%'
+)
+AND (@status::varchar = '' OR users.status = @status)
+AND (@role_id::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM user_roles
+    WHERE user_roles.user_id = users.id AND user_roles.role_id = @role_id
+))
+AND (sqlc.narg('sso_linked')::boolean IS NULL OR (users.external_sso_id IS NOT NULL) = sqlc.narg('sso_linked'))
+AND (@created_from::timestamptz IS NULL OR users.created_at >= @created_from)
+AND (@created_to::timestamptz IS NULL OR users.created_at <= @created_to)
+AND (@last_login_from::timestamptz IS NULL OR users.last_login_at >= @last_login_from)
+AND (@last_login_to::timestamptz IS NULL OR users.last_login_at <= @last_login_to)
+AND (@cursor_id::uuid IS NULL OR (users.created_at, users.id) < (@cursor_time::timestamptz, @cursor_id))
+ORDER BY users.created_at DESC, users.id DESC
+LIMIT @limit_count OFFSET @offset_count;
+
+-- name: ListUserIDsByCreatedAtAsc :many
+SELECT users.id
+FROM users
+JOIN tenant_memberships ON tenant_memberships.user_id = users.id
+WHERE tenant_memberships.tenant_id = @tenant_id
+AND users.is_internal_user = false
+AND users.deleted_at IS NULL
+AND (
+    @search_term::varchar = '' OR
+    users.name ILIKE '%' || @search_term || '%' OR
+    users.email ILIKE '%' || @search_term || '%'
+)
+AND (@status::varchar = '' OR users.status = @status)
+AND (@role_id::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM user_roles
+    WHERE user_roles.user_id = users.id AND user_roles.role_id = @role_id
+))
+AND (sqlc.narg('sso_linked')::boolean IS NULL OR (users.external_sso_id IS NOT NULL) = sqlc.narg('sso_linked'))
+AND (@created_from::timestamptz IS NULL OR users.created_at >= @created_from)
+AND (@created_to::timestamptz IS NULL OR users.created_at <= @created_to)
+AND (@last_login_from::timestamptz IS NULL OR users.last_login_at >= @last_login_from)
+AND (@last_login_to::timestamptz IS NULL OR users.last_login_at <= @last_login_to)
+AND (@cursor_id::uuid IS NULL OR (users.created_at, users.id) > (@cursor_time::timestamptz, @cursor_id))
+ORDER BY users.created_at ASC, users.id ASC
+LIMIT @limit_count OFFSET @offset_count;
+
+-- name: ListUserIDsByLastLoginAtDesc :many
+SELECT users.id
+FROM users
+JOIN tenant_memberships ON tenant_memberships.user_id = users.id
+WHERE tenant_memberships.tenant_id = @tenant_id
+AND users.is_internal_user = false
+AND users.deleted_at IS NULL
+AND (
+    @search_term::varchar = '' OR
+    users.name ILIKE '%' || @search_term || '%' OR
+    users.email ILIKE '%' || @search_term || '%'
+)
+AND (@status::varchar = '' OR users.status = @status)
+AND (@role_id::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM user_roles
+    WHERE user_roles.user_id = users.id AND user_roles.role_id = @role_id
+))
+AND (sqlc.narg('sso_linked')::boolean IS NULL OR (users.external_sso_id IS NOT NULL) = sqlc.narg('sso_linked'))
+AND (@created_from::timestamptz IS NULL OR users.created_at >= @created_from)
+AND (@created_to::timestamptz IS NULL OR users.created_at <= @created_to)
+AND (@last_login_from::timestamptz IS NULL OR users.last_login_at >= @last_login_from)
+AND (@last_login_to::timestamptz IS NULL OR users.last_login_at <= @last_login_to)
+AND (@cursor_id::uuid IS NULL OR (COALESCE(users.last_login_at, '-infinity'::timestamptz), users.id) < (@cursor_time::timestamptz, @cursor_id))
+ORDER BY COALESCE(users.last_login_at, '-infinity'::timestamptz) DESC, users.id DESC
+LIMIT @limit_count OFFSET @offset_count;
+
+-- name: ListUserIDsByLastLoginAtAsc :many
+SELECT users.id
+FROM users
+JOIN tenant_memberships ON tenant_memberships.user_id = users.id
+WHERE tenant_memberships.tenant_id = @tenant_id
+AND users.is_internal_user = false
+AND users.deleted_at IS NULL
+AND (
+    @search_term::varchar = '' OR
+    users.name ILIKE '%' || @search_term || '%' OR
+    users.email ILIKE '%' || @search_term || '%'
+)
+AND (@status::varchar = '' OR users.status = @status)
+AND (@role_id::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM user_roles
+    WHERE user_roles.user_id = users.id AND user_roles.role_id = @role_id
+))
+AND (sqlc.narg('sso_linked')::boolean IS NULL OR (users.external_sso_id IS NOT NULL) = sqlc.narg('sso_linked'))
+AND (@created_from::timestamptz IS NULL OR users.created_at >= @created_from)
+AND (@created_to::timestamptz IS NULL OR users.created_at <= @created_to)
+AND (@last_login_from::timestamptz IS NULL OR users.last_login_at >= @last_login_from)
+AND (@last_login_to::timestamptz IS NULL OR users.last_login_at <= @last_login_to)
+AND (@cursor_id::uuid IS NULL OR (COALESCE(users.last_login_at, '-infinity'::timestamptz), users.id) > (@cursor_time::timestamptz, @cursor_id))
+ORDER BY COALESCE(users.last_login_at, '-infinity'::timestamptz) ASC, users.id ASC
+LIMIT @limit_count OFFSET @offset_count;
+
+-- name: ListUserIDsByNameDesc :many
+SELECT users.id
+FROM users
+JOIN tenant_memberships ON tenant_memberships.user_id = users.id
+WHERE tenant_memberships.tenant_id = @tenant_id
+AND users.is_internal_user = false
+AND users.deleted_at IS NULL
+AND (
+    @search_term::varchar = '' OR
+    users.name ILIKE '%' || @search_term || '%' OR
+    users.email ILIKE '%' || @search_term || '%'
+)
+AND (@status::varchar = '' OR users.status = @status)
+AND (@role_id::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM user_roles
+    WHERE user_roles.user_id = users.id AND user_roles.role_id = @role_id
+))
+AND (sqlc.narg('sso_linked')::boolean IS NULL OR (users.external_sso_id IS NOT NULL) = sqlc.narg('sso_linked'))
+AND (@created_from::timestamptz IS NULL OR users.created_at >= @created_from)
+AND (@created_to::timestamptz IS NULL OR users.created_at <= @created_to)
+AND (@last_login_from::timestamptz IS NULL OR users.last_login_at >= @last_login_from)
+AND (@last_login_to::timestamptz IS NULL OR users.last_login_at <= @last_login_to)
+AND (@cursor_id::uuid IS NULL OR (users.name, users.id) < (@cursor_text::varchar, @cursor_id))
+ORDER BY users.name DESC, users.id DESC
+LIMIT @limit_count OFFSET @offset_count;
+
+-- name: ListUserIDsByNameAsc :many
+SELECT users.id
+FROM users
+JOIN tenant_memberships ON tenant_memberships.user_id = users.id
+WHERE tenant_memberships.tenant_id = @tenant_id
+AND users.is_internal_user = false
+AND users.deleted_at IS NULL
+AND (
+    @search_term::varchar = '' OR
+    users.name ILIKE '%' || @search_term || '%' OR
+    users.email ILIKE '%' || @search_term || '%'
+)
+AND (@status::varchar = '' OR users.status = @status)
+AND (@role_id::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM user_roles
+    WHERE user_roles.user_id = users.id AND user_roles.role_id = @role_id
+))
+AND (sqlc.narg('sso_linked')::boolean IS NULL OR (users.external_sso_id IS NOT NULL) = sqlc.narg('sso_linked'))
+AND (@created_from::timestamptz IS NULL OR users.created_at >= @created_from)
+AND (@created_to::timestamptz IS NULL OR users.created_at <= @created_to)
+AND (@last_login_from::timestamptz IS NULL OR users.last_login_at >= @last_login_from)
+AND (@last_login_to::timestamptz IS NULL OR users.last_login_at <= @last_login_to)
+AND (@cursor_id::uuid IS NULL OR (users.name, users.id) > (@cursor_text::varchar, @cursor_id))
+ORDER BY users.name ASC, users.id ASC
+LIMIT @limit_count OFFSET @offset_count;
+
+-- name: ListUserIDsByEmailDesc :many
+SELECT users.id
+FROM users
+JOIN tenant_memberships ON tenant_memberships.user_id = users.id
+WHERE tenant_memberships.tenant_id = @tenant_id
+AND users.is_internal_user = false
+AND users.deleted_at IS NULL
+AND (
+    @search_term::varchar = '' OR
+    users.name ILIKE '%' || @search_term || '%' OR
+    users.email ILIKE '%' || @search_term || '%'
+)
+AND (@status::varchar = '' OR users.status = @status)
+AND (@role_id::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM user_roles
+    WHERE user_roles.user_id = users.id AND user_roles.role_id = @role_id
+))
+AND (sqlc.narg('sso_linked')::boolean IS NULL OR (users.external_sso_id IS NOT NULL) = sqlc.narg('sso_linked'))
+AND (@created_from::timestamptz IS NULL OR users.created_at >= @created_from)
+AND (@created_to::timestamptz IS NULL OR users.created_at <= @created_to)
+AND (@last_login_from::timestamptz IS NULL OR users.last_login_at >= @last_login_from)
+AND (@last_login_to::timestamptz IS NULL OR users.last_login_at <= @last_login_to)
+AND (@cursor_id::uuid IS NULL OR (users.email, users.id) < (@cursor_text::varchar, @cursor_id))
+ORDER BY users.email DESC, users.id DESC
+LIMIT @limit_count OFFSET @offset_count;
+
+-- name: ListUserIDsByEmailAsc :many
+SELECT users.id
+FROM users
+JOIN tenant_memberships ON tenant_memberships.user_id = users.id
+WHERE tenant_memberships.tenant_id = @tenant_id
+AND users.is_internal_user = false
+AND users.deleted_at IS NULL
+AND (
+    @search_term::varchar = '' OR
+    users.name ILIKE '%' || @search_term || '%' OR
+    users.email ILIKE '%' || @search_term || '%'
+)
+AND (@status::varchar = '' OR users.status = @status)
+AND (@role_id::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM user_roles
+    WHERE user_roles.user_id = users.id AND user_roles.role_id = @role_id
+))
+AND (sqlc.narg('sso_linked')::boolean IS NULL OR (users.external_sso_id IS NOT NULL) = sqlc.narg('sso_linked'))
+AND (@created_from::timestamptz IS NULL OR users.created_at >= @created_from)
+AND (@created_to::timestamptz IS NULL OR users.created_at <= @created_to)
+AND (@last_login_from::timestamptz IS NULL OR users.last_login_at >= @last_login_from)
+AND (@last_login_to::timestamptz IS NULL OR users.last_login_at <= @last_login_to)
+AND (@cursor_id::uuid IS NULL OR (users.email, users.id) > (@cursor_text::varchar, @cursor_id))
+ORDER BY users.email ASC, users.id ASC
+LIMIT @limit_count OFFSET @offset_count;
+
+-- name: GetUsersWithRolesByIDs :many
+WITH paginated_users AS (
+    SELECT page.id, page.position
+    FROM UNNEST(@user_ids::uuid[]) WITH ORDINALITY AS page(id, position)
+),
+user_roles_with_rbac AS (
+    SELECT DISTINCT 
+        users.id as user_id,
+        roles.id as role_id, 
+        roles.name as role_name, 
+        roles.description as role_description,
+        1 as priority
+    FROM users
+    JOIN paginated_users pu ON users.id = pu.id
+    JOIN user_roles ON users.id = user_roles.user_id AND user_roles.tenant_id = @tenant_id
+        AND (user_roles.expires_at IS NULL OR user_roles.expires_at > CURRENT_TIMESTAMP)
+    JOIN roles ON user_roles.role_id = roles.id
+    WHERE (
+        @rbac_enabled = true OR  -- RBAC enabled: use all roles
+        roles.is_default = true  -- RBAC disabled: only default roles
+    )
+),
+fallback_roles AS (
+    SELECT DISTINCT 
+        users.id as user_id,
+        roles.id as role_id, 
+        roles.name as role_name, 
+        roles.description as role_description,
+        2 as priority
+    FROM users
+    JOIN paginated_users pu ON users.id = pu.id
+    JOIN roles ON roles.tenant_id = @tenant_id
+    WHERE roles.name = '閲覧者'
+    AND roles.is_default = true
+    AND NOT EXISTS (
+        SELECT 1 FROM user_roles_with_rbac urwr 
+        WHERE urwr.user_id = users.id
+    )
+)
+SELECT 
+    users.id, users.email, users.name, users.status, users.created_at, users.updated_at, users.last_login_at,
+    combined_roles.role_id, combined_roles.role_name, combined_roles.role_description
+FROM users
+JOIN paginated_users pu ON users.id = pu.id
+LEFT JOIN (
+    SELECT user_id, role_id, role_name, role_description, priority 
+    FROM user_roles_with_rbac
+    UNION ALL
+    SELECT user_id, role_id, role_name, role_description, priority 
+    FROM fallback_roles
+) combined_roles ON users.id = combined_roles.user_id
+ORDER BY pu.position, combined_roles.priority, combined_roles.role_name;
+
 -- name: AssignRoleToUser :exec
-INSERT INTO user_roles (user_id, role_id, tenant_id)
-VALUES ($1, $2, $3);
+INSERT INTO user_roles (user_id, role_id, tenant_id, expires_at, granted_by)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: GrantTemporaryRole :exec
+INSERT INTO user_roles (user_id, role_id, tenant_id, expires_at, granted_by)
+VALUES (@user_id, @role_id, @tenant_id, @expires_at, @granted_by)
+ON CONFLICT (user_id, role_id) DO UPDATE
+SET expires_at = EXCLUDED.expires_at,
+    granted_by = EXCLUDED.granted_by
+-- Never shorten an existing grant or turn a permanent one into a temporary one.
+WHERE user_roles.expires_at IS NOT NULL AND user_roles.expires_at < EXCLUDED.expires_at;
+
+-- name: AddTenantMembership :exec
+INSERT INTO tenant_memberships (tenant_id, user_id)
+VALUES (@tenant_id, @user_id)
+ON CONFLICT DO NOTHING;
+
+-- name: RemoveTenantMembership :exec
+DELETE FROM tenant_memberships
+WHERE tenant_id = @tenant_id AND user_id = @user_id;
+
+-- name: IsTenantMember :one
+SELECT EXISTS (
+    SELECT 1 FROM tenant_memberships
+    WHERE tenant_id = @tenant_id AND user_id = @user_id
+);
+
+-- name: ListTenantMembershipsForUser :many
+SELECT tenants.id, tenants.name, tenants.auth_method, (tenants.id = users.tenant_id)::boolean AS is_home
+FROM tenant_memberships
+JOIN tenants ON tenants.id = tenant_memberships.tenant_id
+JOIN users ON users.id = tenant_memberships.user_id
+WHERE tenant_memberships.user_id = @user_id
+ORDER BY is_home DESC, tenant_memberships.created_at, tenants.id;
+
+-- name: UpdateUserHomeTenant :exec
+UPDATE users
+SET tenant_id = @tenant_id, updated_at = CURRENT_TIMESTAMP
+WHERE id = @user_id;
+
+-- name: RemoveUserRolesInTenant :exec
+DELETE FROM user_roles
+WHERE user_id = @user_id AND tenant_id = @tenant_id;
+
+-- name: RevokeRefreshTokensForTenant :exec
+UPDATE refresh_tokens
+SET revoked_at = CURRENT_TIMESTAMP
+WHERE user_id = @user_id
+AND revoked_at IS NULL
+AND (tenant_id = @tenant_id OR (tenant_id IS NULL AND @is_home_tenant::boolean));
@@ -0,0 +1,228 @@
+package access_requests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"lugia/features/access_requests"
+	"lugia/lib/maintenance"
+	"lugia/queries"
+	"lugia/test/integration/setup"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// doAsUser logs in as userKey, sends the request and decodes a JSON response
+// into out when out is non-nil and the request succeeded.
+func doAsUser(t *testing.T, userKey, method, path string, body any, out any) int {
+	var reqBody *bytes.Buffer
+	if body != nil {
+		b, err := json.Marshal(body)
+		require.NoError(t, err)
+		reqBody = bytes.NewBuffer(b)
+	} else {
+		reqBody = bytes.NewBuffer(nil)
+	}
+
+	req, err := http.NewRequest(method, setup.BaseURL+path, reqBody)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	loginDetails := setup.TestUsersData[userKey]
+	accessToken, _ := setup.LoginUserAndGetTokens(t, loginDetails.Email, loginDetails.PlainTextPassword)
+	req.AddCookie(&http.Cookie{Name: "dislyze_access_token", Value: accessToken})
+
+	resp, err := (&http.Client{}).Do(req)
+	require.NoError(t, err)
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			t.Logf("Error closing response body: %v", err)
+		}
+	}()
+
+	if out != nil && resp.StatusCode == http.StatusOK {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(out))
+	}
+	return resp.StatusCode
+}
+
+func requestAccess(t *testing.T, userKey, roleID string, durationHours int32) int {
+	return doAsUser(t, userKey, "POST", "/me/access-requests", access_requests.CreateAccessRequestRequestBody{
+		RoleID:        roleID,
+		DurationHours: durationHours,
+		Justification: "月末の棚卸し対応のため",
+	}, nil)
+}
+
+func pendingRequestID(t *testing.T, requesterKey string) string {
+	var body access_requests.GetAccessRequestsResponse
+	status := doAsUser(t, "enterprise_1", "GET", "/access-requests?status=pending", nil, &body)
+	require.Equal(t, http.StatusOK, status)
+	for _, r := range body.Requests {
+		if r.RequesterID == setup.TestUsersData[requesterKey].UserID {
+			return r.ID
+		}
+	}
+	t.Fatalf("no pending request from %s", requesterKey)
+	return ""
+}
+
+func TestAccessRequests_Integration(t *testing.T) {
+	pool := setup.InitDB(t)
+	setup.ResetAndSeedDB(t, pool)
+	defer setup.CloseDB(pool)
+
+	ctx := context.Background()
+	managerRoleID := setup.TestRolesData["enterprise_user_manager"].ID
+	approver := setup.TestUsersData["enterprise_1"]
+
+	t.Run("requestable roles exclude permanently held ones", func(t *testing.T) {
+		var body access_requests.GetMyAccessRequestsResponse
+		status := doAsUser(t, "enterprise_8", "GET", "/me/access-requests", nil, &body)
+		require.Equal(t, http.StatusOK, status)
+
+		ids := make([]string, 0, len(body.RequestableRoles))
+		for _, r := range body.RequestableRoles {
+			ids = append(ids, r.ID)
+		}
+		assert.Contains(t, ids, managerRoleID)
+		assert.NotContains(t, ids, setup.TestRolesData["enterprise_viewer"].ID)
+		assert.Empty(t, body.Requests)
+	})
+
+	t.Run("duration outside the allowed range is rejected", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnprocessableEntity, requestAccess(t, "enterprise_8", managerRoleID, 0))
+		assert.Equal(t, http.StatusUnprocessableEntity, requestAccess(t, "enterprise_8", managerRoleID, 24*31))
+	})
+
+	t.Run("request notifies approvers and is audited", func(t *testing.T) {
+		require.Equal(t, http.StatusNoContent, requestAccess(t, "enterprise_8", managerRoleID, 4))
+
+		email, err := setup.GetLatestEmailFromSendgridMock(t, approver.Email)
+		require.NoError(t, err)
+		assert.Contains(t, email.Personalizations[0].Subject, "アクセス申請")
+
+		var count int
+		err = pool.QueryRow(ctx, `SELECT COUNT(*) FROM audit_logs WHERE resource_type = 'access_request' AND action = 'requested' AND actor_id = $1`,
+			setup.TestUsersData["enterprise_8"].UserID).Scan(&count)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("a second pending request for the same role conflicts", func(t *testing.T) {
+		assert.Equal(t, http.StatusConflict, requestAccess(t, "enterprise_8", managerRoleID, 4))
+	})
+
+	t.Run("request is rejected when nobody else could approve it", func(t *testing.T) {
+		assert.Equal(t, http.StatusConflict, requestAccess(t, "enterprise_1", managerRoleID, 4))
+	})
+
+	t.Run("listing and deciding requires roles edit", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, doAsUser(t, "enterprise_8", "GET", "/access-requests", nil, nil))
+	})
+
+	t.Run("approval grants the role until the duration runs out", func(t *testing.T) {
+		requestID := pendingRequestID(t, "enterprise_8")
+		approvedAt := time.Now()
+
+		status := doAsUser(t, "enterprise_1", "POST", "/access-requests/"+requestID+"/approve", nil, nil)
+		require.Equal(t, http.StatusNoContent, status)
+
+		var expiresAt time.Time
+		var grantedBy string
+		err := pool.QueryRow(ctx, `SELECT expires_at, granted_by::text FROM user_roles WHERE user_id = $1 AND role_id = $2`,
+			setup.TestUsersData["enterprise_8"].UserID, managerRoleID).Scan(&expiresAt, &grantedBy)
+		require.NoError(t, err)
+		assert.WithinDuration(t, approvedAt.Add(4*time.Hour), expiresAt, time.Minute)
+		assert.Equal(t, approver.UserID, grantedBy)
+
+		assert.Equal(t, http.StatusOK, doAsUser(t, "enterprise_8", "GET", "/users", nil, nil))
+
+		_, err = setup.GetLatestEmailFromSendgridMock(t, setup.TestUsersData["enterprise_8"].Email)
+		require.NoError(t, err)
+
+		var action string
+		err = pool.QueryRow(ctx, `SELECT action FROM audit_logs WHERE resource_type = 'access_request' AND resource_id = $1 ORDER BY created_at DESC LIMIT 1`,
+			requestID).Scan(&action)
+		require.NoError(t, err)
+		assert.Equal(t, "approved", action)
+
+		status = doAsUser(t, "enterprise_1", "POST", "/access-requests/"+requestID+"/deny", access_requests.DenyAccessRequestRequestBody{}, nil)
+		assert.Equal(t, http.StatusConflict, status, "A decided request can't be decided again")
+	})
+
+	t.Run("denial records the note and grants nothing", func(t *testing.T) {
+		require.Equal(t, http.StatusNoContent, requestAccess(t, "enterprise_9", managerRoleID, 8))
+		requestID := pendingRequestID(t, "enterprise_9")
+
+		status := doAsUser(t, "enterprise_1", "POST", "/access-requests/"+requestID+"/deny", access_requests.DenyAccessRequestRequestBody{
+			Note: "今回は不要です",
+		}, nil)
+		require.Equal(t, http.StatusNoContent, status)
+
+		var requestStatus, note string
+		err := pool.QueryRow(ctx, `SELECT status, decision_note FROM access_requests WHERE id = $1`, requestID).Scan(&requestStatus, &note)
+		require.NoError(t, err)
+		assert.Equal(t, "denied", requestStatus)
+		assert.Equal(t, "今回は不要です", note)
+
+		var count int
+		err = pool.QueryRow(ctx, `SELECT COUNT(*) FROM user_roles WHERE user_id = $1 AND role_id = $2`,
+			setup.TestUsersData["enterprise_9"].UserID, managerRoleID).Scan(&count)
+		require.NoError(t, err)
+		assert.Equal(t, 0, count)
+	})
+
+	t.Run("approvers can't decide their own requests", func(t *testing.T) {
+		var requestID string
+		err := pool.QueryRow(ctx, `
+			INSERT INTO access_requests (tenant_id, requester_id, role_id, justification, duration_hours, expires_at)
+			VALUES ($1, $2, $3, '自己承認テスト', 1, CURRENT_TIMESTAMP + INTERVAL '1 day') RETURNING id::text`,
+			approver.TenantID, approver.UserID, managerRoleID).Scan(&requestID)
+		require.NoError(t, err)
+
+		status := doAsUser(t, "enterprise_1", "POST", "/access-requests/"+requestID+"/approve", nil, nil)
+		assert.Equal(t, http.StatusForbidden, status)
+	})
+
+	t.Run("undecided requests expire and are audited", func(t *testing.T) {
+		require.Equal(t, http.StatusNoContent, requestAccess(t, "enterprise_10", managerRoleID, 2))
+		requestID := pendingRequestID(t, "enterprise_10")
+
+		_, err := pool.Exec(ctx, `UPDATE access_requests SET expires_at = CURRENT_TIMESTAMP - INTERVAL '1 minute' WHERE id = $1`, requestID)
+		require.NoError(t, err)
+
+		status := doAsUser(t, "enterprise_1", "POST", "/access-requests/"+requestID+"/approve", nil, nil)
+		assert.Equal(t, http.StatusConflict, status, "A request past its deadline can't be approved before the sweep")
+
+		maintenance.NewRunner(pool, queries.New(pool), &maintenance.Config{
+			Interval:  time.Hour,
+			BatchSize: 100,
+			Retentions: maintenance.Retentions{
+				DeletedUsers:        1000 * time.Hour,
+				PasswordResetTokens: 1000 * time.Hour,
+				EmailChangeTokens:   1000 * time.Hour,
+				InvitationTokens:    1000 * time.Hour,
+				RefreshTokens:       1000 * time.Hour,
+				SSOAuthRequests:     1000 * time.Hour,
+			},
+		}).RunOnce(ctx)
+
+		var requestStatus string
+		err = pool.QueryRow(ctx, `SELECT status FROM access_requests WHERE id = $1`, requestID).Scan(&requestStatus)
+		require.NoError(t, err)
+		assert.Equal(t, "expired", requestStatus)
+
+		var hasActor bool
+		var targetEmail string
+		err = pool.QueryRow(ctx, `SELECT actor_id IS NOT NULL, metadata->>'target_user_email' FROM audit_logs WHERE resource_type = 'access_request' AND action = 'expired' AND resource_id = $1`,
+			requestID).Scan(&hasActor, &targetEmail)
+		require.NoError(t, err)
+		assert.False(t, hasActor, "Nobody expired the request, so the entry has no actor")
+		assert.Equal(t, setup.TestUsersData["enterprise_10"].Email, targetEmail)
+	})
+}
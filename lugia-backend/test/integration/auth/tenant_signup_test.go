@@ -2,6 +2,7 @@ package auth
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"lugia/test/integration/setup"
@@ -15,10 +16,11 @@ import (
 )
 
 type TenantSignupRequestBody struct {
-	Password        string `json:"password"`
-	PasswordConfirm string `json:"password_confirm"`
-	CompanyName     string `json:"company_name"`
-	UserName        string `json:"user_name"`
+	Password        string   `json:"password"`
+	PasswordConfirm string   `json:"password_confirm"`
+	CompanyName     string   `json:"company_name"`
+	UserName        string   `json:"user_name"`
+	RoleTemplates   []string `json:"role_templates,omitempty"`
 }
 
 type CreateTenantTokenClaims struct {
@@ -410,6 +412,17 @@ func TestTenantSignupRequestBodyValidation(t *testing.T) {
 			},
 			expectedStatus: http.StatusBadRequest,
 		},
+		{
+			name: "unknown role template",
+			requestBody: TenantSignupRequestBody{
+				Password:        "password123",
+				PasswordConfirm: "password123",
+				CompanyName:     "Test Company",
+				UserName:        "Test User",
+				RoleTemplates:   []string{"no_such_template"},
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
 	}
 
 	for _, tt := range tests {
@@ -436,6 +449,57 @@ func TestTenantSignupRequestBodyValidation(t *testing.T) {
 	}
 }
 
+func TestTenantSignupWithRoleTemplates(t *testing.T) {
+	pool := setup.InitDB(t)
+	setup.ResetAndSeedDB(t, pool)
+	defer setup.CloseDB(pool)
+
+	email := "templates@example.com"
+	requestBody := TenantSignupRequestBody{
+		Password:        "password123",
+		PasswordConfirm: "password123",
+		CompanyName:     "Template Company",
+		UserName:        "Template User",
+		RoleTemplates:   []string{"readonly_auditor", "helpdesk"},
+	}
+
+	resp := makeTenantSignupRequest(t, generateValidJWTToken(t, email), requestBody)
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			t.Logf("Error closing response body: %v", err)
+		}
+	}()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	rows, err := pool.Query(context.Background(), `
+		SELECT r.name, r.is_default, COUNT(rp.permission_id)
+		FROM roles r
+		JOIN users u ON u.tenant_id = r.tenant_id
+		LEFT JOIN role_permissions rp ON rp.role_id = r.id
+		WHERE u.email = $1
+		GROUP BY r.id, r.name, r.is_default`, email)
+	assert.NoError(t, err)
+	defer rows.Close()
+
+	type roleSummary struct {
+		isDefault   bool
+		permissions int
+	}
+	roles := map[string]roleSummary{}
+	for rows.Next() {
+		var name string
+		var summary roleSummary
+		assert.NoError(t, rows.Scan(&name, &summary.isDefault, &summary.permissions))
+		roles[name] = summary
+	}
+	assert.NoError(t, rows.Err())
+
+	assert.Len(t, roles, 5)
+	assert.Equal(t, roleSummary{isDefault: false, permissions: 5}, roles["読み取り専用監査担当"])
+	assert.Equal(t, roleSummary{isDefault: false, permissions: 3}, roles["ヘルプデスク"])
+	assert.True(t, roles["管理者"].isDefault)
+}
+
 func TestTenantSignupInvalidJSON(t *testing.T) {
 	pool := setup.InitDB(t)
 	setup.ResetAndSeedDB(t, pool)
@@ -0,0 +1,108 @@
+package ip_whitelist
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"lugia/features/ip_whitelist"
+	"lugia/test/integration/setup"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIPWhitelistAnalysisIntegration(t *testing.T) {
+	pool := setup.InitDB(t)
+	setup.ResetAndSeedDB(t, pool)
+	defer setup.CloseDB(pool)
+
+	const clientIP = "203.0.113.30"
+
+	tenantID := setup.TestTenantsData["enterprise"].ID
+	createdBy := setup.TestUsersData["enterprise_1"].UserID
+	updateTenantEnterpriseFeatures(t, pool, tenantID, map[string]interface{}{
+		"ip_whitelist": map[string]interface{}{
+			"enabled":                     true,
+			"active":                      false,
+			"allow_internal_admin_bypass": false,
+		},
+		"audit_log": map[string]interface{}{
+			"enabled": true,
+		},
+	})
+
+	ruleID := func(t *testing.T, cidr string) string {
+		var id string
+		err := pool.QueryRow(context.Background(),
+			"SELECT id::text FROM tenant_ip_whitelist WHERE tenant_id = $1 AND ip_address = $2::cidr",
+			tenantID, cidr).Scan(&id)
+		require.NoError(t, err)
+		return id
+	}
+
+	t.Run("a broad tenant rule is refused until confirmed", func(t *testing.T) {
+		body := ip_whitelist.AddIPToWhitelistRequest{IPAddress: "0.0.0.0/0"}
+		resp := postJSONFromIP(t, "/ip-whitelist/create", "enterprise_1", clientIP, body)
+		require.Equal(t, http.StatusConflict, resp.StatusCode)
+
+		var errorResp map[string]string
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&errorResp))
+		assert.Equal(t, "confirm_broad required", errorResp["error"])
+		assert.Equal(t, 0, countIPWhitelistRules(t, pool, tenantID))
+
+		body.ConfirmBroad = true
+		resp = postJSONFromIP(t, "/ip-whitelist/create", "enterprise_1", clientIP, body)
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+		assert.Equal(t, 1, countIPWhitelistRules(t, pool, tenantID))
+
+		var broad string
+		err := pool.QueryRow(context.Background(),
+			"SELECT metadata->>'broad' FROM audit_logs WHERE tenant_id = $1 AND action = 'ip_added'",
+			tenantID).Scan(&broad)
+		require.NoError(t, err)
+		assert.Equal(t, "true", broad)
+	})
+
+	t.Run("a narrow rule needs no confirmation", func(t *testing.T) {
+		resp := postJSONFromIP(t, "/ip-whitelist/create", "enterprise_1", clientIP,
+			ip_whitelist.AddIPToWhitelistRequest{IPAddress: "2001:db8::/48"})
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	})
+
+	t.Run("the analysis reports broad, redundant and private rules", func(t *testing.T) {
+		insertIPWhitelistRule(t, pool, tenantID, "2001:db8:0:1::/64", "Branch", createdBy)
+		insertIPWhitelistRule(t, pool, tenantID, "192.168.1.0/24", "Office LAN", createdBy)
+
+		resp := requestFromIP(t, http.MethodGet, "/ip-whitelist/analysis", "enterprise_1", clientIP)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var analysis ip_whitelist.GetIPWhitelistAnalysisResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&analysis))
+
+		found := make(map[string]ip_whitelist.IPWhitelistFinding)
+		for _, finding := range analysis.Findings {
+			found[finding.Kind+" "+finding.IPAddress] = finding
+		}
+		assert.Len(t, found, 4)
+
+		assert.Contains(t, found, "broad 0.0.0.0/0")
+		assert.Contains(t, found, "private 192.168.1.0/24")
+
+		branch, ok := found["redundant 2001:db8:0:1::/64"]
+		require.True(t, ok)
+		require.NotNil(t, branch.RelatedIPAddress)
+		assert.Equal(t, "2001:db8::/48", *branch.RelatedIPAddress)
+
+		office, ok := found["redundant 192.168.1.0/24"]
+		require.True(t, ok)
+		require.NotNil(t, office.RelatedRuleID)
+		assert.Equal(t, ruleID(t, "0.0.0.0/0"), *office.RelatedRuleID)
+	})
+
+	t.Run("viewing the analysis needs ip_whitelist view", func(t *testing.T) {
+		resp := requestFromIP(t, http.MethodGet, "/ip-whitelist/analysis", "enterprise_2", clientIP)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+}
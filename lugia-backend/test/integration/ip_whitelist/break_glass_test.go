@@ -0,0 +1,372 @@
+package ip_whitelist
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"lugia/features/ip_whitelist"
+	"lugia/lib/maintenance"
+	"lugia/queries"
+	"lugia/test/integration/setup"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// postPublicJSON posts without a session, as the break-glass endpoints are
+// used by someone who can't sign in.
+func postPublicJSON(t *testing.T, path, clientIP string, body any) *http.Response {
+	payload, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, setup.BaseURL+path, bytes.NewReader(payload))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Real-IP", clientIP)
+
+	resp, err := (&http.Client{}).Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if err := resp.Body.Close(); err != nil {
+			t.Logf("Error closing response body: %v", err)
+		}
+	})
+	return resp
+}
+
+func countBreakGlassAuditLogs(t *testing.T, pool *pgxpool.Pool, tenantID, action string) int {
+	var count int
+	err := pool.QueryRow(context.Background(),
+		"SELECT COUNT(*) FROM audit_logs WHERE tenant_id = $1 AND action = $2",
+		tenantID, action).Scan(&count)
+	require.NoError(t, err)
+	return count
+}
+
+func activateWhitelist(t *testing.T, pool *pgxpool.Pool, tenantID string) {
+	updateTenantEnterpriseFeatures(t, pool, tenantID, map[string]interface{}{
+		"ip_whitelist": map[string]interface{}{
+			"enabled":                     true,
+			"active":                      true,
+			"allow_internal_admin_bypass": false,
+		},
+		"audit_log": map[string]interface{}{
+			"enabled": true,
+		},
+	})
+}
+
+func TestIPWhitelistRecoveryCodesIntegration(t *testing.T) {
+	pool := setup.InitDB(t)
+	setup.ResetAndSeedDB(t, pool)
+	defer setup.CloseDB(pool)
+
+	const (
+		office  = "192.168.1.100"
+		outside = "203.0.113.21"
+	)
+
+	tenantID := setup.TestTenantsData["enterprise"].ID
+	adminEmail, _ := findUserCredentials("enterprise_1")
+	editorEmail, _ := findUserCredentials("enterprise_2")
+	insertIPWhitelistRule(t, pool, tenantID, "192.168.1.0/24", "Office", setup.TestUsersData["enterprise_1"].UserID)
+	activateWhitelist(t, pool, tenantID)
+
+	var codes []string
+
+	t.Run("a user without emergency can't issue codes", func(t *testing.T) {
+		resp := requestFromIP(t, http.MethodPost, "/ip-whitelist/recovery-codes", "enterprise_2", office)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("issuing returns ten codes once", func(t *testing.T) {
+		resp := requestFromIP(t, http.MethodPost, "/ip-whitelist/recovery-codes", "enterprise_1", office)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var body ip_whitelist.IssueRecoveryCodesResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		require.Len(t, body.Codes, 10)
+		codes = body.Codes
+
+		statusResp := requestFromIP(t, http.MethodGet, "/ip-whitelist/recovery-codes", "enterprise_1", office)
+		require.Equal(t, http.StatusOK, statusResp.StatusCode)
+		var status ip_whitelist.GetRecoveryCodesResponse
+		require.NoError(t, json.NewDecoder(statusResp.Body).Decode(&status))
+		assert.Equal(t, int64(10), status.Remaining)
+		assert.NotNil(t, status.IssuedAt)
+
+		assert.Equal(t, 1, countBreakGlassAuditLogs(t, pool, tenantID, "ip_recovery_codes_issued"))
+	})
+
+	t.Run("a wrong code, unknown email or missing permission all get the same answer", func(t *testing.T) {
+		cases := []ip_whitelist.RedeemRecoveryCodeRequestBody{
+			{Email: adminEmail, Code: "AAAA-AAAA-AAAA-AAAA"},
+			{Email: "nobody@enterprise.test", Code: codes[0]},
+			{Email: editorEmail, Code: codes[0]},
+		}
+		for _, c := range cases {
+			resp := postPublicJSON(t, "/ip-whitelist/recovery-codes/redeem", outside, c)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		}
+		assert.True(t, getIPWhitelistFeatures(t, pool)["active"].(bool))
+	})
+
+	t.Run("a valid code deactivates the whitelist from anywhere", func(t *testing.T) {
+		// Codes are accepted in lower case and without hyphens.
+		resp := postPublicJSON(t, "/ip-whitelist/recovery-codes/redeem", outside, ip_whitelist.RedeemRecoveryCodeRequestBody{
+			Email: adminEmail,
+			Code:  strings.ToLower(strings.ReplaceAll(codes[0], "-", "")),
+		})
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		features := getIPWhitelistFeatures(t, pool)
+		assert.False(t, features["active"].(bool))
+		assert.False(t, features["monitor"].(bool))
+
+		var remaining string
+		err := pool.QueryRow(context.Background(),
+			"SELECT metadata->>'remaining_codes' FROM audit_logs WHERE tenant_id = $1 AND action = 'ip_recovery_code_used'",
+			tenantID).Scan(&remaining)
+		require.NoError(t, err)
+		assert.Equal(t, "9", remaining)
+	})
+
+	t.Run("a used code is rejected", func(t *testing.T) {
+		activateWhitelist(t, pool, tenantID)
+		resp := postPublicJSON(t, "/ip-whitelist/recovery-codes/redeem", outside, ip_whitelist.RedeemRecoveryCodeRequestBody{
+			Email: adminEmail,
+			Code:  codes[0],
+		})
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("a code is kept while the whitelist is not active", func(t *testing.T) {
+		updateTenantEnterpriseFeatures(t, pool, tenantID, map[string]interface{}{
+			"ip_whitelist": map[string]interface{}{
+				"enabled": true,
+				"active":  false,
+			},
+		})
+		resp := postPublicJSON(t, "/ip-whitelist/recovery-codes/redeem", outside, ip_whitelist.RedeemRecoveryCodeRequestBody{
+			Email: adminEmail,
+			Code:  codes[1],
+		})
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+		var unused int
+		err := pool.QueryRow(context.Background(),
+			"SELECT COUNT(*) FROM ip_whitelist_recovery_codes WHERE tenant_id = $1 AND used_at IS NULL",
+			tenantID).Scan(&unused)
+		require.NoError(t, err)
+		assert.Equal(t, 9, unused)
+	})
+
+	t.Run("a code is kept while the whitelist is disabled", func(t *testing.T) {
+		updateTenantEnterpriseFeatures(t, pool, tenantID, map[string]interface{}{
+			"ip_whitelist": map[string]interface{}{
+				"enabled": false,
+				"active":  true,
+			},
+		})
+		resp := postPublicJSON(t, "/ip-whitelist/recovery-codes/redeem", outside, ip_whitelist.RedeemRecoveryCodeRequestBody{
+			Email: adminEmail,
+			Code:  codes[1],
+		})
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+		var unused int
+		err := pool.QueryRow(context.Background(),
+			"SELECT COUNT(*) FROM ip_whitelist_recovery_codes WHERE tenant_id = $1 AND used_at IS NULL",
+			tenantID).Scan(&unused)
+		require.NoError(t, err)
+		assert.Equal(t, 9, unused)
+	})
+
+	t.Run("issuing again invalidates the earlier codes", func(t *testing.T) {
+		activateWhitelist(t, pool, tenantID)
+		resp := requestFromIP(t, http.MethodPost, "/ip-whitelist/recovery-codes", "enterprise_1", office)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp = postPublicJSON(t, "/ip-whitelist/recovery-codes/redeem", outside, ip_whitelist.RedeemRecoveryCodeRequestBody{
+			Email: adminEmail,
+			Code:  codes[1],
+		})
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestIPWhitelistBreakGlassApprovalIntegration(t *testing.T) {
+	pool := setup.InitDB(t)
+	setup.ResetAndSeedDB(t, pool)
+	defer setup.CloseDB(pool)
+
+	const outside = "203.0.113.22"
+
+	tenantID := setup.TestTenantsData["enterprise"].ID
+	adminEmail, _ := findUserCredentials("enterprise_1")
+	insertIPWhitelistRule(t, pool, tenantID, "192.168.1.0/24", "Office", setup.TestUsersData["enterprise_1"].UserID)
+	activateWhitelist(t, pool, tenantID)
+
+	countRequests := func(t *testing.T) int {
+		var count int
+		err := pool.QueryRow(context.Background(),
+			"SELECT COUNT(*) FROM ip_whitelist_break_glass_requests WHERE tenant_id = $1", tenantID).Scan(&count)
+		require.NoError(t, err)
+		return count
+	}
+
+	// Approval links are only emailed and stored hashed, so the test swaps
+	// each holder's hash for one of a token it knows.
+	tokenFor := func(t *testing.T, userKey string) string {
+		token := "test-approval-token-" + userKey
+		_, err := pool.Exec(context.Background(),
+			"UPDATE ip_whitelist_break_glass_approvals SET token_hash = $1 WHERE user_id = $2",
+			fmt.Sprintf("%x", sha256.Sum256([]byte(token))), setup.TestUsersData[userKey].UserID)
+		require.NoError(t, err)
+		return token
+	}
+
+	approve := func(t *testing.T, token string) (*http.Response, ip_whitelist.ApproveBreakGlassResponse) {
+		resp := postPublicJSON(t, "/ip-whitelist/break-glass/approve?token="+url.QueryEscape(token), outside, struct{}{})
+		var body ip_whitelist.ApproveBreakGlassResponse
+		if resp.StatusCode == http.StatusOK {
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		}
+		return resp, body
+	}
+
+	t.Run("a tenant with one emergency holder gets no request", func(t *testing.T) {
+		resp := postPublicJSON(t, "/ip-whitelist/break-glass", outside, ip_whitelist.RequestBreakGlassRequestBody{Email: adminEmail})
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+		assert.Equal(t, 0, countRequests(t))
+	})
+
+	assignRoleToUser(t, pool, setup.TestUsersData["enterprise_2"].UserID, "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa", tenantID)
+
+	t.Run("an unknown email gets the same answer", func(t *testing.T) {
+		resp := postPublicJSON(t, "/ip-whitelist/break-glass", outside, ip_whitelist.RequestBreakGlassRequestBody{Email: "nobody@enterprise.test"})
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+		assert.Equal(t, 0, countRequests(t))
+	})
+
+	t.Run("a holder opens a request once", func(t *testing.T) {
+		resp := postPublicJSON(t, "/ip-whitelist/break-glass", outside, ip_whitelist.RequestBreakGlassRequestBody{Email: adminEmail})
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+		assert.Equal(t, 1, countRequests(t))
+		assert.Equal(t, 1, countBreakGlassAuditLogs(t, pool, tenantID, "ip_break_glass_requested"))
+
+		resp = postPublicJSON(t, "/ip-whitelist/break-glass", outside, ip_whitelist.RequestBreakGlassRequestBody{Email: adminEmail})
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+		assert.Equal(t, 1, countRequests(t))
+	})
+
+	var firstToken, secondToken string
+
+	t.Run("an unknown token is rejected", func(t *testing.T) {
+		firstToken = tokenFor(t, "enterprise_1")
+		secondToken = tokenFor(t, "enterprise_2")
+
+		resp, _ := approve(t, "not-a-token")
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("one approval leaves the whitelist active", func(t *testing.T) {
+		resp, body := approve(t, firstToken)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, 1, body.Approvals)
+		assert.Equal(t, 2, body.Required)
+		assert.False(t, body.Deactivated)
+		assert.True(t, getIPWhitelistFeatures(t, pool)["active"].(bool))
+
+		email, err := setup.GetLatestEmailFromSendgridMock(t, setup.TestUsersData["enterprise_2"].Email)
+		require.NoError(t, err)
+		require.NotNil(t, email)
+		assert.Contains(t, email.Personalizations[0].Subject, "緊急解除が承認されました（1/2）", "Editors hear about each approval")
+	})
+
+	t.Run("the same holder can't approve twice", func(t *testing.T) {
+		resp, _ := approve(t, firstToken)
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	})
+
+	t.Run("a second holder's approval deactivates the whitelist", func(t *testing.T) {
+		resp, body := approve(t, secondToken)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, 2, body.Approvals)
+		assert.True(t, body.Deactivated)
+		assert.False(t, getIPWhitelistFeatures(t, pool)["active"].(bool))
+
+		assert.Equal(t, 2, countBreakGlassAuditLogs(t, pool, tenantID, "ip_break_glass_approved"))
+		assert.Equal(t, 1, countBreakGlassAuditLogs(t, pool, tenantID, "ip_break_glass_deactivated"))
+	})
+
+	t.Run("a completed request can't be approved again", func(t *testing.T) {
+		resp, _ := approve(t, secondToken)
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	})
+
+	t.Run("an expired request is purged with its approvals", func(t *testing.T) {
+		ctx := context.Background()
+		_, err := pool.Exec(ctx,
+			"UPDATE ip_whitelist_break_glass_requests SET expires_at = CURRENT_TIMESTAMP - INTERVAL '1 minute' WHERE tenant_id = $1", tenantID)
+		require.NoError(t, err)
+
+		maintenance.NewRunner(pool, queries.New(pool), &maintenance.Config{
+			Interval:  time.Hour,
+			BatchSize: 100,
+			Retentions: maintenance.Retentions{
+				DeletedUsers:         1000 * time.Hour,
+				PasswordResetTokens:  1000 * time.Hour,
+				EmailChangeTokens:    1000 * time.Hour,
+				InvitationTokens:     1000 * time.Hour,
+				RefreshTokens:        1000 * time.Hour,
+				SSOAuthRequests:      1000 * time.Hour,
+				IPMonitorHits:        1000 * time.Hour,
+				IPBreakGlassRequests: 0,
+			},
+		}).RunOnce(ctx)
+
+		assert.Equal(t, 0, countRequests(t))
+		var approvals int
+		err = pool.QueryRow(ctx, "SELECT COUNT(*) FROM ip_whitelist_break_glass_approvals").Scan(&approvals)
+		require.NoError(t, err)
+		assert.Equal(t, 0, approvals)
+	})
+}
+
+// The per-address limit is keyed on a header the caller controls here, so
+// guesses against one email have to be capped on their own.
+func TestIPWhitelistRecoveryCodeRateLimitIntegration(t *testing.T) {
+	pool := setup.InitDB(t)
+	setup.ResetAndSeedDB(t, pool)
+	defer setup.CloseDB(pool)
+
+	body := ip_whitelist.RedeemRecoveryCodeRequestBody{Email: "rate-limited@enterprise.test", Code: "AAAA-AAAA-AAAA-AAAA"}
+	for i := 0; i < 10; i++ {
+		resp := postPublicJSON(t, "/ip-whitelist/recovery-codes/redeem", fmt.Sprintf("203.0.113.%d", 100+i), body)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode, "attempt %d", i+1)
+	}
+
+	resp := postPublicJSON(t, "/ip-whitelist/recovery-codes/redeem", "203.0.113.120", body)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+
+	// Email case doesn't start a fresh count.
+	body.Email = strings.ToUpper(body.Email)
+	resp = postPublicJSON(t, "/ip-whitelist/recovery-codes/redeem", "203.0.113.121", body)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+
+	resp = postPublicJSON(t, "/ip-whitelist/recovery-codes/redeem", "203.0.113.122", ip_whitelist.RedeemRecoveryCodeRequestBody{
+		Email: "someone-else@enterprise.test",
+		Code:  body.Code,
+	})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
@@ -0,0 +1,124 @@
+package ip_whitelist
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"lugia/features/ip_whitelist"
+	"lugia/test/integration/setup"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The integration environment has no GeoIP database configured, so these
+// cover how country rules behave without one.
+func TestIPWhitelistCountriesIntegration(t *testing.T) {
+	pool := setup.InitDB(t)
+	setup.ResetAndSeedDB(t, pool)
+	defer setup.CloseDB(pool)
+
+	tenantID := setup.TestTenantsData["enterprise"].ID
+	updateTenantEnterpriseFeatures(t, pool, tenantID, map[string]interface{}{
+		"ip_whitelist": map[string]interface{}{
+			"enabled":                     true,
+			"active":                      true,
+			"allow_internal_admin_bypass": false,
+		},
+	})
+	insertIPWhitelistRule(t, pool, tenantID, "192.168.1.0/24", "Office", setup.TestUsersData["enterprise_1"].UserID)
+
+	const office = "192.168.1.100"
+
+	t.Run("lists no countries and reports the database missing", func(t *testing.T) {
+		resp := requestFromIP(t, http.MethodGet, "/ip-whitelist/countries", "enterprise_1", office)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var body ip_whitelist.GetIPWhitelistCountriesResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		assert.Empty(t, body.Countries)
+		assert.False(t, body.DatabaseLoaded)
+		assert.Empty(t, body.CurrentCountry)
+	})
+
+	t.Run("rejects an invalid country code", func(t *testing.T) {
+		resp := postJSONFromIP(t, "/ip-whitelist/countries/create", "enterprise_1", office, ip_whitelist.AddIPWhitelistCountryRequest{
+			CountryCode: "JPN",
+			Action:      "allow",
+		})
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+	})
+
+	t.Run("rejects an unknown action", func(t *testing.T) {
+		resp := postJSONFromIP(t, "/ip-whitelist/countries/create", "enterprise_1", office, ip_whitelist.AddIPWhitelistCountryRequest{
+			CountryCode: "JP",
+			Action:      "block",
+		})
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+	})
+
+	t.Run("refuses new rules without a database", func(t *testing.T) {
+		resp := postJSONFromIP(t, "/ip-whitelist/countries/create", "enterprise_1", office, ip_whitelist.AddIPWhitelistCountryRequest{
+			CountryCode: "JP",
+			Action:      "allow",
+		})
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("existing rules match no address without a database", func(t *testing.T) {
+		_, err := pool.Exec(context.Background(), `
+			INSERT INTO tenant_ip_whitelist_countries (tenant_id, country_code, action, created_by)
+			VALUES ($1, 'JP', 'deny', $2), ($1, 'US', 'allow', $2)`,
+			tenantID, setup.TestUsersData["enterprise_1"].UserID)
+		require.NoError(t, err)
+
+		resp := requestFromIP(t, http.MethodGet, "/me", "enterprise_2", office)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		resp = requestFromIP(t, http.MethodGet, "/me", "enterprise_2", "8.8.8.8")
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("deletes a country rule", func(t *testing.T) {
+		resp := requestFromIP(t, http.MethodGet, "/ip-whitelist/countries", "enterprise_1", office)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var body ip_whitelist.GetIPWhitelistCountriesResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		require.Len(t, body.Countries, 2)
+
+		resp = postJSONFromIP(t, "/ip-whitelist/countries/"+body.Countries[0].ID+"/delete", "enterprise_1", office, struct{}{})
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		var remaining int
+		err := pool.QueryRow(context.Background(),
+			"SELECT COUNT(*) FROM tenant_ip_whitelist_countries WHERE tenant_id = $1", tenantID).Scan(&remaining)
+		require.NoError(t, err)
+		assert.Equal(t, 1, remaining)
+	})
+}
+
+func TestIPWhitelistCountryCreatorPurgeIntegration(t *testing.T) {
+	pool := setup.InitDB(t)
+	setup.ResetAndSeedDB(t, pool)
+	defer setup.CloseDB(pool)
+
+	ctx := context.Background()
+	tenantID := setup.TestTenantsData["enterprise"].ID
+	creatorID := setup.TestUsersData["enterprise_20"].UserID
+
+	var countryID string
+	err := pool.QueryRow(ctx, `
+		INSERT INTO tenant_ip_whitelist_countries (tenant_id, country_code, action, created_by)
+		VALUES ($1, 'JP', 'allow', $2)
+		RETURNING id`,
+		tenantID, creatorID).Scan(&countryID)
+	require.NoError(t, err)
+
+	purgeDeletedUser(t, pool, creatorID)
+
+	var hasCreator bool
+	err = pool.QueryRow(ctx, `SELECT created_by IS NOT NULL FROM tenant_ip_whitelist_countries WHERE id = $1`, countryID).Scan(&hasCreator)
+	require.NoError(t, err, "The country rule outlives the user who added it")
+	assert.False(t, hasCreator)
+}
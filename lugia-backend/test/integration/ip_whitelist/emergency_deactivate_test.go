@@ -451,8 +451,8 @@ func TestEmergencyDeactivateIntegration(t *testing.T) {
 
 			// Add auth if user specified
 			if userKey != "" {
-				email, password := findUserCredentials(userKey)
-				accessToken, _ := setup.LoginUserAndGetTokens(t, email, password)
+				email, _ := findUserCredentials(userKey)
+				accessToken := setup.IssueAccessToken(t, email)
 				req.AddCookie(&http.Cookie{
 					Name:  "dislyze_access_token",
 					Value: accessToken,
@@ -0,0 +1,215 @@
+package ip_whitelist
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"lugia/features/audit_logs"
+	"lugia/features/ip_whitelist"
+	"lugia/lib/maintenance"
+	"lugia/queries"
+	"lugia/test/integration/setup"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func addIPWithExpiry(t *testing.T, userKey, clientIP string, body ip_whitelist.AddIPToWhitelistRequest) int {
+	payload, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, setup.BaseURL+"/ip-whitelist/create", bytes.NewReader(payload))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Real-IP", clientIP)
+
+	email, _ := findUserCredentials(userKey)
+	accessToken := setup.IssueAccessToken(t, email)
+	req.AddCookie(&http.Cookie{
+		Name:  "dislyze_access_token",
+		Value: accessToken,
+		Path:  "/",
+	})
+
+	resp, err := (&http.Client{}).Do(req)
+	require.NoError(t, err)
+	if err := resp.Body.Close(); err != nil {
+		t.Logf("Error closing response body: %v", err)
+	}
+	return resp.StatusCode
+}
+
+func TestIPWhitelistRuleExpiryIntegration(t *testing.T) {
+	pool := setup.InitDB(t)
+	setup.ResetAndSeedDB(t, pool)
+	defer setup.CloseDB(pool)
+
+	ctx := context.Background()
+	tenantID := setup.TestTenantsData["enterprise"].ID
+	updateTenantEnterpriseFeatures(t, pool, tenantID, map[string]interface{}{
+		"rbac": map[string]interface{}{
+			"enabled": true,
+		},
+		"audit_log": map[string]interface{}{
+			"enabled": true,
+		},
+		"ip_whitelist": map[string]interface{}{
+			"enabled":                     true,
+			"active":                      true,
+			"allow_internal_admin_bypass": false,
+		},
+	})
+	insertIPWhitelistRule(t, pool, tenantID, "192.168.1.100", "Office", setup.TestUsersData["enterprise_1"].UserID)
+
+	t.Run("past expiry is rejected", func(t *testing.T) {
+		expiresAt := time.Now().Add(-time.Hour)
+		status := addIPWithExpiry(t, "enterprise_1", "192.168.1.100", ip_whitelist.AddIPToWhitelistRequest{
+			IPAddress: "198.51.100.0/24",
+			ExpiresAt: &expiresAt,
+		})
+		assert.Equal(t, http.StatusUnprocessableEntity, status)
+	})
+
+	t.Run("temporary rule allows access until it expires", func(t *testing.T) {
+		expiresAt := time.Now().Add(time.Hour)
+		status := addIPWithExpiry(t, "enterprise_1", "192.168.1.100", ip_whitelist.AddIPToWhitelistRequest{
+			IPAddress: "198.51.100.0/24",
+			ExpiresAt: &expiresAt,
+		})
+		require.Equal(t, http.StatusNoContent, status)
+
+		resp := requestFromIP(t, http.MethodGet, "/me", "enterprise_2", "198.51.100.20")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp = requestFromIP(t, http.MethodGet, "/ip-whitelist", "enterprise_1", "192.168.1.100")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var whitelist ip_whitelist.GetIPWhitelistResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&whitelist))
+		var found bool
+		for _, rule := range whitelist.Rules {
+			if rule.IPAddress == "198.51.100.0/24" {
+				found = true
+				require.NotNil(t, rule.ExpiresAt)
+				assert.WithinDuration(t, expiresAt, *rule.ExpiresAt, time.Second)
+			}
+		}
+		assert.True(t, found)
+	})
+
+	runner := maintenance.NewRunner(pool, queries.New(pool), &maintenance.Config{
+		Interval:  time.Hour,
+		BatchSize: 100,
+		Retentions: maintenance.Retentions{
+			DeletedUsers:        1000 * time.Hour,
+			PasswordResetTokens: 1000 * time.Hour,
+			EmailChangeTokens:   1000 * time.Hour,
+			InvitationTokens:    1000 * time.Hour,
+			RefreshTokens:       1000 * time.Hour,
+			SSOAuthRequests:     1000 * time.Hour,
+			IPMonitorHits:       1000 * time.Hour,
+		},
+		IPRuleExpiryWarning: 72 * time.Hour,
+		FrontendURL:         "http://localhost:23000",
+		SendgridAPIKey:      os.Getenv("SENDGRID_API_KEY"),
+		SendgridAPIUrl:      os.Getenv("SENDGRID_API_URL"),
+	})
+
+	t.Run("editors are warned once before a rule expires", func(t *testing.T) {
+		runner.RunOnce(ctx)
+
+		var warned bool
+		err := pool.QueryRow(ctx, `SELECT expiry_warned_at IS NOT NULL FROM tenant_ip_whitelist WHERE tenant_id = $1 AND ip_address = '198.51.100.0/24'`,
+			tenantID).Scan(&warned)
+		require.NoError(t, err)
+		assert.True(t, warned)
+
+		email, err := setup.GetLatestEmailFromSendgridMock(t, setup.TestUsersData["enterprise_1"].Email)
+		require.NoError(t, err)
+		require.NotNil(t, email)
+		assert.Contains(t, email.Personalizations[0].Subject, "まもなく期限切れ")
+		assert.True(t, strings.Contains(email.Content[0].Value, "198.51.100.0/24"))
+	})
+
+	t.Run("expired rule stops matching immediately", func(t *testing.T) {
+		_, err := pool.Exec(ctx, `UPDATE tenant_ip_whitelist SET expires_at = CURRENT_TIMESTAMP - INTERVAL '1 minute' WHERE tenant_id = $1 AND ip_address = '198.51.100.0/24'`,
+			tenantID)
+		require.NoError(t, err)
+
+		resp := requestFromIP(t, http.MethodGet, "/me", "enterprise_2", "198.51.100.20")
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("sweeper removes expired rules and audits them", func(t *testing.T) {
+		runner.RunOnce(ctx)
+
+		var remaining int
+		err := pool.QueryRow(ctx, `SELECT COUNT(*) FROM tenant_ip_whitelist WHERE tenant_id = $1 AND ip_address = '198.51.100.0/24'`,
+			tenantID).Scan(&remaining)
+		require.NoError(t, err)
+		assert.Equal(t, 0, remaining)
+
+		var hasActor bool
+		var reason, ipAddress, createdBy string
+		err = pool.QueryRow(ctx, `
+			SELECT actor_id IS NOT NULL, metadata->>'reason', metadata->>'ip_address', metadata->>'created_by' FROM audit_logs
+			WHERE tenant_id = $1 AND action = 'ip_removed'
+			ORDER BY created_at DESC LIMIT 1`,
+			tenantID).Scan(&hasActor, &reason, &ipAddress, &createdBy)
+		require.NoError(t, err)
+		assert.Equal(t, "expired", reason)
+		assert.Equal(t, "198.51.100.0/24", ipAddress)
+		assert.False(t, hasActor, "Nobody removed the rule, so the entry has no actor")
+		assert.Equal(t, setup.TestUsersData["enterprise_1"].UserID, createdBy)
+
+		var active bool
+		err = pool.QueryRow(ctx, `SELECT (enterprise_features->'ip_whitelist'->>'active')::boolean FROM tenants WHERE id = $1`,
+			tenantID).Scan(&active)
+		require.NoError(t, err)
+		assert.True(t, active, "A tenant-wide rule is left, so the whitelist stays active")
+	})
+
+	t.Run("system entries are listed without an actor", func(t *testing.T) {
+		resp := requestFromIP(t, http.MethodGet, "/audit-logs?resource_type=ip_whitelist&action=ip_removed", "enterprise_1", "192.168.1.100")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var result audit_logs.GetAuditLogsResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		require.NotEmpty(t, result.AuditLogs)
+		assert.Equal(t, "", result.AuditLogs[0].ActorID)
+		assert.Equal(t, "", result.AuditLogs[0].ActorName)
+	})
+
+	t.Run("expiry of the last tenant-wide rule keeps the whitelist enforcing", func(t *testing.T) {
+		_, err := pool.Exec(ctx, `UPDATE tenant_ip_whitelist SET expires_at = CURRENT_TIMESTAMP - INTERVAL '1 minute' WHERE tenant_id = $1 AND ip_address = '192.168.1.100'`,
+			tenantID)
+		require.NoError(t, err)
+
+		runner.RunOnce(ctx)
+
+		var remaining int
+		err = pool.QueryRow(ctx, `SELECT COUNT(*) FROM tenant_ip_whitelist WHERE tenant_id = $1`, tenantID).Scan(&remaining)
+		require.NoError(t, err)
+		assert.Equal(t, 0, remaining)
+
+		var active bool
+		err = pool.QueryRow(ctx, `SELECT (enterprise_features->'ip_whitelist'->>'active')::boolean FROM tenants WHERE id = $1`,
+			tenantID).Scan(&active)
+		require.NoError(t, err)
+		assert.True(t, active, "An expiry must not switch the whitelist off")
+
+		resp := requestFromIP(t, http.MethodGet, "/me", "enterprise_1", "192.168.1.100")
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode, "An emptied whitelist denies everyone")
+
+		var deactivations int
+		err = pool.QueryRow(ctx, `SELECT COUNT(*) FROM audit_logs WHERE tenant_id = $1 AND resource_type = 'ip_whitelist' AND action = 'deactivated'`,
+			tenantID).Scan(&deactivations)
+		require.NoError(t, err)
+		assert.Equal(t, 0, deactivations)
+	})
+}
@@ -763,10 +763,10 @@ func TestGetIPWhitelistIntegration(t *testing.T) {
 
 			// Login if user specified
 			if userKey != "" {
-				email, password := findUserCredentials(userKey)
+				email, _ := findUserCredentials(userKey)
 				require.NotEmpty(t, email, "User key not found: %s", userKey)
 
-				accessToken, _ := setup.LoginUserAndGetTokens(t, email, password)
+				accessToken := setup.IssueAccessToken(t, email)
 				req.AddCookie(&http.Cookie{
 					Name:  "dislyze_access_token",
 					Value: accessToken,
@@ -0,0 +1,214 @@
+package ip_whitelist
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"lugia/features/ip_whitelist"
+	"lugia/test/integration/setup"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func importIPWhitelist(t *testing.T, userKey string, body ip_whitelist.ImportIPWhitelistRequest) (int, *ip_whitelist.ImportIPWhitelistResponse) {
+	payload, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, setup.BaseURL+"/ip-whitelist/import", bytes.NewReader(payload))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	email, password := findUserCredentials(userKey)
+	accessToken, _ := setup.LoginUserAndGetTokens(t, email, password)
+	req.AddCookie(&http.Cookie{
+		Name:  "dislyze_access_token",
+		Value: accessToken,
+		Path:  "/",
+	})
+
+	resp, err := (&http.Client{}).Do(req)
+	require.NoError(t, err)
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			t.Logf("Error closing response body: %v", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, nil
+	}
+	var result ip_whitelist.ImportIPWhitelistResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	return resp.StatusCode, &result
+}
+
+func countIPWhitelistRules(t *testing.T, pool *pgxpool.Pool, tenantID string) int {
+	var count int
+	err := pool.QueryRow(context.Background(), "SELECT COUNT(*) FROM tenant_ip_whitelist WHERE tenant_id = $1", tenantID).Scan(&count)
+	require.NoError(t, err)
+	return count
+}
+
+func TestImportExportIPWhitelistIntegration(t *testing.T) {
+	pool := setup.InitDB(t)
+	setup.ResetAndSeedDB(t, pool)
+	defer setup.CloseDB(pool)
+
+	ctx := context.Background()
+	tenantID := setup.TestTenantsData["enterprise"].ID
+	updateTenantEnterpriseFeatures(t, pool, tenantID, map[string]interface{}{
+		"rbac": map[string]interface{}{
+			"enabled": true,
+		},
+		"audit_log": map[string]interface{}{
+			"enabled": true,
+		},
+		"ip_whitelist": map[string]interface{}{
+			"enabled":                     true,
+			"active":                      false,
+			"allow_internal_admin_bypass": false,
+		},
+	})
+	insertIPWhitelistRule(t, pool, tenantID, "192.168.1.100/32", "Office", setup.TestUsersData["enterprise_1"].UserID)
+
+	t.Run("user without ip_whitelist edit cannot import", func(t *testing.T) {
+		status, _ := importIPWhitelist(t, "enterprise_2", ip_whitelist.ImportIPWhitelistRequest{
+			Format:  "csv",
+			Content: "10.0.0.0/8\n",
+		})
+		assert.Equal(t, http.StatusForbidden, status)
+	})
+
+	t.Run("unreadable file is rejected", func(t *testing.T) {
+		status, _ := importIPWhitelist(t, "enterprise_1", ip_whitelist.ImportIPWhitelistRequest{
+			Format:  "json",
+			Content: `{"ip_address": "10.0.0.0/8"}`,
+		})
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+
+	t.Run("all-or-nothing import adds nothing when a row fails", func(t *testing.T) {
+		before := countIPWhitelistRules(t, pool, tenantID)
+
+		status, result := importIPWhitelist(t, "enterprise_1", ip_whitelist.ImportIPWhitelistRequest{
+			Format:       "csv",
+			Content:      "ip_address,label\n10.0.0.0/8,VPN\nnot-an-ip,Broken\n",
+			AllOrNothing: true,
+		})
+		require.Equal(t, http.StatusOK, status)
+		assert.Equal(t, 0, result.Imported)
+		require.Len(t, result.Rows, 2)
+		assert.Equal(t, "skipped", result.Rows[0].Status)
+		assert.Equal(t, "invalid", result.Rows[1].Status)
+		assert.Equal(t, 3, result.Rows[1].Row)
+		assert.NotNil(t, result.Rows[1].Error)
+
+		assert.Equal(t, before, countIPWhitelistRules(t, pool, tenantID))
+	})
+
+	t.Run("partial import adds valid rows and reports the rest", func(t *testing.T) {
+		expiresAt := time.Now().Add(48 * time.Hour).UTC().Format(time.RFC3339)
+		status, result := importIPWhitelist(t, "enterprise_1", ip_whitelist.ImportIPWhitelistRequest{
+			Format: "json",
+			Content: `[
+				{"ip_address": "10.0.0.0/8", "label": "VPN"},
+				{"ip_address": "2001:db8::1", "label": null, "expires_at": "` + expiresAt + `"},
+				{"ip_address": "192.168.1.100", "label": "Office again"},
+				{"ip_address": "10.0.0.0/8", "label": "VPN twice"},
+				{"ip_address": "172.16.0.0/12", "expires_at": "2000-01-01T00:00:00Z"}
+			]`,
+		})
+		require.Equal(t, http.StatusOK, status)
+		assert.Equal(t, 2, result.Imported)
+		require.Len(t, result.Rows, 5)
+		assert.Equal(t, "added", result.Rows[0].Status)
+		assert.Equal(t, "added", result.Rows[1].Status)
+		assert.Equal(t, "2001:db8::1/128", result.Rows[1].IPAddress)
+		assert.Equal(t, "duplicate", result.Rows[2].Status, "Already in the whitelist")
+		assert.Equal(t, "duplicate", result.Rows[3].Status, "Repeated within the file")
+		assert.Equal(t, "invalid", result.Rows[4].Status, "Expiry in the past")
+
+		var count int
+		var rulesJSON []byte
+		err := pool.QueryRow(ctx, `
+			SELECT (metadata->>'count')::int, metadata->'rules' FROM audit_logs
+			WHERE tenant_id = $1 AND action = 'ip_imported'
+			ORDER BY created_at DESC LIMIT 1`,
+			tenantID).Scan(&count, &rulesJSON)
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+		var rules []map[string]string
+		require.NoError(t, json.Unmarshal(rulesJSON, &rules))
+		require.Len(t, rules, 2)
+		assert.Equal(t, "10.0.0.0/8", rules[0]["ip_address"])
+		assert.Equal(t, "VPN", rules[0]["label"])
+	})
+
+	t.Run("a broad rule is only imported once confirmed", func(t *testing.T) {
+		body := ip_whitelist.ImportIPWhitelistRequest{
+			Format:  "csv",
+			Content: "ip_address,label\n198.51.100.0/24,Branch\n0.0.0.0/0,Everyone\n",
+		}
+
+		status, result := importIPWhitelist(t, "enterprise_1", body)
+		require.Equal(t, http.StatusOK, status)
+		assert.Equal(t, 1, result.Imported)
+		require.Len(t, result.Rows, 2)
+		assert.Equal(t, "added", result.Rows[0].Status)
+		assert.Equal(t, "broad", result.Rows[1].Status)
+		assert.NotNil(t, result.Rows[1].Error)
+
+		var stored int
+		err := pool.QueryRow(ctx, `SELECT COUNT(*) FROM tenant_ip_whitelist WHERE tenant_id = $1 AND ip_address = '0.0.0.0/0'`, tenantID).Scan(&stored)
+		require.NoError(t, err)
+		assert.Equal(t, 0, stored)
+
+		body.ConfirmBroad = true
+		status, result = importIPWhitelist(t, "enterprise_1", body)
+		require.Equal(t, http.StatusOK, status)
+		assert.Equal(t, 1, result.Imported)
+		assert.Equal(t, "duplicate", result.Rows[0].Status)
+		assert.Equal(t, "added", result.Rows[1].Status)
+
+		var broad string
+		err = pool.QueryRow(ctx, `
+			SELECT metadata->'rules'->0->>'broad' FROM audit_logs
+			WHERE tenant_id = $1 AND action = 'ip_imported'
+			ORDER BY created_at DESC LIMIT 1`,
+			tenantID).Scan(&broad)
+		require.NoError(t, err)
+		assert.Equal(t, "true", broad)
+
+		_, err = pool.Exec(ctx, `DELETE FROM tenant_ip_whitelist WHERE tenant_id = $1 AND ip_address IN ('0.0.0.0/0', '198.51.100.0/24')`, tenantID)
+		require.NoError(t, err)
+	})
+
+	t.Run("export round-trips through import", func(t *testing.T) {
+		for _, format := range []string{"csv", "json"} {
+			resp := requestFromIP(t, http.MethodGet, "/ip-whitelist/export?format="+format, "enterprise_1", "192.168.1.100")
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Contains(t, resp.Header.Get("Content-Disposition"), "ip-whitelist."+format)
+			content, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			assert.Contains(t, string(content), "2001:db8::1/128")
+
+			status, result := importIPWhitelist(t, "enterprise_1", ip_whitelist.ImportIPWhitelistRequest{
+				Format:  format,
+				Content: string(content),
+			})
+			require.Equal(t, http.StatusOK, status)
+			assert.Equal(t, 0, result.Imported)
+			require.Len(t, result.Rows, 3)
+			for _, row := range result.Rows {
+				assert.Equal(t, "duplicate", row.Status, row.IPAddress)
+			}
+		}
+	})
+}
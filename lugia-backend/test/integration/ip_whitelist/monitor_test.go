@@ -0,0 +1,133 @@
+package ip_whitelist
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"lugia/features/ip_whitelist"
+	"lugia/test/integration/setup"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func requestFromIP(t *testing.T, method, path, userKey, clientIP string) *http.Response {
+	var body io.Reader
+	if method == http.MethodPost {
+		body = strings.NewReader("{}")
+	}
+	req, err := http.NewRequest(method, setup.BaseURL+path, body)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Real-IP", clientIP)
+
+	email, _ := findUserCredentials(userKey)
+	accessToken := setup.IssueAccessToken(t, email)
+	req.AddCookie(&http.Cookie{
+		Name:  "dislyze_access_token",
+		Value: accessToken,
+		Path:  "/",
+	})
+
+	resp, err := (&http.Client{}).Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if err := resp.Body.Close(); err != nil {
+			t.Logf("Error closing response body: %v", err)
+		}
+	})
+	return resp
+}
+
+func getIPWhitelistFeatures(t *testing.T, pool *pgxpool.Pool) map[string]interface{} {
+	var featuresJSON []byte
+	err := pool.QueryRow(context.Background(),
+		"SELECT enterprise_features FROM tenants WHERE id = $1",
+		setup.TestTenantsData["enterprise"].ID).Scan(&featuresJSON)
+	require.NoError(t, err)
+
+	var features map[string]interface{}
+	require.NoError(t, json.Unmarshal(featuresJSON, &features))
+	return features["ip_whitelist"].(map[string]interface{})
+}
+
+func TestIPWhitelistMonitorIntegration(t *testing.T) {
+	pool := setup.InitDB(t)
+	setup.ResetAndSeedDB(t, pool)
+	defer setup.CloseDB(pool)
+
+	tenantID := setup.TestTenantsData["enterprise"].ID
+	updateTenantEnterpriseFeatures(t, pool, tenantID, map[string]interface{}{
+		"rbac": map[string]interface{}{
+			"enabled": true,
+		},
+		"ip_whitelist": map[string]interface{}{
+			"enabled":                     true,
+			"active":                      true,
+			"allow_internal_admin_bypass": false,
+		},
+	})
+	insertIPWhitelistRule(t, pool, tenantID, "192.168.1.100", "Office", setup.TestUsersData["enterprise_1"].UserID)
+
+	t.Run("editor without ip_whitelist edit cannot start monitoring", func(t *testing.T) {
+		resp := requestFromIP(t, http.MethodPost, "/ip-whitelist/monitor", "enterprise_2", "192.168.1.100")
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("starting monitor mode stops enforcement", func(t *testing.T) {
+		resp := requestFromIP(t, http.MethodPost, "/ip-whitelist/monitor", "enterprise_1", "192.168.1.100")
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		features := getIPWhitelistFeatures(t, pool)
+		assert.False(t, features["active"].(bool))
+		assert.True(t, features["monitor"].(bool))
+	})
+
+	t.Run("requests from outside the whitelist pass and are recorded", func(t *testing.T) {
+		for range 3 {
+			resp := requestFromIP(t, http.MethodGet, "/me", "enterprise_2", "203.0.113.7")
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+		}
+		resp := requestFromIP(t, http.MethodGet, "/me", "enterprise_2", "192.168.1.100")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp = requestFromIP(t, http.MethodGet, "/ip-whitelist/monitor-report?days=1", "enterprise_1", "192.168.1.100")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var report ip_whitelist.GetMonitorReportResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+		assert.Equal(t, int64(3), report.TotalHits)
+		require.Len(t, report.SourceIPs, 1)
+		assert.Equal(t, "203.0.113.7", report.SourceIPs[0].IPAddress)
+		assert.Equal(t, 1, report.SourceIPs[0].Users)
+		assert.False(t, report.SourceIPs[0].AllowedByCurrentRules)
+		require.Len(t, report.Entries, 1)
+		assert.Equal(t, setup.TestUsersData["enterprise_2"].UserID, report.Entries[0].UserID)
+	})
+
+	t.Run("report re-checks source IPs against the current rules", func(t *testing.T) {
+		insertIPWhitelistRule(t, pool, tenantID, "203.0.113.0/24", "VPN", setup.TestUsersData["enterprise_1"].UserID)
+
+		resp := requestFromIP(t, http.MethodGet, "/ip-whitelist/monitor-report", "enterprise_1", "192.168.1.100")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var report ip_whitelist.GetMonitorReportResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+		require.Len(t, report.SourceIPs, 1)
+		assert.True(t, report.SourceIPs[0].AllowedByCurrentRules)
+	})
+
+	t.Run("activating clears monitor mode", func(t *testing.T) {
+		resp := requestFromIP(t, http.MethodPost, "/ip-whitelist/activate", "enterprise_1", "192.168.1.100")
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		features := getIPWhitelistFeatures(t, pool)
+		assert.True(t, features["active"].(bool))
+		assert.False(t, features["monitor"].(bool))
+	})
+}
@@ -0,0 +1,298 @@
+package ip_whitelist
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"lugia/features/ip_whitelist"
+	"lugia/lib/maintenance"
+	"lugia/queries"
+	"lugia/test/integration/setup"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func postJSONFromIP(t *testing.T, path, userKey, clientIP string, body any) *http.Response {
+	payload, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, setup.BaseURL+path, bytes.NewReader(payload))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Real-IP", clientIP)
+
+	email, _ := findUserCredentials(userKey)
+	accessToken := setup.IssueAccessToken(t, email)
+	req.AddCookie(&http.Cookie{
+		Name:  "dislyze_access_token",
+		Value: accessToken,
+		Path:  "/",
+	})
+
+	resp, err := (&http.Client{}).Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if err := resp.Body.Close(); err != nil {
+			t.Logf("Error closing response body: %v", err)
+		}
+	})
+	return resp
+}
+
+func TestIPWhitelistScopesIntegration(t *testing.T) {
+	pool := setup.InitDB(t)
+	setup.ResetAndSeedDB(t, pool)
+	defer setup.CloseDB(pool)
+
+	tenantID := setup.TestTenantsData["enterprise"].ID
+	updateTenantEnterpriseFeatures(t, pool, tenantID, map[string]interface{}{
+		"rbac": map[string]interface{}{
+			"enabled": true,
+		},
+		"audit_log": map[string]interface{}{
+			"enabled": true,
+		},
+		"ip_whitelist": map[string]interface{}{
+			"enabled":                     true,
+			"active":                      true,
+			"allow_internal_admin_bypass": false,
+		},
+	})
+	insertIPWhitelistRule(t, pool, tenantID, "192.168.1.0/24", "Office", setup.TestUsersData["enterprise_1"].UserID)
+
+	const office = "192.168.1.100"
+	const travelling = "203.0.113.7"
+	editorRoleID := setup.TestRolesData["enterprise_editor"].ID
+	userID := setup.TestUsersData["enterprise_3"].UserID
+
+	var roleScopeID, userScopeID string
+
+	t.Run("a scope needs exactly one of role and user", func(t *testing.T) {
+		resp := postJSONFromIP(t, "/ip-whitelist/scopes/create", "enterprise_1", office, map[string]any{
+			"role_id": editorRoleID,
+			"user_id": userID,
+			"exempt":  true,
+		})
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+	})
+
+	t.Run("an exempt role is let in from anywhere", func(t *testing.T) {
+		resp := requestFromIP(t, http.MethodGet, "/me", "enterprise_2", travelling)
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp = postJSONFromIP(t, "/ip-whitelist/scopes/create", "enterprise_1", office, ip_whitelist.CreateIPWhitelistScopeRequest{
+			RoleID: &editorRoleID,
+			Exempt: true,
+		})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var created ip_whitelist.CreateIPWhitelistScopeResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+		roleScopeID = created.Scope.ID
+		require.NotNil(t, created.Scope.RoleName)
+		assert.Equal(t, setup.TestRolesData["enterprise_editor"].Name, *created.Scope.RoleName)
+
+		resp = requestFromIP(t, http.MethodGet, "/me", "enterprise_2", travelling)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("a second scope for the same role is rejected", func(t *testing.T) {
+		resp := postJSONFromIP(t, "/ip-whitelist/scopes/create", "enterprise_1", office, ip_whitelist.CreateIPWhitelistScopeRequest{
+			RoleID: &editorRoleID,
+		})
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("a user scope overrides the user's role scope", func(t *testing.T) {
+		resp := postJSONFromIP(t, "/ip-whitelist/scopes/create", "enterprise_1", office, ip_whitelist.CreateIPWhitelistScopeRequest{
+			UserID: &userID,
+		})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var created ip_whitelist.CreateIPWhitelistScopeResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+		userScopeID = created.Scope.ID
+
+		// The scope has no rules yet, so it admits nothing.
+		resp = requestFromIP(t, http.MethodGet, "/me", "enterprise_3", office)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		resp = requestFromIP(t, http.MethodGet, "/me", "enterprise_2", travelling)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("scope rules narrow the tenant rules", func(t *testing.T) {
+		resp := postJSONFromIP(t, "/ip-whitelist/create", "enterprise_1", office, ip_whitelist.AddIPToWhitelistRequest{
+			IPAddress: "192.168.1.100/32",
+			ScopeID:   &userScopeID,
+		})
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		resp = requestFromIP(t, http.MethodGet, "/me", "enterprise_3", office)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		resp = requestFromIP(t, http.MethodGet, "/me", "enterprise_3", "192.168.1.101")
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("rules can't be added to an exempt scope", func(t *testing.T) {
+		resp := postJSONFromIP(t, "/ip-whitelist/create", "enterprise_1", office, ip_whitelist.AddIPToWhitelistRequest{
+			IPAddress: "192.168.1.100/32",
+			ScopeID:   &roleScopeID,
+		})
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("turning exemption off restricts the role", func(t *testing.T) {
+		resp := postJSONFromIP(t, "/ip-whitelist/scopes/"+roleScopeID+"/update", "enterprise_1", office, ip_whitelist.UpdateIPWhitelistScopeRequest{
+			Exempt: false,
+		})
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		resp = requestFromIP(t, http.MethodGet, "/me", "enterprise_2", travelling)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		resp = requestFromIP(t, http.MethodGet, "/me", "enterprise_2", office)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("deleting a scope returns its members to the tenant rules", func(t *testing.T) {
+		resp := postJSONFromIP(t, "/ip-whitelist/scopes/"+userScopeID+"/delete", "enterprise_1", office, struct{}{})
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+		resp = postJSONFromIP(t, "/ip-whitelist/scopes/"+roleScopeID+"/delete", "enterprise_1", office, struct{}{})
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		resp = requestFromIP(t, http.MethodGet, "/me", "enterprise_3", "192.168.1.101")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		resp = requestFromIP(t, http.MethodGet, "/me", "enterprise_2", office)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var scopedRules int
+		err := pool.QueryRow(context.Background(),
+			"SELECT COUNT(*) FROM tenant_ip_whitelist WHERE tenant_id = $1 AND scope_id IS NOT NULL", tenantID).Scan(&scopedRules)
+		require.NoError(t, err)
+		assert.Equal(t, 0, scopedRules)
+	})
+
+	t.Run("scope changes are audited", func(t *testing.T) {
+		rows, err := pool.Query(context.Background(),
+			"SELECT action FROM audit_logs WHERE tenant_id = $1 AND action LIKE 'ip_scope_%' ORDER BY created_at", tenantID)
+		require.NoError(t, err)
+		defer rows.Close()
+
+		var actions []string
+		for rows.Next() {
+			var action string
+			require.NoError(t, rows.Scan(&action))
+			actions = append(actions, action)
+		}
+		require.NoError(t, rows.Err())
+		assert.Equal(t, []string{"ip_scope_created", "ip_scope_created", "ip_scope_updated", "ip_scope_deleted", "ip_scope_deleted"}, actions)
+	})
+
+	t.Run("an included role brings its scope along", func(t *testing.T) {
+		includedRoleID := createIPWhitelistRole(t, pool, tenantID, nil)
+		_, err := pool.Exec(context.Background(),
+			"INSERT INTO role_inclusions (role_id, included_role_id, tenant_id) VALUES ($1, $2, $3)",
+			editorRoleID, includedRoleID, tenantID)
+		require.NoError(t, err)
+
+		resp := requestFromIP(t, http.MethodGet, "/me", "enterprise_2", travelling)
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp = postJSONFromIP(t, "/ip-whitelist/scopes/create", "enterprise_1", office, ip_whitelist.CreateIPWhitelistScopeRequest{
+			RoleID: &includedRoleID,
+			Exempt: true,
+		})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp = requestFromIP(t, http.MethodGet, "/me", "enterprise_2", travelling)
+		assert.Equal(t, http.StatusOK, resp.StatusCode, "The editor role includes the exempt role")
+	})
+}
+
+// purgeDeletedUser soft-deletes userID long enough ago and runs the
+// maintenance runner, asserting the anonymized user row was purged.
+func purgeDeletedUser(t *testing.T, pool *pgxpool.Pool, userID string) {
+	ctx := context.Background()
+
+	_, err := pool.Exec(ctx, `UPDATE users SET deleted_at = CURRENT_TIMESTAMP - INTERVAL '2000 hours' WHERE id = $1`, userID)
+	require.NoError(t, err)
+
+	maintenance.NewRunner(pool, queries.New(pool), &maintenance.Config{
+		Interval:  time.Hour,
+		BatchSize: 100,
+		Retentions: maintenance.Retentions{
+			DeletedUsers:        1000 * time.Hour,
+			PasswordResetTokens: 1000 * time.Hour,
+			EmailChangeTokens:   1000 * time.Hour,
+			InvitationTokens:    1000 * time.Hour,
+			RefreshTokens:       1000 * time.Hour,
+			SSOAuthRequests:     1000 * time.Hour,
+			IPMonitorHits:       1000 * time.Hour,
+		},
+	}).RunOnce(ctx)
+
+	var remaining int
+	err = pool.QueryRow(ctx, `SELECT COUNT(*) FROM users WHERE id = $1`, userID).Scan(&remaining)
+	require.NoError(t, err)
+	assert.Equal(t, 0, remaining, "The anonymized user should have been purged")
+}
+
+func TestIPWhitelistScopeCreatorPurgeIntegration(t *testing.T) {
+	pool := setup.InitDB(t)
+	setup.ResetAndSeedDB(t, pool)
+	defer setup.CloseDB(pool)
+
+	ctx := context.Background()
+	tenantID := setup.TestTenantsData["enterprise"].ID
+	creatorID := setup.TestUsersData["enterprise_20"].UserID
+
+	var scopeID string
+	err := pool.QueryRow(ctx, `
+		INSERT INTO ip_whitelist_scopes (tenant_id, role_id, exempt, created_by)
+		VALUES ($1, $2, true, $3)
+		RETURNING id`,
+		tenantID, setup.TestRolesData["enterprise_editor"].ID, creatorID).Scan(&scopeID)
+	require.NoError(t, err)
+
+	purgeDeletedUser(t, pool, creatorID)
+
+	var hasCreator bool
+	err = pool.QueryRow(ctx, `SELECT created_by IS NOT NULL FROM ip_whitelist_scopes WHERE id = $1`, scopeID).Scan(&hasCreator)
+	require.NoError(t, err, "The scope outlives the user who created it")
+	assert.False(t, hasCreator)
+}
+
+func TestIPWhitelistRuleCreatorPurgeIntegration(t *testing.T) {
+	pool := setup.InitDB(t)
+	setup.ResetAndSeedDB(t, pool)
+	defer setup.CloseDB(pool)
+
+	ctx := context.Background()
+	tenantID := setup.TestTenantsData["enterprise"].ID
+	creatorID := setup.TestUsersData["enterprise_20"].UserID
+
+	ruleID := insertIPWhitelistRuleAndReturnID(t, pool, tenantID, "203.0.113.0/24", "Office", creatorID)
+
+	var auditLogID string
+	err := pool.QueryRow(ctx, `
+		INSERT INTO audit_logs (tenant_id, actor_id, resource_type, action, outcome, resource_id)
+		VALUES ($1, $2, 'ip_whitelist', 'ip_added', 'success', $3)
+		RETURNING id`,
+		tenantID, creatorID, ruleID).Scan(&auditLogID)
+	require.NoError(t, err)
+
+	purgeDeletedUser(t, pool, creatorID)
+
+	var hasCreator bool
+	err = pool.QueryRow(ctx, `SELECT created_by IS NOT NULL FROM tenant_ip_whitelist WHERE id = $1`, ruleID).Scan(&hasCreator)
+	require.NoError(t, err, "The rule outlives the user who added it")
+	assert.False(t, hasCreator)
+
+	var hasActor bool
+	err = pool.QueryRow(ctx, `SELECT actor_id IS NOT NULL FROM audit_logs WHERE id = $1`, auditLogID).Scan(&hasActor)
+	require.NoError(t, err, "The audit entry outlives its actor")
+	assert.False(t, hasActor)
+}
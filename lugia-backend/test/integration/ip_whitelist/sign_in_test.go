@@ -0,0 +1,215 @@
+package ip_whitelist
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"lugia/features/auth"
+	"lugia/features/users"
+	"lugia/test/integration/setup"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loginFromIP(t *testing.T, userKey, clientIP string) *http.Response {
+	email, password := findUserCredentials(userKey)
+	payload, err := json.Marshal(auth.LoginRequestBody{Email: email, Password: password})
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, setup.BaseURL+"/auth/login", bytes.NewReader(payload))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Real-IP", clientIP)
+
+	resp, err := (&http.Client{}).Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if err := resp.Body.Close(); err != nil {
+			t.Logf("Error closing response body: %v", err)
+		}
+	})
+	return resp
+}
+
+// refreshFromIP calls /me with only a refresh token, so the auth middleware
+// has to refresh the session before the request goes through.
+func refreshFromIP(t *testing.T, refreshToken, clientIP string) *http.Response {
+	req, err := http.NewRequest(http.MethodGet, setup.BaseURL+"/me", nil)
+	require.NoError(t, err)
+	req.Header.Set("X-Real-IP", clientIP)
+	req.AddCookie(&http.Cookie{
+		Name:  "dislyze_refresh_token",
+		Value: refreshToken,
+		Path:  "/",
+	})
+
+	resp, err := (&http.Client{}).Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if err := resp.Body.Close(); err != nil {
+			t.Logf("Error closing response body: %v", err)
+		}
+	})
+	return resp
+}
+
+func TestIPWhitelistSignInIntegration(t *testing.T) {
+	pool := setup.InitDB(t)
+	setup.ResetAndSeedDB(t, pool)
+	defer setup.CloseDB(pool)
+
+	const (
+		office  = "192.168.1.100"
+		outside = "203.0.113.7"
+	)
+
+	tenantID := setup.TestTenantsData["enterprise"].ID
+	userID := setup.TestUsersData["enterprise_2"].UserID
+	insertIPWhitelistRule(t, pool, tenantID, "192.168.1.0/24", "Office", setup.TestUsersData["enterprise_1"].UserID)
+	updateTenantEnterpriseFeatures(t, pool, tenantID, map[string]interface{}{
+		"ip_whitelist": map[string]interface{}{
+			"enabled":                     true,
+			"active":                      true,
+			"allow_internal_admin_bypass": false,
+		},
+		"audit_log": map[string]interface{}{
+			"enabled": true,
+		},
+	})
+
+	countIPBlocked := func(t *testing.T, blockedIP string) int {
+		var count int
+		err := pool.QueryRow(context.Background(), `
+			SELECT COUNT(*) FROM audit_logs
+			WHERE tenant_id = $1 AND actor_id = $2 AND action = 'ip_blocked'
+			  AND metadata->>'blocked_ip' = $3`,
+			tenantID, userID, blockedIP).Scan(&count)
+		require.NoError(t, err)
+		return count
+	}
+
+	var refreshToken string
+
+	t.Run("login from a whitelisted IP succeeds", func(t *testing.T) {
+		resp := loginFromIP(t, "enterprise_2", office)
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+		for _, cookie := range resp.Cookies() {
+			if cookie.Name == "dislyze_refresh_token" {
+				refreshToken = cookie.Value
+			}
+		}
+		require.NotEmpty(t, refreshToken)
+	})
+
+	t.Run("token refresh from a disallowed IP is refused and audited", func(t *testing.T) {
+		resp := refreshFromIP(t, refreshToken, outside)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		assert.Equal(t, 1, countIPBlocked(t, outside))
+	})
+
+	// The refused refresh rolled back, so the token is still unused.
+	t.Run("token refresh from a whitelisted IP succeeds", func(t *testing.T) {
+		resp := refreshFromIP(t, refreshToken, office)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("login from a disallowed IP is refused and audited", func(t *testing.T) {
+		resp := loginFromIP(t, "enterprise_2", outside)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		for _, cookie := range resp.Cookies() {
+			assert.NotEqual(t, "dislyze_access_token", cookie.Name)
+		}
+		assert.Equal(t, 2, countIPBlocked(t, outside))
+	})
+
+	t.Run("a live emergency link lets its user sign in from anywhere", func(t *testing.T) {
+		_, err := pool.Exec(context.Background(), `
+			INSERT INTO ip_whitelist_emergency_tokens (jti, tenant_id, user_id, expires_at)
+			VALUES (uuid_generate_v4(), $1, $2, CURRENT_TIMESTAMP + INTERVAL '30 minutes')`,
+			tenantID, userID)
+		require.NoError(t, err)
+
+		resp := loginFromIP(t, "enterprise_2", outside)
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+		assert.Equal(t, 2, countIPBlocked(t, outside))
+
+		_, err = pool.Exec(context.Background(),
+			"UPDATE ip_whitelist_emergency_tokens SET used_at = CURRENT_TIMESTAMP WHERE user_id = $1", userID)
+		require.NoError(t, err)
+
+		resp = loginFromIP(t, "enterprise_2", outside)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("login is not restricted while the whitelist is inactive", func(t *testing.T) {
+		updateTenantEnterpriseFeatures(t, pool, tenantID, map[string]interface{}{
+			"ip_whitelist": map[string]interface{}{
+				"enabled":                     true,
+				"active":                      false,
+				"allow_internal_admin_bypass": false,
+			},
+		})
+
+		resp := loginFromIP(t, "enterprise_2", outside)
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	})
+}
+
+func TestIPWhitelistTenantSwitchIntegration(t *testing.T) {
+	pool := setup.InitDB(t)
+	setup.ResetAndSeedDB(t, pool)
+	defer setup.CloseDB(pool)
+
+	const (
+		office  = "192.168.1.100"
+		outside = "203.0.113.7"
+	)
+
+	tenantID := setup.TestTenantsData["enterprise"].ID
+	smbUser := setup.TestUsersData["smb_1"]
+	_, err := pool.Exec(context.Background(),
+		"INSERT INTO tenant_memberships (tenant_id, user_id) VALUES ($1, $2)", tenantID, smbUser.UserID)
+	require.NoError(t, err)
+	assignRoleToUser(t, pool, smbUser.UserID, setup.TestRolesData["enterprise_viewer"].ID, tenantID)
+
+	insertIPWhitelistRule(t, pool, tenantID, "192.168.1.0/24", "Office", setup.TestUsersData["enterprise_1"].UserID)
+	updateTenantEnterpriseFeatures(t, pool, tenantID, map[string]interface{}{
+		"ip_whitelist": map[string]interface{}{
+			"enabled":                     true,
+			"active":                      true,
+			"allow_internal_admin_bypass": false,
+		},
+		"audit_log": map[string]interface{}{
+			"enabled": true,
+		},
+	})
+
+	switchTo := users.SwitchTenantRequestBody{TenantID: tenantID}
+
+	t.Run("switching in from a disallowed IP is refused and audited", func(t *testing.T) {
+		resp := postJSONFromIP(t, "/me/tenants/switch", "smb_1", outside, switchTo)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		for _, cookie := range resp.Cookies() {
+			assert.NotEqual(t, "dislyze_access_token", cookie.Name)
+		}
+
+		var blocked, switched int
+		err := pool.QueryRow(context.Background(), `
+			SELECT COUNT(*) FILTER (WHERE action = 'ip_blocked' AND metadata->>'blocked_ip' = $3),
+			       COUNT(*) FILTER (WHERE action = 'tenant_switched')
+			FROM audit_logs WHERE tenant_id = $1 AND actor_id = $2`,
+			tenantID, smbUser.UserID, outside).Scan(&blocked, &switched)
+		require.NoError(t, err)
+		assert.Equal(t, 1, blocked)
+		assert.Equal(t, 0, switched)
+	})
+
+	t.Run("switching in from a whitelisted IP succeeds", func(t *testing.T) {
+		resp := postJSONFromIP(t, "/me/tenants/switch", "smb_1", office, switchTo)
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	})
+}
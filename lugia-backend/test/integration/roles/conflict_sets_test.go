@@ -0,0 +1,119 @@
+package roles
+
+import (
+	"context"
+	"lugia/features/roles"
+	"lugia/features/users"
+	"lugia/test/integration/setup"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoleConflictSets_Integration(t *testing.T) {
+	pool := setup.InitDB(t)
+	setup.ResetAndSeedDB(t, pool)
+	defer setup.CloseDB(pool)
+
+	ctx := context.Background()
+	tenantID := setup.TestUsersData["enterprise_1"].TenantID
+	editorRoleID := setup.TestRolesData["enterprise_editor"].ID
+	managerRoleID := setup.TestRolesData["enterprise_user_manager"].ID
+
+	// enterprise_3 already holds both roles before the set exists.
+	_, err := pool.Exec(ctx, `INSERT INTO user_roles (user_id, role_id, tenant_id) VALUES ($1, $2, $3)`,
+		setup.TestUsersData["enterprise_3"].UserID, managerRoleID, tenantID)
+	require.NoError(t, err)
+
+	require.Equal(t, http.StatusNoContent, sendAsUser(t, "enterprise_1", "POST", "/roles/conflict-sets/create", roles.CreateConflictSetRequestBody{
+		Name:    "編集と承認の分離",
+		RoleIDs: []string{editorRoleID, managerRoleID},
+	}, nil))
+
+	var sets roles.GetConflictSetsResponse
+	require.Equal(t, http.StatusOK, sendAsUser(t, "enterprise_1", "GET", "/roles/conflict-sets", nil, &sets))
+	require.Len(t, sets.ConflictSets, 1)
+	set := sets.ConflictSets[0]
+	assert.Equal(t, "編集と承認の分離", set.Name)
+	assert.Len(t, set.Roles, 2)
+
+	t.Run("set needs two roles and a unique name", func(t *testing.T) {
+		status := sendAsUser(t, "enterprise_1", "POST", "/roles/conflict-sets/create", roles.CreateConflictSetRequestBody{
+			Name:    "単一ロール",
+			RoleIDs: []string{editorRoleID},
+		}, nil)
+		assert.Equal(t, http.StatusUnprocessableEntity, status)
+
+		status = sendAsUser(t, "enterprise_1", "POST", "/roles/conflict-sets/create", roles.CreateConflictSetRequestBody{
+			Name:    "重複ロール",
+			RoleIDs: []string{editorRoleID, editorRoleID},
+		}, nil)
+		assert.Equal(t, http.StatusBadRequest, status)
+
+		status = sendAsUser(t, "enterprise_1", "POST", "/roles/conflict-sets/create", roles.CreateConflictSetRequestBody{
+			Name:    "編集と承認の分離",
+			RoleIDs: []string{editorRoleID, managerRoleID},
+		}, nil)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+
+	t.Run("roles from another tenant are rejected", func(t *testing.T) {
+		status := sendAsUser(t, "enterprise_1", "POST", "/roles/conflict-sets/create", roles.CreateConflictSetRequestBody{
+			Name:    "他テナント",
+			RoleIDs: []string{editorRoleID, setup.TestRolesData["smb_admin"].ID},
+		}, nil)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+
+	t.Run("assigning both roles is refused", func(t *testing.T) {
+		status := sendAsUser(t, "enterprise_1", "POST", "/users/"+setup.TestUsersData["enterprise_2"].UserID+"/roles", users.UpdateUserRolesRequestBody{
+			RoleIDs: []string{editorRoleID, managerRoleID},
+		}, nil)
+		assert.Equal(t, http.StatusConflict, status)
+	})
+
+	t.Run("a composite role reaching both roles is refused", func(t *testing.T) {
+		status := sendAsUser(t, "enterprise_1", "POST", "/roles/create", roles.CreateRoleRequestBody{
+			Name:            "編集と承認",
+			IncludedRoleIDs: []string{editorRoleID, managerRoleID},
+		}, nil)
+		assert.Equal(t, http.StatusConflict, status)
+	})
+
+	t.Run("existing violations are reported", func(t *testing.T) {
+		var body roles.GetConflictViolationsResponse
+		require.Equal(t, http.StatusOK, sendAsUser(t, "enterprise_1", "GET", "/roles/conflict-sets/violations", nil, &body))
+		require.Len(t, body.Violations, 1)
+		assert.Equal(t, setup.TestUsersData["enterprise_3"].UserID, body.Violations[0].UserID)
+		assert.Equal(t, set.ID, body.Violations[0].SetID)
+		assert.Len(t, body.Violations[0].Roles, 2)
+	})
+
+	t.Run("a violating user can be made compliant", func(t *testing.T) {
+		status := sendAsUser(t, "enterprise_1", "POST", "/users/"+setup.TestUsersData["enterprise_3"].UserID+"/roles", users.UpdateUserRolesRequestBody{
+			RoleIDs: []string{managerRoleID},
+		}, nil)
+		require.Equal(t, http.StatusNoContent, status)
+
+		var body roles.GetConflictViolationsResponse
+		require.Equal(t, http.StatusOK, sendAsUser(t, "enterprise_1", "GET", "/roles/conflict-sets/violations", nil, &body))
+		assert.Empty(t, body.Violations)
+	})
+
+	t.Run("viewers can't see or manage sets", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, sendAsUser(t, "enterprise_7", "GET", "/roles/conflict-sets", nil, nil))
+		assert.Equal(t, http.StatusForbidden, sendAsUser(t, "enterprise_7", "POST", "/roles/conflict-sets/"+set.ID+"/delete", nil, nil))
+	})
+
+	t.Run("deleting the set lifts the restriction", func(t *testing.T) {
+		require.Equal(t, http.StatusNoContent, sendAsUser(t, "enterprise_1", "POST", "/roles/conflict-sets/"+set.ID+"/delete", nil, nil))
+		assert.Equal(t, http.StatusNotFound, sendAsUser(t, "enterprise_1", "POST", "/roles/conflict-sets/"+set.ID+"/delete", nil, nil))
+
+		status := sendAsUser(t, "enterprise_1", "POST", "/users/"+setup.TestUsersData["enterprise_2"].UserID+"/roles", users.UpdateUserRolesRequestBody{
+			RoleIDs: []string{editorRoleID, managerRoleID},
+		}, nil)
+		assert.Equal(t, http.StatusNoContent, status)
+	})
+}
@@ -0,0 +1,168 @@
+package roles
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"lugia/features/roles"
+	"lugia/test/integration/setup"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sendAsUser logs in as userKey, sends the request and decodes a JSON
+// response into out when out is non-nil and the request succeeded.
+func sendAsUser(t *testing.T, userKey, method, path string, body any, out any) int {
+	var reqBody *bytes.Buffer
+	if body != nil {
+		b, err := json.Marshal(body)
+		require.NoError(t, err)
+		reqBody = bytes.NewBuffer(b)
+	} else {
+		reqBody = bytes.NewBuffer(nil)
+	}
+
+	req, err := http.NewRequest(method, setup.BaseURL+path, reqBody)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	loginDetails := setup.TestUsersData[userKey]
+	accessToken, _ := setup.LoginUserAndGetTokens(t, loginDetails.Email, loginDetails.PlainTextPassword)
+	req.AddCookie(&http.Cookie{Name: "dislyze_access_token", Value: accessToken})
+
+	resp, err := (&http.Client{}).Do(req)
+	require.NoError(t, err)
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			t.Logf("Error closing response body: %v", err)
+		}
+	}()
+
+	if out != nil && resp.StatusCode == http.StatusOK {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(out))
+	}
+	return resp.StatusCode
+}
+
+func findRole(t *testing.T, name string) roles.RoleInfo {
+	var body roles.GetRolesResponse
+	require.Equal(t, http.StatusOK, sendAsUser(t, "enterprise_1", "GET", "/roles", nil, &body))
+	for _, r := range body.Roles {
+		if r.Name == name {
+			return r
+		}
+	}
+	t.Fatalf("role %s not found", name)
+	return roles.RoleInfo{}
+}
+
+func TestRoleInclusions_Integration(t *testing.T) {
+	pool := setup.InitDB(t)
+	setup.ResetAndSeedDB(t, pool)
+	defer setup.CloseDB(pool)
+
+	ctx := context.Background()
+	tenantID := setup.TestUsersData["enterprise_2"].TenantID
+
+	// ロール閲覧 <- テナント閲覧 <- ユーザー閲覧: each level adds one permission.
+	require.Equal(t, http.StatusNoContent, sendAsUser(t, "enterprise_1", "POST", "/roles/create", roles.CreateRoleRequestBody{
+		Name:          "ロール閲覧",
+		PermissionIDs: []string{setup.TestPermissionsData["roles_view"].ID},
+	}, nil))
+	base := findRole(t, "ロール閲覧")
+
+	require.Equal(t, http.StatusNoContent, sendAsUser(t, "enterprise_1", "POST", "/roles/create", roles.CreateRoleRequestBody{
+		Name:            "テナント閲覧",
+		PermissionIDs:   []string{setup.TestPermissionsData["tenant_view"].ID},
+		IncludedRoleIDs: []string{base.ID},
+	}, nil))
+	middle := findRole(t, "テナント閲覧")
+
+	require.Equal(t, http.StatusNoContent, sendAsUser(t, "enterprise_1", "POST", "/roles/create", roles.CreateRoleRequestBody{
+		Name:            "ユーザー閲覧",
+		IncludedRoleIDs: []string{middle.ID},
+	}, nil))
+	top := findRole(t, "ユーザー閲覧")
+
+	t.Run("a role needs permissions or included roles", func(t *testing.T) {
+		status := sendAsUser(t, "enterprise_1", "POST", "/roles/create", roles.CreateRoleRequestBody{Name: "空のロール"}, nil)
+		assert.Equal(t, http.StatusUnprocessableEntity, status)
+	})
+
+	t.Run("roles list direct and inherited permissions", func(t *testing.T) {
+		assert.Empty(t, top.Permissions)
+		require.Len(t, top.IncludedRoles, 1)
+		assert.Equal(t, middle.ID, top.IncludedRoles[0].ID)
+
+		inherited := map[string][]string{}
+		for _, p := range top.InheritedPermissions {
+			inherited[p.Resource+"."+p.Action] = p.InheritedFrom
+		}
+		assert.Equal(t, []string{"テナント閲覧"}, inherited["tenant.view"])
+		assert.Equal(t, []string{"ロール閲覧"}, inherited["roles.view"])
+	})
+
+	t.Run("permission checks follow inclusions transitively", func(t *testing.T) {
+		require.Equal(t, http.StatusForbidden, sendAsUser(t, "enterprise_2", "GET", "/roles", nil, nil))
+
+		_, err := pool.Exec(ctx, `INSERT INTO user_roles (user_id, role_id, tenant_id) VALUES ($1, $2, $3)`,
+			setup.TestUsersData["enterprise_2"].UserID, top.ID, tenantID)
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, sendAsUser(t, "enterprise_2", "GET", "/roles", nil, nil))
+	})
+
+	t.Run("an inclusion that would create a cycle is rejected", func(t *testing.T) {
+		status := sendAsUser(t, "enterprise_1", "POST", "/roles/"+base.ID+"/update", roles.UpdateRoleRequestBody{
+			Name:            base.Name,
+			PermissionIDs:   []string{setup.TestPermissionsData["roles_view"].ID},
+			IncludedRoleIDs: &[]string{top.ID},
+		}, nil)
+		assert.Equal(t, http.StatusBadRequest, status)
+
+		status = sendAsUser(t, "enterprise_1", "POST", "/roles/"+base.ID+"/update", roles.UpdateRoleRequestBody{
+			Name:            base.Name,
+			PermissionIDs:   []string{setup.TestPermissionsData["roles_view"].ID},
+			IncludedRoleIDs: &[]string{base.ID},
+		}, nil)
+		assert.Equal(t, http.StatusBadRequest, status, "A role can't include itself")
+	})
+
+	t.Run("roles from another tenant can't be included", func(t *testing.T) {
+		status := sendAsUser(t, "enterprise_1", "POST", "/roles/"+middle.ID+"/update", roles.UpdateRoleRequestBody{
+			Name:            middle.Name,
+			PermissionIDs:   []string{setup.TestPermissionsData["tenant_view"].ID},
+			IncludedRoleIDs: &[]string{setup.TestRolesData["smb_admin"].ID},
+		}, nil)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+
+	t.Run("an included role can't be deleted", func(t *testing.T) {
+		status := sendAsUser(t, "enterprise_1", "POST", "/roles/"+base.ID+"/delete", nil, nil)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+
+	t.Run("an update without included roles keeps them", func(t *testing.T) {
+		status := sendAsUser(t, "enterprise_1", "POST", "/roles/"+middle.ID+"/update", roles.UpdateRoleRequestBody{
+			Name:          middle.Name,
+			PermissionIDs: []string{setup.TestPermissionsData["tenant_view"].ID},
+		}, nil)
+		require.Equal(t, http.StatusNoContent, status)
+
+		assert.Equal(t, http.StatusOK, sendAsUser(t, "enterprise_2", "GET", "/roles", nil, nil))
+	})
+
+	t.Run("removing an inclusion revokes what it granted", func(t *testing.T) {
+		status := sendAsUser(t, "enterprise_1", "POST", "/roles/"+middle.ID+"/update", roles.UpdateRoleRequestBody{
+			Name:            middle.Name,
+			PermissionIDs:   []string{setup.TestPermissionsData["tenant_view"].ID},
+			IncludedRoleIDs: &[]string{},
+		}, nil)
+		require.Equal(t, http.StatusNoContent, status)
+
+		assert.Equal(t, http.StatusForbidden, sendAsUser(t, "enterprise_2", "GET", "/roles", nil, nil))
+	})
+}
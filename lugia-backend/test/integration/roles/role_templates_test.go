@@ -0,0 +1,125 @@
+package roles
+
+import (
+	"lugia/features/roles"
+	"lugia/test/integration/setup"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDuplicateRole_Integration(t *testing.T) {
+	pool := setup.InitDB(t)
+	setup.ResetAndSeedDB(t, pool)
+	defer setup.CloseDB(pool)
+
+	editorRoleID := setup.TestRolesData["enterprise_editor"].ID
+	managerRoleID := setup.TestRolesData["enterprise_user_manager"].ID
+
+	require.Equal(t, http.StatusNoContent, sendAsUser(t, "enterprise_1", "POST", "/roles/create", roles.CreateRoleRequestBody{
+		Name:            "複製元",
+		Description:     "複製元の説明",
+		PermissionIDs:   []string{setup.TestPermissionsData["tenant_view"].ID},
+		IncludedRoleIDs: []string{editorRoleID},
+	}, nil))
+	source := findRole(t, "複製元")
+
+	t.Run("copy keeps permissions, inclusions and description", func(t *testing.T) {
+		require.Equal(t, http.StatusNoContent, sendAsUser(t, "enterprise_1", "POST", "/roles/"+source.ID+"/duplicate", roles.DuplicateRoleRequestBody{
+			Name: "  複製先  ",
+		}, nil))
+
+		copied := findRole(t, "複製先")
+		assert.NotEqual(t, source.ID, copied.ID)
+		assert.False(t, copied.IsDefault)
+		assert.Equal(t, source.Description, copied.Description)
+		assert.ElementsMatch(t, source.Permissions, copied.Permissions)
+		require.Len(t, copied.IncludedRoles, 1)
+		assert.Equal(t, editorRoleID, copied.IncludedRoles[0].ID)
+	})
+
+	t.Run("name must be unused", func(t *testing.T) {
+		status := sendAsUser(t, "enterprise_1", "POST", "/roles/"+source.ID+"/duplicate", roles.DuplicateRoleRequestBody{
+			Name: "複製元",
+		}, nil)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+
+	t.Run("roles of another tenant are not found", func(t *testing.T) {
+		status := sendAsUser(t, "enterprise_1", "POST", "/roles/"+setup.TestRolesData["smb_admin"].ID+"/duplicate", roles.DuplicateRoleRequestBody{
+			Name: "他テナントの複製",
+		}, nil)
+		assert.Equal(t, http.StatusNotFound, status)
+	})
+
+	t.Run("copy joins the source's conflict sets", func(t *testing.T) {
+		require.Equal(t, http.StatusNoContent, sendAsUser(t, "enterprise_1", "POST", "/roles/conflict-sets/create", roles.CreateConflictSetRequestBody{
+			Name:    "編集と承認の分離",
+			RoleIDs: []string{editorRoleID, managerRoleID},
+		}, nil))
+		require.Equal(t, http.StatusNoContent, sendAsUser(t, "enterprise_1", "POST", "/roles/"+managerRoleID+"/duplicate", roles.DuplicateRoleRequestBody{
+			Name: "ユーザー管理の複製",
+		}, nil))
+		copied := findRole(t, "ユーザー管理の複製")
+
+		var sets roles.GetConflictSetsResponse
+		require.Equal(t, http.StatusOK, sendAsUser(t, "enterprise_1", "GET", "/roles/conflict-sets", nil, &sets))
+		require.Len(t, sets.ConflictSets, 1)
+		roleIDs := []string{}
+		for _, r := range sets.ConflictSets[0].Roles {
+			roleIDs = append(roleIDs, r.ID)
+		}
+		assert.Contains(t, roleIDs, copied.ID)
+	})
+
+	t.Run("viewers can't duplicate", func(t *testing.T) {
+		status := sendAsUser(t, "enterprise_7", "POST", "/roles/"+source.ID+"/duplicate", roles.DuplicateRoleRequestBody{
+			Name: "閲覧者の複製",
+		}, nil)
+		assert.Equal(t, http.StatusForbidden, status)
+	})
+}
+
+func TestRoleTemplates_Integration(t *testing.T) {
+	pool := setup.InitDB(t)
+	setup.ResetAndSeedDB(t, pool)
+	defer setup.CloseDB(pool)
+
+	var body roles.GetRoleTemplatesResponse
+	require.Equal(t, http.StatusOK, sendAsUser(t, "enterprise_1", "GET", "/roles/templates", nil, &body))
+	keys := []string{}
+	for _, template := range body.Templates {
+		keys = append(keys, template.Key)
+	}
+	assert.ElementsMatch(t, []string{"readonly_auditor", "security_admin", "helpdesk"}, keys)
+
+	t.Run("template name is used by default", func(t *testing.T) {
+		require.Equal(t, http.StatusNoContent, sendAsUser(t, "enterprise_1", "POST", "/roles/templates/helpdesk/create", roles.CreateRoleFromTemplateRequestBody{}, nil))
+
+		role := findRole(t, "ヘルプデスク")
+		assert.False(t, role.IsDefault)
+		assert.Len(t, role.Permissions, 3)
+	})
+
+	t.Run("a custom name avoids the collision", func(t *testing.T) {
+		status := sendAsUser(t, "enterprise_1", "POST", "/roles/templates/helpdesk/create", roles.CreateRoleFromTemplateRequestBody{}, nil)
+		assert.Equal(t, http.StatusBadRequest, status)
+
+		require.Equal(t, http.StatusNoContent, sendAsUser(t, "enterprise_1", "POST", "/roles/templates/helpdesk/create", roles.CreateRoleFromTemplateRequestBody{
+			Name: "ヘルプデスク（夜間）",
+		}, nil))
+		findRole(t, "ヘルプデスク（夜間）")
+	})
+
+	t.Run("unknown template is not found", func(t *testing.T) {
+		status := sendAsUser(t, "enterprise_1", "POST", "/roles/templates/no_such_template/create", roles.CreateRoleFromTemplateRequestBody{}, nil)
+		assert.Equal(t, http.StatusNotFound, status)
+	})
+
+	t.Run("viewers can't list or create", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, sendAsUser(t, "enterprise_7", "GET", "/roles/templates", nil, nil))
+		assert.Equal(t, http.StatusForbidden, sendAsUser(t, "enterprise_7", "POST", "/roles/templates/helpdesk/create", roles.CreateRoleFromTemplateRequestBody{}, nil))
+	})
+}
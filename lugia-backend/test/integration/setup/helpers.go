@@ -3,6 +3,7 @@ package setup
 import (
 	"bytes"
 	"context"
+	"dislyze/jirachi/jwt"
 	"dislyze/jirachi/sendgridlib"
 	"encoding/json"
 	"fmt"
@@ -14,6 +15,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/stretchr/testify/assert"
 )
@@ -154,6 +156,29 @@ func LoginUserAndGetTokens(t *testing.T, email string, password string) (string,
 	return accessTokenValue, refreshTokenValue
 }
 
+// IssueAccessToken signs an access token for a seeded user in their home
+// tenant without logging in. Login enforces the IP whitelist, so tests of the
+// whitelist middleware use this to hold a session that reaches it from
+// addresses the whitelist blocks.
+func IssueAccessToken(t *testing.T, email string) string {
+	t.Helper()
+
+	for _, user := range TestUsersData {
+		if user.Email != email {
+			continue
+		}
+		var userID, tenantID pgtype.UUID
+		assert.NoError(t, userID.Scan(user.UserID))
+		assert.NoError(t, tenantID.Scan(user.TenantID))
+		accessToken, _, _, err := jwt.GenerateAccessToken(userID, tenantID, []byte(os.Getenv("AUTH_JWT_SECRET")))
+		assert.NoError(t, err, "Failed to sign access token for %s", email)
+		return accessToken
+	}
+
+	t.Fatalf("No seeded user with email %s", email)
+	return ""
+}
+
 func GetLatestEmailFromSendgridMock(t *testing.T, expectedRecipientEmail string) (*sendgridlib.SendGridMailRequestBody, error) {
 	t.Helper()
 	sendgridAPIURL := os.Getenv("SENDGRID_API_URL")
@@ -137,6 +137,24 @@ var TestPermissionsData = map[string]PermissionTestData{
 		Action:      "edit",
 		Description: "ユーザーの編集",
 	},
+	"users_invite": {
+		ID:          "0c5e6f1a-2b3c-4d5e-8f90-a1b2c3d4e5f6",
+		Resource:    "users",
+		Action:      "invite",
+		Description: "ユーザーの招待",
+	},
+	"users_delete": {
+		ID:          "1d6f7a2b-3c4d-4e5f-9a01-b2c3d4e5f6a7",
+		Resource:    "users",
+		Action:      "delete",
+		Description: "ユーザーの削除",
+	},
+	"users_assign_roles": {
+		ID:          "2e7a8b3c-4d5e-4f60-8b12-c3d4e5f6a7b8",
+		Resource:    "users",
+		Action:      "assign_roles",
+		Description: "ユーザーへのロール割り当て",
+	},
 	"roles_view": {
 		ID:          "44b8962d-5dc5-490e-8469-03078668dd52",
 		Resource:    "roles",
@@ -161,6 +179,12 @@ var TestPermissionsData = map[string]PermissionTestData{
 		Action:      "edit",
 		Description: "IP制限画面の編集",
 	},
+	"ip_whitelist_emergency": {
+		ID:          "3f8b9c4d-5e6f-4a71-9c23-d4e5f6a7b8c9",
+		Resource:    "ip_whitelist",
+		Action:      "emergency",
+		Description: "IP制限の緊急解除",
+	},
 	"audit_log_view": {
 		ID:          "b1c2d3e4-f5a6-b7c8-d9e0-f1a2b3c4d5e6",
 		Resource:    "audit_log",
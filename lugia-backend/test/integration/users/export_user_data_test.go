@@ -0,0 +1,159 @@
+package users
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"lugia/features/users"
+	"lugia/test/integration/setup"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportUserData_Integration(t *testing.T) {
+	pool := setup.InitDB(t)
+	setup.ResetAndSeedDB(t, pool)
+	defer setup.CloseDB(pool)
+
+	target := setup.TestUsersData["enterprise_7"]
+	// Logging the target in creates a session and a login audit entry to export.
+	setup.LoginUserAndGetTokens(t, target.Email, target.PlainTextPassword)
+
+	tests := []struct {
+		name           string
+		loginUserKey   string
+		path           string
+		expectedStatus int
+		validateFunc   func(t *testing.T, export users.UserDataExport)
+	}{
+		{
+			name:           "unauthenticated request returns 401",
+			path:           "/users/" + target.UserID + "/export",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "user without users.edit permission gets 403",
+			loginUserKey:   "enterprise_2",
+			path:           "/users/" + target.UserID + "/export",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "invalid user ID returns 400",
+			loginUserKey:   "enterprise_1",
+			path:           "/users/not-a-uuid/export",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "non-existent user returns 404",
+			loginUserKey:   "enterprise_1",
+			path:           "/users/99999999-9999-9999-9999-999999999999/export",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "user in another tenant returns 403",
+			loginUserKey:   "enterprise_1",
+			path:           "/users/" + setup.TestUsersData["smb_1"].UserID + "/export",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "admin can export a user's data",
+			loginUserKey:   "enterprise_1",
+			path:           "/users/" + target.UserID + "/export",
+			expectedStatus: http.StatusOK,
+			validateFunc: func(t *testing.T, export users.UserDataExport) {
+				assert.Equal(t, target.UserID, export.Profile.ID)
+				assert.Equal(t, target.Email, export.Profile.Email)
+				assert.NotNil(t, export.Profile.LastLoginAt)
+				require.Len(t, export.Roles, 1)
+				assert.Equal(t, setup.TestRolesData["enterprise_viewer"].ID, export.Roles[0].ID)
+				assert.NotEmpty(t, export.Sessions)
+
+				hasLogin := false
+				for _, entry := range export.AuditLogs {
+					assert.True(t, entry.ActorID == target.UserID || (entry.ResourceID != nil && *entry.ResourceID == target.UserID),
+						"Audit entry %s should involve the exported user", entry.ID)
+					if entry.Action == "login" {
+						hasLogin = true
+					}
+				}
+				assert.True(t, hasLogin, "Export should include the user's login audit entry")
+			},
+		},
+		{
+			name:           "user can export their own data via /me",
+			loginUserKey:   "enterprise_7",
+			path:           "/me/export",
+			expectedStatus: http.StatusOK,
+			validateFunc: func(t *testing.T, export users.UserDataExport) {
+				assert.Equal(t, target.UserID, export.Profile.ID)
+				assert.NotEmpty(t, export.Sessions)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", setup.BaseURL+tt.path, nil)
+			require.NoError(t, err)
+
+			if tt.loginUserKey != "" {
+				loginDetails := setup.TestUsersData[tt.loginUserKey]
+				accessToken, _ := setup.LoginUserAndGetTokens(t, loginDetails.Email, loginDetails.PlainTextPassword)
+				req.AddCookie(&http.Cookie{Name: "dislyze_access_token", Value: accessToken})
+			}
+
+			resp, err := (&http.Client{}).Do(req)
+			require.NoError(t, err)
+			defer func() {
+				if err := resp.Body.Close(); err != nil {
+					t.Logf("Error closing response body: %v", err)
+				}
+			}()
+
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+
+			if resp.StatusCode == http.StatusOK {
+				assert.Regexp(t, `^attachment; filename="user-data-`+target.UserID+`-\d{4}-\d{2}-\d{2}\.json"$`,
+					resp.Header.Get("Content-Disposition"))
+			}
+
+			if tt.validateFunc != nil && resp.StatusCode == http.StatusOK {
+				var export users.UserDataExport
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&export))
+				tt.validateFunc(t, export)
+			}
+		})
+	}
+}
+
+func TestExportUserData_WritesAuditLog_Integration(t *testing.T) {
+	pool := setup.InitDB(t)
+	setup.ResetAndSeedDB(t, pool)
+	defer setup.CloseDB(pool)
+
+	admin := setup.TestUsersData["enterprise_1"]
+	target := setup.TestUsersData["enterprise_7"]
+	accessToken, _ := setup.LoginUserAndGetTokens(t, admin.Email, admin.PlainTextPassword)
+
+	for i := 0; i < 2; i++ {
+		req, err := http.NewRequest("GET", fmt.Sprintf("%s/users/%s/export", setup.BaseURL, target.UserID), nil)
+		require.NoError(t, err)
+		req.AddCookie(&http.Cookie{Name: "dislyze_access_token", Value: accessToken})
+
+		resp, err := (&http.Client{}).Do(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		_ = resp.Body.Close()
+	}
+
+	var count int
+	err := pool.QueryRow(context.Background(),
+		`SELECT COUNT(*) FROM audit_logs
+		 WHERE actor_id = $1 AND resource_type = 'user' AND action = 'data_exported' AND resource_id = $2`,
+		admin.UserID, target.UserID).Scan(&count)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count, "Each export should write its own audit entry")
+}
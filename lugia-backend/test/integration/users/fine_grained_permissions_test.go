@@ -0,0 +1,74 @@
+package users
+
+import (
+	"context"
+	"lugia/features/users"
+	"lugia/test/integration/setup"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFineGrainedUserPermissions_Integration(t *testing.T) {
+	pool := setup.InitDB(t)
+	setup.ResetAndSeedDB(t, pool)
+	defer setup.CloseDB(pool)
+
+	ctx := context.Background()
+	inviter := setup.TestUsersData["enterprise_8"]
+	target := setup.TestUsersData["enterprise_9"]
+
+	// A custom role that may only invite; "view" is implied by "invite".
+	var roleID string
+	err := pool.QueryRow(ctx, `INSERT INTO roles (tenant_id, name, description, is_default) VALUES ($1, '招待担当', '', false) RETURNING id::text`,
+		inviter.TenantID).Scan(&roleID)
+	require.NoError(t, err)
+	_, err = pool.Exec(ctx, `INSERT INTO role_permissions (role_id, permission_id, tenant_id) VALUES ($1, $2, $3)`,
+		roleID, setup.TestPermissionsData["users_invite"].ID, inviter.TenantID)
+	require.NoError(t, err)
+	_, err = pool.Exec(ctx, `DELETE FROM user_roles WHERE user_id = $1 AND tenant_id = $2`, inviter.UserID, inviter.TenantID)
+	require.NoError(t, err)
+	_, err = pool.Exec(ctx, `INSERT INTO user_roles (user_id, role_id, tenant_id) VALUES ($1, $2, $3)`, inviter.UserID, roleID, inviter.TenantID)
+	require.NoError(t, err)
+
+	t.Run("users.invite allows inviting", func(t *testing.T) {
+		status, _ := postAsUser(t, "enterprise_8", "/users/invite", users.InviteUserRequestBody{
+			Email:   "fine-grained-invitee@localhost.com",
+			Name:    "招待 太郎",
+			RoleIDs: []string{setup.TestRolesData["enterprise_viewer"].ID},
+		})
+		assert.Equal(t, http.StatusNoContent, status)
+	})
+
+	t.Run("users.invite implies users.view", func(t *testing.T) {
+		accessToken, _ := setup.LoginUserAndGetTokens(t, inviter.Email, inviter.PlainTextPassword)
+		resp := doWithTokens(t, "GET", "/users", accessToken, "", nil)
+		defer func() { _ = resp.Body.Close() }()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("users.invite does not allow deleting", func(t *testing.T) {
+		status, _ := postAsUser(t, "enterprise_8", "/users/"+target.UserID+"/delete", nil)
+		assert.Equal(t, http.StatusForbidden, status)
+	})
+
+	t.Run("users.invite does not allow assigning roles", func(t *testing.T) {
+		status, _ := postAsUser(t, "enterprise_8", "/users/"+target.UserID+"/roles",
+			users.UpdateUserRolesRequestBody{RoleIDs: []string{setup.TestRolesData["enterprise_admin"].ID}})
+		assert.Equal(t, http.StatusForbidden, status)
+	})
+
+	t.Run("existing administrators keep every user action", func(t *testing.T) {
+		var granted int
+		err := pool.QueryRow(ctx, `
+			SELECT COUNT(*) FROM role_permissions
+			JOIN permissions ON permissions.id = role_permissions.permission_id
+			WHERE role_permissions.role_id = $1 AND permissions.resource = 'users'
+			  AND permissions.action IN ('invite', 'delete', 'assign_roles')`,
+			setup.TestRolesData["enterprise_admin"].ID).Scan(&granted)
+		require.NoError(t, err)
+		assert.Equal(t, 3, granted)
+	})
+}
@@ -0,0 +1,121 @@
+package users
+
+import (
+	"context"
+	"encoding/json"
+	"lugia/features/users"
+	"lugia/test/integration/setup"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getUserPermissionsAs(t *testing.T, loginUserKey, targetUserKey, query string) (int, *users.GetUserPermissionsResponse) {
+	login := setup.TestUsersData[loginUserKey]
+	accessToken, _ := setup.LoginUserAndGetTokens(t, login.Email, login.PlainTextPassword)
+
+	resp := doWithTokens(t, "GET", "/users/"+setup.TestUsersData[targetUserKey].UserID+"/permissions"+query, accessToken, "", nil)
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, nil
+	}
+
+	var body users.GetUserPermissionsResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	return resp.StatusCode, &body
+}
+
+func findEffectivePermission(permissions []users.EffectivePermission, resource, action string) *users.EffectivePermission {
+	for i := range permissions {
+		if permissions[i].Resource == resource && permissions[i].Action == action {
+			return &permissions[i]
+		}
+	}
+	return nil
+}
+
+func TestGetUserPermissions_Integration(t *testing.T) {
+	pool := setup.InitDB(t)
+	setup.ResetAndSeedDB(t, pool)
+	defer setup.CloseDB(pool)
+
+	ctx := context.Background()
+
+	t.Run("lists each permission with the roles that grant it", func(t *testing.T) {
+		status, body := getUserPermissionsAs(t, "enterprise_1", "enterprise_1", "")
+		require.Equal(t, http.StatusOK, status)
+
+		assert.True(t, body.RBACEnabled)
+		assert.False(t, body.FallbackInEffect)
+		assert.Nil(t, body.Check)
+
+		invite := findEffectivePermission(body.Permissions, "users", "invite")
+		require.NotNil(t, invite)
+		require.Len(t, invite.GrantedBy, 1)
+		assert.Equal(t, setup.TestRolesData["enterprise_admin"].ID, invite.GrantedBy[0].ID)
+		assert.False(t, invite.GrantedBy[0].ViaFallback)
+	})
+
+	t.Run("check mode explains an implied view", func(t *testing.T) {
+		status, body := getUserPermissionsAs(t, "enterprise_1", "enterprise_1", "?resource=users&action=view")
+		require.Equal(t, http.StatusOK, status)
+		require.NotNil(t, body.Check)
+		assert.True(t, body.Check.Allowed)
+		assert.Equal(t, "view_implied", body.Check.Reason)
+	})
+
+	t.Run("check mode explains a denial", func(t *testing.T) {
+		status, body := getUserPermissionsAs(t, "enterprise_1", "enterprise_7", "?resource=users&action=invite")
+		require.Equal(t, http.StatusOK, status)
+		require.NotNil(t, body.Check)
+		assert.False(t, body.Check.Allowed)
+		assert.Equal(t, "not_granted", body.Check.Reason)
+		assert.Empty(t, body.Check.GrantedBy)
+	})
+
+	t.Run("unknown permission in check mode is a bad request", func(t *testing.T) {
+		status, _ := getUserPermissionsAs(t, "enterprise_1", "enterprise_1", "?resource=users&action=fly")
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+
+	t.Run("user from another tenant is not found", func(t *testing.T) {
+		status, _ := getUserPermissionsAs(t, "enterprise_1", "smb_1", "")
+		assert.Equal(t, http.StatusNotFound, status)
+	})
+
+	t.Run("requires users view", func(t *testing.T) {
+		status, _ := getUserPermissionsAs(t, "enterprise_7", "enterprise_1", "")
+		assert.Equal(t, http.StatusForbidden, status)
+	})
+
+	t.Run("reports the RBAC-disabled fallback", func(t *testing.T) {
+		target := setup.TestUsersData["smb_8"]
+
+		var customRoleID string
+		err := pool.QueryRow(ctx, `INSERT INTO roles (tenant_id, name, description, is_default) VALUES ($1, 'SMBカスタム', '', false) RETURNING id::text`,
+			target.TenantID).Scan(&customRoleID)
+		require.NoError(t, err)
+		_, err = pool.Exec(ctx, `INSERT INTO role_permissions (role_id, permission_id, tenant_id) VALUES ($1, $2, $3)`,
+			customRoleID, setup.TestPermissionsData["users_edit"].ID, target.TenantID)
+		require.NoError(t, err)
+		_, err = pool.Exec(ctx, `DELETE FROM user_roles WHERE user_id = $1 AND tenant_id = $2`, target.UserID, target.TenantID)
+		require.NoError(t, err)
+		_, err = pool.Exec(ctx, `INSERT INTO user_roles (user_id, role_id, tenant_id) VALUES ($1, $2, $3)`, target.UserID, customRoleID, target.TenantID)
+		require.NoError(t, err)
+
+		status, body := getUserPermissionsAs(t, "smb_1", "smb_8", "?resource=users&action=edit")
+		require.Equal(t, http.StatusOK, status)
+
+		assert.False(t, body.RBACEnabled)
+		assert.True(t, body.FallbackInEffect)
+		require.Len(t, body.IgnoredRoles, 1)
+		assert.Equal(t, customRoleID, body.IgnoredRoles[0].ID)
+		assert.Nil(t, findEffectivePermission(body.Permissions, "users", "edit"), "Ignored custom role must not contribute permissions")
+
+		require.NotNil(t, body.Check)
+		assert.False(t, body.Check.Allowed)
+		assert.Equal(t, "not_granted", body.Check.Reason)
+	})
+}
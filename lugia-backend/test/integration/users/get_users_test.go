@@ -633,3 +633,196 @@ func TestGetUsersInvalidParameters_Integration(t *testing.T) {
 		})
 	}
 }
+
+func fetchUsers(t *testing.T, accessToken string, queryParams string) (int, users.GetUsersResponse) {
+	t.Helper()
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/users?%s", setup.BaseURL, queryParams), nil)
+	assert.NoError(t, err)
+	req.AddCookie(&http.Cookie{
+		Name:  "dislyze_access_token",
+		Value: accessToken,
+		Path:  "/",
+	})
+
+	resp, err := (&http.Client{}).Do(req)
+	assert.NoError(t, err)
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			t.Logf("Error closing response body: %v", err)
+		}
+	}()
+
+	var usersResponse users.GetUsersResponse
+	if resp.StatusCode == http.StatusOK {
+		err = json.NewDecoder(resp.Body).Decode(&usersResponse)
+		assert.NoError(t, err)
+	}
+	return resp.StatusCode, usersResponse
+}
+
+func TestGetUsersFilters_Integration(t *testing.T) {
+	pool := setup.InitDB(t)
+	setup.ResetAndSeedDB(t, pool)
+	defer setup.CloseDB(pool)
+
+	loginDetails := setup.TestUsersData["enterprise_1"]
+	accessToken, _ := setup.LoginUserAndGetTokens(t, loginDetails.Email, loginDetails.PlainTextPassword)
+
+	tests := []struct {
+		name           string
+		queryParams    string
+		expectedStatus int
+		validateFunc   func(t *testing.T, response users.GetUsersResponse)
+	}{
+		{
+			name:           "status filter returns only matching users",
+			queryParams:    "status=pending_verification&limit=100",
+			expectedStatus: http.StatusOK,
+			validateFunc: func(t *testing.T, response users.GetUsersResponse) {
+				assert.Greater(t, len(response.Users), 0)
+				for _, user := range response.Users {
+					assert.Equal(t, "pending_verification", user.Status)
+				}
+				assert.Equal(t, len(response.Users), response.Pagination.Total)
+			},
+		},
+		{
+			name:           "role filter returns only users assigned the role",
+			queryParams:    "role_id=" + setup.TestRolesData["enterprise_admin"].ID + "&limit=100",
+			expectedStatus: http.StatusOK,
+			validateFunc: func(t *testing.T, response users.GetUsersResponse) {
+				assert.Greater(t, len(response.Users), 0)
+				for _, user := range response.Users {
+					hasRole := false
+					for _, role := range user.Roles {
+						if role.ID == setup.TestRolesData["enterprise_admin"].ID {
+							hasRole = true
+						}
+					}
+					assert.True(t, hasRole, "User %s should have the admin role", user.Email)
+				}
+			},
+		},
+		{
+			name:           "sso_linked=true returns no users for a password tenant",
+			queryParams:    "sso_linked=true",
+			expectedStatus: http.StatusOK,
+			validateFunc: func(t *testing.T, response users.GetUsersResponse) {
+				assert.Equal(t, 0, len(response.Users))
+				assert.Equal(t, 0, response.Pagination.Total)
+			},
+		},
+		{
+			name:           "last_login_from only returns users who logged in",
+			queryParams:    "last_login_from=2000-01-01T00:00:00Z&limit=100",
+			expectedStatus: http.StatusOK,
+			validateFunc: func(t *testing.T, response users.GetUsersResponse) {
+				assert.Len(t, response.Users, 1, "Only the logged-in user has a last login")
+				assert.Equal(t, loginDetails.Email, response.Users[0].Email)
+				assert.NotNil(t, response.Users[0].LastLoginAt)
+			},
+		},
+		{
+			name:           "created_to in the past returns no users",
+			queryParams:    "created_to=2000-01-01T00:00:00Z",
+			expectedStatus: http.StatusOK,
+			validateFunc: func(t *testing.T, response users.GetUsersResponse) {
+				assert.Equal(t, 0, len(response.Users))
+			},
+		},
+		{
+			name:           "invalid status returns 422",
+			queryParams:    "status=deleted",
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "invalid role_id returns 400",
+			queryParams:    "role_id=not-a-uuid",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid created_from returns 400",
+			queryParams:    "created_from=yesterday",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid sort returns 422",
+			queryParams:    "sort=password_hash",
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, response := fetchUsers(t, accessToken, tt.queryParams)
+			assert.Equal(t, tt.expectedStatus, status)
+			if tt.validateFunc != nil && status == http.StatusOK {
+				tt.validateFunc(t, response)
+			}
+		})
+	}
+}
+
+func TestGetUsersKeysetPagination_Integration(t *testing.T) {
+	pool := setup.InitDB(t)
+	setup.ResetAndSeedDB(t, pool)
+	defer setup.CloseDB(pool)
+
+	loginDetails := setup.TestUsersData["enterprise_1"]
+	accessToken, _ := setup.LoginUserAndGetTokens(t, loginDetails.Email, loginDetails.PlainTextPassword)
+
+	_, all := fetchUsers(t, accessToken, "sort=name&order=asc&limit=100")
+	assert.Greater(t, len(all.Users), 3, "Need several users to exercise keyset pagination")
+	assert.Nil(t, all.NextCursor, "A single page holding every user has no next cursor")
+
+	t.Run("walking cursors visits every user once in sort order", func(t *testing.T) {
+		var walked []string
+		query := "sort=name&order=asc&limit=3"
+		for i := 0; i < 100; i++ {
+			status, page := fetchUsers(t, accessToken, query)
+			assert.Equal(t, http.StatusOK, status)
+			for _, user := range page.Users {
+				walked = append(walked, user.ID)
+			}
+			if page.NextCursor == nil {
+				break
+			}
+			assert.Len(t, page.Users, 3)
+			query = "sort=name&order=asc&limit=3&cursor=" + *page.NextCursor
+		}
+
+		expected := make([]string, len(all.Users))
+		for i, user := range all.Users {
+			expected[i] = user.ID
+		}
+		assert.Equal(t, expected, walked)
+	})
+
+	t.Run("cursor from a different sort is rejected", func(t *testing.T) {
+		_, page := fetchUsers(t, accessToken, "sort=name&order=asc&limit=1")
+		assert.NotNil(t, page.NextCursor)
+
+		status, _ := fetchUsers(t, accessToken, "sort=email&order=asc&limit=1&cursor="+*page.NextCursor)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+
+	t.Run("malformed cursor is rejected", func(t *testing.T) {
+		status, _ := fetchUsers(t, accessToken, "cursor=garbage")
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+
+	t.Run("sorting by last login puts the logged-in user first", func(t *testing.T) {
+		status, page := fetchUsers(t, accessToken, "sort=last_login_at&order=desc&limit=2")
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, loginDetails.Email, page.Users[0].Email)
+		assert.NotNil(t, page.NextCursor)
+
+		status, next := fetchUsers(t, accessToken, "sort=last_login_at&order=desc&limit=2&cursor="+*page.NextCursor)
+		assert.Equal(t, http.StatusOK, status)
+		for _, user := range next.Users {
+			assert.NotEqual(t, page.Users[0].ID, user.ID)
+			assert.NotEqual(t, page.Users[1].ID, user.ID)
+		}
+	})
+}
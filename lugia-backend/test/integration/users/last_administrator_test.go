@@ -0,0 +1,136 @@
+package users
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"lugia/features/roles"
+	"lugia/features/users"
+	"lugia/lib/authz"
+	"lugia/test/integration/setup"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func postAsUser(t *testing.T, userKey, path string, body any) (int, map[string]string) {
+	var reqBody *bytes.Buffer
+	if body != nil {
+		b, err := json.Marshal(body)
+		require.NoError(t, err)
+		reqBody = bytes.NewBuffer(b)
+	} else {
+		reqBody = bytes.NewBuffer(nil)
+	}
+
+	req, err := http.NewRequest("POST", setup.BaseURL+path, reqBody)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	loginDetails := setup.TestUsersData[userKey]
+	accessToken, _ := setup.LoginUserAndGetTokens(t, loginDetails.Email, loginDetails.PlainTextPassword)
+	req.AddCookie(&http.Cookie{Name: "dislyze_access_token", Value: accessToken})
+
+	resp, err := (&http.Client{}).Do(req)
+	require.NoError(t, err)
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			t.Logf("Error closing response body: %v", err)
+		}
+	}()
+
+	errorResp := map[string]string{}
+	_ = json.NewDecoder(resp.Body).Decode(&errorResp)
+	return resp.StatusCode, errorResp
+}
+
+func TestLastAdministratorProtection_Integration(t *testing.T) {
+	pool := setup.InitDB(t)
+	setup.ResetAndSeedDB(t, pool)
+	defer setup.CloseDB(pool)
+
+	ctx := context.Background()
+	tenantID := setup.TestUsersData["enterprise_1"].TenantID
+	admin := setup.TestUsersData["enterprise_1"]
+	manager := setup.TestUsersData["enterprise_2"]
+	adminRoleID := setup.TestRolesData["enterprise_admin"].ID
+
+	// enterprise_2 can edit users but not roles, so enterprise_1 is the only
+	// active user left who can edit roles.
+	status, _ := postAsUser(t, "enterprise_1", "/users/"+manager.UserID+"/roles",
+		users.UpdateUserRolesRequestBody{RoleIDs: []string{setup.TestRolesData["enterprise_user_manager"].ID}})
+	require.Equal(t, http.StatusNoContent, status)
+
+	t.Run("removing roles edit from the last holder is refused", func(t *testing.T) {
+		status, errorResp := postAsUser(t, "enterprise_2", "/users/"+admin.UserID+"/roles",
+			users.UpdateUserRolesRequestBody{RoleIDs: []string{setup.TestRolesData["enterprise_user_manager"].ID}})
+		assert.Equal(t, http.StatusConflict, status)
+		assert.Equal(t, authz.LastAdministratorDetail, errorResp["error"])
+
+		var hasAdminRole bool
+		err := pool.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM user_roles WHERE user_id = $1 AND role_id = $2)`,
+			admin.UserID, adminRoleID).Scan(&hasAdminRole)
+		require.NoError(t, err)
+		assert.True(t, hasAdminRole, "Refused change must be rolled back")
+	})
+
+	t.Run("deleting the last holder is refused", func(t *testing.T) {
+		status, errorResp := postAsUser(t, "enterprise_2", "/users/"+admin.UserID+"/delete", nil)
+		assert.Equal(t, http.StatusConflict, status)
+		assert.Equal(t, authz.LastAdministratorDetail, errorResp["error"])
+
+		var deleted bool
+		err := pool.QueryRow(ctx, `SELECT deleted_at IS NOT NULL FROM users WHERE id = $1`, admin.UserID).Scan(&deleted)
+		require.NoError(t, err)
+		assert.False(t, deleted)
+	})
+
+	// Give enterprise_2 a custom role carrying both permissions, so the admin
+	// role can go and the custom role becomes the last source of roles edit.
+	var superRoleID string
+	err := pool.QueryRow(ctx,
+		`INSERT INTO roles (tenant_id, name, description, is_default) VALUES ($1, 'スーパー管理者', '', false) RETURNING id`,
+		tenantID).Scan(&superRoleID)
+	require.NoError(t, err)
+	for _, permKey := range []string{"users_view", "users_edit", "roles_view", "roles_edit"} {
+		_, err := pool.Exec(ctx, `INSERT INTO role_permissions (role_id, permission_id, tenant_id) VALUES ($1, $2, $3)`,
+			superRoleID, setup.TestPermissionsData[permKey].ID, tenantID)
+		require.NoError(t, err)
+	}
+	_, err = pool.Exec(ctx, `INSERT INTO user_roles (user_id, role_id, tenant_id) VALUES ($1, $2, $3)`,
+		manager.UserID, superRoleID, tenantID)
+	require.NoError(t, err)
+
+	t.Run("removing the admin role is allowed while another holder remains", func(t *testing.T) {
+		status, _ := postAsUser(t, "enterprise_2", "/users/"+admin.UserID+"/roles",
+			users.UpdateUserRolesRequestBody{RoleIDs: []string{setup.TestRolesData["enterprise_user_manager"].ID}})
+		assert.Equal(t, http.StatusNoContent, status)
+	})
+
+	t.Run("stripping roles edit from the last role granting it is refused", func(t *testing.T) {
+		status, errorResp := postAsUser(t, "enterprise_2", "/roles/"+superRoleID+"/update", roles.UpdateRoleRequestBody{
+			Name:          "スーパー管理者",
+			PermissionIDs: []string{setup.TestPermissionsData["users_edit"].ID},
+		})
+		assert.Equal(t, http.StatusConflict, status)
+		assert.Equal(t, authz.LastAdministratorDetail, errorResp["error"])
+
+		var permCount int
+		err := pool.QueryRow(ctx, `SELECT COUNT(*) FROM role_permissions WHERE role_id = $1`, superRoleID).Scan(&permCount)
+		require.NoError(t, err)
+		assert.Equal(t, 4, permCount, "Refused change must be rolled back")
+	})
+
+	t.Run("updating the role while keeping both permissions is allowed", func(t *testing.T) {
+		status, _ := postAsUser(t, "enterprise_2", "/roles/"+superRoleID+"/update", roles.UpdateRoleRequestBody{
+			Name: "スーパー管理者",
+			PermissionIDs: []string{
+				setup.TestPermissionsData["users_edit"].ID,
+				setup.TestPermissionsData["roles_edit"].ID,
+			},
+		})
+		assert.Equal(t, http.StatusNoContent, status)
+	})
+}
@@ -0,0 +1,119 @@
+package users
+
+import (
+	"context"
+	"lugia/features/users"
+	"lugia/lib/maintenance"
+	"lugia/queries"
+	"lugia/test/integration/setup"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoleExpiry_Integration(t *testing.T) {
+	pool := setup.InitDB(t)
+	setup.ResetAndSeedDB(t, pool)
+	defer setup.CloseDB(pool)
+
+	ctx := context.Background()
+	target := setup.TestUsersData["enterprise_8"]
+	managerRoleID := setup.TestRolesData["enterprise_user_manager"].ID
+
+	t.Run("past expiry is rejected", func(t *testing.T) {
+		status, _ := postAsUser(t, "enterprise_1", "/users/"+target.UserID+"/roles", users.UpdateUserRolesRequestBody{
+			RoleIDs:       []string{managerRoleID},
+			RoleExpiresAt: map[string]time.Time{managerRoleID: time.Now().Add(-time.Hour)},
+		})
+		assert.Equal(t, http.StatusUnprocessableEntity, status)
+	})
+
+	t.Run("expiry for a role that is not requested is rejected", func(t *testing.T) {
+		status, _ := postAsUser(t, "enterprise_1", "/users/"+target.UserID+"/roles", users.UpdateUserRolesRequestBody{
+			RoleIDs:       []string{managerRoleID},
+			RoleExpiresAt: map[string]time.Time{setup.TestRolesData["enterprise_admin"].ID: time.Now().Add(time.Hour)},
+		})
+		assert.Equal(t, http.StatusUnprocessableEntity, status)
+	})
+
+	t.Run("time-bound role grants access until it expires", func(t *testing.T) {
+		status, _ := postAsUser(t, "enterprise_1", "/users/"+target.UserID+"/roles", users.UpdateUserRolesRequestBody{
+			RoleIDs:       []string{managerRoleID},
+			RoleExpiresAt: map[string]time.Time{managerRoleID: time.Now().Add(time.Hour)},
+		})
+		require.Equal(t, http.StatusNoContent, status)
+
+		var grantedBy string
+		err := pool.QueryRow(ctx, `SELECT granted_by::text FROM user_roles WHERE user_id = $1 AND role_id = $2 AND expires_at IS NOT NULL`,
+			target.UserID, managerRoleID).Scan(&grantedBy)
+		require.NoError(t, err)
+		assert.Equal(t, setup.TestUsersData["enterprise_1"].UserID, grantedBy)
+
+		accessToken, _ := setup.LoginUserAndGetTokens(t, target.Email, target.PlainTextPassword)
+		resp := doWithTokens(t, "GET", "/users", accessToken, "", nil)
+		_ = resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		_, err = pool.Exec(ctx, `UPDATE user_roles SET expires_at = CURRENT_TIMESTAMP - INTERVAL '1 minute' WHERE user_id = $1 AND role_id = $2`,
+			target.UserID, managerRoleID)
+		require.NoError(t, err)
+
+		resp = doWithTokens(t, "GET", "/users", accessToken, "", nil)
+		_ = resp.Body.Close()
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode, "Expired assignment must stop granting immediately")
+	})
+
+	t.Run("sweeper removes expired assignments and audits them", func(t *testing.T) {
+		maintenance.NewRunner(pool, queries.New(pool), &maintenance.Config{
+			Interval:  time.Hour,
+			BatchSize: 100,
+			Retentions: maintenance.Retentions{
+				DeletedUsers:        1000 * time.Hour,
+				PasswordResetTokens: 1000 * time.Hour,
+				EmailChangeTokens:   1000 * time.Hour,
+				InvitationTokens:    1000 * time.Hour,
+				RefreshTokens:       1000 * time.Hour,
+				SSOAuthRequests:     1000 * time.Hour,
+			},
+		}).RunOnce(ctx)
+
+		var remaining int
+		err := pool.QueryRow(ctx, `SELECT COUNT(*) FROM user_roles WHERE user_id = $1 AND role_id = $2`, target.UserID, managerRoleID).Scan(&remaining)
+		require.NoError(t, err)
+		assert.Equal(t, 0, remaining)
+
+		var hasActor bool
+		var reason, grantedBy string
+		err = pool.QueryRow(ctx, `
+			SELECT actor_id IS NOT NULL, metadata->>'reason', metadata->>'granted_by' FROM audit_logs
+			WHERE tenant_id = $1 AND action = 'roles_updated' AND resource_id = $2
+			ORDER BY created_at DESC LIMIT 1`,
+			target.TenantID, target.UserID).Scan(&hasActor, &reason, &grantedBy)
+		require.NoError(t, err)
+		assert.Equal(t, "expired", reason)
+		assert.False(t, hasActor, "Nobody removed the role, so the entry has no actor")
+		assert.Equal(t, setup.TestUsersData["enterprise_1"].UserID, grantedBy, "The granter is kept in the metadata")
+	})
+
+	t.Run("invite accepts time-bound roles", func(t *testing.T) {
+		expiresAt := time.Now().Add(24 * time.Hour)
+		status, _ := postAsUser(t, "enterprise_1", "/users/invite", users.InviteUserRequestBody{
+			Email:         "temporary-invitee@localhost.com",
+			Name:          "期限 付子",
+			RoleIDs:       []string{managerRoleID},
+			RoleExpiresAt: map[string]time.Time{managerRoleID: expiresAt},
+		})
+		require.Equal(t, http.StatusNoContent, status)
+
+		var stored time.Time
+		err := pool.QueryRow(ctx, `
+			SELECT user_roles.expires_at FROM user_roles
+			JOIN users ON users.id = user_roles.user_id
+			WHERE users.email = 'temporary-invitee@localhost.com' AND user_roles.role_id = $1`, managerRoleID).Scan(&stored)
+		require.NoError(t, err)
+		assert.WithinDuration(t, expiresAt, stored, time.Second)
+	})
+}
@@ -0,0 +1,166 @@
+package users
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"lugia/features/users"
+	"lugia/test/integration/setup"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func doWithTokens(t *testing.T, method, path, accessToken, refreshToken string, body any) *http.Response {
+	var reqBody *bytes.Buffer
+	if body != nil {
+		b, err := json.Marshal(body)
+		require.NoError(t, err)
+		reqBody = bytes.NewBuffer(b)
+	} else {
+		reqBody = bytes.NewBuffer(nil)
+	}
+
+	req, err := http.NewRequest(method, setup.BaseURL+path, reqBody)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.AddCookie(&http.Cookie{Name: "dislyze_access_token", Value: accessToken})
+	if refreshToken != "" {
+		req.AddCookie(&http.Cookie{Name: "dislyze_refresh_token", Value: refreshToken})
+	}
+
+	resp, err := (&http.Client{}).Do(req)
+	require.NoError(t, err)
+	return resp
+}
+
+func responseCookie(resp *http.Response, name string) string {
+	for _, c := range resp.Cookies() {
+		if c.Name == name {
+			return c.Value
+		}
+	}
+	return ""
+}
+
+func TestTenantMembership_Integration(t *testing.T) {
+	pool := setup.InitDB(t)
+	setup.ResetAndSeedDB(t, pool)
+	defer setup.CloseDB(pool)
+
+	ctx := context.Background()
+	smbUser := setup.TestUsersData["smb_1"]
+	enterpriseTenantID := setup.TestUsersData["enterprise_1"].TenantID
+
+	t.Run("inviting an existing account adds a membership", func(t *testing.T) {
+		status, _ := postAsUser(t, "enterprise_1", "/users/invite", users.InviteUserRequestBody{
+			Email:   smbUser.Email,
+			Name:    "ignored for existing accounts",
+			RoleIDs: []string{setup.TestRolesData["enterprise_editor"].ID},
+		})
+		require.Equal(t, http.StatusNoContent, status)
+
+		var homeTenantID, name string
+		err := pool.QueryRow(ctx, `SELECT tenant_id::text, name FROM users WHERE id = $1`, smbUser.UserID).Scan(&homeTenantID, &name)
+		require.NoError(t, err)
+		assert.Equal(t, smbUser.TenantID, homeTenantID, "Home tenant must not change")
+		assert.Equal(t, smbUser.Name, name, "Existing account must not be renamed")
+
+		var roleCount int
+		err = pool.QueryRow(ctx, `SELECT COUNT(*) FROM user_roles WHERE user_id = $1 AND tenant_id = $2`, smbUser.UserID, enterpriseTenantID).Scan(&roleCount)
+		require.NoError(t, err)
+		assert.Equal(t, 1, roleCount)
+	})
+
+	t.Run("inviting an existing member is still a conflict", func(t *testing.T) {
+		status, errorResp := postAsUser(t, "enterprise_1", "/users/invite", users.InviteUserRequestBody{
+			Email:   smbUser.Email,
+			Name:    "again",
+			RoleIDs: []string{setup.TestRolesData["enterprise_editor"].ID},
+		})
+		assert.Equal(t, http.StatusConflict, status)
+		assert.Equal(t, "このメールアドレスは既に使用されています。", errorResp["error"])
+	})
+
+	accessToken, refreshToken := setup.LoginUserAndGetTokens(t, smbUser.Email, smbUser.PlainTextPassword)
+
+	t.Run("lists both memberships with the home tenant first", func(t *testing.T) {
+		resp := doWithTokens(t, "GET", "/me/tenants", accessToken, "", nil)
+		defer func() { _ = resp.Body.Close() }()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var body users.GetMyTenantsResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		require.Len(t, body.Tenants, 2)
+		assert.Equal(t, smbUser.TenantID, body.Tenants[0].ID)
+		assert.True(t, body.Tenants[0].IsHome)
+		assert.True(t, body.Tenants[0].IsCurrent)
+		assert.Equal(t, enterpriseTenantID, body.Tenants[1].ID)
+		assert.False(t, body.Tenants[1].IsCurrent)
+	})
+
+	var switchedAccessToken string
+	t.Run("switching issues a session for the target tenant", func(t *testing.T) {
+		resp := doWithTokens(t, "POST", "/me/tenants/switch", accessToken, refreshToken,
+			users.SwitchTenantRequestBody{TenantID: enterpriseTenantID})
+		defer func() { _ = resp.Body.Close() }()
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		switchedAccessToken = responseCookie(resp, "dislyze_access_token")
+		require.NotEmpty(t, switchedAccessToken)
+		require.NotEmpty(t, responseCookie(resp, "dislyze_refresh_token"))
+
+		meResp := doWithTokens(t, "GET", "/me", switchedAccessToken, "", nil)
+		defer func() { _ = meResp.Body.Close() }()
+		require.Equal(t, http.StatusOK, meResp.StatusCode)
+		var me users.MeResponse
+		require.NoError(t, json.NewDecoder(meResp.Body).Decode(&me))
+		assert.Equal(t, "エンタープライズ株式会社", me.TenantName)
+
+		var storedTenantID string
+		err := pool.QueryRow(ctx, `SELECT tenant_id::text FROM refresh_tokens WHERE user_id = $1 ORDER BY created_at DESC LIMIT 1`, smbUser.UserID).Scan(&storedTenantID)
+		require.NoError(t, err)
+		assert.Equal(t, enterpriseTenantID, storedTenantID, "Refresh token must remember the tenant so rotation keeps it")
+
+		var auditCount int
+		err = pool.QueryRow(ctx, `SELECT COUNT(*) FROM audit_logs WHERE tenant_id = $1 AND actor_id = $2 AND action = 'tenant_switched'`, enterpriseTenantID, smbUser.UserID).Scan(&auditCount)
+		require.NoError(t, err)
+		assert.Equal(t, 1, auditCount)
+	})
+
+	t.Run("switching to a tenant without membership is forbidden", func(t *testing.T) {
+		resp := doWithTokens(t, "POST", "/me/tenants/switch", accessToken, "",
+			users.SwitchTenantRequestBody{TenantID: setup.TestUsersData["sso_1"].TenantID})
+		defer func() { _ = resp.Body.Close() }()
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("deleting a multi-tenant user only removes the membership", func(t *testing.T) {
+		status, _ := postAsUser(t, "enterprise_1", "/users/"+smbUser.UserID+"/delete", nil)
+		require.Equal(t, http.StatusNoContent, status)
+
+		var deleted bool
+		err := pool.QueryRow(ctx, `SELECT deleted_at IS NOT NULL FROM users WHERE id = $1`, smbUser.UserID).Scan(&deleted)
+		require.NoError(t, err)
+		assert.False(t, deleted, "User must not be anonymized while other memberships remain")
+
+		var isMember bool
+		err = pool.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM tenant_memberships WHERE tenant_id = $1 AND user_id = $2)`, enterpriseTenantID, smbUser.UserID).Scan(&isMember)
+		require.NoError(t, err)
+		assert.False(t, isMember)
+
+		var activeSessions int
+		err = pool.QueryRow(ctx, `SELECT COUNT(*) FROM refresh_tokens WHERE user_id = $1 AND tenant_id = $2 AND revoked_at IS NULL`, smbUser.UserID, enterpriseTenantID).Scan(&activeSessions)
+		require.NoError(t, err)
+		assert.Equal(t, 0, activeSessions, "Sessions in the removed tenant must be revoked")
+
+		resp := doWithTokens(t, "GET", "/me", switchedAccessToken, "", nil)
+		defer func() { _ = resp.Body.Close() }()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode, "Access token for the removed tenant must stop working")
+
+		// The account still logs in to its home tenant.
+		_, _ = setup.LoginUserAndGetTokens(t, smbUser.Email, smbUser.PlainTextPassword)
+	})
+}
@@ -0,0 +1,131 @@
+package users
+
+import (
+	"context"
+	"lugia/features/user_groups"
+	"lugia/features/users"
+	"lugia/lib/authz"
+	"lugia/test/integration/setup"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupScopedAdministration_Integration(t *testing.T) {
+	pool := setup.InitDB(t)
+	setup.ResetAndSeedDB(t, pool)
+	defer setup.CloseDB(pool)
+
+	ctx := context.Background()
+	viewerRoleID := setup.TestRolesData["enterprise_viewer"].ID
+	adminRoleID := setup.TestRolesData["enterprise_admin"].ID
+
+	// enterprise_2 (編集者, no user management permissions) administers a group
+	// holding two viewers, another editor and the tenant administrator.
+	status, _ := postAsUser(t, "enterprise_1", "/users/groups/create", user_groups.UserGroupRequestBody{
+		Name: "営業部",
+		MemberIDs: []string{
+			setup.TestUsersData["enterprise_1"].UserID,
+			setup.TestUsersData["enterprise_3"].UserID,
+			setup.TestUsersData["enterprise_8"].UserID,
+			setup.TestUsersData["enterprise_9"].UserID,
+		},
+		AdminIDs: []string{setup.TestUsersData["enterprise_2"].UserID},
+	})
+	require.Equal(t, http.StatusNoContent, status)
+
+	var groupID string
+	err := pool.QueryRow(ctx, `SELECT id::text FROM user_groups WHERE name = '営業部'`).Scan(&groupID)
+	require.NoError(t, err)
+
+	t.Run("group admin can change roles of a member", func(t *testing.T) {
+		status, _ := postAsUser(t, "enterprise_2", "/users/"+setup.TestUsersData["enterprise_8"].UserID+"/roles",
+			users.UpdateUserRolesRequestBody{RoleIDs: []string{viewerRoleID}})
+		assert.Equal(t, http.StatusNoContent, status)
+	})
+
+	t.Run("group admin cannot grant roles beyond their own permissions", func(t *testing.T) {
+		status, body := postAsUser(t, "enterprise_2", "/users/"+setup.TestUsersData["enterprise_8"].UserID+"/roles",
+			users.UpdateUserRolesRequestBody{RoleIDs: []string{adminRoleID}})
+		assert.Equal(t, http.StatusForbidden, status)
+		assert.Equal(t, authz.RolesBeyondScopeDetail, body["error"])
+	})
+
+	t.Run("group admin cannot act on users outside their groups", func(t *testing.T) {
+		target := setup.TestUsersData["enterprise_10"].UserID
+		status, body := postAsUser(t, "enterprise_2", "/users/"+target+"/roles",
+			users.UpdateUserRolesRequestBody{RoleIDs: []string{viewerRoleID}})
+		assert.Equal(t, http.StatusForbidden, status)
+		assert.Equal(t, authz.OutOfScopeDetail, body["error"])
+
+		status, _ = postAsUser(t, "enterprise_2", "/users/"+target+"/delete", nil)
+		assert.Equal(t, http.StatusForbidden, status)
+	})
+
+	t.Run("group admin cannot delete a member holding more than they do", func(t *testing.T) {
+		status, body := postAsUser(t, "enterprise_2", "/users/"+setup.TestUsersData["enterprise_1"].UserID+"/delete", nil)
+		assert.Equal(t, http.StatusForbidden, status)
+		assert.Equal(t, authz.RolesBeyondScopeDetail, body["error"])
+	})
+
+	t.Run("group admin can delete a member", func(t *testing.T) {
+		target := setup.TestUsersData["enterprise_9"].UserID
+		status, _ := postAsUser(t, "enterprise_2", "/users/"+target+"/delete", nil)
+		assert.Equal(t, http.StatusNoContent, status)
+
+		var memberships int
+		err := pool.QueryRow(ctx, `SELECT COUNT(*) FROM user_group_members WHERE user_id = $1`, target).Scan(&memberships)
+		require.NoError(t, err)
+		assert.Equal(t, 0, memberships)
+	})
+
+	t.Run("group admin must invite into their group", func(t *testing.T) {
+		status, _ := postAsUser(t, "enterprise_2", "/users/invite", users.InviteUserRequestBody{
+			Email:   "group-invitee-nogroup@localhost.com",
+			Name:    "グループ 外",
+			RoleIDs: []string{viewerRoleID},
+		})
+		assert.Equal(t, http.StatusForbidden, status)
+
+		status, _ = postAsUser(t, "enterprise_2", "/users/invite", users.InviteUserRequestBody{
+			Email:   "group-invitee@localhost.com",
+			Name:    "グループ 太郎",
+			RoleIDs: []string{viewerRoleID},
+			GroupID: groupID,
+		})
+		assert.Equal(t, http.StatusNoContent, status)
+
+		var inGroup bool
+		err := pool.QueryRow(ctx, `
+			SELECT EXISTS(
+				SELECT 1 FROM user_group_members
+				JOIN users ON users.id = user_group_members.user_id
+				WHERE users.email = 'group-invitee@localhost.com' AND user_group_members.group_id = $1
+			)`, groupID).Scan(&inGroup)
+		require.NoError(t, err)
+		assert.True(t, inGroup)
+	})
+
+	t.Run("revoking the grant takes effect immediately", func(t *testing.T) {
+		status, _ := postAsUser(t, "enterprise_1", "/users/groups/"+groupID+"/update", user_groups.UserGroupRequestBody{
+			Name:      "営業部",
+			MemberIDs: []string{setup.TestUsersData["enterprise_8"].UserID},
+			AdminIDs:  []string{},
+		})
+		require.Equal(t, http.StatusNoContent, status)
+
+		status, _ = postAsUser(t, "enterprise_2", "/users/"+setup.TestUsersData["enterprise_8"].UserID+"/roles",
+			users.UpdateUserRolesRequestBody{RoleIDs: []string{viewerRoleID}})
+		assert.Equal(t, http.StatusForbidden, status)
+	})
+
+	t.Run("managing groups needs tenant-wide users.assign_roles", func(t *testing.T) {
+		status, _ := postAsUser(t, "enterprise_2", "/users/groups/"+groupID+"/delete", nil)
+		assert.Equal(t, http.StatusForbidden, status)
+
+		status, _ = postAsUser(t, "enterprise_1", "/users/groups/"+groupID+"/delete", nil)
+		assert.Equal(t, http.StatusNoContent, status)
+	})
+}
@@ -1,16 +1,19 @@
 import type { Me, EnterpriseFeatures } from "@dislyze/zoroark/meCache";
 
+export type PermissionAction = "view" | "edit" | "invite" | "delete" | "assign_roles" | "emergency";
+
 export function hasPermission(
 	me: Me,
-	permission: `${"tenant" | "users" | "roles" | "ip_whitelist" | "audit_log"}.${"view" | "edit"}`
+	permission: `${"tenant" | "users" | "roles" | "ip_whitelist" | "audit_log"}.${PermissionAction}`
 ): boolean {
 	if (me.permissions.includes(permission)) {
 		return true;
 	}
 
+	// Any action on a resource implies viewing it.
 	if (permission.endsWith(".view")) {
-		const editPermission = permission.replace(".view", ".edit") as typeof permission;
-		return me.permissions.includes(editPermission);
+		const resourcePrefix = permission.slice(0, -"view".length);
+		return me.permissions.some((p) => p.startsWith(resourcePrefix));
 	}
 
 	return false;
@@ -19,3 +22,9 @@ export function hasPermission(
 export function hasFeature(me: Me, feature: keyof EnterpriseFeatures): boolean {
 	return me.enterprise_features[feature].enabled;
 }
+
+// Group admins may invite, delete and change roles of their groups' members
+// without holding the users permission tenant-wide.
+export function isGroupAdmin(me: Me): boolean {
+	return hasFeature(me, "rbac") && (me.administered_group_ids?.length ?? 0) > 0;
+}
@@ -102,6 +102,12 @@ export async function load({ fetch, url }: Parameters<LayoutLoad>[0]) {
 		return { me: null as any };
 	}
 
+	// Break-glass recovery is for admins the whitelist has locked out, and its
+	// approval links are opened by admins who may or may not be signed in.
+	if (url.pathname.startsWith("/ip-whitelist-recovery")) {
+		return { me: null as any };
+	}
+
 	// user needs to be able to display /error when they are locked out
 	if (url.pathname === "/error") {
 		return { me: null as any };
@@ -0,0 +1,42 @@
+// Feature doc: docs/features/ip-whitelisting.md
+import type { PageLoad } from "./$types";
+import type { ApproveBreakGlassResponse } from "$lugia/schema";
+
+export async function load({ url, fetch }: Parameters<PageLoad>[0]) {
+	const token = url.searchParams.get("token");
+
+	if (!token) {
+		return {
+			result: null,
+			error: "承認リンクが無効または期限切れです。"
+		};
+	}
+
+	try {
+		const response = await fetch(
+			`/api/ip-whitelist/break-glass/approve?token=${encodeURIComponent(token)}`,
+			{
+				method: "POST"
+			}
+		);
+
+		if (!response.ok) {
+			const data = (await response.json()) as { error?: string };
+			return {
+				result: null,
+				error: data.error || "承認リンクが無効または期限切れです。"
+			};
+		}
+
+		return {
+			result: (await response.json()) as ApproveBreakGlassResponse,
+			error: null
+		};
+	} catch (e) {
+		console.error("Error in break-glass approve load function:", e);
+		return {
+			result: null,
+			error: "予期せぬエラーが発生しました。"
+		};
+	}
+}
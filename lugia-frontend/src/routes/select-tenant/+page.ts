@@ -0,0 +1,43 @@
+// Feature doc: docs/features/authentication.md
+import { error, redirect } from "@sveltejs/kit";
+import type { PageLoad } from "./$types";
+
+export type TenantOption = {
+	id: string;
+	name: string;
+	auth_method: string;
+	is_home: boolean;
+	is_current: boolean;
+};
+
+export async function load({ url, fetch }: Parameters<PageLoad>[0]) {
+	const redirectTo = url.searchParams.get("redirect");
+
+	// Validate redirect URL for security (prevent open redirect attacks)
+	let validatedRedirect = "/";
+	if (redirectTo) {
+		try {
+			const redirectUrl = new URL(redirectTo, url.origin);
+			if (redirectUrl.origin === url.origin) {
+				validatedRedirect = redirectUrl.pathname + redirectUrl.search;
+			}
+		} catch {
+			validatedRedirect = "/";
+		}
+	}
+
+	const response = await fetch(`/api/me/tenants`, { credentials: "include" });
+	if (response.status === 401) {
+		redirect(302, "/auth/login");
+	}
+	if (!response.ok) {
+		error(response.status, "テナント一覧の取得に失敗しました。");
+	}
+
+	const { tenants } = (await response.json()) as { tenants: TenantOption[] };
+
+	return {
+		tenants,
+		redirectTo: validatedRedirect
+	};
+}
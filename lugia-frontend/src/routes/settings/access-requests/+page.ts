@@ -0,0 +1,57 @@
+// Feature doc: docs/features/access-requests.md
+import { error } from "@sveltejs/kit";
+import type { PageLoad } from "./$types";
+import { hasPermission } from "$lugia/lib/authz";
+
+export type AccessRequestStatus = "pending" | "approved" | "denied" | "expired";
+
+export type AccessRequest = {
+	id: string;
+	requester_id: string;
+	requester_name: string;
+	requester_email: string;
+	role_id: string;
+	role_name: string;
+	justification: string;
+	duration_hours: number;
+	status: AccessRequestStatus;
+	expires_at: string;
+	decided_by_name: string | null;
+	decided_at: string | null;
+	decision_note: string | null;
+	granted_until: string | null;
+	created_at: string;
+};
+
+export type RequestableRole = {
+	id: string;
+	name: string;
+	description: string;
+};
+
+async function getJSON<T>(fetch: typeof globalThis.fetch, url: string): Promise<T> {
+	const response = await fetch(url, { credentials: "include" });
+	if (!response.ok) {
+		error(response.status, "アクセス申請の取得に失敗しました。");
+	}
+	return (await response.json()) as T;
+}
+
+export async function load({ fetch, parent }: Parameters<PageLoad>[0]) {
+	const { me } = await parent();
+
+	const myRequestsPromise = getJSON<{
+		requests: AccessRequest[];
+		requestable_roles: RequestableRole[];
+	}>(fetch, `/api/me/access-requests`);
+
+	// Only approvers can see the tenant-wide queue
+	const pendingRequestsPromise = hasPermission(me, "roles.edit")
+		? getJSON<{ requests: AccessRequest[] }>(fetch, `/api/access-requests?status=pending`)
+		: Promise.resolve({ requests: [] as AccessRequest[] });
+
+	return {
+		myRequestsPromise,
+		pendingRequestsPromise
+	};
+}
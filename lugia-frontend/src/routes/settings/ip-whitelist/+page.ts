@@ -6,8 +6,20 @@ export function load({ fetch }: Parameters<PageLoad>[0]) {
 	const api = createLoadClient(fetch);
 
 	const ipWhitelistPromise = api.GET("/ip-whitelist").then(({ data }) => data!.rules);
+	const scopesPromise = api.GET("/ip-whitelist/scopes").then(({ data }) => data!.scopes);
+	const countriesPromise = api.GET("/ip-whitelist/countries").then(({ data }) => data!);
+	const recoveryCodesPromise = api.GET("/ip-whitelist/recovery-codes").then(({ data }) => data!);
+	const analysisPromise = api.GET("/ip-whitelist/analysis").then(({ data }) => data!.findings);
+	const monitorReportPromise = api
+		.GET("/ip-whitelist/monitor-report", { params: { query: { days: 7 } } })
+		.then(({ data }) => data!);
 
 	return {
-		ipWhitelistPromise
+		ipWhitelistPromise,
+		scopesPromise,
+		countriesPromise,
+		recoveryCodesPromise,
+		analysisPromise,
+		monitorReportPromise
 	};
 }
@@ -7,9 +7,11 @@ export function load({ fetch }: Parameters<PageLoad>[0]) {
 
 	const rolesPromise = api.GET("/roles").then(({ data }) => data!);
 	const permissionsPromise = api.GET("/roles/permissions").then(({ data }) => data!);
+	const templatesPromise = api.GET("/roles/templates").then(({ data }) => data!);
 
 	return {
 		rolesPromise,
-		permissionsPromise
+		permissionsPromise,
+		templatesPromise
 	};
 }
@@ -0,0 +1,50 @@
+// Feature doc: docs/features/separation-of-duties.md
+import { error } from "@sveltejs/kit";
+import type { PageLoad } from "./$types";
+
+export type ConflictSetRole = {
+	id: string;
+	name: string;
+};
+
+export type ConflictSet = {
+	id: string;
+	name: string;
+	roles: ConflictSetRole[];
+	created_at: string;
+};
+
+export type ConflictViolation = {
+	user_id: string;
+	user_name: string;
+	user_email: string;
+	set_id: string;
+	set_name: string;
+	roles: ConflictSetRole[];
+};
+
+async function getJSON<T>(fetch: typeof globalThis.fetch, url: string): Promise<T> {
+	const response = await fetch(url, { credentials: "include" });
+	if (!response.ok) {
+		error(response.status, "職務分掌ルールの取得に失敗しました。");
+	}
+	return (await response.json()) as T;
+}
+
+export function load({ fetch }: Parameters<PageLoad>[0]) {
+	const conflictSetsPromise = getJSON<{ conflict_sets: ConflictSet[] }>(
+		fetch,
+		`/api/roles/conflict-sets`
+	);
+	const violationsPromise = getJSON<{ violations: ConflictViolation[] }>(
+		fetch,
+		`/api/roles/conflict-sets/violations`
+	);
+	const rolesPromise = getJSON<{ roles: ConflictSetRole[] }>(fetch, `/api/roles`);
+
+	return {
+		conflictSetsPromise,
+		violationsPromise,
+		rolesPromise
+	};
+}
@@ -1,8 +1,12 @@
-// Feature doc: docs/features/user-management.md
+// Feature doc: docs/features/user-management.md, docs/features/user-groups.md
 import type { PageLoad } from "./$types";
 import { createLoadClient } from "$lugia/lib/api";
+import { hasFeature } from "$lugia/lib/authz";
+import { getUserGroups } from "$lugia/routes/settings/users/groups/+page";
+
+export async function load({ fetch, url, parent }: Parameters<PageLoad>[0]) {
+	const { me } = await parent();
 
-export function load({ fetch, url }: Parameters<PageLoad>[0]) {
 	const searchParams = url.searchParams;
 	const page = parseInt(searchParams.get("page") || "1", 10);
 	const limit = parseInt(searchParams.get("limit") || "50", 10);
@@ -18,9 +22,13 @@ export function load({ fetch, url }: Parameters<PageLoad>[0]) {
 
 	const rolesPromise = api.GET("/users/roles").then(({ data }) => data!);
 
+	// Groups only exist with RBAC; they decide which users a group admin can manage
+	const groupsPromise = hasFeature(me, "rbac") ? getUserGroups(fetch) : Promise.resolve([]);
+
 	return {
 		usersPromise,
 		rolesPromise,
+		groupsPromise,
 		currentPage: page,
 		currentLimit: limit,
 		currentSearch: search
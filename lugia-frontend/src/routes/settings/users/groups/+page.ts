@@ -0,0 +1,43 @@
+// Feature doc: docs/features/user-groups.md
+import { error } from "@sveltejs/kit";
+import type { PageLoad } from "./$types";
+
+export type UserGroupUser = {
+	id: string;
+	name: string;
+	email: string;
+};
+
+export type UserGroup = {
+	id: string;
+	name: string;
+	members: UserGroupUser[];
+	admins: UserGroupUser[];
+	created_at: string;
+};
+
+export async function getUserGroups(fetch: typeof globalThis.fetch): Promise<UserGroup[]> {
+	const response = await fetch(`/api/users/groups`, { credentials: "include" });
+	if (!response.ok) {
+		error(response.status, "グループの取得に失敗しました。");
+	}
+	return ((await response.json()) as { groups: UserGroup[] }).groups;
+}
+
+export function load({ fetch }: Parameters<PageLoad>[0]) {
+	const groupsPromise = getUserGroups(fetch);
+	// The member and admin pickers offer the first 100 members of the tenant
+	const usersPromise = fetch(`/api/users?limit=100`, { credentials: "include" }).then(
+		async (response) => {
+			if (!response.ok) {
+				error(response.status, "ユーザーの取得に失敗しました。");
+			}
+			return ((await response.json()) as { users: UserGroupUser[] }).users;
+		}
+	);
+
+	return {
+		groupsPromise,
+		usersPromise
+	};
+}
@@ -180,6 +180,102 @@ export interface paths {
         patch?: never;
         trace?: never;
     };
+    "/ip-whitelist/analysis": {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        get: operations["get-ip-whitelist-analysis"];
+        put?: never;
+        post?: never;
+        delete?: never;
+        options?: never;
+        head?: never;
+        patch?: never;
+        trace?: never;
+    };
+    "/ip-whitelist/break-glass": {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        get?: never;
+        put?: never;
+        post: operations["request-ip-whitelist-break-glass"];
+        delete?: never;
+        options?: never;
+        head?: never;
+        patch?: never;
+        trace?: never;
+    };
+    "/ip-whitelist/break-glass/approve": {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        get?: never;
+        put?: never;
+        post: operations["approve-ip-whitelist-break-glass"];
+        delete?: never;
+        options?: never;
+        head?: never;
+        patch?: never;
+        trace?: never;
+    };
+    "/ip-whitelist/countries": {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        get: operations["get-ip-whitelist-countries"];
+        put?: never;
+        post?: never;
+        delete?: never;
+        options?: never;
+        head?: never;
+        patch?: never;
+        trace?: never;
+    };
+    "/ip-whitelist/countries/create": {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        get?: never;
+        put?: never;
+        post: operations["add-ip-whitelist-country"];
+        delete?: never;
+        options?: never;
+        head?: never;
+        patch?: never;
+        trace?: never;
+    };
+    "/ip-whitelist/countries/{id}/delete": {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        get?: never;
+        put?: never;
+        post: operations["delete-ip-whitelist-country"];
+        delete?: never;
+        options?: never;
+        head?: never;
+        patch?: never;
+        trace?: never;
+    };
     "/ip-whitelist/create": {
         parameters: {
             query?: never;
@@ -228,7 +324,23 @@ export interface paths {
         patch?: never;
         trace?: never;
     };
-    "/ip-whitelist/{id}/delete": {
+    "/ip-whitelist/export": {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        get: operations["export-ip-whitelist"];
+        put?: never;
+        post?: never;
+        delete?: never;
+        options?: never;
+        head?: never;
+        patch?: never;
+        trace?: never;
+    };
+    "/ip-whitelist/import": {
         parameters: {
             query?: never;
             header?: never;
@@ -237,14 +349,14 @@ export interface paths {
         };
         get?: never;
         put?: never;
-        post: operations["delete-ip"];
+        post: operations["import-ip-whitelist"];
         delete?: never;
         options?: never;
         head?: never;
         patch?: never;
         trace?: never;
     };
-    "/ip-whitelist/{id}/label/update": {
+    "/ip-whitelist/monitor": {
         parameters: {
             query?: never;
             header?: never;
@@ -253,21 +365,21 @@ export interface paths {
         };
         get?: never;
         put?: never;
-        post: operations["update-ip-label"];
+        post: operations["start-whitelist-monitor"];
         delete?: never;
         options?: never;
         head?: never;
         patch?: never;
         trace?: never;
     };
-    "/me": {
+    "/ip-whitelist/monitor-report": {
         parameters: {
             query?: never;
             header?: never;
             path?: never;
             cookie?: never;
         };
-        get: operations["get-me"];
+        get: operations["get-whitelist-monitor-report"];
         put?: never;
         post?: never;
         delete?: never;
@@ -276,7 +388,23 @@ export interface paths {
         patch?: never;
         trace?: never;
     };
-    "/me/change-email": {
+    "/ip-whitelist/recovery-codes": {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        get: operations["get-ip-whitelist-recovery-codes"];
+        put?: never;
+        post: operations["issue-ip-whitelist-recovery-codes"];
+        delete?: never;
+        options?: never;
+        head?: never;
+        patch?: never;
+        trace?: never;
+    };
+    "/ip-whitelist/recovery-codes/redeem": {
         parameters: {
             query?: never;
             header?: never;
@@ -285,14 +413,30 @@ export interface paths {
         };
         get?: never;
         put?: never;
-        post: operations["change-email"];
+        post: operations["redeem-ip-whitelist-recovery-code"];
         delete?: never;
         options?: never;
         head?: never;
         patch?: never;
         trace?: never;
     };
-    "/me/change-name": {
+    "/ip-whitelist/scopes": {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        get: operations["get-ip-whitelist-scopes"];
+        put?: never;
+        post?: never;
+        delete?: never;
+        options?: never;
+        head?: never;
+        patch?: never;
+        trace?: never;
+    };
+    "/ip-whitelist/scopes/create": {
         parameters: {
             query?: never;
             header?: never;
@@ -301,14 +445,14 @@ export interface paths {
         };
         get?: never;
         put?: never;
-        post: operations["update-me"];
+        post: operations["create-ip-whitelist-scope"];
         delete?: never;
         options?: never;
         head?: never;
         patch?: never;
         trace?: never;
     };
-    "/me/change-password": {
+    "/ip-whitelist/scopes/{id}/delete": {
         parameters: {
             query?: never;
             header?: never;
@@ -317,46 +461,46 @@ export interface paths {
         };
         get?: never;
         put?: never;
-        post: operations["change-password"];
+        post: operations["delete-ip-whitelist-scope"];
         delete?: never;
         options?: never;
         head?: never;
         patch?: never;
         trace?: never;
     };
-    "/me/verify-change-email": {
+    "/ip-whitelist/scopes/{id}/update": {
         parameters: {
             query?: never;
             header?: never;
             path?: never;
             cookie?: never;
         };
-        get: operations["verify-change-email"];
+        get?: never;
         put?: never;
-        post?: never;
+        post: operations["update-ip-whitelist-scope"];
         delete?: never;
         options?: never;
         head?: never;
         patch?: never;
         trace?: never;
     };
-    "/roles": {
+    "/ip-whitelist/{id}/delete": {
         parameters: {
             query?: never;
             header?: never;
             path?: never;
             cookie?: never;
         };
-        get: operations["get-roles"];
+        get?: never;
         put?: never;
-        post?: never;
+        post: operations["delete-ip"];
         delete?: never;
         options?: never;
         head?: never;
         patch?: never;
         trace?: never;
     };
-    "/roles/create": {
+    "/ip-whitelist/{id}/label/update": {
         parameters: {
             query?: never;
             header?: never;
@@ -365,21 +509,21 @@ export interface paths {
         };
         get?: never;
         put?: never;
-        post: operations["create-role"];
+        post: operations["update-ip-label"];
         delete?: never;
         options?: never;
         head?: never;
         patch?: never;
         trace?: never;
     };
-    "/roles/permissions": {
+    "/me": {
         parameters: {
             query?: never;
             header?: never;
             path?: never;
             cookie?: never;
         };
-        get: operations["get-permissions"];
+        get: operations["get-me"];
         put?: never;
         post?: never;
         delete?: never;
@@ -388,7 +532,7 @@ export interface paths {
         patch?: never;
         trace?: never;
     };
-    "/roles/{roleID}/delete": {
+    "/me/change-email": {
         parameters: {
             query?: never;
             header?: never;
@@ -397,14 +541,14 @@ export interface paths {
         };
         get?: never;
         put?: never;
-        post: operations["delete-role"];
+        post: operations["change-email"];
         delete?: never;
         options?: never;
         head?: never;
         patch?: never;
         trace?: never;
     };
-    "/roles/{roleID}/update": {
+    "/me/change-name": {
         parameters: {
             query?: never;
             header?: never;
@@ -413,14 +557,14 @@ export interface paths {
         };
         get?: never;
         put?: never;
-        post: operations["update-role"];
+        post: operations["update-me"];
         delete?: never;
         options?: never;
         head?: never;
         patch?: never;
         trace?: never;
     };
-    "/tenant/change-name": {
+    "/me/change-password": {
         parameters: {
             query?: never;
             header?: never;
@@ -429,21 +573,21 @@ export interface paths {
         };
         get?: never;
         put?: never;
-        post: operations["change-tenant-name"];
+        post: operations["change-password"];
         delete?: never;
         options?: never;
         head?: never;
         patch?: never;
         trace?: never;
     };
-    "/users": {
+    "/me/verify-change-email": {
         parameters: {
             query?: never;
             header?: never;
             path?: never;
             cookie?: never;
         };
-        get: operations["get-users"];
+        get: operations["verify-change-email"];
         put?: never;
         post?: never;
         delete?: never;
@@ -452,7 +596,23 @@ export interface paths {
         patch?: never;
         trace?: never;
     };
-    "/users/invite": {
+    "/roles": {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        get: operations["get-roles"];
+        put?: never;
+        post?: never;
+        delete?: never;
+        options?: never;
+        head?: never;
+        patch?: never;
+        trace?: never;
+    };
+    "/roles/create": {
         parameters: {
             query?: never;
             header?: never;
@@ -461,21 +621,21 @@ export interface paths {
         };
         get?: never;
         put?: never;
-        post: operations["invite-user"];
+        post: operations["create-role"];
         delete?: never;
         options?: never;
         head?: never;
         patch?: never;
         trace?: never;
     };
-    "/users/roles": {
+    "/roles/permissions": {
         parameters: {
             query?: never;
             header?: never;
             path?: never;
             cookie?: never;
         };
-        get: operations["get-users-roles"];
+        get: operations["get-permissions"];
         put?: never;
         post?: never;
         delete?: never;
@@ -484,23 +644,23 @@ export interface paths {
         patch?: never;
         trace?: never;
     };
-    "/users/{userID}/delete": {
+    "/roles/templates": {
         parameters: {
             query?: never;
             header?: never;
             path?: never;
             cookie?: never;
         };
-        get?: never;
+        get: operations["get-role-templates"];
         put?: never;
-        post: operations["delete-user"];
+        post?: never;
         delete?: never;
         options?: never;
         head?: never;
         patch?: never;
         trace?: never;
     };
-    "/users/{userID}/resend-invite": {
+    "/roles/templates/{templateKey}/create": {
         parameters: {
             query?: never;
             header?: never;
@@ -509,14 +669,14 @@ export interface paths {
         };
         get?: never;
         put?: never;
-        post: operations["resend-invite"];
+        post: operations["create-role-from-template"];
         delete?: never;
         options?: never;
         head?: never;
         patch?: never;
         trace?: never;
     };
-    "/users/{userID}/roles": {
+    "/roles/{roleID}/delete": {
         parameters: {
             query?: never;
             header?: never;
@@ -525,55 +685,237 @@ export interface paths {
         };
         get?: never;
         put?: never;
-        post: operations["update-user-roles"];
+        post: operations["delete-role"];
         delete?: never;
         options?: never;
         head?: never;
         patch?: never;
         trace?: never;
     };
-}
-export type webhooks = Record<string, never>;
-export interface components {
-    schemas: {
-        AcceptInviteRequestBody: {
-            /**
-             * Format: uri
-             * @description A URL to the JSON Schema for this object.
-             * @example https://example.com/schemas/AcceptInviteRequestBody.json
-             */
-            readonly $schema?: string;
-            password: string;
-            password_confirm: string;
-            token: string;
-        };
-        ActivateWhitelistRequestBody: {
-            /**
-             * Format: uri
-             * @description A URL to the JSON Schema for this object.
-             * @example https://example.com/schemas/ActivateWhitelistRequestBody.json
-             */
-            readonly $schema?: string;
-            force?: boolean;
+    "/roles/{roleID}/duplicate": {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
         };
-        ActivateWhitelistResponse: {
-            /**
-             * Format: uri
-             * @description A URL to the JSON Schema for this object.
-             * @example https://example.com/schemas/ActivateWhitelistResponse.json
-             */
-            readonly $schema?: string;
-            user_ip?: string;
+        get?: never;
+        put?: never;
+        post: operations["duplicate-role"];
+        delete?: never;
+        options?: never;
+        head?: never;
+        patch?: never;
+        trace?: never;
+    };
+    "/roles/{roleID}/update": {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
         };
-        AddIPToWhitelistRequest: {
+        get?: never;
+        put?: never;
+        post: operations["update-role"];
+        delete?: never;
+        options?: never;
+        head?: never;
+        patch?: never;
+        trace?: never;
+    };
+    "/tenant/change-name": {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        get?: never;
+        put?: never;
+        post: operations["change-tenant-name"];
+        delete?: never;
+        options?: never;
+        head?: never;
+        patch?: never;
+        trace?: never;
+    };
+    "/users": {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        get: operations["get-users"];
+        put?: never;
+        post?: never;
+        delete?: never;
+        options?: never;
+        head?: never;
+        patch?: never;
+        trace?: never;
+    };
+    "/users/invite": {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        get?: never;
+        put?: never;
+        post: operations["invite-user"];
+        delete?: never;
+        options?: never;
+        head?: never;
+        patch?: never;
+        trace?: never;
+    };
+    "/users/roles": {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        get: operations["get-users-roles"];
+        put?: never;
+        post?: never;
+        delete?: never;
+        options?: never;
+        head?: never;
+        patch?: never;
+        trace?: never;
+    };
+    "/users/{userID}/delete": {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        get?: never;
+        put?: never;
+        post: operations["delete-user"];
+        delete?: never;
+        options?: never;
+        head?: never;
+        patch?: never;
+        trace?: never;
+    };
+    "/users/{userID}/resend-invite": {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        get?: never;
+        put?: never;
+        post: operations["resend-invite"];
+        delete?: never;
+        options?: never;
+        head?: never;
+        patch?: never;
+        trace?: never;
+    };
+    "/users/{userID}/roles": {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        get?: never;
+        put?: never;
+        post: operations["update-user-roles"];
+        delete?: never;
+        options?: never;
+        head?: never;
+        patch?: never;
+        trace?: never;
+    };
+}
+export type webhooks = Record<string, never>;
+export interface components {
+    schemas: {
+        AcceptInviteRequestBody: {
+            /**
+             * Format: uri
+             * @description A URL to the JSON Schema for this object.
+             * @example https://example.com/schemas/AcceptInviteRequestBody.json
+             */
+            readonly $schema?: string;
+            password: string;
+            password_confirm: string;
+            token: string;
+        };
+        ActivateWhitelistRequestBody: {
+            /**
+             * Format: uri
+             * @description A URL to the JSON Schema for this object.
+             * @example https://example.com/schemas/ActivateWhitelistRequestBody.json
+             */
+            readonly $schema?: string;
+            force?: boolean;
+        };
+        ActivateWhitelistResponse: {
+            /**
+             * Format: uri
+             * @description A URL to the JSON Schema for this object.
+             * @example https://example.com/schemas/ActivateWhitelistResponse.json
+             */
+            readonly $schema?: string;
+            user_ip?: string;
+        };
+        AddIPToWhitelistRequest: {
             /**
              * Format: uri
              * @description A URL to the JSON Schema for this object.
              * @example https://example.com/schemas/AddIPToWhitelistRequest.json
              */
             readonly $schema?: string;
+            confirm_broad?: boolean;
+            /** Format: date-time */
+            expires_at?: string;
             ip_address: string;
             label: string | null;
+            scope_id?: string;
+        };
+        AddIPWhitelistCountryRequest: {
+            /**
+             * Format: uri
+             * @description A URL to the JSON Schema for this object.
+             * @example https://example.com/schemas/AddIPWhitelistCountryRequest.json
+             */
+            readonly $schema?: string;
+            /** @enum {string} */
+            action: "allow" | "deny";
+            /** @description ISO 3166-1 alpha-2 country code */
+            country_code: string;
+        };
+        AddIPWhitelistCountryResponse: {
+            /**
+             * Format: uri
+             * @description A URL to the JSON Schema for this object.
+             * @example https://example.com/schemas/AddIPWhitelistCountryResponse.json
+             */
+            readonly $schema?: string;
+            country: components["schemas"]["IPWhitelistCountry"];
+        };
+        ApproveBreakGlassResponse: {
+            /**
+             * Format: uri
+             * @description A URL to the JSON Schema for this object.
+             * @example https://example.com/schemas/ApproveBreakGlassResponse.json
+             */
+            readonly $schema?: string;
+            /** Format: int64 */
+            approvals: number;
+            deactivated: boolean;
+            /** Format: int64 */
+            required: number;
         };
         AuditLog: {
             enabled: boolean;
@@ -626,6 +968,36 @@ export interface components {
             ip_whitelist: components["schemas"]["IPWhitelist"];
             rbac: components["schemas"]["RBAC"];
         };
+        CreateIPWhitelistScopeRequest: {
+            /**
+             * Format: uri
+             * @description A URL to the JSON Schema for this object.
+             * @example https://example.com/schemas/CreateIPWhitelistScopeRequest.json
+             */
+            readonly $schema?: string;
+            exempt: boolean;
+            role_id?: string;
+            user_id?: string;
+        };
+        CreateIPWhitelistScopeResponse: {
+            /**
+             * Format: uri
+             * @description A URL to the JSON Schema for this object.
+             * @example https://example.com/schemas/CreateIPWhitelistScopeResponse.json
+             */
+            readonly $schema?: string;
+            scope: components["schemas"]["IPWhitelistScope"];
+        };
+        CreateRoleFromTemplateRequestBody: {
+            /**
+             * Format: uri
+             * @description A URL to the JSON Schema for this object.
+             * @example https://example.com/schemas/CreateRoleFromTemplateRequestBody.json
+             */
+            readonly $schema?: string;
+            /** @description Name defaults to the template's name. */
+            name?: string;
+        };
         CreateRoleRequestBody: {
             /**
              * Format: uri
@@ -634,9 +1006,19 @@ export interface components {
              */
             readonly $schema?: string;
             description: string;
+            included_role_ids?: string[] | null;
             name: string;
             permission_ids: string[] | null;
         };
+        DuplicateRoleRequestBody: {
+            /**
+             * Format: uri
+             * @description A URL to the JSON Schema for this object.
+             * @example https://example.com/schemas/DuplicateRoleRequestBody.json
+             */
+            readonly $schema?: string;
+            name: string;
+        };
         ErrorDetail: {
             /** @description Where the error occurred, e.g. 'body.items[3].tags' or 'path.thing-id' */
             location?: string;
@@ -703,6 +1085,26 @@ export interface components {
             audit_logs: components["schemas"]["AuditLogEntry"][];
             pagination: components["schemas"]["PaginationMetadata"];
         };
+        GetIPWhitelistAnalysisResponse: {
+            /**
+             * Format: uri
+             * @description A URL to the JSON Schema for this object.
+             * @example https://example.com/schemas/GetIPWhitelistAnalysisResponse.json
+             */
+            readonly $schema?: string;
+            findings: components["schemas"]["IPWhitelistFinding"][];
+        };
+        GetIPWhitelistCountriesResponse: {
+            /**
+             * Format: uri
+             * @description A URL to the JSON Schema for this object.
+             * @example https://example.com/schemas/GetIPWhitelistCountriesResponse.json
+             */
+            readonly $schema?: string;
+            countries: components["schemas"]["IPWhitelistCountry"][];
+            current_country: string;
+            database_loaded: boolean;
+        };
         GetIPWhitelistResponse: {
             /**
              * Format: uri
@@ -712,6 +1114,29 @@ export interface components {
             readonly $schema?: string;
             rules: components["schemas"]["IPWhitelistRule"][];
         };
+        GetIPWhitelistScopesResponse: {
+            /**
+             * Format: uri
+             * @description A URL to the JSON Schema for this object.
+             * @example https://example.com/schemas/GetIPWhitelistScopesResponse.json
+             */
+            readonly $schema?: string;
+            scopes: components["schemas"]["IPWhitelistScope"][];
+        };
+        GetMonitorReportResponse: {
+            /**
+             * Format: uri
+             * @description A URL to the JSON Schema for this object.
+             * @example https://example.com/schemas/GetMonitorReportResponse.json
+             */
+            readonly $schema?: string;
+            entries: components["schemas"]["MonitorReportEntry"][];
+            /** Format: date-time */
+            since: string;
+            source_ips: components["schemas"]["MonitorReportSourceIP"][];
+            /** Format: int64 */
+            total_hits: number;
+        };
         GetPermissionsResponse: {
             /**
              * Format: uri
@@ -721,6 +1146,27 @@ export interface components {
             readonly $schema?: string;
             permissions: components["schemas"]["Permission"][];
         };
+        GetRecoveryCodesResponse: {
+            /**
+             * Format: uri
+             * @description A URL to the JSON Schema for this object.
+             * @example https://example.com/schemas/GetRecoveryCodesResponse.json
+             */
+            readonly $schema?: string;
+            /** Format: date-time */
+            issued_at: string | null;
+            /** Format: int64 */
+            remaining: number;
+        };
+        GetRoleTemplatesResponse: {
+            /**
+             * Format: uri
+             * @description A URL to the JSON Schema for this object.
+             * @example https://example.com/schemas/GetRoleTemplatesResponse.json
+             */
+            readonly $schema?: string;
+            templates: components["schemas"]["RoleTemplateInfo"][];
+        };
         GetRolesResponse: {
             /**
              * Format: uri
@@ -744,26 +1190,112 @@ export interface components {
             active: boolean;
             allow_internal_admin_bypass: boolean;
             enabled: boolean;
+            monitor: boolean;
+        };
+        IPWhitelistCountry: {
+            /** @enum {string} */
+            action: "allow" | "deny";
+            country_code: string;
+            /** Format: date-time */
+            created_at: string;
+            created_by: string;
+            id: string;
+        };
+        IPWhitelistFinding: {
+            ip_address: string;
+            /** @enum {string} */
+            kind: "redundant" | "overlapping" | "broad" | "private";
+            related_ip_address: string | null;
+            related_rule_id: string | null;
+            rule_id: string;
+            scope_id: string | null;
         };
         IPWhitelistRule: {
             /** Format: date-time */
             created_at: string;
             created_by: string;
+            /** Format: date-time */
+            expires_at: string | null;
             id: string;
             ip_address: string;
             label: string | null;
+            scope_id: string | null;
         };
-        InviteUserRequestBody: {
+        IPWhitelistScope: {
+            /** Format: date-time */
+            created_at: string;
+            exempt: boolean;
+            id: string;
+            role_id: string | null;
+            role_name: string | null;
+            user_email: string | null;
+            user_id: string | null;
+            user_name: string | null;
+        };
+        ImportIPWhitelistRequest: {
             /**
              * Format: uri
              * @description A URL to the JSON Schema for this object.
-             * @example https://example.com/schemas/InviteUserRequestBody.json
+             * @example https://example.com/schemas/ImportIPWhitelistRequest.json
              */
             readonly $schema?: string;
-            email: string;
+            all_or_nothing: boolean;
+            confirm_broad?: boolean;
+            content: string;
+            /** @enum {string} */
+            format: "csv" | "json";
+        };
+        ImportIPWhitelistResponse: {
+            /**
+             * Format: uri
+             * @description A URL to the JSON Schema for this object.
+             * @example https://example.com/schemas/ImportIPWhitelistResponse.json
+             */
+            readonly $schema?: string;
+            /** Format: int64 */
+            imported: number;
+            rows: components["schemas"]["ImportIPWhitelistRowResult"][];
+        };
+        ImportIPWhitelistRowResult: {
+            error: string | null;
+            ip_address: string;
+            /** Format: int64 */
+            row: number;
+            /** @enum {string} */
+            status: "added" | "duplicate" | "invalid" | "broad" | "skipped";
+        };
+        IncludedRole: {
+            id: string;
+            name: string;
+        };
+        InheritedPermission: {
+            action: string;
+            description: string;
+            id: string;
+            inherited_from: string[];
+            resource: string;
+        };
+        InviteUserRequestBody: {
+            /**
+             * Format: uri
+             * @description A URL to the JSON Schema for this object.
+             * @example https://example.com/schemas/InviteUserRequestBody.json
+             */
+            readonly $schema?: string;
+            email: string;
+            group_id?: string;
             name: string;
             role_ids: string[] | null;
         };
+        IssueRecoveryCodesResponse: {
+            /**
+             * Format: uri
+             * @description A URL to the JSON Schema for this object.
+             * @example https://example.com/schemas/IssueRecoveryCodesResponse.json
+             */
+            readonly $schema?: string;
+            codes: string[];
+        };
         LoginRequestBody: {
             /**
              * Format: uri
@@ -781,6 +1313,7 @@ export interface components {
              * @example https://example.com/schemas/MeResponse.json
              */
             readonly $schema?: string;
+            administered_group_ids: string[];
             email: string;
             enterprise_features: components["schemas"]["ClientEnterpriseFeatures"];
             permissions: string[];
@@ -788,6 +1321,26 @@ export interface components {
             user_id: string;
             user_name: string;
         };
+        MonitorReportEntry: {
+            /** Format: int64 */
+            hits: number;
+            ip_address: string;
+            /** Format: date-time */
+            last_seen_at: string;
+            user_email: string;
+            user_id: string;
+            user_name: string;
+        };
+        MonitorReportSourceIP: {
+            allowed_by_current_rules: boolean;
+            /** Format: int64 */
+            hits: number;
+            ip_address: string;
+            /** Format: date-time */
+            last_seen_at: string;
+            /** Format: int64 */
+            users: number;
+        };
         PaginationMetadata: {
             has_next: boolean;
             has_prev: boolean;
@@ -809,6 +1362,25 @@ export interface components {
         RBAC: {
             enabled: boolean;
         };
+        RedeemRecoveryCodeRequestBody: {
+            /**
+             * Format: uri
+             * @description A URL to the JSON Schema for this object.
+             * @example https://example.com/schemas/RedeemRecoveryCodeRequestBody.json
+             */
+            readonly $schema?: string;
+            code: string;
+            email: string;
+        };
+        RequestBreakGlassRequestBody: {
+            /**
+             * Format: uri
+             * @description A URL to the JSON Schema for this object.
+             * @example https://example.com/schemas/RequestBreakGlassRequestBody.json
+             */
+            readonly $schema?: string;
+            email: string;
+        };
         ResetPasswordRequestBody: {
             /**
              * Format: uri
@@ -823,10 +1395,23 @@ export interface components {
         RoleInfo: {
             description: string;
             id: string;
+            included_roles: components["schemas"]["IncludedRole"][];
+            inherited_permissions: components["schemas"]["InheritedPermission"][];
             is_default: boolean;
             name: string;
             permissions: components["schemas"]["Permission"][];
         };
+        RoleTemplateInfo: {
+            description: string;
+            key: string;
+            name: string;
+            permissions: components["schemas"]["RoleTemplatePermission"][];
+        };
+        RoleTemplatePermission: {
+            action: string;
+            description: string;
+            resource: string;
+        };
         SignupRequestBody: {
             /**
              * Format: uri
@@ -850,8 +1435,18 @@ export interface components {
             company_name: string;
             password: string;
             password_confirm: string;
+            role_templates?: string[] | null;
             user_name: string;
         };
+        UpdateIPWhitelistScopeRequest: {
+            /**
+             * Format: uri
+             * @description A URL to the JSON Schema for this object.
+             * @example https://example.com/schemas/UpdateIPWhitelistScopeRequest.json
+             */
+            readonly $schema?: string;
+            exempt: boolean;
+        };
         UpdateLabelRequest: {
             /**
              * Format: uri
@@ -878,6 +1473,7 @@ export interface components {
              */
             readonly $schema?: string;
             description: string;
+            included_role_ids?: string[];
             name: string;
             permission_ids: string[] | null;
         };
@@ -933,33 +1529,57 @@ export type AcceptInviteRequestBody = components['schemas']['AcceptInviteRequest
 export type ActivateWhitelistRequestBody = components['schemas']['ActivateWhitelistRequestBody'];
 export type ActivateWhitelistResponse = components['schemas']['ActivateWhitelistResponse'];
 export type AddIpToWhitelistRequest = components['schemas']['AddIPToWhitelistRequest'];
+export type AddIpWhitelistCountryRequest = components['schemas']['AddIPWhitelistCountryRequest'];
+export type AddIpWhitelistCountryResponse = components['schemas']['AddIPWhitelistCountryResponse'];
+export type ApproveBreakGlassResponse = components['schemas']['ApproveBreakGlassResponse'];
 export type AuditLog = components['schemas']['AuditLog'];
 export type AuditLogEntry = components['schemas']['AuditLogEntry'];
 export type ChangeEmailRequestBody = components['schemas']['ChangeEmailRequestBody'];
 export type ChangePasswordRequestBody = components['schemas']['ChangePasswordRequestBody'];
 export type ChangeTenantNameRequestBody = components['schemas']['ChangeTenantNameRequestBody'];
 export type ClientEnterpriseFeatures = components['schemas']['ClientEnterpriseFeatures'];
+export type CreateIpWhitelistScopeRequest = components['schemas']['CreateIPWhitelistScopeRequest'];
+export type CreateIpWhitelistScopeResponse = components['schemas']['CreateIPWhitelistScopeResponse'];
 export type CreateRoleRequestBody = components['schemas']['CreateRoleRequestBody'];
 export type ErrorDetail = components['schemas']['ErrorDetail'];
 export type ErrorModel = components['schemas']['ErrorModel'];
 export type ForgotPasswordRequestBody = components['schemas']['ForgotPasswordRequestBody'];
 export type GetAuditLogsResponse = components['schemas']['GetAuditLogsResponse'];
+export type GetIpWhitelistAnalysisResponse = components['schemas']['GetIPWhitelistAnalysisResponse'];
+export type GetIpWhitelistCountriesResponse = components['schemas']['GetIPWhitelistCountriesResponse'];
 export type GetIpWhitelistResponse = components['schemas']['GetIPWhitelistResponse'];
+export type GetIpWhitelistScopesResponse = components['schemas']['GetIPWhitelistScopesResponse'];
+export type GetMonitorReportResponse = components['schemas']['GetMonitorReportResponse'];
 export type GetPermissionsResponse = components['schemas']['GetPermissionsResponse'];
+export type GetRecoveryCodesResponse = components['schemas']['GetRecoveryCodesResponse'];
 export type GetRolesResponse = components['schemas']['GetRolesResponse'];
 export type GetUsersResponse = components['schemas']['GetUsersResponse'];
 export type IpWhitelist = components['schemas']['IPWhitelist'];
+export type IpWhitelistCountry = components['schemas']['IPWhitelistCountry'];
+export type IpWhitelistFinding = components['schemas']['IPWhitelistFinding'];
 export type IpWhitelistRule = components['schemas']['IPWhitelistRule'];
+export type IpWhitelistScope = components['schemas']['IPWhitelistScope'];
+export type ImportIpWhitelistRequest = components['schemas']['ImportIPWhitelistRequest'];
+export type ImportIpWhitelistResponse = components['schemas']['ImportIPWhitelistResponse'];
+export type ImportIpWhitelistRowResult = components['schemas']['ImportIPWhitelistRowResult'];
+export type IncludedRole = components['schemas']['IncludedRole'];
+export type InheritedPermission = components['schemas']['InheritedPermission'];
 export type InviteUserRequestBody = components['schemas']['InviteUserRequestBody'];
+export type IssueRecoveryCodesResponse = components['schemas']['IssueRecoveryCodesResponse'];
 export type LoginRequestBody = components['schemas']['LoginRequestBody'];
 export type MeResponse = components['schemas']['MeResponse'];
+export type MonitorReportEntry = components['schemas']['MonitorReportEntry'];
+export type MonitorReportSourceIp = components['schemas']['MonitorReportSourceIP'];
 export type PaginationMetadata = components['schemas']['PaginationMetadata'];
 export type Permission = components['schemas']['Permission'];
 export type Rbac = components['schemas']['RBAC'];
+export type RedeemRecoveryCodeRequestBody = components['schemas']['RedeemRecoveryCodeRequestBody'];
+export type RequestBreakGlassRequestBody = components['schemas']['RequestBreakGlassRequestBody'];
 export type ResetPasswordRequestBody = components['schemas']['ResetPasswordRequestBody'];
 export type RoleInfo = components['schemas']['RoleInfo'];
 export type SignupRequestBody = components['schemas']['SignupRequestBody'];
 export type TenantSignupRequestBody = components['schemas']['TenantSignupRequestBody'];
+export type UpdateIpWhitelistScopeRequest = components['schemas']['UpdateIPWhitelistScopeRequest'];
 export type UpdateLabelRequest = components['schemas']['UpdateLabelRequest'];
 export type UpdateMeRequestBody = components['schemas']['UpdateMeRequestBody'];
 export type UpdateRoleRequestBody = components['schemas']['UpdateRoleRequestBody'];
@@ -986,7 +1606,470 @@ export interface operations {
             path?: never;
             cookie?: never;
         };
-        requestBody?: never;
+        requestBody?: never;
+        responses: {
+            /** @description OK */
+            200: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content: {
+                    "application/json": components["schemas"]["GetAuditLogsResponse"];
+                };
+            };
+            /** @description Error */
+            default: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content: {
+                    "application/problem+json": components["schemas"]["ErrorModel"];
+                };
+            };
+        };
+    };
+    "accept-invite": {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        requestBody: {
+            content: {
+                "application/json": components["schemas"]["AcceptInviteRequestBody"];
+            };
+        };
+        responses: {
+            /** @description No Content */
+            204: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content?: never;
+            };
+            /** @description Error */
+            default: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content: {
+                    "application/problem+json": components["schemas"]["ErrorModel"];
+                };
+            };
+        };
+    };
+    "forgot-password": {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        requestBody: {
+            content: {
+                "application/json": components["schemas"]["ForgotPasswordRequestBody"];
+            };
+        };
+        responses: {
+            /** @description No Content */
+            204: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content?: never;
+            };
+            /** @description Error */
+            default: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content: {
+                    "application/problem+json": components["schemas"]["ErrorModel"];
+                };
+            };
+        };
+    };
+    login: {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        requestBody: {
+            content: {
+                "application/json": components["schemas"]["LoginRequestBody"];
+            };
+        };
+        responses: {
+            /** @description No Content */
+            204: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content?: never;
+            };
+            /** @description Error */
+            default: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content: {
+                    "application/problem+json": components["schemas"]["ErrorModel"];
+                };
+            };
+        };
+    };
+    logout: {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        requestBody?: never;
+        responses: {
+            /** @description No Content */
+            204: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content?: never;
+            };
+            /** @description Error */
+            default: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content: {
+                    "application/problem+json": components["schemas"]["ErrorModel"];
+                };
+            };
+        };
+    };
+    "reset-password": {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        requestBody: {
+            content: {
+                "application/json": components["schemas"]["ResetPasswordRequestBody"];
+            };
+        };
+        responses: {
+            /** @description No Content */
+            204: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content?: never;
+            };
+            /** @description Error */
+            default: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content: {
+                    "application/problem+json": components["schemas"]["ErrorModel"];
+                };
+            };
+        };
+    };
+    signup: {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        requestBody: {
+            content: {
+                "application/json": components["schemas"]["SignupRequestBody"];
+            };
+        };
+        responses: {
+            /** @description No Content */
+            204: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content?: never;
+            };
+            /** @description Error */
+            default: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content: {
+                    "application/problem+json": components["schemas"]["ErrorModel"];
+                };
+            };
+        };
+    };
+    "tenant-signup": {
+        parameters: {
+            query?: {
+                token?: string;
+            };
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        requestBody: {
+            content: {
+                "application/json": components["schemas"]["TenantSignupRequestBody"];
+            };
+        };
+        responses: {
+            /** @description No Content */
+            204: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content?: never;
+            };
+            /** @description Error */
+            default: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content: {
+                    "application/problem+json": components["schemas"]["ErrorModel"];
+                };
+            };
+        };
+    };
+    "verify-reset-token": {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        requestBody: {
+            content: {
+                "application/json": components["schemas"]["VerifyResetTokenRequestBody"];
+            };
+        };
+        responses: {
+            /** @description OK */
+            200: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content: {
+                    "application/json": components["schemas"]["VerifyResetTokenResponse"];
+                };
+            };
+            /** @description Error */
+            default: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content: {
+                    "application/problem+json": components["schemas"]["ErrorModel"];
+                };
+            };
+        };
+    };
+    "get-ip-whitelist": {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        requestBody?: never;
+        responses: {
+            /** @description OK */
+            200: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content: {
+                    "application/json": components["schemas"]["GetIPWhitelistResponse"];
+                };
+            };
+            /** @description Error */
+            default: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content: {
+                    "application/problem+json": components["schemas"]["ErrorModel"];
+                };
+            };
+        };
+    };
+    "activate-whitelist": {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        requestBody: {
+            content: {
+                "application/json": components["schemas"]["ActivateWhitelistRequestBody"];
+            };
+        };
+        responses: {
+            /** @description OK */
+            200: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content: {
+                    "application/json": components["schemas"]["ActivateWhitelistResponse"];
+                };
+            };
+            /** @description Error */
+            default: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content: {
+                    "application/problem+json": components["schemas"]["ErrorModel"];
+                };
+            };
+        };
+    };
+    "get-ip-whitelist-analysis": {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        requestBody?: never;
+        responses: {
+            /** @description OK */
+            200: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content: {
+                    "application/json": components["schemas"]["GetIPWhitelistAnalysisResponse"];
+                };
+            };
+            /** @description Error */
+            default: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content: {
+                    "application/problem+json": components["schemas"]["ErrorModel"];
+                };
+            };
+        };
+    };
+    "request-ip-whitelist-break-glass": {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        requestBody: {
+            content: {
+                "application/json": components["schemas"]["RequestBreakGlassRequestBody"];
+            };
+        };
+        responses: {
+            /** @description No Content */
+            204: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content?: never;
+            };
+            /** @description Error */
+            default: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content: {
+                    "application/problem+json": components["schemas"]["ErrorModel"];
+                };
+            };
+        };
+    };
+    "approve-ip-whitelist-break-glass": {
+        parameters: {
+            query?: {
+                token?: string;
+            };
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        requestBody?: never;
+        responses: {
+            /** @description OK */
+            200: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content: {
+                    "application/json": components["schemas"]["ApproveBreakGlassResponse"];
+                };
+            };
+            /** @description Error */
+            default: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content: {
+                    "application/problem+json": components["schemas"]["ErrorModel"];
+                };
+            };
+        };
+    };
+    "get-ip-whitelist-countries": {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        requestBody?: never;
+        responses: {
+            /** @description OK */
+            200: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content: {
+                    "application/json": components["schemas"]["GetIPWhitelistCountriesResponse"];
+                };
+            };
+            /** @description Error */
+            default: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content: {
+                    "application/problem+json": components["schemas"]["ErrorModel"];
+                };
+            };
+        };
+    };
+    "add-ip-whitelist-country": {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        requestBody: {
+            content: {
+                "application/json": components["schemas"]["AddIPWhitelistCountryRequest"];
+            };
+        };
         responses: {
             /** @description OK */
             200: {
@@ -994,7 +2077,7 @@ export interface operations {
                     [name: string]: unknown;
                 };
                 content: {
-                    "application/json": components["schemas"]["GetAuditLogsResponse"];
+                    "application/json": components["schemas"]["AddIPWhitelistCountryResponse"];
                 };
             };
             /** @description Error */
@@ -1008,18 +2091,16 @@ export interface operations {
             };
         };
     };
-    "accept-invite": {
+    "delete-ip-whitelist-country": {
         parameters: {
             query?: never;
             header?: never;
-            path?: never;
-            cookie?: never;
-        };
-        requestBody: {
-            content: {
-                "application/json": components["schemas"]["AcceptInviteRequestBody"];
+            path: {
+                id: string;
             };
+            cookie?: never;
         };
+        requestBody?: never;
         responses: {
             /** @description No Content */
             204: {
@@ -1039,7 +2120,7 @@ export interface operations {
             };
         };
     };
-    "forgot-password": {
+    "add-ip-to-whitelist": {
         parameters: {
             query?: never;
             header?: never;
@@ -1048,7 +2129,7 @@ export interface operations {
         };
         requestBody: {
             content: {
-                "application/json": components["schemas"]["ForgotPasswordRequestBody"];
+                "application/json": components["schemas"]["AddIPToWhitelistRequest"];
             };
         };
         responses: {
@@ -1070,18 +2151,14 @@ export interface operations {
             };
         };
     };
-    login: {
+    "deactivate-whitelist": {
         parameters: {
             query?: never;
             header?: never;
             path?: never;
             cookie?: never;
         };
-        requestBody: {
-            content: {
-                "application/json": components["schemas"]["LoginRequestBody"];
-            };
-        };
+        requestBody?: never;
         responses: {
             /** @description No Content */
             204: {
@@ -1101,9 +2178,11 @@ export interface operations {
             };
         };
     };
-    logout: {
+    "emergency-deactivate": {
         parameters: {
-            query?: never;
+            query?: {
+                token?: string;
+            };
             header?: never;
             path?: never;
             cookie?: never;
@@ -1128,7 +2207,41 @@ export interface operations {
             };
         };
     };
-    "reset-password": {
+    "export-ip-whitelist": {
+        parameters: {
+            query?: {
+                format?: "csv" | "json";
+            };
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        requestBody?: never;
+        responses: {
+            /** @description IP whitelist rules as a file download */
+            200: {
+                headers: {
+                    "Content-Disposition"?: string;
+                    "Content-Type"?: string;
+                    [name: string]: unknown;
+                };
+                content: {
+                    "application/json": Record<string, never>[];
+                    "text/csv": string;
+                };
+            };
+            /** @description Error */
+            default: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content: {
+                    "application/problem+json": components["schemas"]["ErrorModel"];
+                };
+            };
+        };
+    };
+    "import-ip-whitelist": {
         parameters: {
             query?: never;
             header?: never;
@@ -1137,16 +2250,18 @@ export interface operations {
         };
         requestBody: {
             content: {
-                "application/json": components["schemas"]["ResetPasswordRequestBody"];
+                "application/json": components["schemas"]["ImportIPWhitelistRequest"];
             };
         };
         responses: {
-            /** @description No Content */
-            204: {
+            /** @description OK */
+            200: {
                 headers: {
                     [name: string]: unknown;
                 };
-                content?: never;
+                content: {
+                    "application/json": components["schemas"]["ImportIPWhitelistResponse"];
+                };
             };
             /** @description Error */
             default: {
@@ -1159,18 +2274,14 @@ export interface operations {
             };
         };
     };
-    signup: {
+    "start-whitelist-monitor": {
         parameters: {
             query?: never;
             header?: never;
             path?: never;
             cookie?: never;
         };
-        requestBody: {
-            content: {
-                "application/json": components["schemas"]["SignupRequestBody"];
-            };
-        };
+        requestBody?: never;
         responses: {
             /** @description No Content */
             204: {
@@ -1190,27 +2301,25 @@ export interface operations {
             };
         };
     };
-    "tenant-signup": {
+    "get-whitelist-monitor-report": {
         parameters: {
             query?: {
-                token?: string;
+                days?: number;
             };
             header?: never;
             path?: never;
             cookie?: never;
         };
-        requestBody: {
-            content: {
-                "application/json": components["schemas"]["TenantSignupRequestBody"];
-            };
-        };
+        requestBody?: never;
         responses: {
-            /** @description No Content */
-            204: {
+            /** @description OK */
+            200: {
                 headers: {
                     [name: string]: unknown;
                 };
-                content?: never;
+                content: {
+                    "application/json": components["schemas"]["GetMonitorReportResponse"];
+                };
             };
             /** @description Error */
             default: {
@@ -1223,18 +2332,14 @@ export interface operations {
             };
         };
     };
-    "verify-reset-token": {
+    "get-ip-whitelist-recovery-codes": {
         parameters: {
             query?: never;
             header?: never;
             path?: never;
             cookie?: never;
         };
-        requestBody: {
-            content: {
-                "application/json": components["schemas"]["VerifyResetTokenRequestBody"];
-            };
-        };
+        requestBody?: never;
         responses: {
             /** @description OK */
             200: {
@@ -1242,7 +2347,7 @@ export interface operations {
                     [name: string]: unknown;
                 };
                 content: {
-                    "application/json": components["schemas"]["VerifyResetTokenResponse"];
+                    "application/json": components["schemas"]["GetRecoveryCodesResponse"];
                 };
             };
             /** @description Error */
@@ -1256,7 +2361,7 @@ export interface operations {
             };
         };
     };
-    "get-ip-whitelist": {
+    "issue-ip-whitelist-recovery-codes": {
         parameters: {
             query?: never;
             header?: never;
@@ -1271,7 +2376,7 @@ export interface operations {
                     [name: string]: unknown;
                 };
                 content: {
-                    "application/json": components["schemas"]["GetIPWhitelistResponse"];
+                    "application/json": components["schemas"]["IssueRecoveryCodesResponse"];
                 };
             };
             /** @description Error */
@@ -1285,7 +2390,7 @@ export interface operations {
             };
         };
     };
-    "activate-whitelist": {
+    "redeem-ip-whitelist-recovery-code": {
         parameters: {
             query?: never;
             header?: never;
@@ -1294,9 +2399,36 @@ export interface operations {
         };
         requestBody: {
             content: {
-                "application/json": components["schemas"]["ActivateWhitelistRequestBody"];
+                "application/json": components["schemas"]["RedeemRecoveryCodeRequestBody"];
+            };
+        };
+        responses: {
+            /** @description No Content */
+            204: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content?: never;
+            };
+            /** @description Error */
+            default: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content: {
+                    "application/problem+json": components["schemas"]["ErrorModel"];
+                };
             };
         };
+    };
+    "get-ip-whitelist-scopes": {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        requestBody?: never;
         responses: {
             /** @description OK */
             200: {
@@ -1304,7 +2436,7 @@ export interface operations {
                     [name: string]: unknown;
                 };
                 content: {
-                    "application/json": components["schemas"]["ActivateWhitelistResponse"];
+                    "application/json": components["schemas"]["GetIPWhitelistScopesResponse"];
                 };
             };
             /** @description Error */
@@ -1318,7 +2450,7 @@ export interface operations {
             };
         };
     };
-    "add-ip-to-whitelist": {
+    "create-ip-whitelist-scope": {
         parameters: {
             query?: never;
             header?: never;
@@ -1327,16 +2459,18 @@ export interface operations {
         };
         requestBody: {
             content: {
-                "application/json": components["schemas"]["AddIPToWhitelistRequest"];
+                "application/json": components["schemas"]["CreateIPWhitelistScopeRequest"];
             };
         };
         responses: {
-            /** @description No Content */
-            204: {
+            /** @description OK */
+            200: {
                 headers: {
                     [name: string]: unknown;
                 };
-                content?: never;
+                content: {
+                    "application/json": components["schemas"]["CreateIPWhitelistScopeResponse"];
+                };
             };
             /** @description Error */
             default: {
@@ -1349,11 +2483,13 @@ export interface operations {
             };
         };
     };
-    "deactivate-whitelist": {
+    "delete-ip-whitelist-scope": {
         parameters: {
             query?: never;
             header?: never;
-            path?: never;
+            path: {
+                id: string;
+            };
             cookie?: never;
         };
         requestBody?: never;
@@ -1376,16 +2512,20 @@ export interface operations {
             };
         };
     };
-    "emergency-deactivate": {
+    "update-ip-whitelist-scope": {
         parameters: {
-            query?: {
-                token?: string;
-            };
+            query?: never;
             header?: never;
-            path?: never;
+            path: {
+                id: string;
+            };
             cookie?: never;
         };
-        requestBody?: never;
+        requestBody: {
+            content: {
+                "application/json": components["schemas"]["UpdateIPWhitelistScopeRequest"];
+            };
+        };
         responses: {
             /** @description No Content */
             204: {
@@ -1707,6 +2847,68 @@ export interface operations {
             };
         };
     };
+    "get-role-templates": {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        requestBody?: never;
+        responses: {
+            /** @description OK */
+            200: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content: {
+                    "application/json": components["schemas"]["GetRoleTemplatesResponse"];
+                };
+            };
+            /** @description Error */
+            default: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content: {
+                    "application/problem+json": components["schemas"]["ErrorModel"];
+                };
+            };
+        };
+    };
+    "create-role-from-template": {
+        parameters: {
+            query?: never;
+            header?: never;
+            path: {
+                templateKey: string;
+            };
+            cookie?: never;
+        };
+        requestBody: {
+            content: {
+                "application/json": components["schemas"]["CreateRoleFromTemplateRequestBody"];
+            };
+        };
+        responses: {
+            /** @description No Content */
+            204: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content?: never;
+            };
+            /** @description Error */
+            default: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content: {
+                    "application/problem+json": components["schemas"]["ErrorModel"];
+                };
+            };
+        };
+    };
     "delete-role": {
         parameters: {
             query?: never;
@@ -1736,6 +2938,39 @@ export interface operations {
             };
         };
     };
+    "duplicate-role": {
+        parameters: {
+            query?: never;
+            header?: never;
+            path: {
+                roleID: string;
+            };
+            cookie?: never;
+        };
+        requestBody: {
+            content: {
+                "application/json": components["schemas"]["DuplicateRoleRequestBody"];
+            };
+        };
+        responses: {
+            /** @description No Content */
+            204: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content?: never;
+            };
+            /** @description Error */
+            default: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content: {
+                    "application/problem+json": components["schemas"]["ErrorModel"];
+                };
+            };
+        };
+    };
     "update-role": {
         parameters: {
             query?: never;
@@ -733,6 +733,46 @@ test.describe("IP Whitelist E2E Tests", () => {
 			.click();
 		await expect(page.getByTestId("add-ip-slideover-panel")).not.toBeVisible();
 	});
+
+	test("Broad IP range requires confirmation", async ({ page }) => {
+		const broadRange = "0.0.0.0/0";
+
+		await logInAs(page, TestUsersData.enterprise_1);
+		await page.goto(IP_WHITELIST_URL);
+
+		await page.getByTestId("add-ip-button").click();
+		await expect(page.getByTestId("add-ip-slideover-panel")).toBeVisible();
+		await page.getByTestId("ip-address-input").fill(broadRange);
+
+		// The first submit is refused and only shows the warning, not an error toast
+		const firstResponsePromise = page.waitForResponse("/api/ip-whitelist/create");
+		await page.getByTestId("add-ip-slideover-primary-button").click();
+		const firstResponse = await firstResponsePromise;
+		expect(firstResponse.status()).toBe(409);
+
+		await expect(page.getByTestId("broad-prefix-warning")).toContainText(broadRange);
+		await expect(page.getByTestId("add-ip-slideover-primary-button")).toContainText(
+			"確認して追加"
+		);
+		await expect(page.getByTestId("add-ip-slideover-panel")).toBeVisible();
+
+		// Submitting the same range again stores it
+		const createResponsePromise = page.waitForResponse("/api/ip-whitelist/create");
+		const refreshResponsePromise = page.waitForResponse("/api/ip-whitelist");
+		await page.getByTestId("add-ip-slideover-primary-button").click();
+		await createResponsePromise;
+		await refreshResponsePromise;
+
+		await expect(page.getByTestId("toast-0")).toContainText("IPアドレスを追加しました");
+		await expect(
+			page.getByTestId("ip-whitelist-table-body").locator("code", { hasText: broadRange })
+		).toBeVisible();
+
+		// The analysis flags it, and the ranges it now covers
+		await expect(page.getByTestId("rule-analysis")).toBeVisible();
+		await expect(page.getByTestId("rule-analysis")).toContainText("広すぎる範囲");
+		await expect(page.getByTestId("rule-analysis")).toContainText("重複");
+	});
 });
 
 test.describe("Activation/Deactivation Workflows", () => {
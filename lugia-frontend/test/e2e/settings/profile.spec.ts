@@ -308,6 +308,21 @@ test.describe("Settings - Profile Page", () => {
 		});
 	});
 
+	test.describe("Data Export", () => {
+		test("should download the user's own data as a dated JSON file", async ({ page }) => {
+			await logInAs(page, TestUsersData.enterprise_2);
+			await page.goto(PROFILE_URL);
+
+			const downloadPromise = page.waitForEvent("download");
+			await page.getByTestId("export-data-button").click();
+			const download = await downloadPromise;
+
+			expect(download.suggestedFilename()).toMatch(
+				/^user-data-[0-9a-f-]+-\d{4}-\d{2}-\d{2}\.json$/
+			);
+		});
+	});
+
 	test.describe("Email Verification Success Handling", () => {
 		test("should show success toast when returning from email verification", async ({ page }) => {
 			// Use alpha_admin to avoid rate limiting issues with alpha_editor
@@ -7,11 +7,17 @@ export type Me = {
 	tenant_name: string;
 	permissions: `${"tenant" | "users" | "roles" | "ip_whitelist" | "audit_log"}.${"view" | "edit"}`[]; // array of {resource}.{action}, e.g. users.view
 	enterprise_features: EnterpriseFeatures;
+	administered_group_ids?: string[]; // user groups the user can manage members of
 };
 
 export type EnterpriseFeatures = {
 	rbac: { enabled: boolean };
-	ip_whitelist: { enabled: boolean; active: boolean; allow_internal_admin_bypass: boolean };
+	ip_whitelist: {
+		enabled: boolean;
+		active: boolean;
+		monitor: boolean;
+		allow_internal_admin_bypass: boolean;
+	};
 	audit_log: { enabled: boolean };
 };
 